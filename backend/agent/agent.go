@@ -35,6 +35,7 @@ type StorkAgent struct {
 	HTTPClient     *HTTPClient // to communicate with Kea Control Agent and named statistics-channel
 	server         *grpc.Server
 	logTailer      *logTailer
+	hookLibraries  *hookLibraryChecker
 	keaInterceptor *keaInterceptor
 	shutdownOnce   sync.Once
 	hookManager    *HookManager
@@ -49,8 +50,9 @@ func NewStorkAgent(settings *cli.Context, appMonitor AppMonitor, hookManager *Ho
 	sa := &StorkAgent{
 		Settings:       settings,
 		AppMonitor:     appMonitor,
-		HTTPClient:     NewHTTPClient(settings.Bool("skip-tls-cert-verification")),
+		HTTPClient:     NewHTTPClient(settings.Bool("skip-tls-cert-verification"), NewHTTPClientProxySettings(settings)),
 		logTailer:      logTailer,
+		hookLibraries:  newHookLibraryChecker(),
 		keaInterceptor: newKeaInterceptor(),
 		hookManager:    hookManager,
 	}
@@ -427,6 +429,28 @@ func (sa *StorkAgent) TailTextFile(ctx context.Context, in *agentapi.TailTextFil
 	return response, nil
 }
 
+// Checks if the specified hook library files exist on the machine. The
+// file is considered existing if it is a regular file or a symbolic link
+// to a regular file. Only the hook libraries found in the Kea configurations
+// returned in response to the config-get commands can be checked.
+func (sa *StorkAgent) CheckHookLibraries(ctx context.Context, in *agentapi.CheckHookLibrariesReq) (*agentapi.CheckHookLibrariesRsp, error) {
+	response := &agentapi.CheckHookLibrariesRsp{
+		Status: &agentapi.Status{
+			Code: agentapi.Status_OK, // all ok
+		},
+	}
+
+	files, err := sa.hookLibraries.check(in.Paths)
+	if err != nil {
+		response.Status.Code = agentapi.Status_ERROR
+		response.Status.Message = fmt.Sprintf("%s", err)
+		return response, nil
+	}
+	response.Files = files
+
+	return response, nil
+}
+
 // Starts the gRPC and HTTP listeners.
 func (sa *StorkAgent) Serve() error {
 	// Install gRPC API handlers.
@@ -66,7 +66,7 @@ func setupAgentTest() (*StorkAgent, context.Context) {
 // Initializes StorkAgent instance and context used by the tests. Loads the
 // given list of callout carriers (hooks' contents).
 func setupAgentTestWithHooks(calloutCarriers []hooks.CalloutCarrier) (*StorkAgent, context.Context) {
-	httpClient := NewHTTPClient(true)
+	httpClient := NewHTTPClient(true, HTTPClientProxySettings{})
 	gock.InterceptClient(httpClient.client)
 
 	fam := FakeAppMonitor{}
@@ -74,6 +74,7 @@ func setupAgentTestWithHooks(calloutCarriers []hooks.CalloutCarrier) (*StorkAgen
 		AppMonitor:     &fam,
 		HTTPClient:     httpClient,
 		logTailer:      newLogTailer(),
+		hookLibraries:  newHookLibraryChecker(),
 		keaInterceptor: newKeaInterceptor(),
 		hookManager:    NewHookManager(),
 	}
@@ -283,6 +284,38 @@ func TestForwardToKeaOverHTTPSuccess(t *testing.T) {
 	require.JSONEq(t, "[{\"result\":0}]", doGunzip(rsp.KeaResponses[0].Response))
 }
 
+// Test that the gzip-encoded response from Kea is decompressed before it
+// is forwarded to the server.
+func TestForwardToKeaOverHTTPGzippedResponse(t *testing.T) {
+	sa, ctx := setupAgentTest()
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, err := writer.Write([]byte(`[{"result":0,"arguments":{"Dhcp4":{}}}]`))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	defer gock.Off()
+	gock.New("http://localhost:45634").
+		MatchHeader("Accept-Encoding", "gzip").
+		Post("/").
+		Reply(200).
+		SetHeader("Content-Encoding", "gzip").
+		Body(&compressed)
+
+	req := &agentapi.ForwardToKeaOverHTTPReq{
+		Url:         "http://localhost:45634/",
+		KeaRequests: []*agentapi.KeaRequest{{Request: "{ \"command\": \"config-get\"}"}},
+	}
+
+	rsp, err := sa.ForwardToKeaOverHTTP(ctx, req)
+	require.NotNil(t, rsp)
+	require.NoError(t, err)
+	require.Len(t, rsp.KeaResponses, 1)
+	require.Equal(t, agentapi.Status_OK, rsp.KeaResponses[0].Status.Code)
+	require.JSONEq(t, `[{"result":0,"arguments":{"Dhcp4":{}}}]`, doGunzip(rsp.KeaResponses[0].Response))
+}
+
 // Test forwarding command to Kea when HTTP 400 (Bad Request) status
 // code is returned.
 func TestForwardToKeaOverHTTPBadRequest(t *testing.T) {
@@ -641,6 +674,66 @@ func TestTailTextFile(t *testing.T) {
 	require.Equal(t, "in testing TailTextFile", rsp.Lines[2])
 }
 
+// Test that the existence of the hook library files is checked.
+func TestCheckHookLibraries(t *testing.T) {
+	sa, ctx := setupAgentTest()
+
+	sandbox := testutil.NewSandbox()
+	defer sandbox.Close()
+	existingPath, err := sandbox.Write("libdhcp_ha.so", "")
+	require.NoError(t, err)
+	missingPath := path.Join(sandbox.BasePath, "libdhcp_lease_cmds.so")
+	sa.hookLibraries.allow(existingPath)
+	sa.hookLibraries.allow(missingPath)
+	sa.hookLibraries.allow(sandbox.BasePath)
+
+	req := &agentapi.CheckHookLibrariesReq{
+		Paths: []string{existingPath, missingPath, sandbox.BasePath},
+	}
+
+	rsp, err := sa.CheckHookLibraries(ctx, req)
+	require.NoError(t, err)
+	require.NotNil(t, rsp)
+	require.Equal(t, agentapi.Status_OK, rsp.Status.Code)
+	require.Len(t, rsp.Files, 3)
+	require.Equal(t, existingPath, rsp.Files[0].Path)
+	require.True(t, rsp.Files[0].Exists)
+	require.Equal(t, missingPath, rsp.Files[1].Path)
+	require.False(t, rsp.Files[1].Exists)
+	// The directory is not a hook library file.
+	require.Equal(t, sandbox.BasePath, rsp.Files[2].Path)
+	require.False(t, rsp.Files[2].Exists)
+}
+
+// Test that checking the hook library files not found in the Kea
+// configurations is forbidden.
+func TestCheckHookLibrariesForbidden(t *testing.T) {
+	// Arrange
+	sa, ctx := setupAgentTest()
+
+	sandbox := testutil.NewSandbox()
+	defer sandbox.Close()
+	allowedPath, err := sandbox.Write("libdhcp_ha.so", "")
+	require.NoError(t, err)
+	forbiddenPath, err := sandbox.Write("secret", "")
+	require.NoError(t, err)
+	sa.hookLibraries.allow(allowedPath)
+
+	req := &agentapi.CheckHookLibrariesReq{
+		Paths: []string{allowedPath, forbiddenPath},
+	}
+
+	// Act
+	rsp, err := sa.CheckHookLibraries(ctx, req)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, rsp)
+	require.Equal(t, agentapi.Status_ERROR, rsp.Status.Code)
+	require.Contains(t, rsp.Status.Message, forbiddenPath)
+	require.Empty(t, rsp.Files)
+}
+
 // Checks if getRootCertificates:
 // - returns an error if the cert file doesn't exist.
 func TestGetRootCertificatesForMissingOrInvalidFiles(t *testing.T) {
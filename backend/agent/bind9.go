@@ -48,6 +48,12 @@ func (ba *Bind9App) DetectAllowedLogs() ([]string, error) {
 	return nil, nil
 }
 
+// BIND 9 does not use hook libraries. It returns always empty list and
+// no error.
+func (ba *Bind9App) DetectAllowedHookLibraries() ([]string, error) {
+	return nil, nil
+}
+
 // Returns a list of the configured daemons in a given application.
 func (ba *Bind9App) GetConfiguredDaemons() []string {
 	// Bind9 is a single daemon application.
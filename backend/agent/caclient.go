@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"compress/gzip"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
@@ -8,10 +9,14 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/net/http/httpproxy"
 )
 
 // CredentialsFile path to a file holding credentials used in basic authentication of the agent in Kea.
@@ -24,10 +29,59 @@ type HTTPClient struct {
 	credentials *CredentialsStore
 }
 
+// Proxy settings of the HTTP client. If the proxy URL is empty, the proxy
+// is read from the HTTP_PROXY and HTTPS_PROXY environment variables.
+type HTTPClientProxySettings struct {
+	// URL of the proxy used for the HTTP and HTTPS requests.
+	URL string
+	// Comma-separated list of the hosts, domains, IP addresses and CIDRs
+	// that are reached directly. It has the same format as the NO_PROXY
+	// environment variable. If it is empty and the environment is used,
+	// the NO_PROXY environment variable is used.
+	NoProxy string
+	// Indicates if the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment
+	// variables are used for the settings not specified explicitly. They
+	// are ignored by default, so the agent doesn't start proxying the
+	// connections to Kea and BIND 9 after an upgrade only because these
+	// variables are set for other software.
+	UseEnvironment bool
+}
+
+// Reads the proxy settings from the command line flags.
+func NewHTTPClientProxySettings(settings *cli.Context) HTTPClientProxySettings {
+	return HTTPClientProxySettings{
+		URL:            settings.String("http-proxy"),
+		NoProxy:        settings.String("no-proxy"),
+		UseEnvironment: settings.Bool("use-env-proxy"),
+	}
+}
+
+// Returns the function selecting the proxy for a request. The requests to
+// the loopback addresses and the hosts matching the no-proxy list are not
+// proxied.
+func (s HTTPClientProxySettings) getProxyFunc() func(*http.Request) (*url.URL, error) {
+	config := &httpproxy.Config{}
+	if s.UseEnvironment {
+		config = httpproxy.FromEnvironment()
+	}
+	if s.URL != "" {
+		config.HTTPProxy = s.URL
+		config.HTTPSProxy = s.URL
+	}
+	if s.NoProxy != "" {
+		config.NoProxy = s.NoProxy
+	}
+	proxyFunc := config.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+}
+
 // Create a client to contact with Kea Control Agent or named statistics-channel.
 // If @skipTLSVerification is true then it doesn't verify the server credentials
-// over HTTPS. It may be useful when Kea uses a self-signed certificate.
-func NewHTTPClient(skipTLSVerification bool) *HTTPClient {
+// over HTTPS. It may be useful when Kea uses a self-signed certificate. The
+// proxy settings specify the HTTP proxy used to reach the servers.
+func NewHTTPClient(skipTLSVerification bool, proxy HTTPClientProxySettings) *HTTPClient {
 	// Kea only supports HTTP/1.1. By default, the client here would use HTTP/2.
 	// The instance of the client which is created here disables HTTP/2 and should
 	// be used whenever the communication with the Kea servers is required.
@@ -48,6 +102,7 @@ func NewHTTPClient(skipTLSVerification bool) *HTTPClient {
 		// Creating empty, non-nil map here disables the HTTP/2.
 		TLSNextProto:    make(map[string]func(authority string, c *tls.Conn) http.RoundTripper),
 		TLSClientConfig: &tlsConfig,
+		Proxy:           proxy.getProxyFunc(),
 	}
 
 	httpClient := &http.Client{
@@ -80,10 +135,28 @@ func NewHTTPClient(skipTLSVerification bool) *HTTPClient {
 	return client
 }
 
+// Response body decompressing the gzip-encoded content. Closing it closes
+// the underlying response body.
+type gzipResponseBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+// Closes the gzip reader and the underlying response body.
+func (b *gzipResponseBody) Close() error {
+	err := b.Reader.Close()
+	if bodyErr := b.body.Close(); err == nil {
+		err = bodyErr
+	}
+	return err
+}
+
 // Sends a request to a given endpoint using the HTTP POST method. The payload
 // must contain the valid JSON. If the authentication credentials or TLS
 // certificates are provided in the application configuration, they are added
-// to the request.
+// to the request. The client accepts the gzip-encoded responses and
+// transparently decompresses them, so the caller always reads the plain
+// response body.
 func (c *HTTPClient) Call(url string, payload io.Reader) (*http.Response, error) {
 	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, payload)
 	if err != nil {
@@ -92,6 +165,9 @@ func (c *HTTPClient) Call(url string, payload io.Reader) (*http.Response, error)
 		return nil, err
 	}
 	req.Header.Add("Content-Type", "application/json")
+	// Setting the header explicitly disables the decompression in the
+	// transport, so the response is decompressed below.
+	req.Header.Add("Accept-Encoding", "gzip")
 
 	if basicAuth, ok := c.credentials.GetBasicAuthByURL(url); ok {
 		secret := fmt.Sprintf("%s:%s", basicAuth.User, basicAuth.Password)
@@ -103,8 +179,23 @@ func (c *HTTPClient) Call(url string, payload io.Reader) (*http.Response, error)
 	rsp, err := c.client.Do(req)
 	if err != nil {
 		err = errors.Wrapf(err, "problem sending POST to %s", url)
+		return rsp, err
+	}
+
+	if strings.EqualFold(rsp.Header.Get("Content-Encoding"), "gzip") {
+		reader, err := gzip.NewReader(rsp.Body)
+		if err != nil {
+			rsp.Body.Close()
+			err = errors.Wrapf(err, "problem decompressing the response from %s", url)
+			return nil, err
+		}
+		rsp.Body = &gzipResponseBody{Reader: reader, body: rsp.Body}
+		rsp.Header.Del("Content-Encoding")
+		rsp.Header.Del("Content-Length")
+		rsp.ContentLength = -1
+		rsp.Uncompressed = true
 	}
-	return rsp, err
+	return rsp, nil
 }
 
 // Indicates if the Stork Agent attaches the authentication credentials to
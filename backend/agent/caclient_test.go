@@ -2,10 +2,13 @@ package agent
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path"
 	"strings"
@@ -21,7 +24,7 @@ func TestCreateHTTPClientWithClientCerts(t *testing.T) {
 	require.NoError(t, err)
 	defer cleanup()
 
-	client := NewHTTPClient(false)
+	client := NewHTTPClient(false, HTTPClientProxySettings{})
 	require.NotNil(t, client)
 
 	transport := client.client.Transport.(*http.Transport)
@@ -48,7 +51,7 @@ func TestCreateHTTPClientWithoutClientCerts(t *testing.T) {
 	RootCAFile = "/not/exists/path"
 	AgentTokenFile = "/not/exists/path"
 
-	client := NewHTTPClient(false)
+	client := NewHTTPClient(false, HTTPClientProxySettings{})
 	require.NotNil(t, client)
 
 	transport := client.client.Transport.(*http.Transport)
@@ -65,7 +68,7 @@ func TestCreateHTTPClientWithoutClientCerts(t *testing.T) {
 // Check that HTTP client may be set to skip a server
 // credentials validation.
 func TestCreateHTTPClientSkipVerification(t *testing.T) {
-	client := NewHTTPClient(true)
+	client := NewHTTPClient(true, HTTPClientProxySettings{})
 	require.NotNil(t, client)
 
 	transport := client.client.Transport.(*http.Transport)
@@ -76,6 +79,144 @@ func TestCreateHTTPClientSkipVerification(t *testing.T) {
 	require.True(t, transportConfig.InsecureSkipVerify)
 }
 
+// Returns the proxy URL selected by the HTTP client for the request to the
+// specified URL or nil if the request is sent directly.
+func getHTTPClientProxy(t *testing.T, client *HTTPClient, requestURL string) *url.URL {
+	transport := client.client.Transport.(*http.Transport)
+	require.NotNil(t, transport.Proxy)
+	request, err := http.NewRequest(http.MethodPost, requestURL, nil)
+	require.NoError(t, err)
+	proxyURL, err := transport.Proxy(request)
+	require.NoError(t, err)
+	return proxyURL
+}
+
+// Test that the configured proxy is used and that the hosts from the
+// no-proxy list are reached directly.
+func TestCreateHTTPClientWithProxy(t *testing.T) {
+	// Arrange
+	t.Setenv("HTTP_PROXY", "")
+	t.Setenv("HTTPS_PROXY", "")
+	t.Setenv("NO_PROXY", "")
+	t.Setenv("http_proxy", "")
+	t.Setenv("https_proxy", "")
+	t.Setenv("no_proxy", "")
+
+	// Act
+	client := NewHTTPClient(false, HTTPClientProxySettings{
+		URL:     "http://proxy.example.org:3128",
+		NoProxy: "kea.example.org,192.0.2.0/24",
+	})
+
+	// Assert
+	proxyURL := getHTTPClientProxy(t, client, "http://kea2.example.org:8000")
+	require.NotNil(t, proxyURL)
+	require.Equal(t, "proxy.example.org:3128", proxyURL.Host)
+
+	proxyURL = getHTTPClientProxy(t, client, "https://kea2.example.org:8000")
+	require.NotNil(t, proxyURL)
+	require.Equal(t, "proxy.example.org:3128", proxyURL.Host)
+
+	require.Nil(t, getHTTPClientProxy(t, client, "http://kea.example.org:8000"))
+	require.Nil(t, getHTTPClientProxy(t, client, "http://192.0.2.1:8000"))
+	require.Nil(t, getHTTPClientProxy(t, client, "http://127.0.0.1:8000"))
+}
+
+// Test that the proxy settings are read from the environment variables if
+// they are not configured explicitly and the environment is enabled.
+func TestCreateHTTPClientWithProxyFromEnvironment(t *testing.T) {
+	// Arrange
+	t.Setenv("HTTP_PROXY", "http://proxy.example.org:3128")
+	t.Setenv("HTTPS_PROXY", "")
+	t.Setenv("NO_PROXY", "kea.example.org")
+	t.Setenv("http_proxy", "")
+	t.Setenv("https_proxy", "")
+	t.Setenv("no_proxy", "")
+
+	// Act
+	client := NewHTTPClient(false, HTTPClientProxySettings{UseEnvironment: true})
+
+	// Assert
+	proxyURL := getHTTPClientProxy(t, client, "http://kea2.example.org:8000")
+	require.NotNil(t, proxyURL)
+	require.Equal(t, "proxy.example.org:3128", proxyURL.Host)
+
+	require.Nil(t, getHTTPClientProxy(t, client, "https://kea2.example.org:8000"))
+	require.Nil(t, getHTTPClientProxy(t, client, "http://kea.example.org:8000"))
+}
+
+// Test that the NO_PROXY environment variable is honoured for the proxy
+// specified explicitly if the environment is enabled.
+func TestCreateHTTPClientWithProxyAndNoProxyFromEnvironment(t *testing.T) {
+	// Arrange
+	t.Setenv("HTTP_PROXY", "")
+	t.Setenv("HTTPS_PROXY", "")
+	t.Setenv("NO_PROXY", "kea.example.org")
+	t.Setenv("http_proxy", "")
+	t.Setenv("https_proxy", "")
+	t.Setenv("no_proxy", "")
+
+	// Act
+	client := NewHTTPClient(false, HTTPClientProxySettings{
+		URL:            "http://proxy.example.org:3128",
+		UseEnvironment: true,
+	})
+
+	// Assert
+	proxyURL := getHTTPClientProxy(t, client, "http://kea2.example.org:8000")
+	require.NotNil(t, proxyURL)
+	require.Equal(t, "proxy.example.org:3128", proxyURL.Host)
+
+	require.Nil(t, getHTTPClientProxy(t, client, "http://kea.example.org:8000"))
+}
+
+// Test that the proxy environment variables are ignored unless the
+// environment is enabled.
+func TestCreateHTTPClientIgnoresProxyEnvironment(t *testing.T) {
+	// Arrange
+	t.Setenv("HTTP_PROXY", "http://proxy.example.org:3128")
+	t.Setenv("HTTPS_PROXY", "http://proxy.example.org:3128")
+	t.Setenv("NO_PROXY", "kea.example.org")
+	t.Setenv("http_proxy", "")
+	t.Setenv("https_proxy", "")
+	t.Setenv("no_proxy", "")
+
+	// Act
+	client := NewHTTPClient(false, HTTPClientProxySettings{})
+	clientWithProxy := NewHTTPClient(false, HTTPClientProxySettings{
+		URL: "http://proxy2.example.org:3128",
+	})
+
+	// Assert
+	require.Nil(t, getHTTPClientProxy(t, client, "http://kea2.example.org:8000"))
+	require.Nil(t, getHTTPClientProxy(t, client, "https://kea2.example.org:8000"))
+
+	proxyURL := getHTTPClientProxy(t, clientWithProxy, "http://kea2.example.org:8000")
+	require.NotNil(t, proxyURL)
+	require.Equal(t, "proxy2.example.org:3128", proxyURL.Host)
+	// The NO_PROXY environment variable is ignored too.
+	proxyURL = getHTTPClientProxy(t, clientWithProxy, "http://kea.example.org:8000")
+	require.NotNil(t, proxyURL)
+	require.Equal(t, "proxy2.example.org:3128", proxyURL.Host)
+}
+
+// Test that no proxy is used by default.
+func TestCreateHTTPClientWithoutProxy(t *testing.T) {
+	// Arrange
+	t.Setenv("HTTP_PROXY", "")
+	t.Setenv("HTTPS_PROXY", "")
+	t.Setenv("NO_PROXY", "")
+	t.Setenv("http_proxy", "")
+	t.Setenv("https_proxy", "")
+	t.Setenv("no_proxy", "")
+
+	// Act
+	client := NewHTTPClient(false, HTTPClientProxySettings{})
+
+	// Assert
+	require.Nil(t, getHTTPClientProxy(t, client, "http://kea.example.org:8000"))
+}
+
 // Test that an authorization header is added to the HTTP request
 // when the credentials file contains the credentials for specific
 // network location.
@@ -124,7 +265,7 @@ func TestAddAuthorizationHeaderWhenBasicAuthCredentialsExist(t *testing.T) {
 	require.NoError(t, err)
 
 	// Create HTTP Client
-	client := NewHTTPClient(true)
+	client := NewHTTPClient(true, HTTPClientProxySettings{})
 	require.NotNil(t, client.credentials)
 
 	res, err := client.Call(ts.URL, bytes.NewBuffer([]byte{}))
@@ -145,7 +286,7 @@ func TestAddAuthorizationHeaderWhenBasicAuthCredentialsNonExist(t *testing.T) {
 	}))
 	defer ts.Close()
 
-	client := NewHTTPClient(true)
+	client := NewHTTPClient(true, HTTPClientProxySettings{})
 	require.NotNil(t, client.credentials)
 
 	res, err := client.Call(ts.URL, bytes.NewBuffer([]byte{}))
@@ -163,12 +304,92 @@ func TestCallWithMissingBody(t *testing.T) {
 	}))
 	defer ts.Close()
 
-	client := NewHTTPClient(false)
+	client := NewHTTPClient(false, HTTPClientProxySettings{})
 	res, err := client.Call(ts.URL, nil)
 	require.NoError(t, err)
 	defer res.Body.Close()
 }
 
+// Test that the client accepts the gzip-encoded responses and decompresses
+// them transparently.
+func TestCallWithGzippedResponse(t *testing.T) {
+	// Arrange
+	restorePaths := RememberPaths()
+	defer restorePaths()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "gzip", r.Header.Get("Accept-Encoding"))
+		w.Header().Set("Content-Encoding", "gzip")
+		writer := gzip.NewWriter(w)
+		_, _ = writer.Write([]byte(`[{ "result": 0 }]`))
+		writer.Close()
+	}))
+	defer ts.Close()
+
+	client := NewHTTPClient(false, HTTPClientProxySettings{})
+
+	// Act
+	res, err := client.Call(ts.URL, bytes.NewBuffer([]byte{}))
+
+	// Assert
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Empty(t, res.Header.Get("Content-Encoding"))
+	require.True(t, res.Uncompressed)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.JSONEq(t, `[{ "result": 0 }]`, string(body))
+}
+
+// Test that the plain response is returned as is when the server doesn't
+// compress it.
+func TestCallWithPlainResponse(t *testing.T) {
+	// Arrange
+	restorePaths := RememberPaths()
+	defer restorePaths()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{ "result": 0 }]`))
+	}))
+	defer ts.Close()
+
+	client := NewHTTPClient(false, HTTPClientProxySettings{})
+
+	// Act
+	res, err := client.Call(ts.URL, bytes.NewBuffer([]byte{}))
+
+	// Assert
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.False(t, res.Uncompressed)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.JSONEq(t, `[{ "result": 0 }]`, string(body))
+}
+
+// Test that an error is returned when the response claims to be gzipped
+// but it is not.
+func TestCallWithInvalidGzippedResponse(t *testing.T) {
+	// Arrange
+	restorePaths := RememberPaths()
+	defer restorePaths()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		_, _ = w.Write([]byte(`[{ "result": 0 }]`))
+	}))
+	defer ts.Close()
+
+	client := NewHTTPClient(false, HTTPClientProxySettings{})
+
+	// Act
+	res, err := client.Call(ts.URL, bytes.NewBuffer([]byte{}))
+
+	// Assert
+	require.Error(t, err)
+	require.Nil(t, res)
+}
+
 // Test that the authentication credentials are detected properly.
 func TestHasAuthenticationCredentials(t *testing.T) {
 	// Arrange
@@ -194,7 +415,7 @@ func TestHasAuthenticationCredentials(t *testing.T) {
 	_ = os.WriteFile(CredentialsFile, []byte(content), 0o600)
 
 	// Act
-	client := NewHTTPClient(false)
+	client := NewHTTPClient(false, HTTPClientProxySettings{})
 
 	// Assert
 	require.True(t, client.HasAuthenticationCredentials())
@@ -217,7 +438,7 @@ func TestHasAuthenticationCredentialsEmptyFile(t *testing.T) {
 	_ = os.WriteFile(CredentialsFile, []byte(content), 0o600)
 
 	// Act
-	client := NewHTTPClient(false)
+	client := NewHTTPClient(false, HTTPClientProxySettings{})
 
 	// Assert
 	require.False(t, client.HasAuthenticationCredentials())
@@ -233,7 +454,7 @@ func TestHasAuthenticationCredentialsMissingFile(t *testing.T) {
 	CredentialsFile = "/not/exist/file.json"
 
 	// Act
-	client := NewHTTPClient(false)
+	client := NewHTTPClient(false, HTTPClientProxySettings{})
 
 	// Assert
 	require.False(t, client.HasAuthenticationCredentials())
@@ -0,0 +1,88 @@
+package agent
+
+import (
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	agentapi "isc.org/stork/api"
+	keaconfig "isc.org/stork/appcfg/kea"
+	keactrl "isc.org/stork/appctrl/kea"
+)
+
+// Hook library checker verifies the existence of the hook library files on
+// the machine. It maintains the list of the hook library paths found in the
+// Kea configurations. If the file is not on the list of the allowed files,
+// an error is returned upon an attempt to check it. It prevents the server
+// from probing arbitrary files on the agent's machine.
+type hookLibraryChecker struct {
+	allowedPaths map[string]bool
+	mutex        *sync.Mutex
+}
+
+// Creates new instance of the hook library checker.
+func newHookLibraryChecker() *hookLibraryChecker {
+	return &hookLibraryChecker{
+		allowedPaths: make(map[string]bool),
+		mutex:        new(sync.Mutex),
+	}
+}
+
+// Adds a specified path to the list of files which can be checked.
+func (hc *hookLibraryChecker) allow(path string) {
+	hc.mutex.Lock()
+	defer hc.mutex.Unlock()
+	hc.allowedPaths[path] = true
+}
+
+// Checks if the given file can be checked.
+func (hc *hookLibraryChecker) allowed(path string) bool {
+	hc.mutex.Lock()
+	defer hc.mutex.Unlock()
+	_, ok := hc.allowedPaths[path]
+	return ok
+}
+
+// Checks if the specified hook library files exist on the machine. The
+// file is considered existing if it is a regular file or a symbolic link
+// to a regular file. If any of the files is not allowed, an error is
+// returned and none of the files is checked.
+func (hc *hookLibraryChecker) check(paths []string) ([]*agentapi.HookLibraryFile, error) {
+	for _, path := range paths {
+		if !hc.allowed(path) {
+			return nil, errors.Errorf("access forbidden to the %s", path)
+		}
+	}
+
+	var files []*agentapi.HookLibraryFile
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		files = append(files, &agentapi.HookLibraryFile{
+			Path:   path,
+			Exists: err == nil && info.Mode().IsRegular(),
+		})
+	}
+	return files, nil
+}
+
+// Collects the hook library paths from the response to the config-get
+// command returned by one of the Kea daemons. This function is intended
+// to be called by the function which intercepts the config-get commands
+// sent periodically by the server to the agents.
+func collectKeaHookLibraries(response *keactrl.Response) []string {
+	if response.Result > 0 || response.Arguments == nil {
+		return nil
+	}
+	cfg := keaconfig.NewConfigFromMap(response.Arguments)
+	if cfg == nil {
+		return nil
+	}
+
+	var paths []string
+	for _, hook := range cfg.GetHookLibraries() {
+		if hook.Library != "" {
+			paths = append(paths, hook.Library)
+		}
+	}
+	return paths
+}
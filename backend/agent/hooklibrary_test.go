@@ -0,0 +1,74 @@
+package agent
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	keactrl "isc.org/stork/appctrl/kea"
+	"isc.org/stork/testutil"
+)
+
+// Test that the new instance of the hook library checker can be created
+// and that the internal fields have been initialized.
+func TestNewHookLibraryChecker(t *testing.T) {
+	hc := newHookLibraryChecker()
+	require.NotNil(t, hc)
+	require.NotNil(t, hc.allowedPaths)
+	require.NotNil(t, hc.mutex)
+}
+
+// Test the mechanism which allows checking selected files.
+func TestHookLibraryCheckerAllow(t *testing.T) {
+	hc := newHookLibraryChecker()
+	hc.allow("/usr/lib/kea/hooks/libdhcp_ha.so")
+	require.True(t, hc.allowed("/usr/lib/kea/hooks/libdhcp_ha.so"))
+	require.False(t, hc.allowed("/usr/lib/kea/hooks/libdhcp_lease_cmds.so"))
+
+	// Make sure that it is ok to allow the same file twice.
+	require.NotPanics(t, func() { hc.allow("/usr/lib/kea/hooks/libdhcp_ha.so") })
+	require.True(t, hc.allowed("/usr/lib/kea/hooks/libdhcp_ha.so"))
+}
+
+// Test that the allowed files are checked and an error is returned if
+// any of the files is not allowed.
+func TestHookLibraryCheckerCheck(t *testing.T) {
+	// Arrange
+	sandbox := testutil.NewSandbox()
+	defer sandbox.Close()
+	existingPath, err := sandbox.Write("libdhcp_ha.so", "")
+	require.NoError(t, err)
+	missingPath := path.Join(sandbox.BasePath, "libdhcp_lease_cmds.so")
+
+	hc := newHookLibraryChecker()
+
+	// Act
+	files, err := hc.check([]string{existingPath, missingPath})
+
+	// Assert
+	require.ErrorContains(t, err, "access forbidden")
+	require.Nil(t, files)
+
+	// Arrange
+	hc.allow(existingPath)
+	hc.allow(missingPath)
+
+	// Act
+	files, err = hc.check([]string{existingPath, missingPath})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	require.True(t, files[0].Exists)
+	require.False(t, files[1].Exists)
+}
+
+// Test that no hook libraries are collected from an unsuccessful response.
+func TestCollectKeaHookLibrariesUnsuccessfulResponse(t *testing.T) {
+	response := &keactrl.Response{
+		ResponseHeader: keactrl.ResponseHeader{
+			Result: keactrl.ResponseError,
+		},
+	}
+	require.Empty(t, collectKeaHookLibraries(response))
+}
@@ -96,14 +96,12 @@ func collectKeaAllowedLogs(response *keactrl.Response) []string {
 	return paths
 }
 
-// Sends config-get command to all running Kea daemons belonging to the given Kea app
-// to fetch logging configuration. The first config-get command is sent to the Kea CA,
-// to fetch its logging configuration and to find the daemons running behind it. Next, the
-// config-get command is sent to the daemons behind CA and their logging configuration
-// is fetched. The log files locations are stored in the logTailer instance of the
-// agent as allowed for viewing. This function should be called when the agent has
-// been started and the running Kea apps have been detected.
-func (ka *KeaApp) DetectAllowedLogs() ([]string, error) {
+// Sends config-get command to all running Kea daemons belonging to the given Kea app.
+// The first config-get command is sent to the Kea CA, to fetch its configuration and
+// to find the daemons running behind it. Next, the config-get command is sent to the
+// daemons behind CA. It returns the response from the CA followed by the responses
+// from the daemons.
+func (ka *KeaApp) getConfigs() (keactrl.ResponseList, error) {
 	// Prepare config-get command to be sent to Kea Control Agent.
 	command := keactrl.NewCommand("config-get", nil, nil)
 	// Send the command to Kea.
@@ -128,11 +126,10 @@ func (ka *KeaApp) DetectAllowedLogs() ([]string, error) {
 		return nil, errors.Errorf("unsuccessful response %d received from Kea CA to config-get command sent to %s:%d", responses[0].Result, ap.Address, ap.Port)
 	}
 
-	// Allow the log files used by the CA.
-	paths := collectKeaAllowedLogs(&responses[0])
+	caResponse := responses[0]
 
 	// Arguments should be returned in response to the config-get command.
-	rawConfig := responses[0].Arguments
+	rawConfig := caResponse.Arguments
 	if rawConfig == nil {
 		return nil, errors.Errorf("empty arguments received from Kea CA in response to config-get command sent to %s:%d", ap.Address, ap.Port)
 	}
@@ -149,7 +146,7 @@ func (ka *KeaApp) DetectAllowedLogs() ([]string, error) {
 
 	// Apparently, it isn't configured to forward commands to the daemons behind it.
 	if len(daemonNames) == 0 {
-		return nil, nil
+		return keactrl.ResponseList{caResponse}, nil
 	}
 
 	// Prepare config-get command to be sent to the daemons behind CA.
@@ -167,8 +164,23 @@ func (ka *KeaApp) DetectAllowedLogs() ([]string, error) {
 		return nil, errors.Errorf("invalid number of responses received from daemons to config-get command sent via %s:%d", ap.Address, ap.Port)
 	}
 
+	return append(keactrl.ResponseList{caResponse}, responses...), nil
+}
+
+// Fetches the configurations of all running Kea daemons belonging to the given
+// Kea app and collects the log files they use. The log files locations are stored
+// in the logTailer instance of the agent as allowed for viewing. This function
+// should be called when the agent has been started and the running Kea apps have
+// been detected.
+func (ka *KeaApp) DetectAllowedLogs() ([]string, error) {
+	responses, err := ka.getConfigs()
+	if err != nil {
+		return nil, err
+	}
+
 	// For each daemon try to extract its logging configuration and allow view
 	// the log files it contains.
+	var paths []string
 	for i := range responses {
 		paths = append(paths, collectKeaAllowedLogs(&responses[i])...)
 	}
@@ -176,6 +188,25 @@ func (ka *KeaApp) DetectAllowedLogs() ([]string, error) {
 	return paths, nil
 }
 
+// Fetches the configurations of all running Kea daemons belonging to the given
+// Kea app and collects the hook libraries they load. The hook library paths are
+// stored in the hook library checker of the agent. It allows the server to check
+// the hook libraries before it sends the first config-get command after the
+// agent is started.
+func (ka *KeaApp) DetectAllowedHookLibraries() ([]string, error) {
+	responses, err := ka.getConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for i := range responses {
+		paths = append(paths, collectKeaHookLibraries(&responses[i])...)
+	}
+
+	return paths, nil
+}
+
 // Returns a list of the configured daemons in a given application.
 func (ka *KeaApp) GetConfiguredDaemons() []string {
 	return ka.ConfiguredDaemons
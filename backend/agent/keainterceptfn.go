@@ -15,6 +15,17 @@ func icptConfigGetLoggers(agent *StorkAgent, response *keactrl.Response) error {
 	return nil
 }
 
+// Intercept callback function for config-get. It records hook libraries
+// found in the daemon's configuration, allowing the server to check if
+// their files exist.
+func icptConfigGetHookLibraries(agent *StorkAgent, response *keactrl.Response) error {
+	paths := collectKeaHookLibraries(response)
+	for _, p := range paths {
+		agent.hookLibraries.allow(p)
+	}
+	return nil
+}
+
 // Change the reservation-get-page response status if unsupported error is
 // returned.
 //
@@ -35,5 +46,6 @@ func reservationGetPageUnsupported(agent *StorkAgent, response *keactrl.Response
 // be extended every time a new intercept function is defined.
 func registerKeaInterceptFns(agent *StorkAgent) {
 	agent.keaInterceptor.registerAsync(icptConfigGetLoggers, "config-get")
+	agent.keaInterceptor.registerAsync(icptConfigGetHookLibraries, "config-get")
 	agent.keaInterceptor.registerSync(reservationGetPageUnsupported, "reservation-get-page")
 }
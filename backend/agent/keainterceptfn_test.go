@@ -76,6 +76,47 @@ func TestIcptConfigGetLoggers(t *testing.T) {
 	require.False(t, sa.logTailer.allowed("syslog:1"))
 }
 
+// Tests that config-get is intercepted and the hook libraries found in the
+// returned configuration are recorded. Only these files can be checked.
+func TestIcptConfigGetHookLibraries(t *testing.T) {
+	// Arrange
+	sa, _ := setupAgentTest()
+
+	responseArgsJSON := `{
+        "Dhcp4": {
+            "hooks-libraries": [
+                {
+                    "library": "/usr/lib/kea/hooks/libdhcp_lease_cmds.so"
+                },
+                {
+                    "library": "/usr/lib/kea/hooks/libdhcp_ha.so",
+                    "parameters": {}
+                }
+            ]
+        }
+    }`
+	responseArgs := make(map[string]interface{})
+	err := json.Unmarshal([]byte(responseArgsJSON), &responseArgs)
+	require.NoError(t, err)
+
+	response := &keactrl.Response{
+		ResponseHeader: keactrl.ResponseHeader{
+			Result: 0,
+			Daemon: "dhcp4",
+		},
+		Arguments: &responseArgs,
+	}
+
+	// Act
+	err = icptConfigGetHookLibraries(sa, response)
+
+	// Assert
+	require.NoError(t, err)
+	require.True(t, sa.hookLibraries.allowed("/usr/lib/kea/hooks/libdhcp_lease_cmds.so"))
+	require.True(t, sa.hookLibraries.allowed("/usr/lib/kea/hooks/libdhcp_ha.so"))
+	require.False(t, sa.hookLibraries.allowed("/usr/lib/kea/hooks/libdhcp_host_cmds.so"))
+}
+
 // Test that the result code is changed if the reservation-get-page command
 // returns an unsupported error.
 func TestReservationGetPageUnsupported(t *testing.T) {
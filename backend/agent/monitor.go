@@ -44,6 +44,7 @@ type BaseApp struct {
 type App interface {
 	GetBaseApp() *BaseApp
 	DetectAllowedLogs() ([]string, error)
+	DetectAllowedHookLibraries() ([]string, error)
 	GetConfiguredDaemons() []string
 }
 
@@ -109,6 +110,11 @@ func (sm *appMonitor) run(storkAgent *StorkAgent) {
 	// from the UI.
 	sm.detectAllowedLogs(storkAgent)
 
+	// The server doesn't send config-get to the restarted agent until the
+	// Kea configuration changes, so the hook libraries must be gathered
+	// here to let the server check them.
+	sm.detectAllowedHookLibraries(storkAgent)
+
 	// prepare ticker
 	const detectionInterval = 10 * time.Second
 	ticker := time.NewTicker(detectionInterval)
@@ -288,6 +294,33 @@ func (sm *appMonitor) detectAllowedLogs(storkAgent *StorkAgent) {
 	}
 }
 
+// Gathers the hook libraries loaded by the detected apps and allows
+// the server to check whether they exist.
+func (sm *appMonitor) detectAllowedHookLibraries(storkAgent *StorkAgent) {
+	// Nothing to do if the agent is not set. It may be nil when running some
+	// tests.
+	if storkAgent == nil {
+		return
+	}
+	for _, app := range sm.apps {
+		paths, err := app.DetectAllowedHookLibraries()
+		if err != nil {
+			ap := app.GetBaseApp().AccessPoints[0]
+			err = errors.WithMessagef(err, "Failed to detect hook libraries for Kea")
+			log.WithFields(
+				log.Fields{
+					"address": ap.Address,
+					"port":    ap.Port,
+				},
+			).Warn(err)
+		} else {
+			for _, p := range paths {
+				storkAgent.hookLibraries.allow(p)
+			}
+		}
+	}
+}
+
 // Get a list of detected apps by a monitor.
 func (sm *appMonitor) GetApps() []App {
 	ret := make(chan []App)
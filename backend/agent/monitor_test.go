@@ -2,6 +2,7 @@ package agent
 
 import (
 	"bytes"
+	"context"
 	"flag"
 	"fmt"
 	"path"
@@ -11,7 +12,9 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v2"
+	"gopkg.in/h2non/gock.v1"
 
+	agentapi "isc.org/stork/api"
 	"isc.org/stork/testutil"
 )
 
@@ -175,7 +178,7 @@ func TestDetectAllowedLogsKeaUnreachable(t *testing.T) {
 				},
 			},
 		},
-		HTTPClient: NewHTTPClient(false),
+		HTTPClient: NewHTTPClient(false, HTTPClientProxySettings{}),
 	})
 
 	hm := NewHookManager()
@@ -186,6 +189,67 @@ func TestDetectAllowedLogsKeaUnreachable(t *testing.T) {
 	require.NotPanics(t, func() { am.detectAllowedLogs(sa) })
 }
 
+// Test that the freshly started agent allows checking the hook libraries
+// loaded by the detected Kea daemons before the server sends config-get.
+func TestDetectAllowedHookLibrariesFreshAgent(t *testing.T) {
+	// Arrange
+	httpClient := NewHTTPClient(false, HTTPClientProxySettings{})
+	gock.InterceptClient(httpClient.client)
+	defer gock.Off()
+
+	gock.New("https://localhost:45634").
+		JSON(map[string]string{"command": "config-get"}).
+		Post("/").
+		Reply(200).
+		BodyString(`[{
+			"result": 0,
+			"arguments": {
+				"Control-agent": {
+					"control-sockets": {
+						"dhcp4": { "socket-name": "/tmp/kea-dhcp4-ctrl.sock" }
+					}
+				}
+			}
+		}]`)
+	gock.New("https://localhost:45634").
+		JSON(map[string]interface{}{"command": "config-get", "service": []string{"dhcp4"}}).
+		Post("/").
+		Reply(200).
+		BodyString(`[{
+			"result": 0,
+			"arguments": {
+				"Dhcp4": {
+					"hooks-libraries": [
+						{ "library": "/usr/lib/kea/hooks/libdhcp_lease_cmds.so" }
+					]
+				}
+			}
+		}]`)
+
+	am := &appMonitor{}
+	am.apps = append(am.apps, &KeaApp{
+		BaseApp: BaseApp{
+			Type:         AppTypeKea,
+			AccessPoints: makeAccessPoint(AccessPointControl, "localhost", "", 45634, true),
+		},
+		HTTPClient: httpClient,
+	})
+	settings := cli.NewContext(nil, flag.NewFlagSet("", 0), nil)
+	sa := NewStorkAgent(settings, am, NewHookManager())
+
+	// Act
+	am.detectAllowedHookLibraries(sa)
+	rsp, err := sa.CheckHookLibraries(context.Background(), &agentapi.CheckHookLibrariesReq{
+		Paths: []string{"/usr/lib/kea/hooks/libdhcp_lease_cmds.so"},
+	})
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, agentapi.Status_OK, rsp.Status.Code)
+	require.Len(t, rsp.Files, 1)
+	require.False(t, sa.hookLibraries.allowed("/usr/lib/kea/hooks/libdhcp_ha.so"))
+}
+
 type testCommandExecutor struct{}
 
 // Returns a fixed output and no error for any data. The output contains the
@@ -311,7 +375,7 @@ func TestDetectKeaApp(t *testing.T) {
 		require.Empty(t, ctrlPoint.Key)
 	}
 
-	httpClient := NewHTTPClient(false)
+	httpClient := NewHTTPClient(false, HTTPClientProxySettings{})
 
 	t.Run("config file without include statement", func(t *testing.T) {
 		tmpFilePath, clean := makeKeaConfFile()
@@ -480,7 +544,7 @@ func TestDetectConfiguredDaemons(t *testing.T) {
 	configPath, clean := makeKeaConfFile()
 	defer clean()
 
-	httpClient := NewHTTPClient(false)
+	httpClient := NewHTTPClient(false, HTTPClientProxySettings{})
 
 	// Act
 	app := detectKeaApp([]string{"", "", configPath}, "", httpClient)
@@ -507,7 +571,7 @@ func TestDetectConfiguredSingleDaemon(t *testing.T) {
 		}
 	} }`)
 
-	httpClient := NewHTTPClient(false)
+	httpClient := NewHTTPClient(false, HTTPClientProxySettings{})
 
 	// Act
 	app := detectKeaApp([]string{"", "", configPath}, "", httpClient)
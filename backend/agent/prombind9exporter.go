@@ -83,7 +83,7 @@ func NewPromBind9Exporter(settings *cli.Context, appMonitor AppMonitor) *PromBin
 	pbe := &PromBind9Exporter{
 		Settings:   settings,
 		AppMonitor: appMonitor,
-		HTTPClient: NewHTTPClient(settings.Bool("skip-tls-cert-verification")),
+		HTTPClient: NewHTTPClient(settings.Bool("skip-tls-cert-verification"), NewHTTPClientProxySettings(settings)),
 		Registry:   prometheus.NewRegistry(),
 	}
 
@@ -347,7 +347,7 @@ func NewPromKeaExporter(settings *cli.Context, appMonitor AppMonitor) *PromKeaEx
 	pke := &PromKeaExporter{
 		Settings:       settings,
 		AppMonitor:     appMonitor,
-		HTTPClient:     NewHTTPClient(settings.Bool("skip-tls-cert-verification")),
+		HTTPClient:     NewHTTPClient(settings.Bool("skip-tls-cert-verification"), NewHTTPClientProxySettings(settings)),
 		DoneCollector:  make(chan bool),
 		Wg:             &sync.WaitGroup{},
 		Registry:       prometheus.NewRegistry(),
@@ -30,10 +30,12 @@ type PoolParameters struct {
 
 // Represents an address pool structure within a Kea configuration.
 type Pool struct {
-	ClientClass          string             `json:"client-class,omitempty"`
-	OptionData           []SingleOptionData `json:"option-data,omitempty"`
-	Pool                 string             `json:"pool"`
-	RequireClientClasses []string           `json:"require-client-classes,omitempty"`
+	ClientClass               string             `json:"client-class,omitempty"`
+	OptionData                []SingleOptionData `json:"option-data,omitempty"`
+	Pool                      string             `json:"pool"`
+	RequireClientClasses      []string           `json:"require-client-classes,omitempty"`
+	ClientClasses             []string           `json:"client-classes,omitempty"`
+	EvaluateAdditionalClasses []string           `json:"evaluate-additional-classes,omitempty"`
 }
 
 // A custom unmarshal function for a Kea address pool. It removes whitespaces from
@@ -1,9 +1,107 @@
 package keaconfig
 
+import "regexp"
+
+// Matches the references to other client classes in the test expressions,
+// e.g., member('foo').
+var clientClassMemberPattern = regexp.MustCompile(`member\(\s*'([^']+)'\s*\)`)
+
 // Represents a client class in Kea configuration.
-// todo: it currently only contains class name because it is
-// all we need for current use cases. It will have extra fields
-// when we need them.
+// todo: it currently only contains the class name, test expression,
+// options and the parameters assigned to the class members because it
+// is all we need for current use cases. It will have extra fields when
+// we need them.
 type ClientClass struct {
-	Name string `json:"name"`
+	Name              string             `json:"name"`
+	Test              string             `json:"test,omitempty"`
+	OptionData        []SingleOptionData `json:"option-data,omitempty"`
+	NextServer        *string            `json:"next-server,omitempty"`
+	ServerHostname    *string            `json:"server-hostname,omitempty"`
+	BootFileName      *string            `json:"boot-file-name,omitempty"`
+	ValidLifetime     *int64             `json:"valid-lifetime,omitempty"`
+	PreferredLifetime *int64             `json:"preferred-lifetime,omitempty"`
+}
+
+// Returns the names of the client classes referenced in the class test
+// expression using the member operator.
+func (c ClientClass) GetReferencedClientClasses() (names []string) {
+	for _, match := range clientClassMemberPattern.FindAllStringSubmatch(c.Test, -1) {
+		names = append(names, match[1])
+	}
+	return
+}
+
+// Checks if the class assigns any parameters or options to its members.
+// Such a class is used even if it is not referenced anywhere.
+func (c ClientClass) hasMemberParameters() bool {
+	return len(c.OptionData) > 0 || c.NextServer != nil || c.ServerHostname != nil ||
+		c.BootFileName != nil || c.ValidLifetime != nil || c.PreferredLifetime != nil
+}
+
+// Records the client class names referenced by the client-class and
+// require-client-classes parameters and by the client-classes and
+// evaluate-additional-classes lists introduced in Kea 2.7.
+func collectClientClassReferences(references map[string]bool, clientClass *string, classLists ...[]string) {
+	if clientClass != nil && *clientClass != "" {
+		references[*clientClass] = true
+	}
+	for _, classList := range classLists {
+		for _, name := range classList {
+			references[name] = true
+		}
+	}
+}
+
+// Returns the client classes defined in the configuration but never used.
+// The class is used when it is referenced by a subnet, shared network,
+// pool, prefix delegation pool, host reservation or a test expression of
+// another class. The class defining DHCP options or other parameters (e.g.,
+// boot file name or lifetimes) is also used because they are returned to
+// the clients belonging to it. The special DROP
+// class is always used. Note that the classes can be also referenced by
+// the host reservations in the host database and by hook libraries. Such
+// references are not taken into account.
+func (c *Config) GetUnusedClientClasses() (unused []ClientClass) {
+	clientClasses := c.GetClientClasses()
+	if len(clientClasses) == 0 {
+		return
+	}
+
+	references := make(map[string]bool)
+	for _, clientClass := range clientClasses {
+		for _, name := range clientClass.GetReferencedClientClasses() {
+			references[name] = true
+		}
+	}
+	reservations := c.GetReservations()
+	for _, sharedNetwork := range c.GetSharedNetworks(true) {
+		params := sharedNetwork.GetSharedNetworkParameters()
+		collectClientClassReferences(references, params.ClientClass, params.RequireClientClasses,
+			params.ClientClasses, params.EvaluateAdditionalClasses)
+		for _, subnet := range sharedNetwork.GetSubnets() {
+			params := subnet.GetSubnetParameters()
+			collectClientClassReferences(references, params.ClientClass, params.RequireClientClasses,
+				params.ClientClasses, params.EvaluateAdditionalClasses)
+			for _, pool := range subnet.GetPools() {
+				collectClientClassReferences(references, &pool.ClientClass, pool.RequireClientClasses,
+					pool.ClientClasses, pool.EvaluateAdditionalClasses)
+			}
+			for _, pool := range subnet.GetPDPools() {
+				collectClientClassReferences(references, &pool.ClientClass, pool.RequireClientClasses,
+					pool.ClientClasses, pool.EvaluateAdditionalClasses)
+			}
+			reservations = append(reservations, subnet.GetReservations()...)
+		}
+	}
+	for _, reservation := range reservations {
+		collectClientClassReferences(references, nil, reservation.ClientClasses)
+	}
+
+	for _, clientClass := range clientClasses {
+		if clientClass.Name == "DROP" || clientClass.hasMemberParameters() || references[clientClass.Name] {
+			continue
+		}
+		unused = append(unused, clientClass)
+	}
+	return
 }
@@ -0,0 +1,208 @@
+package keaconfig
+
+import (
+	"testing"
+
+	require "github.com/stretchr/testify/require"
+)
+
+// Test that the client classes referenced in the test expression are
+// returned.
+func TestClientClassGetReferencedClientClasses(t *testing.T) {
+	clientClass := ClientClass{
+		Name: "foo",
+		Test: "member('bar') and not member( 'baz' ) or substring(option[61].hex,0,3) == 'foo'",
+	}
+	require.ElementsMatch(t, []string{"bar", "baz"}, clientClass.GetReferencedClientClasses())
+
+	clientClass = ClientClass{
+		Name: "foo",
+		Test: "option[93].hex == 0x0009",
+	}
+	require.Empty(t, clientClass.GetReferencedClientClasses())
+}
+
+// Test that the client classes that are defined but not referenced
+// anywhere in the configuration are returned.
+func TestGetUnusedClientClasses(t *testing.T) {
+	// Arrange
+	cfg, err := NewConfig(`{
+        "Dhcp6": {
+            "client-classes": [
+                { "name": "global-reservation" },
+                { "name": "shared-network" },
+                { "name": "subnet" },
+                { "name": "subnet-required" },
+                { "name": "pool" },
+                { "name": "pd-pool-required" },
+                { "name": "reservation" },
+                { "name": "member", "test": "option[1].hex == 0x01" },
+                { "name": "parent", "test": "member('member')" },
+                {
+                    "name": "options",
+                    "option-data": [
+                        { "name": "dns-servers", "data": "2001:db8:1::1" }
+                    ]
+                },
+                { "name": "DROP", "test": "option[1].hex == 0x02" },
+                { "name": "orphan" },
+                { "name": "orphan-with-test", "test": "option[1].hex == 0x03" }
+            ],
+            "reservations": [
+                { "duid": "01:02:03:04", "client-classes": [ "global-reservation" ] }
+            ],
+            "shared-networks": [
+                {
+                    "name": "foo",
+                    "client-class": "shared-network",
+                    "subnet6": [
+                        {
+                            "id": 1,
+                            "subnet": "2001:db8:1::/64",
+                            "client-class": "subnet",
+                            "require-client-classes": [ "subnet-required" ]
+                        }
+                    ]
+                }
+            ],
+            "subnet6": [
+                {
+                    "id": 2,
+                    "subnet": "2001:db8:2::/64",
+                    "pools": [
+                        { "pool": "2001:db8:2::10-2001:db8:2::20", "client-class": "pool" }
+                    ],
+                    "pd-pools": [
+                        {
+                            "prefix": "3000::",
+                            "prefix-len": 64,
+                            "delegated-len": 96,
+                            "require-client-classes": [ "pd-pool-required" ]
+                        }
+                    ],
+                    "reservations": [
+                        { "duid": "01:02:03:05", "client-classes": [ "reservation", "undefined" ] }
+                    ]
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	// Act
+	unused := cfg.GetUnusedClientClasses()
+
+	// Assert
+	// The parent class references the member class but it is not
+	// referenced itself.
+	require.Len(t, unused, 3)
+	require.Equal(t, "parent", unused[0].Name)
+	require.Equal(t, "orphan", unused[1].Name)
+	require.Equal(t, "orphan-with-test", unused[2].Name)
+}
+
+// Test that the client classes assigning the boot parameters or the
+// lifetimes to their members are considered used.
+func TestGetUnusedClientClassesMemberParameters(t *testing.T) {
+	// Arrange
+	cfg, err := NewConfig(`{
+        "Dhcp4": {
+            "client-classes": [
+                { "name": "next-server", "next-server": "192.0.2.1" },
+                { "name": "server-hostname", "server-hostname": "tftp.example.org" },
+                { "name": "boot-file-name", "boot-file-name": "/boot/pxelinux.0" },
+                { "name": "valid-lifetime", "valid-lifetime": 3600 },
+                { "name": "orphan" }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+	cfg6, err := NewConfig(`{
+        "Dhcp6": {
+            "client-classes": [
+                { "name": "preferred-lifetime", "preferred-lifetime": 1800 },
+                { "name": "orphan" }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	// Act
+	unused := cfg.GetUnusedClientClasses()
+	unused6 := cfg6.GetUnusedClientClasses()
+
+	// Assert
+	require.Len(t, unused, 1)
+	require.Equal(t, "orphan", unused[0].Name)
+	require.Len(t, unused6, 1)
+	require.Equal(t, "orphan", unused6[0].Name)
+}
+
+// Test that the client classes referenced by the client-classes and
+// evaluate-additional-classes lists introduced in Kea 2.7 are used.
+func TestGetUnusedClientClassesKea27References(t *testing.T) {
+	// Arrange
+	cfg, err := NewConfig(`{
+        "Dhcp6": {
+            "client-classes": [
+                { "name": "shared-network" },
+                { "name": "subnet" },
+                { "name": "subnet-additional" },
+                { "name": "pool" },
+                { "name": "pd-pool-additional" },
+                { "name": "orphan" }
+            ],
+            "shared-networks": [
+                {
+                    "name": "foo",
+                    "client-classes": [ "shared-network" ],
+                    "subnet6": [
+                        {
+                            "id": 1,
+                            "subnet": "2001:db8:1::/64",
+                            "client-classes": [ "subnet" ],
+                            "evaluate-additional-classes": [ "subnet-additional" ]
+                        }
+                    ]
+                }
+            ],
+            "subnet6": [
+                {
+                    "id": 2,
+                    "subnet": "2001:db8:2::/64",
+                    "pools": [
+                        { "pool": "2001:db8:2::10-2001:db8:2::20", "client-classes": [ "pool" ] }
+                    ],
+                    "pd-pools": [
+                        {
+                            "prefix": "3000::",
+                            "prefix-len": 64,
+                            "delegated-len": 96,
+                            "evaluate-additional-classes": [ "pd-pool-additional" ]
+                        }
+                    ]
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	// Act
+	unused := cfg.GetUnusedClientClasses()
+
+	// Assert
+	require.Len(t, unused, 1)
+	require.Equal(t, "orphan", unused[0].Name)
+}
+
+// Test that no unused client classes are returned when there are no
+// client classes or the configuration is not a DHCP server configuration.
+func TestGetUnusedClientClassesNoClasses(t *testing.T) {
+	cfg, err := NewConfig(`{ "Dhcp4": { "subnet4": [ { "id": 1, "subnet": "192.0.2.0/24" } ] } }`)
+	require.NoError(t, err)
+	require.Empty(t, cfg.GetUnusedClientClasses())
+
+	cfg, err = NewConfig(`{ "Control-agent": { } }`)
+	require.NoError(t, err)
+	require.Empty(t, cfg.GetUnusedClientClasses())
+}
@@ -12,7 +12,7 @@ type DatabaseConfig interface {
 // Configuration of the Kea configuration backend connections.
 type ConfigControl struct {
 	ConfigDatabases     []Database `json:"config-databases"`
-	ConfigFetchWaitTime int64      `json:"config-wait-fetch-time"`
+	ConfigFetchWaitTime *int64     `json:"config-fetch-wait-time,omitempty"`
 }
 
 // A structure holding all possible database configurations in the Kea
@@ -29,12 +29,14 @@ type Databases struct {
 }
 
 // A structure representing the database connection parameters. It is common
-// for all supported backend types.
+// for all supported backend types. The credentials are deliberately not
+// included to avoid exposing them.
 type Database struct {
 	Path string `json:"path"`
 	Type string `json:"type"`
 	Name string `json:"name"`
 	Host string `json:"host"`
+	Port int64  `json:"port,omitempty"`
 }
 
 // Parses database connection configuration setting the default
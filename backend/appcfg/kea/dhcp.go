@@ -37,6 +37,7 @@ type DHCPv4Config struct {
 	CommonDHCPConfig
 	Authoritative   *bool              `json:"authoritative,omitempty"`
 	BootFileName    *string            `json:"boot-file-name,omitempty"`
+	EchoClientID    *bool              `json:"echo-client-id,omitempty"`
 	MatchClientID   *bool              `json:"match-client-id,omitempty"`
 	NextServer      *string            `json:"next-server,omitempty"`
 	OptionData      []SingleOptionData `json:"option-data,omitempty"`
@@ -66,18 +67,21 @@ type CommonDHCPConfig struct {
 	ReservationParameters
 	TimerParameters
 	ValidLifetimeParameters
-	Allocator         *string         `json:"allocator"`
-	ClientClasses     []ClientClass   `json:"client-classes"`
-	ConfigControl     *ConfigControl  `json:"config-control"`
-	ControlSocket     *ControlSocket  `json:"control-socket"`
-	HostsDatabase     *Database       `json:"hosts-database"`
-	HostsDatabases    []Database      `json:"hosts-databases"`
-	HookLibraries     []HookLibrary   `json:"hooks-libraries"`
-	LeaseDatabase     *Database       `json:"lease-database"`
-	Loggers           []Logger        `json:"loggers"`
-	MultiThreading    *MultiThreading `json:"multi-threading"`
-	Reservations      []Reservation   `json:"reservations"`
-	StoreExtendedInfo *bool           `json:"store-extended-info"`
+	Allocator         *string           `json:"allocator"`
+	ClientClasses     []ClientClass     `json:"client-classes"`
+	ConfigControl     *ConfigControl    `json:"config-control"`
+	ControlSocket     *ControlSocket    `json:"control-socket"`
+	DHCPQueueControl  *DHCPQueueControl `json:"dhcp-queue-control"`
+	HostsDatabase     *Database         `json:"hosts-database"`
+	HostsDatabases    []Database        `json:"hosts-databases"`
+	HookLibraries     []HookLibrary     `json:"hooks-libraries"`
+	InterfacesConfig  *InterfacesConfig `json:"interfaces-config"`
+	LeaseDatabase     *Database         `json:"lease-database"`
+	Loggers           []Logger          `json:"loggers"`
+	MultiThreading    *MultiThreading   `json:"multi-threading"`
+	Reservations      []Reservation     `json:"reservations"`
+	ServerTag         *string           `json:"server-tag"`
+	StoreExtendedInfo *bool             `json:"store-extended-info"`
 }
 
 // Represents the global DHCP multi-threading parameters.
@@ -87,6 +91,25 @@ type MultiThreading struct {
 	PacketQueueSize      *int  `json:"packet-queue-size"`
 }
 
+// Represents the DHCP packet queue parameters. The queue is used to
+// buffer the incoming packets when the server is under heavy load.
+type DHCPQueueControl struct {
+	EnableQueue *bool   `json:"enable-queue"`
+	QueueType   *string `json:"queue-type"`
+	Capacity    *int    `json:"capacity"`
+}
+
+// Represents the interfaces-config parameters selecting the interfaces
+// and the sockets used by the DHCP server to receive and send packets.
+// The DHCPSocketType and OutboundInterface are only supported by the
+// DHCPv4 server.
+type InterfacesConfig struct {
+	Interfaces        []string `json:"interfaces"`
+	ReDetect          *bool    `json:"re-detect"`
+	DHCPSocketType    *string  `json:"dhcp-socket-type"`
+	OutboundInterface *string  `json:"outbound-interface"`
+}
+
 // Unmarshals the DHCPv4 configuration and builds an index of the
 // subnets by prefix.
 func (c *DHCPv4Config) UnmarshalJSON(data []byte) error {
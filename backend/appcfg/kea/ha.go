@@ -18,6 +18,9 @@ type HA struct {
 	MaxUnackedClients *int              `json:"max-unacked-clients"`
 	Peers             []Peer            `json:"peers"`
 	MultiThreading    *HAMultiThreading `json:"multi-threading"`
+	TrustAnchor       *string           `json:"trust-anchor"`
+	CertFile          *string           `json:"cert-file"`
+	KeyFile           *string           `json:"key-file"`
 }
 
 // A structure representing the multi-threading configuration in the
@@ -36,6 +39,9 @@ type Peer struct {
 	URL          *string `json:"url"`
 	Role         *string `json:"role"`
 	AutoFailover *bool   `json:"auto-failover"`
+	TrustAnchor  *string `json:"trust-anchor"`
+	CertFile     *string `json:"cert-file"`
+	KeyFile      *string `json:"key-file"`
 }
 
 // Convenience function returning the first HA configuration. Note that Kea in
@@ -62,6 +68,33 @@ func (c HA) IsValid() bool {
 	return c.ThisServerName != nil && c.Mode != nil
 }
 
+// Checks if the HA peers communicate over the dedicated HTTP listeners
+// opened by the HA hook instead of the Kea Control Agent. It is the case
+// when the multi-threading and the dedicated listener are enabled.
+func (c HA) IsDedicatedListenerEnabled() bool {
+	mt := c.MultiThreading
+	return mt != nil && mt.EnableMultiThreading != nil && *mt.EnableMultiThreading &&
+		mt.HTTPDedicatedListener != nil && *mt.HTTPDedicatedListener
+}
+
+// Checks if the TLS parameters are specified for the HA relationship or for
+// this server's peer. They are used by the dedicated HTTP listener and by
+// the HA client connecting to the peers.
+func (c HA) IsTLSConfigured() bool {
+	isSet := func(value *string) bool {
+		return value != nil && *value != ""
+	}
+	if isSet(c.TrustAnchor) && isSet(c.CertFile) && isSet(c.KeyFile) {
+		return true
+	}
+	for _, p := range c.Peers {
+		if c.ThisServerName != nil && p.Name != nil && *p.Name == *c.ThisServerName {
+			return isSet(p.TrustAnchor) && isSet(p.CertFile) && isSet(p.KeyFile)
+		}
+	}
+	return false
+}
+
 // Checks if the mandatory peer parameters are set. It doesn't check if the
 // values are correct.
 func (p Peer) IsValid() bool {
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	storkutil "isc.org/stork/util"
 )
 
 // Checks if the HA peer structure validation works as expected.
@@ -46,3 +47,53 @@ func TestHAConfigParametersValid(t *testing.T) {
 	cfg.Peers = append(cfg.Peers, p)
 	require.False(t, cfg.IsValid())
 }
+
+// Checks if the dedicated HTTP listener is recognized as enabled only when
+// the multi-threading is enabled as well.
+func TestHAConfigIsDedicatedListenerEnabled(t *testing.T) {
+	cfg := HA{}
+	require.False(t, cfg.IsDedicatedListenerEnabled())
+
+	cfg.MultiThreading = &HAMultiThreading{
+		HTTPDedicatedListener: storkutil.Ptr(true),
+	}
+	require.False(t, cfg.IsDedicatedListenerEnabled())
+
+	cfg.MultiThreading.EnableMultiThreading = storkutil.Ptr(true)
+	require.True(t, cfg.IsDedicatedListenerEnabled())
+
+	cfg.MultiThreading.HTTPDedicatedListener = storkutil.Ptr(false)
+	require.False(t, cfg.IsDedicatedListenerEnabled())
+}
+
+// Checks if the TLS parameters are found for the relationship or for this
+// server's peer.
+func TestHAConfigIsTLSConfigured(t *testing.T) {
+	cfg := HA{
+		ThisServerName: storkutil.Ptr("server1"),
+		Peers: []Peer{
+			{Name: storkutil.Ptr("server1")},
+			{
+				Name:        storkutil.Ptr("server2"),
+				TrustAnchor: storkutil.Ptr("/ca.pem"),
+				CertFile:    storkutil.Ptr("/cert.pem"),
+				KeyFile:     storkutil.Ptr("/key.pem"),
+			},
+		},
+	}
+	// Only the other peer has the TLS parameters.
+	require.False(t, cfg.IsTLSConfigured())
+
+	cfg.Peers[0].TrustAnchor = storkutil.Ptr("/ca.pem")
+	cfg.Peers[0].CertFile = storkutil.Ptr("/cert.pem")
+	require.False(t, cfg.IsTLSConfigured())
+
+	cfg.Peers[0].KeyFile = storkutil.Ptr("/key.pem")
+	require.True(t, cfg.IsTLSConfigured())
+
+	cfg.Peers[0] = Peer{Name: storkutil.Ptr("server1")}
+	cfg.TrustAnchor = storkutil.Ptr("/ca.pem")
+	cfg.CertFile = storkutil.Ptr("/cert.pem")
+	cfg.KeyFile = storkutil.Ptr("/key.pem")
+	require.True(t, cfg.IsTLSConfigured())
+}
@@ -25,18 +25,33 @@ type commonConfigAccessor interface {
 // commands. The other embedded structures hold parsed configurations for
 // the respective daemons. For instance, for the Kea CA configuration the
 // CtrlAgentConfig field is set and the parsed data are returned from this
-// structure. All other structures are nil.
+// structure. All other structures are nil. The LegacyLogging field is only
+// set for the configurations using the legacy structure with the top-level
+// Logging map.
 type Config struct {
 	Raw              RawConfig `json:"-"`
 	*CtrlAgentConfig `json:"Control-agent,omitempty"`
 	*D2Config        `json:"DhcpDdns,omitempty"`
 	*DHCPv4Config    `json:"Dhcp4,omitempty"`
 	*DHCPv6Config    `json:"Dhcp6,omitempty"`
+	LegacyLogging    *LegacyLoggingConfig `json:"Logging,omitempty"`
 }
 
 // Raw configuration type for a Kea server.
 type RawConfig map[string]any
 
+// Variant of the Kea configuration structure.
+type ConfigStructure string
+
+const (
+	// The loggers are specified within the daemon's configuration.
+	ConfigStructureCurrent ConfigStructure = "current"
+	// The loggers are specified in the top-level Logging map next to
+	// the daemon's configuration. This structure was used by the older
+	// Kea versions.
+	ConfigStructureLegacy ConfigStructure = "legacy"
+)
+
 // Convenience function used by different exported functions returning
 // an interface to the DHCP-specific data.
 func (c *Config) getDHCPConfigAccessor() dhcpConfigAccessor {
@@ -147,6 +162,35 @@ func (c *Config) GetMultiThreading() (mt *MultiThreading) {
 	return
 }
 
+// Returns the server tag used by a DHCP server to select its configuration
+// from the configuration backend. It returns an empty string when the
+// server tag is not specified or the configuration is not associated with
+// a DHCP server.
+func (c *Config) GetServerTag() (serverTag string) {
+	if accessor := c.getDHCPConfigAccessor(); accessor != nil {
+		if tag := accessor.GetCommonDHCPConfig().ServerTag; tag != nil {
+			serverTag = *tag
+		}
+	}
+	return
+}
+
+// Returns the packet queue configuration for a DHCP server.
+func (c *Config) GetDHCPQueueControl() (queueControl *DHCPQueueControl) {
+	if accessor := c.getDHCPConfigAccessor(); accessor != nil {
+		queueControl = accessor.GetCommonDHCPConfig().DHCPQueueControl
+	}
+	return
+}
+
+// Returns the interfaces configuration for a DHCP server.
+func (c *Config) GetInterfacesConfig() (interfacesConfig *InterfacesConfig) {
+	if accessor := c.getDHCPConfigAccessor(); accessor != nil {
+		interfacesConfig = accessor.GetCommonDHCPConfig().InterfacesConfig
+	}
+	return
+}
+
 // It returns all database backend configurations found in the DHCP configuration.
 // It includes lease-database, host-database or hosts-databases, config-databases
 // and the database used by the Legal Log hooks library. It is safe to call for
@@ -251,6 +295,14 @@ func (c *Config) GetPDAllocator() (allocator *string) {
 	return
 }
 
+// Returns the global DHCP allocator and prefix delegation allocator.
+func (c *Config) GetAllocatorParameters() AllocatorParameters {
+	return AllocatorParameters{
+		Allocator:   c.GetAllocator(),
+		PDAllocator: c.GetPDAllocator(),
+	}
+}
+
 // Returns DHCPv4 authoritative flag.
 func (c *Config) GetAuthoritative() (authoritative *bool) {
 	if c.IsDHCPv4() {
@@ -275,6 +327,22 @@ func (c *Config) GetMatchClientID() (matchClientID *bool) {
 	return
 }
 
+// Returns DHCPv4 echo client ID.
+func (c *Config) GetEchoClientID() (echoClientID *bool) {
+	if c.IsDHCPv4() {
+		echoClientID = c.DHCPv4Config.EchoClientID
+	}
+	return
+}
+
+// Returns the global DHCPv4 client identification parameters.
+func (c *Config) GetClientIdentificationParameters() ClientIdentificationParameters {
+	return ClientIdentificationParameters{
+		MatchClientID: c.GetMatchClientID(),
+		EchoClientID:  c.GetEchoClientID(),
+	}
+}
+
 // Returns DHCPv4 next server.
 func (c *Config) GetNextServer() (nextServer *string) {
 	if c.IsDHCPv4() {
@@ -291,6 +359,16 @@ func (c *Config) GetServerHostname() (serverHostname *string) {
 	return
 }
 
+// Returns the global DHCPv4 boot parameters and the authoritative flag.
+func (c *Config) GetBootParameters() BootParameters {
+	return BootParameters{
+		Authoritative:  c.GetAuthoritative(),
+		BootFileName:   c.GetBootFileName(),
+		NextServer:     c.GetNextServer(),
+		ServerHostname: c.GetServerHostname(),
+	}
+}
+
 // Returns DHCPv6 rapid commit flag.
 func (c *Config) GetRapidCommit() (rapidCommit *bool) {
 	if c.IsDHCPv6() {
@@ -335,11 +413,37 @@ func (c *Config) GetHookLibrary(name string) (path string, params map[string]any
 	return
 }
 
-// Returns configured loggers.
+// Returns the identifier expression configured in the flex_id hook library.
+// The expression computes the flex-id identifier values matched against
+// the host reservations. The ok flag is false if the hook library is not
+// configured or it lacks the expression.
+func (c *Config) GetFlexIDIdentifierExpression() (expression string, ok bool) {
+	_, params, present := c.GetHookLibrary("libdhcp_flex_id")
+	if !present {
+		return
+	}
+	expression, ok = params["identifier-expression"].(string)
+	return
+}
+
+// Returns the variant of the configuration structure.
+func (c *Config) GetConfigStructure() ConfigStructure {
+	if c.LegacyLogging != nil {
+		return ConfigStructureLegacy
+	}
+	return ConfigStructureCurrent
+}
+
+// Returns configured loggers. If the loggers are not specified within the
+// daemon's configuration, the loggers from the legacy top-level Logging
+// map are returned.
 func (c *Config) GetLoggers() (loggers []Logger) {
 	if accessor := c.getCommonConfigAccessor(); accessor != nil {
 		loggers = accessor.GetLoggers()
 	}
+	if len(loggers) == 0 && c.LegacyLogging != nil {
+		loggers = c.LegacyLogging.Loggers
+	}
 	return
 }
 
@@ -374,6 +478,15 @@ func (c *Config) GetSubnets() (subnets []Subnet) {
 	return
 }
 
+// Returns the configuration backend settings for a DHCP server or nil
+// if they are not specified.
+func (c *Config) GetConfigControl() (configControl *ConfigControl) {
+	if accessor := c.getDHCPConfigAccessor(); accessor != nil {
+		configControl = accessor.GetCommonDHCPConfig().ConfigControl
+	}
+	return
+}
+
 // Returns DHCP extended info flag.
 func (c *Config) GetStoreExtendedInfo() (storeExtendedInfo *bool) {
 	if accessor := c.getDHCPConfigAccessor(); accessor != nil {
@@ -348,6 +348,58 @@ func TestGetLoggers(t *testing.T) {
 	require.Equal(t, 99, loggers[1].DebugLevel)
 }
 
+// Test that the new-style configuration with the loggers specified within
+// the daemon's configuration is detected.
+func TestGetConfigStructureCurrent(t *testing.T) {
+	// Arrange
+	cfg := getTestConfigWithLoggers(t, "Dhcp4")
+
+	// Act
+	structure := cfg.GetConfigStructure()
+
+	// Assert
+	require.Equal(t, ConfigStructureCurrent, structure)
+	require.Nil(t, cfg.LegacyLogging)
+}
+
+// Test that the old-style configuration with the top-level Logging map is
+// detected and that the loggers are returned from this map.
+func TestGetConfigStructureLegacy(t *testing.T) {
+	// Arrange
+	cfg, err := NewConfig(`{
+		"Dhcp6": {
+			"subnet6": []
+		},
+		"Logging": {
+			"loggers": [
+				{
+					"name": "kea-dhcp6",
+					"output_options": [
+						{
+							"output": "/var/log/kea-dhcp6.log"
+						}
+					],
+					"severity": "INFO"
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+
+	// Act
+	structure := cfg.GetConfigStructure()
+	loggers := cfg.GetLoggers()
+
+	// Assert
+	require.Equal(t, ConfigStructureLegacy, structure)
+	require.True(t, cfg.IsDHCPv6())
+	require.Len(t, loggers, 1)
+	require.Equal(t, "kea-dhcp6", loggers[0].Name)
+	require.Equal(t, "INFO", loggers[0].Severity)
+	require.Len(t, loggers[0].OutputOptions, 1)
+	require.Equal(t, "/var/log/kea-dhcp6.log", loggers[0].OutputOptions[0].Output)
+}
+
 // Verifies that a list of loggers is parsed correctly for a daemon.
 func TestGetControlSockets(t *testing.T) {
 	configStr := `{
@@ -462,6 +514,67 @@ func TestConfiguredDaemonNames(t *testing.T) {
 	require.Contains(t, names, "d2")
 }
 
+// Test that the configuration backend settings are parsed and returned
+// without the credentials.
+func TestGetConfigControl(t *testing.T) {
+	configStr := `{
+        "Dhcp4": {
+            "config-control": {
+                "config-databases": [
+                    {
+                        "type": "mysql",
+                        "name": "kea-cb",
+                        "host": "cb.example.org",
+                        "port": 3306,
+                        "user": "kea",
+                        "password": "secret"
+                    },
+                    {
+                        "type": "postgresql",
+                        "name": "kea-cb-pgsql"
+                    }
+                ],
+                "config-fetch-wait-time": 20
+            }
+        }
+    }`
+	cfg, err := NewConfig(configStr)
+	require.NoError(t, err)
+
+	configControl := cfg.GetConfigControl()
+	require.NotNil(t, configControl)
+	require.NotNil(t, configControl.ConfigFetchWaitTime)
+	require.EqualValues(t, 20, *configControl.ConfigFetchWaitTime)
+	require.Len(t, configControl.ConfigDatabases, 2)
+
+	require.Equal(t, "mysql", configControl.ConfigDatabases[0].Type)
+	require.Equal(t, "kea-cb", configControl.ConfigDatabases[0].Name)
+	require.Equal(t, "cb.example.org", configControl.ConfigDatabases[0].Host)
+	require.EqualValues(t, 3306, configControl.ConfigDatabases[0].Port)
+
+	require.Equal(t, "postgresql", configControl.ConfigDatabases[1].Type)
+	require.Equal(t, "kea-cb-pgsql", configControl.ConfigDatabases[1].Name)
+	require.Equal(t, "localhost", configControl.ConfigDatabases[1].Host)
+	require.Zero(t, configControl.ConfigDatabases[1].Port)
+
+	// The credentials must not be retained.
+	serialized, err := json.Marshal(configControl)
+	require.NoError(t, err)
+	require.NotContains(t, string(serialized), "secret")
+	require.NotContains(t, string(serialized), "password")
+}
+
+// Test that nil is returned when the configuration backend is not used.
+func TestGetConfigControlNotSpecified(t *testing.T) {
+	cfg, err := NewConfig(`{"Dhcp6": {}}`)
+	require.NoError(t, err)
+	require.Nil(t, cfg.GetConfigControl())
+
+	cfg, err = NewConfig(`{"Control-agent": {}}`)
+	require.NoError(t, err)
+	require.Nil(t, cfg.GetConfigControl())
+}
+
 // Test that all database connections configurations are parsed and returned
 // correctly: lease-database, hosts-database, hosts-databases, config-databases
 // and forensic logging config.
@@ -952,6 +1065,37 @@ func TestGetMatchClientIDUnsupported(t *testing.T) {
 	require.Nil(t, cfg.GetMatchClientID())
 }
 
+// Test that the echo-client-id parameter is parsed and returned correctly.
+func TestGetEchoClientID(t *testing.T) {
+	configStr := `{
+        "Dhcp4": {
+            "echo-client-id": false
+        }
+    }`
+
+	cfg, err := NewConfig(configStr)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	require.NotNil(t, cfg.GetEchoClientID())
+	require.False(t, *cfg.GetEchoClientID())
+}
+
+// Test that the echo-client-id parameter is ignored for DHCPv6.
+func TestGetEchoClientIDUnsupported(t *testing.T) {
+	configStr := `{
+        "Dhcp6": {
+            "echo-client-id": false
+        }
+    }`
+
+	cfg, err := NewConfig(configStr)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	require.Nil(t, cfg.GetEchoClientID())
+}
+
 // Test that the next-server parameter is parsed and returned correctly.
 func TestGetNextServer(t *testing.T) {
 	configStr := `{
@@ -1257,6 +1401,26 @@ func TestIsInAnyReservationModes(t *testing.T) {
 	}, modes[0], modes[0]))
 }
 
+// Test that the effective host reservation mode is taken from the lowest
+// level at which it is explicitly specified.
+func TestGetEffectiveReservationMode(t *testing.T) {
+	enabled := true
+	disabled := false
+	unspecified := ReservationParameters{}
+	modeEnabled := ReservationParameters{ReservationsInSubnet: &enabled}
+	modeDisabled := ReservationParameters{ReservationsInSubnet: &disabled}
+	condition := func(modes ReservationParameters) (bool, bool) {
+		return modes.IsInSubnet()
+	}
+
+	require.False(t, GetEffectiveReservationMode(condition, modeDisabled, unspecified, modeEnabled))
+	require.True(t, GetEffectiveReservationMode(condition, unspecified, modeEnabled, modeDisabled))
+	require.False(t, GetEffectiveReservationMode(condition, unspecified, unspecified, modeDisabled))
+	// The in-subnet mode is enabled by default.
+	require.True(t, GetEffectiveReservationMode(condition, unspecified, unspecified, unspecified))
+	require.False(t, GetEffectiveReservationMode(condition))
+}
+
 // Test that the store-extended-info parameter is parsed and returned correctly.
 func TestStoreExtendedInfo(t *testing.T) {
 	configStr := `{
@@ -1384,6 +1548,25 @@ func TestGetLocalIPv6SubnetID(t *testing.T) {
 	require.Nil(t, cfg.GetSubnetByPrefix("2001:db8:4::/64"))
 }
 
+// Test that the server tag is returned for the DHCP servers.
+func TestGetServerTag(t *testing.T) {
+	// Arrange
+	config4, err := NewConfig(`{ "Dhcp4": { "server-tag": "server1" } }`)
+	require.NoError(t, err)
+	config6, err := NewConfig(`{ "Dhcp6": { "server-tag": "server2" } }`)
+	require.NoError(t, err)
+	configNoTag, err := NewConfig(`{ "Dhcp4": { } }`)
+	require.NoError(t, err)
+	configD2, err := NewConfig(`{ "DhcpDdns": { } }`)
+	require.NoError(t, err)
+
+	// Act & Assert
+	require.Equal(t, "server1", config4.GetServerTag())
+	require.Equal(t, "server2", config6.GetServerTag())
+	require.Empty(t, configNoTag.GetServerTag())
+	require.Empty(t, configD2.GetServerTag())
+}
+
 // Test that the top-level multi-threading parameters are returned properly.
 func TestGetMultiThreadingEntry(t *testing.T) {
 	// Arrange
@@ -1444,6 +1627,202 @@ func TestGetMultiThreadingEntryNotExists(t *testing.T) {
 	require.Nil(t, multiThreading)
 }
 
+// Test that the enabled packet queue configuration is returned.
+func TestGetDHCPQueueControlEnabled(t *testing.T) {
+	// Arrange
+	configStr := `{
+		"Dhcp4": {
+			"dhcp-queue-control": {
+				"enable-queue": true,
+				"queue-type": "kea-ring4",
+				"capacity": 250
+			}
+		}
+	}`
+	config, _ := NewConfig(configStr)
+
+	// Act
+	queueControl := config.GetDHCPQueueControl()
+
+	// Assert
+	require.NotNil(t, queueControl)
+	require.NotNil(t, queueControl.EnableQueue)
+	require.True(t, *queueControl.EnableQueue)
+	require.NotNil(t, queueControl.QueueType)
+	require.EqualValues(t, "kea-ring4", *queueControl.QueueType)
+	require.NotNil(t, queueControl.Capacity)
+	require.EqualValues(t, 250, *queueControl.Capacity)
+}
+
+// Test that the disabled packet queue configuration is returned and the
+// missing parameters are nil.
+func TestGetDHCPQueueControlDisabled(t *testing.T) {
+	// Arrange
+	configStr := `{
+		"Dhcp6": {
+			"dhcp-queue-control": {
+				"enable-queue": false
+			}
+		}
+	}`
+	config, _ := NewConfig(configStr)
+
+	// Act
+	queueControl := config.GetDHCPQueueControl()
+
+	// Assert
+	require.NotNil(t, queueControl)
+	require.NotNil(t, queueControl.EnableQueue)
+	require.False(t, *queueControl.EnableQueue)
+	require.Nil(t, queueControl.QueueType)
+	require.Nil(t, queueControl.Capacity)
+}
+
+// Test that nil is returned when the packet queue configuration is missing.
+func TestGetDHCPQueueControlNotExists(t *testing.T) {
+	// Arrange
+	configStr := `{ "Dhcp4": { } }`
+	config, _ := NewConfig(configStr)
+
+	// Act
+	queueControl := config.GetDHCPQueueControl()
+
+	// Assert
+	require.Nil(t, queueControl)
+}
+
+// Test that nil is returned for the packet queue configuration of a
+// non-DHCP daemon.
+func TestGetDHCPQueueControlNonDHCPDaemon(t *testing.T) {
+	// Arrange
+	configStr := `{ "Control-agent": { } }`
+	config, _ := NewConfig(configStr)
+
+	// Act
+	queueControl := config.GetDHCPQueueControl()
+
+	// Assert
+	require.Nil(t, queueControl)
+}
+
+// Test that the interfaces configuration with the socket settings is
+// returned for the DHCPv4 server.
+func TestGetInterfacesConfig4(t *testing.T) {
+	// Arrange
+	configStr := `{
+		"Dhcp4": {
+			"interfaces-config": {
+				"interfaces": [ "eth0", "eth1/192.0.2.1" ],
+				"re-detect": false,
+				"dhcp-socket-type": "udp",
+				"outbound-interface": "use-routing"
+			}
+		}
+	}`
+	config, _ := NewConfig(configStr)
+
+	// Act
+	interfacesConfig := config.GetInterfacesConfig()
+
+	// Assert
+	require.NotNil(t, interfacesConfig)
+	require.Equal(t, []string{"eth0", "eth1/192.0.2.1"}, interfacesConfig.Interfaces)
+	require.NotNil(t, interfacesConfig.ReDetect)
+	require.False(t, *interfacesConfig.ReDetect)
+	require.NotNil(t, interfacesConfig.DHCPSocketType)
+	require.Equal(t, "udp", *interfacesConfig.DHCPSocketType)
+	require.NotNil(t, interfacesConfig.OutboundInterface)
+	require.Equal(t, "use-routing", *interfacesConfig.OutboundInterface)
+}
+
+// Test that the interfaces configuration of the DHCPv6 server is returned
+// and the parameters not supported by this server are nil.
+func TestGetInterfacesConfig6(t *testing.T) {
+	// Arrange
+	configStr := `{
+		"Dhcp6": {
+			"interfaces-config": {
+				"interfaces": [ "*" ],
+				"re-detect": true
+			}
+		}
+	}`
+	config, _ := NewConfig(configStr)
+
+	// Act
+	interfacesConfig := config.GetInterfacesConfig()
+
+	// Assert
+	require.NotNil(t, interfacesConfig)
+	require.Equal(t, []string{"*"}, interfacesConfig.Interfaces)
+	require.NotNil(t, interfacesConfig.ReDetect)
+	require.True(t, *interfacesConfig.ReDetect)
+	require.Nil(t, interfacesConfig.DHCPSocketType)
+	require.Nil(t, interfacesConfig.OutboundInterface)
+}
+
+// Test that nil is returned when the interfaces configuration is missing
+// or the daemon is not a DHCP server.
+func TestGetInterfacesConfigNotExists(t *testing.T) {
+	// Arrange
+	config4, _ := NewConfig(`{ "Dhcp4": { } }`)
+	configCA, _ := NewConfig(`{ "Control-agent": { } }`)
+
+	// Act & Assert
+	require.Nil(t, config4.GetInterfacesConfig())
+	require.Nil(t, configCA.GetInterfacesConfig())
+}
+
+// Test that the identifier expression of the flex_id hook library is
+// returned.
+func TestGetFlexIDIdentifierExpression(t *testing.T) {
+	// Arrange
+	config, _ := NewConfig(`{
+		"Dhcp4": {
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/hooks/libdhcp_flex_id.so",
+					"parameters": {
+						"identifier-expression": "relay4[2].hex",
+						"replace-client-id": false
+					}
+				}
+			]
+		}
+	}`)
+
+	// Act
+	expression, ok := config.GetFlexIDIdentifierExpression()
+
+	// Assert
+	require.True(t, ok)
+	require.Equal(t, "relay4[2].hex", expression)
+}
+
+// Test that no identifier expression is returned when the flex_id hook
+// library is not configured or lacks the expression.
+func TestGetFlexIDIdentifierExpressionNotExists(t *testing.T) {
+	// Arrange
+	configNoHook, _ := NewConfig(`{ "Dhcp4": { } }`)
+	configNoExpression, _ := NewConfig(`{
+		"Dhcp6": {
+			"hooks-libraries": [
+				{ "library": "/usr/lib/kea/hooks/libdhcp_flex_id.so" }
+			]
+		}
+	}`)
+
+	// Act
+	expression1, ok1 := configNoHook.GetFlexIDIdentifierExpression()
+	expression2, ok2 := configNoExpression.GetFlexIDIdentifierExpression()
+
+	// Assert
+	require.False(t, ok1)
+	require.Empty(t, expression1)
+	require.False(t, ok2)
+	require.Empty(t, expression2)
+}
+
 // Test getting all shared networks from the DHCPv4 config.
 func TestGetSharedNetworks4(t *testing.T) {
 	cfg := getTestConfigWithIPv4Subnets(t)
@@ -8,6 +8,12 @@ type Logger struct {
 	DebugLevel    int                   `json:"debuglevel"`
 }
 
+// A structure representing the top-level Logging map used in the legacy
+// configuration structure.
+type LegacyLoggingConfig struct {
+	Loggers []Logger `json:"loggers"`
+}
+
 // A structure representing output_options for a logger.
 type LoggerOutputOptions struct {
 	Output string `json:"output"`
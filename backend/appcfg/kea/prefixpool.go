@@ -14,7 +14,7 @@ type PrefixPool interface {
 
 // Represents prefix delegation pool structure within Kea configuration.
 type PDPool struct {
-	Prefix               string
+	Prefix               string             `json:"prefix"`
 	PrefixLen            int                `json:"prefix-len"`
 	DelegatedLen         int                `json:"delegated-len"`
 	ExcludedPrefix       string             `json:"excluded-prefix,omitempty"`
@@ -22,6 +22,10 @@ type PDPool struct {
 	ClientClass          string             `json:"client-class,omitempty"`
 	RequireClientClasses []string           `json:"require-client-classes,omitempty"`
 	OptionData           []SingleOptionData `json:"option-data,omitempty"`
+	// The lists of client classes replacing the client-class and
+	// require-client-classes parameters since Kea 2.7.
+	ClientClasses             []string `json:"client-classes,omitempty"`
+	EvaluateAdditionalClasses []string `json:"evaluate-additional-classes,omitempty"`
 }
 
 // Returns a delegated prefix pool in a canonical form.
@@ -1,6 +1,9 @@
 package keaconfig
 
 import (
+	"encoding/hex"
+	"strings"
+
 	"github.com/pkg/errors"
 	dhcpmodel "isc.org/stork/datamodel/dhcp"
 	storkutil "isc.org/stork/util"
@@ -70,6 +73,27 @@ type ReservationModes struct {
 	Deprecated *string `json:"reservation-mode,omitempty"`
 }
 
+// Parses the host identifier value specified in a Kea reservation. The
+// value is a string of hexadecimal digits, optionally separated with colons
+// (e.g., 01:02:03), or a text in single quotes (e.g., 'foo'). The text
+// form is typically used by the flex-id reservations matching the values
+// computed by the flex_id hook library's identifier expression.
+func ParseHostIdentifier(value string) ([]byte, error) {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") {
+		text := value[1 : len(value)-1]
+		if len(text) == 0 {
+			return nil, errors.New("host identifier text must not be empty")
+		}
+		return []byte(text), nil
+	}
+	decoded, err := hex.DecodeString(strings.ReplaceAll(value, ":", ""))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid host identifier %s", value)
+	}
+	return decoded, nil
+}
+
 // Converts a host representation in Stork to Kea host reservation format used
 // in Kea configuration. The lookup interface must not be nil.
 func CreateReservation(daemonID int64, lookup DHCPOptionDefinitionLookup, host HostAccessor) (*Reservation, error) {
@@ -246,3 +270,21 @@ func IsInAnyReservationModes(condition func(modes ReservationParameters) (bool,
 	}
 	return false
 }
+
+// Returns the host reservation mode effective according to the Kea
+// configuration inheritance scheme. The reservation modes should be
+// ordered from the lowest to the highest configuration level. The value
+// is taken from the lowest level at which the mode has been explicitly
+// specified. If it has not been specified at any level, the default value
+// returned by the condition function for the last level is used. Unlike
+// IsInAnyReservationModes, the mode explicitly disabled at the lower level
+// overrides the mode enabled at the higher level.
+func GetEffectiveReservationMode(condition func(modes ReservationParameters) (bool, bool), modes ...ReservationParameters) bool {
+	for i, mode := range modes {
+		cond, explicit := condition(mode)
+		if explicit || i >= len(modes)-1 {
+			return cond
+		}
+	}
+	return false
+}
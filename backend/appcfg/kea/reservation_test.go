@@ -229,3 +229,39 @@ func TestCreateHostCmdsDeletedReservationSubnetIDError(t *testing.T) {
 	require.Error(t, err)
 	require.Nil(t, reservation)
 }
+
+// Test parsing the host identifiers specified in the hexadecimal and the
+// quoted text forms.
+func TestParseHostIdentifier(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected []byte
+	}{
+		{"01:02:03:04:05:06", []byte{1, 2, 3, 4, 5, 6}},
+		{"010203", []byte{1, 2, 3}},
+		{" 0a:0b ", []byte{0xa, 0xb}},
+		{"'foo'", []byte("foo")},
+		{"'s0mEVaLue'", []byte("s0mEVaLue")},
+		{"'a:b'", []byte("a:b")},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.value, func(t *testing.T) {
+			value, err := keaconfig.ParseHostIdentifier(testCase.value)
+			require.NoError(t, err)
+			require.Equal(t, testCase.expected, value)
+		})
+	}
+}
+
+// Test that parsing the invalid host identifiers fails.
+func TestParseInvalidHostIdentifier(t *testing.T) {
+	for _, value := range []string{"foo", "01:0", "''", "'foo"} {
+		value := value
+		t.Run(value, func(t *testing.T) {
+			parsed, err := keaconfig.ParseHostIdentifier(value)
+			require.Error(t, err)
+			require.Nil(t, parsed)
+		})
+	}
+}
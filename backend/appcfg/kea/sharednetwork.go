@@ -70,6 +70,32 @@ type SharedNetworkParameters struct {
 	StoreExtendedInfo *bool
 }
 
+// Returns the boot parameters specified for the shared network.
+func (p *SharedNetworkParameters) GetBootParameters() BootParameters {
+	return BootParameters{
+		Authoritative:  p.Authoritative,
+		BootFileName:   p.BootFileName,
+		NextServer:     p.NextServer,
+		ServerHostname: p.ServerHostname,
+	}
+}
+
+// Returns the allocators specified for the shared network.
+func (p *SharedNetworkParameters) GetAllocatorParameters() AllocatorParameters {
+	return AllocatorParameters{
+		Allocator:   p.Allocator,
+		PDAllocator: p.PDAllocator,
+	}
+}
+
+// Returns the client identification parameters specified for the shared
+// network.
+func (p *SharedNetworkParameters) GetClientIdentificationParameters() ClientIdentificationParameters {
+	return ClientIdentificationParameters{
+		MatchClientID: p.MatchClientID,
+	}
+}
+
 // Represents an IPv4 shared network in Kea.
 type SharedNetwork4 struct {
 	CommonSharedNetworkParameters
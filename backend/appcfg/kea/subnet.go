@@ -51,15 +51,16 @@ type FourOverSixParameters struct {
 
 // Represents DDNS configuration parameters in Kea.
 type DDNSParameters struct {
-	DDNSGeneratedPrefix       *string  `json:"ddns-generated-prefix,omitempty"`
-	DDNSOverrideClientUpdate  *bool    `json:"ddns-override-client-update,omitempty"`
-	DDNSOverrideNoUpdate      *bool    `json:"ddns-override-no-update,omitempty"`
-	DDNSQualifyingSuffix      *string  `json:"ddns-qualifying-suffix,omitempty"`
-	DDNSReplaceClientName     *string  `json:"ddns-replace-client-name,omitempty"`
-	DDNSSendUpdates           *bool    `json:"ddns-send-updates,omitempty"`
-	DDNSUpdateOnRenew         *bool    `json:"ddns-update-on-renew,omitempty"`
-	DDNSUseConflictResolution *bool    `json:"ddns-use-conflict-resolution,omitempty"`
-	DDNSTTLPercent            *float32 `json:"ddns-ttl-percent,omitempty"`
+	DDNSGeneratedPrefix        *string  `json:"ddns-generated-prefix,omitempty"`
+	DDNSOverrideClientUpdate   *bool    `json:"ddns-override-client-update,omitempty"`
+	DDNSOverrideNoUpdate       *bool    `json:"ddns-override-no-update,omitempty"`
+	DDNSQualifyingSuffix       *string  `json:"ddns-qualifying-suffix,omitempty"`
+	DDNSReplaceClientName      *string  `json:"ddns-replace-client-name,omitempty"`
+	DDNSSendUpdates            *bool    `json:"ddns-send-updates,omitempty"`
+	DDNSUpdateOnRenew          *bool    `json:"ddns-update-on-renew,omitempty"`
+	DDNSUseConflictResolution  *bool    `json:"ddns-use-conflict-resolution,omitempty"`
+	DDNSConflictResolutionMode *string  `json:"ddns-conflict-resolution-mode,omitempty"`
+	DDNSTTLPercent             *float32 `json:"ddns-ttl-percent,omitempty"`
 }
 
 // Represents Kea configuration parameters for hostname manipulation.
@@ -95,6 +96,12 @@ type CacheParameters struct {
 type ClientClassParameters struct {
 	ClientClass          *string  `json:"client-class,omitempty"`
 	RequireClientClasses []string `json:"require-client-classes,omitempty"`
+	// The list of client classes replacing the client-class parameter
+	// since Kea 2.7.
+	ClientClasses []string `json:"client-classes,omitempty"`
+	// The list of client classes replacing the require-client-classes
+	// parameter since Kea 2.7.
+	EvaluateAdditionalClasses []string `json:"evaluate-additional-classes,omitempty"`
 }
 
 // Represents valid lifetime configuration parameters in Kea.
@@ -104,6 +111,145 @@ type ValidLifetimeParameters struct {
 	MaxValidLifetime *int64 `json:"max-valid-lifetime,omitempty"`
 }
 
+// Returns the valid lifetime parameters effective for a subnet according
+// to the Kea configuration inheritance scheme. The specified parameters
+// should be ordered from the lowest to the highest configuration level,
+// e.g., subnet-level, shared network-level and global-level parameters.
+// Each returned parameter is taken from the lowest level at which it has
+// been explicitly specified. It is nil when it has not been specified at
+// any level, in which case Kea uses its built-in default.
+func ResolveValidLifetimeParameters(levels ...ValidLifetimeParameters) (parameters ValidLifetimeParameters) {
+	for _, level := range levels {
+		parameters.ValidLifetime = getFirstNonNil(parameters.ValidLifetime, level.ValidLifetime)
+		parameters.MinValidLifetime = getFirstNonNil(parameters.MinValidLifetime, level.MinValidLifetime)
+		parameters.MaxValidLifetime = getFirstNonNil(parameters.MaxValidLifetime, level.MaxValidLifetime)
+	}
+	return
+}
+
+// Returns the DHCP timer parameters effective for a subnet according to the
+// Kea configuration inheritance scheme. The parameters are resolved like in
+// ResolveValidLifetimeParameters.
+func ResolveTimerParameters(levels ...TimerParameters) (parameters TimerParameters) {
+	for _, level := range levels {
+		parameters.RenewTimer = getFirstNonNil(parameters.RenewTimer, level.RenewTimer)
+		parameters.RebindTimer = getFirstNonNil(parameters.RebindTimer, level.RebindTimer)
+		parameters.T1Percent = getFirstNonNil(parameters.T1Percent, level.T1Percent)
+		parameters.T2Percent = getFirstNonNil(parameters.T2Percent, level.T2Percent)
+		parameters.CalculateTeeTimes = getFirstNonNil(parameters.CalculateTeeTimes, level.CalculateTeeTimes)
+	}
+	return
+}
+
+// Returns the DDNS parameters effective for a subnet according to the Kea
+// configuration inheritance scheme. The parameters are resolved like in
+// ResolveValidLifetimeParameters.
+func ResolveDDNSParameters(levels ...DDNSParameters) (parameters DDNSParameters) {
+	for _, level := range levels {
+		parameters.DDNSGeneratedPrefix = getFirstNonNil(parameters.DDNSGeneratedPrefix, level.DDNSGeneratedPrefix)
+		parameters.DDNSOverrideClientUpdate = getFirstNonNil(parameters.DDNSOverrideClientUpdate, level.DDNSOverrideClientUpdate)
+		parameters.DDNSOverrideNoUpdate = getFirstNonNil(parameters.DDNSOverrideNoUpdate, level.DDNSOverrideNoUpdate)
+		parameters.DDNSQualifyingSuffix = getFirstNonNil(parameters.DDNSQualifyingSuffix, level.DDNSQualifyingSuffix)
+		parameters.DDNSReplaceClientName = getFirstNonNil(parameters.DDNSReplaceClientName, level.DDNSReplaceClientName)
+		parameters.DDNSSendUpdates = getFirstNonNil(parameters.DDNSSendUpdates, level.DDNSSendUpdates)
+		parameters.DDNSUpdateOnRenew = getFirstNonNil(parameters.DDNSUpdateOnRenew, level.DDNSUpdateOnRenew)
+		parameters.DDNSUseConflictResolution = getFirstNonNil(parameters.DDNSUseConflictResolution, level.DDNSUseConflictResolution)
+		parameters.DDNSConflictResolutionMode = getFirstNonNil(parameters.DDNSConflictResolutionMode, level.DDNSConflictResolutionMode)
+		parameters.DDNSTTLPercent = getFirstNonNil(parameters.DDNSTTLPercent, level.DDNSTTLPercent)
+	}
+	return
+}
+
+// Returns the hostname sanitization parameters effective for a subnet
+// according to the Kea configuration inheritance scheme. They specify
+// which characters of the hostnames sent by the clients are replaced
+// before the names are registered in DNS. The parameters are resolved
+// like in ResolveValidLifetimeParameters.
+func ResolveHostnameCharParameters(levels ...HostnameCharParameters) (parameters HostnameCharParameters) {
+	for _, level := range levels {
+		parameters.HostnameCharReplacement = getFirstNonNil(parameters.HostnameCharReplacement, level.HostnameCharReplacement)
+		parameters.HostnameCharSet = getFirstNonNil(parameters.HostnameCharSet, level.HostnameCharSet)
+	}
+	return
+}
+
+// Groups the DHCPv4 boot (PXE) related parameters and the authoritative
+// flag. These parameters are specified separately in the Kea configuration
+// and this structure is only used to resolve their effective values.
+type BootParameters struct {
+	Authoritative  *bool
+	BootFileName   *string
+	NextServer     *string
+	ServerHostname *string
+}
+
+// Returns the boot parameters effective for a subnet according to the Kea
+// configuration inheritance scheme. The specified parameters should be
+// ordered from the lowest to the highest configuration level. Each returned
+// parameter is taken from the lowest level at which it has been explicitly
+// specified.
+func ResolveBootParameters(levels ...BootParameters) (parameters BootParameters) {
+	for _, level := range levels {
+		parameters.Authoritative = getFirstNonNil(parameters.Authoritative, level.Authoritative)
+		parameters.BootFileName = getFirstNonNil(parameters.BootFileName, level.BootFileName)
+		parameters.NextServer = getFirstNonNil(parameters.NextServer, level.NextServer)
+		parameters.ServerHostname = getFirstNonNil(parameters.ServerHostname, level.ServerHostname)
+	}
+	return
+}
+
+// Represents the allocation strategies selected in Kea, i.e., iterative,
+// random or flq. The prefix delegation allocator is specific to DHCPv6.
+type AllocatorParameters struct {
+	Allocator   *string
+	PDAllocator *string
+}
+
+// Returns the allocators effective for a subnet according to the Kea
+// configuration inheritance scheme. The parameters are resolved like in
+// ResolveBootParameters. Kea uses the iterative allocator when it is not
+// specified at any level.
+func ResolveAllocatorParameters(levels ...AllocatorParameters) (parameters AllocatorParameters) {
+	for _, level := range levels {
+		parameters.Allocator = getFirstNonNil(parameters.Allocator, level.Allocator)
+		parameters.PDAllocator = getFirstNonNil(parameters.PDAllocator, level.PDAllocator)
+	}
+	return
+}
+
+// Represents the DHCPv4 parameters controlling the client identification,
+// i.e., whether the client identifier is used to match the leases and
+// whether it is echoed back to the client. The echo-client-id is a global
+// parameter in Kea.
+type ClientIdentificationParameters struct {
+	MatchClientID *bool
+	EchoClientID  *bool
+}
+
+// Returns the client identification parameters effective for a subnet
+// according to the Kea configuration inheritance scheme. The parameters
+// are resolved like in ResolveBootParameters. Kea enables both parameters
+// when they are not specified at any level.
+func ResolveClientIdentificationParameters(levels ...ClientIdentificationParameters) (parameters ClientIdentificationParameters) {
+	for _, level := range levels {
+		parameters.MatchClientID = getFirstNonNil(parameters.MatchClientID, level.MatchClientID)
+		parameters.EchoClientID = getFirstNonNil(parameters.EchoClientID, level.EchoClientID)
+	}
+	return
+}
+
+// Returns the first non-nil value from the specified values or nil if
+// all values are nil. It is a convenience function used to resolve the
+// inherited configuration parameters.
+func getFirstNonNil[T any](values ...*T) *T {
+	for _, value := range values {
+		if value != nil {
+			return value
+		}
+	}
+	return nil
+}
+
 // Represents preferred lifetime configuration parameters in Kea.
 type PreferredLifetimeParameters struct {
 	PreferredLifetime    *int64 `json:"preferred-lifetime,omitempty"`
@@ -111,6 +257,26 @@ type PreferredLifetimeParameters struct {
 	MaxPreferredLifetime *int64 `json:"max-preferred-lifetime,omitempty"`
 }
 
+// Returns the DHCPv6 preferred lifetime parameters effective for a subnet
+// according to the Kea configuration inheritance scheme. The parameters are
+// resolved like in ResolveValidLifetimeParameters.
+func ResolvePreferredLifetimeParameters(levels ...PreferredLifetimeParameters) (parameters PreferredLifetimeParameters) {
+	for _, level := range levels {
+		parameters.PreferredLifetime = getFirstNonNil(parameters.PreferredLifetime, level.PreferredLifetime)
+		parameters.MinPreferredLifetime = getFirstNonNil(parameters.MinPreferredLifetime, level.MinPreferredLifetime)
+		parameters.MaxPreferredLifetime = getFirstNonNil(parameters.MaxPreferredLifetime, level.MaxPreferredLifetime)
+	}
+	return
+}
+
+// Returns the DHCPv6 rapid commit flag effective for a subnet according to
+// the Kea configuration inheritance scheme. The specified flags should be
+// ordered from the lowest to the highest configuration level. It returns
+// nil when the flag has not been specified at any level.
+func ResolveRapidCommit(levels ...*bool) *bool {
+	return getFirstNonNil(levels...)
+}
+
 // Represents mandatory subnet configuration parameters in Kea.
 // Note that ID can be left unspecified by the user. In this case
 // it will be auto-generated. So, mandatory means that it is always
@@ -190,6 +356,31 @@ type SubnetParameters struct {
 	StoreExtendedInfo *bool
 }
 
+// Returns the boot parameters specified for the subnet.
+func (p *SubnetParameters) GetBootParameters() BootParameters {
+	return BootParameters{
+		Authoritative:  p.Authoritative,
+		BootFileName:   p.BootFileName,
+		NextServer:     p.NextServer,
+		ServerHostname: p.ServerHostname,
+	}
+}
+
+// Returns the allocators specified for the subnet.
+func (p *SubnetParameters) GetAllocatorParameters() AllocatorParameters {
+	return AllocatorParameters{
+		Allocator:   p.Allocator,
+		PDAllocator: p.PDAllocator,
+	}
+}
+
+// Returns the client identification parameters specified for the subnet.
+func (p *SubnetParameters) GetClientIdentificationParameters() ClientIdentificationParameters {
+	return ClientIdentificationParameters{
+		MatchClientID: p.MatchClientID,
+	}
+}
+
 // Returns a subnet ID.
 func (s MandatorySubnetParameters) GetID() int64 {
 	return s.ID
@@ -371,6 +562,8 @@ func CreateSubnet4(daemonID int64, lookup DHCPOptionDefinitionLookup, subnet Sub
 				keaPool.ClientClass = *params.ClientClass
 			}
 			keaPool.RequireClientClasses = params.RequireClientClasses
+			keaPool.ClientClasses = params.ClientClasses
+			keaPool.EvaluateAdditionalClasses = params.EvaluateAdditionalClasses
 		}
 		// Add the pool to the subnet.
 		subnet4.Pools = append(subnet4.Pools, keaPool)
@@ -440,6 +633,8 @@ func CreateSubnet6(daemonID int64, lookup DHCPOptionDefinitionLookup, subnet Sub
 				keaPool.ClientClass = *params.ClientClass
 			}
 			keaPool.RequireClientClasses = params.RequireClientClasses
+			keaPool.ClientClasses = params.ClientClasses
+			keaPool.EvaluateAdditionalClasses = params.EvaluateAdditionalClasses
 		}
 		// Add the pool to the subnet.
 		subnet6.Pools = append(subnet6.Pools, keaPool)
@@ -479,6 +674,8 @@ func CreateSubnet6(daemonID int64, lookup DHCPOptionDefinitionLookup, subnet Sub
 				keaPool.ClientClass = *params.ClientClass
 			}
 			keaPool.RequireClientClasses = params.RequireClientClasses
+			keaPool.ClientClasses = params.ClientClasses
+			keaPool.EvaluateAdditionalClasses = params.EvaluateAdditionalClasses
 		}
 		// Add the pool to the subnet.
 		subnet6.PDPools = append(subnet6.PDPools, keaPool)
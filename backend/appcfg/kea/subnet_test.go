@@ -898,3 +898,703 @@ func TestCreateSubnet6(t *testing.T) {
 	require.EqualValues(t, 0.44, *subnet6.T2Percent)
 	require.EqualValues(t, 1001, *subnet6.ValidLifetime)
 }
+
+// Test that the valid lifetime bounds are resolved according to the Kea
+// configuration inheritance scheme.
+func TestResolveValidLifetimeParameters(t *testing.T) {
+	configStr := `{
+        "Dhcp4": {
+            "valid-lifetime": 4000,
+            "min-valid-lifetime": 3000,
+            "max-valid-lifetime": 5000,
+            "shared-networks": [
+                {
+                    "name": "foo",
+                    "min-valid-lifetime": 2000,
+                    "subnet4": [
+                        {
+                            "id": 1,
+                            "subnet": "192.0.2.0/24",
+                            "max-valid-lifetime": 6000
+                        }
+                    ]
+                }
+            ],
+            "subnet4": [
+                {
+                    "id": 2,
+                    "subnet": "192.0.3.0/24"
+                }
+            ]
+        }
+    }`
+
+	cfg, err := keaconfig.NewConfig(configStr)
+	require.NoError(t, err)
+
+	sharedNetworks := cfg.GetSharedNetworks(true)
+	require.Len(t, sharedNetworks, 2)
+	global := cfg.GetValidLifetimeParameters()
+
+	// Subnet in the shared network overrides the maximum and inherits the
+	// minimum from the shared network and the default from the global level.
+	network := sharedNetworks[0]
+	require.Len(t, network.GetSubnets(), 1)
+	params := keaconfig.ResolveValidLifetimeParameters(
+		network.GetSubnets()[0].GetSubnetParameters().ValidLifetimeParameters,
+		network.GetSharedNetworkParameters().ValidLifetimeParameters,
+		global,
+	)
+	require.NotNil(t, params.ValidLifetime)
+	require.EqualValues(t, 4000, *params.ValidLifetime)
+	require.NotNil(t, params.MinValidLifetime)
+	require.EqualValues(t, 2000, *params.MinValidLifetime)
+	require.NotNil(t, params.MaxValidLifetime)
+	require.EqualValues(t, 6000, *params.MaxValidLifetime)
+
+	// Top-level subnet inherits all parameters from the global level.
+	network = sharedNetworks[1]
+	require.Len(t, network.GetSubnets(), 1)
+	params = keaconfig.ResolveValidLifetimeParameters(
+		network.GetSubnets()[0].GetSubnetParameters().ValidLifetimeParameters,
+		global,
+	)
+	require.NotNil(t, params.ValidLifetime)
+	require.EqualValues(t, 4000, *params.ValidLifetime)
+	require.NotNil(t, params.MinValidLifetime)
+	require.EqualValues(t, 3000, *params.MinValidLifetime)
+	require.NotNil(t, params.MaxValidLifetime)
+	require.EqualValues(t, 5000, *params.MaxValidLifetime)
+}
+
+// Test that the valid lifetime parameters remain unspecified when they
+// are not specified at any configuration level.
+func TestResolveValidLifetimeParametersUnspecified(t *testing.T) {
+	params := keaconfig.ResolveValidLifetimeParameters(
+		keaconfig.ValidLifetimeParameters{},
+		keaconfig.ValidLifetimeParameters{
+			MaxValidLifetime: ptr[int64](1000),
+		},
+	)
+	require.Nil(t, params.ValidLifetime)
+	require.Nil(t, params.MinValidLifetime)
+	require.NotNil(t, params.MaxValidLifetime)
+	require.EqualValues(t, 1000, *params.MaxValidLifetime)
+
+	params = keaconfig.ResolveValidLifetimeParameters()
+	require.Nil(t, params.ValidLifetime)
+	require.Nil(t, params.MinValidLifetime)
+	require.Nil(t, params.MaxValidLifetime)
+}
+
+// Test that the boot parameters and the authoritative flag are resolved
+// according to the Kea configuration inheritance scheme.
+func TestResolveBootParameters(t *testing.T) {
+	configStr := `{
+        "Dhcp4": {
+            "authoritative": true,
+            "boot-file-name": "/tmp/global-boot",
+            "next-server": "192.0.2.1",
+            "server-hostname": "global.example.org",
+            "shared-networks": [
+                {
+                    "name": "foo",
+                    "next-server": "192.0.2.2",
+                    "subnet4": [
+                        {
+                            "id": 1,
+                            "subnet": "192.0.2.0/24",
+                            "authoritative": false,
+                            "boot-file-name": "/tmp/subnet-boot"
+                        }
+                    ]
+                }
+            ],
+            "subnet4": [
+                {
+                    "id": 2,
+                    "subnet": "192.0.3.0/24",
+                    "server-hostname": "subnet.example.org"
+                }
+            ]
+        }
+    }`
+
+	cfg, err := keaconfig.NewConfig(configStr)
+	require.NoError(t, err)
+
+	sharedNetworks := cfg.GetSharedNetworks(true)
+	require.Len(t, sharedNetworks, 2)
+	global := cfg.GetBootParameters()
+
+	network := sharedNetworks[0]
+	require.Len(t, network.GetSubnets(), 1)
+	params := keaconfig.ResolveBootParameters(
+		network.GetSubnets()[0].GetSubnetParameters().GetBootParameters(),
+		network.GetSharedNetworkParameters().GetBootParameters(),
+		global,
+	)
+	require.NotNil(t, params.Authoritative)
+	require.False(t, *params.Authoritative)
+	require.NotNil(t, params.BootFileName)
+	require.Equal(t, "/tmp/subnet-boot", *params.BootFileName)
+	require.NotNil(t, params.NextServer)
+	require.Equal(t, "192.0.2.2", *params.NextServer)
+	require.NotNil(t, params.ServerHostname)
+	require.Equal(t, "global.example.org", *params.ServerHostname)
+
+	network = sharedNetworks[1]
+	require.Len(t, network.GetSubnets(), 1)
+	params = keaconfig.ResolveBootParameters(
+		network.GetSubnets()[0].GetSubnetParameters().GetBootParameters(),
+		global,
+	)
+	require.NotNil(t, params.Authoritative)
+	require.True(t, *params.Authoritative)
+	require.NotNil(t, params.BootFileName)
+	require.Equal(t, "/tmp/global-boot", *params.BootFileName)
+	require.NotNil(t, params.NextServer)
+	require.Equal(t, "192.0.2.1", *params.NextServer)
+	require.NotNil(t, params.ServerHostname)
+	require.Equal(t, "subnet.example.org", *params.ServerHostname)
+}
+
+// Test that the boot parameters are not resolved for a DHCPv6 server.
+func TestResolveBootParametersDHCPv6(t *testing.T) {
+	cfg, err := keaconfig.NewConfig(`{"Dhcp6": {}}`)
+	require.NoError(t, err)
+
+	params := keaconfig.ResolveBootParameters(cfg.GetBootParameters())
+	require.Nil(t, params.Authoritative)
+	require.Nil(t, params.BootFileName)
+	require.Nil(t, params.NextServer)
+	require.Nil(t, params.ServerHostname)
+}
+
+// Test that the allocators are resolved according to the Kea configuration
+// inheritance scheme.
+func TestResolveAllocatorParameters(t *testing.T) {
+	configStr := `{
+        "Dhcp6": {
+            "allocator": "random",
+            "pd-allocator": "flq",
+            "shared-networks": [
+                {
+                    "name": "foo",
+                    "pd-allocator": "iterative",
+                    "subnet6": [
+                        {
+                            "id": 1,
+                            "subnet": "2001:db8:1::/64",
+                            "allocator": "iterative"
+                        }
+                    ]
+                }
+            ],
+            "subnet6": [
+                {
+                    "id": 2,
+                    "subnet": "2001:db8:2::/64",
+                    "pd-allocator": "random"
+                },
+                {
+                    "id": 3,
+                    "subnet": "2001:db8:3::/64"
+                }
+            ]
+        }
+    }`
+
+	cfg, err := keaconfig.NewConfig(configStr)
+	require.NoError(t, err)
+
+	sharedNetworks := cfg.GetSharedNetworks(true)
+	require.Len(t, sharedNetworks, 2)
+	global := cfg.GetAllocatorParameters()
+
+	// Subnet in the shared network overrides the address allocator and
+	// inherits the prefix delegation allocator from the shared network.
+	network := sharedNetworks[0]
+	require.Len(t, network.GetSubnets(), 1)
+	params := keaconfig.ResolveAllocatorParameters(
+		network.GetSubnets()[0].GetSubnetParameters().GetAllocatorParameters(),
+		network.GetSharedNetworkParameters().GetAllocatorParameters(),
+		global,
+	)
+	require.NotNil(t, params.Allocator)
+	require.Equal(t, "iterative", *params.Allocator)
+	require.NotNil(t, params.PDAllocator)
+	require.Equal(t, "iterative", *params.PDAllocator)
+
+	// Top-level subnets inherit the allocators from the global level
+	// unless they override them.
+	network = sharedNetworks[1]
+	require.Len(t, network.GetSubnets(), 2)
+	params = keaconfig.ResolveAllocatorParameters(
+		network.GetSubnets()[0].GetSubnetParameters().GetAllocatorParameters(),
+		global,
+	)
+	require.NotNil(t, params.Allocator)
+	require.Equal(t, "random", *params.Allocator)
+	require.NotNil(t, params.PDAllocator)
+	require.Equal(t, "random", *params.PDAllocator)
+
+	params = keaconfig.ResolveAllocatorParameters(
+		network.GetSubnets()[1].GetSubnetParameters().GetAllocatorParameters(),
+		global,
+	)
+	require.NotNil(t, params.Allocator)
+	require.Equal(t, "random", *params.Allocator)
+	require.NotNil(t, params.PDAllocator)
+	require.Equal(t, "flq", *params.PDAllocator)
+}
+
+// Test that the allocators remain unspecified when they are not specified
+// at any level and that the prefix delegation allocator is not resolved
+// for a DHCPv4 server.
+func TestResolveAllocatorParametersUnspecified(t *testing.T) {
+	cfg, err := keaconfig.NewConfig(`{"Dhcp4": {}}`)
+	require.NoError(t, err)
+
+	params := keaconfig.ResolveAllocatorParameters(cfg.GetAllocatorParameters())
+	require.Nil(t, params.Allocator)
+	require.Nil(t, params.PDAllocator)
+
+	cfg, err = keaconfig.NewConfig(`{"Dhcp4": {"allocator": "flq", "pd-allocator": "random"}}`)
+	require.NoError(t, err)
+
+	params = keaconfig.ResolveAllocatorParameters(cfg.GetAllocatorParameters())
+	require.NotNil(t, params.Allocator)
+	require.Equal(t, "flq", *params.Allocator)
+	require.Nil(t, params.PDAllocator)
+}
+
+// Test that the client identification parameters are resolved according
+// to the Kea configuration inheritance scheme.
+func TestResolveClientIdentificationParameters(t *testing.T) {
+	configStr := `{
+        "Dhcp4": {
+            "echo-client-id": false,
+            "match-client-id": true,
+            "shared-networks": [
+                {
+                    "name": "foo",
+                    "match-client-id": false,
+                    "subnet4": [
+                        {
+                            "id": 1,
+                            "subnet": "192.0.2.0/24"
+                        },
+                        {
+                            "id": 2,
+                            "subnet": "192.0.3.0/24",
+                            "match-client-id": true
+                        }
+                    ]
+                }
+            ],
+            "subnet4": [
+                {
+                    "id": 3,
+                    "subnet": "192.0.4.0/24"
+                }
+            ]
+        }
+    }`
+
+	cfg, err := keaconfig.NewConfig(configStr)
+	require.NoError(t, err)
+
+	sharedNetworks := cfg.GetSharedNetworks(true)
+	require.Len(t, sharedNetworks, 2)
+	global := cfg.GetClientIdentificationParameters()
+
+	// The first subnet inherits from the shared network, the second one
+	// overrides the shared network setting. The echo-client-id is global.
+	network := sharedNetworks[0]
+	require.Len(t, network.GetSubnets(), 2)
+	params := keaconfig.ResolveClientIdentificationParameters(
+		network.GetSubnets()[0].GetSubnetParameters().GetClientIdentificationParameters(),
+		network.GetSharedNetworkParameters().GetClientIdentificationParameters(),
+		global,
+	)
+	require.NotNil(t, params.MatchClientID)
+	require.False(t, *params.MatchClientID)
+	require.NotNil(t, params.EchoClientID)
+	require.False(t, *params.EchoClientID)
+
+	params = keaconfig.ResolveClientIdentificationParameters(
+		network.GetSubnets()[1].GetSubnetParameters().GetClientIdentificationParameters(),
+		network.GetSharedNetworkParameters().GetClientIdentificationParameters(),
+		global,
+	)
+	require.NotNil(t, params.MatchClientID)
+	require.True(t, *params.MatchClientID)
+	require.NotNil(t, params.EchoClientID)
+	require.False(t, *params.EchoClientID)
+
+	// Top-level subnet inherits from the global level.
+	network = sharedNetworks[1]
+	require.Len(t, network.GetSubnets(), 1)
+	params = keaconfig.ResolveClientIdentificationParameters(
+		network.GetSubnets()[0].GetSubnetParameters().GetClientIdentificationParameters(),
+		global,
+	)
+	require.NotNil(t, params.MatchClientID)
+	require.True(t, *params.MatchClientID)
+	require.NotNil(t, params.EchoClientID)
+	require.False(t, *params.EchoClientID)
+}
+
+// Test that the DHCPv6 preferred lifetime parameters and the rapid commit
+// flag are resolved according to the Kea configuration inheritance scheme.
+func TestResolvePreferredLifetimeParametersAndRapidCommit(t *testing.T) {
+	configStr := `{
+        "Dhcp6": {
+            "preferred-lifetime": 3000,
+            "min-preferred-lifetime": 2000,
+            "max-preferred-lifetime": 4000,
+            "rapid-commit": false,
+            "shared-networks": [
+                {
+                    "name": "foo",
+                    "min-preferred-lifetime": 1000,
+                    "rapid-commit": true,
+                    "subnet6": [
+                        {
+                            "id": 1,
+                            "subnet": "2001:db8:1::/64",
+                            "max-preferred-lifetime": 5000
+                        }
+                    ]
+                }
+            ],
+            "subnet6": [
+                {
+                    "id": 2,
+                    "subnet": "2001:db8:2::/64",
+                    "preferred-lifetime": 3500
+                }
+            ]
+        }
+    }`
+
+	cfg, err := keaconfig.NewConfig(configStr)
+	require.NoError(t, err)
+
+	sharedNetworks := cfg.GetSharedNetworks(true)
+	require.Len(t, sharedNetworks, 2)
+	global := cfg.GetPreferredLifetimeParameters()
+
+	// Subnet in the shared network overrides the maximum and inherits the
+	// minimum and the rapid commit flag from the shared network and the
+	// default from the global level.
+	network := sharedNetworks[0]
+	require.Len(t, network.GetSubnets(), 1)
+	subnetParams := network.GetSubnets()[0].GetSubnetParameters()
+	networkParams := network.GetSharedNetworkParameters()
+	params := keaconfig.ResolvePreferredLifetimeParameters(
+		subnetParams.PreferredLifetimeParameters,
+		networkParams.PreferredLifetimeParameters,
+		global,
+	)
+	require.NotNil(t, params.PreferredLifetime)
+	require.EqualValues(t, 3000, *params.PreferredLifetime)
+	require.NotNil(t, params.MinPreferredLifetime)
+	require.EqualValues(t, 1000, *params.MinPreferredLifetime)
+	require.NotNil(t, params.MaxPreferredLifetime)
+	require.EqualValues(t, 5000, *params.MaxPreferredLifetime)
+
+	rapidCommit := keaconfig.ResolveRapidCommit(subnetParams.RapidCommit, networkParams.RapidCommit, cfg.GetRapidCommit())
+	require.NotNil(t, rapidCommit)
+	require.True(t, *rapidCommit)
+
+	// Top-level subnet overrides the default and inherits the remaining
+	// parameters from the global level.
+	network = sharedNetworks[1]
+	require.Len(t, network.GetSubnets(), 1)
+	subnetParams = network.GetSubnets()[0].GetSubnetParameters()
+	params = keaconfig.ResolvePreferredLifetimeParameters(
+		subnetParams.PreferredLifetimeParameters,
+		global,
+	)
+	require.NotNil(t, params.PreferredLifetime)
+	require.EqualValues(t, 3500, *params.PreferredLifetime)
+	require.NotNil(t, params.MinPreferredLifetime)
+	require.EqualValues(t, 2000, *params.MinPreferredLifetime)
+	require.NotNil(t, params.MaxPreferredLifetime)
+	require.EqualValues(t, 4000, *params.MaxPreferredLifetime)
+
+	rapidCommit = keaconfig.ResolveRapidCommit(subnetParams.RapidCommit, cfg.GetRapidCommit())
+	require.NotNil(t, rapidCommit)
+	require.False(t, *rapidCommit)
+}
+
+// Test that the DHCPv6 preferred lifetime parameters and the rapid commit
+// flag remain unspecified when they are not specified at any level.
+func TestResolvePreferredLifetimeParametersAndRapidCommitUnspecified(t *testing.T) {
+	cfg, err := keaconfig.NewConfig(`{"Dhcp4": {}}`)
+	require.NoError(t, err)
+
+	params := keaconfig.ResolvePreferredLifetimeParameters(cfg.GetPreferredLifetimeParameters())
+	require.Nil(t, params.PreferredLifetime)
+	require.Nil(t, params.MinPreferredLifetime)
+	require.Nil(t, params.MaxPreferredLifetime)
+
+	require.Nil(t, keaconfig.ResolveRapidCommit(cfg.GetRapidCommit()))
+	require.Nil(t, keaconfig.ResolveRapidCommit())
+}
+
+// Test that the DDNS parameters, including the update on renew and the
+// conflict resolution settings, are resolved from the subnet, shared
+// network and global configuration levels.
+func TestResolveDDNSParameters(t *testing.T) {
+	configStr := `{
+        "Dhcp4": {
+            "ddns-update-on-renew": false,
+            "ddns-use-conflict-resolution": true,
+            "ddns-conflict-resolution-mode": "check-with-dhcid",
+            "shared-networks": [
+                {
+                    "name": "foo",
+                    "ddns-update-on-renew": true,
+                    "subnet4": [
+                        {
+                            "id": 1,
+                            "subnet": "192.0.2.0/24",
+                            "ddns-conflict-resolution-mode": "no-check-with-dhcid"
+                        }
+                    ]
+                }
+            ],
+            "subnet4": [
+                {
+                    "id": 2,
+                    "subnet": "192.0.3.0/24",
+                    "ddns-use-conflict-resolution": false
+                }
+            ]
+        }
+    }`
+
+	cfg, err := keaconfig.NewConfig(configStr)
+	require.NoError(t, err)
+
+	sharedNetworks := cfg.GetSharedNetworks(true)
+	require.Len(t, sharedNetworks, 2)
+	global := cfg.GetDDNSParameters()
+
+	// Subnet in the shared network overrides the conflict resolution mode
+	// and inherits the update on renew flag from the shared network and
+	// the conflict resolution flag from the global level.
+	network := sharedNetworks[0]
+	require.Len(t, network.GetSubnets(), 1)
+	params := keaconfig.ResolveDDNSParameters(
+		network.GetSubnets()[0].GetSubnetParameters().DDNSParameters,
+		network.GetSharedNetworkParameters().DDNSParameters,
+		global,
+	)
+	require.NotNil(t, params.DDNSUpdateOnRenew)
+	require.True(t, *params.DDNSUpdateOnRenew)
+	require.NotNil(t, params.DDNSUseConflictResolution)
+	require.True(t, *params.DDNSUseConflictResolution)
+	require.NotNil(t, params.DDNSConflictResolutionMode)
+	require.Equal(t, "no-check-with-dhcid", *params.DDNSConflictResolutionMode)
+
+	// Top-level subnet overrides the conflict resolution flag and inherits
+	// the remaining parameters from the global level.
+	network = sharedNetworks[1]
+	require.Len(t, network.GetSubnets(), 1)
+	params = keaconfig.ResolveDDNSParameters(
+		network.GetSubnets()[0].GetSubnetParameters().DDNSParameters,
+		global,
+	)
+	require.NotNil(t, params.DDNSUpdateOnRenew)
+	require.False(t, *params.DDNSUpdateOnRenew)
+	require.NotNil(t, params.DDNSUseConflictResolution)
+	require.False(t, *params.DDNSUseConflictResolution)
+	require.NotNil(t, params.DDNSConflictResolutionMode)
+	require.Equal(t, "check-with-dhcid", *params.DDNSConflictResolutionMode)
+}
+
+// Test that the DDNS naming parameters, i.e., the prefix of the generated
+// client names and the qualifying suffix, are resolved from the subnet and
+// global configuration levels.
+func TestResolveDDNSNamingParameters(t *testing.T) {
+	configStr := `{
+        "Dhcp4": {
+            "ddns-generated-prefix": "myhost",
+            "ddns-qualifying-suffix": "example.org",
+            "subnet4": [
+                {
+                    "id": 1,
+                    "subnet": "192.0.2.0/24"
+                },
+                {
+                    "id": 2,
+                    "subnet": "192.0.3.0/24",
+                    "ddns-generated-prefix": "guest",
+                    "ddns-qualifying-suffix": ""
+                }
+            ]
+        }
+    }`
+
+	cfg, err := keaconfig.NewConfig(configStr)
+	require.NoError(t, err)
+
+	sharedNetworks := cfg.GetSharedNetworks(true)
+	require.Len(t, sharedNetworks, 1)
+	subnets := sharedNetworks[0].GetSubnets()
+	require.Len(t, subnets, 2)
+	global := cfg.GetDDNSParameters()
+
+	// The first subnet inherits the naming scheme from the global level.
+	params := keaconfig.ResolveDDNSParameters(subnets[0].GetSubnetParameters().DDNSParameters, global)
+	require.NotNil(t, params.DDNSGeneratedPrefix)
+	require.Equal(t, "myhost", *params.DDNSGeneratedPrefix)
+	require.NotNil(t, params.DDNSQualifyingSuffix)
+	require.Equal(t, "example.org", *params.DDNSQualifyingSuffix)
+
+	// The second subnet overrides the prefix and clears the suffix.
+	params = keaconfig.ResolveDDNSParameters(subnets[1].GetSubnetParameters().DDNSParameters, global)
+	require.NotNil(t, params.DDNSGeneratedPrefix)
+	require.Equal(t, "guest", *params.DDNSGeneratedPrefix)
+	require.NotNil(t, params.DDNSQualifyingSuffix)
+	require.Empty(t, *params.DDNSQualifyingSuffix)
+}
+
+// Test that the DDNS parameters remain unspecified when they are not
+// specified at any level.
+func TestResolveDDNSParametersUnspecified(t *testing.T) {
+	cfg, err := keaconfig.NewConfig(`{"Dhcp4": {}}`)
+	require.NoError(t, err)
+
+	params := keaconfig.ResolveDDNSParameters(cfg.GetDDNSParameters())
+	require.Nil(t, params.DDNSUpdateOnRenew)
+	require.Nil(t, params.DDNSUseConflictResolution)
+	require.Nil(t, params.DDNSConflictResolutionMode)
+}
+
+// Test that the hostname sanitization parameters are resolved from the
+// subnet, shared network and global configuration levels.
+func TestResolveHostnameCharParameters(t *testing.T) {
+	configStr := `{
+        "Dhcp6": {
+            "hostname-char-set": "[^A-Za-z0-9.-]",
+            "hostname-char-replacement": "x",
+            "shared-networks": [
+                {
+                    "name": "foo",
+                    "hostname-char-replacement": "-",
+                    "subnet6": [
+                        {
+                            "id": 1,
+                            "subnet": "2001:db8:1::/64"
+                        },
+                        {
+                            "id": 2,
+                            "subnet": "2001:db8:2::/64",
+                            "hostname-char-replacement": ""
+                        }
+                    ]
+                }
+            ],
+            "subnet6": [
+                {
+                    "id": 3,
+                    "subnet": "2001:db8:3::/64",
+                    "hostname-char-set": "[^A-Za-z0-9-]"
+                }
+            ]
+        }
+    }`
+
+	cfg, err := keaconfig.NewConfig(configStr)
+	require.NoError(t, err)
+
+	sharedNetworks := cfg.GetSharedNetworks(true)
+	require.Len(t, sharedNetworks, 2)
+	global := cfg.GetHostnameCharParameters()
+
+	// The first subnet in the shared network inherits the replacement from
+	// the shared network and the character set from the global level.
+	network := sharedNetworks[0]
+	require.Len(t, network.GetSubnets(), 2)
+	params := keaconfig.ResolveHostnameCharParameters(
+		network.GetSubnets()[0].GetSubnetParameters().HostnameCharParameters,
+		network.GetSharedNetworkParameters().HostnameCharParameters,
+		global,
+	)
+	require.NotNil(t, params.HostnameCharReplacement)
+	require.Equal(t, "-", *params.HostnameCharReplacement)
+	require.NotNil(t, params.HostnameCharSet)
+	require.Equal(t, "[^A-Za-z0-9.-]", *params.HostnameCharSet)
+
+	// The second subnet overrides the replacement with an empty string,
+	// which removes the invalid characters.
+	params = keaconfig.ResolveHostnameCharParameters(
+		network.GetSubnets()[1].GetSubnetParameters().HostnameCharParameters,
+		network.GetSharedNetworkParameters().HostnameCharParameters,
+		global,
+	)
+	require.NotNil(t, params.HostnameCharReplacement)
+	require.Empty(t, *params.HostnameCharReplacement)
+
+	// Top-level subnet overrides the character set and inherits the
+	// replacement from the global level.
+	network = sharedNetworks[1]
+	require.Len(t, network.GetSubnets(), 1)
+	params = keaconfig.ResolveHostnameCharParameters(
+		network.GetSubnets()[0].GetSubnetParameters().HostnameCharParameters,
+		global,
+	)
+	require.NotNil(t, params.HostnameCharReplacement)
+	require.Equal(t, "x", *params.HostnameCharReplacement)
+	require.NotNil(t, params.HostnameCharSet)
+	require.Equal(t, "[^A-Za-z0-9-]", *params.HostnameCharSet)
+}
+
+// Test that the hostname sanitization parameters remain unspecified when
+// they are not specified at any level.
+func TestResolveHostnameCharParametersUnspecified(t *testing.T) {
+	cfg, err := keaconfig.NewConfig(`{"Dhcp4": {}}`)
+	require.NoError(t, err)
+
+	params := keaconfig.ResolveHostnameCharParameters(cfg.GetHostnameCharParameters())
+	require.Nil(t, params.HostnameCharReplacement)
+	require.Nil(t, params.HostnameCharSet)
+}
+
+// Test that the DHCP timer parameters are resolved from the subnet, shared
+// network and global configuration levels.
+func TestResolveTimerParameters(t *testing.T) {
+	global := keaconfig.TimerParameters{
+		RenewTimer:        storkutil.Ptr[int64](1000),
+		RebindTimer:       storkutil.Ptr[int64](2000),
+		CalculateTeeTimes: storkutil.Ptr(false),
+	}
+	network := keaconfig.TimerParameters{
+		RebindTimer: storkutil.Ptr[int64](3000),
+		T2Percent:   storkutil.Ptr[float32](0.8),
+	}
+	subnet := keaconfig.TimerParameters{
+		T1Percent: storkutil.Ptr[float32](0.5),
+	}
+
+	params := keaconfig.ResolveTimerParameters(subnet, network, global)
+	require.NotNil(t, params.RenewTimer)
+	require.EqualValues(t, 1000, *params.RenewTimer)
+	require.NotNil(t, params.RebindTimer)
+	require.EqualValues(t, 3000, *params.RebindTimer)
+	require.NotNil(t, params.T1Percent)
+	require.EqualValues(t, 0.5, *params.T1Percent)
+	require.NotNil(t, params.T2Percent)
+	require.EqualValues(t, 0.8, *params.T2Percent)
+	require.NotNil(t, params.CalculateTeeTimes)
+	require.False(t, *params.CalculateTeeTimes)
+
+	params = keaconfig.ResolveTimerParameters()
+	require.Nil(t, params.RenewTimer)
+	require.Nil(t, params.RebindTimer)
+}
@@ -247,6 +247,22 @@ func setupApp(reload bool) *cli.App {
 				Usage:   "Skip TLS certificate verification when the Stork Agent connects to Kea over TLS and Kea uses self-signed certificates",
 				EnvVars: []string{"STORK_AGENT_SKIP_TLS_CERT_VERIFICATION"},
 			},
+			&cli.StringFlag{
+				Name:    "http-proxy",
+				Usage:   "The URL of the HTTP proxy used when the Stork Agent connects to Kea or BIND 9; if not specified and the use-env-proxy is enabled, the HTTP_PROXY and HTTPS_PROXY environment variables are used",
+				EnvVars: []string{"STORK_AGENT_HTTP_PROXY"},
+			},
+			&cli.StringFlag{
+				Name:    "no-proxy",
+				Usage:   "Comma-separated list of the hosts, domains and CIDRs the Stork Agent connects to without the proxy; if not specified and the use-env-proxy is enabled, the NO_PROXY environment variable is used",
+				EnvVars: []string{"STORK_AGENT_NO_PROXY"},
+			},
+			&cli.BoolFlag{
+				Name:    "use-env-proxy",
+				Value:   false,
+				Usage:   "Use the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables for the proxy settings not specified explicitly when the Stork Agent connects to Kea or BIND 9",
+				EnvVars: []string{"STORK_AGENT_USE_ENV_PROXY"},
+			},
 			// Registration related settings
 			&cli.StringFlag{
 				Name:    "server-url",
@@ -5,6 +5,8 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"net"
+	"strings"
 	"sync"
 
 	"github.com/pkg/errors"
@@ -18,8 +20,70 @@ import (
 	"isc.org/stork/server/eventcenter"
 )
 
+// The default maximum size of the decompressed response to the Kea command
+// forwarded by an agent (100 MiB). It is large enough to fit the
+// configurations of the servers with many subnets and host reservations.
+const DefaultMaxKeaResponseSize int64 = 100 * 1024 * 1024
+
 // Settings specific to communication with Agents.
-type AgentsSettings struct{}
+type AgentsSettings struct {
+	MaxKeaResponseSize int64  `long:"max-kea-response-size" description:"The maximum size in bytes of the response to a Kea command received from an agent; larger responses are rejected; if not provided the default of 100 MiB is used" env:"STORK_SERVER_MAX_KEA_RESPONSE_SIZE"`
+	TLSServerNames     string `long:"agent-tls-server-name" description:"Comma-separated list of the address[:port]=name entries specifying the server names expected in the TLS certificates of the agents, e.g., 192.0.2.1=agent1.example.org; if not provided for an agent, its address is expected" env:"STORK_SERVER_AGENT_TLS_SERVER_NAME"`
+}
+
+// Parses the comma-separated list of the address[:port]=name entries
+// specifying the TLS server names of the agents. It returns the names
+// indexed by the agent addresses with the optional ports.
+func ParseTLSServerNames(value string) (map[string]string, error) {
+	names := make(map[string]string)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		address, name, found := strings.Cut(entry, "=")
+		address = strings.TrimSpace(address)
+		name = strings.TrimSpace(name)
+		if !found || address == "" || name == "" {
+			return nil, errors.Errorf("invalid agent TLS server name entry %s, expected address[:port]=name", entry)
+		}
+		names[address] = name
+	}
+	return names, nil
+}
+
+// Returns the TLS server name expected in the certificate of the agent
+// with the specified address (including the port). The entry specified for
+// the address with the port takes precedence over the entry for the
+// address alone. It returns an empty string if the name is not configured,
+// so the agent address is used.
+func (settings *AgentsSettings) GetTLSServerName(address string) (string, error) {
+	if settings == nil || settings.TLSServerNames == "" {
+		return "", nil
+	}
+	names, err := ParseTLSServerNames(settings.TLSServerNames)
+	if err != nil {
+		return "", err
+	}
+	if name, ok := names[address]; ok {
+		return name, nil
+	}
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		if name, ok := names[host]; ok {
+			return name, nil
+		}
+	}
+	return "", nil
+}
+
+// Returns the maximum size of the response to the Kea command. It returns
+// the default value if the size is not specified.
+func (settings *AgentsSettings) GetMaxKeaResponseSize() int64 {
+	if settings == nil || settings.MaxKeaResponseSize <= 0 {
+		return DefaultMaxKeaResponseSize
+	}
+	return settings.MaxKeaResponseSize
+}
 
 // Holds runtime communication statistics with Kea daemons via
 // a given agent.
@@ -52,10 +116,13 @@ type AgentStats struct {
 // Runtime information about the agent, e.g. connection, communication
 // statistics.
 type Agent struct {
-	Address  string
-	Client   agentapi.AgentClient
-	GrpcConn *grpc.ClientConn
-	Stats    AgentStats
+	Address string
+	// Server name expected in the agent's TLS certificate. The address is
+	// used if it is empty.
+	TLSServerName string
+	Client        agentapi.AgentClient
+	GrpcConn      *grpc.ClientConn
+	Stats         AgentStats
 }
 
 // Prepare TLS credentials with configured certs and verification options.
@@ -116,8 +183,13 @@ func (agent *Agent) MakeGrpcConnection(caCertPEM, serverCertPEM, serverKeyPEM []
 		return errors.WithMessagef(err, "problem preparing TLS credentials")
 	}
 
-	// Setup new connection
-	grpcConn, err := grpc.Dial(agent.Address, grpc.WithTransportCredentials(creds))
+	// Setup new connection. The authority is used as the server name in
+	// the TLS handshake and verified against the agent's certificate.
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if agent.TLSServerName != "" {
+		opts = append(opts, grpc.WithAuthority(agent.TLSServerName))
+	}
+	grpcConn, err := grpc.Dial(agent.Address, opts...)
 	if err != nil {
 		return errors.Wrapf(err, "problem with dial to agent %s", agent.Address)
 	}
@@ -139,6 +211,7 @@ type ConnectedAgents interface {
 	ForwardToNamedStats(ctx context.Context, agentAddress string, agentPort int64, statsAddress string, statsPort int64, path string, statsOutput interface{}) error
 	ForwardToKeaOverHTTP(ctx context.Context, app ControlledApp, commands []keactrl.SerializableCommand, cmdResponses ...interface{}) (*KeaCmdsResult, error)
 	TailTextFile(ctx context.Context, agentAddress string, agentPort int64, path string, offset int64) ([]string, error)
+	CheckHookLibraries(ctx context.Context, agentAddress string, agentPort int64, paths []string) (map[string]bool, error)
 }
 
 // Agents management map. It tracks Agents currently connected to the Server.
@@ -198,12 +271,18 @@ func (agents *connectedAgentsData) GetConnectedAgent(address string) (*Agent, er
 		return agent, nil
 	}
 
+	tlsServerName, err := agents.Settings.GetTLSServerName(address)
+	if err != nil {
+		return nil, err
+	}
+
 	// Agent not found so allocate agent and prepare connection
 	agent = new(Agent)
 	agent.Address = address
+	agent.TLSServerName = tlsServerName
 	agent.Stats.AppCommStats = make(map[AppCommStatsKey]interface{})
 	agent.Stats.mutex = new(sync.Mutex)
-	err := agent.MakeGrpcConnection(agents.caCertPEM, agents.serverCertPEM, agents.serverKeyPEM)
+	err = agent.MakeGrpcConnection(agents.caCertPEM, agents.serverCertPEM, agents.serverKeyPEM)
 	if err != nil {
 		return nil, err
 	}
@@ -1,7 +1,9 @@
 package agentcomm
 
 import (
+	"crypto/tls"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	storktest "isc.org/stork/server/test/dbmodel"
@@ -220,3 +222,136 @@ func TestPrepareTLSCreds(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, creds)
 }
+
+// Test that the default maximum Kea response size is returned when it
+// is not specified.
+func TestGetMaxKeaResponseSize(t *testing.T) {
+	require.EqualValues(t, DefaultMaxKeaResponseSize, (&AgentsSettings{}).GetMaxKeaResponseSize())
+	require.EqualValues(t, DefaultMaxKeaResponseSize, (*AgentsSettings)(nil).GetMaxKeaResponseSize())
+	require.EqualValues(t, 1024, (&AgentsSettings{MaxKeaResponseSize: 1024}).GetMaxKeaResponseSize())
+}
+
+// Test that the TLS server names of the agents are parsed.
+func TestParseTLSServerNames(t *testing.T) {
+	// Act
+	names, err := ParseTLSServerNames(" 192.0.2.1=agent1.example.org, 192.0.2.2:8080 = agent2.example.org ,")
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, names, 2)
+	require.Equal(t, "agent1.example.org", names["192.0.2.1"])
+	require.Equal(t, "agent2.example.org", names["192.0.2.2:8080"])
+}
+
+// Test that parsing the invalid TLS server names fails.
+func TestParseInvalidTLSServerNames(t *testing.T) {
+	for _, value := range []string{"192.0.2.1", "=agent.example.org", "192.0.2.1=", "192.0.2.1=a,foo"} {
+		t.Run(value, func(t *testing.T) {
+			names, err := ParseTLSServerNames(value)
+			require.Error(t, err)
+			require.Nil(t, names)
+		})
+	}
+}
+
+// Test that the TLS server name is selected for the agent address and
+// that the entry with the port takes precedence.
+func TestGetTLSServerName(t *testing.T) {
+	// Arrange
+	settings := &AgentsSettings{
+		TLSServerNames: "192.0.2.1=agent1.example.org,192.0.2.1:8081=agent2.example.org",
+	}
+
+	// Act & Assert
+	name, err := settings.GetTLSServerName("192.0.2.1:8080")
+	require.NoError(t, err)
+	require.Equal(t, "agent1.example.org", name)
+
+	name, err = settings.GetTLSServerName("192.0.2.1:8081")
+	require.NoError(t, err)
+	require.Equal(t, "agent2.example.org", name)
+
+	name, err = settings.GetTLSServerName("192.0.2.2:8080")
+	require.NoError(t, err)
+	require.Empty(t, name)
+
+	name, err = (&AgentsSettings{}).GetTLSServerName("192.0.2.1:8080")
+	require.NoError(t, err)
+	require.Empty(t, name)
+
+	name, err = (*AgentsSettings)(nil).GetTLSServerName("192.0.2.1:8080")
+	require.NoError(t, err)
+	require.Empty(t, name)
+}
+
+// Test that the configured TLS server name is sent in the TLS handshake
+// with the agent.
+func TestConnectingToAgentWithTLSServerName(t *testing.T) {
+	// Arrange
+	certificate, err := tls.X509KeyPair(ServerCertPEM, ServerKeyPEM)
+	require.NoError(t, err)
+
+	serverNames := make(chan string, 10)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			serverNames <- hello.ServerName
+			return nil, nil
+		},
+		MinVersion: tls.VersionTLS12,
+	})
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_ = conn.(*tls.Conn).Handshake()
+			}()
+		}
+	}()
+
+	settings := AgentsSettings{
+		TLSServerNames: "127.0.0.1=agent.example.org",
+	}
+	fec := &storktest.FakeEventCenter{}
+	agents := NewConnectedAgents(&settings, fec, CACertPEM, ServerCertPEM, ServerKeyPEM)
+	defer agents.Shutdown()
+
+	// Act
+	agent, err := agents.GetConnectedAgent(listener.Addr().String())
+	require.NoError(t, err)
+	agent.GrpcConn.Connect()
+
+	// Assert
+	require.Equal(t, "agent.example.org", agent.TLSServerName)
+	select {
+	case serverName := <-serverNames:
+		require.Equal(t, "agent.example.org", serverName)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "the agent connection was not established")
+	}
+}
+
+// Test that connecting to the agent fails when the TLS server names are
+// invalid.
+func TestConnectingToAgentWithInvalidTLSServerName(t *testing.T) {
+	// Arrange
+	settings := AgentsSettings{
+		TLSServerNames: "127.0.0.1",
+	}
+	fec := &storktest.FakeEventCenter{}
+	agents := NewConnectedAgents(&settings, fec, CACertPEM, ServerCertPEM, ServerKeyPEM)
+	defer agents.Shutdown()
+
+	// Act
+	agent, err := agents.GetConnectedAgent("127.0.0.1:8080")
+
+	// Assert
+	require.Error(t, err)
+	require.Nil(t, agent)
+}
@@ -522,7 +522,10 @@ func (agents *connectedAgentsData) ForwardToKeaOverHTTP(ctx context.Context, app
 			caErrorStr += "\n" + fmt.Sprintf("%+v", err)
 			continue
 		}
-		unpackedResp, err := io.ReadAll(zr)
+		// Read at most one byte more than allowed to detect that the
+		// response is too large without reading it entirely.
+		maxResponseSize := agents.Settings.GetMaxKeaResponseSize()
+		unpackedResp, err := io.ReadAll(io.LimitReader(zr, maxResponseSize+1))
 		if err != nil {
 			err = errors.Wrapf(err, "failed to parse Kea response from %s, response was: %s", caURL, rsp)
 			result.CmdsErrors = append(result.CmdsErrors, err)
@@ -534,6 +537,18 @@ func (agents *connectedAgentsData) ForwardToKeaOverHTTP(ctx context.Context, app
 			}
 			continue
 		}
+		if int64(len(unpackedResp)) > maxResponseSize {
+			// Don't include the response in the error message because it
+			// is too large.
+			err = errors.Errorf("Kea response from %s exceeds the maximum allowed size of %d bytes", caURL, maxResponseSize)
+			result.CmdsErrors = append(result.CmdsErrors, err)
+			caErrorsCount++
+			caErrorStr += "\n" + err.Error()
+			if err2 := zr.Close(); err2 != nil {
+				log.Errorf("Error while closing gzip reader: %s", err2)
+			}
+			continue
+		}
 		if err := zr.Close(); err != nil {
 			err = errors.Wrapf(err, "failed to parse Kea response from %s, response was: %s", caURL, rsp)
 			result.CmdsErrors = append(result.CmdsErrors, err)
@@ -715,3 +730,35 @@ func (agents *connectedAgentsData) TailTextFile(ctx context.Context, agentAddres
 
 	return response.Lines, nil
 }
+
+// Checks if the specified hook library files exist on the agent's machine.
+// It returns the existence flags by path.
+func (agents *connectedAgentsData) CheckHookLibraries(ctx context.Context, agentAddress string, agentPort int64, paths []string) (map[string]bool, error) {
+	addrPort := net.JoinHostPort(agentAddress, strconv.FormatInt(agentPort, 10))
+
+	req := &agentapi.CheckHookLibrariesReq{
+		Paths: paths,
+	}
+
+	// Send the request via queue.
+	agentResponse, err := agents.sendAndRecvViaQueue(addrPort, req)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"agent": addrPort,
+		}).Warnf("Failed to check the hook library files")
+
+		return nil, errors.Wrapf(err, "failed to check the hook library files")
+	}
+
+	response := agentResponse.(*agentapi.CheckHookLibrariesRsp)
+
+	if response.Status.Code != agentapi.Status_OK {
+		return nil, errors.New(response.Status.Message)
+	}
+
+	files := make(map[string]bool)
+	for _, file := range response.Files {
+		files[file.Path] = file.Exists
+	}
+	return files, nil
+}
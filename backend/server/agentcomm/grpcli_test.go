@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -29,7 +30,12 @@ func makeAccessPoint(tp, address, key string, port int64) (ap []*agentapi.Access
 // 127.0.0.1:8080. The returned function performs a test teardown and
 // should be invoked when the unit test finishes.
 func setupGrpcliTestCase(t *testing.T) (*MockAgentClient, ConnectedAgents, func()) {
-	settings := AgentsSettings{}
+	return setupGrpcliTestCaseWithSettings(t, AgentsSettings{})
+}
+
+// Setup function for the unit tests using the specified agents settings.
+// See setupGrpcliTestCase for details.
+func setupGrpcliTestCaseWithSettings(t *testing.T, settings AgentsSettings) (*MockAgentClient, ConnectedAgents, func()) {
 	fec := &storktest.FakeEventCenter{}
 	agents := NewConnectedAgents(&settings, fec, CACertPEM, ServerCertPEM, ServerKeyPEM)
 
@@ -300,6 +306,71 @@ func TestForwardToKeaOverHTTPWith2Cmds(t *testing.T) {
 	require.Zero(t, appCommStats.CurrentErrorsDaemons["dhcp6"])
 }
 
+// Test that the response to the forwarded Kea command exceeding the maximum
+// size is rejected.
+func TestForwardToKeaOverHTTPOversizedResponse(t *testing.T) {
+	mockAgentClient, agents, teardown := setupGrpcliTestCaseWithSettings(t, AgentsSettings{
+		MaxKeaResponseSize: 64,
+	})
+	defer teardown()
+
+	rsp := agentapi.ForwardToKeaOverHTTPRsp{
+		Status: &agentapi.Status{
+			Code: 0,
+		},
+		KeaResponses: []*agentapi.KeaResponse{
+			{
+				Status: &agentapi.Status{
+					Code: 0,
+				},
+				// The response within the limit.
+				Response: doGzip(`[ { "result": 0, "text": "ok" } ]`),
+			},
+			{
+				Status: &agentapi.Status{
+					Code: 0,
+				},
+				// The response exceeding the limit.
+				Response: doGzip(`[ { "result": 0, "text": "` + strings.Repeat("a", 100) + `" } ]`),
+			},
+		},
+	}
+	mockAgentClient.EXPECT().ForwardToKeaOverHTTP(gomock.Any(), gomock.Any()).
+		Return(&rsp, nil)
+
+	ctx := context.Background()
+	command := keactrl.NewCommand("test-command", nil, nil)
+	actualResponse1 := keactrl.ResponseList{}
+	actualResponse2 := keactrl.ResponseList{}
+	dbApp := &dbmodel.App{
+		Machine: &dbmodel.Machine{
+			Address:   "127.0.0.1",
+			AgentPort: 8080,
+		},
+		AccessPoints: []*dbmodel.AccessPoint{{
+			Type:    dbmodel.AccessPointControl,
+			Address: "localhost",
+			Port:    8000,
+			Key:     "",
+		}},
+	}
+	cmdsResult, err := agents.ForwardToKeaOverHTTP(ctx, dbApp, []keactrl.SerializableCommand{command, command}, &actualResponse1, &actualResponse2)
+	require.NoError(t, err)
+	require.NotNil(t, cmdsResult)
+	require.NoError(t, cmdsResult.Error)
+	require.Len(t, cmdsResult.CmdsErrors, 2)
+	require.NoError(t, cmdsResult.CmdsErrors[0])
+	require.Len(t, actualResponse1, 1)
+	require.ErrorContains(t, cmdsResult.CmdsErrors[1], "exceeds the maximum allowed size of 64 bytes")
+	require.Empty(t, actualResponse2)
+
+	agent, err := agents.GetConnectedAgent("127.0.0.1:8080")
+	require.NoError(t, err)
+	appCommStats, ok := agent.Stats.AppCommStats[AppCommStatsKey{"localhost", 8000}].(*AgentKeaCommStats)
+	require.True(t, ok)
+	require.EqualValues(t, 1, appCommStats.CurrentErrorsCA)
+}
+
 // Test that the error is returned when the response to the forwarded Kea command
 // is malformed.
 func TestForwardToKeaOverHTTPInvalidResponse(t *testing.T) {
@@ -524,6 +595,57 @@ func TestTailTextFile(t *testing.T) {
 	require.Equal(t, "mock agent client", tail[1])
 }
 
+// Test the gRPC call which checks if the hook library files exist.
+func TestCheckHookLibraries(t *testing.T) {
+	mockAgentClient, agents, teardown := setupGrpcliTestCase(t)
+	defer teardown()
+
+	rsp := agentapi.CheckHookLibrariesRsp{
+		Status: &agentapi.Status{
+			Code: 0,
+		},
+		Files: []*agentapi.HookLibraryFile{
+			{Path: "/usr/lib/kea/hooks/libdhcp_ha.so", Exists: true},
+			{Path: "/usr/lib/kea/hooks/libdhcp_lease_cmds.so", Exists: false},
+		},
+	}
+
+	mockAgentClient.EXPECT().CheckHookLibraries(gomock.Any(), gomock.Any()).
+		Return(&rsp, nil)
+
+	ctx := context.Background()
+	files, err := agents.CheckHookLibraries(ctx, "127.0.0.1", 8080, []string{
+		"/usr/lib/kea/hooks/libdhcp_ha.so",
+		"/usr/lib/kea/hooks/libdhcp_lease_cmds.so",
+	})
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	require.True(t, files["/usr/lib/kea/hooks/libdhcp_ha.so"])
+	require.False(t, files["/usr/lib/kea/hooks/libdhcp_lease_cmds.so"])
+}
+
+// Test that an error is returned when the agent fails to check the hook
+// library files.
+func TestCheckHookLibrariesError(t *testing.T) {
+	mockAgentClient, agents, teardown := setupGrpcliTestCase(t)
+	defer teardown()
+
+	rsp := agentapi.CheckHookLibrariesRsp{
+		Status: &agentapi.Status{
+			Code:    agentapi.Status_ERROR,
+			Message: "failed",
+		},
+	}
+
+	mockAgentClient.EXPECT().CheckHookLibraries(gomock.Any(), gomock.Any()).
+		Return(&rsp, nil)
+
+	ctx := context.Background()
+	files, err := agents.CheckHookLibraries(ctx, "127.0.0.1", 8080, []string{"/usr/lib/kea/hooks/libdhcp_ha.so"})
+	require.ErrorContains(t, err, "failed")
+	require.Nil(t, files)
+}
+
 // Check MakeAccessPoint.
 func TestMakeAccessPoint(t *testing.T) {
 	aps := MakeAccessPoint(dbmodel.AccessPointControl, "1.2.3.4", "abcd", 124)
@@ -66,6 +66,8 @@ func doCall(ctx context.Context, agent *Agent, in interface{}) (interface{}, err
 		response, err = agent.Client.ForwardToKeaOverHTTP(ctx, inData)
 	case *agentapi.TailTextFileReq:
 		response, err = agent.Client.TailTextFile(ctx, inData)
+	case *agentapi.CheckHookLibrariesReq:
+		response, err = agent.Client.CheckHookLibraries(ctx, inData)
 	default:
 		err = errors.New("doCall: unsupported request type")
 	}
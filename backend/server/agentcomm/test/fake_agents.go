@@ -26,6 +26,10 @@ type FakeAgents struct {
 
 	MachineState   *agentcomm.State
 	GetStateCalled bool
+
+	// Hook library files reported as missing by CheckHookLibraries.
+	MissingHookLibraries  []string
+	RecordedHookLibraries []string
 }
 
 // mockRndcOutput returns some mocked named response.
@@ -199,3 +203,21 @@ func (fa *FakeAgents) ForwardRndcCommand(ctx context.Context, app agentcomm.Cont
 func (fa *FakeAgents) TailTextFile(ctx context.Context, agentAddress string, agentPort int64, path string, offset int64) ([]string, error) {
 	return []string{"lorem ipsum"}, nil
 }
+
+// Mimics checking the hook library files. It records the checked paths.
+// The files specified in MissingHookLibraries are reported as missing and
+// other files as existing.
+func (fa *FakeAgents) CheckHookLibraries(ctx context.Context, agentAddress string, agentPort int64, paths []string) (map[string]bool, error) {
+	fa.RecordedHookLibraries = append(fa.RecordedHookLibraries, paths...)
+	files := make(map[string]bool)
+	for _, path := range paths {
+		files[path] = true
+		for _, missing := range fa.MissingHookLibraries {
+			if path == missing {
+				files[path] = false
+				break
+			}
+		}
+	}
+	return files, nil
+}
@@ -3,6 +3,8 @@ package kea
 import (
 	"context"
 	"fmt"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/go-pg/pg/v10"
@@ -35,6 +37,49 @@ func GetDaemonHooks(dbDaemon *dbmodel.Daemon) (hooks []string) {
 	return
 }
 
+// Get the hook libraries with their parameters for each daemon of the given
+// Kea app. The returned map is indexed by daemon names. Contrary to the
+// GetDaemonHooks, it returns the complete hook library configurations,
+// including the parameters, e.g., the HA peers definitions. The daemons
+// lacking the configuration are not included in the map.
+func GetDaemonHooksWithParams(dbApp *dbmodel.App) map[string]keaconfig.HookLibraries {
+	hooksByDaemon := make(map[string]keaconfig.HookLibraries)
+	for _, dbDaemon := range dbApp.Daemons {
+		if dbDaemon.KeaDaemon == nil || dbDaemon.KeaDaemon.Config == nil {
+			continue
+		}
+		hooksByDaemon[dbDaemon.Name] = dbDaemon.KeaDaemon.Config.GetHookLibraries()
+	}
+	return hooksByDaemon
+}
+
+// Returns the Kea DHCP daemons loading the specified hook library and the
+// daemons not loading it. The hook name is matched against the library
+// paths returned by GetDaemonHooks, e.g., "libdhcp_stat_cmds" matches the
+// "/usr/lib/kea/hooks/libdhcp_stat_cmds.so" library. It allows for finding
+// the daemons lacking the hooks required by Stork, e.g., stat_cmds or HA.
+func GetDHCPDaemonsByHook(dbi dbops.DBI, hookName string) (withHook, withoutHook []dbmodel.Daemon, err error) {
+	daemons, err := dbmodel.GetKeaDHCPDaemons(dbi)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range daemons {
+		loaded := false
+		for _, hook := range GetDaemonHooks(&daemons[i]) {
+			if strings.Contains(hook, hookName) {
+				loaded = true
+				break
+			}
+		}
+		if loaded {
+			withHook = append(withHook, daemons[i])
+		} else {
+			withoutHook = append(withoutHook, daemons[i])
+		}
+	}
+	return withHook, withoutHook, nil
+}
+
 // The arguments of the version-get command response.
 type VersionGetRespArgs struct {
 	Extended string
@@ -46,6 +91,109 @@ type VersionGetResponse struct {
 	Arguments *VersionGetRespArgs `json:"arguments,omitempty"`
 }
 
+// The arguments of the config-hash-get command response.
+type ConfigHashGetRespArgs struct {
+	Hash string
+}
+
+// The response of the config-hash-get command.
+type ConfigHashGetResponse struct {
+	keactrl.ResponseHeader
+	Arguments *ConfigHashGetRespArgs `json:"arguments,omitempty"`
+}
+
+// The default timeout of a single attempt to get the state from the Kea
+// Control Agent or the Kea daemons.
+const DefaultAppStateTimeout = 2 * time.Second
+
+// The settings controlling the communication with the Kea app while getting
+// its state. The commands sent to the Control Agent are retried with an
+// exponential backoff when the communication fails, e.g., due to a dropped
+// packet, before the Control Agent is declared inactive.
+type AppStatePullSettings struct {
+	// The timeout of a single attempt to get the state from the Control
+	// Agent or the Kea daemons. The default timeout is used when it is zero.
+	Timeout time.Duration
+	// The number of times the commands sent to the Control Agent are
+	// retried after the communication failure.
+	Retries int
+	// The delay before the first retry. It is doubled for each subsequent
+	// retry.
+	Backoff time.Duration
+	// Tracks the daemons reporting implausible uptimes to log the warning
+	// once per transition. The warning is logged on every pull when it is
+	// nil.
+	ImplausibleUptimes *ImplausibleUptimeTracker
+}
+
+// Returns the timeout of a single attempt to get the state. The settings may
+// be nil.
+func (settings *AppStatePullSettings) getTimeout() time.Duration {
+	if settings == nil || settings.Timeout <= 0 {
+		return DefaultAppStateTimeout
+	}
+	return settings.Timeout
+}
+
+// Returns a copy of the settings with the specified timeout of a single
+// attempt. The settings may be nil, in which case the copy has the default
+// retries.
+func (settings *AppStatePullSettings) WithTimeout(timeout time.Duration) *AppStatePullSettings {
+	copied := &AppStatePullSettings{}
+	if settings != nil {
+		*copied = *settings
+	}
+	copied.Timeout = timeout
+	return copied
+}
+
+// Returns the number of retries. The settings may be nil.
+func (settings *AppStatePullSettings) getRetries() int {
+	if settings == nil || settings.Retries < 0 {
+		return 0
+	}
+	return settings.Retries
+}
+
+// Returns the delay before the specified retry. The retries are numbered
+// from 1. The settings may be nil.
+func (settings *AppStatePullSettings) getBackoff(retry int) time.Duration {
+	if settings == nil || settings.Backoff <= 0 || retry < 1 {
+		return 0
+	}
+	return settings.Backoff << (retry - 1)
+}
+
+// Records whether the daemon reported an implausible uptime. It returns
+// true if the warning or the recovery should be logged. The settings may
+// be nil.
+func (settings *AppStatePullSettings) updateImplausibleUptime(key string, implausible bool) bool {
+	if settings == nil || settings.ImplausibleUptimes == nil {
+		return implausible
+	}
+	return settings.ImplausibleUptimes.update(key, implausible)
+}
+
+// Discards the implausible uptime states of the daemons not belonging to
+// the specified apps. It should be called with all monitored apps after
+// pulling their states, so the states of the deleted daemons and apps are
+// not retained. The settings may be nil.
+func (settings *AppStatePullSettings) PruneImplausibleUptimes(apps []*dbmodel.App) {
+	if settings == nil || settings.ImplausibleUptimes == nil {
+		return
+	}
+	keys := make(map[string]bool)
+	for _, app := range apps {
+		if app.Type != dbmodel.AppTypeKea {
+			continue
+		}
+		for _, daemon := range app.Daemons {
+			keys[getUptimeTrackerKey(app, daemon.Name)] = true
+		}
+	}
+	settings.ImplausibleUptimes.retain(keys)
+}
+
 // Struct returned by GetAppState() function.
 type AppStateMeta struct {
 	Events            []*dbmodel.Event
@@ -66,23 +214,68 @@ func copyOrCreateActiveKeaDaemon(dbApp *dbmodel.App, daemonName string) *dbmodel
 	return dbmodel.NewKeaDaemon(daemonName, true)
 }
 
+// Sends the commands to the Kea Control Agent. The commands are retried with
+// an exponential backoff when the communication with the Control Agent fails.
+// Each attempt is limited by the timeout specified in the settings. It returns
+// the result of the last attempt.
+func forwardToCAWithRetries(ctx context.Context, agents agentcomm.ConnectedAgents, dbApp *dbmodel.App, cmds []keactrl.SerializableCommand, settings *AppStatePullSettings, cmdResponses ...interface{}) (*agentcomm.KeaCmdsResult, error) {
+	retries := settings.getRetries()
+	for attempt := 0; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, settings.getTimeout())
+		cmdsResult, err := agents.ForwardToKeaOverHTTP(attemptCtx, dbApp, cmds, cmdResponses...)
+		if errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+			log.Warnf("Communication with Kea CA of the app %s timed out after %s", dbApp.Name, settings.getTimeout())
+		}
+		cancel()
+
+		failure := err
+		if failure == nil {
+			failure = cmdsResult.Error
+		}
+		if failure == nil {
+			for _, cmdErr := range cmdsResult.CmdsErrors {
+				if cmdErr != nil {
+					failure = cmdErr
+					break
+				}
+			}
+		}
+		if failure == nil || attempt >= retries {
+			return cmdsResult, err
+		}
+
+		backoff := settings.getBackoff(attempt + 1)
+		log.WithError(failure).Warnf("Problem communicating with Kea CA of the app %s; retrying in %s (%d/%d)",
+			dbApp.Name, backoff, attempt+1, retries)
+		select {
+		case <-ctx.Done():
+			return cmdsResult, err
+		case <-time.After(backoff):
+		}
+	}
+}
+
 // Get state of Kea application Control Agent using ForwardToKeaOverHTTP function.
 // The state, that is stored into dbApp, includes: version and config of CA.
-// It also returns:
-// - list of all Kea daemons
-// - list of DHCP daemons (dhcpv4 and/or dhcpv6).
-func getStateFromCA(ctx context.Context, agents agentcomm.ConnectedAgents, dbApp *dbmodel.App, daemonsMap map[string]*dbmodel.Daemon, daemonsErrors map[string]string) ([]string, []string, error) {
-	// prepare the command to get config and version from CA
+// The commands are retried according to the settings when the communication
+// with the Control Agent fails.
+// It also returns the list of all Kea daemons configured in the CA and the
+// CA's status. The status is nil if the CA doesn't support the status-get
+// command.
+func getStateFromCA(ctx context.Context, agents agentcomm.ConnectedAgents, dbApp *dbmodel.App, daemonsMap map[string]*dbmodel.Daemon, daemonsErrors map[string]string, settings *AppStatePullSettings) ([]string, *StatusGetRespArgs, error) {
+	// prepare the command to get config, version and status from CA
 	cmds := []keactrl.SerializableCommand{
 		keactrl.NewCommand("version-get", nil, nil),
 		keactrl.NewCommand("config-get", nil, nil),
+		keactrl.NewCommand("status-get", nil, nil),
 	}
 
-	// get version and config from CA
+	// get version, config and status from CA
 	versionGetResp := []VersionGetResponse{}
 	caConfigGetResp := []keactrl.HashedResponse{}
+	caStatusGetResp := []StatusGetResponse{}
 
-	cmdsResult, err := agents.ForwardToKeaOverHTTP(ctx, dbApp, cmds, &versionGetResp, &caConfigGetResp)
+	cmdsResult, err := forwardToCAWithRetries(ctx, agents, dbApp, cmds, settings, &versionGetResp, &caConfigGetResp, &caStatusGetResp)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -131,9 +324,20 @@ func getStateFromCA(ctx context.Context, agents agentcomm.ConnectedAgents, dbApp
 		return nil, nil, err
 	}
 
+	// The older CA versions don't support the status-get command, in which
+	// case the CA's runtime state remains unknown.
+	var caStatus *StatusGetRespArgs
+	if (len(cmdsResult.CmdsErrors) < 3 || cmdsResult.CmdsErrors[2] == nil) &&
+		len(caStatusGetResp) > 0 && caStatusGetResp[0].Result == keactrl.ResponseSuccess {
+		caStatus = caStatusGetResp[0].Arguments
+	}
+	if caStatus != nil {
+		dmn.Uptime = caStatus.Uptime
+		dmn.ReloadedAt = storkutil.UTCNow().Add(time.Second * time.Duration(-caStatus.Reload))
+	}
+
 	// prepare a set of available daemons
 	allDaemons := []string{}
-	dhcpDaemons := []string{}
 
 	// Only set the new configuration if the configuration is added for the first
 	// time or the hash values aren't matching.
@@ -150,36 +354,80 @@ func getStateFromCA(ctx context.Context, agents agentcomm.ConnectedAgents, dbApp
 	sockets := dmn.KeaDaemon.Config.GetControlSockets()
 	if sockets.Dhcp4 != nil {
 		allDaemons = append(allDaemons, dhcp4)
-		dhcpDaemons = append(dhcpDaemons, dhcp4)
 	}
 	if sockets.Dhcp6 != nil {
 		allDaemons = append(allDaemons, dhcp6)
-		dhcpDaemons = append(dhcpDaemons, dhcp6)
 	}
 	if sockets.D2 != nil {
 		allDaemons = append(allDaemons, d2)
 	}
 
-	return allDaemons, dhcpDaemons, nil
+	return allDaemons, caStatus, nil
+}
+
+// Returns the key identifying the daemon in the tracker of the implausible
+// uptimes. The daemons are identified by the control access points of
+// their apps because the new apps don't have IDs yet.
+func getUptimeTrackerKey(dbApp *dbmodel.App, daemonName string) string {
+	address, port, _, _, _ := dbApp.GetControlAccessPoint()
+	return fmt.Sprintf("%s/%s", net.JoinHostPort(address, fmt.Sprint(port)), daemonName)
+}
+
+// Marks the daemons that haven't responded to the command as inactive and
+// records the errors for them. The command error is the error returned for
+// the whole command, e.g., a communication timeout. It is nil if the daemons
+// are missing from the received responses. The responded map holds the
+// names of the daemons that returned the responses.
+func markUnresponsiveDaemons(command string, daemons []string, responded map[string]bool, cmdErr error, daemonsMap map[string]*dbmodel.Daemon, daemonsErrors map[string]string) {
+	for _, name := range daemons {
+		if responded[name] {
+			continue
+		}
+		dmn, ok := daemonsMap[name]
+		if !ok {
+			continue
+		}
+		dmn.Active = false
+		errStr := fmt.Sprintf("no %s response from kea daemon %s", command, name)
+		if cmdErr != nil {
+			errStr = fmt.Sprintf("problem with %s and kea daemon %s: %s", command, name, cmdErr)
+		}
+		log.Warn(errStr)
+		// Keep the error from the previous command.
+		if _, exists := daemonsErrors[name]; !exists {
+			daemonsErrors[name] = errStr
+		}
+	}
 }
 
 // Get state of Kea application daemons (beside Control Agent) using ForwardToKeaOverHTTP function.
 // The state, that is stored into dbApp, includes: version, config and runtime state of indicated Kea daemons.
-func getStateFromDaemons(ctx context.Context, agents agentcomm.ConnectedAgents, dbApp *dbmodel.App, daemonsMap map[string]*dbmodel.Daemon, allDaemons []string, dhcpDaemons []string, daemonsErrors map[string]string) error {
+// The runtime state is also fetched from the D2 daemon. The older D2 versions don't support the status-get
+// command, in which case the D2 runtime state remains unknown but the daemon is not marked inactive.
+// The configuration hashes are fetched with the config-hash-get command first. The config-get command
+// is sent only to the daemons whose configuration hashes differ from the stored ones or which don't
+// support the config-hash-get command. The configurations of the remaining daemons are not fetched.
+// A failure of a command for one of the daemons, e.g., a timeout, doesn't stop processing the responses
+// from the other daemons. The daemons that haven't responded are marked inactive and their errors are
+// recorded in the daemonsErrors. The function returns the first command error after processing all
+// responses. The uptimes of the daemons are compared with the CA's uptime if the CA's status is
+// specified. The warning about the implausible uptime is logged once per daemon until the daemon
+// reports the plausible uptime again.
+func getStateFromDaemons(ctx context.Context, agents agentcomm.ConnectedAgents, dbApp *dbmodel.App, daemonsMap map[string]*dbmodel.Daemon, allDaemons []string, caStatus *StatusGetRespArgs, daemonsErrors map[string]string, pullSettings *AppStatePullSettings) error {
 	now := storkutil.UTCNow()
 
 	// issue 3 commands to Kea daemons at once to get their state
 	cmds := []keactrl.SerializableCommand{
 		keactrl.NewCommand("version-get", allDaemons, nil),
-		keactrl.NewCommand("status-get", dhcpDaemons, nil),
-		keactrl.NewCommand("config-get", allDaemons, nil),
+		keactrl.NewCommand("status-get", allDaemons, nil),
+		keactrl.NewCommand("config-hash-get", allDaemons, nil),
 	}
 
 	versionGetResp := []VersionGetResponse{}
 	statusGetResp := []StatusGetResponse{}
-	configGetResp := []keactrl.HashedResponse{}
+	configHashGetResp := []ConfigHashGetResponse{}
 
-	cmdsResult, err := agents.ForwardToKeaOverHTTP(ctx, dbApp, cmds, &versionGetResp, &statusGetResp, &configGetResp)
+	cmdsResult, err := agents.ForwardToKeaOverHTTP(ctx, dbApp, cmds, &versionGetResp, &statusGetResp, &configHashGetResp)
 	if err != nil {
 		return err
 	}
@@ -192,110 +440,193 @@ func getStateFromDaemons(ctx context.Context, agents agentcomm.ConnectedAgents,
 		daemonsMap[name] = copyOrCreateActiveKeaDaemon(dbApp, name)
 	}
 
-	// process version-get responses
-	err = cmdsResult.CmdsErrors[0]
-	if err != nil {
-		return errors.WithMessage(err, "problem with version-get response")
-	}
-
-	for _, vRsp := range versionGetResp {
-		dmn, ok := daemonsMap[vRsp.Daemon]
-		if !ok {
-			log.Warnf("Unrecognized daemon in version-get response: %v", vRsp)
-			continue
+	var firstErr error
+	getCmdErr := func(idx int, command string) error {
+		if idx >= len(cmdsResult.CmdsErrors) || cmdsResult.CmdsErrors[idx] == nil {
+			return nil
 		}
-		if vRsp.Result != 0 {
-			dmn.Active = false
-			errStr := fmt.Sprintf("problem with version-get and kea daemon %s: %s", vRsp.Daemon, vRsp.Text)
-			log.Warnf(errStr)
-			daemonsErrors[dmn.Name] = errStr
-			continue
+		err := cmdsResult.CmdsErrors[idx]
+		if firstErr == nil {
+			firstErr = errors.WithMessagef(err, "problem with %s response", command)
 		}
+		return err
+	}
 
-		dmn.Version = vRsp.Text
-		if vRsp.Arguments != nil {
-			dmn.ExtendedVersion = vRsp.Arguments.Extended
+	// process version-get responses
+	responded := make(map[string]bool)
+	err = getCmdErr(0, "version-get")
+	if err == nil {
+		for _, vRsp := range versionGetResp {
+			dmn, ok := daemonsMap[vRsp.Daemon]
+			if !ok {
+				log.Warnf("Unrecognized daemon in version-get response: %v", vRsp)
+				continue
+			}
+			responded[dmn.Name] = true
+			if vRsp.Result != 0 {
+				dmn.Active = false
+				errStr := fmt.Sprintf("problem with version-get and kea daemon %s: %s", vRsp.Daemon, vRsp.Text)
+				log.Warnf(errStr)
+				daemonsErrors[dmn.Name] = errStr
+				continue
+			}
+
+			dmn.Version = vRsp.Text
+			if vRsp.Arguments != nil {
+				dmn.ExtendedVersion = vRsp.Arguments.Extended
+			}
 		}
 	}
+	markUnresponsiveDaemons("version-get", allDaemons, responded, err, daemonsMap, daemonsErrors)
 
 	// process status-get responses
-	err = cmdsResult.CmdsErrors[1]
-	if err != nil {
-		return errors.WithMessage(err, "problem with status-get response")
-	}
+	responded = make(map[string]bool)
+	err = getCmdErr(1, "status-get")
+	if err == nil {
+		for _, sRsp := range statusGetResp {
+			dmn, ok := daemonsMap[sRsp.Daemon]
+			if !ok {
+				log.Warnf("Unrecognized daemon in status-get response: %v", sRsp)
+				continue
+			}
+			responded[dmn.Name] = true
+			if sRsp.Result == keactrl.ResponseCommandUnsupported && dmn.Name == d2 {
+				log.Debugf("Kea daemon %s doesn't support status-get: %s", sRsp.Daemon, sRsp.Text)
+				continue
+			}
+			if sRsp.Result != 0 {
+				dmn.Active = false
+				errStr := fmt.Sprintf("problem with status-get and kea daemon %s: %s", sRsp.Daemon, sRsp.Text)
+				log.Warnf(errStr)
+				daemonsErrors[dmn.Name] = errStr
+				continue
+			}
 
-	for _, sRsp := range statusGetResp {
-		dmn, ok := daemonsMap[sRsp.Daemon]
-		if !ok {
-			log.Warnf("Unrecognized daemon in status-get response: %v", sRsp)
-			continue
+			if sRsp.Arguments != nil {
+				err := sRsp.Arguments.validateUptime(caStatus)
+				if pullSettings.updateImplausibleUptime(getUptimeTrackerKey(dbApp, dmn.Name), err != nil) {
+					if err != nil {
+						log.WithError(err).Warnf("Kea daemon %s of the app %s reported implausible uptime; it may indicate clock issues on the host",
+							dmn.Name, dbApp.Name)
+					} else {
+						log.Infof("Kea daemon %s of the app %s reported plausible uptime again", dmn.Name, dbApp.Name)
+					}
+				}
+				dmn.Uptime = sRsp.Arguments.Uptime
+				dmn.ReloadedAt = now.Add(time.Second * time.Duration(-sRsp.Arguments.Reload))
+			}
 		}
-		if sRsp.Result != 0 {
-			dmn.Active = false
-			errStr := fmt.Sprintf("problem with status-get and kea daemon %s: %s", sRsp.Daemon, sRsp.Text)
-			log.Warnf(errStr)
-			daemonsErrors[dmn.Name] = errStr
-			continue
+	}
+	markUnresponsiveDaemons("status-get", allDaemons, responded, err, daemonsMap, daemonsErrors)
+
+	// Process config-hash-get responses. The daemons that don't support this
+	// command or failed to respond have empty hashes and their configurations
+	// are always fetched.
+	configHashes := make(map[string]string)
+	if getCmdErr(2, "config-hash-get") == nil {
+		for _, hRsp := range configHashGetResp {
+			if _, ok := daemonsMap[hRsp.Daemon]; !ok {
+				log.Warnf("Unrecognized daemon in config-hash-get response: %v", hRsp)
+				continue
+			}
+			if hRsp.Result != 0 || hRsp.Arguments == nil {
+				continue
+			}
+			configHashes[hRsp.Daemon] = hRsp.Arguments.Hash
 		}
+	}
 
-		if sRsp.Arguments != nil {
-			dmn.Uptime = sRsp.Arguments.Uptime
-			dmn.ReloadedAt = now.Add(time.Second * time.Duration(-sRsp.Arguments.Reload))
+	// Select the daemons whose configurations have to be fetched. The empty
+	// config hash means that the configuration must be fetched regardless
+	// of the hash returned by Kea. It is the case when the database migration
+	// resets the hash to populate the new columns from the configuration.
+	configDaemons := []string{}
+	for _, name := range allDaemons {
+		dmn := daemonsMap[name]
+		hash := configHashes[name]
+		if hash == "" || dmn.KeaDaemon.Config == nil || dmn.KeaDaemon.ConfigHash == "" ||
+			dmn.KeaDaemon.KeaConfigHash != hash {
+			configDaemons = append(configDaemons, name)
 		}
 	}
-
-	// process config-get responses
-	err = cmdsResult.CmdsErrors[2]
-	if err != nil {
-		return errors.WithMessage(err, "problem with config-get response")
+	if len(configDaemons) == 0 {
+		return firstErr
 	}
 
-	for _, cRsp := range configGetResp {
-		dmn, ok := daemonsMap[cRsp.Daemon]
-		if !ok {
-			log.Warnf("Unrecognized daemon in config-get response: %v", cRsp)
-			continue
-		}
-		if cRsp.Result != 0 {
-			dmn.Active = false
-			errStr := fmt.Sprintf("problem with config-get and kea daemon %s: %s", cRsp.Daemon, cRsp.Text)
-			log.Warnf(errStr)
-			daemonsErrors[dmn.Name] = errStr
-			continue
+	configGetResp := []keactrl.HashedResponse{}
+	cmdsResult, err = agents.ForwardToKeaOverHTTP(ctx, dbApp,
+		[]keactrl.SerializableCommand{keactrl.NewCommand("config-get", configDaemons, nil)},
+		&configGetResp)
+	if err == nil {
+		err = cmdsResult.Error
+	}
+	if err != nil {
+		if firstErr == nil {
+			firstErr = errors.WithMessage(err, "problem with config-get response")
 		}
+		markUnresponsiveDaemons("config-get", configDaemons, map[string]bool{}, err, daemonsMap, daemonsErrors)
+		return firstErr
+	}
 
-		if (dmn.KeaDaemon.Config == nil) || (dmn.KeaDaemon.ConfigHash != cRsp.ArgumentsHash) {
-			// Set the configuration for the daemon and populate selected configuration
-			// information to the respective structures, e.g. logging information.
-			err = dmn.SetConfigWithHash(dbmodel.NewKeaConfig(cRsp.Arguments), cRsp.ArgumentsHash)
-			if err != nil {
-				errStr := fmt.Sprintf("%s", err)
-				log.Warn(errStr)
+	// process config-get responses
+	responded = make(map[string]bool)
+	err = getCmdErr(0, "config-get")
+	if err == nil {
+		for _, cRsp := range configGetResp {
+			dmn, ok := daemonsMap[cRsp.Daemon]
+			if !ok {
+				log.Warnf("Unrecognized daemon in config-get response: %v", cRsp)
+				continue
+			}
+			responded[dmn.Name] = true
+			if cRsp.Result != 0 {
+				dmn.Active = false
+				errStr := fmt.Sprintf("problem with config-get and kea daemon %s: %s", cRsp.Daemon, cRsp.Text)
+				log.Warnf(errStr)
 				daemonsErrors[dmn.Name] = errStr
 				continue
 			}
+
+			if (dmn.KeaDaemon.Config == nil) || (dmn.KeaDaemon.ConfigHash != cRsp.ArgumentsHash) {
+				// Set the configuration for the daemon and populate selected configuration
+				// information to the respective structures, e.g. logging information.
+				if err := dmn.SetConfigWithHash(dbmodel.NewKeaConfig(cRsp.Arguments), cRsp.ArgumentsHash); err != nil {
+					errStr := fmt.Sprintf("%s", err)
+					log.Warn(errStr)
+					daemonsErrors[dmn.Name] = errStr
+					continue
+				}
+			}
+			dmn.KeaDaemon.KeaConfigHash = configHashes[dmn.Name]
 		}
 	}
+	markUnresponsiveDaemons("config-get", configDaemons, responded, err, daemonsMap, daemonsErrors)
 
-	return nil
+	return firstErr
 }
 
 // Get state of Kea application daemons using ForwardToKeaOverHTTP function.
 // The state that is stored into dbApp includes: version, config and runtime state of indicated Kea daemons.
-func GetAppState(ctx context.Context, agents agentcomm.ConnectedAgents, dbApp *dbmodel.App, eventCenter eventcenter.EventCenter) *AppStateMeta {
-	ctx2, cancel := context.WithTimeout(ctx, 2*time.Second)
-	defer cancel()
-
+// The severities specify the levels of the events raised for the daemon state changes. If they are nil,
+// the default levels are used. The pull settings specify the timeouts and the retries of the commands
+// sent to the Control Agent. If they are nil, the default timeout is used and the commands are not
+// retried.
+func GetAppState(ctx context.Context, agents agentcomm.ConnectedAgents, dbApp *dbmodel.App, eventCenter eventcenter.EventCenter, severities DaemonEventSeverities, pullSettings *AppStatePullSettings) *AppStateMeta {
 	// get state from CA
 	daemonsMap := map[string]*dbmodel.Daemon{}
 	daemonsErrors := map[string]string{}
-	allDaemons, dhcpDaemons, err := getStateFromCA(ctx2, agents, dbApp, daemonsMap, daemonsErrors)
+	allDaemons, caStatus, err := getStateFromCA(ctx, agents, dbApp, daemonsMap, daemonsErrors, pullSettings)
 	if err != nil {
 		log.Warnf("Problem getting state from Kea CA: %s", err)
 	}
 
 	// if no problems then now get state from the rest of Kea daemons
-	err = getStateFromDaemons(ctx2, agents, dbApp, daemonsMap, allDaemons, dhcpDaemons, daemonsErrors)
+	ctx2, cancel := context.WithTimeout(ctx, pullSettings.getTimeout())
+	defer cancel()
+	err = getStateFromDaemons(ctx2, agents, dbApp, daemonsMap, allDaemons, caStatus, daemonsErrors, pullSettings)
+	if errors.Is(ctx2.Err(), context.DeadlineExceeded) {
+		log.Warnf("Communication with Kea daemons of the app %s timed out after %s", dbApp.Name, pullSettings.getTimeout())
+	}
 	if err != nil {
 		log.Warnf("Problem getting state from Kea daemons: %s", err)
 	}
@@ -305,16 +636,20 @@ func GetAppState(ctx context.Context, agents agentcomm.ConnectedAgents, dbApp *d
 	// list of daemons.
 	if dbApp.ID == 0 {
 		dbApp.Active, dbApp.Daemons = createNewAppState(daemonsMap)
+		dbApp.Meta.NoDaemons = hasOnlyControlAgent(dbApp.Daemons)
+		updateControlAccessPointState(dbApp, daemonsMap)
 		return nil
 	}
 
-	newActive, overrideDaemons, newDaemons, events, sameConfigDaemons := findChangesAndRaiseEvents(dbApp, daemonsMap, daemonsErrors)
+	newActive, overrideDaemons, newDaemons, events, sameConfigDaemons := findChangesAndRaiseEvents(dbApp, daemonsMap, daemonsErrors, severities)
+	events = append(events, updateControlAccessPointState(dbApp, daemonsMap)...)
 
 	// update app state
 	dbApp.Active = newActive
 	if overrideDaemons {
 		dbApp.Daemons = newDaemons
 	}
+	dbApp.Meta.NoDaemons = hasOnlyControlAgent(dbApp.Daemons)
 
 	// Return supplementary information about the state returned.
 	state := &AppStateMeta{
@@ -346,16 +681,57 @@ func createNewAppState(daemonsMap map[string]*dbmodel.Daemon) (active bool, daem
 	return active, daemons
 }
 
+// Sets the reachability of the app's control access point based on the
+// Control Agent state returned by GetAppState. Each Kea app has its own
+// control access point, so the Control Agents running on the same machine
+// are tracked independently. It returns the events about the access point
+// becoming unreachable or reachable again.
+func updateControlAccessPointState(dbApp *dbmodel.App, daemonsMap map[string]*dbmodel.Daemon) (events []*dbmodel.Event) {
+	accessPoint, err := dbApp.GetAccessPoint(dbmodel.AccessPointControl)
+	if err != nil {
+		return nil
+	}
+	caDaemon, ok := daemonsMap["ca"]
+	reachable := ok && caDaemon.Active
+	if accessPoint.Reachable == reachable {
+		return nil
+	}
+	accessPoint.Reachable = reachable
+	// The events are not raised for the new apps.
+	if dbApp.ID == 0 {
+		return nil
+	}
+	address := net.JoinHostPort(accessPoint.Address, fmt.Sprint(accessPoint.Port))
+	if reachable {
+		events = append(events, eventcenter.CreateEvent(dbmodel.EvInfo,
+			fmt.Sprintf("control access point %s of {app} is reachable again", address),
+			dbApp.Machine, dbApp))
+	} else {
+		events = append(events, eventcenter.CreateEvent(dbmodel.EvWarning,
+			fmt.Sprintf("control access point %s of {app} is unreachable", address),
+			dbApp.Machine, dbApp))
+	}
+	return events
+}
+
+// Checks if the Control Agent is the only daemon on the list. It is the case
+// when the Control Agent has no control sockets configured.
+func hasOnlyControlAgent(daemons []*dbmodel.Daemon) bool {
+	return len(daemons) == 1 && daemons[0].Name == dbmodel.DaemonNameCA
+}
+
 // Detects changes in the returned app state comparing to the state recorded in the
 // database. It raises events when a daemon changes its state between active and
 // inactive state. It also raises events about detected daemon restarts and when
-// configuration change was detected. This function should only be called from
+// configuration change was detected. An informational event is raised when the
+// app has lost all its daemons except the Control Agent. This function should only be called from
 // the GetAppState function. The following values are returned: boolean value
 // indicating whether the app is considered active or inactive after update;
 // a boolean flag indicating whether daemons in the app should be replaced with
 // daemons returned in 3rd argument; list of events to be passed to the event
 // center; map of names of daemons for which configuration remains the same.
-func findChangesAndRaiseEvents(dbApp *dbmodel.App, daemonsMap map[string]*dbmodel.Daemon, daemonsErrors map[string]string) (bool, bool, []*dbmodel.Daemon, []*dbmodel.Event, map[string]bool) {
+// The severities override the default levels of the raised events.
+func findChangesAndRaiseEvents(dbApp *dbmodel.App, daemonsMap map[string]*dbmodel.Daemon, daemonsErrors map[string]string, severities DaemonEventSeverities) (bool, bool, []*dbmodel.Daemon, []*dbmodel.Event, map[string]bool) {
 	var (
 		newDaemons []*dbmodel.Daemon
 		events     []*dbmodel.Event
@@ -374,7 +750,8 @@ func findChangesAndRaiseEvents(dbApp *dbmodel.App, daemonsMap map[string]*dbmode
 				// when creating the event below.
 				oldDaemon.App = dbApp
 				errStr := daemonsErrors[oldDaemon.Name]
-				ev := eventcenter.CreateEvent(dbmodel.EvError, "{daemon} is unreachable", errStr, dbApp.Machine, dbApp, oldDaemon)
+				lvl := severities.getLevel(DaemonEventUnreachable, oldDaemon.Name, dbmodel.EvError)
+				ev := eventcenter.CreateEvent(lvl, "{daemon} is unreachable", errStr, dbApp.Machine, dbApp, oldDaemon)
 				events = append(events, ev)
 			}
 		}
@@ -428,11 +805,12 @@ func findChangesAndRaiseEvents(dbApp *dbmodel.App, daemonsMap map[string]*dbmode
 			if daemon.Active && !oldDaemon.Active {
 				// Daemon was inactive and now it is active again.
 				text += "reachable now"
+				lvl = severities.getLevel(DaemonEventReachable, oldDaemon.Name, lvl)
 			} else if !daemon.Active && oldDaemon.Active {
 				// Daemon was active and now it is inactive. This has higher
 				// severity.
 				text += "unreachable"
-				lvl = dbmodel.EvError
+				lvl = severities.getLevel(DaemonEventUnreachable, oldDaemon.Name, dbmodel.EvError)
 			}
 			errStr := daemonsErrors[oldDaemon.Name]
 			ev := eventcenter.CreateEvent(lvl, text, errStr, dbApp.Machine, dbApp, oldDaemon)
@@ -441,7 +819,8 @@ func findChangesAndRaiseEvents(dbApp *dbmodel.App, daemonsMap map[string]*dbmode
 			// Check if daemon has been restarted.
 		} else if daemon.Uptime < oldDaemon.Uptime {
 			text := "{daemon} has been restarted"
-			ev := eventcenter.CreateEvent(dbmodel.EvWarning, text, dbApp.Machine, dbApp, oldDaemon)
+			lvl := severities.getLevel(DaemonEventRestarted, oldDaemon.Name, dbmodel.EvWarning)
+			ev := eventcenter.CreateEvent(lvl, text, dbApp.Machine, dbApp, oldDaemon)
 			events = append(events, ev)
 		}
 
@@ -449,7 +828,8 @@ func findChangesAndRaiseEvents(dbApp *dbmodel.App, daemonsMap map[string]*dbmode
 		if daemon.Version != oldDaemon.Version {
 			text := fmt.Sprintf("{daemon} version changed from %s to %s",
 				oldDaemon.Version, daemon.Version)
-			ev := eventcenter.CreateEvent(dbmodel.EvWarning, text, dbApp.Machine, dbApp, oldDaemon)
+			lvl := severities.getLevel(DaemonEventVersionChanged, oldDaemon.Name, dbmodel.EvWarning)
+			ev := eventcenter.CreateEvent(lvl, text, dbApp.Machine, dbApp, oldDaemon)
 			events = append(events, ev)
 		}
 
@@ -462,6 +842,14 @@ func findChangesAndRaiseEvents(dbApp *dbmodel.App, daemonsMap map[string]*dbmode
 		}
 	}
 
+	// The Control Agent doesn't forward the commands to any daemons. Raise
+	// the event only when the app had other daemons before to avoid raising
+	// it on every state pull.
+	if hasOnlyControlAgent(newDaemons) && !hasOnlyControlAgent(dbApp.Daemons) {
+		ev := eventcenter.CreateEvent(dbmodel.EvInfo, "{app} has no DHCP or D2 daemons configured in the Control Agent", dbApp.Machine, dbApp)
+		events = append(events, ev)
+	}
+
 	return newActive, true, newDaemons, events, sameConfigDaemons
 }
 
@@ -515,25 +903,25 @@ func deleteDaemonAssociations(tx *pg.Tx, daemon *dbmodel.Daemon) error {
 }
 
 // Deletes empty shared networks and orphaned subnets and hosts.
-func deleteEmptyAndOrphanedObjects(tx *pg.Tx) error {
+func deleteEmptyAndOrphanedObjects(tx *pg.Tx) ([]dbmodel.Subnet, error) {
 	// Removed the hosts that no longer belong to any app.
 	_, err := dbmodel.DeleteOrphanedHosts(tx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Remove the subnets that no longer belong to any daemon.
-	_, err = dbmodel.DeleteOrphanedSubnets(tx)
+	deletedSubnets, err := dbmodel.DeleteAndGetOrphanedSubnets(tx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Delete the shared networks that no longer belong to any daemon.
 	_, err = dbmodel.DeleteOrphanedSharedNetworks(tx)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return deletedSubnets, nil
 }
 
 // Detects and commits the discovered services into the database for each
@@ -574,18 +962,69 @@ func addOnCommitAppEvents(app *dbmodel.App, addedDaemons, deletedDaemons []*dbmo
 	}
 }
 
-// Adds events specific to the recent app/daemon subnets updates.
-func addOnCommitSubnetEvents(app *dbmodel.App, daemon *dbmodel.Daemon, addedSubnets []*dbmodel.Subnet, eventCenter eventcenter.EventCenter) {
-	if len(addedSubnets) > 0 {
-		// add event per subnet only if there is not more than 10 subnets
-		if len(addedSubnets) < 10 {
-			for _, sn := range addedSubnets {
-				eventCenter.AddInfoEvent("added {subnet} to {daemon} in {app}", sn, daemon, app)
-			}
-		}
-		t := fmt.Sprintf("added %d subnets to {daemon} in {app}", len(addedSubnets))
-		eventCenter.AddInfoEvent(t, daemon, app)
+// Adds events specific to the recent app/daemon subnets updates. The
+// per-subnet events are only added when their number doesn't exceed
+// the limit.
+func addOnCommitSubnetEvents(app *dbmodel.App, daemon *dbmodel.Daemon, addedSubnets []*dbmodel.Subnet, limit int64, eventCenter eventcenter.EventCenter) {
+	var events []*dbmodel.Event
+	for _, sn := range addedSubnets {
+		events = append(events, eventcenter.CreateEvent(dbmodel.EvInfo, "added {subnet} to {daemon} in {app}", sn, daemon, app))
 	}
+	t := fmt.Sprintf("added %d subnets to {daemon} in {app}", len(addedSubnets))
+	eventcenter.AddBulkEvents(eventCenter, events, limit, eventcenter.CreateEvent(dbmodel.EvInfo, t, daemon, app))
+}
+
+// Adds events about the subnets removed from the database because they
+// are no longer configured in any daemon. The deleted subnets are not
+// referenced by the events. The per-subnet events are only added when
+// their number doesn't exceed the limit.
+func addOnCommitDeletedSubnetEvents(app *dbmodel.App, deletedSubnets []dbmodel.Subnet, limit int64, eventCenter eventcenter.EventCenter) {
+	var events []*dbmodel.Event
+	for _, sn := range deletedSubnets {
+		t := fmt.Sprintf("removed subnet %s after updating {app}", sn.Prefix)
+		events = append(events, eventcenter.CreateEvent(dbmodel.EvInfo, t, app))
+	}
+	t := fmt.Sprintf("removed %d subnets after updating {app}", len(deletedSubnets))
+	eventcenter.AddBulkEvents(eventCenter, events, limit, eventcenter.CreateEvent(dbmodel.EvInfo, t, app))
+}
+
+// Returns the maximum number of the individual events added for a bulk
+// operation, e.g., adding many subnets. It falls back to the default
+// limit when the setting cannot be read or is negative.
+func getBulkEventsLimit(db *dbops.PgDB) int64 {
+	limit, err := dbmodel.GetSettingInt(db, "bulk_events_limit")
+	if err != nil {
+		log.WithError(err).Warn("Problem getting the limit of events per bulk operation; using the default value")
+		return eventcenter.DefaultBulkEventsLimit
+	}
+	if limit < 0 {
+		log.WithField("limit", limit).Warn("Invalid negative limit of events per bulk operation; using the default value")
+		return eventcenter.DefaultBulkEventsLimit
+	}
+	return limit
+}
+
+// Returns the configuration size of the DHCP daemon, i.e., the number of
+// the subnets and host reservations detected in its configuration. It
+// returns nil for the daemons other than the DHCP servers and the daemons
+// without the configuration.
+func getDaemonConfigSize(daemon *dbmodel.Daemon, networks []dbmodel.SharedNetwork, subnets []dbmodel.Subnet, globalHosts []dbmodel.Host) *dbmodel.DaemonConfigSize {
+	if (daemon.Name != dhcp4 && daemon.Name != dhcp6) || daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil {
+		return nil
+	}
+	size := &dbmodel.DaemonConfigSize{
+		DaemonID:         daemon.ID,
+		RecordedAt:       storkutil.UTCNow(),
+		ReservationCount: int64(len(globalHosts)),
+	}
+	for _, network := range networks {
+		subnets = append(subnets, network.Subnets...)
+	}
+	for _, subnet := range subnets {
+		size.SubnetCount++
+		size.ReservationCount += int64(len(subnet.Hosts))
+	}
+	return size
 }
 
 // Inserts or updates information about Kea app in the database. Next, it extracts
@@ -593,6 +1032,7 @@ func addOnCommitSubnetEvents(app *dbmodel.App, daemon *dbmodel.Daemon, addedSubn
 // subnets and pools. Finally, the relations between the subnets and the Kea app
 // are created. Note that multiple apps can be associated with the same subnet.
 func CommitAppIntoDB(db *dbops.PgDB, app *dbmodel.App, eventCenter eventcenter.EventCenter, state *AppStateMeta, lookup keaconfig.DHCPOptionDefinitionLookup) (err error) {
+	eventsLimit := getBulkEventsLimit(db)
 	err = db.RunInTransaction(context.Background(), func(tx *pg.Tx) error {
 		networks := make(map[string][]dbmodel.SharedNetwork)
 		subnets := make(map[string][]dbmodel.Subnet)
@@ -667,7 +1107,18 @@ func CommitAppIntoDB(db *dbops.PgDB, app *dbmodel.App, eventCenter eventcenter.E
 			}
 
 			// Add subnet related events to the database.
-			addOnCommitSubnetEvents(app, daemon, addedSubnets, eventCenter)
+			addOnCommitSubnetEvents(app, daemon, addedSubnets, eventsLimit, eventCenter)
+
+			// Record the configuration size to track its growth. The size is
+			// recorded only when the daemon's configuration has been detected,
+			// i.e., it has changed since the last update.
+			if _, detected := subnets[daemon.Name]; detected {
+				if size := getDaemonConfigSize(daemon, networks[daemon.Name], subnets[daemon.Name], globalHosts[daemon.Name]); size != nil {
+					if err = dbmodel.AddDaemonConfigSize(tx, size); err != nil {
+						return err
+					}
+				}
+			}
 		}
 
 		// Detect and commit discovered services for each daemon.
@@ -676,8 +1127,12 @@ func CommitAppIntoDB(db *dbops.PgDB, app *dbmodel.App, eventCenter eventcenter.E
 		}
 
 		// Remove empty shared networks and orphaned subnets and hosts.
-		err = deleteEmptyAndOrphanedObjects(tx)
-		return err
+		deletedSubnets, err := deleteEmptyAndOrphanedObjects(tx)
+		if err != nil {
+			return err
+		}
+		addOnCommitDeletedSubnetEvents(app, deletedSubnets, eventsLimit, eventCenter)
+		return nil
 	})
 	return errors.Wrapf(err, "problem committing updates for app %d", app.ID)
 }
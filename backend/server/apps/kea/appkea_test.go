@@ -2,10 +2,15 @@ package kea
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 	keactrl "isc.org/stork/appctrl/kea"
+	"isc.org/stork/server/agentcomm"
 	agentcommtest "isc.org/stork/server/agentcomm/test"
 	dbmodel "isc.org/stork/server/database/model"
 	dbtest "isc.org/stork/server/database/test"
@@ -87,9 +92,14 @@ func mockGetConfigFromCAResponse(daemons int, cmdResponses []interface{}) {
 	}
 }
 
-// Kea servers' response to config-get command from other Kea daemons. The argument indicates if
-// it is a response from a single server or two servers.
+// Kea servers' response to version-get, status-get and config-hash-get or config-get
+// commands from other Kea daemons. The argument indicates if it is a response from a
+// single server or two servers.
 func mockGetConfigFromOtherDaemonsResponse(daemons int, cmdResponses []interface{}) {
+	if _, ok := cmdResponses[0].(*[]keactrl.HashedResponse); ok {
+		mockConfigGetFromOtherDaemonsResponse(daemons, cmdResponses)
+		return
+	}
 	// version-get response
 	list1 := cmdResponses[0].(*[]VersionGetResponse)
 	*list1 = []VersionGetResponse{
@@ -138,9 +148,37 @@ func mockGetConfigFromOtherDaemonsResponse(daemons int, cmdResponses []interface
 			},
 		})
 	}
-	// config-get response
-	list3 := cmdResponses[2].(*[]keactrl.HashedResponse)
-	*list3 = []keactrl.HashedResponse{
+	// config-hash-get response
+	list3 := cmdResponses[2].(*[]ConfigHashGetResponse)
+	*list3 = []ConfigHashGetResponse{
+		{
+			ResponseHeader: keactrl.ResponseHeader{
+				Result: 0,
+				Daemon: "dhcp4",
+			},
+			Arguments: &ConfigHashGetRespArgs{
+				Hash: "kea-hash1",
+			},
+		},
+	}
+	if daemons > 1 {
+		*list3 = append(*list3, ConfigHashGetResponse{
+			ResponseHeader: keactrl.ResponseHeader{
+				Result: 0,
+				Daemon: "dhcp6",
+			},
+			Arguments: &ConfigHashGetRespArgs{
+				Hash: "kea-hash2",
+			},
+		})
+	}
+}
+
+// Kea servers' response to config-get command from other Kea daemons. The argument
+// indicates if it is a response from a single server or two servers.
+func mockConfigGetFromOtherDaemonsResponse(daemons int, cmdResponses []interface{}) {
+	list := cmdResponses[0].(*[]keactrl.HashedResponse)
+	*list = []keactrl.HashedResponse{
 		{
 			ResponseHeader: keactrl.ResponseHeader{
 				Result: 0,
@@ -160,9 +198,9 @@ func mockGetConfigFromOtherDaemonsResponse(daemons int, cmdResponses []interface
 			},
 		},
 	}
-	(*list3)[0].ArgumentsHash = "hash1"
+	(*list)[0].ArgumentsHash = "hash1"
 	if daemons > 1 {
-		*list3 = append(*list3, keactrl.HashedResponse{
+		*list = append(*list, keactrl.HashedResponse{
 			ResponseHeader: keactrl.ResponseHeader{
 				Result: 0,
 				Daemon: "dhcp6",
@@ -180,7 +218,7 @@ func mockGetConfigFromOtherDaemonsResponse(daemons int, cmdResponses []interface
 				},
 			},
 		})
-		(*list3)[1].ArgumentsHash = "hash2"
+		(*list)[1].ArgumentsHash = "hash2"
 	}
 }
 
@@ -192,7 +230,7 @@ func TestGetAppStateWith1Daemon(t *testing.T) {
 	keaMock := func(callNo int, cmdResponses []interface{}) {
 		if callNo == 0 {
 			mockGetConfigFromCAResponse(1, cmdResponses)
-		} else if callNo == 1 {
+		} else {
 			mockGetConfigFromOtherDaemonsResponse(1, cmdResponses)
 		}
 	}
@@ -210,7 +248,7 @@ func TestGetAppStateWith1Daemon(t *testing.T) {
 		},
 	}
 
-	GetAppState(ctx, fa, &dbApp, fec)
+	GetAppState(ctx, fa, &dbApp, fec, nil, nil)
 
 	require.Contains(t, fa.RecordedURLs, "https://192.0.2.0:1234/")
 	require.Equal(t, "version-get", fa.RecordedCommands[0].GetCommand())
@@ -224,7 +262,7 @@ func TestGetAppStateWith2Daemons(t *testing.T) {
 	keaMock := func(callNo int, cmdResponses []interface{}) {
 		if callNo == 0 {
 			mockGetConfigFromCAResponse(2, cmdResponses)
-		} else if callNo == 1 {
+		} else {
 			mockGetConfigFromOtherDaemonsResponse(2, cmdResponses)
 		}
 	}
@@ -242,22 +280,706 @@ func TestGetAppStateWith2Daemons(t *testing.T) {
 		},
 	}
 
-	GetAppState(ctx, fa, &dbApp, fec)
+	GetAppState(ctx, fa, &dbApp, fec, nil, nil)
 
 	require.Contains(t, fa.RecordedURLs, "http://192.0.2.0:1234/")
 	require.Equal(t, "version-get", fa.RecordedCommands[0].GetCommand())
 	require.Equal(t, "config-get", fa.RecordedCommands[1].GetCommand())
 }
 
+// Fake agents returning the specified errors for the Kea commands, e.g.,
+// the communication timeouts.
+type cmdsErrorsFakeAgents struct {
+	*agentcommtest.FakeAgents
+	cmdsErrors []error
+}
+
+// Forwards the commands to the fake agents and sets the command errors in
+// the returned result.
+func (fa *cmdsErrorsFakeAgents) ForwardToKeaOverHTTP(ctx context.Context, app agentcomm.ControlledApp, commands []keactrl.SerializableCommand, cmdResponses ...interface{}) (*agentcomm.KeaCmdsResult, error) {
+	result, err := fa.FakeAgents.ForwardToKeaOverHTTP(ctx, app, commands, cmdResponses...)
+	if err == nil {
+		copy(result.CmdsErrors, fa.cmdsErrors)
+	}
+	return result, err
+}
+
+// Fake agents failing to communicate with the Kea Control Agent the
+// specified number of times before forwarding the commands.
+type failingFakeAgents struct {
+	*agentcommtest.FakeAgents
+	failures int
+	attempts int
+}
+
+// Returns an error for the first calls and forwards the commands to the
+// fake agents afterwards.
+func (fa *failingFakeAgents) ForwardToKeaOverHTTP(ctx context.Context, app agentcomm.ControlledApp, commands []keactrl.SerializableCommand, cmdResponses ...interface{}) (*agentcomm.KeaCmdsResult, error) {
+	fa.attempts++
+	if fa.attempts <= fa.failures {
+		return nil, errors.New("connection refused")
+	}
+	return fa.FakeAgents.ForwardToKeaOverHTTP(ctx, app, commands, cmdResponses...)
+}
+
+// Test that the app state pull settings return the defaults when they
+// are not specified and the backoff is doubled for each retry.
+func TestAppStatePullSettings(t *testing.T) {
+	// Arrange
+	var nilSettings *AppStatePullSettings
+	settings := &AppStatePullSettings{
+		Timeout: 5 * time.Second,
+		Retries: 3,
+		Backoff: 100 * time.Millisecond,
+	}
+
+	// Act & Assert
+	require.Equal(t, DefaultAppStateTimeout, nilSettings.getTimeout())
+	require.Zero(t, nilSettings.getRetries())
+	require.Zero(t, nilSettings.getBackoff(1))
+
+	require.Equal(t, 5*time.Second, settings.getTimeout())
+	require.Equal(t, 3, settings.getRetries())
+	require.Zero(t, settings.getBackoff(0))
+	require.Equal(t, 100*time.Millisecond, settings.getBackoff(1))
+	require.Equal(t, 200*time.Millisecond, settings.getBackoff(2))
+	require.Equal(t, 400*time.Millisecond, settings.getBackoff(3))
+}
+
+// Test that the copy of the app state pull settings with the overridden
+// timeout is returned.
+func TestAppStatePullSettingsWithTimeout(t *testing.T) {
+	// Arrange
+	var nilSettings *AppStatePullSettings
+	settings := &AppStatePullSettings{
+		Timeout: 5 * time.Second,
+		Retries: 3,
+		Backoff: 100 * time.Millisecond,
+	}
+
+	// Act
+	fromNil := nilSettings.WithTimeout(30 * time.Second)
+	copied := settings.WithTimeout(30 * time.Second)
+
+	// Assert
+	require.Equal(t, 30*time.Second, fromNil.getTimeout())
+	require.Zero(t, fromNil.getRetries())
+
+	require.Equal(t, 30*time.Second, copied.getTimeout())
+	require.Equal(t, 3, copied.getRetries())
+	require.Equal(t, 100*time.Millisecond, copied.getBackoff(1))
+	require.Equal(t, 5*time.Second, settings.getTimeout())
+}
+
+// Test that the implausible uptimes of the daemons not belonging to the
+// specified apps are discarded.
+func TestAppStatePullSettingsPruneImplausibleUptimes(t *testing.T) {
+	// Arrange
+	var accessPoints []*dbmodel.AccessPoint
+	accessPoints = dbmodel.AppendAccessPoint(accessPoints, dbmodel.AccessPointControl, "192.0.2.1", "", 8000, true)
+	app := &dbmodel.App{
+		Type:         dbmodel.AppTypeKea,
+		AccessPoints: accessPoints,
+		Daemons: []*dbmodel.Daemon{
+			{Name: dhcp4},
+		},
+	}
+	settings := &AppStatePullSettings{
+		ImplausibleUptimes: NewImplausibleUptimeTracker(),
+	}
+	settings.updateImplausibleUptime(getUptimeTrackerKey(app, dhcp4), true)
+	settings.updateImplausibleUptime(getUptimeTrackerKey(app, dhcp6), true)
+	settings.updateImplausibleUptime("192.0.2.2:8000/dhcp4", true)
+
+	// Act
+	settings.PruneImplausibleUptimes([]*dbmodel.App{app})
+
+	// Assert
+	require.Len(t, settings.ImplausibleUptimes.daemons, 1)
+	require.True(t, settings.ImplausibleUptimes.daemons[getUptimeTrackerKey(app, dhcp4)])
+	// The daemon still reporting the implausible uptime is not logged again.
+	require.False(t, settings.updateImplausibleUptime(getUptimeTrackerKey(app, dhcp4), true))
+	// The deleted daemon is logged again when it is re-added.
+	require.True(t, settings.updateImplausibleUptime(getUptimeTrackerKey(app, dhcp6), true))
+}
+
+// Test that the implausible uptimes are reported on every update when
+// the tracker is not specified.
+func TestAppStatePullSettingsNoImplausibleUptimeTracker(t *testing.T) {
+	// Arrange
+	var nilSettings *AppStatePullSettings
+	settings := &AppStatePullSettings{}
+
+	// Act & Assert
+	require.True(t, nilSettings.updateImplausibleUptime("foo", true))
+	require.True(t, nilSettings.updateImplausibleUptime("foo", true))
+	require.False(t, settings.updateImplausibleUptime("foo", false))
+	require.True(t, settings.updateImplausibleUptime("foo", true))
+	require.NotPanics(t, func() {
+		nilSettings.PruneImplausibleUptimes(nil)
+		settings.PruneImplausibleUptimes(nil)
+	})
+}
+
+// Test that the commands sent to the Control Agent are retried until
+// they succeed.
+func TestGetStateFromCARetries(t *testing.T) {
+	// Arrange
+	keaMock := func(callNo int, cmdResponses []interface{}) {
+		mockGetConfigFromCAResponse(2, cmdResponses)
+	}
+	fa := &failingFakeAgents{
+		FakeAgents: agentcommtest.NewFakeAgents(keaMock, nil),
+		failures:   2,
+	}
+	settings := &AppStatePullSettings{Retries: 2, Backoff: time.Millisecond}
+	daemonsMap := map[string]*dbmodel.Daemon{}
+	daemonsErrors := map[string]string{}
+
+	// Act
+	allDaemons, _, err := getStateFromCA(context.Background(), fa, &dbmodel.App{},
+		daemonsMap, daemonsErrors, settings)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, 3, fa.attempts)
+	require.ElementsMatch(t, []string{dhcp4, dhcp6}, allDaemons)
+	require.True(t, daemonsMap["ca"].Active)
+	require.Empty(t, daemonsErrors)
+}
+
+// Test that the status of the Control Agent is returned and its uptime is
+// recorded. The status is not returned when the Control Agent doesn't
+// support the status-get command.
+func TestGetStateFromCAStatus(t *testing.T) {
+	// Arrange
+	keaMock := func(callNo int, cmdResponses []interface{}) {
+		mockGetConfigFromCAResponse(1, cmdResponses)
+		status := StatusGetResponse{
+			ResponseHeader: keactrl.ResponseHeader{Result: keactrl.ResponseSuccess, Daemon: "ca"},
+			Arguments:      &StatusGetRespArgs{Pid: 123, Uptime: 100, Reload: 50},
+		}
+		if callNo > 0 {
+			status = StatusGetResponse{
+				ResponseHeader: keactrl.ResponseHeader{Result: keactrl.ResponseCommandUnsupported, Daemon: "ca"},
+			}
+		}
+		*(cmdResponses[2].(*[]StatusGetResponse)) = []StatusGetResponse{status}
+	}
+	fa := agentcommtest.NewFakeAgents(keaMock, nil)
+	daemonsMap := map[string]*dbmodel.Daemon{}
+
+	// Act
+	_, caStatus, err := getStateFromCA(context.Background(), fa, &dbmodel.App{},
+		daemonsMap, map[string]string{}, nil)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, caStatus)
+	require.EqualValues(t, 100, caStatus.Uptime)
+	require.EqualValues(t, 100, daemonsMap["ca"].Uptime)
+	require.Equal(t, "status-get", fa.RecordedCommands[2].GetCommand())
+
+	// Act
+	daemonsMap = map[string]*dbmodel.Daemon{}
+	_, caStatus, err = getStateFromCA(context.Background(), fa, &dbmodel.App{},
+		daemonsMap, map[string]string{}, nil)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, caStatus)
+	require.True(t, daemonsMap["ca"].Active)
+	require.Zero(t, daemonsMap["ca"].Uptime)
+}
+
+// Test that an error is returned when all retries of the commands sent
+// to the Control Agent fail.
+func TestGetStateFromCARetriesExhausted(t *testing.T) {
+	// Arrange
+	keaMock := func(callNo int, cmdResponses []interface{}) {
+		mockGetConfigFromCAResponse(2, cmdResponses)
+	}
+	fa := &failingFakeAgents{
+		FakeAgents: agentcommtest.NewFakeAgents(keaMock, nil),
+		failures:   3,
+	}
+	settings := &AppStatePullSettings{Retries: 2, Backoff: time.Millisecond}
+
+	// Act
+	_, _, err := getStateFromCA(context.Background(), fa, &dbmodel.App{},
+		map[string]*dbmodel.Daemon{}, map[string]string{}, settings)
+
+	// Assert
+	require.ErrorContains(t, err, "connection refused")
+	require.Equal(t, 3, fa.attempts)
+}
+
+// Test that the unreachable event is not raised when the communication
+// with the Control Agent fails transiently.
+func TestGetAppStateCATransientFailure(t *testing.T) {
+	// Arrange
+	keaMock := func(callNo int, cmdResponses []interface{}) {
+		if callNo == 0 {
+			mockGetConfigFromCAResponse(1, cmdResponses)
+		} else {
+			mockGetConfigFromOtherDaemonsResponse(1, cmdResponses)
+		}
+	}
+	fa := &failingFakeAgents{
+		FakeAgents: agentcommtest.NewFakeAgents(keaMock, nil),
+		failures:   1,
+	}
+	fec := &storktest.FakeEventCenter{}
+
+	var accessPoints []*dbmodel.AccessPoint
+	accessPoints = dbmodel.AppendAccessPoint(accessPoints, dbmodel.AccessPointControl, "192.0.2.0", "", 1234, false)
+	accessPoints[0].Reachable = true
+
+	dbApp := dbmodel.App{
+		ID:           1,
+		Active:       true,
+		AccessPoints: accessPoints,
+		Machine: &dbmodel.Machine{
+			Address:   "192.0.2.0",
+			AgentPort: 1111,
+		},
+		Daemons: []*dbmodel.Daemon{
+			dbmodel.NewKeaDaemon(dbmodel.DaemonNameCA, true),
+			dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true),
+		},
+	}
+	settings := &AppStatePullSettings{Retries: 1, Backoff: time.Millisecond}
+
+	// Act
+	state := GetAppState(context.Background(), fa, &dbApp, fec, nil, settings)
+
+	// Assert
+	require.NotNil(t, state)
+	// The failed attempt, the retry to the CA, and the commands sent to
+	// the DHCPv4 daemon.
+	require.Equal(t, 4, fa.attempts)
+	require.True(t, dbApp.Active)
+	require.True(t, dbApp.AccessPoints[0].Reachable)
+	for _, event := range state.Events {
+		require.NotContains(t, event.Text, "unreachable")
+	}
+}
+
+// Test that the responses from the responsive daemons are processed and
+// only the daemon missing from the responses is marked inactive.
+func TestGetStateFromDaemonsUnresponsiveDaemon(t *testing.T) {
+	// Arrange
+	keaMock := func(callNo int, cmdResponses []interface{}) {
+		mockGetConfigFromOtherDaemonsResponse(1, cmdResponses)
+	}
+	fa := agentcommtest.NewFakeAgents(keaMock, nil)
+	dbApp := &dbmodel.App{}
+	daemonsMap := map[string]*dbmodel.Daemon{}
+	daemonsErrors := map[string]string{}
+
+	// Act
+	err := getStateFromDaemons(context.Background(), fa, dbApp, daemonsMap,
+		[]string{dhcp4, dhcp6}, nil, daemonsErrors, nil)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, daemonsMap, 2)
+	require.True(t, daemonsMap[dhcp4].Active)
+	require.Equal(t, "Extended version", daemonsMap[dhcp4].ExtendedVersion)
+	require.NotNil(t, daemonsMap[dhcp4].KeaDaemon.Config)
+	require.NotContains(t, daemonsErrors, dhcp4)
+
+	require.False(t, daemonsMap[dhcp6].Active)
+	require.Equal(t, "no version-get response from kea daemon dhcp6", daemonsErrors[dhcp6])
+}
+
+// Test that a failure of one command doesn't stop processing the responses
+// to the other commands.
+func TestGetStateFromDaemonsCommandError(t *testing.T) {
+	// Arrange
+	keaMock := func(callNo int, cmdResponses []interface{}) {
+		mockGetConfigFromOtherDaemonsResponse(2, cmdResponses)
+		// The status-get command failed, so there are no responses.
+		if len(cmdResponses) > 1 {
+			*(cmdResponses[1].(*[]StatusGetResponse)) = []StatusGetResponse{}
+		}
+	}
+	fa := &cmdsErrorsFakeAgents{
+		FakeAgents: agentcommtest.NewFakeAgents(keaMock, nil),
+		cmdsErrors: []error{nil, errors.New("timeout")},
+	}
+	dbApp := &dbmodel.App{}
+	daemonsMap := map[string]*dbmodel.Daemon{}
+	daemonsErrors := map[string]string{}
+
+	// Act
+	err := getStateFromDaemons(context.Background(), fa, dbApp, daemonsMap,
+		[]string{dhcp4, dhcp6}, nil, daemonsErrors, nil)
+
+	// Assert
+	require.ErrorContains(t, err, "problem with status-get response: timeout")
+	for _, name := range []string{dhcp4, dhcp6} {
+		require.False(t, daemonsMap[name].Active)
+		require.Equal(t, "Extended version", daemonsMap[name].ExtendedVersion)
+		require.NotNil(t, daemonsMap[name].KeaDaemon.Config)
+		require.Equal(t, fmt.Sprintf("problem with status-get and kea daemon %s: timeout", name), daemonsErrors[name])
+	}
+}
+
+// Test that the configuration is fetched only from the daemon whose
+// configuration hash has changed.
+func TestGetStateFromDaemonsConfigHashChanged(t *testing.T) {
+	// Arrange
+	keaMock := func(callNo int, cmdResponses []interface{}) {
+		if callNo == 0 {
+			mockGetConfigFromOtherDaemonsResponse(2, cmdResponses)
+		} else {
+			// Only the DHCPv4 daemon is expected to return its configuration.
+			mockConfigGetFromOtherDaemonsResponse(1, cmdResponses)
+		}
+	}
+	fa := agentcommtest.NewFakeAgents(keaMock, nil)
+	dbApp := &dbmodel.App{
+		Daemons: []*dbmodel.Daemon{
+			dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true),
+			dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv6, true),
+		},
+	}
+	for i, daemon := range dbApp.Daemons {
+		err := daemon.SetConfigFromJSON(fmt.Sprintf(`{"Dhcp%d": {}}`, 4+2*i))
+		require.NoError(t, err)
+	}
+	dbApp.Daemons[0].KeaDaemon.KeaConfigHash = "kea-hash0"
+	dbApp.Daemons[1].KeaDaemon.KeaConfigHash = "kea-hash2"
+	dhcp6Config := dbApp.Daemons[1].KeaDaemon.Config
+	daemonsMap := map[string]*dbmodel.Daemon{}
+	daemonsErrors := map[string]string{}
+
+	// Act
+	err := getStateFromDaemons(context.Background(), fa, dbApp, daemonsMap,
+		[]string{dhcp4, dhcp6}, nil, daemonsErrors, nil)
+
+	// Assert
+	require.NoError(t, err)
+	require.Empty(t, daemonsErrors)
+	require.Len(t, fa.RecordedCommands, 4)
+	require.Equal(t, "config-hash-get", fa.RecordedCommands[2].GetCommand())
+	require.Equal(t, "config-get", fa.RecordedCommands[3].GetCommand())
+	require.Equal(t, []string{dhcp4}, fa.RecordedCommands[3].GetDaemonsList())
+
+	require.True(t, daemonsMap[dhcp4].Active)
+	require.Equal(t, "hash1", daemonsMap[dhcp4].KeaDaemon.ConfigHash)
+	require.Equal(t, "kea-hash1", daemonsMap[dhcp4].KeaDaemon.KeaConfigHash)
+	require.True(t, daemonsMap[dhcp6].Active)
+	require.Same(t, dhcp6Config, daemonsMap[dhcp6].KeaDaemon.Config)
+	require.Equal(t, "kea-hash2", daemonsMap[dhcp6].KeaDaemon.KeaConfigHash)
+}
+
+// Test that the configuration is fetched from the daemon when its config
+// hash stored in the database is NULL, even though the hash returned by
+// Kea matches. The database migrations reset the config hash to force
+// fetching the configurations.
+func TestGetStateFromDaemonsConfigHashReset(t *testing.T) {
+	// Arrange
+	keaMock := func(callNo int, cmdResponses []interface{}) {
+		if callNo == 0 {
+			mockGetConfigFromOtherDaemonsResponse(1, cmdResponses)
+		} else {
+			mockConfigGetFromOtherDaemonsResponse(1, cmdResponses)
+		}
+	}
+	fa := agentcommtest.NewFakeAgents(keaMock, nil)
+	dbApp := &dbmodel.App{
+		Daemons: []*dbmodel.Daemon{
+			dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true),
+		},
+	}
+	err := dbApp.Daemons[0].SetConfigFromJSON(`{"Dhcp4": {}}`)
+	require.NoError(t, err)
+	// The NULL config hash is read from the database as an empty string.
+	dbApp.Daemons[0].KeaDaemon.ConfigHash = ""
+	dbApp.Daemons[0].KeaDaemon.KeaConfigHash = "kea-hash1"
+	daemonsMap := map[string]*dbmodel.Daemon{}
+	daemonsErrors := map[string]string{}
+
+	// Act
+	err = getStateFromDaemons(context.Background(), fa, dbApp, daemonsMap,
+		[]string{dhcp4}, nil, daemonsErrors, nil)
+
+	// Assert
+	require.NoError(t, err)
+	require.Empty(t, daemonsErrors)
+	require.Len(t, fa.RecordedCommands, 4)
+	require.Equal(t, "config-get", fa.RecordedCommands[3].GetCommand())
+	require.Equal(t, []string{dhcp4}, fa.RecordedCommands[3].GetDaemonsList())
+	require.Equal(t, "hash1", daemonsMap[dhcp4].KeaDaemon.ConfigHash)
+	require.Equal(t, "kea-hash1", daemonsMap[dhcp4].KeaDaemon.KeaConfigHash)
+}
+
+// Test that the configuration is always fetched from the daemon that
+// doesn't support the config-hash-get command.
+func TestGetStateFromDaemonsConfigHashUnsupported(t *testing.T) {
+	// Arrange
+	keaMock := func(callNo int, cmdResponses []interface{}) {
+		mockGetConfigFromOtherDaemonsResponse(1, cmdResponses)
+		if len(cmdResponses) > 1 {
+			*(cmdResponses[2].(*[]ConfigHashGetResponse)) = []ConfigHashGetResponse{
+				{
+					ResponseHeader: keactrl.ResponseHeader{
+						Result: 2,
+						Text:   "'config-hash-get' command not supported.",
+						Daemon: dhcp4,
+					},
+				},
+			}
+		}
+	}
+	fa := agentcommtest.NewFakeAgents(keaMock, nil)
+	dbApp := &dbmodel.App{
+		Daemons: []*dbmodel.Daemon{
+			dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true),
+		},
+	}
+	err := dbApp.Daemons[0].SetConfigFromJSON(`{"Dhcp4": {}}`)
+	require.NoError(t, err)
+	daemonsMap := map[string]*dbmodel.Daemon{}
+	daemonsErrors := map[string]string{}
+
+	// Act
+	err = getStateFromDaemons(context.Background(), fa, dbApp, daemonsMap,
+		[]string{dhcp4}, nil, daemonsErrors, nil)
+
+	// Assert
+	require.NoError(t, err)
+	require.Empty(t, daemonsErrors)
+	require.Len(t, fa.RecordedCommands, 4)
+	require.Equal(t, "config-get", fa.RecordedCommands[3].GetCommand())
+	require.True(t, daemonsMap[dhcp4].Active)
+	require.Equal(t, "hash1", daemonsMap[dhcp4].KeaDaemon.ConfigHash)
+	require.Empty(t, daemonsMap[dhcp4].KeaDaemon.KeaConfigHash)
+}
+
+// Returns the function mocking the responses of the D2 daemon to the
+// version-get, status-get and config-get commands. The status-get result
+// code is specified.
+func mockD2StateResponses(statusResult int) func(int, []interface{}) {
+	return mockD2StateResponsesWithUptime(statusResult, 100, 50)
+}
+
+// Returns a function generating the responses to the commands sent to the
+// D2 daemon. The status-get response includes the specified uptime and the
+// time elapsed since the last reload.
+func mockD2StateResponsesWithUptime(statusResult int, uptime, reload int64) func(int, []interface{}) {
+	return func(callNo int, cmdResponses []interface{}) {
+		if len(cmdResponses) == 1 {
+			*(cmdResponses[0].(*[]keactrl.HashedResponse)) = []keactrl.HashedResponse{
+				{
+					ResponseHeader: keactrl.ResponseHeader{Daemon: d2},
+					Arguments: &map[string]interface{}{
+						"DhcpDdns": map[string]interface{}{},
+					},
+					ArgumentsHash: "hash",
+				},
+			}
+			return
+		}
+		*(cmdResponses[0].(*[]VersionGetResponse)) = []VersionGetResponse{
+			{
+				ResponseHeader: keactrl.ResponseHeader{Daemon: d2, Text: "2.4.0"},
+			},
+		}
+		status := StatusGetResponse{
+			ResponseHeader: keactrl.ResponseHeader{Result: statusResult, Daemon: d2},
+		}
+		if statusResult == keactrl.ResponseSuccess {
+			status.Arguments = &StatusGetRespArgs{Pid: 123, Uptime: uptime, Reload: reload}
+		} else {
+			status.Text = "'status-get' command not supported."
+		}
+		*(cmdResponses[1].(*[]StatusGetResponse)) = []StatusGetResponse{status}
+	}
+}
+
+// Test that the runtime state of the D2 daemon is fetched.
+func TestGetStateFromDaemonsD2Status(t *testing.T) {
+	// Arrange
+	fa := agentcommtest.NewFakeAgents(mockD2StateResponses(keactrl.ResponseSuccess), nil)
+	daemonsMap := map[string]*dbmodel.Daemon{}
+	daemonsErrors := map[string]string{}
+
+	// Act
+	err := getStateFromDaemons(context.Background(), fa, &dbmodel.App{}, daemonsMap,
+		[]string{d2}, nil, daemonsErrors, nil)
+
+	// Assert
+	require.NoError(t, err)
+	require.Empty(t, daemonsErrors)
+	require.Equal(t, []string{d2}, fa.RecordedCommands[1].GetDaemonsList())
+	require.True(t, daemonsMap[d2].Active)
+	require.Equal(t, "2.4.0", daemonsMap[d2].Version)
+	require.EqualValues(t, 100, daemonsMap[d2].Uptime)
+	require.False(t, daemonsMap[d2].ReloadedAt.IsZero())
+	require.NotNil(t, daemonsMap[d2].KeaDaemon.Config)
+}
+
+// Test that the implausible uptime and reload time returned by the daemon
+// don't cause the daemon to be marked inactive.
+func TestGetStateFromDaemonsImplausibleUptime(t *testing.T) {
+	// Arrange
+	fa := agentcommtest.NewFakeAgents(mockD2StateResponsesWithUptime(keactrl.ResponseSuccess, 100, -50), nil)
+	daemonsMap := map[string]*dbmodel.Daemon{}
+	daemonsErrors := map[string]string{}
+
+	settings := &AppStatePullSettings{
+		ImplausibleUptimes: NewImplausibleUptimeTracker(),
+	}
+
+	// Act
+	err := getStateFromDaemons(context.Background(), fa, &dbmodel.App{}, daemonsMap,
+		[]string{d2}, nil, daemonsErrors, settings)
+
+	// Assert
+	require.NoError(t, err)
+	require.Empty(t, daemonsErrors)
+	require.True(t, settings.ImplausibleUptimes.daemons[getUptimeTrackerKey(&dbmodel.App{}, d2)])
+	require.True(t, daemonsMap[d2].Active)
+	require.EqualValues(t, 100, daemonsMap[d2].Uptime)
+	// The negative reload time results in the reload timestamp in the future.
+	require.True(t, daemonsMap[d2].ReloadedAt.After(time.Now()))
+}
+
+// Test that the daemon's uptime exceeding the Control Agent's uptime
+// doesn't cause the daemon to be marked inactive.
+func TestGetStateFromDaemonsUptimeExceedingCA(t *testing.T) {
+	// Arrange
+	fa := agentcommtest.NewFakeAgents(mockD2StateResponsesWithUptime(keactrl.ResponseSuccess, 100000, 50), nil)
+	daemonsMap := map[string]*dbmodel.Daemon{}
+	daemonsErrors := map[string]string{}
+	caStatus := &StatusGetRespArgs{Uptime: 100}
+
+	// Act
+	err := getStateFromDaemons(context.Background(), fa, &dbmodel.App{}, daemonsMap,
+		[]string{d2}, caStatus, daemonsErrors, nil)
+
+	// Assert
+	require.NoError(t, err)
+	require.Empty(t, daemonsErrors)
+	require.True(t, daemonsMap[d2].Active)
+	require.EqualValues(t, 100000, daemonsMap[d2].Uptime)
+}
+
+// Test that the D2 daemon not supporting the status-get command is not
+// marked inactive.
+func TestGetStateFromDaemonsD2StatusUnsupported(t *testing.T) {
+	// Arrange
+	fa := agentcommtest.NewFakeAgents(mockD2StateResponses(keactrl.ResponseCommandUnsupported), nil)
+	daemonsMap := map[string]*dbmodel.Daemon{}
+	daemonsErrors := map[string]string{}
+
+	// Act
+	err := getStateFromDaemons(context.Background(), fa, &dbmodel.App{}, daemonsMap,
+		[]string{d2}, nil, daemonsErrors, nil)
+
+	// Assert
+	require.NoError(t, err)
+	require.Empty(t, daemonsErrors)
+	require.True(t, daemonsMap[d2].Active)
+	require.Equal(t, "2.4.0", daemonsMap[d2].Version)
+	require.Zero(t, daemonsMap[d2].Uptime)
+	require.NotNil(t, daemonsMap[d2].KeaDaemon.Config)
+}
+
+// Test that the DHCP daemon not supporting the status-get command is
+// marked inactive.
+func TestGetStateFromDaemonsDHCPStatusUnsupported(t *testing.T) {
+	// Arrange
+	keaMock := func(callNo int, cmdResponses []interface{}) {
+		mockGetConfigFromOtherDaemonsResponse(1, cmdResponses)
+		if len(cmdResponses) > 1 {
+			status := &(*(cmdResponses[1].(*[]StatusGetResponse)))[0]
+			status.Result = keactrl.ResponseCommandUnsupported
+			status.Text = "'status-get' command not supported."
+		}
+	}
+	fa := agentcommtest.NewFakeAgents(keaMock, nil)
+	daemonsMap := map[string]*dbmodel.Daemon{}
+	daemonsErrors := map[string]string{}
+
+	// Act
+	err := getStateFromDaemons(context.Background(), fa, &dbmodel.App{}, daemonsMap,
+		[]string{dhcp4}, nil, daemonsErrors, nil)
+
+	// Assert
+	require.NoError(t, err)
+	require.False(t, daemonsMap[dhcp4].Active)
+	require.Contains(t, daemonsErrors[dhcp4], "problem with status-get and kea daemon dhcp4")
+}
+
+// Test that the unreachable event is raised only for the daemon that
+// didn't respond while the other daemon remains active.
+func TestGetAppStateUnresponsiveDaemon(t *testing.T) {
+	// Arrange
+	keaMock := func(callNo int, cmdResponses []interface{}) {
+		if callNo == 0 {
+			mockGetConfigFromCAResponse(2, cmdResponses)
+		} else {
+			mockGetConfigFromOtherDaemonsResponse(1, cmdResponses)
+		}
+	}
+	fa := agentcommtest.NewFakeAgents(keaMock, nil)
+	fec := &storktest.FakeEventCenter{}
+
+	var accessPoints []*dbmodel.AccessPoint
+	accessPoints = dbmodel.AppendAccessPoint(accessPoints, dbmodel.AccessPointControl, "192.0.2.0", "", 1234, false)
+
+	dbApp := dbmodel.App{
+		ID:           1,
+		Active:       true,
+		AccessPoints: accessPoints,
+		Machine: &dbmodel.Machine{
+			Address:   "192.0.2.0",
+			AgentPort: 1111,
+		},
+		Daemons: []*dbmodel.Daemon{
+			dbmodel.NewKeaDaemon(dbmodel.DaemonNameCA, true),
+			dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true),
+			dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv6, true),
+		},
+	}
+	for i, daemon := range dbApp.Daemons {
+		daemon.ID = int64(i + 1)
+	}
+
+	// Act
+	state := GetAppState(context.Background(), fa, &dbApp, fec, nil, nil)
+
+	// Assert
+	require.NotNil(t, state)
+	require.False(t, dbApp.Active)
+	require.True(t, dbApp.GetDaemonByName(dhcp4).Active)
+	require.False(t, dbApp.GetDaemonByName(dhcp6).Active)
+
+	var unreachableEvents []*dbmodel.Event
+	for _, event := range state.Events {
+		if strings.Contains(event.Text, "is unreachable") {
+			unreachableEvents = append(unreachableEvents, event)
+		}
+	}
+	require.Len(t, unreachableEvents, 1)
+	require.EqualValues(t, 3, unreachableEvents[0].Relations.DaemonID)
+	require.Contains(t, unreachableEvents[0].Details, "no version-get response from kea daemon dhcp6")
+}
+
 // Check GetAppState when app already exists.
 func TestGetAppStateForExistingApp(t *testing.T) {
 	ctx := context.Background()
 
 	// check getting config of 1 daemon
 	keaMock := func(callNo int, cmdResponses []interface{}) {
-		if callNo%2 == 0 {
+		// The status-get command is the last one sent to the Control Agent.
+		if _, ok := cmdResponses[len(cmdResponses)-1].(*[]StatusGetResponse); ok {
 			mockGetConfigFromCAResponse(1, cmdResponses)
-		} else if callNo%2 == 1 {
+		} else {
 			mockGetConfigFromOtherDaemonsResponse(1, cmdResponses)
 		}
 	}
@@ -312,7 +1034,7 @@ func TestGetAppStateForExistingApp(t *testing.T) {
 	dhcp4Hash := dbApp.Daemons[0].KeaDaemon.ConfigHash
 	caHash := dbApp.Daemons[1].KeaDaemon.ConfigHash
 
-	state := GetAppState(ctx, fa, &dbApp, fec)
+	state := GetAppState(ctx, fa, &dbApp, fec, nil, nil)
 	require.NotNil(t, state)
 	require.Empty(t, state.SameConfigDaemons)
 
@@ -364,11 +1086,17 @@ func TestGetAppStateForExistingApp(t *testing.T) {
 	dhcp4Config := dhcp4Daemon.KeaDaemon.Config
 	caConfig := caDaemon.KeaDaemon.Config
 
-	state = GetAppState(ctx, fa, &dbApp, fec)
+	recordedCommands := len(fa.RecordedCommands)
+	state = GetAppState(ctx, fa, &dbApp, fec, nil, nil)
 	require.NotNil(t, state)
 	require.Contains(t, state.SameConfigDaemons, "ca")
 	require.Contains(t, state.SameConfigDaemons, "dhcp4")
 
+	// The configuration hash of the DHCPv4 daemon hasn't changed, so its
+	// configuration should not be fetched.
+	require.Len(t, fa.RecordedCommands, recordedCommands+6)
+	require.Equal(t, "config-hash-get", fa.RecordedCommands[len(fa.RecordedCommands)-1].GetCommand())
+
 	require.NotNil(t, dhcp4Daemon.KeaDaemon.Config)
 	require.Same(t, dhcp4Config, dhcp4Daemon.KeaDaemon.Config)
 	require.NotNil(t, caDaemon.KeaDaemon.Config)
@@ -401,6 +1129,449 @@ func TestGetDaemonHooksFrom1Daemon(t *testing.T) {
 	require.Equal(t, "hook_def.so", hooks[1])
 }
 
+// Test that GetDaemonHooksWithParams returns the hook libraries along with
+// their parameters for each daemon of the app.
+func TestGetDaemonHooksWithParams(t *testing.T) {
+	// Arrange
+	dbApp := &dbmodel.App{
+		Type: dbmodel.AppTypeKea,
+		Daemons: []*dbmodel.Daemon{
+			{
+				Name: "dhcp4",
+				KeaDaemon: &dbmodel.KeaDaemon{
+					Config: dbmodel.NewKeaConfig(&map[string]interface{}{
+						"Dhcp4": map[string]interface{}{
+							"hooks-libraries": []interface{}{
+								map[string]interface{}{
+									"library": "hook_abc.so",
+									"parameters": map[string]interface{}{
+										"foo": "bar",
+									},
+								},
+								map[string]interface{}{
+									"library": "hook_def.so",
+								},
+							},
+						},
+					}),
+				},
+			},
+			{
+				Name: "dhcp6",
+				KeaDaemon: &dbmodel.KeaDaemon{
+					Config: dbmodel.NewKeaConfig(&map[string]interface{}{
+						"Dhcp6": map[string]interface{}{},
+					}),
+				},
+			},
+			{
+				Name:      "d2",
+				KeaDaemon: &dbmodel.KeaDaemon{},
+			},
+		},
+	}
+
+	// Act
+	hooks := GetDaemonHooksWithParams(dbApp)
+
+	// Assert
+	require.Len(t, hooks, 2)
+	require.Contains(t, hooks, "dhcp4")
+	require.Contains(t, hooks, "dhcp6")
+	require.NotContains(t, hooks, "d2")
+
+	require.Len(t, hooks["dhcp4"], 2)
+	require.Equal(t, "hook_abc.so", hooks["dhcp4"][0].Library)
+	require.JSONEq(t, `{"foo": "bar"}`, string(hooks["dhcp4"][0].Parameters))
+	require.Equal(t, "hook_def.so", hooks["dhcp4"][1].Library)
+	require.Empty(t, hooks["dhcp4"][1].Parameters)
+
+	require.Empty(t, hooks["dhcp6"])
+}
+
+// Test that the app with the Control Agent having no control sockets is
+// detected as the app without daemons.
+func TestGetAppStateControlAgentOnly(t *testing.T) {
+	ctx := context.Background()
+
+	keaMock := func(callNo int, cmdResponses []interface{}) {
+		if callNo%2 != 0 {
+			return
+		}
+		list1 := cmdResponses[0].(*[]VersionGetResponse)
+		*list1 = []VersionGetResponse{
+			{
+				ResponseHeader: keactrl.ResponseHeader{
+					Result: 0,
+					Daemon: "ca",
+					Text:   "2.2.0",
+				},
+			},
+		}
+		list2 := cmdResponses[1].(*[]keactrl.HashedResponse)
+		*list2 = []keactrl.HashedResponse{
+			{
+				ResponseHeader: keactrl.ResponseHeader{
+					Result: 0,
+					Daemon: "ca",
+				},
+				Arguments: &map[string]interface{}{
+					"Control-agent": map[string]interface{}{},
+				},
+				ArgumentsHash: "hash",
+			},
+		}
+	}
+	fa := agentcommtest.NewFakeAgents(keaMock, nil)
+	fec := &storktest.FakeEventCenter{}
+
+	var accessPoints []*dbmodel.AccessPoint
+	accessPoints = dbmodel.AppendAccessPoint(accessPoints, dbmodel.AccessPointControl, "192.0.2.0", "", 1234, true)
+
+	dbApp := dbmodel.App{
+		ID:           1,
+		Active:       true,
+		AccessPoints: accessPoints,
+		Machine: &dbmodel.Machine{
+			Address:   "192.0.2.0",
+			AgentPort: 1111,
+		},
+		Daemons: []*dbmodel.Daemon{
+			dbmodel.NewKeaDaemon(dbmodel.DaemonNameCA, true),
+			dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true),
+		},
+	}
+
+	state := GetAppState(ctx, fa, &dbApp, fec, nil, nil)
+	require.NotNil(t, state)
+	require.True(t, dbApp.Meta.NoDaemons)
+	require.Len(t, dbApp.Daemons, 1)
+	require.Equal(t, dbmodel.DaemonNameCA, dbApp.Daemons[0].Name)
+
+	// The event should be raised because the app had the DHCPv4 daemon before.
+	found := false
+	for _, ev := range state.Events {
+		if strings.Contains(ev.Text, "has no DHCP or D2 daemons") {
+			require.Equal(t, dbmodel.EvInfo, ev.Level)
+			found = true
+		}
+	}
+	require.True(t, found)
+
+	// Get the state again. The app is still without daemons but the event
+	// should not be raised again.
+	state = GetAppState(ctx, fa, &dbApp, fec, nil, nil)
+	require.NotNil(t, state)
+	require.True(t, dbApp.Meta.NoDaemons)
+	for _, ev := range state.Events {
+		require.NotContains(t, ev.Text, "has no DHCP or D2 daemons")
+	}
+}
+
+// Test that the reachability of the Control Agents running on the same
+// machine is tracked independently.
+func TestGetAppStateMultipleControlAgentsReachability(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+
+	// Only the Control Agent listening on port 1234 responds.
+	var fa *agentcommtest.FakeAgents
+	keaMock := func(callNo int, cmdResponses []interface{}) {
+		if !strings.Contains(fa.RecordedURLs[len(fa.RecordedURLs)-1], ":1234/") {
+			return
+		}
+		versionGetResp, ok := cmdResponses[0].(*[]VersionGetResponse)
+		if !ok {
+			return
+		}
+		configGetResp, ok := cmdResponses[1].(*[]keactrl.HashedResponse)
+		if !ok {
+			return
+		}
+		*versionGetResp = []VersionGetResponse{
+			{
+				ResponseHeader: keactrl.ResponseHeader{
+					Result: 0,
+					Daemon: "ca",
+					Text:   "2.2.0",
+				},
+			},
+		}
+		*configGetResp = []keactrl.HashedResponse{
+			{
+				ResponseHeader: keactrl.ResponseHeader{
+					Result: 0,
+					Daemon: "ca",
+				},
+				Arguments: &map[string]interface{}{
+					"Control-agent": map[string]interface{}{},
+				},
+				ArgumentsHash: "hash",
+			},
+		}
+	}
+	fa = agentcommtest.NewFakeAgents(keaMock, nil)
+	fec := &storktest.FakeEventCenter{}
+
+	machine := &dbmodel.Machine{
+		ID:        1,
+		Address:   "192.0.2.0",
+		AgentPort: 1111,
+	}
+	var apps []*dbmodel.App
+	for i, port := range []int64{1234, 1235} {
+		var accessPoints []*dbmodel.AccessPoint
+		accessPoints = dbmodel.AppendAccessPoint(accessPoints, dbmodel.AccessPointControl, "192.0.2.0", "", port, false)
+		apps = append(apps, &dbmodel.App{
+			ID:           int64(i + 1),
+			Active:       true,
+			MachineID:    machine.ID,
+			Machine:      machine,
+			AccessPoints: accessPoints,
+			Daemons: []*dbmodel.Daemon{
+				dbmodel.NewKeaDaemon(dbmodel.DaemonNameCA, true),
+			},
+		})
+	}
+
+	// Act
+	states := []*AppStateMeta{
+		GetAppState(ctx, fa, apps[0], fec, nil, nil),
+		GetAppState(ctx, fa, apps[1], fec, nil, nil),
+	}
+
+	// Assert
+	require.True(t, apps[0].AccessPoints[0].Reachable)
+	require.False(t, apps[1].AccessPoints[0].Reachable)
+
+	// The reachable access point was reachable before, so there is no
+	// event about it.
+	for _, ev := range states[0].Events {
+		require.NotContains(t, ev.Text, "control access point")
+	}
+	var found bool
+	for _, ev := range states[1].Events {
+		if strings.Contains(ev.Text, "control access point 192.0.2.0:1235") {
+			require.Contains(t, ev.Text, "is unreachable")
+			require.Equal(t, dbmodel.EvWarning, ev.Level)
+			require.EqualValues(t, 2, ev.Relations.AppID)
+			found = true
+		}
+	}
+	require.True(t, found)
+
+	// Act
+	// Get the state of the unreachable app again. The event should not
+	// be repeated.
+	state := GetAppState(ctx, fa, apps[1], fec, nil, nil)
+
+	// Assert
+	require.False(t, apps[1].AccessPoints[0].Reachable)
+	for _, ev := range state.Events {
+		require.NotContains(t, ev.Text, "control access point")
+	}
+}
+
+// Test that the events are raised when the control access point changes
+// its reachability.
+func TestUpdateControlAccessPointState(t *testing.T) {
+	// Arrange
+	var accessPoints []*dbmodel.AccessPoint
+	accessPoints = dbmodel.AppendAccessPoint(accessPoints, dbmodel.AccessPointControl, "192.0.2.0", "", 1234, false)
+	dbApp := &dbmodel.App{
+		ID:           1,
+		Machine:      &dbmodel.Machine{ID: 1},
+		AccessPoints: accessPoints,
+	}
+	inactiveCA := dbmodel.NewKeaDaemon(dbmodel.DaemonNameCA, false)
+	activeCA := dbmodel.NewKeaDaemon(dbmodel.DaemonNameCA, true)
+
+	// Act & Assert
+	// The Control Agent is active and the access point was reachable.
+	events := updateControlAccessPointState(dbApp, map[string]*dbmodel.Daemon{"ca": activeCA})
+	require.Empty(t, events)
+	require.True(t, dbApp.AccessPoints[0].Reachable)
+
+	// The Control Agent is missing.
+	events = updateControlAccessPointState(dbApp, map[string]*dbmodel.Daemon{})
+	require.Len(t, events, 1)
+	require.Equal(t, dbmodel.EvWarning, events[0].Level)
+	require.Contains(t, events[0].Text, "192.0.2.0:1234")
+	require.Contains(t, events[0].Text, "is unreachable")
+	require.False(t, dbApp.AccessPoints[0].Reachable)
+
+	// The Control Agent is still inactive.
+	events = updateControlAccessPointState(dbApp, map[string]*dbmodel.Daemon{"ca": inactiveCA})
+	require.Empty(t, events)
+	require.False(t, dbApp.AccessPoints[0].Reachable)
+
+	// The Control Agent is back.
+	events = updateControlAccessPointState(dbApp, map[string]*dbmodel.Daemon{"ca": activeCA})
+	require.Len(t, events, 1)
+	require.Equal(t, dbmodel.EvInfo, events[0].Level)
+	require.Contains(t, events[0].Text, "is reachable again")
+	require.True(t, dbApp.AccessPoints[0].Reachable)
+}
+
+// Test that no events are raised for a new app and for an app without
+// the control access point.
+func TestUpdateControlAccessPointStateNoEvents(t *testing.T) {
+	var accessPoints []*dbmodel.AccessPoint
+	accessPoints = dbmodel.AppendAccessPoint(accessPoints, dbmodel.AccessPointControl, "192.0.2.0", "", 1234, false)
+	dbApp := &dbmodel.App{
+		AccessPoints: accessPoints,
+	}
+
+	events := updateControlAccessPointState(dbApp, map[string]*dbmodel.Daemon{})
+	require.Empty(t, events)
+	require.False(t, dbApp.AccessPoints[0].Reachable)
+
+	dbApp = &dbmodel.App{ID: 1}
+	events = updateControlAccessPointState(dbApp, map[string]*dbmodel.Daemon{})
+	require.Empty(t, events)
+}
+
+// Test that the event about the app without daemons is raised when the
+// app loses all daemons except the Control Agent.
+func TestFindChangesAndRaiseEventsControlAgentOnly(t *testing.T) {
+	// Arrange
+	dbApp := &dbmodel.App{
+		ID: 1,
+		Machine: &dbmodel.Machine{
+			Address: "192.0.2.0",
+		},
+		Daemons: []*dbmodel.Daemon{
+			dbmodel.NewKeaDaemon(dbmodel.DaemonNameCA, true),
+			dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv6, true),
+		},
+	}
+	daemonsMap := map[string]*dbmodel.Daemon{
+		dbmodel.DaemonNameCA: dbmodel.NewKeaDaemon(dbmodel.DaemonNameCA, true),
+	}
+
+	// Act
+	active, overrideDaemons, newDaemons, events, _ := findChangesAndRaiseEvents(dbApp, daemonsMap, map[string]string{}, nil)
+
+	// Assert
+	require.True(t, active)
+	require.True(t, overrideDaemons)
+	require.Len(t, newDaemons, 1)
+	require.True(t, hasOnlyControlAgent(newDaemons))
+	require.NotEmpty(t, events)
+	lastEvent := events[len(events)-1]
+	require.Equal(t, dbmodel.EvInfo, lastEvent.Level)
+	require.Contains(t, lastEvent.Text, "has no DHCP or D2 daemons")
+}
+
+// Test that the event about the app without daemons is not raised when
+// the app had no other daemons than the Control Agent before.
+func TestFindChangesAndRaiseEventsControlAgentOnlyNoChange(t *testing.T) {
+	// Arrange
+	dbApp := &dbmodel.App{
+		ID: 1,
+		Machine: &dbmodel.Machine{
+			Address: "192.0.2.0",
+		},
+		Daemons: []*dbmodel.Daemon{
+			dbmodel.NewKeaDaemon(dbmodel.DaemonNameCA, true),
+		},
+	}
+	daemonsMap := map[string]*dbmodel.Daemon{
+		dbmodel.DaemonNameCA: dbmodel.NewKeaDaemon(dbmodel.DaemonNameCA, true),
+	}
+
+	// Act
+	_, _, newDaemons, events, _ := findChangesAndRaiseEvents(dbApp, daemonsMap, map[string]string{}, nil)
+
+	// Assert
+	require.True(t, hasOnlyControlAgent(newDaemons))
+	for _, ev := range events {
+		require.NotContains(t, ev.Text, "has no DHCP or D2 daemons")
+	}
+}
+
+// Test that the app with the DHCP daemon is not considered as having
+// only the Control Agent.
+func TestHasOnlyControlAgent(t *testing.T) {
+	require.True(t, hasOnlyControlAgent([]*dbmodel.Daemon{
+		dbmodel.NewKeaDaemon(dbmodel.DaemonNameCA, true),
+	}))
+	require.False(t, hasOnlyControlAgent([]*dbmodel.Daemon{
+		dbmodel.NewKeaDaemon(dbmodel.DaemonNameCA, true),
+		dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true),
+	}))
+	require.False(t, hasOnlyControlAgent([]*dbmodel.Daemon{
+		dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true),
+	}))
+	require.False(t, hasOnlyControlAgent(nil))
+}
+
+// Test that the DHCP daemons are grouped by the presence of the specified
+// hook library in their configurations.
+func TestGetDHCPDaemonsByHook(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	// The first app has the DHCPv4 server loading the stat_cmds hook
+	// and the DHCPv6 server without configuration.
+	app1 := createAppWithSubnets(t, db, 0, `{
+		"Dhcp4": {
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/hooks/libdhcp_stat_cmds.so"
+				}
+			]
+		}
+	}`, "")
+
+	// The second app has the DHCPv4 server loading the stat_cmds and HA
+	// hooks and the DHCPv6 server loading the stat_cmds hook.
+	app2 := createAppWithSubnets(t, db, 1, `{
+		"Dhcp4": {
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/hooks/libdhcp_stat_cmds.so"
+				},
+				{
+					"library": "/usr/lib/kea/hooks/libdhcp_ha.so"
+				}
+			]
+		}
+	}`, `{
+		"Dhcp6": {
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/hooks/libdhcp_stat_cmds.so"
+				}
+			]
+		}
+	}`)
+
+	// Act
+	statCmdsDaemons, noStatCmdsDaemons, statCmdsErr := GetDHCPDaemonsByHook(db, "libdhcp_stat_cmds")
+	haDaemons, noHADaemons, haErr := GetDHCPDaemonsByHook(db, "libdhcp_ha")
+	unknownDaemons, noUnknownDaemons, unknownErr := GetDHCPDaemonsByHook(db, "libdhcp_unknown")
+
+	// Assert
+	require.NoError(t, statCmdsErr)
+	require.Len(t, statCmdsDaemons, 3)
+	require.EqualValues(t, app1.Daemons[0].ID, statCmdsDaemons[0].ID)
+	require.EqualValues(t, app2.Daemons[0].ID, statCmdsDaemons[1].ID)
+	require.EqualValues(t, app2.Daemons[1].ID, statCmdsDaemons[2].ID)
+	require.Len(t, noStatCmdsDaemons, 1)
+	require.EqualValues(t, app1.Daemons[1].ID, noStatCmdsDaemons[0].ID)
+
+	require.NoError(t, haErr)
+	require.Len(t, haDaemons, 1)
+	require.EqualValues(t, app2.Daemons[0].ID, haDaemons[0].ID)
+	require.Len(t, noHADaemons, 3)
+
+	require.NoError(t, unknownErr)
+	require.Empty(t, unknownDaemons)
+	require.Len(t, noUnknownDaemons, 4)
+}
+
 // Tests that Kea can be added and then updated in the database.
 func TestCommitAppIntoDB(t *testing.T) {
 	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
@@ -447,3 +1618,241 @@ func TestCommitAppIntoDB(t *testing.T) {
 	require.EqualValues(t, 2345, returned.AccessPoints[0].Port)
 	require.True(t, returned.AccessPoints[0].UseSecureProtocol)
 }
+
+// Returns the DHCPv4 configuration with the specified number of the
+// subnets outside of the shared network. Each subnet has one reservation.
+// The configuration also includes one shared network with one subnet and
+// one global reservation.
+func getConfigSizeTestConfig(subnetCount int) string {
+	subnets := make([]string, subnetCount)
+	for i := range subnets {
+		subnets[i] = fmt.Sprintf(`{
+            "id": %d,
+            "subnet": "192.0.%d.0/24",
+            "reservations": [ { "hw-address": "01:02:03:04:05:%02x", "ip-address": "192.0.%d.10" } ]
+        }`, i+1, i+2, i+1, i+2)
+	}
+	return fmt.Sprintf(`{
+        "Dhcp4": {
+            "reservations": [ { "hw-address": "0a:0b:0c:0d:0e:0f", "hostname": "global" } ],
+            "subnet4": [ %s ],
+            "shared-networks": [
+                {
+                    "name": "foo",
+                    "subnet4": [ { "id": 100, "subnet": "10.0.0.0/8" } ]
+                }
+            ]
+        }
+    }`, strings.Join(subnets, ","))
+}
+
+// Test that the configuration size of the DHCP daemon is computed from the
+// detected subnets and reservations.
+func TestGetDaemonConfigSize(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(getConfigSizeTestConfig(2))
+	require.NoError(t, err)
+
+	networks := []dbmodel.SharedNetwork{
+		{Subnets: []dbmodel.Subnet{{Prefix: "10.0.0.0/8", Hosts: []dbmodel.Host{{}, {}}}}},
+	}
+	subnets := []dbmodel.Subnet{
+		{Prefix: "192.0.2.0/24", Hosts: []dbmodel.Host{{}}},
+		{Prefix: "192.0.3.0/24"},
+	}
+	globalHosts := []dbmodel.Host{{}}
+
+	// Act
+	size := getDaemonConfigSize(daemon, networks, subnets, globalHosts)
+
+	// Assert
+	require.NotNil(t, size)
+	require.EqualValues(t, 42, size.DaemonID)
+	require.NotZero(t, size.RecordedAt)
+	require.EqualValues(t, 3, size.SubnetCount)
+	require.EqualValues(t, 4, size.ReservationCount)
+}
+
+// Test that the configuration size is not returned for the daemons other
+// than the DHCP servers.
+func TestGetDaemonConfigSizeNonDHCPDaemon(t *testing.T) {
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameCA, true)
+	require.Nil(t, getDaemonConfigSize(daemon, nil, nil, nil))
+
+	daemon = dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	require.Nil(t, getDaemonConfigSize(daemon, nil, nil, nil))
+}
+
+// Test that the configuration sizes of the DHCP daemon are recorded when
+// the app is committed with the changed configuration.
+func TestCommitAppIntoDBDaemonConfigSize(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	machine := &dbmodel.Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	err := dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	err = daemon.SetConfigFromJSON(getConfigSizeTestConfig(1))
+	require.NoError(t, err)
+
+	var accessPoints []*dbmodel.AccessPoint
+	accessPoints = dbmodel.AppendAccessPoint(accessPoints, dbmodel.AccessPointControl, "", "", 1234, false)
+	app := &dbmodel.App{
+		MachineID:    machine.ID,
+		Machine:      machine,
+		Type:         dbmodel.AppTypeKea,
+		Active:       true,
+		AccessPoints: accessPoints,
+		Daemons:      []*dbmodel.Daemon{daemon},
+	}
+
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	fec := &storktest.FakeEventCenter{}
+
+	// Act
+	err = CommitAppIntoDB(db, app, fec, nil, lookup)
+	require.NoError(t, err)
+
+	err = daemon.SetConfigFromJSON(getConfigSizeTestConfig(3))
+	require.NoError(t, err)
+	err = CommitAppIntoDB(db, app, fec, nil, lookup)
+	require.NoError(t, err)
+
+	// The configuration hasn't changed.
+	state := &AppStateMeta{SameConfigDaemons: map[string]bool{dbmodel.DaemonNameDHCPv4: true}}
+	err = CommitAppIntoDB(db, app, fec, state, lookup)
+	require.NoError(t, err)
+
+	// Assert
+	sizes, err := dbmodel.GetDaemonConfigSizes(db, daemon.ID)
+	require.NoError(t, err)
+	require.Len(t, sizes, 2)
+	require.EqualValues(t, 2, sizes[0].SubnetCount)
+	require.EqualValues(t, 2, sizes[0].ReservationCount)
+	require.EqualValues(t, 4, sizes[1].SubnetCount)
+	require.EqualValues(t, 4, sizes[1].ReservationCount)
+	require.False(t, sizes[1].RecordedAt.Before(sizes[0].RecordedAt))
+}
+
+// Creates the specified number of subnets for the event tests.
+func createEventSubnets(count int) (subnets []*dbmodel.Subnet) {
+	for i := 0; i < count; i++ {
+		subnets = append(subnets, &dbmodel.Subnet{
+			ID:     int64(i + 1),
+			Prefix: fmt.Sprintf("192.0.%d.0/24", i),
+		})
+	}
+	return
+}
+
+// Test that an event per added subnet and the summary are added when the
+// number of added subnets is equal to the limit.
+func TestAddOnCommitSubnetEventsAtLimit(t *testing.T) {
+	app := &dbmodel.App{ID: 1, Type: dbmodel.AppTypeKea}
+	daemon := &dbmodel.Daemon{ID: 2, Name: dbmodel.DaemonNameDHCPv4, App: app}
+	fec := &storktest.FakeEventCenter{}
+
+	addOnCommitSubnetEvents(app, daemon, createEventSubnets(3), 3, fec)
+
+	require.Len(t, fec.Events, 4)
+	for i := 0; i < 3; i++ {
+		require.Contains(t, fec.Events[i].Text, "added <subnet")
+		require.EqualValues(t, i+1, fec.Events[i].Relations.SubnetID)
+	}
+	require.Contains(t, fec.Events[3].Text, "added 3 subnets to")
+}
+
+// Test that only the summary is added when the number of added subnets
+// exceeds the limit.
+func TestAddOnCommitSubnetEventsAboveLimit(t *testing.T) {
+	app := &dbmodel.App{ID: 1, Type: dbmodel.AppTypeKea}
+	daemon := &dbmodel.Daemon{ID: 2, Name: dbmodel.DaemonNameDHCPv4, App: app}
+	fec := &storktest.FakeEventCenter{}
+
+	addOnCommitSubnetEvents(app, daemon, createEventSubnets(4), 3, fec)
+
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "added 4 subnets to")
+}
+
+// Test that no events are added when no subnets have been added.
+func TestAddOnCommitSubnetEventsNoSubnets(t *testing.T) {
+	app := &dbmodel.App{ID: 1, Type: dbmodel.AppTypeKea}
+	daemon := &dbmodel.Daemon{ID: 2, Name: dbmodel.DaemonNameDHCPv4, App: app}
+	fec := &storktest.FakeEventCenter{}
+
+	addOnCommitSubnetEvents(app, daemon, nil, 3, fec)
+	require.Empty(t, fec.Events)
+}
+
+// Test that the events about the removed subnets are capped.
+func TestAddOnCommitDeletedSubnetEvents(t *testing.T) {
+	app := &dbmodel.App{ID: 1, Type: dbmodel.AppTypeKea}
+	var deletedSubnets []dbmodel.Subnet
+	for _, subnet := range createEventSubnets(3) {
+		deletedSubnets = append(deletedSubnets, *subnet)
+	}
+
+	fec := &storktest.FakeEventCenter{}
+	addOnCommitDeletedSubnetEvents(app, deletedSubnets, 3, fec)
+	require.Len(t, fec.Events, 4)
+	require.Contains(t, fec.Events[0].Text, "removed subnet 192.0.0.0/24 after updating <app")
+	// The deleted subnets must not be referenced.
+	require.Zero(t, fec.Events[0].Relations.SubnetID)
+	require.EqualValues(t, 1, fec.Events[0].Relations.AppID)
+	require.Contains(t, fec.Events[3].Text, "removed 3 subnets after updating")
+
+	fec = &storktest.FakeEventCenter{}
+	addOnCommitDeletedSubnetEvents(app, deletedSubnets, 2, fec)
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "removed 3 subnets after updating")
+}
+
+// Test that the configured severities override the default levels of the
+// events raised when the daemons become unreachable.
+func TestFindChangesAndRaiseEventsSeverities(t *testing.T) {
+	// Arrange
+	dbApp := &dbmodel.App{
+		ID: 1,
+		Machine: &dbmodel.Machine{
+			Address: "192.0.2.0",
+		},
+		Daemons: []*dbmodel.Daemon{
+			dbmodel.NewKeaDaemon(dbmodel.DaemonNameCA, true),
+			dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true),
+			dbmodel.NewKeaDaemon(dbmodel.DaemonNameD2, true),
+		},
+	}
+	daemonsMap := map[string]*dbmodel.Daemon{
+		dbmodel.DaemonNameCA:     dbmodel.NewKeaDaemon(dbmodel.DaemonNameCA, true),
+		dbmodel.DaemonNameDHCPv4: dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, false),
+		dbmodel.DaemonNameD2:     dbmodel.NewKeaDaemon(dbmodel.DaemonNameD2, false),
+	}
+	severities := DaemonEventSeverities{"d2:unreachable": dbmodel.EvInfo}
+
+	// Act
+	_, _, _, events, _ := findChangesAndRaiseEvents(dbApp, daemonsMap, map[string]string{}, severities)
+
+	// Assert
+	levels := make(map[string]dbmodel.EventLevel)
+	for _, ev := range events {
+		if strings.Contains(ev.Text, "is unreachable") {
+			for _, name := range []string{dbmodel.DaemonNameDHCPv4, dbmodel.DaemonNameD2} {
+				if strings.Contains(ev.Text, name) {
+					levels[name] = ev.Level
+				}
+			}
+		}
+	}
+	require.Len(t, levels, 2)
+	require.Equal(t, dbmodel.EvError, levels[dbmodel.DaemonNameDHCPv4])
+	require.Equal(t, dbmodel.EvInfo, levels[dbmodel.DaemonNameD2])
+}
@@ -0,0 +1,265 @@
+package kea
+
+import (
+	"path"
+	"reflect"
+	"sort"
+
+	keaconfig "isc.org/stork/appcfg/kea"
+	dbmodel "isc.org/stork/server/database/model"
+	storkutil "isc.org/stork/util"
+)
+
+// Describes differences between the configurations of the daemons having
+// the same name in two compared Kea apps. The "first" and "second" names
+// refer to the order of the apps passed to the comparison function. In the
+// comparison with the baseline, the first is the baseline configuration
+// and the second is the current configuration.
+type DaemonConfigDiff struct {
+	DaemonName string
+	// Indicates that the daemon exists only in one of the apps or it
+	// lacks the configuration.
+	MissingInFirst  bool
+	MissingInSecond bool
+	// Prefixes of the subnets configured only in one of the daemons.
+	SubnetsOnlyInFirst  []string
+	SubnetsOnlyInSecond []string
+	// Names of the hook libraries loaded only by one of the daemons.
+	HooksOnlyInFirst  []string
+	HooksOnlyInSecond []string
+	// Indicates that the HA modes or peers differ.
+	HADiffers bool
+	// Indicates that the global DHCP options differ.
+	OptionsDiffer bool
+}
+
+// Checks if the compared daemons' configurations differ.
+func (diff DaemonConfigDiff) IsEmpty() bool {
+	return !diff.MissingInFirst && !diff.MissingInSecond &&
+		len(diff.SubnetsOnlyInFirst) == 0 && len(diff.SubnetsOnlyInSecond) == 0 &&
+		len(diff.HooksOnlyInFirst) == 0 && len(diff.HooksOnlyInSecond) == 0 &&
+		!diff.HADiffers && !diff.OptionsDiffer
+}
+
+// Describes differences between the configurations of two Kea apps,
+// typically running on different machines. It only holds the entries
+// for the daemons having different configurations.
+type AppConfigDiff struct {
+	Daemons []DaemonConfigDiff
+}
+
+// Checks if the compared apps' configurations are the same.
+func (diff AppConfigDiff) IsEmpty() bool {
+	return len(diff.Daemons) == 0
+}
+
+// Returns the symmetric difference between two string sets as two sorted
+// slices: values found only in the first set and only in the second set.
+func diffStringSets(first, second map[string]bool) (onlyInFirst, onlyInSecond []string) {
+	for value := range first {
+		if !second[value] {
+			onlyInFirst = append(onlyInFirst, value)
+		}
+	}
+	for value := range second {
+		if !first[value] {
+			onlyInSecond = append(onlyInSecond, value)
+		}
+	}
+	sort.Strings(onlyInFirst)
+	sort.Strings(onlyInSecond)
+	return
+}
+
+// Returns a set of the canonical subnet prefixes configured in a daemon.
+func getSubnetPrefixSet(config *keaconfig.Config) map[string]bool {
+	prefixes := make(map[string]bool)
+	for _, subnet := range config.GetSubnets() {
+		prefix, err := subnet.GetCanonicalPrefix()
+		if err != nil {
+			prefix = subnet.GetPrefix()
+		}
+		prefixes[prefix] = true
+	}
+	return prefixes
+}
+
+// Returns a set of the hook library names loaded by a daemon. The paths
+// to the libraries are stripped because they often vary between the
+// machines.
+func getHookNameSet(config *keaconfig.Config) map[string]bool {
+	hooks := make(map[string]bool)
+	for _, library := range config.GetHookLibraries() {
+		hooks[path.Base(library.Library)] = true
+	}
+	return hooks
+}
+
+// Checks if the HA configurations of two daemons differ. The servers'
+// own names are excluded from the comparison because they are expected
+// to be different for the servers in the same HA relationship.
+func isHADifferent(first, second *keaconfig.Config) bool {
+	_, firstParams, firstOk := first.GetHookLibraries().GetHAHookLibrary()
+	_, secondParams, secondOk := second.GetHookLibraries().GetHAHookLibrary()
+	if firstOk != secondOk {
+		return true
+	}
+	if !firstOk {
+		return false
+	}
+	if len(firstParams.HA) != len(secondParams.HA) {
+		return true
+	}
+	for i := range firstParams.HA {
+		firstHA, secondHA := firstParams.HA[i], secondParams.HA[i]
+		firstHA.ThisServerName, secondHA.ThisServerName = nil, nil
+		if !reflect.DeepEqual(firstHA, secondHA) {
+			return true
+		}
+	}
+	return false
+}
+
+// Compares the configurations of two daemons having the same name.
+func compareDaemonConfigs(name string, first, second *dbmodel.Daemon) (diff DaemonConfigDiff) {
+	diff.DaemonName = name
+	firstConfig := getDaemonConfig(first)
+	secondConfig := getDaemonConfig(second)
+	diff.MissingInFirst = firstConfig == nil
+	diff.MissingInSecond = secondConfig == nil
+	if diff.MissingInFirst || diff.MissingInSecond {
+		return
+	}
+	// The configurations are the same when their hashes match. It is the
+	// same check as the one used to detect the configuration changes of
+	// a daemon.
+	if first.KeaDaemon.ConfigHash != "" && first.KeaDaemon.ConfigHash == second.KeaDaemon.ConfigHash {
+		return
+	}
+	diff.SubnetsOnlyInFirst, diff.SubnetsOnlyInSecond = diffStringSets(
+		getSubnetPrefixSet(firstConfig), getSubnetPrefixSet(secondConfig),
+	)
+	diff.HooksOnlyInFirst, diff.HooksOnlyInSecond = diffStringSets(
+		getHookNameSet(firstConfig), getHookNameSet(secondConfig),
+	)
+	diff.HADiffers = isHADifferent(firstConfig, secondConfig)
+	diff.OptionsDiffer = storkutil.Fnv128(firstConfig.GetDHCPOptions()) != storkutil.Fnv128(secondConfig.GetDHCPOptions())
+	return
+}
+
+// Returns the parsed configuration of a Kea daemon or nil if the daemon
+// doesn't exist or its configuration hasn't been fetched.
+func getDaemonConfig(daemon *dbmodel.Daemon) *keaconfig.Config {
+	if daemon == nil || daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil {
+		return nil
+	}
+	return daemon.KeaDaemon.Config.Config
+}
+
+// Compares the configurations of two Kea apps. It compares the daemons
+// having the same names in both apps: their subnets, loaded hook
+// libraries, HA configurations and global DHCP options. The control agent
+// is excluded from the comparison.
+func CompareAppConfigs(first, second *dbmodel.App) (diff AppConfigDiff) {
+	names := make(map[string]bool)
+	for _, app := range []*dbmodel.App{first, second} {
+		if app == nil {
+			continue
+		}
+		for _, daemon := range app.Daemons {
+			if daemon.Name != dbmodel.DaemonNameCA {
+				names[daemon.Name] = true
+			}
+		}
+	}
+	sortedNames := make([]string, 0, len(names))
+	for name := range names {
+		sortedNames = append(sortedNames, name)
+	}
+	sort.Strings(sortedNames)
+
+	for _, name := range sortedNames {
+		var firstDaemon, secondDaemon *dbmodel.Daemon
+		if first != nil {
+			firstDaemon = first.GetDaemonByName(name)
+		}
+		if second != nil {
+			secondDaemon = second.GetDaemonByName(name)
+		}
+		daemonDiff := compareDaemonConfigs(name, firstDaemon, secondDaemon)
+		if !daemonDiff.IsEmpty() {
+			diff.Daemons = append(diff.Daemons, daemonDiff)
+		}
+	}
+	return
+}
+
+// Returns the first Kea app belonging to a machine or nil.
+func getMachineKeaApp(machine *dbmodel.Machine) *dbmodel.App {
+	if machine == nil {
+		return nil
+	}
+	for _, app := range machine.Apps {
+		if app.Type == dbmodel.AppTypeKea {
+			return app
+		}
+	}
+	return nil
+}
+
+// Compares the configurations of the Kea apps running on two machines.
+// The machines must be fetched with their apps and daemons.
+func CompareMachineConfigs(first, second *dbmodel.Machine) AppConfigDiff {
+	return CompareAppConfigs(getMachineKeaApp(first), getMachineKeaApp(second))
+}
+
+// Compares the baseline configuration of a daemon, i.e., the configuration
+// fetched when the daemon was added to Stork, with its current
+// configuration. The subnets, hook libraries and HA configuration found
+// only in the baseline have been removed since then. The ones found only
+// in the current configuration have been added.
+func CompareDaemonConfigWithBaseline(daemon *dbmodel.Daemon) DaemonConfigDiff {
+	var baseline *dbmodel.Daemon
+	if daemon != nil && daemon.KeaDaemon != nil && daemon.KeaDaemon.BaselineConfig != nil {
+		baseline = &dbmodel.Daemon{
+			Name: daemon.Name,
+			KeaDaemon: &dbmodel.KeaDaemon{
+				Config:     daemon.KeaDaemon.BaselineConfig,
+				ConfigHash: daemon.KeaDaemon.BaselineConfigHash,
+			},
+		}
+	}
+	name := ""
+	if daemon != nil {
+		name = daemon.Name
+	}
+	return compareDaemonConfigs(name, baseline, daemon)
+}
+
+// Compares the baseline configurations of the app's daemons with their
+// current configurations. It only returns the entries for the daemons
+// whose configurations have changed since they were added to Stork. The
+// control agent and the daemons whose configurations have never been
+// fetched are excluded from the comparison.
+func CompareAppConfigWithBaseline(app *dbmodel.App) (diff AppConfigDiff) {
+	if app == nil {
+		return
+	}
+	for _, daemon := range app.Daemons {
+		if daemon.Name == dbmodel.DaemonNameCA {
+			continue
+		}
+		daemonDiff := CompareDaemonConfigWithBaseline(daemon)
+		// The configuration of the daemon has never been fetched.
+		if daemonDiff.MissingInFirst && daemonDiff.MissingInSecond {
+			continue
+		}
+		if !daemonDiff.IsEmpty() {
+			diff.Daemons = append(diff.Daemons, daemonDiff)
+		}
+	}
+	sort.Slice(diff.Daemons, func(i, j int) bool {
+		return diff.Daemons[i].DaemonName < diff.Daemons[j].DaemonName
+	})
+	return
+}
@@ -0,0 +1,309 @@
+package kea
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Returns a DHCPv4 server configuration with the HA hook library. The
+// arguments customize the server name, the hook libraries' location and
+// the configured subnets.
+func getComparedDHCPv4Config(serverName, hooksPath string, subnets ...string) string {
+	subnetsJSON := ""
+	for i, subnet := range subnets {
+		if i > 0 {
+			subnetsJSON += ","
+		}
+		subnetsJSON += fmt.Sprintf(`{"id": %d, "subnet": "%s"}`, i+1, subnet)
+	}
+	return fmt.Sprintf(`{
+		"Dhcp4": {
+			"option-data": [
+				{
+					"name": "domain-name-servers",
+					"data": "192.0.2.1"
+				}
+			],
+			"hooks-libraries": [
+				{
+					"library": "%[2]s/libdhcp_lease_cmds.so"
+				},
+				{
+					"library": "%[2]s/libdhcp_ha.so",
+					"parameters": {
+						"high-availability": [
+							{
+								"this-server-name": "%[1]s",
+								"mode": "hot-standby",
+								"peers": [
+									{
+										"name": "server1",
+										"url": "http://192.0.2.1:8001/",
+										"role": "primary"
+									},
+									{
+										"name": "server2",
+										"url": "http://192.0.2.2:8001/",
+										"role": "standby"
+									}
+								]
+							}
+						]
+					}
+				}
+			],
+			"subnet4": [ %[3]s ]
+		}
+	}`, serverName, hooksPath, subnetsJSON)
+}
+
+// Creates a machine with a Kea app including the control agent and the
+// DHCPv4 daemon with the specified configuration.
+func createComparedMachine(t *testing.T, dhcp4Config string) *dbmodel.Machine {
+	config, err := dbmodel.NewKeaConfigFromJSON(dhcp4Config)
+	require.NoError(t, err)
+	dhcp4 := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	dhcp4.KeaDaemon.Config = config
+
+	return &dbmodel.Machine{
+		Apps: []*dbmodel.App{
+			{
+				Type: dbmodel.AppTypeKea,
+				Daemons: []*dbmodel.Daemon{
+					dbmodel.NewKeaDaemon(dbmodel.DaemonNameCA, true),
+					dhcp4,
+				},
+			},
+		},
+	}
+}
+
+// Test that no differences are reported for the machines with similar
+// configurations.
+func TestCompareMachineConfigsSimilar(t *testing.T) {
+	first := createComparedMachine(t, getComparedDHCPv4Config("server1", "/usr/lib/kea/hooks", "192.0.2.0/24", "192.0.3.0/24"))
+	// The server name and the hooks location differ but they are not
+	// considered a difference. The subnets are specified in a different
+	// order and in a non-canonical form.
+	second := createComparedMachine(t, getComparedDHCPv4Config("server2", "/opt/kea/lib/hooks", "192.0.3.0/24", "192.0.2.1/24"))
+
+	diff := CompareMachineConfigs(first, second)
+	require.True(t, diff.IsEmpty())
+	require.Empty(t, diff.Daemons)
+}
+
+// Test that the configurations having equal hashes are considered the same.
+func TestCompareMachineConfigsSameHash(t *testing.T) {
+	first := createComparedMachine(t, getComparedDHCPv4Config("server1", "/usr/lib", "192.0.2.0/24"))
+	second := createComparedMachine(t, getComparedDHCPv4Config("server1", "/usr/lib", "192.0.2.0/24"))
+	first.Apps[0].Daemons[1].KeaDaemon.ConfigHash = "1234"
+	second.Apps[0].Daemons[1].KeaDaemon.ConfigHash = "1234"
+
+	diff := CompareMachineConfigs(first, second)
+	require.True(t, diff.IsEmpty())
+}
+
+// Test that the differences between the machines' configurations are
+// reported.
+func TestCompareMachineConfigsDivergent(t *testing.T) {
+	first := createComparedMachine(t, getComparedDHCPv4Config("server1", "/usr/lib", "192.0.2.0/24", "192.0.3.0/24"))
+	secondConfig := `{
+		"Dhcp4": {
+			"option-data": [
+				{
+					"name": "domain-name-servers",
+					"data": "192.0.2.2"
+				}
+			],
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/libdhcp_stat_cmds.so"
+				}
+			],
+			"subnet4": [
+				{
+					"id": 1,
+					"subnet": "192.0.2.0/24"
+				},
+				{
+					"id": 2,
+					"subnet": "192.0.4.0/24"
+				}
+			]
+		}
+	}`
+	second := createComparedMachine(t, secondConfig)
+	// The DHCPv6 daemon exists only on the second machine.
+	second.Apps[0].Daemons = append(second.Apps[0].Daemons, dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv6, true))
+
+	diff := CompareMachineConfigs(first, second)
+	require.False(t, diff.IsEmpty())
+	require.Len(t, diff.Daemons, 2)
+
+	dhcp4Diff := diff.Daemons[0]
+	require.Equal(t, dbmodel.DaemonNameDHCPv4, dhcp4Diff.DaemonName)
+	require.False(t, dhcp4Diff.MissingInFirst)
+	require.False(t, dhcp4Diff.MissingInSecond)
+	require.Equal(t, []string{"192.0.3.0/24"}, dhcp4Diff.SubnetsOnlyInFirst)
+	require.Equal(t, []string{"192.0.4.0/24"}, dhcp4Diff.SubnetsOnlyInSecond)
+	require.Equal(t, []string{"libdhcp_ha.so", "libdhcp_lease_cmds.so"}, dhcp4Diff.HooksOnlyInFirst)
+	require.Equal(t, []string{"libdhcp_stat_cmds.so"}, dhcp4Diff.HooksOnlyInSecond)
+	require.True(t, dhcp4Diff.HADiffers)
+	require.True(t, dhcp4Diff.OptionsDiffer)
+
+	dhcp6Diff := diff.Daemons[1]
+	require.Equal(t, dbmodel.DaemonNameDHCPv6, dhcp6Diff.DaemonName)
+	require.True(t, dhcp6Diff.MissingInFirst)
+	// The daemon exists but its configuration hasn't been fetched.
+	require.True(t, dhcp6Diff.MissingInSecond)
+}
+
+// Test that the HA configuration difference is detected when the peers
+// differ.
+func TestCompareAppConfigsHADiffers(t *testing.T) {
+	first := createComparedMachine(t, getComparedDHCPv4Config("server1", "/usr/lib", "192.0.2.0/24"))
+	secondConfig := getComparedDHCPv4Config("server1", "/usr/lib", "192.0.2.0/24")
+	second := createComparedMachine(t, secondConfig)
+	_, params, ok := second.Apps[0].Daemons[1].KeaDaemon.Config.GetHookLibraries().GetHAHookLibrary()
+	require.True(t, ok)
+	require.Equal(t, "hot-standby", *params.GetFirst().Mode)
+
+	// Replace the mode in the second configuration.
+	config, err := dbmodel.NewKeaConfigFromJSON(strings.ReplaceAll(secondConfig, "hot-standby", "load-balancing"))
+	require.NoError(t, err)
+	second.Apps[0].Daemons[1].KeaDaemon.Config = config
+
+	diff := CompareAppConfigs(first.Apps[0], second.Apps[0])
+	require.Len(t, diff.Daemons, 1)
+	require.True(t, diff.Daemons[0].HADiffers)
+	require.Empty(t, diff.Daemons[0].SubnetsOnlyInFirst)
+	require.Empty(t, diff.Daemons[0].SubnetsOnlyInSecond)
+	require.Empty(t, diff.Daemons[0].HooksOnlyInFirst)
+	require.Empty(t, diff.Daemons[0].HooksOnlyInSecond)
+	require.False(t, diff.Daemons[0].OptionsDiffer)
+}
+
+// Test that the changes since the daemon was added are reported.
+func TestCompareDaemonConfigWithBaseline(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	err := daemon.SetConfigFromJSON(getComparedDHCPv4Config("server1", "/usr/lib", "192.0.2.0/24", "192.0.3.0/24"))
+	require.NoError(t, err)
+	err = daemon.SetConfigFromJSON(`{
+		"Dhcp4": {
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/libdhcp_lease_cmds.so"
+				},
+				{
+					"library": "/usr/lib/libdhcp_stat_cmds.so"
+				}
+			],
+			"subnet4": [
+				{
+					"id": 1,
+					"subnet": "192.0.2.0/24"
+				},
+				{
+					"id": 2,
+					"subnet": "192.0.4.0/24"
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+
+	// Act
+	diff := CompareDaemonConfigWithBaseline(daemon)
+
+	// Assert
+	require.False(t, diff.IsEmpty())
+	require.Equal(t, dbmodel.DaemonNameDHCPv4, diff.DaemonName)
+	require.False(t, diff.MissingInFirst)
+	require.False(t, diff.MissingInSecond)
+	require.Equal(t, []string{"192.0.3.0/24"}, diff.SubnetsOnlyInFirst)
+	require.Equal(t, []string{"192.0.4.0/24"}, diff.SubnetsOnlyInSecond)
+	require.Equal(t, []string{"libdhcp_ha.so"}, diff.HooksOnlyInFirst)
+	require.Equal(t, []string{"libdhcp_stat_cmds.so"}, diff.HooksOnlyInSecond)
+	require.True(t, diff.HADiffers)
+	require.True(t, diff.OptionsDiffer)
+}
+
+// Test that no changes are reported when the configuration is the same
+// as the baseline.
+func TestCompareDaemonConfigWithBaselineUnchanged(t *testing.T) {
+	// Arrange
+	config := getComparedDHCPv4Config("server1", "/usr/lib", "192.0.2.0/24")
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	err := daemon.SetConfigFromJSON(config)
+	require.NoError(t, err)
+	err = daemon.SetConfigFromJSON(config)
+	require.NoError(t, err)
+
+	// Act
+	diff := CompareDaemonConfigWithBaseline(daemon)
+
+	// Assert
+	require.True(t, diff.IsEmpty())
+}
+
+// Test that the daemon lacking the baseline is reported.
+func TestCompareDaemonConfigWithBaselineMissing(t *testing.T) {
+	// Arrange
+	machine := createComparedMachine(t, getComparedDHCPv4Config("server1", "/usr/lib", "192.0.2.0/24"))
+	daemon := machine.Apps[0].Daemons[1]
+
+	// Act
+	diff := CompareDaemonConfigWithBaseline(daemon)
+
+	// Assert
+	require.True(t, diff.MissingInFirst)
+	require.False(t, diff.MissingInSecond)
+}
+
+// Test that the changes since the app's daemons were added are reported
+// only for the daemons whose configurations have changed.
+func TestCompareAppConfigWithBaseline(t *testing.T) {
+	// Arrange
+	dhcp4 := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	err := dhcp4.SetConfigFromJSON(getComparedDHCPv4Config("server1", "/usr/lib", "192.0.2.0/24"))
+	require.NoError(t, err)
+	err = dhcp4.SetConfigFromJSON(getComparedDHCPv4Config("server1", "/usr/lib", "192.0.2.0/24", "192.0.3.0/24"))
+	require.NoError(t, err)
+
+	dhcp6 := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv6, true)
+	err = dhcp6.SetConfigFromJSON(`{ "Dhcp6": { } }`)
+	require.NoError(t, err)
+	err = dhcp6.SetConfigFromJSON(`{ "Dhcp6": { } }`)
+	require.NoError(t, err)
+
+	ca := dbmodel.NewKeaDaemon(dbmodel.DaemonNameCA, true)
+	err = ca.SetConfigFromJSON(`{ "Control-agent": { } }`)
+	require.NoError(t, err)
+	err = ca.SetConfigFromJSON(`{ "Control-agent": { "http-port": 8001 } }`)
+	require.NoError(t, err)
+
+	app := &dbmodel.App{
+		Type: dbmodel.AppTypeKea,
+		Daemons: []*dbmodel.Daemon{
+			ca,
+			dhcp6,
+			dhcp4,
+			// The configuration of this daemon has never been fetched.
+			dbmodel.NewKeaDaemon(dbmodel.DaemonNameD2, true),
+		},
+	}
+
+	// Act
+	diff := CompareAppConfigWithBaseline(app)
+
+	// Assert
+	require.Len(t, diff.Daemons, 1)
+	require.Equal(t, dbmodel.DaemonNameDHCPv4, diff.Daemons[0].DaemonName)
+	require.Empty(t, diff.Daemons[0].SubnetsOnlyInFirst)
+	require.Equal(t, []string{"192.0.3.0/24"}, diff.Daemons[0].SubnetsOnlyInSecond)
+}
@@ -0,0 +1,85 @@
+package kea
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Category of the daemon state change detected during the state pull.
+type DaemonEventCategory string
+
+const (
+	// The daemon became unreachable.
+	DaemonEventUnreachable DaemonEventCategory = "unreachable"
+	// The daemon became reachable again.
+	DaemonEventReachable DaemonEventCategory = "reachable"
+	// The daemon has been restarted.
+	DaemonEventRestarted DaemonEventCategory = "restarted"
+	// The daemon version has changed.
+	DaemonEventVersionChanged DaemonEventCategory = "version-changed"
+)
+
+// Severities of the events raised for the daemon state changes. The keys
+// are the event categories optionally prefixed with the daemon name and
+// a colon, e.g., "d2:unreachable". The daemon-specific severity takes
+// precedence over the category severity. The default severity is used when
+// the severity is not specified for the category. The nil value is valid
+// and yields the default severities.
+type DaemonEventSeverities map[string]dbmodel.EventLevel
+
+// Parses the severities specified as a comma-separated list of the
+// [daemon:]category=severity entries, e.g., "d2:unreachable=info,restarted=error".
+// The daemon is one of: dhcp4, dhcp6, d2 or ca. The severity is one of:
+// info, warning or error. It returns nil for the empty specification.
+func ParseDaemonEventSeverities(spec string) (DaemonEventSeverities, error) {
+	var severities DaemonEventSeverities
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, errors.Errorf("invalid daemon event severity '%s'; expected [daemon:]category=severity", entry)
+		}
+		key = strings.TrimSpace(key)
+		daemonName, category, hasDaemon := strings.Cut(key, ":")
+		if !hasDaemon {
+			category = daemonName
+		} else {
+			switch daemonName {
+			case dbmodel.DaemonNameDHCPv4, dbmodel.DaemonNameDHCPv6, dbmodel.DaemonNameD2, dbmodel.DaemonNameCA:
+			default:
+				return nil, errors.Errorf("unknown Kea daemon '%s'; expected one of: dhcp4, dhcp6, d2, ca", daemonName)
+			}
+		}
+		switch DaemonEventCategory(category) {
+		case DaemonEventUnreachable, DaemonEventReachable, DaemonEventRestarted, DaemonEventVersionChanged:
+		default:
+			return nil, errors.Errorf("unknown daemon event category '%s'", category)
+		}
+		level, err := dbmodel.ParseEventLevel(strings.TrimSpace(value))
+		if err != nil {
+			return nil, err
+		}
+		if severities == nil {
+			severities = make(DaemonEventSeverities)
+		}
+		severities[key] = level
+	}
+	return severities, nil
+}
+
+// Returns the severity of the event of the specified category raised for
+// the daemon. It returns the default severity if it is not configured.
+func (s DaemonEventSeverities) getLevel(category DaemonEventCategory, daemonName string, defaultLevel dbmodel.EventLevel) dbmodel.EventLevel {
+	if level, ok := s[daemonName+":"+string(category)]; ok {
+		return level
+	}
+	if level, ok := s[string(category)]; ok {
+		return level
+	}
+	return defaultLevel
+}
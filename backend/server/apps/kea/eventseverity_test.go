@@ -0,0 +1,69 @@
+package kea
+
+import (
+	"testing"
+
+	require "github.com/stretchr/testify/require"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Test that the daemon event severities are parsed correctly.
+func TestParseDaemonEventSeverities(t *testing.T) {
+	// Act
+	severities, err := ParseDaemonEventSeverities(" d2:unreachable=info, restarted=error,version-changed = warning ")
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, severities, 3)
+	require.Equal(t, dbmodel.EvInfo, severities["d2:unreachable"])
+	require.Equal(t, dbmodel.EvError, severities["restarted"])
+	require.Equal(t, dbmodel.EvWarning, severities["version-changed"])
+}
+
+// Test that the empty specification yields no severities.
+func TestParseDaemonEventSeveritiesEmpty(t *testing.T) {
+	severities, err := ParseDaemonEventSeverities("")
+	require.NoError(t, err)
+	require.Nil(t, severities)
+}
+
+// Test that parsing the invalid specification fails.
+func TestParseDaemonEventSeveritiesInvalid(t *testing.T) {
+	for _, spec := range []string{"unreachable", "foo=info", "d2:foo=error", "unreachable=critical", "dhcp:unreachable=info", ":unreachable=info", "named:restarted=error"} {
+		t.Run(spec, func(t *testing.T) {
+			severities, err := ParseDaemonEventSeverities(spec)
+			require.Error(t, err)
+			require.Nil(t, severities)
+		})
+	}
+}
+
+// Test that the severities are accepted for all Kea daemons.
+func TestParseDaemonEventSeveritiesDaemons(t *testing.T) {
+	// Act
+	severities, err := ParseDaemonEventSeverities("dhcp4:unreachable=info,dhcp6:reachable=info,d2:restarted=error,ca:version-changed=warning")
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, severities, 4)
+	require.Equal(t, dbmodel.EvInfo, severities["dhcp4:unreachable"])
+	require.Equal(t, dbmodel.EvInfo, severities["dhcp6:reachable"])
+	require.Equal(t, dbmodel.EvError, severities["d2:restarted"])
+	require.Equal(t, dbmodel.EvWarning, severities["ca:version-changed"])
+}
+
+// Test that the daemon-specific severity takes precedence over the category
+// severity and that the default severity is returned if none is configured.
+func TestDaemonEventSeveritiesGetLevel(t *testing.T) {
+	// Arrange
+	severities := DaemonEventSeverities{
+		"unreachable":    dbmodel.EvWarning,
+		"d2:unreachable": dbmodel.EvInfo,
+	}
+
+	// Act & Assert
+	require.Equal(t, dbmodel.EvInfo, severities.getLevel(DaemonEventUnreachable, "d2", dbmodel.EvError))
+	require.Equal(t, dbmodel.EvWarning, severities.getLevel(DaemonEventUnreachable, "dhcp4", dbmodel.EvError))
+	require.Equal(t, dbmodel.EvWarning, severities.getLevel(DaemonEventRestarted, "d2", dbmodel.EvWarning))
+	require.Equal(t, dbmodel.EvError, DaemonEventSeverities(nil).getLevel(DaemonEventUnreachable, "d2", dbmodel.EvError))
+}
@@ -0,0 +1,106 @@
+package kea
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Minimal number of the utilization samples required to project the
+// subnet exhaustion date.
+const minExhaustionProjectionSamples = 3
+
+// Represents a subnet utilization recorded at a given time.
+type UtilizationSample struct {
+	SampledAt   time.Time // time the utilization was recorded
+	Utilization float64   // utilization as a fraction (1.0 means full)
+}
+
+// Indicates the utilization trend observed in the subnet history.
+type ExhaustionTrend int
+
+const (
+	// Not enough history to determine the trend.
+	ExhaustionTrendUnknown ExhaustionTrend = iota
+	// The utilization grows and the subnet is expected to be exhausted.
+	ExhaustionTrendGrowing
+	// The utilization is stable or grows too slowly to project the date.
+	ExhaustionTrendNoGrowth
+	// The utilization decreases.
+	ExhaustionTrendDecreasing
+	// The subnet is already exhausted.
+	ExhaustionTrendExhausted
+)
+
+// The result of the subnet exhaustion projection. The ExhaustedAt is only
+// set when the trend is ExhaustionTrendGrowing or ExhaustionTrendExhausted.
+type ExhaustionProjection struct {
+	Trend       ExhaustionTrend
+	ExhaustedAt *time.Time
+}
+
+// Projects the date when the subnet utilization reaches 100% by fitting a
+// linear trend (least squares) to the utilization history. The samples
+// don't have to be sorted. The caller is responsible for limiting them to
+// the recent history. The trend is unknown if there are fewer than three
+// samples or all of them were recorded at the same time. If the latest
+// sample indicates full utilization, the subnet is reported as exhausted
+// at that time. The projected date is never earlier than the latest sample.
+func ProjectSubnetExhaustion(samples []UtilizationSample) ExhaustionProjection {
+	if len(samples) < minExhaustionProjectionSamples {
+		return ExhaustionProjection{Trend: ExhaustionTrendUnknown}
+	}
+
+	sorted := make([]UtilizationSample, len(samples))
+	copy(sorted, samples)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].SampledAt.Before(sorted[j].SampledAt)
+	})
+	first := sorted[0]
+	last := sorted[len(sorted)-1]
+
+	if last.Utilization >= 1 {
+		exhaustedAt := last.SampledAt
+		return ExhaustionProjection{Trend: ExhaustionTrendExhausted, ExhaustedAt: &exhaustedAt}
+	}
+
+	// The time is expressed in seconds relative to the first sample to
+	// avoid the loss of precision.
+	var meanX, meanY float64
+	for _, sample := range sorted {
+		meanX += sample.SampledAt.Sub(first.SampledAt).Seconds()
+		meanY += sample.Utilization
+	}
+	meanX /= float64(len(sorted))
+	meanY /= float64(len(sorted))
+
+	var covariance, variance float64
+	for _, sample := range sorted {
+		dx := sample.SampledAt.Sub(first.SampledAt).Seconds() - meanX
+		covariance += dx * (sample.Utilization - meanY)
+		variance += dx * dx
+	}
+	if variance == 0 {
+		return ExhaustionProjection{Trend: ExhaustionTrendUnknown}
+	}
+
+	slope := covariance / variance
+	switch {
+	case slope < 0:
+		return ExhaustionProjection{Trend: ExhaustionTrendDecreasing}
+	case slope == 0:
+		return ExhaustionProjection{Trend: ExhaustionTrendNoGrowth}
+	}
+
+	intercept := meanY - slope*meanX
+	seconds := (1 - intercept) / slope
+	// The growth is so slow that the date can't be represented.
+	if seconds > float64(math.MaxInt64)/float64(time.Second) {
+		return ExhaustionProjection{Trend: ExhaustionTrendNoGrowth}
+	}
+	exhaustedAt := first.SampledAt.Add(time.Duration(seconds * float64(time.Second)))
+	if exhaustedAt.Before(last.SampledAt) {
+		exhaustedAt = last.SampledAt
+	}
+	return ExhaustionProjection{Trend: ExhaustionTrendGrowing, ExhaustedAt: &exhaustedAt}
+}
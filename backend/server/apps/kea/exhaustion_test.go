@@ -0,0 +1,163 @@
+package kea
+
+import (
+	"testing"
+	"time"
+
+	require "github.com/stretchr/testify/require"
+)
+
+// Generates the utilization samples recorded daily, starting from the
+// specified time, with the utilization computed by the specified function.
+func generateUtilizationSamples(start time.Time, count int, utilization func(day int) float64) []UtilizationSample {
+	var samples []UtilizationSample
+	for i := 0; i < count; i++ {
+		samples = append(samples, UtilizationSample{
+			SampledAt:   start.Add(time.Duration(i) * 24 * time.Hour),
+			Utilization: utilization(i),
+		})
+	}
+	return samples
+}
+
+// Test that the exhaustion date is projected for the linearly growing
+// utilization.
+func TestProjectSubnetExhaustionLinearGrowth(t *testing.T) {
+	// Arrange
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := generateUtilizationSamples(start, 10, func(day int) float64 {
+		return 0.1 + 0.05*float64(day)
+	})
+
+	// Act
+	projection := ProjectSubnetExhaustion(samples)
+
+	// Assert
+	require.Equal(t, ExhaustionTrendGrowing, projection.Trend)
+	require.NotNil(t, projection.ExhaustedAt)
+	// 0.1 + 0.05 * 18 = 1.0
+	require.WithinDuration(t, start.Add(18*24*time.Hour), *projection.ExhaustedAt, time.Minute)
+}
+
+// Test that the exhaustion date is projected for the noisy growth and
+// that the samples order doesn't matter.
+func TestProjectSubnetExhaustionNoisyGrowthUnordered(t *testing.T) {
+	// Arrange
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := generateUtilizationSamples(start, 8, func(day int) float64 {
+		noise := 0.01
+		if day%2 == 0 {
+			noise = -noise
+		}
+		return 0.2 + 0.02*float64(day) + noise
+	})
+	samples[0], samples[5] = samples[5], samples[0]
+
+	// Act
+	projection := ProjectSubnetExhaustion(samples)
+
+	// Assert
+	require.Equal(t, ExhaustionTrendGrowing, projection.Trend)
+	require.NotNil(t, projection.ExhaustedAt)
+	require.WithinDuration(t, start.Add(40*24*time.Hour), *projection.ExhaustedAt, 2*24*time.Hour)
+}
+
+// Test that the stable utilization is reported as no growth.
+func TestProjectSubnetExhaustionNoGrowth(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := generateUtilizationSamples(start, 5, func(day int) float64 {
+		return 0.5
+	})
+
+	projection := ProjectSubnetExhaustion(samples)
+
+	require.Equal(t, ExhaustionTrendNoGrowth, projection.Trend)
+	require.Nil(t, projection.ExhaustedAt)
+}
+
+// Test that the decreasing utilization is reported.
+func TestProjectSubnetExhaustionDecreasing(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := generateUtilizationSamples(start, 5, func(day int) float64 {
+		return 0.9 - 0.1*float64(day)
+	})
+
+	projection := ProjectSubnetExhaustion(samples)
+
+	require.Equal(t, ExhaustionTrendDecreasing, projection.Trend)
+	require.Nil(t, projection.ExhaustedAt)
+}
+
+// Test that the subnet is reported as exhausted when the latest sample
+// indicates full utilization.
+func TestProjectSubnetExhaustionAlreadyExhausted(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := generateUtilizationSamples(start, 5, func(day int) float64 {
+		return 0.6 + 0.1*float64(day)
+	})
+
+	projection := ProjectSubnetExhaustion(samples)
+
+	require.Equal(t, ExhaustionTrendExhausted, projection.Trend)
+	require.NotNil(t, projection.ExhaustedAt)
+	require.Equal(t, samples[4].SampledAt, *projection.ExhaustedAt)
+}
+
+// Test that the projected date is not earlier than the latest sample when
+// the fitted trend crosses the full utilization in the past.
+func TestProjectSubnetExhaustionNotInPast(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := []UtilizationSample{
+		{SampledAt: start, Utilization: 0.0},
+		{SampledAt: start.Add(time.Hour), Utilization: 0.0},
+		{SampledAt: start.Add(2 * time.Hour), Utilization: 0.99},
+	}
+
+	projection := ProjectSubnetExhaustion(samples)
+
+	require.Equal(t, ExhaustionTrendGrowing, projection.Trend)
+	require.NotNil(t, projection.ExhaustedAt)
+	require.False(t, projection.ExhaustedAt.Before(samples[2].SampledAt))
+}
+
+// Test that the trend is unknown when there is not enough history.
+func TestProjectSubnetExhaustionInsufficientHistory(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// No samples.
+	projection := ProjectSubnetExhaustion(nil)
+	require.Equal(t, ExhaustionTrendUnknown, projection.Trend)
+	require.Nil(t, projection.ExhaustedAt)
+
+	// Too few samples.
+	samples := generateUtilizationSamples(start, 2, func(day int) float64 {
+		return 0.1 * float64(day)
+	})
+	projection = ProjectSubnetExhaustion(samples)
+	require.Equal(t, ExhaustionTrendUnknown, projection.Trend)
+	require.Nil(t, projection.ExhaustedAt)
+
+	// All samples recorded at the same time.
+	samples = []UtilizationSample{
+		{SampledAt: start, Utilization: 0.1},
+		{SampledAt: start, Utilization: 0.2},
+		{SampledAt: start, Utilization: 0.3},
+	}
+	projection = ProjectSubnetExhaustion(samples)
+	require.Equal(t, ExhaustionTrendUnknown, projection.Trend)
+	require.Nil(t, projection.ExhaustedAt)
+}
+
+// Test that the extremely slow growth that can't be represented as a date
+// is reported as no growth.
+func TestProjectSubnetExhaustionExtremelySlowGrowth(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := generateUtilizationSamples(start, 3, func(day int) float64 {
+		return 0.1 + 1e-15*float64(day)
+	})
+
+	projection := ProjectSubnetExhaustion(samples)
+
+	require.Equal(t, ExhaustionTrendNoGrowth, projection.Trend)
+	require.Nil(t, projection.ExhaustedAt)
+}
@@ -0,0 +1,68 @@
+package kea
+
+import (
+	"time"
+
+	dbops "isc.org/stork/server/database"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Returns the times of the most recent statistics collected from the
+// daemons by daemon ID.
+func getStatsCollectedAt(subnets []*dbmodel.Subnet) map[int64]time.Time {
+	collectedAt := make(map[int64]time.Time)
+	for _, subnet := range subnets {
+		for _, localSubnet := range subnet.LocalSubnets {
+			if localSubnet.StatsCollectedAt.After(collectedAt[localSubnet.DaemonID]) {
+				collectedAt[localSubnet.DaemonID] = localSubnet.StatsCollectedAt
+			}
+		}
+	}
+	return collectedAt
+}
+
+// Returns the ID of the HA peer whose statistics are not authoritative.
+// It is the peer whose statistics were collected less recently, e.g., the
+// peer that stopped responding. The statistics collected since the
+// specified time, i.e., during the current pull, are equally recent. In
+// this case, the passive peer is selected according to the HA states.
+func getNonAuthoritativeHAPeerID(service *dbmodel.BaseHAService, collectedAt map[int64]time.Time, since time.Time) int64 {
+	primaryAt := collectedAt[service.PrimaryID]
+	secondaryAt := collectedAt[service.SecondaryID]
+	if primaryAt.Before(since) || secondaryAt.Before(since) {
+		switch {
+		case primaryAt.Before(secondaryAt):
+			return service.PrimaryID
+		case secondaryAt.Before(primaryAt):
+			return service.SecondaryID
+		}
+	}
+	return service.GetPassivePeerID()
+}
+
+// Returns the IDs of the HA daemons whose statistics are excluded from the
+// global statistics. The HA peers return the same statistics, so only one
+// daemon in each HA service is the authoritative source of the global
+// statistics to avoid counting the same leases multiple times. The local
+// subnet statistics are still stored for all daemons. The HA services are
+// detected from the daemons' configurations. The services not stored in
+// the database yet are skipped because their HA states are unknown. The
+// backup servers are always excluded.
+func getNonAuthoritativeHADaemonIDs(dbi dbops.DBI, daemons []*dbmodel.Daemon, subnets []*dbmodel.Subnet, since time.Time) []int64 {
+	collectedAt := getStatsCollectedAt(subnets)
+
+	excludedDaemons := []int64{}
+	detectedServices := make(map[int64]bool)
+	for _, daemon := range daemons {
+		for _, service := range DetectHAServices(dbi, daemon) {
+			if service.IsNew() || detectedServices[service.ID] {
+				continue
+			}
+			detectedServices[service.ID] = true
+
+			excludedDaemons = append(excludedDaemons, service.HAService.BackupID...)
+			excludedDaemons = append(excludedDaemons, getNonAuthoritativeHAPeerID(service.HAService, collectedAt, since))
+		}
+	}
+	return excludedDaemons
+}
@@ -0,0 +1,139 @@
+package kea
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+)
+
+// Test that the most recent statistics collection times are returned
+// for the daemons.
+func TestGetStatsCollectedAt(t *testing.T) {
+	// Arrange
+	now := time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)
+	subnets := []*dbmodel.Subnet{
+		{
+			LocalSubnets: []*dbmodel.LocalSubnet{
+				{DaemonID: 1, StatsCollectedAt: now.Add(-time.Minute)},
+				{DaemonID: 2, StatsCollectedAt: now},
+			},
+		},
+		{
+			LocalSubnets: []*dbmodel.LocalSubnet{
+				{DaemonID: 1, StatsCollectedAt: now},
+				{DaemonID: 3},
+			},
+		},
+	}
+
+	// Act
+	collectedAt := getStatsCollectedAt(subnets)
+
+	// Assert
+	require.Len(t, collectedAt, 2)
+	require.Equal(t, now, collectedAt[1])
+	require.Equal(t, now, collectedAt[2])
+	require.Zero(t, collectedAt[3])
+}
+
+// Test that the HA peer with the less recent statistics is not
+// authoritative and the HA states are used when the statistics of both
+// peers are up to date.
+func TestGetNonAuthoritativeHAPeerID(t *testing.T) {
+	// Arrange
+	since := time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)
+	service := &dbmodel.BaseHAService{
+		PrimaryID:          1,
+		SecondaryID:        2,
+		PrimaryLastState:   dbmodel.HAStateHotStandby,
+		PrimaryReachable:   true,
+		SecondaryLastState: dbmodel.HAStateHotStandby,
+		SecondaryReachable: true,
+	}
+
+	t.Run("both up to date", func(t *testing.T) {
+		collectedAt := map[int64]time.Time{
+			1: since.Add(2 * time.Second),
+			2: since.Add(time.Second),
+		}
+		require.EqualValues(t, 2, getNonAuthoritativeHAPeerID(service, collectedAt, since))
+	})
+
+	t.Run("primary outdated", func(t *testing.T) {
+		collectedAt := map[int64]time.Time{
+			1: since.Add(-time.Minute),
+			2: since.Add(time.Second),
+		}
+		require.EqualValues(t, 1, getNonAuthoritativeHAPeerID(service, collectedAt, since))
+	})
+
+	t.Run("secondary never collected", func(t *testing.T) {
+		collectedAt := map[int64]time.Time{
+			1: since.Add(-time.Minute),
+		}
+		require.EqualValues(t, 2, getNonAuthoritativeHAPeerID(service, collectedAt, since))
+	})
+
+	t.Run("both outdated", func(t *testing.T) {
+		collectedAt := map[int64]time.Time{
+			1: since.Add(-2 * time.Minute),
+			2: since.Add(-time.Minute),
+		}
+		require.EqualValues(t, 1, getNonAuthoritativeHAPeerID(service, collectedAt, since))
+	})
+
+	t.Run("none collected", func(t *testing.T) {
+		require.EqualValues(t, 2, getNonAuthoritativeHAPeerID(service, map[int64]time.Time{}, since))
+	})
+}
+
+// Test that a single daemon in each detected HA service is the
+// authoritative source of the global statistics.
+func TestGetNonAuthoritativeHADaemonIDs(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	loadBalancing, hotStandby := prepareHAEnvironment(t, db)
+	for _, service := range []*dbmodel.Service{loadBalancing, hotStandby} {
+		service.HAService.PrimaryLastState = dbmodel.HAStateReady
+		service.HAService.PrimaryReachable = true
+		service.HAService.SecondaryLastState = dbmodel.HAStateReady
+		service.HAService.SecondaryReachable = true
+		_ = dbmodel.UpdateService(db, service)
+	}
+
+	apps, err := dbmodel.GetAppsByType(db, dbmodel.AppTypeKea)
+	require.NoError(t, err)
+	var daemons []*dbmodel.Daemon
+	for _, app := range apps {
+		daemons = append(daemons, app.Daemons...)
+	}
+
+	// The hot-standby primary server stopped responding.
+	since := time.Now().UTC()
+	subnets := []*dbmodel.Subnet{
+		{
+			LocalSubnets: []*dbmodel.LocalSubnet{
+				{DaemonID: loadBalancing.HAService.PrimaryID, StatsCollectedAt: since.Add(time.Second)},
+				{DaemonID: loadBalancing.HAService.SecondaryID, StatsCollectedAt: since.Add(time.Second)},
+				{DaemonID: hotStandby.HAService.PrimaryID, StatsCollectedAt: since.Add(-time.Minute)},
+				{DaemonID: hotStandby.HAService.SecondaryID, StatsCollectedAt: since.Add(time.Second)},
+			},
+		},
+	}
+
+	// Act
+	excludedDaemons := getNonAuthoritativeHADaemonIDs(db, daemons, subnets, since)
+
+	// Assert
+	require.Len(t, excludedDaemons, 2+len(loadBalancing.HAService.BackupID)+len(hotStandby.HAService.BackupID))
+	require.Contains(t, excludedDaemons, loadBalancing.HAService.SecondaryID)
+	require.Contains(t, excludedDaemons, hotStandby.HAService.PrimaryID)
+	for _, backupID := range loadBalancing.HAService.BackupID {
+		require.Contains(t, excludedDaemons, backupID)
+	}
+}
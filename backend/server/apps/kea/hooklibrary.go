@@ -0,0 +1,101 @@
+package kea
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"isc.org/stork/server/agentcomm"
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/eventcenter"
+)
+
+// Verifies that the hook library files referenced in the configurations
+// of the Kea daemons belonging to the app exist on the app's machine. A
+// missing hook library file causes a Kea startup failure. The files are
+// checked by the Stork agent in a single call. It returns the paths to the
+// missing files by daemon ID. The daemons with all hook library files
+// present are not included in the returned map. The relative paths are
+// skipped because Kea resolves them against its hooks directory which is
+// unknown to Stork.
+func FindMissingHookLibraries(agents agentcomm.ConnectedAgents, app *dbmodel.App) (map[int64][]string, error) {
+	if app.Machine == nil {
+		return nil, errors.Errorf("machine of the app with ID %d is not available", app.ID)
+	}
+
+	// Collect the unique paths from all daemons.
+	var paths []string
+	visited := make(map[string]bool)
+	for _, daemon := range app.Daemons {
+		if daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil {
+			continue
+		}
+		for _, hook := range daemon.KeaDaemon.Config.GetHookLibraries() {
+			if !filepath.IsAbs(hook.Library) || visited[hook.Library] {
+				continue
+			}
+			visited[hook.Library] = true
+			paths = append(paths, hook.Library)
+		}
+	}
+
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	files, err := agents.CheckHookLibraries(context.Background(), app.Machine.Address, app.Machine.AgentPort, paths)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to check the hook library files of the app with ID %d", app.ID)
+	}
+
+	missing := make(map[int64][]string)
+	for _, daemon := range app.Daemons {
+		if daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil {
+			continue
+		}
+		for _, hook := range daemon.KeaDaemon.Config.GetHookLibraries() {
+			// The files not returned by the agent are assumed to exist.
+			if exists, ok := files[hook.Library]; ok && !exists {
+				missing[daemon.ID] = append(missing[daemon.ID], hook.Library)
+			}
+		}
+	}
+	return missing, nil
+}
+
+// Checks the hook library files of the Kea daemons which configurations
+// have changed since the last state pull and raises a warning event for
+// each daemon referencing the missing files. The daemons with unchanged
+// configurations are skipped to avoid contacting the agent and raising
+// the same events on every pull. It is called by the state puller after
+// the app state has been committed into the database.
+func ReportMissingHookLibraries(agents agentcomm.ConnectedAgents, app *dbmodel.App, state *AppStateMeta, eventCenter eventcenter.EventCenter) {
+	changedApp := *app
+	changedApp.Daemons = nil
+	for _, daemon := range app.Daemons {
+		if state != nil && state.SameConfigDaemons[daemon.Name] {
+			continue
+		}
+		changedApp.Daemons = append(changedApp.Daemons, daemon)
+	}
+	if len(changedApp.Daemons) == 0 {
+		return
+	}
+
+	missing, err := FindMissingHookLibraries(agents, &changedApp)
+	if err != nil {
+		log.WithError(err).Warn("Failed to find missing hook libraries")
+		return
+	}
+	for _, daemon := range changedApp.Daemons {
+		if paths, ok := missing[daemon.ID]; ok {
+			eventCenter.AddWarningEvent(
+				fmt.Sprintf("{daemon} is configured with the hook libraries missing on the machine: %s", strings.Join(paths, ", ")),
+				app.Machine, app, daemon,
+			)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package kea
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	agentcommtest "isc.org/stork/server/agentcomm/test"
+	dbmodel "isc.org/stork/server/database/model"
+	storktest "isc.org/stork/server/test/dbmodel"
+)
+
+// Returns the Kea app with the DHCPv4 and DHCPv6 daemons using the
+// specified hook libraries.
+func getHookLibrariesTestApp(t *testing.T) *dbmodel.App {
+	config4, err := dbmodel.NewKeaConfigFromJSON(`{
+        "Dhcp4": {
+            "hooks-libraries": [
+                { "library": "/usr/lib/kea/hooks/libdhcp_lease_cmds.so" },
+                { "library": "/usr/lib/kea/hooks/libdhcp_ha.so" }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+	config6, err := dbmodel.NewKeaConfigFromJSON(`{
+        "Dhcp6": {
+            "hooks-libraries": [
+                { "library": "/usr/lib/kea/hooks/libdhcp_lease_cmds.so" },
+                { "library": "libdhcp_stat_cmds.so" }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	return &dbmodel.App{
+		ID: 1,
+		Machine: &dbmodel.Machine{
+			Address:   "192.0.2.1",
+			AgentPort: 8080,
+		},
+		Daemons: []*dbmodel.Daemon{
+			{
+				ID:   1,
+				Name: dbmodel.DaemonNameDHCPv4,
+				KeaDaemon: &dbmodel.KeaDaemon{
+					Config: config4,
+				},
+			},
+			{
+				ID:   2,
+				Name: dbmodel.DaemonNameDHCPv6,
+				KeaDaemon: &dbmodel.KeaDaemon{
+					Config: config6,
+				},
+			},
+			{
+				ID:        3,
+				Name:      dbmodel.DaemonNameCA,
+				KeaDaemon: &dbmodel.KeaDaemon{},
+			},
+		},
+	}
+}
+
+// Test that no missing hook libraries are returned when all files exist.
+func TestFindMissingHookLibrariesAllPresent(t *testing.T) {
+	// Arrange
+	agents := agentcommtest.NewFakeAgents(nil, nil)
+	app := getHookLibrariesTestApp(t)
+
+	// Act
+	missing, err := FindMissingHookLibraries(agents, app)
+
+	// Assert
+	require.NoError(t, err)
+	require.Empty(t, missing)
+	// The duplicated and relative paths are not checked.
+	require.Equal(t, []string{
+		"/usr/lib/kea/hooks/libdhcp_lease_cmds.so",
+		"/usr/lib/kea/hooks/libdhcp_ha.so",
+	}, agents.RecordedHookLibraries)
+}
+
+// Test that the missing hook libraries are returned by daemon ID.
+func TestFindMissingHookLibraries(t *testing.T) {
+	// Arrange
+	agents := agentcommtest.NewFakeAgents(nil, nil)
+	agents.MissingHookLibraries = []string{"/usr/lib/kea/hooks/libdhcp_lease_cmds.so"}
+	app := getHookLibrariesTestApp(t)
+
+	// Act
+	missing, err := FindMissingHookLibraries(agents, app)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, missing, 2)
+	require.Equal(t, []string{"/usr/lib/kea/hooks/libdhcp_lease_cmds.so"}, missing[1])
+	require.Equal(t, []string{"/usr/lib/kea/hooks/libdhcp_lease_cmds.so"}, missing[2])
+}
+
+// Test that the agent is not contacted when there are no hook libraries
+// to check.
+func TestFindMissingHookLibrariesNoHooks(t *testing.T) {
+	// Arrange
+	agents := agentcommtest.NewFakeAgents(nil, nil)
+	app := &dbmodel.App{
+		ID:      1,
+		Machine: &dbmodel.Machine{},
+	}
+
+	// Act
+	missing, err := FindMissingHookLibraries(agents, app)
+
+	// Assert
+	require.NoError(t, err)
+	require.Empty(t, missing)
+	require.Empty(t, agents.RecordedHookLibraries)
+}
+
+// Test that an error is returned when the app's machine is not available.
+func TestFindMissingHookLibrariesNoMachine(t *testing.T) {
+	// Arrange
+	agents := agentcommtest.NewFakeAgents(nil, nil)
+	app := getHookLibrariesTestApp(t)
+	app.Machine = nil
+
+	// Act
+	missing, err := FindMissingHookLibraries(agents, app)
+
+	// Assert
+	require.Error(t, err)
+	require.Nil(t, missing)
+}
+
+// Test that a warning event is raised for each daemon with the modified
+// configuration referencing the missing hook libraries.
+func TestReportMissingHookLibraries(t *testing.T) {
+	// Arrange
+	agents := agentcommtest.NewFakeAgents(nil, nil)
+	agents.MissingHookLibraries = []string{"/usr/lib/kea/hooks/libdhcp_lease_cmds.so"}
+	app := getHookLibrariesTestApp(t)
+	eventCenter := &storktest.FakeEventCenter{}
+	state := &AppStateMeta{
+		SameConfigDaemons: map[string]bool{
+			dbmodel.DaemonNameDHCPv6: true,
+		},
+	}
+
+	// Act
+	ReportMissingHookLibraries(agents, app, state, eventCenter)
+
+	// Assert
+	require.Len(t, eventCenter.Events, 1)
+	require.Contains(t, eventCenter.Events[0].Text, "/usr/lib/kea/hooks/libdhcp_lease_cmds.so")
+	require.Equal(t, dbmodel.EvWarning, eventCenter.Events[0].Level)
+}
+
+// Test that the agent is not contacted when the configurations haven't
+// changed since the last state pull.
+func TestReportMissingHookLibrariesSameConfigs(t *testing.T) {
+	// Arrange
+	agents := agentcommtest.NewFakeAgents(nil, nil)
+	agents.MissingHookLibraries = []string{"/usr/lib/kea/hooks/libdhcp_lease_cmds.so"}
+	app := getHookLibrariesTestApp(t)
+	eventCenter := &storktest.FakeEventCenter{}
+	state := &AppStateMeta{
+		SameConfigDaemons: map[string]bool{
+			dbmodel.DaemonNameDHCPv4: true,
+			dbmodel.DaemonNameDHCPv6: true,
+			dbmodel.DaemonNameCA:     true,
+		},
+	}
+
+	// Act
+	ReportMissingHookLibraries(agents, app, state, eventCenter)
+
+	// Assert
+	require.Empty(t, eventCenter.Events)
+	require.Empty(t, agents.RecordedHookLibraries)
+}
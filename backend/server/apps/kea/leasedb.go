@@ -0,0 +1,193 @@
+package kea
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/eventcenter"
+)
+
+// Timeout for establishing the TCP connection with the lease database.
+const leaseDatabaseProbeTimeout = 2 * time.Second
+
+// Maximum number of the lease databases probed at the same time.
+const leaseDatabaseProbeConcurrency = 16
+
+// Function establishing the connection with the lease database. It has the
+// same signature as the net.DialTimeout and can be replaced in the unit
+// tests.
+type LeaseDatabaseDialer func(network, address string, timeout time.Duration) (net.Conn, error)
+
+// Lease database address and reachability recorded for a daemon during
+// the last probe.
+type leaseDatabaseState struct {
+	address   string
+	reachable bool
+}
+
+// Lease database of a daemon to be probed and the probe result.
+type leaseDatabaseProbeTarget struct {
+	app     *dbmodel.App
+	daemon  *dbmodel.Daemon
+	address string
+	err     error
+}
+
+// Checks whether the lease databases used by the Kea DHCP servers are
+// reachable from the Stork server. It dials the lease database host
+// configured in the lease-database structure and raises a warning event
+// when the database becomes unreachable, and an informational event when
+// it becomes reachable again or its address changes. The events are raised
+// only on the state transitions, so the probe can be run periodically.
+// The memfile backend is not probed. The lease databases are dialed
+// concurrently, so the unreachable databases don't delay each other.
+type LeaseDatabaseProber struct {
+	dial   LeaseDatabaseDialer
+	mutex  sync.Mutex
+	states map[int64]*leaseDatabaseState
+}
+
+// Creates the lease database prober. If the dialer is nil, the net.DialTimeout
+// is used.
+func NewLeaseDatabaseProber(dial LeaseDatabaseDialer) *LeaseDatabaseProber {
+	if dial == nil {
+		dial = net.DialTimeout
+	}
+	return &LeaseDatabaseProber{
+		dial:   dial,
+		states: make(map[int64]*leaseDatabaseState),
+	}
+}
+
+// Returns the address of the lease database used by the daemon. The
+// second returned value is false if the daemon doesn't use the lease
+// database that can be probed (e.g., it uses the memfile backend). The
+// localhost is substituted with the machine address because it refers to
+// the machine where Kea is running rather than the Stork server.
+func getLeaseDatabaseAddress(app *dbmodel.App, daemon *dbmodel.Daemon) (string, bool) {
+	if daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil {
+		return "", false
+	}
+	database := daemon.KeaDaemon.Config.GetAllDatabases().Lease
+	if database == nil || database.Host == "" {
+		return "", false
+	}
+	var port int64
+	switch database.Type {
+	case "mysql":
+		port = 3306
+	case "postgresql":
+		port = 5432
+	default:
+		return "", false
+	}
+	if database.Port != 0 {
+		port = database.Port
+	}
+	host := database.Host
+	if ip := net.ParseIP(host); host == "localhost" || (ip != nil && ip.IsLoopback()) {
+		if app.Machine == nil {
+			return "", false
+		}
+		host = app.Machine.Address
+	}
+	return net.JoinHostPort(host, fmt.Sprint(port)), true
+}
+
+// Probes the lease databases of the DHCP daemons belonging to the specified
+// Kea apps and returns the events to be passed to the event center. The
+// apps should include all monitored Kea apps because the recorded states
+// of the daemons not belonging to them are discarded. It prevents growing
+// the states when the daemons are deleted.
+func (p *LeaseDatabaseProber) Probe(apps []*dbmodel.App) (events []*dbmodel.Event) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var targets []*leaseDatabaseProbeTarget
+	probed := make(map[int64]bool)
+	for _, app := range apps {
+		if app.Type != dbmodel.AppTypeKea {
+			continue
+		}
+		for _, daemon := range app.Daemons {
+			if !daemon.Active || daemon.ID == 0 {
+				continue
+			}
+			address, ok := getLeaseDatabaseAddress(app, daemon)
+			if !ok {
+				continue
+			}
+			probed[daemon.ID] = true
+			targets = append(targets, &leaseDatabaseProbeTarget{
+				app:     app,
+				daemon:  daemon,
+				address: address,
+			})
+		}
+	}
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, leaseDatabaseProbeConcurrency)
+	for _, target := range targets {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(target *leaseDatabaseProbeTarget) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			conn, err := p.dial("tcp", target.address, leaseDatabaseProbeTimeout)
+			if err == nil {
+				conn.Close()
+			}
+			target.err = err
+		}(target)
+	}
+	wg.Wait()
+
+	for daemonID := range p.states {
+		if !probed[daemonID] {
+			delete(p.states, daemonID)
+		}
+	}
+
+	for _, target := range targets {
+		events = append(events, p.updateState(target)...)
+	}
+	return events
+}
+
+// Records the probe result for the daemon and returns the events raised
+// on the lease database state transition.
+func (p *LeaseDatabaseProber) updateState(target *leaseDatabaseProbeTarget) (events []*dbmodel.Event) {
+	app, daemon, address := target.app, target.daemon, target.address
+	reachable := target.err == nil
+	var details string
+	if !reachable {
+		details = target.err.Error()
+	}
+
+	state, known := p.states[daemon.ID]
+	p.states[daemon.ID] = &leaseDatabaseState{address: address, reachable: reachable}
+
+	addressChanged := known && state.address != address
+	if addressChanged {
+		events = append(events, eventcenter.CreateEvent(dbmodel.EvInfo,
+			fmt.Sprintf("lease database of {daemon} changed from %s to %s", state.address, address),
+			app.Machine, app, daemon))
+	}
+	switch {
+	case !reachable && (!known || state.reachable || addressChanged):
+		events = append(events, eventcenter.CreateEvent(dbmodel.EvWarning,
+			fmt.Sprintf("lease database %s of {daemon} appears unreachable from the Stork server", address),
+			details, app.Machine, app, daemon))
+	case reachable && known && !state.reachable && !addressChanged:
+		events = append(events, eventcenter.CreateEvent(dbmodel.EvInfo,
+			fmt.Sprintf("lease database %s of {daemon} is reachable again", address),
+			app.Machine, app, daemon))
+	}
+	return events
+}
@@ -0,0 +1,195 @@
+package kea
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	require "github.com/stretchr/testify/require"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Fake dialer recording the dialed addresses. It fails to connect to the
+// addresses marked as unreachable. It is safe for concurrent use.
+type fakeLeaseDatabaseDialer struct {
+	mutex       sync.Mutex
+	dialed      []string
+	unreachable map[string]bool
+}
+
+// Simulates establishing the connection with the lease database.
+func (d *fakeLeaseDatabaseDialer) dial(network, address string, timeout time.Duration) (net.Conn, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.dialed = append(d.dialed, address)
+	if d.unreachable[address] {
+		return nil, errors.New("connection refused")
+	}
+	client, server := net.Pipe()
+	server.Close()
+	return client, nil
+}
+
+// Creates a Kea app with a DHCPv4 daemon using the specified lease database
+// configuration.
+func createLeaseDatabaseTestApp(t *testing.T, leaseDatabase string) *dbmodel.App {
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 1
+	err := daemon.SetConfigFromJSON(`{"Dhcp4": {"lease-database": ` + leaseDatabase + `}}`)
+	require.NoError(t, err)
+	return &dbmodel.App{
+		ID:      1,
+		Type:    dbmodel.AppTypeKea,
+		Machine: &dbmodel.Machine{Address: "192.0.2.1"},
+		Daemons: []*dbmodel.Daemon{daemon},
+	}
+}
+
+// Test that no events are raised when the lease database is reachable.
+func TestProbeLeaseDatabaseReachable(t *testing.T) {
+	// Arrange
+	dialer := &fakeLeaseDatabaseDialer{}
+	prober := NewLeaseDatabaseProber(dialer.dial)
+	app := createLeaseDatabaseTestApp(t, `{"type": "postgresql", "name": "kea", "host": "db.example.org"}`)
+
+	// Act
+	events := prober.Probe([]*dbmodel.App{app})
+
+	// Assert
+	require.Empty(t, events)
+	require.Equal(t, []string{"db.example.org:5432"}, dialer.dialed)
+}
+
+// Test that the warning is raised once when the lease database is
+// unreachable and the informational event is raised when it becomes
+// reachable again.
+func TestProbeLeaseDatabaseUnreachable(t *testing.T) {
+	// Arrange
+	dialer := &fakeLeaseDatabaseDialer{
+		unreachable: map[string]bool{"db.example.org:3307": true},
+	}
+	prober := NewLeaseDatabaseProber(dialer.dial)
+	app := createLeaseDatabaseTestApp(t, `{"type": "mysql", "name": "kea", "host": "db.example.org", "port": 3307}`)
+
+	// Act
+	events := prober.Probe([]*dbmodel.App{app})
+
+	// Assert
+	require.Len(t, events, 1)
+	require.Equal(t, dbmodel.EvWarning, events[0].Level)
+	require.Contains(t, events[0].Text, "lease database db.example.org:3307")
+	require.Contains(t, events[0].Text, "appears unreachable from the Stork server")
+	require.Contains(t, events[0].Details, "connection refused")
+
+	// The warning is not repeated.
+	events = prober.Probe([]*dbmodel.App{app})
+	require.Empty(t, events)
+
+	// The database is reachable again.
+	dialer.unreachable = nil
+	events = prober.Probe([]*dbmodel.App{app})
+	require.Len(t, events, 1)
+	require.Equal(t, dbmodel.EvInfo, events[0].Level)
+	require.Contains(t, events[0].Text, "is reachable again")
+	require.Len(t, dialer.dialed, 3)
+}
+
+// Test that the change of the lease database address is reported.
+func TestProbeLeaseDatabaseAddressChanged(t *testing.T) {
+	// Arrange
+	dialer := &fakeLeaseDatabaseDialer{
+		unreachable: map[string]bool{"db2.example.org:5432": true},
+	}
+	prober := NewLeaseDatabaseProber(dialer.dial)
+	app := createLeaseDatabaseTestApp(t, `{"type": "postgresql", "name": "kea", "host": "db1.example.org"}`)
+	require.Empty(t, prober.Probe([]*dbmodel.App{app}))
+
+	app = createLeaseDatabaseTestApp(t, `{"type": "postgresql", "name": "kea", "host": "db2.example.org"}`)
+
+	// Act
+	events := prober.Probe([]*dbmodel.App{app})
+
+	// Assert
+	require.Len(t, events, 2)
+	require.Equal(t, dbmodel.EvInfo, events[0].Level)
+	require.Contains(t, events[0].Text, "changed from db1.example.org:5432 to db2.example.org:5432")
+	require.Equal(t, dbmodel.EvWarning, events[1].Level)
+	require.Contains(t, events[1].Text, "lease database db2.example.org:5432")
+}
+
+// Test that the localhost is replaced with the machine address and that
+// the memfile backend is not probed.
+func TestProbeLeaseDatabaseAddress(t *testing.T) {
+	// Arrange
+	dialer := &fakeLeaseDatabaseDialer{}
+	prober := NewLeaseDatabaseProber(dialer.dial)
+
+	// Act
+	prober.Probe([]*dbmodel.App{createLeaseDatabaseTestApp(t, `{"type": "mysql", "name": "kea"}`)})
+	prober.Probe([]*dbmodel.App{createLeaseDatabaseTestApp(t, `{"type": "postgresql", "name": "kea", "host": "::1"}`)})
+	prober.Probe([]*dbmodel.App{createLeaseDatabaseTestApp(t, `{"type": "memfile", "name": "/tmp/leases4.csv"}`)})
+
+	// Assert
+	require.Equal(t, []string{"192.0.2.1:3306", "192.0.2.1:5432"}, dialer.dialed)
+}
+
+// Test that the lease databases of multiple daemons are dialed concurrently.
+func TestProbeLeaseDatabaseConcurrently(t *testing.T) {
+	// Arrange
+	const daemonCount = 3
+	var wg sync.WaitGroup
+	wg.Add(daemonCount)
+	allDialing := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(allDialing)
+	}()
+	// The dialer returns only when all databases are being dialed. It
+	// would time out if the databases were dialed one by one.
+	prober := NewLeaseDatabaseProber(func(network, address string, timeout time.Duration) (net.Conn, error) {
+		wg.Done()
+		select {
+		case <-allDialing:
+		case <-time.After(5 * time.Second):
+			return nil, errors.New("timeout")
+		}
+		client, server := net.Pipe()
+		server.Close()
+		return client, nil
+	})
+	var apps []*dbmodel.App
+	for i := 1; i <= daemonCount; i++ {
+		app := createLeaseDatabaseTestApp(t, `{"type": "postgresql", "name": "kea", "host": "db.example.org"}`)
+		app.ID = int64(i)
+		app.Daemons[0].ID = int64(i)
+		apps = append(apps, app)
+	}
+
+	// Act
+	events := prober.Probe(apps)
+
+	// Assert
+	require.Empty(t, events)
+	require.Len(t, prober.states, daemonCount)
+}
+
+// Test that the states of the daemons no longer monitored are discarded.
+func TestProbeLeaseDatabaseDeletedDaemon(t *testing.T) {
+	// Arrange
+	dialer := &fakeLeaseDatabaseDialer{
+		unreachable: map[string]bool{"db.example.org:5432": true},
+	}
+	prober := NewLeaseDatabaseProber(dialer.dial)
+	app := createLeaseDatabaseTestApp(t, `{"type": "postgresql", "name": "kea", "host": "db.example.org"}`)
+	require.Len(t, prober.Probe([]*dbmodel.App{app}), 1)
+	require.Len(t, prober.states, 1)
+
+	// Act
+	events := prober.Probe([]*dbmodel.App{})
+
+	// Assert
+	require.Empty(t, events)
+	require.Empty(t, prober.states)
+}
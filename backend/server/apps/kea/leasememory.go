@@ -0,0 +1,87 @@
+package kea
+
+import (
+	"math/big"
+
+	dbmodel "isc.org/stork/server/database/model"
+	storkutil "isc.org/stork/util"
+)
+
+// Approximate memory used by a single lease held by the Kea memfile lease
+// backend in bytes. It includes the lease structure, the client identifiers
+// and the entries in the lease indexes. The actual footprint depends on the
+// Kea version and the lengths of the client identifiers, so the values can
+// be adjusted to match the memory usage observed in a deployment.
+type LeaseMemoryFootprint struct {
+	Lease4  uint64 // DHCPv4 lease
+	LeaseNA uint64 // DHCPv6 address lease
+	LeasePD uint64 // DHCPv6 delegated prefix lease
+}
+
+// The per-lease memory footprint used in the estimates by default.
+var DefaultLeaseMemoryFootprint = LeaseMemoryFootprint{
+	Lease4:  400,
+	LeaseNA: 600,
+	LeasePD: 600,
+}
+
+// The estimated memory used by the leases of a daemon. The lease counts
+// are the sums of the assigned lease statistics of the daemon's subnets.
+type LeaseMemoryEstimate struct {
+	Leases4  *big.Int
+	LeasesNA *big.Int
+	LeasesPD *big.Int
+	Bytes    *big.Int
+}
+
+// Adds the value of the statistic of the local subnet to the counter. The
+// missing and negative statistics are skipped.
+func addLocalSubnetStat(counter *storkutil.BigCounter, localSubnet *dbmodel.LocalSubnet, statName string) {
+	switch value := localSubnet.Stats[statName].(type) {
+	case uint64:
+		counter.AddUint64(value)
+	case *big.Int:
+		counter.AddBigInt(value)
+	}
+}
+
+// Estimates the memory used by the leases of the daemon in the memfile
+// lease backend. The subnets are typically fetched with the
+// GetSubnetsByDaemonID function. The estimate is based on the
+// assigned-addresses, assigned-nas and assigned-pds statistics of the
+// daemon's local subnets multiplied by the per-lease footprint. The local
+// subnets belonging to other daemons are skipped. The estimate doesn't
+// include the memory used by the expired-reclaimed leases.
+func EstimateLeaseMemory(daemonID int64, subnets []dbmodel.Subnet, footprint LeaseMemoryFootprint) *LeaseMemoryEstimate {
+	leases4 := storkutil.NewBigCounter(0)
+	leasesNA := storkutil.NewBigCounter(0)
+	leasesPD := storkutil.NewBigCounter(0)
+	for _, subnet := range subnets {
+		for _, localSubnet := range subnet.LocalSubnets {
+			if localSubnet.DaemonID != daemonID {
+				continue
+			}
+			addLocalSubnetStat(leases4, localSubnet, "assigned-addresses")
+			addLocalSubnetStat(leasesNA, localSubnet, "assigned-nas")
+			addLocalSubnetStat(leasesPD, localSubnet, "assigned-pds")
+		}
+	}
+
+	estimate := &LeaseMemoryEstimate{
+		Leases4:  leases4.ToBigInt(),
+		LeasesNA: leasesNA.ToBigInt(),
+		LeasesPD: leasesPD.ToBigInt(),
+		Bytes:    big.NewInt(0),
+	}
+	for _, term := range []struct {
+		count *big.Int
+		size  uint64
+	}{
+		{estimate.Leases4, footprint.Lease4},
+		{estimate.LeasesNA, footprint.LeaseNA},
+		{estimate.LeasesPD, footprint.LeasePD},
+	} {
+		estimate.Bytes.Add(estimate.Bytes, new(big.Int).Mul(term.count, new(big.Int).SetUint64(term.size)))
+	}
+	return estimate
+}
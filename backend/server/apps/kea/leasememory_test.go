@@ -0,0 +1,131 @@
+package kea
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Test that the lease memory of a DHCPv4 daemon is estimated from the
+// assigned addresses in its subnets.
+func TestEstimateLeaseMemoryIPv4(t *testing.T) {
+	// Arrange
+	subnets := []dbmodel.Subnet{
+		{
+			Prefix: "192.0.2.0/24",
+			LocalSubnets: []*dbmodel.LocalSubnet{
+				{
+					DaemonID: 1,
+					Stats: dbmodel.SubnetStats{
+						"assigned-addresses": uint64(100),
+						"total-addresses":    uint64(256),
+					},
+				},
+				// The local subnet belonging to another daemon.
+				{
+					DaemonID: 2,
+					Stats: dbmodel.SubnetStats{
+						"assigned-addresses": uint64(1000),
+					},
+				},
+			},
+		},
+		{
+			Prefix: "192.0.3.0/24",
+			LocalSubnets: []*dbmodel.LocalSubnet{
+				{
+					DaemonID: 1,
+					Stats: dbmodel.SubnetStats{
+						"assigned-addresses": uint64(50),
+					},
+				},
+			},
+		},
+		// The statistics haven't been pulled yet.
+		{
+			Prefix: "192.0.4.0/24",
+			LocalSubnets: []*dbmodel.LocalSubnet{
+				{
+					DaemonID: 1,
+				},
+			},
+		},
+	}
+
+	// Act
+	estimate := EstimateLeaseMemory(1, subnets, DefaultLeaseMemoryFootprint)
+
+	// Assert
+	require.EqualValues(t, 150, estimate.Leases4.Int64())
+	require.Zero(t, estimate.LeasesNA.Sign())
+	require.Zero(t, estimate.LeasesPD.Sign())
+	require.EqualValues(t, 150*400, estimate.Bytes.Int64())
+}
+
+// Test that the lease memory of a DHCPv6 daemon is estimated from the
+// assigned addresses and delegated prefixes using the custom footprint.
+func TestEstimateLeaseMemoryIPv6(t *testing.T) {
+	// Arrange
+	hugeCount, ok := new(big.Int).SetString("36893488147419103232", 10)
+	require.True(t, ok)
+	subnets := []dbmodel.Subnet{
+		{
+			Prefix: "2001:db8:1::/64",
+			LocalSubnets: []*dbmodel.LocalSubnet{
+				{
+					DaemonID: 1,
+					Stats: dbmodel.SubnetStats{
+						"assigned-nas": uint64(1000),
+						"assigned-pds": uint64(10),
+					},
+				},
+			},
+		},
+		{
+			Prefix: "2001:db8:2::/64",
+			LocalSubnets: []*dbmodel.LocalSubnet{
+				{
+					DaemonID: 1,
+					Stats: dbmodel.SubnetStats{
+						"assigned-nas": hugeCount,
+						// The negative statistics are skipped.
+						"assigned-pds": big.NewInt(-5),
+					},
+				},
+			},
+		},
+	}
+	footprint := LeaseMemoryFootprint{
+		Lease4:  1,
+		LeaseNA: 500,
+		LeasePD: 800,
+	}
+
+	// Act
+	estimate := EstimateLeaseMemory(1, subnets, footprint)
+
+	// Assert
+	expectedNAs := new(big.Int).Add(hugeCount, big.NewInt(1000))
+	require.Zero(t, estimate.Leases4.Sign())
+	require.Zero(t, expectedNAs.Cmp(estimate.LeasesNA))
+	require.EqualValues(t, 10, estimate.LeasesPD.Int64())
+
+	expectedBytes := new(big.Int).Mul(expectedNAs, big.NewInt(500))
+	expectedBytes.Add(expectedBytes, big.NewInt(10*800))
+	require.Zero(t, expectedBytes.Cmp(estimate.Bytes))
+}
+
+// Test that the estimate is zero when the daemon has no subnets.
+func TestEstimateLeaseMemoryNoSubnets(t *testing.T) {
+	// Act
+	estimate := EstimateLeaseMemory(1, nil, DefaultLeaseMemoryFootprint)
+
+	// Assert
+	require.NotNil(t, estimate)
+	require.Zero(t, estimate.Leases4.Sign())
+	require.Zero(t, estimate.LeasesNA.Sign())
+	require.Zero(t, estimate.LeasesPD.Sign())
+	require.Zero(t, estimate.Bytes.Sign())
+}
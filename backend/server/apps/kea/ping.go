@@ -0,0 +1,124 @@
+package kea
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	keactrl "isc.org/stork/appctrl/kea"
+	"isc.org/stork/server/agentcomm"
+	dbmodel "isc.org/stork/server/database/model"
+	storkutil "isc.org/stork/util"
+)
+
+// Holds the result of pinging the control access point of a Kea app.
+type AccessPointPingResult struct {
+	App     *dbmodel.App
+	Address string
+	Port    int64
+	// Round-trip time of the version-get command. It is only meaningful
+	// when the access point is reachable.
+	Latency time.Duration
+	// Indicates if the access point responded to the version-get command.
+	Reachable bool
+	// Error returned when the access point is unreachable.
+	Error error
+}
+
+// Maximum time to wait for the response to the version-get command sent
+// to a single access point.
+const accessPointPingTimeout = 5 * time.Second
+
+// Outcome of the version-get command sent to an access point.
+type pingResponse struct {
+	cmdsResult     *agentcomm.KeaCmdsResult
+	versionGetResp []VersionGetResponse
+	latency        time.Duration
+	err            error
+}
+
+// Sends the version-get command to the control access point of the
+// specified app and measures the time it takes to receive the response
+// using the specified clock. The access point is considered unreachable
+// if it doesn't respond within the timeout.
+func pingAccessPoint(ctx context.Context, agents agentcomm.ConnectedAgents, app *dbmodel.App, clock storkutil.Clock, timeout time.Duration) (result AccessPointPingResult) {
+	result.App = app
+	address, port, _, _, err := app.GetControlAccessPoint()
+	if err != nil {
+		result.Error = err
+		return
+	}
+	result.Address = address
+	result.Port = port
+
+	cmds := []keactrl.SerializableCommand{
+		keactrl.NewCommand("version-get", nil, nil),
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// The response is awaited in a separate goroutine, so the ping is
+	// abandoned when the timeout elapses even if the agent doesn't respond.
+	responseChan := make(chan pingResponse, 1)
+	go func() {
+		var response pingResponse
+		startTime := clock.Now()
+		response.cmdsResult, response.err = agents.ForwardToKeaOverHTTP(ctx, app, cmds, &response.versionGetResp)
+		response.latency = clock.Now().Sub(startTime)
+		responseChan <- response
+	}()
+
+	var response pingResponse
+	select {
+	case response = <-responseChan:
+	case <-ctx.Done():
+		response.err = errors.Errorf("no response to version-get from %s:%d within %s", address, port, timeout)
+	}
+
+	switch {
+	case response.err != nil:
+		result.Error = response.err
+	case response.cmdsResult.Error != nil:
+		result.Error = response.cmdsResult.Error
+	case len(response.cmdsResult.CmdsErrors) > 0 && response.cmdsResult.CmdsErrors[0] != nil:
+		result.Error = response.cmdsResult.CmdsErrors[0]
+	case len(response.versionGetResp) == 0:
+		result.Error = errors.Errorf("empty response to version-get from %s:%d", address, port)
+	default:
+		// Any response from Kea, including an error result, proves
+		// that the access point is reachable.
+		result.Latency = response.latency
+		result.Reachable = true
+	}
+	if result.Error != nil {
+		result.Error = errors.WithMessagef(result.Error, "failed to ping Kea app %s", app.GetName())
+	}
+	return
+}
+
+// Sends the version-get command to the control access points of the
+// specified Kea apps and measures the round-trip latencies using the
+// specified clock. It returns the results sorted by latency in ascending
+// order. The unreachable access points are reported at the end of the list.
+// The access points are pinged one by one because the requests to the
+// agents are serialized in the agents communication loop and the latencies
+// of the concurrent pings would include the time spent in the queue. The
+// timeout of each ping bounds the total duration instead.
+func PingAccessPoints(ctx context.Context, agents agentcomm.ConnectedAgents, apps []dbmodel.App, clock storkutil.Clock) []AccessPointPingResult {
+	results := []AccessPointPingResult{}
+	for i := range apps {
+		if apps[i].Type != dbmodel.AppTypeKea {
+			continue
+		}
+		results = append(results, pingAccessPoint(ctx, agents, &apps[i], clock, accessPointPingTimeout))
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Reachable != results[j].Reachable {
+			return results[i].Reachable
+		}
+		return results[i].Latency < results[j].Latency
+	})
+	return results
+}
@@ -0,0 +1,135 @@
+package kea
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	keactrl "isc.org/stork/appctrl/kea"
+	agentcommtest "isc.org/stork/server/agentcomm/test"
+	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/testutil"
+)
+
+// Returns a function generating a successful response to the version-get
+// command after moving the clock forward by the specified latency.
+func mockVersionGetWithLatency(clock *testutil.FakeClock, latency time.Duration) func(int, []interface{}) {
+	return func(callNo int, cmdResponses []interface{}) {
+		clock.Advance(latency)
+		list := cmdResponses[0].(*[]VersionGetResponse)
+		*list = []VersionGetResponse{
+			{
+				ResponseHeader: keactrl.ResponseHeader{
+					Result: 0,
+					Text:   "2.2.0",
+					Daemon: "ca",
+				},
+			},
+		}
+	}
+}
+
+// Returns a Kea app with the control access point on the specified address.
+func createPingedApp(id int64, address string) dbmodel.App {
+	return dbmodel.App{
+		ID:           id,
+		Type:         dbmodel.AppTypeKea,
+		Name:         address,
+		AccessPoints: dbmodel.AppendAccessPoint(nil, dbmodel.AccessPointControl, address, "", 8000, false),
+	}
+}
+
+// Test that the access points are pinged and the results are sorted by
+// latency with the unreachable access points at the end.
+func TestPingAccessPoints(t *testing.T) {
+	clock := testutil.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	fa := agentcommtest.NewKeaFakeAgents(
+		mockVersionGetWithLatency(clock, 60*time.Millisecond),
+		mockVersionGetWithLatency(clock, time.Millisecond),
+		// Empty response.
+		func(callNo int, cmdResponses []interface{}) {},
+		mockVersionGetWithLatency(clock, 30*time.Millisecond),
+	)
+
+	apps := []dbmodel.App{
+		createPingedApp(1, "192.0.2.1"),
+		createPingedApp(2, "192.0.2.2"),
+		createPingedApp(3, "192.0.2.3"),
+		createPingedApp(4, "192.0.2.4"),
+		// No control access point.
+		{
+			ID:   5,
+			Type: dbmodel.AppTypeKea,
+		},
+		// Non-Kea apps are ignored.
+		{
+			ID:           6,
+			Type:         dbmodel.AppTypeBind9,
+			AccessPoints: dbmodel.AppendAccessPoint(nil, dbmodel.AccessPointControl, "192.0.2.6", "", 953, false),
+		},
+	}
+
+	results := PingAccessPoints(context.Background(), fa, apps, clock)
+	require.Len(t, results, 5)
+
+	// The access point without the control access point is not pinged.
+	require.Len(t, fa.RecordedCommands, 4)
+	for _, command := range fa.RecordedCommands {
+		require.Equal(t, "version-get", command.(*keactrl.Command).Command)
+	}
+
+	// Reachable access points sorted by latency.
+	require.EqualValues(t, 2, results[0].App.ID)
+	require.EqualValues(t, 4, results[1].App.ID)
+	require.EqualValues(t, 1, results[2].App.ID)
+	for i := 0; i < 3; i++ {
+		require.True(t, results[i].Reachable)
+		require.NoError(t, results[i].Error)
+		require.EqualValues(t, 8000, results[i].Port)
+	}
+	require.Equal(t, "192.0.2.2", results[0].Address)
+	require.Equal(t, time.Millisecond, results[0].Latency)
+	require.Equal(t, 30*time.Millisecond, results[1].Latency)
+	require.Equal(t, 60*time.Millisecond, results[2].Latency)
+
+	// Unreachable access points.
+	require.EqualValues(t, 3, results[3].App.ID)
+	require.False(t, results[3].Reachable)
+	require.ErrorContains(t, results[3].Error, "empty response")
+	require.Zero(t, results[3].Latency)
+
+	require.EqualValues(t, 5, results[4].App.ID)
+	require.False(t, results[4].Reachable)
+	require.ErrorContains(t, results[4].Error, "no access point")
+	require.Zero(t, results[4].Latency)
+}
+
+// Test that pinging an empty list of apps returns an empty report.
+func TestPingAccessPointsNoApps(t *testing.T) {
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	results := PingAccessPoints(context.Background(), fa, nil, testutil.NewFakeClock(time.Time{}))
+	require.NotNil(t, results)
+	require.Empty(t, results)
+	require.Empty(t, fa.RecordedCommands)
+}
+
+// Test that the access point is reported as unreachable when it doesn't
+// respond within the timeout.
+func TestPingAccessPointTimeout(t *testing.T) {
+	// Arrange
+	release := make(chan struct{})
+	defer close(release)
+	fa := agentcommtest.NewKeaFakeAgents(func(callNo int, cmdResponses []interface{}) {
+		<-release
+	})
+	app := createPingedApp(1, "192.0.2.1")
+
+	// Act
+	result := pingAccessPoint(context.Background(), fa, &app, testutil.NewFakeClock(time.Time{}), 10*time.Millisecond)
+
+	// Assert
+	require.False(t, result.Reachable)
+	require.Zero(t, result.Latency)
+	require.ErrorContains(t, result.Error, "no response to version-get from 192.0.2.1:8000 within 10ms")
+}
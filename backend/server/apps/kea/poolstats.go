@@ -0,0 +1,231 @@
+package kea
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+	keactrl "isc.org/stork/appctrl/kea"
+	dbmodel "isc.org/stork/server/database/model"
+	storkutil "isc.org/stork/util"
+)
+
+// Names of the statistics holding the ID and the utilization of the most
+// utilized address pool in a subnet.
+const (
+	mostUtilizedPoolIDStat          = "most-utilized-pool-id"
+	mostUtilizedPoolUtilizationStat = "most-utilized-pool-utilization"
+)
+
+// Matches the pool prefix of the pool-level statistic names, e.g., pool[0].
+// in pool[0].assigned-addresses.
+var poolStatPrefixPattern = regexp.MustCompile(`^(?:pd-)?pool\[\d+\]\.`)
+
+// Matches the pool-indexed columns of the stat-lease4-get and stat-lease6-get
+// result sets, e.g., pool0-total-addresses or pd-pool1-assigned-pds.
+var poolColumnPattern = regexp.MustCompile(`^(pd-)?pool(\d+)-(.+)$`)
+
+// Converts the name of the pool-indexed result set column to the name of
+// the pool-level statistic, e.g., pool0-total-addresses to
+// pool[0].total-addresses. It returns false if the column is not
+// pool-indexed.
+func getPoolStatNameFromColumn(column string) (string, bool) {
+	match := poolColumnPattern.FindStringSubmatch(column)
+	if match == nil {
+		return "", false
+	}
+	return fmt.Sprintf("%spool[%s].%s", match[1], match[2], match[3]), true
+}
+
+// Represents unmarshaled response from Kea daemon to the statistic-get-all
+// command. Each statistic holds a list of samples. The sample is a pair of
+// the value and the timestamp. The most recent sample comes first.
+type StatisticGetAllResponse struct {
+	keactrl.ResponseHeader
+	Arguments map[string][][]json.RawMessage `json:"arguments,omitempty"`
+}
+
+// Appends the statistic-get-all command to the given command list. It
+// returns an instance of the expected response type.
+func poolStatsAddCmd(cmds *[]*keactrl.Command, daemons []string) interface{} {
+	*cmds = append(*cmds, &keactrl.Command{
+		Command: "statistic-get-all",
+		Daemons: daemons,
+	})
+	return &[]StatisticGetAllResponse{}
+}
+
+// Extracts the pool-level lease statistics from the statistic-get-all
+// response. It returns the statistics by local subnet ID. The statistic
+// names are stripped from the subnet part, e.g., pool[0].assigned-addresses.
+// It returns nil statistics without an error if the command is not
+// supported by the daemon.
+func parsePoolStats(response interface{}) (map[int64]dbmodel.SubnetStats, error) {
+	statsResp, ok := response.(*[]StatisticGetAllResponse)
+	if !ok || len(*statsResp) == 0 {
+		return nil, errors.Errorf("response is empty: %+v", response)
+	}
+	sr := (*statsResp)[0]
+	if sr.Result != keactrl.ResponseSuccess {
+		return nil, nil
+	}
+
+	// Matches the pool-level statistics, e.g., subnet[1].pool[0].assigned-addresses.
+	pattern := regexp.MustCompile(`^subnet\[(\d+)\]\.((?:pd-)?pool\[\d+\]\.(?:total|assigned)-(?:addresses|nas|pds))$`)
+
+	stats := make(map[int64]dbmodel.SubnetStats)
+	for name, samples := range sr.Arguments {
+		match := pattern.FindStringSubmatch(name)
+		if match == nil || len(samples) == 0 || len(samples[0]) == 0 {
+			continue
+		}
+		localSubnetID, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		value, ok := new(big.Int).SetString(string(samples[0][0]), 10)
+		if !ok || value.Sign() < 0 {
+			return nil, errors.Errorf("invalid value of the %s statistic: %s", name, string(samples[0][0]))
+		}
+		if stats[localSubnetID] == nil {
+			stats[localSubnetID] = dbmodel.SubnetStats{}
+		}
+		if value.IsUint64() {
+			stats[localSubnetID][match[2]] = value.Uint64()
+		} else {
+			stats[localSubnetID][match[2]] = value
+		}
+	}
+	return stats, nil
+}
+
+// Utilization of a single address pool computed from the pool-level
+// statistics.
+type poolUtilization struct {
+	poolID      int64
+	utilization float64
+}
+
+// Returns the most utilized address pool in the subnet. The utilization of
+// each pool is computed from the pool-level statistics of the local subnets,
+// excluding the statistics from the excluded daemons. It returns nil if the
+// pool-level statistics are not available.
+func getMostUtilizedPool(subnet *dbmodel.Subnet, excludedDaemons map[int64]bool) *poolUtilization {
+	// Matches the pool-level total addresses, e.g., pool[0].total-addresses.
+	pattern := regexp.MustCompile(`^pool\[(\d+)\]\.total-(addresses|nas)$`)
+
+	// Collect the pool IDs and the statistic names of their total addresses.
+	poolIDs := make(map[int64]string)
+	for _, localSubnet := range subnet.LocalSubnets {
+		if excludedDaemons[localSubnet.DaemonID] {
+			continue
+		}
+		for name := range localSubnet.Stats {
+			match := pattern.FindStringSubmatch(name)
+			if match == nil {
+				continue
+			}
+			poolID, err := strconv.ParseInt(match[1], 10, 64)
+			if err != nil {
+				continue
+			}
+			poolIDs[poolID] = match[2]
+		}
+	}
+
+	var mostUtilized *poolUtilization
+	for poolID, statSuffix := range poolIDs {
+		prefix := fmt.Sprintf("pool[%d].", poolID)
+		total := sumStatLocalSubnetsIPv6(subnet, prefix+"total-"+statSuffix, excludedDaemons)
+		assigned := sumStatLocalSubnetsIPv6(subnet, prefix+"assigned-"+statSuffix, excludedDaemons)
+		utilization := assigned.DivideSafeBy(total)
+		if mostUtilized == nil || utilization > mostUtilized.utilization ||
+			(utilization == mostUtilized.utilization && poolID < mostUtilized.poolID) {
+			mostUtilized = &poolUtilization{
+				poolID:      poolID,
+				utilization: utilization,
+			}
+		}
+	}
+	return mostUtilized
+}
+
+// Subnet statistics extended with the most utilized address pool. If the
+// pool-level statistics are not available, the subnet address utilization
+// is used instead.
+type subnetStatsWithPool struct {
+	subnetStats
+	mostUtilizedPool *poolUtilization
+}
+
+// Returns the subnet statistics including the ID and the utilization of
+// the most utilized pool. The pool ID is omitted if the pool-level
+// statistics are not available.
+func (s *subnetStatsWithPool) GetStatistics() dbmodel.SubnetStats {
+	stats := s.subnetStats.GetStatistics()
+	if s.mostUtilizedPool == nil {
+		stats[mostUtilizedPoolUtilizationStat] = s.subnetStats.GetAddressUtilization()
+		return stats
+	}
+	stats[mostUtilizedPoolIDStat] = uint64(s.mostUtilizedPool.poolID)
+	stats[mostUtilizedPoolUtilizationStat] = s.mostUtilizedPool.utilization
+	return stats
+}
+
+// Returns the delegated prefix utilization of the local subnet by the
+// delegated prefix length. The pool index in the pool-level statistic
+// names, e.g., pd-pool[0].assigned-pds, is the position of the pool in the
+// subnet. The capacity of the pool is its total-pds statistic or, if it is
+// not available, the number of the prefixes of the delegated length in the
+// pool prefix. The assigned prefixes are summed from the pool-level
+// assigned-pds statistics. If they are not available and all pools delegate
+// the prefixes of the same length, the subnet-level assigned-pds statistic
+// is used. Otherwise, the utilization of the delegated length is unknown
+// and it is not returned.
+func getPdUtilizationByDelegatedLen(prefixPools []dbmodel.PrefixPool, stats dbmodel.SubnetStats) map[int]float64 {
+	type delegatedLenCounters struct {
+		total    *storkutil.BigCounter
+		assigned *storkutil.BigCounter
+		// Indicates that all pools have the assigned-pds statistics.
+		assignedKnown bool
+	}
+	counters := make(map[int]*delegatedLenCounters)
+	for i, pool := range prefixPools {
+		c, ok := counters[pool.DelegatedLen]
+		if !ok {
+			c = &delegatedLenCounters{
+				total:         storkutil.NewBigCounter(0),
+				assigned:      storkutil.NewBigCounter(0),
+				assignedKnown: true,
+			}
+			counters[pool.DelegatedLen] = c
+		}
+		prefix := fmt.Sprintf("pd-pool[%d].", i)
+		if !stats.AddToCounter(c.total, prefix+"total-pds") {
+			parsed := storkutil.ParseIP(pool.Prefix)
+			if parsed == nil {
+				continue
+			}
+			c.total.AddBigInt(storkutil.CalculateDelegatedPrefixRangeSize(parsed.PrefixLength, pool.DelegatedLen))
+		}
+		c.assignedKnown = stats.AddToCounter(c.assigned, prefix+"assigned-pds") && c.assignedKnown
+	}
+
+	utilizations := make(map[int]float64)
+	for delegatedLen, c := range counters {
+		if !c.assignedKnown {
+			if len(counters) > 1 {
+				continue
+			}
+			c.assigned = storkutil.NewBigCounter(0)
+			if !stats.AddToCounter(c.assigned, "assigned-pds") {
+				continue
+			}
+		}
+		utilizations[delegatedLen] = c.assigned.DivideSafeBy(c.total)
+	}
+	return utilizations
+}
@@ -0,0 +1,280 @@
+package kea
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	keactrl "isc.org/stork/appctrl/kea"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Unmarshals the JSON response to the statistic-get-all command.
+func unmarshalStatisticGetAllResponse(t *testing.T, response string) *[]StatisticGetAllResponse {
+	cmd := keactrl.NewCommand("statistic-get-all", []string{"dhcp4"}, nil)
+	parsed := &[]StatisticGetAllResponse{}
+	err := keactrl.UnmarshalResponseList(cmd, []byte(response), parsed)
+	require.NoError(t, err)
+	return parsed
+}
+
+// Test that the pool-level statistics are extracted from the
+// statistic-get-all response.
+func TestParsePoolStats(t *testing.T) {
+	// Arrange
+	response := unmarshalStatisticGetAllResponse(t, `[{
+		"result": 0,
+		"arguments": {
+			"pkt4-received": [ [ 10, "2023-01-01 10:00:00.000000" ] ],
+			"subnet[1].total-addresses": [ [ 256, "2023-01-01 10:00:00.000000" ] ],
+			"subnet[1].pool[0].total-addresses": [ [ 100, "2023-01-01 10:00:00.000000" ] ],
+			"subnet[1].pool[0].assigned-addresses": [ [ 50, "2023-01-01 10:00:00.000000" ], [ 40, "2023-01-01 09:00:00.000000" ] ],
+			"subnet[1].pool[1].total-addresses": [ [ 156, "2023-01-01 10:00:00.000000" ] ],
+			"subnet[2].pool[0].total-nas": [ [ 36893488147419103232, "2023-01-01 10:00:00.000000" ] ],
+			"subnet[2].pd-pool[0].assigned-pds": [ [ 3, "2023-01-01 10:00:00.000000" ] ]
+		}
+	}]`)
+
+	// Act
+	stats, err := parsePoolStats(response)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	require.Len(t, stats[1], 3)
+	require.EqualValues(t, 100, stats[1]["pool[0].total-addresses"])
+	require.EqualValues(t, 50, stats[1]["pool[0].assigned-addresses"])
+	require.EqualValues(t, 156, stats[1]["pool[1].total-addresses"])
+	require.Len(t, stats[2], 2)
+	require.Equal(t, "36893488147419103232", stats[2]["pool[0].total-nas"].(fmt.Stringer).String())
+	require.EqualValues(t, 3, stats[2]["pd-pool[0].assigned-pds"])
+}
+
+// Test that the pool-indexed result set columns are converted to the names
+// of the pool-level statistics.
+func TestGetPoolStatNameFromColumn(t *testing.T) {
+	for column, expected := range map[string]string{
+		"pool0-total-addresses":     "pool[0].total-addresses",
+		"pool12-assigned-nas":       "pool[12].assigned-nas",
+		"pd-pool1-assigned-pds":     "pd-pool[1].assigned-pds",
+		"pool3-cumulative-assigned": "pool[3].cumulative-assigned",
+	} {
+		t.Run(column, func(t *testing.T) {
+			name, ok := getPoolStatNameFromColumn(column)
+			require.True(t, ok)
+			require.Equal(t, expected, name)
+		})
+	}
+	for _, column := range []string{"subnet-id", "total-addresses", "pool-total-addresses", "poolx-total-addresses"} {
+		t.Run(column, func(t *testing.T) {
+			name, ok := getPoolStatNameFromColumn(column)
+			require.False(t, ok)
+			require.Empty(t, name)
+		})
+	}
+}
+
+// Test that no stats and no error are returned when the daemon doesn't
+// support the statistic-get-all command.
+func TestParsePoolStatsUnsupported(t *testing.T) {
+	// Arrange
+	response := unmarshalStatisticGetAllResponse(t, `[{
+		"result": 2,
+		"text": "'statistic-get-all' command not supported."
+	}]`)
+
+	// Act
+	stats, err := parsePoolStats(response)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, stats)
+}
+
+// Test that parsing the malformed pool-level statistics fails.
+func TestParsePoolStatsInvalid(t *testing.T) {
+	response := unmarshalStatisticGetAllResponse(t, `[{
+		"result": 0,
+		"arguments": {
+			"subnet[1].pool[0].total-addresses": [ [ "foo", "2023-01-01 10:00:00.000000" ] ]
+		}
+	}]`)
+	stats, err := parsePoolStats(response)
+	require.Error(t, err)
+	require.Nil(t, stats)
+
+	_, err = parsePoolStats(&[]StatisticGetAllResponse{})
+	require.Error(t, err)
+}
+
+// Test that the most utilized pool is found from the pool-level statistics
+// of the local subnets, excluding the passive HA servers.
+func TestGetMostUtilizedPool(t *testing.T) {
+	// Arrange
+	subnet := &dbmodel.Subnet{
+		LocalSubnets: []*dbmodel.LocalSubnet{
+			{
+				DaemonID: 1,
+				Stats: dbmodel.SubnetStats{
+					"total-addresses":            uint64(300),
+					"pool[0].total-addresses":    uint64(100),
+					"pool[0].assigned-addresses": uint64(50),
+					"pool[1].total-addresses":    uint64(200),
+					"pool[1].assigned-addresses": uint64(20),
+				},
+			},
+			{
+				DaemonID: 2,
+				Stats: dbmodel.SubnetStats{
+					"pool[1].total-addresses":    uint64(200),
+					"pool[1].assigned-addresses": uint64(190),
+				},
+			},
+		},
+	}
+
+	// Act
+	pool := getMostUtilizedPool(subnet, map[int64]bool{})
+	poolExcluded := getMostUtilizedPool(subnet, map[int64]bool{2: true})
+
+	// Assert
+	require.NotNil(t, pool)
+	require.EqualValues(t, 1, pool.poolID)
+	require.InDelta(t, 210.0/400.0, pool.utilization, 0.001)
+
+	require.NotNil(t, poolExcluded)
+	require.EqualValues(t, 0, poolExcluded.poolID)
+	require.InDelta(t, 0.5, poolExcluded.utilization, 0.001)
+}
+
+// Test that no pool is returned when the pool-level statistics are not
+// available.
+func TestGetMostUtilizedPoolNoStats(t *testing.T) {
+	// Arrange
+	subnet := &dbmodel.Subnet{
+		LocalSubnets: []*dbmodel.LocalSubnet{
+			{
+				DaemonID: 1,
+				Stats: dbmodel.SubnetStats{
+					"total-addresses":    uint64(300),
+					"assigned-addresses": uint64(30),
+				},
+			},
+		},
+	}
+
+	// Act
+	pool := getMostUtilizedPool(subnet, map[int64]bool{})
+
+	// Assert
+	require.Nil(t, pool)
+}
+
+// Test that the subnet statistics include the most utilized pool or fall
+// back to the subnet utilization without the pool ID.
+func TestSubnetStatsWithPoolGetStatistics(t *testing.T) {
+	// Arrange
+	subnet := &subnetIPv4Stats{
+		totalAddresses:         100,
+		totalAssignedAddresses: 25,
+	}
+	withPool := &subnetStatsWithPool{
+		subnetStats:      subnet,
+		mostUtilizedPool: &poolUtilization{poolID: 3, utilization: 0.75},
+	}
+	withoutPool := &subnetStatsWithPool{
+		subnetStats: subnet,
+	}
+
+	// Act
+	statsWithPool := withPool.GetStatistics()
+	statsWithoutPool := withoutPool.GetStatistics()
+
+	// Assert
+	require.EqualValues(t, 100, statsWithPool["total-addresses"])
+	require.EqualValues(t, 3, statsWithPool[mostUtilizedPoolIDStat])
+	require.EqualValues(t, 0.75, statsWithPool[mostUtilizedPoolUtilizationStat])
+
+	require.NotContains(t, statsWithoutPool, mostUtilizedPoolIDStat)
+	require.EqualValues(t, 0.25, statsWithoutPool[mostUtilizedPoolUtilizationStat])
+}
+
+// Test that the delegated prefix utilization is computed by the delegated
+// prefix length from the pool-level statistics.
+func TestGetPdUtilizationByDelegatedLen(t *testing.T) {
+	// Arrange
+	prefixPools := []dbmodel.PrefixPool{
+		{Prefix: "3000::/48", DelegatedLen: 64},
+		{Prefix: "3001::/48", DelegatedLen: 56},
+		{Prefix: "3002::/48", DelegatedLen: 64},
+	}
+	stats := dbmodel.SubnetStats{
+		"assigned-pds":            uint64(1000),
+		"pd-pool[0].total-pds":    uint64(65536),
+		"pd-pool[0].assigned-pds": uint64(100),
+		"pd-pool[1].total-pds":    uint64(256),
+		"pd-pool[1].assigned-pds": uint64(128),
+		"pd-pool[2].total-pds":    uint64(65536),
+		"pd-pool[2].assigned-pds": uint64(300),
+	}
+
+	// Act
+	utilizations := getPdUtilizationByDelegatedLen(prefixPools, stats)
+
+	// Assert
+	require.Len(t, utilizations, 2)
+	require.InDelta(t, 400.0/131072.0, utilizations[64], 1e-9)
+	require.InDelta(t, 0.5, utilizations[56], 1e-9)
+}
+
+// Test that the capacity of the pools lacking the pool-level statistics is
+// computed from the prefix length and the delegated length, and the
+// subnet-level assigned prefixes are used when all pools delegate the
+// prefixes of the same length.
+func TestGetPdUtilizationByDelegatedLenNoPoolStats(t *testing.T) {
+	// Arrange
+	prefixPools := []dbmodel.PrefixPool{
+		{Prefix: "3000::/48", DelegatedLen: 56},
+		{Prefix: "3001::/52", DelegatedLen: 56},
+	}
+	stats := dbmodel.SubnetStats{
+		"assigned-pds": uint64(136),
+	}
+
+	// Act
+	utilizations := getPdUtilizationByDelegatedLen(prefixPools, stats)
+
+	// Assert
+	// The capacity is 256 + 16 prefixes.
+	require.Len(t, utilizations, 1)
+	require.InDelta(t, 0.5, utilizations[56], 1e-9)
+}
+
+// Test that the utilization of the delegated lengths is not returned when
+// the assigned prefixes cannot be attributed to the pools.
+func TestGetPdUtilizationByDelegatedLenUnknownAssigned(t *testing.T) {
+	// Arrange
+	prefixPools := []dbmodel.PrefixPool{
+		{Prefix: "3000::/48", DelegatedLen: 64},
+		{Prefix: "3001::/48", DelegatedLen: 56},
+	}
+	stats := dbmodel.SubnetStats{
+		"assigned-pds":            uint64(1000),
+		"pd-pool[1].assigned-pds": uint64(64),
+	}
+
+	// Act
+	utilizations := getPdUtilizationByDelegatedLen(prefixPools, stats)
+
+	// Assert
+	require.Len(t, utilizations, 1)
+	require.InDelta(t, 0.25, utilizations[56], 1e-9)
+	require.NotContains(t, utilizations, 64)
+}
+
+// Test that no utilization is returned for the subnets without the
+// delegated prefix pools.
+func TestGetPdUtilizationByDelegatedLenNoPools(t *testing.T) {
+	utilizations := getPdUtilizationByDelegatedLen(nil, dbmodel.SubnetStats{"assigned-pds": uint64(10)})
+	require.Empty(t, utilizations)
+}
@@ -0,0 +1,361 @@
+package kea
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-pg/pg/v10"
+	log "github.com/sirupsen/logrus"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Content type of the Prometheus text exposition format.
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Content type of the OpenMetrics text exposition format.
+const openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// Matches the characters not allowed in the metric names.
+var invalidMetricNameChars = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+
+// Matches the characters not allowed in the label names.
+var invalidLabelNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// Matches the names of the statistics exported as metrics, e.g.,
+// assigned-addresses. The pool-level statistics, e.g.,
+// pool[0].assigned-addresses, are not exported.
+var exportedStatPattern = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// A label of the Prometheus metric sample.
+type prometheusLabel struct {
+	name  string
+	value string
+}
+
+// A single sample of the Prometheus metric.
+type prometheusSample struct {
+	labels []prometheusLabel
+	value  string
+}
+
+// A Prometheus metric with its samples. The unit is empty for the
+// dimensionless metrics, e.g., the lease counts. Otherwise, the metric name
+// ends with the unit as required by OpenMetrics.
+type prometheusMetric struct {
+	help    string
+	unit    string
+	samples []prometheusSample
+}
+
+// A set of the Prometheus metrics rendered in the text format.
+type prometheusMetrics map[string]*prometheusMetric
+
+// Adds the sample of the dimensionless gauge metric. The metric is created
+// if it doesn't exist.
+func (metrics prometheusMetrics) add(name, help, value string, labels ...prometheusLabel) {
+	metrics.addWithUnit(name, "", help, value, labels...)
+}
+
+// Adds the sample of the gauge metric having the unit, e.g., seconds. The
+// metric is created if it doesn't exist. The invalid characters in the
+// metric name are replaced.
+func (metrics prometheusMetrics) addWithUnit(name, unit, help, value string, labels ...prometheusLabel) {
+	name = sanitizePrometheusMetricName(name)
+	metric, ok := metrics[name]
+	if !ok {
+		metric = &prometheusMetric{help: help, unit: unit}
+		metrics[name] = metric
+	}
+	metric.samples = append(metric.samples, prometheusSample{
+		labels: labels,
+		value:  value,
+	})
+}
+
+// Replaces the characters not allowed in the metric name with underscores.
+// The name starting with a digit is prefixed with an underscore.
+func sanitizePrometheusMetricName(name string) string {
+	name = invalidMetricNameChars.ReplaceAllString(name, "_")
+	if len(name) == 0 || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+// Replaces the characters not allowed in the label name with underscores.
+// The name starting with a digit is prefixed with an underscore.
+func sanitizePrometheusLabelName(name string) string {
+	name = invalidLabelNameChars.ReplaceAllString(name, "_")
+	if len(name) == 0 || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+// Escapes the label value according to the Prometheus text format.
+func escapePrometheusLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// Writes the metrics in the Prometheus text format. The metrics are
+// sorted by name.
+func (metrics prometheusMetrics) write(w io.Writer) error {
+	return metrics.writeFormat(w, false)
+}
+
+// Writes the metrics in the OpenMetrics text format. It differs from the
+// Prometheus text format by the units of the metrics and the terminating
+// EOF marker. The exemplars are not written because OpenMetrics allows them
+// only for the counters and histograms while all metrics are gauges.
+func (metrics prometheusMetrics) writeOpenMetrics(w io.Writer) error {
+	return metrics.writeFormat(w, true)
+}
+
+// Writes the metrics in the Prometheus or OpenMetrics text format. The
+// metrics are sorted by name.
+func (metrics prometheusMetrics) writeFormat(w io.Writer, openMetrics bool) error {
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	writer := bufio.NewWriter(w)
+	for _, name := range names {
+		metric := metrics[name]
+		fmt.Fprintf(writer, "# HELP %s %s\n", name, metric.help)
+		fmt.Fprintf(writer, "# TYPE %s gauge\n", name)
+		if openMetrics && metric.unit != "" {
+			fmt.Fprintf(writer, "# UNIT %s %s\n", name, metric.unit)
+		}
+		for _, sample := range metric.samples {
+			fmt.Fprint(writer, name)
+			if len(sample.labels) > 0 {
+				var labels []string
+				for _, label := range sample.labels {
+					labels = append(labels, fmt.Sprintf(`%s="%s"`, sanitizePrometheusLabelName(label.name),
+						escapePrometheusLabelValue(label.value)))
+				}
+				fmt.Fprintf(writer, "{%s}", strings.Join(labels, ","))
+			}
+			fmt.Fprintf(writer, " %s\n", sample.value)
+		}
+	}
+	if openMetrics {
+		fmt.Fprint(writer, "# EOF\n")
+	}
+	return writer.Flush()
+}
+
+// Converts the statistic value to the metric sample value. It returns
+// false if the value is not numeric.
+func formatPrometheusValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case uint64:
+		return strconv.FormatUint(v, 10), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case int:
+		return strconv.Itoa(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), true
+	case *big.Int:
+		if v == nil {
+			return "", false
+		}
+		return v.String(), true
+	default:
+		return "", false
+	}
+}
+
+// Converts the statistic name to the metric name suffix, e.g.,
+// assigned-addresses to assigned_addresses.
+func getPrometheusMetricSuffix(statName string) string {
+	return strings.ReplaceAll(statName, "-", "_")
+}
+
+// Returns the labels identifying the daemon and its app.
+func getPrometheusDaemonLabels(daemon *dbmodel.Daemon) []prometheusLabel {
+	labels := []prometheusLabel{}
+	if daemon.App != nil {
+		labels = append(labels,
+			prometheusLabel{"app_id", strconv.FormatInt(daemon.App.ID, 10)},
+			prometheusLabel{"app_name", daemon.App.Name},
+		)
+	} else {
+		labels = append(labels, prometheusLabel{"app_id", strconv.FormatInt(daemon.AppID, 10)})
+	}
+	return append(labels,
+		prometheusLabel{"daemon", daemon.Name},
+		prometheusLabel{"daemon_id", strconv.FormatInt(daemon.ID, 10)},
+	)
+}
+
+// Adds the metrics for the lease statistics of the local subnets of the
+// subnet. The local subnets are distinguished by the daemon and app
+// labels. The local subnets without the statistics are skipped.
+func addPrometheusSubnetMetrics(metrics prometheusMetrics, subnet *dbmodel.Subnet) {
+	for _, localSubnet := range subnet.LocalSubnets {
+		if localSubnet.Daemon == nil {
+			continue
+		}
+		labels := getPrometheusDaemonLabels(localSubnet.Daemon)
+		labels = append(labels,
+			prometheusLabel{"subnet_id", strconv.FormatInt(localSubnet.LocalSubnetID, 10)},
+			prometheusLabel{"prefix", subnet.Prefix},
+		)
+		for statName, statValue := range localSubnet.Stats {
+			if !exportedStatPattern.MatchString(statName) {
+				continue
+			}
+			value, ok := formatPrometheusValue(statValue)
+			if !ok {
+				continue
+			}
+			metrics.add("kea_subnet_"+getPrometheusMetricSuffix(statName),
+				fmt.Sprintf("Kea subnet statistic %s.", statName), value, labels...)
+		}
+	}
+}
+
+// Adds the metrics for the responses sent by the DHCP daemon and its
+// uptime. The last sampled number of the sent responses is taken from the
+// RPS values recorded by the stats puller. The RPS over the short and long
+// intervals are taken from the daemon statistics stored in the database.
+func addPrometheusDaemonMetrics(metrics prometheusMetrics, daemon *dbmodel.Daemon, previousRps map[int64]StatSample) {
+	labels := getPrometheusDaemonLabels(daemon)
+	metrics.addWithUnit("kea_daemon_uptime_seconds", "seconds",
+		"Time elapsed since the Kea daemon start.",
+		strconv.FormatInt(daemon.Uptime, 10), labels...)
+	if sample, ok := previousRps[daemon.ID]; ok {
+		metrics.add("kea_daemon_responses_sent",
+			"Number of the responses sent by the Kea daemon at the last statistics pull.",
+			strconv.FormatInt(sample.Value, 10), labels...)
+	}
+	if daemon.KeaDaemon != nil && daemon.KeaDaemon.KeaDHCPDaemon != nil {
+		stats := daemon.KeaDaemon.KeaDHCPDaemon.Stats
+		metrics.add("kea_daemon_rps1",
+			"Responses per second sent by the Kea daemon over the short interval.",
+			strconv.Itoa(stats.RPS1), labels...)
+		metrics.add("kea_daemon_rps2",
+			"Responses per second sent by the Kea daemon over the long interval.",
+			strconv.Itoa(stats.RPS2), labels...)
+	}
+}
+
+// Adds the metrics for the global statistics summed over all subnets.
+// The statistics without the values are skipped.
+func addPrometheusGlobalMetrics(metrics prometheusMetrics, stats map[string]*big.Int) {
+	for statName, statValue := range stats {
+		if statValue == nil || !exportedStatPattern.MatchString(statName) {
+			continue
+		}
+		metrics.add("kea_global_"+getPrometheusMetricSuffix(statName),
+			fmt.Sprintf("Kea global statistic %s summed over all subnets.", statName),
+			statValue.String())
+	}
+}
+
+// Exports the Kea lease statistics collected by the stats puller as the
+// Prometheus metrics in the text format. It allows for scraping the
+// subnet utilization directly from Stork, e.g., by Grafana. The metrics
+// are rendered from the latest statistics stored in the database on each
+// request, so they are as fresh as the last statistics pull.
+type PrometheusExporter struct {
+	db          *pg.DB
+	statsPuller *StatsPuller
+}
+
+// Creates the Prometheus exporter of the Kea lease statistics. The stats
+// puller provides the last sampled numbers of the sent responses. It may
+// be nil, in which case these metrics are not exported.
+func NewPrometheusExporter(db *pg.DB, statsPuller *StatsPuller) *PrometheusExporter {
+	return &PrometheusExporter{
+		db:          db,
+		statsPuller: statsPuller,
+	}
+}
+
+// Writes the metrics in the Prometheus text format. The subnets are
+// fetched from the database page by page.
+func (exporter *PrometheusExporter) Write(w io.Writer) error {
+	metrics, err := exporter.collect()
+	if err != nil {
+		return err
+	}
+	return metrics.write(w)
+}
+
+// Writes the metrics in the OpenMetrics text format terminated with the
+// EOF marker.
+func (exporter *PrometheusExporter) WriteOpenMetrics(w io.Writer) error {
+	metrics, err := exporter.collect()
+	if err != nil {
+		return err
+	}
+	return metrics.writeOpenMetrics(w)
+}
+
+// Collects the metrics from the statistics stored in the database. The
+// subnets are fetched from the database page by page.
+func (exporter *PrometheusExporter) collect() (prometheusMetrics, error) {
+	metrics := prometheusMetrics{}
+
+	err := dbmodel.ForEachSubnetsPage(exporter.db, nil, 0, func(subnets []dbmodel.Subnet) error {
+		for i := range subnets {
+			addPrometheusSubnetMetrics(metrics, &subnets[i])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	daemons, err := dbmodel.GetKeaDHCPDaemons(exporter.db)
+	if err != nil {
+		return nil, err
+	}
+	previousRps := map[int64]StatSample{}
+	if exporter.statsPuller != nil && exporter.statsPuller.RpsWorker != nil {
+		previousRps, _ = exporter.statsPuller.RpsWorker.getPreviousRps()
+	}
+	for i := range daemons {
+		addPrometheusDaemonMetrics(metrics, &daemons[i], previousRps)
+	}
+
+	globals, err := dbmodel.GetAllStats(exporter.db)
+	if err != nil {
+		return nil, err
+	}
+	addPrometheusGlobalMetrics(metrics, globals)
+
+	return metrics, nil
+}
+
+// Handles the HTTP request for the metrics. It can be mounted on any
+// HTTP server, e.g., under the /metrics/kea path. The metrics are served
+// in the OpenMetrics format if the client accepts it, e.g., Prometheus
+// with the OpenMetrics scraping enabled. Otherwise, the Prometheus text
+// format is used.
+func (exporter *PrometheusExporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	write, contentType := exporter.Write, prometheusContentType
+	if strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text") {
+		write, contentType = exporter.WriteOpenMetrics, openMetricsContentType
+	}
+	var builder strings.Builder
+	if err := write(&builder); err != nil {
+		log.WithError(err).Error("Problem exporting the Kea statistics as Prometheus metrics")
+		http.Error(w, "problem exporting the Kea statistics", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	_, _ = io.WriteString(w, builder.String())
+}
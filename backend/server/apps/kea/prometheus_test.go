@@ -0,0 +1,320 @@
+package kea
+
+import (
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	agentcommtest "isc.org/stork/server/agentcomm/test"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+	storktest "isc.org/stork/server/test/dbmodel"
+)
+
+// Renders the metrics in the Prometheus text format.
+func renderPrometheusMetrics(t *testing.T, metrics prometheusMetrics) string {
+	var builder strings.Builder
+	err := metrics.write(&builder)
+	require.NoError(t, err)
+	return builder.String()
+}
+
+// Test that the metrics are rendered in the Prometheus text format sorted
+// by name and with the escaped label values.
+func TestPrometheusMetricsWrite(t *testing.T) {
+	// Arrange
+	metrics := prometheusMetrics{}
+	metrics.add("kea_foo", "Foo metric.", "2", prometheusLabel{"app_name", `my "kea"\server`})
+	metrics.add("kea_bar", "Bar metric.", "1")
+	metrics.add("kea_foo", "Foo metric.", "3", prometheusLabel{"app_name", "line1\nline2"}, prometheusLabel{"daemon", "dhcp4"})
+
+	// Act
+	text := renderPrometheusMetrics(t, metrics)
+
+	// Assert
+	require.Equal(t, `# HELP kea_bar Bar metric.
+# TYPE kea_bar gauge
+kea_bar 1
+# HELP kea_foo Foo metric.
+# TYPE kea_foo gauge
+kea_foo{app_name="my \"kea\"\\server"} 2
+kea_foo{app_name="line1\nline2",daemon="dhcp4"} 3
+`, text)
+}
+
+// Test that the metrics are rendered in the OpenMetrics text format with
+// the units and the terminating EOF marker.
+func TestPrometheusMetricsWriteOpenMetrics(t *testing.T) {
+	// Arrange
+	metrics := prometheusMetrics{}
+	metrics.add("kea_subnet_assigned_addresses", "Assigned addresses.", "5",
+		prometheusLabel{"subnet_id", "1"}, prometheusLabel{"prefix", "192.0.2.0/24"})
+	metrics.addWithUnit("kea_daemon_uptime_seconds", "seconds", "Uptime.", "120",
+		prometheusLabel{"daemon", "dhcp4"})
+
+	// Act
+	var builder strings.Builder
+	err := metrics.writeOpenMetrics(&builder)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, `# HELP kea_daemon_uptime_seconds Uptime.
+# TYPE kea_daemon_uptime_seconds gauge
+# UNIT kea_daemon_uptime_seconds seconds
+kea_daemon_uptime_seconds{daemon="dhcp4"} 120
+# HELP kea_subnet_assigned_addresses Assigned addresses.
+# TYPE kea_subnet_assigned_addresses gauge
+kea_subnet_assigned_addresses{subnet_id="1",prefix="192.0.2.0/24"} 5
+# EOF
+`, builder.String())
+}
+
+// Test that the units and the EOF marker are not written in the Prometheus
+// text format.
+func TestPrometheusMetricsWriteNoOpenMetricsFeatures(t *testing.T) {
+	// Arrange
+	metrics := prometheusMetrics{}
+	metrics.addWithUnit("kea_daemon_uptime_seconds", "seconds", "Uptime.", "120")
+
+	// Act
+	text := renderPrometheusMetrics(t, metrics)
+
+	// Assert
+	require.NotContains(t, text, "# UNIT")
+	require.NotContains(t, text, "# EOF")
+}
+
+// Test that the invalid characters in the metric and label names are
+// replaced.
+func TestSanitizePrometheusNames(t *testing.T) {
+	require.Equal(t, "kea_subnet_assigned_addresses", sanitizePrometheusMetricName("kea_subnet_assigned_addresses"))
+	require.Equal(t, "kea:subnet_pool_0__total", sanitizePrometheusMetricName("kea:subnet-pool[0]-total"))
+	require.Equal(t, "_6_addresses", sanitizePrometheusMetricName("6-addresses"))
+	require.Equal(t, "_", sanitizePrometheusMetricName(""))
+
+	require.Equal(t, "app_name", sanitizePrometheusLabelName("app_name"))
+	require.Equal(t, "app_name", sanitizePrometheusLabelName("app:name"))
+	require.Equal(t, "_1st_label", sanitizePrometheusLabelName("1st-label"))
+}
+
+// Test that the sanitized names are rendered.
+func TestPrometheusMetricsWriteSanitizedNames(t *testing.T) {
+	// Arrange
+	metrics := prometheusMetrics{}
+	metrics.add("kea_subnet_total-nas", "Total NAs.", "1", prometheusLabel{"app-name", "kea"})
+
+	// Act
+	text := renderPrometheusMetrics(t, metrics)
+
+	// Assert
+	require.Contains(t, text, "# TYPE kea_subnet_total_nas gauge\n")
+	require.Contains(t, text, `kea_subnet_total_nas{app_name="kea"} 1`)
+}
+
+// Test that the statistic values are converted to the metric values.
+func TestFormatPrometheusValue(t *testing.T) {
+	hugeCount, ok := new(big.Int).SetString("36893488147419103232", 10)
+	require.True(t, ok)
+
+	for _, testCase := range []struct {
+		value    interface{}
+		expected string
+	}{
+		{uint64(18446744073709551615), "18446744073709551615"},
+		{int64(-1), "-1"},
+		{42, "42"},
+		{0.25, "0.25"},
+		{hugeCount, "36893488147419103232"},
+	} {
+		value, ok := formatPrometheusValue(testCase.value)
+		require.True(t, ok)
+		require.Equal(t, testCase.expected, value)
+	}
+
+	_, ok = formatPrometheusValue("foo")
+	require.False(t, ok)
+	_, ok = formatPrometheusValue((*big.Int)(nil))
+	require.False(t, ok)
+}
+
+// Test that the metrics are created for the local subnet statistics with
+// the labels distinguishing the daemons and apps.
+func TestAddPrometheusSubnetMetrics(t *testing.T) {
+	// Arrange
+	subnet := &dbmodel.Subnet{
+		Prefix: "192.0.2.0/24",
+		LocalSubnets: []*dbmodel.LocalSubnet{
+			{
+				LocalSubnetID: 1,
+				Daemon: &dbmodel.Daemon{
+					ID:   3,
+					Name: "dhcp4",
+					App: &dbmodel.App{
+						ID:   2,
+						Name: "kea@server1",
+					},
+				},
+				Stats: dbmodel.SubnetStats{
+					"assigned-addresses":         uint64(10),
+					"total-addresses":            uint64(256),
+					"pool[0].assigned-addresses": uint64(5),
+					"foo":                        "bar",
+				},
+			},
+			{
+				LocalSubnetID: 7,
+				Daemon: &dbmodel.Daemon{
+					ID:   5,
+					Name: "dhcp4",
+					App: &dbmodel.App{
+						ID:   4,
+						Name: "kea@server2",
+					},
+				},
+				Stats: dbmodel.SubnetStats{
+					"assigned-addresses": uint64(20),
+				},
+			},
+			// The local subnet without the statistics.
+			{
+				LocalSubnetID: 9,
+				Daemon: &dbmodel.Daemon{
+					ID:   6,
+					Name: "dhcp4",
+				},
+			},
+		},
+	}
+	metrics := prometheusMetrics{}
+
+	// Act
+	addPrometheusSubnetMetrics(metrics, subnet)
+
+	// Assert
+	require.Len(t, metrics, 2)
+	text := renderPrometheusMetrics(t, metrics)
+	require.Contains(t, text, "# HELP kea_subnet_assigned_addresses Kea subnet statistic assigned-addresses.\n")
+	require.Contains(t, text, `kea_subnet_assigned_addresses{app_id="2",app_name="kea@server1",daemon="dhcp4",daemon_id="3",subnet_id="1",prefix="192.0.2.0/24"} 10`)
+	require.Contains(t, text, `kea_subnet_assigned_addresses{app_id="4",app_name="kea@server2",daemon="dhcp4",daemon_id="5",subnet_id="7",prefix="192.0.2.0/24"} 20`)
+	require.Contains(t, text, `kea_subnet_total_addresses{app_id="2",app_name="kea@server1",daemon="dhcp4",daemon_id="3",subnet_id="1",prefix="192.0.2.0/24"} 256`)
+	require.NotContains(t, text, "pool")
+	require.NotContains(t, text, "foo")
+}
+
+// Test that the metrics are created for the responses sent by the daemon.
+func TestAddPrometheusDaemonMetrics(t *testing.T) {
+	// Arrange
+	daemon := &dbmodel.Daemon{
+		ID:     3,
+		AppID:  2,
+		Name:   "dhcp6",
+		Uptime: 3600,
+		KeaDaemon: &dbmodel.KeaDaemon{
+			KeaDHCPDaemon: &dbmodel.KeaDHCPDaemon{
+				Stats: dbmodel.KeaDHCPDaemonStats{
+					RPS1: 12,
+					RPS2: 7,
+				},
+			},
+		},
+	}
+	previousRps := map[int64]StatSample{
+		3: {Value: 1234},
+		4: {Value: 5678},
+	}
+	metrics := prometheusMetrics{}
+
+	// Act
+	addPrometheusDaemonMetrics(metrics, daemon, previousRps)
+
+	// Assert
+	text := renderPrometheusMetrics(t, metrics)
+	require.Contains(t, text, `kea_daemon_responses_sent{app_id="2",daemon="dhcp6",daemon_id="3"} 1234`)
+	require.Contains(t, text, `kea_daemon_rps1{app_id="2",daemon="dhcp6",daemon_id="3"} 12`)
+	require.Contains(t, text, `kea_daemon_rps2{app_id="2",daemon="dhcp6",daemon_id="3"} 7`)
+	require.Contains(t, text, `kea_daemon_uptime_seconds{app_id="2",daemon="dhcp6",daemon_id="3"} 3600`)
+	require.NotContains(t, text, "5678")
+}
+
+// Test that the metrics are created for the global statistics.
+func TestAddPrometheusGlobalMetrics(t *testing.T) {
+	// Arrange
+	hugeCount, ok := new(big.Int).SetString("36893488147419103232", 10)
+	require.True(t, ok)
+	metrics := prometheusMetrics{}
+
+	// Act
+	addPrometheusGlobalMetrics(metrics, map[string]*big.Int{
+		"total-nas":          hugeCount,
+		"assigned-addresses": big.NewInt(42),
+		"declined-addresses": nil,
+	})
+
+	// Assert
+	text := renderPrometheusMetrics(t, metrics)
+	require.Contains(t, text, "kea_global_total_nas 36893488147419103232\n")
+	require.Contains(t, text, "kea_global_assigned_addresses 42\n")
+	require.NotContains(t, text, "declined")
+}
+
+// Test that the exporter serves the metrics for the statistics stored in
+// the database.
+func TestPrometheusExporterServeHTTP(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+	_ = dbmodel.InitializeSettings(db, 0)
+	_ = dbmodel.InitializeStats(db)
+
+	v4Config, v6Config := createDhcpConfigs()
+	app := createAppWithSubnets(t, db, 0, v4Config, v6Config)
+
+	fa := agentcommtest.NewFakeAgents(createStandardKeaMock(false), nil)
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	for i := range app.Daemons {
+		sharedNetworks, subnets, err := detectDaemonNetworks(db, app.Daemons[i], lookup)
+		require.NoError(t, err)
+		_, err = dbmodel.CommitNetworksIntoDB(db, sharedNetworks, subnets, app.Daemons[i])
+		require.NoError(t, err)
+	}
+
+	sp, _ := NewStatsPuller(db, fa, &storktest.FakeEventCenter{}, nil)
+	defer sp.Shutdown()
+	err := sp.pullStats()
+	require.NoError(t, err)
+
+	exporter := NewPrometheusExporter(db, sp)
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/metrics/kea", nil)
+
+	// Act
+	exporter.ServeHTTP(recorder, request)
+
+	// Assert
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, prometheusContentType, recorder.Header().Get("Content-Type"))
+	text := recorder.Body.String()
+	require.Contains(t, text, `daemon="dhcp4"`)
+	require.Contains(t, text, `subnet_id="10"`)
+	require.Contains(t, text, "kea_subnet_assigned_addresses{")
+	require.Contains(t, text, "kea_daemon_responses_sent{")
+	require.Contains(t, text, "kea_global_assigned_addresses 2145\n")
+	require.NotContains(t, text, "# EOF")
+
+	// Act
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodGet, "/metrics/kea", nil)
+	request.Header.Set("Accept", "application/openmetrics-text;version=1.0.0,text/plain;version=0.0.4;q=0.5")
+	exporter.ServeHTTP(recorder, request)
+
+	// Assert
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, openMetricsContentType, recorder.Header().Get("Content-Type"))
+	text = recorder.Body.String()
+	require.Contains(t, text, "# UNIT kea_daemon_uptime_seconds seconds\n")
+	require.Contains(t, text, "kea_global_assigned_addresses 2145\n")
+	require.True(t, strings.HasSuffix(text, "# EOF\n"))
+}
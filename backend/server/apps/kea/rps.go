@@ -1,6 +1,7 @@
 package kea
 
 import (
+	"sync"
 	"time"
 
 	"github.com/go-pg/pg/v10"
@@ -11,12 +12,22 @@ import (
 	storkutil "isc.org/stork/util"
 )
 
+// Number of the stats pulling cycles after which the last known RPS value
+// of a daemon is evicted if the daemon hasn't returned the statistic.
+const rpsEvictionCycles = 3
+
 // Periodic Puller that generates RPS interval data.
 type RpsWorker struct {
 	db          *pg.DB
 	PreviousRps map[int64]StatSample // map of last known values per Daemon
 	Interval1   time.Duration
 	Interval2   time.Duration
+	clock       storkutil.Clock
+	cycle       int64           // number of the completed pulling cycles
+	lastSeen    map[int64]int64 // cycle when the daemon's value was recorded
+	// Protects the last known values and the cycles from being read, e.g.,
+	// by the metrics exporter, while they are updated.
+	mutex sync.RWMutex
 }
 
 // Represents a time/value pair.
@@ -74,12 +85,19 @@ type ResponseArguments6 struct {
 }
 
 // Create a RpsWorker object for building Kea API commands and using
-// their responses to populate RPS statistics.
-func NewRpsWorker(db *pg.DB) (*RpsWorker, error) {
+// their responses to populate RPS statistics. The clock is used to
+// timestamp the samples and to determine the RPS intervals. If it is
+// nil, the real clock is used.
+func NewRpsWorker(db *pg.DB, clock storkutil.Clock) (*RpsWorker, error) {
 	rpsWorker := &RpsWorker{}
 
 	rpsWorker.db = db
+	if clock == nil {
+		clock = storkutil.NewRealClock()
+	}
+	rpsWorker.clock = clock
 	rpsWorker.PreviousRps = map[int64]StatSample{}
+	rpsWorker.lastSeen = map[int64]int64{}
 
 	// The interval values may some day be configurable
 	rpsWorker.Interval1 = (time.Minute * 15)
@@ -91,7 +109,7 @@ func NewRpsWorker(db *pg.DB) (*RpsWorker, error) {
 // Ages off obsolete RPS interval data.
 func (rpsWorker *RpsWorker) AgeOffRpsIntervals() error {
 	// Age off records more than Interval2 old.
-	deleteTime := storkutil.UTCNow().Add(-rpsWorker.Interval2)
+	deleteTime := rpsWorker.clock.Now().Add(-rpsWorker.Interval2)
 	err := dbmodel.AgeOffRpsInterval(rpsWorker.db, deleteTime)
 	return err
 }
@@ -225,7 +243,8 @@ func (rpsWorker *RpsWorker) extractSamples6(statsResp []StatGetResponse6) ([]int
 func (rpsWorker *RpsWorker) updateDaemonRpsIntervals(daemon *dbmodel.Daemon, samples []interface{}) error {
 	// The first row of the samples is the most recent value and the only
 	// one we care about. Fetch it.
-	value, sampledAt, err := getFirstSample(samples)
+	sampledAt := rpsWorker.clock.Now()
+	value, err := getFirstSample(samples)
 	if err != nil {
 		return errors.WithMessagef(err, "could not extract RPS statistic")
 	}
@@ -238,7 +257,10 @@ func (rpsWorker *RpsWorker) updateDaemonRpsIntervals(daemon *dbmodel.Daemon, sam
 	}
 
 	// If we have a previous recording, calculate a delta row for it
-	if previous, exist := rpsWorker.PreviousRps[daemonID]; exist {
+	rpsWorker.mutex.RLock()
+	previous, exist := rpsWorker.PreviousRps[daemonID]
+	rpsWorker.mutex.RUnlock()
+	if exist {
 		// Make a new interval
 		interval := &dbmodel.RpsInterval{}
 		interval.KeaDaemonID = daemonID
@@ -261,16 +283,71 @@ func (rpsWorker *RpsWorker) updateDaemonRpsIntervals(daemon *dbmodel.Daemon, sam
 	}
 
 	// Always update the last reported values for the Daemon.
-	rpsWorker.PreviousRps[daemonID] = StatSample{sampledAt, value}
+	rpsWorker.recordPreviousRps(daemonID, StatSample{sampledAt, value})
 
 	return err
 }
 
+// Records the last known RPS value of the daemon in the current cycle.
+func (rpsWorker *RpsWorker) recordPreviousRps(daemonID int64, sample StatSample) {
+	rpsWorker.mutex.Lock()
+	defer rpsWorker.mutex.Unlock()
+	rpsWorker.PreviousRps[daemonID] = sample
+	rpsWorker.lastSeen[daemonID] = rpsWorker.cycle
+}
+
+// Completes the pulling cycle and evicts the last known RPS values of the
+// daemons that no longer exist or haven't returned the statistic for
+// rpsEvictionCycles cycles. It bounds the size of the PreviousRps map when
+// the daemons are frequently added and removed. The daemonIDs are the IDs
+// of all currently existing Kea daemons.
+func (rpsWorker *RpsWorker) EndCycle(daemonIDs []int64) {
+	rpsWorker.mutex.Lock()
+	defer rpsWorker.mutex.Unlock()
+	existing := make(map[int64]bool, len(daemonIDs))
+	for _, daemonID := range daemonIDs {
+		existing[daemonID] = true
+	}
+	for daemonID := range rpsWorker.PreviousRps {
+		if !existing[daemonID] || rpsWorker.cycle-rpsWorker.lastSeen[daemonID] >= rpsEvictionCycles {
+			delete(rpsWorker.PreviousRps, daemonID)
+			delete(rpsWorker.lastSeen, daemonID)
+		}
+	}
+	rpsWorker.cycle++
+}
+
+// Marks the last known RPS values of the daemons as seen in the current
+// cycle. It prevents evicting the values of the daemons pulled on their
+// own schedule, less often than the cycles are completed.
+func (rpsWorker *RpsWorker) retain(daemonIDs []int64) {
+	rpsWorker.mutex.Lock()
+	defer rpsWorker.mutex.Unlock()
+	for _, daemonID := range daemonIDs {
+		if _, ok := rpsWorker.PreviousRps[daemonID]; ok {
+			rpsWorker.lastSeen[daemonID] = rpsWorker.cycle
+		}
+	}
+}
+
+// Returns a copy of the last known RPS values by daemon ID and the number
+// of the completed pulling cycles. It doesn't wait for the ongoing stats
+// pull to complete.
+func (rpsWorker *RpsWorker) getPreviousRps() (map[int64]StatSample, int64) {
+	rpsWorker.mutex.RLock()
+	defer rpsWorker.mutex.RUnlock()
+	previousRps := make(map[int64]StatSample, len(rpsWorker.PreviousRps))
+	for daemonID, sample := range rpsWorker.PreviousRps {
+		previousRps[daemonID] = sample
+	}
+	return previousRps, rpsWorker.cycle
+}
+
 // Update the RPS value for both intervals for given daemon.
 // Uses the RpsInterval table contents to get the total responses and duration
 // for both intervals and then updates the Daemon's statistics in the db.
 func (rpsWorker *RpsWorker) updateKeaDaemonRpsStats(daemon *dbmodel.Daemon) error {
-	endTime := storkutil.UTCNow()
+	endTime := rpsWorker.clock.Now()
 	startTime1 := endTime.Add(-rpsWorker.Interval1)
 	daemonID := daemon.KeaDaemon.DaemonID
 
@@ -319,35 +396,34 @@ func calculateRps(totals []*dbmodel.RpsInterval) int {
 	return (int(responses / duration))
 }
 
-// Returns the statistic value and sample time from a given row within a
-// a list of samples.  Note that rather than use the sample time in the list,
-// We use current Stork Server time so interval times across Daemons are
-// consistent and relative to us. In other words, we don't care when Kea
-// modified the value, we care about when we got it.
-func getFirstSample(samples []interface{}) (int64, time.Time, error) {
-	sampledAt := storkutil.UTCNow()
+// Returns the statistic value from a given row within a list of samples.
+// Note that the sample time in the list is ignored. The caller uses current
+// Stork Server time so interval times across Daemons are consistent and
+// relative to us. In other words, we don't care when Kea modified the value,
+// we care about when we got it.
+func getFirstSample(samples []interface{}) (int64, error) {
 	if samples == nil {
-		return 0, sampledAt, errors.New("samples cannot be nil")
+		return 0, errors.New("samples cannot be nil")
 	}
 
 	if len(samples) == 0 {
 		// Not enough rows
-		return 0, sampledAt, errors.Errorf("sampleList is empty")
+		return 0, errors.Errorf("sampleList is empty")
 	}
 
 	row, ok := samples[0].([]interface{})
 	if !ok {
-		return 0, sampledAt, errors.Errorf("problem casting sample row: %+v", samples[0])
+		return 0, errors.Errorf("problem casting sample row: %+v", samples[0])
 	}
 
 	if len(row) != 2 {
-		return 0, sampledAt, errors.Errorf("row has incorrect number of values: %+v", row)
+		return 0, errors.Errorf("row has incorrect number of values: %+v", row)
 	}
 
 	// Not sure why unmarshalling makes it a float64, but we need an int64.
 	value := int64(row[0].(float64))
 
-	return value, sampledAt, nil
+	return value, nil
 }
 
 // "Static" constant for dhcp4 statistic-get command argument.
@@ -10,6 +10,7 @@ import (
 	dbops "isc.org/stork/server/database"
 	dbmodel "isc.org/stork/server/database/model"
 	dbtest "isc.org/stork/server/database/test"
+	"isc.org/stork/testutil"
 )
 
 // Check if Kea response to statistic-get command is handled correctly
@@ -29,7 +30,7 @@ func TestRpsWorkerEmptyOrInvalidResponses(t *testing.T) {
 	dhcp4Daemon, dhcp6Daemon := rpsTestAddMachine(t, db, true, true)
 
 	// prepare stats puller
-	rps, err := NewRpsWorker(db)
+	rps, err := NewRpsWorker(db, nil)
 	require.NoError(t, err)
 
 	for call := 0; call < len(jsonResponses); call++ {
@@ -69,7 +70,7 @@ func TestRpsWorkerPullRps(t *testing.T) {
 	dhcp4Daemon, dhcp6Daemon := rpsTestAddMachine(t, db, true, true)
 
 	// prepare stats puller
-	rps, err := NewRpsWorker(db)
+	rps, err := NewRpsWorker(db, nil)
 	require.NoError(t, err)
 
 	// Process a round of statistics for both daemons (equates to a single pull cycle)
@@ -188,7 +189,7 @@ func TestRpsWorkerValuePermutations(t *testing.T) {
 	dhcp4Daemon, _ := rpsTestAddMachine(t, db, true, false)
 
 	// prepare stats puller
-	rps, err := NewRpsWorker(db)
+	rps, err := NewRpsWorker(db, nil)
 	require.NoError(t, err)
 
 	for pass := 0; pass < len(statValues); pass++ {
@@ -224,6 +225,74 @@ func TestRpsWorkerValuePermutations(t *testing.T) {
 	}
 }
 
+// Verifies that the RPS rates are computed over the intervals measured by
+// the clock supplied to the worker.
+func TestRpsWorkerRatesWithFakeClock(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	makeJSON4 := func(value int64) string {
+		return fmt.Sprintf(`[{
+                            "result": 0,
+                            "text": "Everything is fine",
+                            "arguments": {
+                                "pkt4-ack-sent": [ [ %d, "2019-07-30 10:13:00.000000" ] ]
+                            }}]`, value)
+	}
+
+	// Create a machine with one app and one active dhcp4 daemon.
+	dhcp4Daemon, _ := rpsTestAddMachine(t, db, true, false)
+
+	clock := testutil.NewFakeClock(time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC))
+	rps, err := NewRpsWorker(db, clock)
+	require.NoError(t, err)
+
+	// The first sample only establishes the baseline.
+	err = rpsTestInvokeResponse4Handler(rps, dhcp4Daemon, makeJSON4(100))
+	require.NoError(t, err)
+	require.Equal(t, clock.Now(), rps.PreviousRps[1].SampledAt)
+
+	// 600 responses sent in 60 seconds.
+	clock.Advance(60 * time.Second)
+	err = rpsTestInvokeResponse4Handler(rps, dhcp4Daemon, makeJSON4(700))
+	require.NoError(t, err)
+
+	rpsIntervals, err := dbmodel.GetAllRpsIntervals(db)
+	require.NoError(t, err)
+	require.Len(t, rpsIntervals, 1)
+	require.EqualValues(t, 60, rpsIntervals[0].Duration)
+	require.EqualValues(t, 600, rpsIntervals[0].Responses)
+
+	daemon := &dbmodel.KeaDHCPDaemon{}
+	err = db.Model(daemon).Where("kea_daemon_id = ?", 1).Select()
+	require.NoError(t, err)
+	require.Equal(t, 10, daemon.Stats.RPS1)
+	require.Equal(t, 10, daemon.Stats.RPS2)
+
+	// 300 more responses sent in the next 60 seconds.
+	clock.Advance(60 * time.Second)
+	err = rpsTestInvokeResponse4Handler(rps, dhcp4Daemon, makeJSON4(1000))
+	require.NoError(t, err)
+
+	daemon = &dbmodel.KeaDHCPDaemon{}
+	err = db.Model(daemon).Where("kea_daemon_id = ?", 1).Select()
+	require.NoError(t, err)
+	require.Equal(t, 7, daemon.Stats.RPS1)
+	require.Equal(t, 7, daemon.Stats.RPS2)
+
+	// Move the clock beyond the first interval. Only the second interval
+	// should include the samples.
+	clock.Advance(rps.Interval1)
+	err = rpsTestInvokeResponse4Handler(rps, dhcp4Daemon, makeJSON4(1000))
+	require.NoError(t, err)
+
+	daemon = &dbmodel.KeaDHCPDaemon{}
+	err = db.Model(daemon).Where("kea_daemon_id = ?", 1).Select()
+	require.NoError(t, err)
+	require.Equal(t, 0, daemon.Stats.RPS1)
+	require.Equal(t, 1, daemon.Stats.RPS2)
+}
+
 // Convenience function that creates a machine with one Kea app and two daemons.
 func rpsTestAddMachine(t *testing.T, db *dbops.PgDB, dhcp4Active bool, dhcp6Active bool) (*dbmodel.Daemon, *dbmodel.Daemon) {
 	// add one machine with one kea app
@@ -333,3 +402,88 @@ func rpsTestInvokeResponse6Handler(rps *RpsWorker, daemon *dbmodel.Daemon, jsonR
 	err := rps.Response6Handler(daemon, responses[0])
 	return err
 }
+
+// Test that the last known RPS value of a removed daemon is evicted.
+func TestRpsWorkerEvictRemovedDaemon(t *testing.T) {
+	// Arrange
+	rps, err := NewRpsWorker(nil, nil)
+	require.NoError(t, err)
+	rps.recordPreviousRps(1, StatSample{time.Now(), 10})
+	rps.recordPreviousRps(2, StatSample{time.Now(), 20})
+
+	// Act
+	rps.EndCycle([]int64{1, 2})
+	require.Len(t, rps.PreviousRps, 2)
+	rps.recordPreviousRps(1, StatSample{time.Now(), 11})
+	rps.EndCycle([]int64{1})
+
+	// Assert
+	require.Len(t, rps.PreviousRps, 1)
+	require.Contains(t, rps.PreviousRps, int64(1))
+	require.NotContains(t, rps.lastSeen, int64(2))
+}
+
+// Test that the last known RPS value of a daemon that hasn't returned the
+// statistic for several cycles is eventually evicted.
+func TestRpsWorkerEvictNotSeenDaemon(t *testing.T) {
+	// Arrange
+	rps, err := NewRpsWorker(nil, nil)
+	require.NoError(t, err)
+	rps.recordPreviousRps(1, StatSample{time.Now(), 10})
+	rps.recordPreviousRps(2, StatSample{time.Now(), 20})
+	rps.EndCycle([]int64{1, 2})
+
+	// Act & Assert
+	for i := 1; i < rpsEvictionCycles; i++ {
+		rps.recordPreviousRps(1, StatSample{time.Now(), int64(10 + i)})
+		rps.EndCycle([]int64{1, 2})
+		require.Contains(t, rps.PreviousRps, int64(2))
+	}
+	rps.recordPreviousRps(1, StatSample{time.Now(), 20})
+	rps.EndCycle([]int64{1, 2})
+	require.NotContains(t, rps.PreviousRps, int64(2))
+	require.Contains(t, rps.PreviousRps, int64(1))
+}
+
+// Test that the retained RPS values of the daemons pulled on their own
+// schedule are not evicted.
+func TestRpsWorkerRetain(t *testing.T) {
+	// Arrange
+	rps, err := NewRpsWorker(nil, nil)
+	require.NoError(t, err)
+	rps.recordPreviousRps(1, StatSample{time.Now(), 10})
+	rps.recordPreviousRps(2, StatSample{time.Now(), 20})
+
+	// Act
+	for i := 0; i <= rpsEvictionCycles; i++ {
+		rps.retain([]int64{2, 3})
+		rps.EndCycle([]int64{1, 2, 3})
+	}
+
+	// Assert
+	require.NotContains(t, rps.PreviousRps, int64(1))
+	require.Contains(t, rps.PreviousRps, int64(2))
+	require.NotContains(t, rps.lastSeen, int64(3))
+}
+
+// Test that the copy of the last known RPS values is returned along with
+// the number of the completed cycles.
+func TestRpsWorkerGetPreviousRps(t *testing.T) {
+	// Arrange
+	rps, err := NewRpsWorker(nil, nil)
+	require.NoError(t, err)
+	rps.recordPreviousRps(1, StatSample{time.Now(), 10})
+	rps.EndCycle([]int64{1})
+
+	// Act
+	previousRps, cycle := rps.getPreviousRps()
+
+	// Assert
+	require.EqualValues(t, 1, cycle)
+	require.Len(t, previousRps, 1)
+	require.EqualValues(t, 10, previousRps[1].Value)
+
+	// The copy is independent of the worker state.
+	delete(previousRps, 1)
+	require.Contains(t, rps.PreviousRps, int64(1))
+}
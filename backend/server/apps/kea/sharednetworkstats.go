@@ -0,0 +1,184 @@
+package kea
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	keactrl "isc.org/stork/appctrl/kea"
+	dbmodel "isc.org/stork/server/database/model"
+	storkutil "isc.org/stork/util"
+)
+
+// Commands returning the lease statistics grouped by shared network. They
+// are supported by the statistics hook in the newer Kea versions.
+const (
+	statLease4SharedNetworkGet = "stat-lease4-shared-network-get"
+	statLease6SharedNetworkGet = "stat-lease6-shared-network-get"
+)
+
+// Part of response for stat-lease4-shared-network-get and
+// stat-lease6-shared-network-get commands. The rows contain the shared
+// network name and the numeric statistics, so they are kept raw and
+// decoded by column.
+type ResultSetInStatLeaseSharedNetworkGet struct {
+	Columns []string
+	Rows    [][]json.RawMessage
+}
+
+// Part of response for stat-lease4-shared-network-get and
+// stat-lease6-shared-network-get commands.
+type StatLeaseSharedNetworkGetArgs struct {
+	ResultSet ResultSetInStatLeaseSharedNetworkGet `json:"result-set"`
+	Timestamp string
+}
+
+// Represents unmarshaled response from Kea daemon to the
+// stat-lease4-shared-network-get and stat-lease6-shared-network-get commands.
+type StatLeaseSharedNetworkGetResponse struct {
+	keactrl.ResponseHeader
+	Arguments *StatLeaseSharedNetworkGetArgs `json:"arguments,omitempty"`
+}
+
+// A key identifying the shared network statistics returned by Kea. The
+// shared networks are identified by name and family.
+type sharedNetworkStatsKey struct {
+	Name   string
+	Family int
+}
+
+// Parses the response to the stat-lease4-shared-network-get or
+// stat-lease6-shared-network-get command. It returns nil statistics
+// without an error if the command is not supported by the daemon.
+func parseSharedNetworkStats(response interface{}, family int) (map[string]*sharedNetworkStats, error) {
+	statsResp, ok := response.(*[]StatLeaseSharedNetworkGetResponse)
+	if !ok || len(*statsResp) == 0 {
+		return nil, errors.Errorf("response is empty: %+v", response)
+	}
+	sr := (*statsResp)[0]
+	if sr.Result != keactrl.ResponseSuccess {
+		// The older Kea versions don't support this command.
+		return nil, nil
+	}
+	if sr.Arguments == nil {
+		return nil, errors.Errorf("missing arguments from shared network lease stats response %+v", sr)
+	}
+
+	resultSet := sr.Arguments.ResultSet
+	stats := make(map[string]*sharedNetworkStats)
+	for _, row := range resultSet.Rows {
+		if len(row) != len(resultSet.Columns) {
+			return nil, errors.Errorf("invalid number of values in the shared network lease stats row: %d, expected: %d", len(row), len(resultSet.Columns))
+		}
+		var name string
+		networkStats := newSharedNetworkStats()
+		for colIdx, val := range row {
+			column := resultSet.Columns[colIdx]
+			if column == "shared-network-name" {
+				if err := json.Unmarshal(val, &name); err != nil {
+					return nil, errors.Wrapf(err, "invalid shared network name in the lease stats row")
+				}
+				continue
+			}
+			var counter *storkutil.BigCounter
+			switch {
+			case (family == 4 && column == "total-addresses") || (family == 6 && column == "total-nas"):
+				counter = networkStats.totalAddresses
+			case (family == 4 && column == "assigned-addresses") || (family == 6 && column == "assigned-nas"):
+				counter = networkStats.totalAssignedAddresses
+			case family == 6 && column == "total-pds":
+				counter = networkStats.totalDelegatedPrefixes
+			case family == 6 && column == "assigned-pds":
+				counter = networkStats.totalAssignedDelegatedPrefixes
+			default:
+				continue
+			}
+			value, ok := new(big.Int).SetString(string(val), 10)
+			if !ok {
+				return nil, errors.Errorf("invalid value of the %s statistic: %s", column, string(val))
+			}
+			counter.AddBigInt(value)
+		}
+		if name == "" {
+			return nil, errors.New("missing shared network name in the lease stats row")
+		}
+		stats[name] = networkStats
+	}
+	return stats, nil
+}
+
+// Processes the shared network lease statistics returned by the daemon
+// and records them until the utilization is computed.
+func (statsPuller *StatsPuller) storeSharedNetworkStats(response interface{}, daemon *dbmodel.Daemon, family int) error {
+	stats, err := parseSharedNetworkStats(response, family)
+	if err != nil {
+		return err
+	}
+	if stats == nil {
+		log.Debugf("Daemon %d does not return the shared network lease stats; they will be summed from the subnets", daemon.ID)
+		return nil
+	}
+	if statsPuller.sharedNetworkStats == nil {
+		statsPuller.sharedNetworkStats = make(map[sharedNetworkStatsKey]map[int64]*sharedNetworkStats)
+	}
+	for name, networkStats := range stats {
+		key := sharedNetworkStatsKey{name, family}
+		if statsPuller.sharedNetworkStats[key] == nil {
+			statsPuller.sharedNetworkStats[key] = make(map[int64]*sharedNetworkStats)
+		}
+		statsPuller.sharedNetworkStats[key][daemon.ID] = networkStats
+	}
+	return nil
+}
+
+// Removes the recorded shared network statistics returned by the daemons
+// matching the function. It drops the statistics of the daemons whose
+// statistics are about to be pulled again.
+func (statsPuller *StatsPuller) removeSharedNetworkStats(remove func(daemonID int64) bool) {
+	for key, daemonStats := range statsPuller.sharedNetworkStats {
+		for daemonID := range daemonStats {
+			if remove(daemonID) {
+				delete(daemonStats, daemonID)
+			}
+		}
+		if len(daemonStats) == 0 {
+			delete(statsPuller.sharedNetworkStats, key)
+		}
+	}
+}
+
+// Returns the shared network statistics returned directly by Kea by
+// shared network ID. The statistics from the excluded daemons (i.e., the
+// passive HA servers) are skipped. The shared networks missing in the
+// returned map should have their statistics summed from the subnets.
+func (statsPuller *StatsPuller) getDirectSharedNetworkStats(networks []dbmodel.SharedNetwork, excludedDaemons []int64) map[int64]*sharedNetworkStats {
+	excluded := make(map[int64]bool)
+	for _, daemonID := range excludedDaemons {
+		excluded[daemonID] = true
+	}
+	stats := make(map[int64]*sharedNetworkStats)
+	for _, network := range networks {
+		daemonStats, ok := statsPuller.sharedNetworkStats[sharedNetworkStatsKey{network.Name, network.Family}]
+		if !ok {
+			continue
+		}
+		var networkStats *sharedNetworkStats
+		for daemonID, s := range daemonStats {
+			if excluded[daemonID] {
+				continue
+			}
+			if networkStats == nil {
+				networkStats = newSharedNetworkStats()
+			}
+			networkStats.totalAddresses.Add(s.totalAddresses)
+			networkStats.totalAssignedAddresses.Add(s.totalAssignedAddresses)
+			networkStats.totalDelegatedPrefixes.Add(s.totalDelegatedPrefixes)
+			networkStats.totalAssignedDelegatedPrefixes.Add(s.totalAssignedDelegatedPrefixes)
+		}
+		if networkStats != nil {
+			stats[network.ID] = networkStats
+		}
+	}
+	return stats
+}
@@ -0,0 +1,196 @@
+package kea
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	keactrl "isc.org/stork/appctrl/kea"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Unmarshals the JSON response to the shared network lease stats command.
+func unmarshalSharedNetworkStatsResponse(t *testing.T, command, daemon, response string) *[]StatLeaseSharedNetworkGetResponse {
+	cmd := keactrl.NewCommand(command, []string{daemon}, nil)
+	parsed := &[]StatLeaseSharedNetworkGetResponse{}
+	err := keactrl.UnmarshalResponseList(cmd, []byte(response), parsed)
+	require.NoError(t, err)
+	return parsed
+}
+
+// Test that the DHCPv4 shared network lease stats are parsed.
+func TestParseSharedNetworkStats4(t *testing.T) {
+	// Arrange
+	response := unmarshalSharedNetworkStatsResponse(t, statLease4SharedNetworkGet, "dhcp4", `[{
+		"result": 0,
+		"text": "stat-lease4-shared-network-get: 2 rows found",
+		"arguments": {
+			"result-set": {
+				"columns": [ "shared-network-name", "total-addresses", "assigned-addresses", "declined-addresses" ],
+				"rows": [
+					[ "frog", 256, 111, 0 ],
+					[ "mouse", 128, 128, 2 ]
+				],
+				"timestamp": "2018-05-04 15:03:37.000000"
+			}
+		}
+	}]`)
+
+	// Act
+	stats, err := parseSharedNetworkStats(response, 4)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	require.EqualValues(t, 256, stats["frog"].totalAddresses.ToUint64())
+	require.EqualValues(t, 111, stats["frog"].totalAssignedAddresses.ToUint64())
+	require.InDelta(t, 111.0/256.0, stats["frog"].GetAddressUtilization(), 0.001)
+	require.False(t, stats["frog"].IsExhausted())
+	require.True(t, stats["mouse"].IsExhausted())
+}
+
+// Test that the DHCPv6 shared network lease stats exceeding the uint64
+// range are parsed.
+func TestParseSharedNetworkStats6(t *testing.T) {
+	// Arrange
+	response := unmarshalSharedNetworkStatsResponse(t, statLease6SharedNetworkGet, "dhcp6", `[{
+		"result": 0,
+		"text": "stat-lease6-shared-network-get: 1 rows found",
+		"arguments": {
+			"result-set": {
+				"columns": [ "shared-network-name", "total-nas", "assigned-nas", "declined-nas", "total-pds", "assigned-pds" ],
+				"rows": [
+					[ "frog", 36893488147419103232, 10, 1, 1024, 256 ]
+				]
+			}
+		}
+	}]`)
+
+	// Act
+	stats, err := parseSharedNetworkStats(response, 6)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	require.Equal(t, "36893488147419103232", stats["frog"].totalAddresses.ToBigInt().String())
+	require.EqualValues(t, 10, stats["frog"].totalAssignedAddresses.ToUint64())
+	require.EqualValues(t, 1024, stats["frog"].totalDelegatedPrefixes.ToUint64())
+	require.EqualValues(t, 256, stats["frog"].totalAssignedDelegatedPrefixes.ToUint64())
+	require.InDelta(t, 0.25, stats["frog"].GetDelegatedPrefixUtilization(), 0.001)
+}
+
+// Test that no stats and no error are returned when the daemon doesn't
+// support the shared network lease stats command.
+func TestParseSharedNetworkStatsUnsupported(t *testing.T) {
+	// Arrange
+	response := unmarshalSharedNetworkStatsResponse(t, statLease4SharedNetworkGet, "dhcp4", `[{
+		"result": 2,
+		"text": "'stat-lease4-shared-network-get' command not supported."
+	}]`)
+
+	// Act
+	stats, err := parseSharedNetworkStats(response, 4)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, stats)
+}
+
+// Test that parsing the malformed shared network lease stats fails.
+func TestParseSharedNetworkStatsInvalid(t *testing.T) {
+	for _, rows := range []string{`[ [ "frog", 256 ] ]`, `[ [ 1, 256, 1 ] ]`, `[ [ "frog", "foo", 1 ] ]`} {
+		t.Run(rows, func(t *testing.T) {
+			response := unmarshalSharedNetworkStatsResponse(t, statLease4SharedNetworkGet, "dhcp4", `[{
+				"result": 0,
+				"arguments": {
+					"result-set": {
+						"columns": [ "shared-network-name", "total-addresses", "assigned-addresses" ],
+						"rows": `+rows+`
+					}
+				}
+			}]`)
+			stats, err := parseSharedNetworkStats(response, 4)
+			require.Error(t, err)
+			require.Nil(t, stats)
+		})
+	}
+	_, err := parseSharedNetworkStats(&[]StatLeaseSharedNetworkGetResponse{}, 4)
+	require.Error(t, err)
+}
+
+// Test that the shared network stats returned by Kea are combined across
+// the daemons excluding the passive HA servers, and that the shared
+// networks without these stats are omitted so their stats are summed
+// from the subnets.
+func TestGetDirectSharedNetworkStats(t *testing.T) {
+	// Arrange
+	puller := &StatsPuller{}
+	response := unmarshalSharedNetworkStatsResponse(t, statLease4SharedNetworkGet, "dhcp4", `[{
+		"result": 0,
+		"arguments": {
+			"result-set": {
+				"columns": [ "shared-network-name", "total-addresses", "assigned-addresses" ],
+				"rows": [ [ "frog", 100, 10 ] ]
+			}
+		}
+	}]`)
+	unsupported := unmarshalSharedNetworkStatsResponse(t, statLease4SharedNetworkGet, "dhcp4", `[{
+		"result": 2,
+		"text": "'stat-lease4-shared-network-get' command not supported."
+	}]`)
+	require.NoError(t, puller.storeSharedNetworkStats(response, &dbmodel.Daemon{ID: 1}, 4))
+	require.NoError(t, puller.storeSharedNetworkStats(response, &dbmodel.Daemon{ID: 2}, 4))
+	require.NoError(t, puller.storeSharedNetworkStats(response, &dbmodel.Daemon{ID: 3}, 4))
+	require.NoError(t, puller.storeSharedNetworkStats(unsupported, &dbmodel.Daemon{ID: 4}, 4))
+
+	networks := []dbmodel.SharedNetwork{
+		{ID: 10, Name: "frog", Family: 4},
+		{ID: 11, Name: "frog", Family: 6},
+		{ID: 12, Name: "mouse", Family: 4},
+	}
+
+	// Act
+	stats := puller.getDirectSharedNetworkStats(networks, []int64{3})
+
+	// Assert
+	require.Len(t, stats, 1)
+	require.Contains(t, stats, int64(10))
+	require.EqualValues(t, 200, stats[10].totalAddresses.ToUint64())
+	require.EqualValues(t, 20, stats[10].totalAssignedAddresses.ToUint64())
+}
+
+// Test that the shared network stats returned by the selected daemons are
+// removed.
+func TestRemoveSharedNetworkStats(t *testing.T) {
+	// Arrange
+	puller := &StatsPuller{}
+	response := unmarshalSharedNetworkStatsResponse(t, statLease4SharedNetworkGet, "dhcp4", `[{
+		"result": 0,
+		"arguments": {
+			"result-set": {
+				"columns": [ "shared-network-name", "total-addresses", "assigned-addresses" ],
+				"rows": [ [ "frog", 100, 10 ] ]
+			}
+		}
+	}]`)
+	require.NoError(t, puller.storeSharedNetworkStats(response, &dbmodel.Daemon{ID: 1}, 4))
+	require.NoError(t, puller.storeSharedNetworkStats(response, &dbmodel.Daemon{ID: 2}, 4))
+
+	// Act
+	puller.removeSharedNetworkStats(func(daemonID int64) bool {
+		return daemonID == 1
+	})
+
+	// Assert
+	require.Len(t, puller.sharedNetworkStats, 1)
+	daemonStats := puller.sharedNetworkStats[sharedNetworkStatsKey{"frog", 4}]
+	require.Len(t, daemonStats, 1)
+	require.Contains(t, daemonStats, int64(2))
+
+	// Act
+	puller.removeSharedNetworkStats(func(daemonID int64) bool {
+		return true
+	})
+
+	// Assert
+	require.Empty(t, puller.sharedNetworkStats)
+}
@@ -0,0 +1,125 @@
+package kea
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	keactrl "isc.org/stork/appctrl/kea"
+	"isc.org/stork/server/agentcomm"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Format of the timestamps of the statistic samples returned by Kea.
+const statisticTimestampFormat = "2006-01-02 15:04:05.999999"
+
+// Represents a response from the single Kea server to the statistic-get
+// command for an arbitrary statistic, e.g.:
+//
+//	{
+//		"arguments": {
+//			"pkt4-request-received": [
+//				[ 125, "2019-07-30 10:11:19.498739" ],
+//				...
+//			]
+//		},
+//		"result": 0
+//	}
+//
+// The arguments are empty if the statistic doesn't exist.
+type StatisticGetResponse struct {
+	keactrl.ResponseHeader
+	Arguments map[string][][]json.RawMessage `json:"arguments,omitempty"`
+}
+
+// Represents a single sample of a Kea statistic. The value is a big
+// integer because some statistics (e.g., the number of addresses in the
+// IPv6 subnets) don't fit in the 64-bit integers.
+type StatisticSample struct {
+	Value     *big.Int
+	SampledAt time.Time
+}
+
+// Parses the list of value/timestamp pairs of the statistic. The most
+// recent sample comes first.
+func parseStatisticSamples(name string, rawSamples [][]json.RawMessage) ([]StatisticSample, error) {
+	samples := []StatisticSample{}
+	for _, rawSample := range rawSamples {
+		if len(rawSample) != 2 {
+			return nil, errors.Errorf("sample of the %s statistic has incorrect number of values: %d", name, len(rawSample))
+		}
+		value, ok := new(big.Int).SetString(string(rawSample[0]), 10)
+		if !ok {
+			return nil, errors.Errorf("invalid value of the %s statistic: %s", name, string(rawSample[0]))
+		}
+		var timestamp string
+		if err := json.Unmarshal(rawSample[1], &timestamp); err != nil {
+			return nil, errors.Wrapf(err, "invalid timestamp type of the %s statistic sample", name)
+		}
+		sampledAt, err := time.Parse(statisticTimestampFormat, timestamp)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid timestamp of the %s statistic sample", name)
+		}
+		samples = append(samples, StatisticSample{
+			Value:     value,
+			SampledAt: sampledAt,
+		})
+	}
+	return samples, nil
+}
+
+// Sends the statistic-get command for the statistic with the specified name
+// to the selected daemons of the Kea app, e.g., dhcp4 and dhcp6. It returns
+// the parsed samples by daemon name. A daemon is not included in the
+// returned map if it doesn't have the statistic. The daemons returning an
+// error are logged and skipped, so the statistic of the other daemons is
+// still returned.
+func GetStatistic(agents agentcomm.ConnectedAgents, dbApp *dbmodel.App, daemons []string, name string) (map[string][]StatisticSample, error) {
+	if len(daemons) == 0 {
+		return nil, errors.New("no daemons specified to get the statistic from")
+	}
+	arguments := map[string]interface{}{
+		"name": name,
+	}
+	command := keactrl.NewCommand("statistic-get", daemons, arguments)
+	response := []StatisticGetResponse{}
+	ctx := context.Background()
+	respResult, err := agents.ForwardToKeaOverHTTP(ctx, dbApp, []keactrl.SerializableCommand{command}, &response)
+	if err != nil {
+		return nil, err
+	}
+	if respResult.Error != nil {
+		return nil, respResult.Error
+	}
+	if len(respResult.CmdsErrors) > 0 && respResult.CmdsErrors[0] != nil {
+		return nil, respResult.CmdsErrors[0]
+	}
+	if len(response) == 0 {
+		return nil, errors.Errorf("invalid response to statistic-get command received")
+	}
+
+	samples := make(map[string][]StatisticSample)
+	for _, daemonResponse := range response {
+		if daemonResponse.Result != keactrl.ResponseSuccess {
+			log.WithFields(log.Fields{
+				"app":       dbApp.Name,
+				"daemon":    daemonResponse.Daemon,
+				"statistic": name,
+			}).Warnf("Failed to get the statistic: %s", daemonResponse.Text)
+			continue
+		}
+		rawSamples, ok := daemonResponse.Arguments[name]
+		if !ok {
+			continue
+		}
+		daemonSamples, err := parseStatisticSamples(name, rawSamples)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to parse the statistic returned by %s", daemonResponse.Daemon)
+		}
+		samples[daemonResponse.Daemon] = daemonSamples
+	}
+	return samples, nil
+}
@@ -0,0 +1,154 @@
+package kea
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	keactrl "isc.org/stork/appctrl/kea"
+	agentcommtest "isc.org/stork/server/agentcomm/test"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Generates a response to the statistic-get command for the
+// pkt4-request-received statistic sent to the DHCPv4 and DHCPv6 daemons.
+// The DHCPv6 daemon doesn't have the statistic.
+func mockStatisticGet(callNo int, responses []interface{}) {
+	json := []byte(`[
+        {
+            "result": 0,
+            "arguments": {
+                "pkt4-request-received": [
+                    [ 125, "2019-07-30 10:11:19.498739" ],
+                    [ 36893488147419103232, "2019-07-30 10:10:19.5" ]
+                ]
+            }
+        },
+        {
+            "result": 0,
+            "arguments": { }
+        }
+    ]`)
+	command := keactrl.NewCommand("statistic-get", []string{"dhcp4", "dhcp6"}, nil)
+	_ = keactrl.UnmarshalResponseList(command, json, responses[0])
+}
+
+// Generates a response to the statistic-get command where the DHCPv4
+// daemon returns an error.
+func mockStatisticGetError(callNo int, responses []interface{}) {
+	json := []byte(`[
+        {
+            "result": 1,
+            "text": "unable to forward command to the dhcp4 service"
+        },
+        {
+            "result": 0,
+            "arguments": {
+                "pkt4-request-received": [
+                    [ 5, "2019-07-30 10:11:19.498739" ]
+                ]
+            }
+        }
+    ]`)
+	command := keactrl.NewCommand("statistic-get", []string{"dhcp4", "dhcp6"}, nil)
+	_ = keactrl.UnmarshalResponseList(command, json, responses[0])
+}
+
+// Generates a response to the statistic-get command with a malformed
+// sample.
+func mockStatisticGetInvalid(callNo int, responses []interface{}) {
+	json := []byte(`[
+        {
+            "result": 0,
+            "arguments": {
+                "pkt4-request-received": [
+                    [ 125 ]
+                ]
+            }
+        }
+    ]`)
+	command := keactrl.NewCommand("statistic-get", []string{"dhcp4"}, nil)
+	_ = keactrl.UnmarshalResponseList(command, json, responses[0])
+}
+
+// Returns the Kea app used in the statistic-get tests.
+func getStatisticTestApp() *dbmodel.App {
+	accessPoints := []*dbmodel.AccessPoint{}
+	accessPoints = dbmodel.AppendAccessPoint(accessPoints, dbmodel.AccessPointControl, "localhost", "", 8000, false)
+	return &dbmodel.App{
+		ID:           1,
+		AccessPoints: accessPoints,
+	}
+}
+
+// Test that the named statistic is fetched from the selected daemons.
+func TestGetStatistic(t *testing.T) {
+	// Arrange
+	agents := agentcommtest.NewFakeAgents(mockStatisticGet, nil)
+	app := getStatisticTestApp()
+
+	// Act
+	samples, err := GetStatistic(agents, app, []string{"dhcp4", "dhcp6"}, "pkt4-request-received")
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	require.Contains(t, samples, "dhcp4")
+	require.Len(t, samples["dhcp4"], 2)
+	require.EqualValues(t, 125, samples["dhcp4"][0].Value.Int64())
+	require.Equal(t, time.Date(2019, time.July, 30, 10, 11, 19, 498739000, time.UTC), samples["dhcp4"][0].SampledAt)
+	require.Equal(t, "36893488147419103232", samples["dhcp4"][1].Value.String())
+	require.Equal(t, time.Date(2019, time.July, 30, 10, 10, 19, 500000000, time.UTC), samples["dhcp4"][1].SampledAt)
+
+	require.Len(t, agents.RecordedCommands, 1)
+	command := agents.RecordedCommands[0].(*keactrl.Command)
+	require.Equal(t, "statistic-get", command.Command)
+	require.Equal(t, []string{"dhcp4", "dhcp6"}, command.Daemons)
+	require.Equal(t, "pkt4-request-received", command.Arguments.(map[string]interface{})["name"])
+}
+
+// Test that the daemons returning an error are skipped.
+func TestGetStatisticDaemonError(t *testing.T) {
+	// Arrange
+	agents := agentcommtest.NewFakeAgents(mockStatisticGetError, nil)
+	app := getStatisticTestApp()
+
+	// Act
+	samples, err := GetStatistic(agents, app, []string{"dhcp4", "dhcp6"}, "pkt4-request-received")
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	require.Contains(t, samples, "dhcp6")
+	require.Len(t, samples["dhcp6"], 1)
+	require.EqualValues(t, 5, samples["dhcp6"][0].Value.Int64())
+}
+
+// Test that an error is returned for a malformed statistic sample.
+func TestGetStatisticInvalidSample(t *testing.T) {
+	// Arrange
+	agents := agentcommtest.NewFakeAgents(mockStatisticGetInvalid, nil)
+	app := getStatisticTestApp()
+
+	// Act
+	samples, err := GetStatistic(agents, app, []string{"dhcp4"}, "pkt4-request-received")
+
+	// Assert
+	require.Error(t, err)
+	require.Nil(t, samples)
+}
+
+// Test that an error is returned when no daemons are specified.
+func TestGetStatisticNoDaemons(t *testing.T) {
+	// Arrange
+	agents := agentcommtest.NewFakeAgents(mockStatisticGet, nil)
+	app := getStatisticTestApp()
+
+	// Act
+	samples, err := GetStatistic(agents, app, []string{}, "pkt4-request-received")
+
+	// Assert
+	require.Error(t, err)
+	require.Nil(t, samples)
+	require.Empty(t, agents.RecordedCommands)
+}
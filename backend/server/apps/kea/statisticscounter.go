@@ -18,6 +18,10 @@ type globalStats struct {
 	totalDeclinedIPv6Addresses     *storkutil.BigCounter
 	totalDelegatedPrefixes         *storkutil.BigCounter
 	totalAssignedDelegatedPrefixes *storkutil.BigCounter
+	// The lease reclamation counters are summed over the IPv4 and IPv6
+	// subnets.
+	totalReclaimedLeases            *storkutil.BigCounter
+	totalReclaimedDeclinedAddresses *storkutil.BigCounter
 }
 
 // Constructor of the global statistic struct with all
@@ -32,6 +36,9 @@ func newGlobalStats() *globalStats {
 		totalDeclinedIPv6Addresses:     storkutil.NewBigCounter(0),
 		totalDelegatedPrefixes:         storkutil.NewBigCounter(0),
 		totalAssignedDelegatedPrefixes: storkutil.NewBigCounter(0),
+
+		totalReclaimedLeases:            storkutil.NewBigCounter(0),
+		totalReclaimedDeclinedAddresses: storkutil.NewBigCounter(0),
 	}
 }
 
@@ -57,6 +64,7 @@ type subnetStats interface {
 	GetAddressUtilization() float64
 	GetDelegatedPrefixUtilization() float64
 	GetStatistics() dbmodel.SubnetStats
+	IsExhausted() bool
 }
 
 // Sum of the subnet statistics from the single shared network.
@@ -100,6 +108,13 @@ func (s *sharedNetworkStats) GetStatistics() dbmodel.SubnetStats {
 	}
 }
 
+// Checks if all addresses or all delegated prefixes in the shared network
+// are assigned.
+func (s *sharedNetworkStats) IsExhausted() bool {
+	return isCounterExhausted(s.totalAssignedAddresses, s.totalAddresses) ||
+		isCounterExhausted(s.totalAssignedDelegatedPrefixes, s.totalDelegatedPrefixes)
+}
+
 // Add the IPv4 subnet statistics to the shared network state.
 func (s *sharedNetworkStats) addIPv4Subnet(subnet *subnetIPv4Stats) {
 	s.totalAddresses.AddUint64(subnet.totalAddresses)
@@ -146,6 +161,12 @@ func (s *subnetIPv4Stats) GetStatistics() dbmodel.SubnetStats {
 	}
 }
 
+// Checks if all addresses in the IPv4 subnet are assigned. The total
+// addresses include the out-of-pool reservations.
+func (s *subnetIPv4Stats) IsExhausted() bool {
+	return s.totalAddresses > 0 && s.totalAssignedAddresses >= s.totalAddresses
+}
+
 // IPv6 statistics retrieved from the single subnet.
 type subnetIPv6Stats struct {
 	totalAddresses                 *storkutil.BigCounter
@@ -178,6 +199,19 @@ func (s *subnetIPv6Stats) GetStatistics() dbmodel.SubnetStats {
 	}
 }
 
+// Checks if all addresses or all delegated prefixes in the IPv6 subnet
+// are assigned. The totals include the out-of-pool reservations.
+func (s *subnetIPv6Stats) IsExhausted() bool {
+	return isCounterExhausted(s.totalAssignedAddresses, s.totalAddresses) ||
+		isCounterExhausted(s.totalAssignedDelegatedPrefixes, s.totalDelegatedPrefixes)
+}
+
+// Checks if the assigned counter reached the non-zero total counter.
+func isCounterExhausted(assigned, total *storkutil.BigCounter) bool {
+	totalValue := total.ToBigInt()
+	return totalValue.Sign() > 0 && assigned.ToBigInt().Cmp(totalValue) >= 0
+}
+
 // Statistics Counter is a helper for calculating the global IPv4 and IPv6
 // address, and delegated prefix statistics per subnet and shared network.
 type statisticsCounter struct {
@@ -242,6 +276,8 @@ func (c *statisticsCounter) add(subnet *dbmodel.Subnet) subnetStats {
 		outOfPoolAddresses = 0
 	}
 
+	c.addReclaimedLeases(subnet)
+
 	if subnet.GetFamily() == 4 {
 		return c.addIPv4Subnet(subnet, outOfPoolAddresses)
 	}
@@ -254,6 +290,14 @@ func (c *statisticsCounter) add(subnet *dbmodel.Subnet) subnetStats {
 	return c.addIPv6Subnet(subnet, outOfPoolAddresses, outOfPoolPrefixes)
 }
 
+// Adds the lease reclamation counters of the subnet to the global state.
+// They are not used to compute the subnet utilization. The counters are
+// missing when Kea doesn't return them.
+func (c *statisticsCounter) addReclaimedLeases(subnet *dbmodel.Subnet) {
+	c.global.totalReclaimedLeases.Add(sumStatLocalSubnetsIPv6(subnet, "reclaimed-leases", c.excludedDaemons))
+	c.global.totalReclaimedDeclinedAddresses.Add(sumStatLocalSubnetsIPv6(subnet, "reclaimed-declined-addresses", c.excludedDaemons))
+}
+
 // The resulting addresses counter will be a sum of the addresses returned by Kea for this
 // subnet and the outOfPool counter holding the number of the out-of-pool reservations
 // that Kea does not include in its statistics.
@@ -25,6 +25,8 @@ func TestCounterConstruction(t *testing.T) {
 	require.Zero(t, counter.global.totalDeclinedIPv6Addresses.ToInt64())
 	require.Zero(t, counter.global.totalDelegatedPrefixes.ToInt64())
 	require.Zero(t, counter.global.totalAssignedDelegatedPrefixes.ToInt64())
+	require.Zero(t, counter.global.totalReclaimedLeases.ToInt64())
+	require.Zero(t, counter.global.totalReclaimedDeclinedAddresses.ToInt64())
 	require.Len(t, counter.sharedNetworks, 0)
 }
 
@@ -698,6 +700,74 @@ func TestCounterSkipExcludedDaemonsIPv4(t *testing.T) {
 	require.Zero(t, counter.global.totalAssignedDelegatedPrefixes.ToInt64())
 }
 
+// Test that the lease reclamation counters are summed over the IPv4 and
+// IPv6 subnets, skipping the excluded daemons and the subnets lacking them.
+func TestCounterAddReclaimedLeases(t *testing.T) {
+	// Arrange
+	subnet4 := &dbmodel.Subnet{
+		Prefix: "192.0.2.0/24",
+		LocalSubnets: []*dbmodel.LocalSubnet{
+			{
+				Stats: dbmodel.SubnetStats{
+					"total-addresses":              uint64(100),
+					"reclaimed-leases":             uint64(7),
+					"reclaimed-declined-addresses": uint64(2),
+				},
+				DaemonID: 1,
+			},
+			{
+				Stats: dbmodel.SubnetStats{
+					"total-addresses":              uint64(100),
+					"reclaimed-leases":             uint64(1000),
+					"reclaimed-declined-addresses": uint64(1000),
+				},
+				DaemonID: 2,
+			},
+		},
+	}
+	subnet6 := &dbmodel.Subnet{
+		Prefix: "2001:db8:1::/64",
+		LocalSubnets: []*dbmodel.LocalSubnet{
+			{
+				Stats: dbmodel.SubnetStats{
+					"total-nas":                    uint64(100),
+					"reclaimed-leases":             big.NewInt(0).SetUint64(math.MaxUint64),
+					"reclaimed-declined-addresses": uint64(3),
+				},
+				DaemonID: 1,
+			},
+		},
+	}
+	// The subnet of the daemon not returning the counters.
+	subnetWithoutCounters := &dbmodel.Subnet{
+		Prefix: "192.0.3.0/24",
+		LocalSubnets: []*dbmodel.LocalSubnet{
+			{
+				Stats: dbmodel.SubnetStats{
+					"total-addresses": uint64(100),
+				},
+				DaemonID: 1,
+			},
+		},
+	}
+
+	counter := newStatisticsCounter()
+	counter.setExcludedDaemons([]int64{2})
+
+	// Act
+	counter.add(subnet4)
+	counter.add(subnet6)
+	counter.add(subnetWithoutCounters)
+
+	// Assert
+	expectedReclaimedLeases := big.NewInt(0).Add(big.NewInt(7), big.NewInt(0).SetUint64(math.MaxUint64))
+	require.Equal(t, expectedReclaimedLeases, counter.global.totalReclaimedLeases.ToBigInt())
+	require.EqualValues(t, 5, counter.global.totalReclaimedDeclinedAddresses.ToInt64())
+	// The counters don't affect the utilization.
+	require.EqualValues(t, 200, counter.global.totalIPv4Addresses.ToInt64())
+	require.EqualValues(t, 100, counter.global.totalIPv6Addresses.ToInt64())
+}
+
 // Checks if the excluded daemons are respected for IPv6 subnets.
 func TestCounterSkipExcludedDaemonsIPv6(t *testing.T) {
 	// Arrange
@@ -895,3 +965,152 @@ func TestCounterGetStatisticsForSharedNetwork(t *testing.T) {
 	require.EqualValues(t, 140, stats["total-pds"])
 	require.EqualValues(t, 40, stats["assigned-pds"])
 }
+
+// Checks if the IPv4 subnet is considered exhausted when all addresses,
+// including the out-of-pool reservations, are assigned.
+func TestCounterIPv4SubnetExhausted(t *testing.T) {
+	// Arrange
+	subnets := []dbmodel.Subnet{
+		{
+			ID:     1,
+			Prefix: "10.0.0.0/24",
+			LocalSubnets: []*dbmodel.LocalSubnet{
+				{
+					Stats: map[string]interface{}{
+						"total-addresses":    uint64(100),
+						"assigned-addresses": uint64(100),
+						"declined-addresses": uint64(0),
+					},
+				},
+			},
+		},
+		{
+			ID:     2,
+			Prefix: "10.0.1.0/24",
+			LocalSubnets: []*dbmodel.LocalSubnet{
+				{
+					Stats: map[string]interface{}{
+						"total-addresses":    uint64(100),
+						"assigned-addresses": uint64(100),
+						"declined-addresses": uint64(0),
+					},
+				},
+			},
+		},
+		{
+			ID:     3,
+			Prefix: "10.0.2.0/24",
+			LocalSubnets: []*dbmodel.LocalSubnet{
+				{
+					Stats: map[string]interface{}{
+						"total-addresses":    uint64(0),
+						"assigned-addresses": uint64(0),
+						"declined-addresses": uint64(0),
+					},
+				},
+			},
+		},
+	}
+
+	counter := newStatisticsCounter()
+	counter.setOutOfPoolAddresses(map[int64]uint64{2: 5})
+
+	// Act
+	stats1 := counter.add(&subnets[0])
+	stats2 := counter.add(&subnets[1])
+	stats3 := counter.add(&subnets[2])
+
+	// Assert
+	require.True(t, stats1.IsExhausted())
+	// The out-of-pool reservations are still available.
+	require.False(t, stats2.IsExhausted())
+	// The subnet without any addresses is not exhausted.
+	require.False(t, stats3.IsExhausted())
+}
+
+// Checks if the IPv6 subnet is considered exhausted when all addresses
+// or all delegated prefixes are assigned.
+func TestCounterIPv6SubnetExhausted(t *testing.T) {
+	// Arrange
+	subnets := []dbmodel.Subnet{
+		{
+			ID:     1,
+			Prefix: "2001:db8:1::/64",
+			LocalSubnets: []*dbmodel.LocalSubnet{
+				{
+					Stats: map[string]interface{}{
+						"total-nas":    uint64(100),
+						"assigned-nas": uint64(100),
+						"declined-nas": uint64(0),
+						"total-pds":    uint64(10),
+						"assigned-pds": uint64(1),
+					},
+				},
+			},
+			SharedNetworkID: 42,
+		},
+		{
+			ID:     2,
+			Prefix: "2001:db8:2::/64",
+			LocalSubnets: []*dbmodel.LocalSubnet{
+				{
+					Stats: map[string]interface{}{
+						"total-nas":    uint64(0),
+						"assigned-nas": uint64(0),
+						"declined-nas": uint64(0),
+						"total-pds":    uint64(10),
+						"assigned-pds": uint64(10),
+					},
+				},
+			},
+		},
+		{
+			ID:     3,
+			Prefix: "2001:db8:3::/64",
+			LocalSubnets: []*dbmodel.LocalSubnet{
+				{
+					Stats: map[string]interface{}{
+						"total-nas":    uint64(100),
+						"assigned-nas": uint64(100),
+						"declined-nas": uint64(0),
+						"total-pds":    uint64(10),
+						"assigned-pds": uint64(10),
+					},
+				},
+			},
+			SharedNetworkID: 42,
+		},
+		{
+			ID:     4,
+			Prefix: "2001:db8:4::/64",
+			LocalSubnets: []*dbmodel.LocalSubnet{
+				{
+					Stats: map[string]interface{}{
+						"total-nas":    uint64(100),
+						"assigned-nas": uint64(50),
+						"declined-nas": uint64(0),
+						"total-pds":    uint64(0),
+						"assigned-pds": uint64(0),
+					},
+				},
+			},
+		},
+	}
+
+	counter := newStatisticsCounter()
+	counter.setOutOfPoolAddresses(map[int64]uint64{3: 1})
+	counter.setOutOfPoolPrefixes(map[int64]uint64{3: 1})
+
+	// Act
+	stats1 := counter.add(&subnets[0])
+	stats2 := counter.add(&subnets[1])
+	stats3 := counter.add(&subnets[2])
+	stats4 := counter.add(&subnets[3])
+
+	// Assert
+	require.True(t, stats1.IsExhausted())
+	require.True(t, stats2.IsExhausted())
+	require.False(t, stats3.IsExhausted())
+	require.False(t, stats4.IsExhausted())
+	require.False(t, counter.sharedNetworks[42].IsExhausted())
+}
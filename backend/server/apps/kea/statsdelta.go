@@ -0,0 +1,79 @@
+package kea
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Represents the global statistics recorded at a given time, e.g.,
+// assigned-addresses or total-nas aggregated over all subnets in the fleet.
+type GlobalStatsSample struct {
+	SampledAt time.Time           // time the statistics were recorded
+	Stats     map[string]*big.Int // statistic values by name
+}
+
+// The difference between the global statistics recorded at two points in
+// time. The From and To hold the times of the samples used to compute the
+// deltas, which may differ from the requested times. The delta is negative
+// when a statistic decreased.
+type GlobalStatsDelta struct {
+	From   time.Time
+	To     time.Time
+	Deltas map[string]*big.Int
+}
+
+// Computes the change of the global statistics between the specified times
+// using the statistics history. The samples don't have to be sorted. The
+// baseline is the latest sample recorded at or before the from time. If
+// there is no such sample (the history starts later), the earliest sample
+// recorded before the to time is used instead. The end sample is the latest
+// sample recorded at or before the to time. It returns nil if there are no
+// two distinct samples in the history to compare. The statistics missing
+// in any of the compared samples or having nil values are not included in
+// the returned deltas.
+func ComputeGlobalStatsDelta(history []GlobalStatsSample, from, to time.Time) (*GlobalStatsDelta, error) {
+	if from.After(to) {
+		return nil, errors.Errorf("start time %s of the statistics delta is after the end time %s",
+			from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+
+	var baseline, earliest, end *GlobalStatsSample
+	for i := range history {
+		sample := &history[i]
+		if sample.SampledAt.After(to) {
+			continue
+		}
+		if end == nil || sample.SampledAt.After(end.SampledAt) {
+			end = sample
+		}
+		if !sample.SampledAt.After(from) {
+			if baseline == nil || sample.SampledAt.After(baseline.SampledAt) {
+				baseline = sample
+			}
+		} else if earliest == nil || sample.SampledAt.Before(earliest.SampledAt) {
+			earliest = sample
+		}
+	}
+	if baseline == nil {
+		baseline = earliest
+	}
+	if baseline == nil || end == nil || baseline == end {
+		return nil, nil
+	}
+
+	delta := &GlobalStatsDelta{
+		From:   baseline.SampledAt,
+		To:     end.SampledAt,
+		Deltas: make(map[string]*big.Int),
+	}
+	for name, endValue := range end.Stats {
+		baselineValue, ok := baseline.Stats[name]
+		if !ok || baselineValue == nil || endValue == nil {
+			continue
+		}
+		delta.Deltas[name] = new(big.Int).Sub(endValue, baselineValue)
+	}
+	return delta, nil
+}
@@ -0,0 +1,155 @@
+package kea
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	require "github.com/stretchr/testify/require"
+)
+
+// Returns the synthetic global statistics history with the samples
+// recorded weekly, starting from the specified time.
+func getGlobalStatsTestHistory(start time.Time) []GlobalStatsSample {
+	hugeTotal, _ := new(big.Int).SetString("340282366920938463463374607431768211456", 10)
+	hugeTotalGrown, _ := new(big.Int).SetString("340282366920938463463374607431768215552", 10)
+	return []GlobalStatsSample{
+		{
+			SampledAt: start.Add(14 * 24 * time.Hour),
+			Stats: map[string]*big.Int{
+				"assigned-addresses": big.NewInt(1500),
+				"declined-addresses": big.NewInt(3),
+				"total-nas":          hugeTotalGrown,
+				"assigned-pds":       big.NewInt(10),
+			},
+		},
+		{
+			SampledAt: start,
+			Stats: map[string]*big.Int{
+				"assigned-addresses": big.NewInt(1000),
+				"declined-addresses": big.NewInt(5),
+				"total-nas":          hugeTotal,
+				"assigned-pds":       nil,
+			},
+		},
+		{
+			SampledAt: start.Add(7 * 24 * time.Hour),
+			Stats: map[string]*big.Int{
+				"assigned-addresses": big.NewInt(1200),
+				"declined-addresses": big.NewInt(4),
+				"total-nas":          hugeTotal,
+				"assigned-pds":       big.NewInt(7),
+			},
+		},
+	}
+}
+
+// Test that the deltas are computed between the samples recorded at the
+// specified times.
+func TestComputeGlobalStatsDelta(t *testing.T) {
+	// Arrange
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := getGlobalStatsTestHistory(start)
+
+	// Act
+	delta, err := ComputeGlobalStatsDelta(history, start.Add(7*24*time.Hour), start.Add(14*24*time.Hour))
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, delta)
+	require.Equal(t, start.Add(7*24*time.Hour), delta.From)
+	require.Equal(t, start.Add(14*24*time.Hour), delta.To)
+	require.Len(t, delta.Deltas, 4)
+	require.EqualValues(t, 300, delta.Deltas["assigned-addresses"].Int64())
+	require.EqualValues(t, -1, delta.Deltas["declined-addresses"].Int64())
+	require.EqualValues(t, 4096, delta.Deltas["total-nas"].Int64())
+	require.EqualValues(t, 3, delta.Deltas["assigned-pds"].Int64())
+}
+
+// Test that the latest samples recorded before the specified times are
+// used and the statistics with missing values are skipped.
+func TestComputeGlobalStatsDeltaBetweenSamples(t *testing.T) {
+	// Arrange
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := getGlobalStatsTestHistory(start)
+
+	// Act
+	delta, err := ComputeGlobalStatsDelta(history, start.Add(time.Hour), start.Add(20*24*time.Hour))
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, delta)
+	require.Equal(t, start, delta.From)
+	require.Equal(t, start.Add(14*24*time.Hour), delta.To)
+	require.Len(t, delta.Deltas, 3)
+	require.EqualValues(t, 500, delta.Deltas["assigned-addresses"].Int64())
+	require.EqualValues(t, -2, delta.Deltas["declined-addresses"].Int64())
+	require.EqualValues(t, 4096, delta.Deltas["total-nas"].Int64())
+	require.NotContains(t, delta.Deltas, "assigned-pds")
+}
+
+// Test that the earliest sample is used as a baseline when the history
+// starts after the specified start time.
+func TestComputeGlobalStatsDeltaHistoryStartsLater(t *testing.T) {
+	// Arrange
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := getGlobalStatsTestHistory(start)
+
+	// Act
+	delta, err := ComputeGlobalStatsDelta(history, start.Add(-30*24*time.Hour), start.Add(10*24*time.Hour))
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, delta)
+	require.Equal(t, start, delta.From)
+	require.Equal(t, start.Add(7*24*time.Hour), delta.To)
+	require.EqualValues(t, 200, delta.Deltas["assigned-addresses"].Int64())
+}
+
+// Test that nil is returned when there are no two samples to compare.
+func TestComputeGlobalStatsDeltaMissingHistory(t *testing.T) {
+	// Arrange
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := getGlobalStatsTestHistory(start)
+
+	t.Run("empty history", func(t *testing.T) {
+		// Act
+		delta, err := ComputeGlobalStatsDelta(nil, start, start.Add(time.Hour))
+
+		// Assert
+		require.NoError(t, err)
+		require.Nil(t, delta)
+	})
+
+	t.Run("before history", func(t *testing.T) {
+		// Act
+		delta, err := ComputeGlobalStatsDelta(history, start.Add(-2*time.Hour), start.Add(-time.Hour))
+
+		// Assert
+		require.NoError(t, err)
+		require.Nil(t, delta)
+	})
+
+	t.Run("after history", func(t *testing.T) {
+		// Act
+		delta, err := ComputeGlobalStatsDelta(history, start.Add(30*24*time.Hour), start.Add(40*24*time.Hour))
+
+		// Assert
+		require.NoError(t, err)
+		require.Nil(t, delta)
+	})
+}
+
+// Test that an error is returned when the start time is after the end time.
+func TestComputeGlobalStatsDeltaInvalidRange(t *testing.T) {
+	// Arrange
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := getGlobalStatsTestHistory(start)
+
+	// Act
+	delta, err := ComputeGlobalStatsDelta(history, start.Add(14*24*time.Hour), start)
+
+	// Assert
+	require.Error(t, err)
+	require.Nil(t, delta)
+}
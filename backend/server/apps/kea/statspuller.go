@@ -2,8 +2,13 @@ package kea
 
 import (
 	"context"
+	"fmt"
 	"math/big"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-pg/pg/v10"
 	"github.com/pkg/errors"
@@ -11,6 +16,8 @@ import (
 	keactrl "isc.org/stork/appctrl/kea"
 	"isc.org/stork/server/agentcomm"
 	dbmodel "isc.org/stork/server/database/model"
+	"isc.org/stork/server/eventcenter"
+	storkutil "isc.org/stork/util"
 )
 
 // Statistics puller is responsible for fetching the data using the Kea
@@ -18,12 +25,115 @@ import (
 type StatsPuller struct {
 	*agentcomm.PeriodicPuller
 	*RpsWorker
+	EventCenter eventcenter.EventCenter
+	// Indicates whether the lease statistics grouped by shared network
+	// should be requested from Kea. The shared network statistics are
+	// summed from the subnet statistics if Kea doesn't return them.
+	SharedNetworkStats bool
+	// Indicates whether the pool-level lease statistics should be requested
+	// from Kea to find the most utilized pools in the subnets. The subnet
+	// utilization is used if Kea doesn't return them.
+	PoolStats bool
+	// Maximum number of the subnets whose lease statistics are requested
+	// with a single stat-lease4-get or stat-lease6-get command. The
+	// command is split into multiple calls for the ranges of the subnet
+	// IDs when a daemon has more subnets. It limits the size of the
+	// responses held in memory. Zero means no limit.
+	MaxSubnetsPerCall int64
+	// Indicates whether the lease statistics commands for the batches of
+	// the subnets should be sent in the same call as the other commands
+	// rather than in separate calls. It reduces the number of round-trips
+	// to the app at the cost of holding all responses in memory at once.
+	CombineCommands bool
+	// Names of the subnet statistics stored in the database. All
+	// statistics are stored if it is nil.
+	storedStats map[string]bool
+	// Shared network statistics returned by Kea during the current pull
+	// by shared network and daemon ID.
+	sharedNetworkStats map[sharedNetworkStatsKey]map[int64]*sharedNetworkStats
+	// Last seen timestamps of the lease statistics by daemon ID.
+	statsTimestamps map[int64]*statsTimestampState
+	// Number of consecutive failed pulls by app ID.
+	appErrors map[int64]int
+	// Utilization alert states of the subnets by subnet ID.
+	utilizationAlerts map[int64]utilizationAlertState
+	// Time of the last global statistics refresh. The global statistics
+	// are refreshed less often than the subnet statistics when the
+	// kea_global_stats_interval setting is specified.
+	globalStatsRefreshedAt time.Time
+	// Clock used to schedule the global statistics refresh.
+	clock storkutil.Clock
+	// Serializes the stats pulls from all apps and from the apps with the
+	// overridden intervals. It is held for the whole pull, including the
+	// communication with Kea.
+	pullMutex sync.Mutex
+	// Protects the internal state included in the diagnostic dump. It is
+	// held only while the state is updated, so the diagnostic dump doesn't
+	// wait for the ongoing pull to complete.
+	stateMutex sync.Mutex
+	// Executors pulling the stats from the apps with the intervals
+	// overridden in the kea_stats_puller_app_intervals setting by app ID.
+	// These apps are skipped by the main puller.
+	appPullers map[int64]*storkutil.PeriodicExecutor
+	// Protects the app pullers from being started and stopped
+	// concurrently.
+	appPullersMutex sync.Mutex
+	// Executor periodically starting and stopping the app pullers
+	// according to the kea_stats_puller_app_intervals setting. It runs
+	// independently of the main puller, so the app pullers run even if
+	// the main puller is disabled.
+	appPullersSyncer *storkutil.PeriodicExecutor
+}
+
+// Interval in seconds at which the app pullers are synchronized with the
+// kea_stats_puller_app_intervals setting.
+const appPullersSyncInterval int64 = 60
+
+// Snapshot of the stats puller internal state used for diagnostics. It
+// is serialized to JSON and included in the dumps.
+type StatsPullerState struct {
+	LastInvokedAt          time.Time
+	LastFinishedAt         time.Time
+	GlobalStatsRefreshedAt time.Time
+	PreviousRps            map[int64]StatSample
+	RpsCycle               int64
+	AppErrors              map[int64]int
+	StatsTimestamps        map[int64]StatsTimestampState
+}
+
+// Last seen timestamp of the lease statistics returned by a daemon.
+type StatsTimestampState struct {
+	Timestamp      string
+	UnchangedPolls int
+	Reported       bool
+}
+
+// Number of consecutive polls returning the same lease statistics timestamp
+// after which the statistics are considered stale.
+const staleStatsPollsThreshold = 3
+
+// Holds the last seen timestamp of the lease statistics returned by a
+// daemon and the number of consecutive polls it didn't change.
+type statsTimestampState struct {
+	timestamp      string
+	unchangedPolls int
+	reported       bool
 }
 
 // Create a StatsPuller object that in background pulls Kea stats about leases.
 // Beneath it spawns a goroutine that pulls stats periodically from Kea apps (that are stored in database).
-func NewStatsPuller(db *pg.DB, agents agentcomm.ConnectedAgents) (*StatsPuller, error) {
-	statsPuller := &StatsPuller{}
+// The event center is used to report the subnets with exhausted addresses or
+// delegated prefixes. The clock is used in the time-dependent computations,
+// e.g., the RPS and the global statistics refresh schedule. If it is nil,
+// the real clock is used.
+func NewStatsPuller(db *pg.DB, agents agentcomm.ConnectedAgents, eventCenter eventcenter.EventCenter, clock storkutil.Clock) (*StatsPuller, error) {
+	if clock == nil {
+		clock = storkutil.NewRealClock()
+	}
+	statsPuller := &StatsPuller{
+		EventCenter: eventCenter,
+		clock:       clock,
+	}
 	periodicPuller, err := agentcomm.NewPeriodicPuller(db, agents, "Kea Stats puller", "kea_stats_puller_interval",
 		statsPuller.pullStats)
 	if err != nil {
@@ -32,43 +142,398 @@ func NewStatsPuller(db *pg.DB, agents agentcomm.ConnectedAgents) (*StatsPuller,
 	statsPuller.PeriodicPuller = periodicPuller
 
 	// Create RpsWorker instance
-	rpsWorker, err := NewRpsWorker(db)
+	rpsWorker, err := NewRpsWorker(db, clock)
 	if err != nil {
 		return nil, err
 	}
 	statsPuller.RpsWorker = rpsWorker
 
+	// Start the app pullers and keep them synchronized with the setting.
+	statsPuller.syncAppPullers(statsPuller.getAppPullIntervals())
+	appPullersSyncer, err := storkutil.NewPeriodicExecutor("Kea Stats app pullers synchronizer",
+		func() error {
+			statsPuller.syncAppPullers(statsPuller.getAppPullIntervals())
+			return nil
+		},
+		func() (int64, error) {
+			return appPullersSyncInterval, nil
+		})
+	if err != nil {
+		statsPuller.Shutdown()
+		return nil, err
+	}
+	statsPuller.appPullersSyncer = appPullersSyncer
+
 	return statsPuller, nil
 }
 
-// Shutdown StatsPuller. It stops goroutine that pulls stats.
+// Returns a snapshot of the puller internal state for diagnostics, e.g.,
+// to find out why the statistics are not updated. It doesn't wait for the
+// ongoing pull to complete.
+func (statsPuller *StatsPuller) GetDiagnosticState() *StatsPullerState {
+	statsPuller.stateMutex.Lock()
+	defer statsPuller.stateMutex.Unlock()
+
+	state := &StatsPullerState{
+		GlobalStatsRefreshedAt: statsPuller.globalStatsRefreshedAt,
+		PreviousRps:            make(map[int64]StatSample),
+		AppErrors:              make(map[int64]int),
+		StatsTimestamps:        make(map[int64]StatsTimestampState),
+	}
+	if statsPuller.PeriodicPuller != nil {
+		state.LastInvokedAt = statsPuller.GetLastInvokedAt()
+		state.LastFinishedAt = statsPuller.GetLastFinishedAt()
+	}
+	if statsPuller.RpsWorker != nil {
+		state.PreviousRps, state.RpsCycle = statsPuller.RpsWorker.getPreviousRps()
+	}
+	for appID, count := range statsPuller.appErrors {
+		state.AppErrors[appID] = count
+	}
+	for daemonID, timestamp := range statsPuller.statsTimestamps {
+		state.StatsTimestamps[daemonID] = StatsTimestampState{
+			Timestamp:      timestamp.timestamp,
+			UnchangedPolls: timestamp.unchangedPolls,
+			Reported:       timestamp.reported,
+		}
+	}
+	return state
+}
+
+// Sets the cron expression specifying when the stats are pulled, e.g.,
+// */5 * * * * to pull them every 5 minutes on the minute. The pulls are
+// aligned to the wall-clock time instead of the interval. The empty
+// expression restores pulling at the interval.
+func (statsPuller *StatsPuller) SetCronSchedule(expression string) error {
+	if strings.TrimSpace(expression) == "" {
+		statsPuller.SetSchedule(nil)
+		return nil
+	}
+	schedule, err := storkutil.ParseCronSchedule(expression)
+	if err != nil {
+		return errors.WithMessage(err, "invalid stats puller schedule")
+	}
+	statsPuller.SetSchedule(schedule)
+	return nil
+}
+
+// Sets the names of the subnet statistics stored in the database, e.g.,
+// total-addresses and assigned-addresses. Other statistics returned by Kea
+// are dropped to reduce the database write volume. The pool-level
+// statistics are matched by their names without the pool prefix, e.g.,
+// assigned-addresses matches pool[0].assigned-addresses. The empty list
+// restores storing all statistics.
+func (statsPuller *StatsPuller) SetStoredStats(names []string) {
+	statsPuller.storedStats = nil
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if statsPuller.storedStats == nil {
+			statsPuller.storedStats = make(map[string]bool)
+		}
+		statsPuller.storedStats[name] = true
+	}
+}
+
+// Removes the statistics not configured to be stored from the subnet
+// statistics.
+func (statsPuller *StatsPuller) filterStats(stats dbmodel.SubnetStats) {
+	if statsPuller.storedStats == nil {
+		return
+	}
+	for name := range stats {
+		if !statsPuller.storedStats[poolStatPrefixPattern.ReplaceAllString(name, "")] {
+			delete(stats, name)
+		}
+	}
+}
+
+// Checks if the global statistics should be refreshed during the current
+// pull. The global statistics aggregated over all subnets are refreshed at
+// the specified interval, independently of the subnet statistics pulled at
+// the puller's interval. The zero interval means that they are refreshed
+// during each pull. They are always refreshed during the first pull.
+func (statsPuller *StatsPuller) isGlobalStatsRefreshDue(interval time.Duration) bool {
+	if interval <= 0 || statsPuller.globalStatsRefreshedAt.IsZero() {
+		return true
+	}
+	return !statsPuller.clock.Now().Before(statsPuller.globalStatsRefreshedAt.Add(interval))
+}
+
+// Shutdown StatsPuller. It stops goroutine that pulls stats and the
+// goroutines pulling them from the apps with the overridden intervals.
+// The main puller and the app pullers synchronizer are stopped first so
+// they don't start the app pullers again.
 func (statsPuller *StatsPuller) Shutdown() {
+	if statsPuller.appPullersSyncer != nil {
+		statsPuller.appPullersSyncer.Shutdown()
+	}
 	statsPuller.PeriodicPuller.Shutdown()
+
+	statsPuller.appPullersMutex.Lock()
+	defer statsPuller.appPullersMutex.Unlock()
+	for appID, appPuller := range statsPuller.appPullers {
+		appPuller.Shutdown()
+		delete(statsPuller.appPullers, appID)
+	}
+}
+
+// Parses the stats pull intervals overriding the global interval for the
+// selected apps. The specification is a comma-separated list of the app
+// IDs and the intervals in seconds, e.g., 3:300,5:60. The zero interval
+// disables pulling the stats from the app.
+func parseAppPullIntervals(spec string) (map[int64]int64, error) {
+	intervals := make(map[int64]int64)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		appIDText, intervalText, found := strings.Cut(entry, ":")
+		if !found {
+			return nil, errors.Errorf("invalid app stats pull interval %s, expected app-id:seconds", entry)
+		}
+		appID, err := strconv.ParseInt(strings.TrimSpace(appIDText), 10, 64)
+		if err != nil || appID <= 0 {
+			return nil, errors.Errorf("invalid app ID in the app stats pull interval %s", entry)
+		}
+		interval, err := strconv.ParseInt(strings.TrimSpace(intervalText), 10, 64)
+		if err != nil || interval < 0 {
+			return nil, errors.Errorf("invalid interval in the app stats pull interval %s", entry)
+		}
+		intervals[appID] = interval
+	}
+	return intervals, nil
+}
+
+// Returns the stats pull intervals overriding the global interval by app
+// ID. It returns nil if the setting is invalid, so all apps are pulled
+// at the global interval.
+func (statsPuller *StatsPuller) getAppPullIntervals() map[int64]int64 {
+	spec, err := dbmodel.GetSettingStr(statsPuller.DB, "kea_stats_puller_app_intervals")
+	if err != nil {
+		log.WithError(err).Error("Cannot get the app stats pull intervals; pulling all apps at the global interval")
+		return nil
+	}
+	intervals, err := parseAppPullIntervals(spec)
+	if err != nil {
+		log.WithError(err).Error("Invalid app stats pull intervals; pulling all apps at the global interval")
+		return nil
+	}
+	return intervals
+}
+
+// Starts the pullers for the apps with the overridden intervals and stops
+// the pullers for the apps whose intervals are no longer overridden. The
+// app pullers read their intervals from the setting, so the changed
+// intervals are applied without restarting them.
+func (statsPuller *StatsPuller) syncAppPullers(intervals map[int64]int64) {
+	statsPuller.appPullersMutex.Lock()
+	defer statsPuller.appPullersMutex.Unlock()
+
+	for appID, appPuller := range statsPuller.appPullers {
+		if _, ok := intervals[appID]; !ok {
+			appPuller.Shutdown()
+			delete(statsPuller.appPullers, appID)
+		}
+	}
+	for appID := range intervals {
+		if _, ok := statsPuller.appPullers[appID]; ok {
+			continue
+		}
+		appID := appID
+		appPuller, err := storkutil.NewPeriodicExecutor(fmt.Sprintf("Kea Stats puller for app %d", appID),
+			func() error {
+				return statsPuller.pullAppStats(appID)
+			},
+			func() (int64, error) {
+				return statsPuller.getAppPullIntervals()[appID], nil
+			})
+		if err != nil {
+			log.WithError(err).Errorf("Cannot start the stats puller for app %d", appID)
+			continue
+		}
+		if statsPuller.appPullers == nil {
+			statsPuller.appPullers = make(map[int64]*storkutil.PeriodicExecutor)
+		}
+		statsPuller.appPullers[appID] = appPuller
+	}
+}
+
+// Pulls the stats from the app with the overridden interval. The subnet
+// statistics are stored in the database, and the utilization of the app's
+// subnets and their shared networks is updated.
+func (statsPuller *StatsPuller) pullAppStats(appID int64) error {
+	statsPuller.pullMutex.Lock()
+	defer statsPuller.pullMutex.Unlock()
+
+	dbApp, err := dbmodel.GetAppByID(statsPuller.DB, appID)
+	if err != nil {
+		return err
+	}
+	if dbApp == nil || dbApp.Type != dbmodel.AppTypeKea {
+		log.Warnf("Kea app %d with the overridden stats pull interval not found", appID)
+		return nil
+	}
+	pullStartedAt := statsPuller.clock.Now()
+	if err = statsPuller.pullDBAppStats(dbApp); err != nil {
+		return err
+	}
+	return statsPuller.updateAppUtilization(dbApp, pullStartedAt)
+}
+
+// Pulls the stats from the Kea app with the specified ID immediately, e.g.,
+// when a user requests refreshing them, without waiting for the next pull
+// and without pulling the other apps. The statistics are stored in the
+// database, and the utilization of the app's subnets and their shared
+// networks is updated immediately. It returns an error if the app doesn't
+// exist or none of its active DHCP daemons has the statistics hook library.
+func (statsPuller *StatsPuller) PullStatsForApp(appID int64) error {
+	statsPuller.pullMutex.Lock()
+	defer statsPuller.pullMutex.Unlock()
+
+	dbApp, err := dbmodel.GetAppByID(statsPuller.DB, appID)
+	if err != nil {
+		return err
+	}
+	if dbApp == nil || dbApp.Type != dbmodel.AppTypeKea {
+		return errors.Errorf("Kea app %d not found", appID)
+	}
+
+	hasStatsDaemons := false
+	for _, daemon := range dbApp.Daemons {
+		if isStatsDaemon(daemon) {
+			hasStatsDaemons = true
+			break
+		}
+	}
+	if !hasStatsDaemons {
+		return errors.Errorf("Kea app %d has no active DHCP daemons with the libdhcp_stat_cmds hook", appID)
+	}
+	pullStartedAt := statsPuller.clock.Now()
+	if err = statsPuller.pullDBAppStats(dbApp); err != nil {
+		return err
+	}
+	return statsPuller.updateAppUtilization(dbApp, pullStartedAt)
+}
+
+// Pulls the stats from the specified app and tracks the errors. The stats
+// previously returned by the app are discarded. The caller must hold the
+// pull lock.
+func (statsPuller *StatsPuller) pullDBAppStats(dbApp *dbmodel.App) error {
+	appID := dbApp.ID
+	statsPuller.removeSharedNetworkStats(func(daemonID int64) bool {
+		for _, daemon := range dbApp.Daemons {
+			if daemon.ID == daemonID {
+				return true
+			}
+		}
+		return false
+	})
+
+	err := statsPuller.getStatsFromApp(dbApp)
+
+	statsPuller.stateMutex.Lock()
+	if statsPuller.appErrors == nil {
+		statsPuller.appErrors = make(map[int64]int)
+	}
+	if err != nil {
+		statsPuller.appErrors[appID]++
+	} else {
+		delete(statsPuller.appErrors, appID)
+	}
+	statsPuller.stateMutex.Unlock()
+
+	if err != nil {
+		log.Errorf("Error occurred while getting stats from app %d: %+v", appID, err)
+		return err
+	}
+	log.Printf("Completed pulling lease stats from Kea app %d", appID)
+	return nil
 }
 
 // Pull stats periodically for all Kea apps which Stork is monitoring. The function returns
 // last encountered error.
 func (statsPuller *StatsPuller) pullStats() error {
+	// The apps with the overridden intervals are pulled by their own
+	// pullers. They must be synchronized before taking the pull lock
+	// because the stopped pullers may be waiting for the lock.
+	appIntervals := statsPuller.getAppPullIntervals()
+	statsPuller.syncAppPullers(appIntervals)
+
+	statsPuller.pullMutex.Lock()
+	defer statsPuller.pullMutex.Unlock()
+
+	// The statistics collected since this time are up to date.
+	pullStartedAt := statsPuller.clock.Now()
+
 	// get list of all kea apps from database
 	dbApps, err := dbmodel.GetAppsByType(statsPuller.DB, dbmodel.AppTypeKea)
 	if err != nil {
 		return err
 	}
 
+	// Keep the shared network statistics returned by the apps pulled on
+	// their own schedule.
+	var scheduledDaemonIDs []int64
+	for _, dbApp := range dbApps {
+		if _, ok := appIntervals[dbApp.ID]; ok {
+			for _, daemon := range dbApp.Daemons {
+				scheduledDaemonIDs = append(scheduledDaemonIDs, daemon.ID)
+			}
+		}
+	}
+	statsPuller.removeSharedNetworkStats(func(daemonID int64) bool {
+		for _, scheduledDaemonID := range scheduledDaemonIDs {
+			if daemonID == scheduledDaemonID {
+				return false
+			}
+		}
+		return true
+	})
+
 	// get lease stats from each kea app
 	var lastErr error
 	appsOkCnt := 0
+	appsCnt := 0
+	appErrors := make(map[int64]int)
 	for _, dbApp := range dbApps {
+		if _, ok := appIntervals[dbApp.ID]; ok {
+			if count, ok := statsPuller.appErrors[dbApp.ID]; ok {
+				appErrors[dbApp.ID] = count
+			}
+			continue
+		}
+		appsCnt++
 		dbApp2 := dbApp
 		err := statsPuller.getStatsFromApp(&dbApp2)
 		if err != nil {
 			lastErr = err
+			appErrors[dbApp.ID] = statsPuller.appErrors[dbApp.ID] + 1
 			log.Errorf("Error occurred while getting stats from app %d: %+v", dbApp.ID, err)
 		} else {
 			appsOkCnt++
 		}
 	}
-	log.Printf("Completed pulling lease stats from Kea apps: %d/%d succeeded", appsOkCnt, len(dbApps))
+	statsPuller.stateMutex.Lock()
+	statsPuller.appErrors = appErrors
+	statsPuller.stateMutex.Unlock()
+	log.Printf("Completed pulling lease stats from Kea apps: %d/%d succeeded", appsOkCnt, appsCnt)
+
+	// Evict the RPS values of the removed daemons. The values of the
+	// daemons pulled on their own schedule are retained.
+	if statsPuller.RpsWorker != nil {
+		statsPuller.RpsWorker.retain(scheduledDaemonIDs)
+		var daemonIDs []int64
+		for _, dbApp := range dbApps {
+			for _, daemon := range dbApp.Daemons {
+				daemonIDs = append(daemonIDs, daemon.ID)
+			}
+		}
+		statsPuller.RpsWorker.EndCycle(daemonIDs)
+	}
 
 	// estimate addresses utilization for subnets
 	subnets, err := dbmodel.GetSubnetsWithLocalSubnets(statsPuller.DB)
@@ -80,8 +545,101 @@ func (statsPuller *StatsPuller) pullStats() error {
 		return lastErr
 	}
 
+	var daemons []*dbmodel.Daemon
+	for _, dbApp := range dbApps {
+		daemons = append(daemons, dbApp.Daemons...)
+	}
+	if err = statsPuller.updateUtilization(daemons, subnets, pullStartedAt, true); err != nil {
+		lastErr = err
+	}
+	return lastErr
+}
+
+// Returns the subnets of the specified app and the other subnets belonging
+// to the same shared networks. The shared network utilization is computed
+// from all its subnets, so they must be included when the utilization is
+// updated for a single app.
+func selectAppSubnets(subnets []*dbmodel.Subnet, dbApp *dbmodel.App) []*dbmodel.Subnet {
+	daemonIDs := make(map[int64]bool)
+	for _, daemon := range dbApp.Daemons {
+		daemonIDs[daemon.ID] = true
+	}
+	selected := make(map[int64]bool)
+	sharedNetworkIDs := make(map[int64]bool)
+	for _, sn := range subnets {
+		for _, ls := range sn.LocalSubnets {
+			if daemonIDs[ls.DaemonID] {
+				selected[sn.ID] = true
+				if sn.SharedNetworkID != 0 {
+					sharedNetworkIDs[sn.SharedNetworkID] = true
+				}
+				break
+			}
+		}
+	}
+	var appSubnets []*dbmodel.Subnet
+	for _, sn := range subnets {
+		if selected[sn.ID] || (sn.SharedNetworkID != 0 && sharedNetworkIDs[sn.SharedNetworkID]) {
+			appSubnets = append(appSubnets, sn)
+		}
+	}
+	return appSubnets
+}
+
+// Estimates the utilization of the subnets of the specified app and of
+// their shared networks from the statistics stored in the database. It
+// is used after pulling the stats from a single app, so the utilization
+// is up to date without waiting for the next pass of the main puller.
+// The caller must hold the pull lock.
+func (statsPuller *StatsPuller) updateAppUtilization(dbApp *dbmodel.App, pullStartedAt time.Time) error {
+	subnets, err := dbmodel.GetSubnetsWithLocalSubnets(statsPuller.DB)
+	if err != nil {
+		return err
+	}
+	subnets = selectAppSubnets(subnets, dbApp)
+	if len(subnets) == 0 {
+		return nil
+	}
+
+	// The daemons of all apps are needed to select the authoritative
+	// daemons of the HA services spanning multiple apps.
+	dbApps, err := dbmodel.GetAppsByType(statsPuller.DB, dbmodel.AppTypeKea)
+	if err != nil {
+		return err
+	}
+	var daemons []*dbmodel.Daemon
+	for _, app := range dbApps {
+		daemons = append(daemons, app.Daemons...)
+	}
+	return statsPuller.updateUtilization(daemons, subnets, pullStartedAt, false)
+}
+
+// Estimates the utilization of the specified subnets and their shared
+// networks and stores it in the database. It reports the exhausted subnets
+// and the subnets crossing the utilization watermarks. The daemons are
+// used to select the authoritative daemons of the HA services, preferably
+// the ones whose statistics were collected since the pullStartedAt. The
+// allSubnets flag indicates that the subnets include all subnets. In this
+// case, the global statistics are also refreshed when due. Otherwise, the
+// utilization alert states of the other subnets are retained. It returns
+// the last encountered error.
+func (statsPuller *StatsPuller) updateUtilization(daemons []*dbmodel.Daemon, subnets []*dbmodel.Subnet, pullStartedAt time.Time, allSubnets bool) error {
+	var lastErr error
 	counter := newStatisticsCounter()
 
+	// The global statistics may be refreshed less often than the subnet
+	// statistics to reduce the cost of the aggregation on large fleets.
+	// They are never refreshed from a subset of the subnets.
+	refreshGlobalStats := false
+	if allSubnets {
+		globalStatsInterval, err := dbmodel.GetSettingInt(statsPuller.DB, "kea_global_stats_interval")
+		if err != nil {
+			log.WithError(err).Error("Cannot get the global statistics refresh interval; refreshing them during each pull")
+			globalStatsInterval = 0
+		}
+		refreshGlobalStats = statsPuller.isGlobalStatsRefreshDue(time.Duration(globalStatsInterval) * time.Second)
+	}
+
 	// The total IPv4 and IPv6 addresses statistics returned by Kea exclude
 	// out-of-pool reservations, yielding possibly incorrect utilization.
 	// The utilization can be corrected by including the out-of-pool
@@ -98,32 +656,60 @@ func (statsPuller *StatsPuller) pullStats() error {
 	}
 	counter.setOutOfPoolPrefixes(outOfPoolCounters)
 
-	// Assume that all global reservations are out-of-pool for all subnets.
-	outOfPoolGlobalIPv4Addresses, outOfPoolGlobalIPv6Addresses, outOfPoolGlobalDelegatedPrefixes, err := dbmodel.CountGlobalReservations(statsPuller.DB)
-	if err != nil {
-		return err
-	}
+	if refreshGlobalStats {
+		// Assume that all global reservations are out-of-pool for all subnets.
+		outOfPoolGlobalIPv4Addresses, outOfPoolGlobalIPv6Addresses, outOfPoolGlobalDelegatedPrefixes, err := dbmodel.CountGlobalReservations(statsPuller.DB)
+		if err != nil {
+			return err
+		}
 
-	counter.global.totalIPv4Addresses.AddUint64(outOfPoolGlobalIPv4Addresses)
-	counter.global.totalIPv6Addresses.AddUint64(outOfPoolGlobalIPv6Addresses)
-	counter.global.totalDelegatedPrefixes.AddUint64(outOfPoolGlobalDelegatedPrefixes)
+		counter.global.totalIPv4Addresses.AddUint64(outOfPoolGlobalIPv4Addresses)
+		counter.global.totalIPv6Addresses.AddUint64(outOfPoolGlobalIPv6Addresses)
+		counter.global.totalDelegatedPrefixes.AddUint64(outOfPoolGlobalDelegatedPrefixes)
+	}
 
 	// The HA servers share the same lease database and return the same
-	// statistics. The statistics from the passive daemons are excluded from
-	// calculations to avoid counting the same lease multiple times. The
-	// calculator uses only the active daemon statistics because the active
-	// daemon's database is overriding others.
-	excludedDaemons, err := dbmodel.GetPassiveHADaemonIDs(statsPuller.DB)
+	// statistics. The statistics from the non-authoritative daemons are
+	// excluded from calculations to avoid counting the same lease multiple
+	// times. The calculator uses only the statistics of a single daemon in
+	// each HA service, preferably the one whose statistics are up to date.
+	excludedDaemons := getNonAuthoritativeHADaemonIDs(statsPuller.DB, daemons, subnets, pullStartedAt)
+	counter.setExcludedDaemons(excludedDaemons)
+
+	// The subnet utilization crossing the watermarks is reported.
+	watermarks, err := getUtilizationWatermarks(statsPuller.DB)
 	if err != nil {
-		return err
+		log.WithError(err).Error("Cannot get the subnet utilization watermarks; the utilization is not reported")
+		watermarks = utilizationWatermarks{}
 	}
-	counter.setExcludedDaemons(excludedDaemons)
+	utilizationAlerts := make(map[int64]utilizationAlertState)
+	if !allSubnets {
+		for subnetID, state := range statsPuller.utilizationAlerts {
+			utilizationAlerts[subnetID] = state
+		}
+		for _, sn := range subnets {
+			delete(utilizationAlerts, sn.ID)
+		}
+	}
+
+	// The utilization samples are recorded for the trends when enabled.
+	historyRetention, err := dbmodel.GetSettingInt(statsPuller.DB, "subnet_utilization_history_retention")
+	if err != nil {
+		log.WithError(err).Error("Cannot get the subnet utilization history retention; the utilization samples are not recorded")
+		historyRetention = 0
+	}
+	var utilizationSamples []dbmodel.SubnetUtilizationSample
 
 	// go through all Subnets and:
 	// 1) estimate utilization per Subnet and per SharedNetwork
 	// 2) estimate global stats
 	for _, sn := range subnets {
-		su := counter.add(sn)
+		su := &subnetStatsWithPool{
+			subnetStats:      counter.add(sn),
+			mostUtilizedPool: getMostUtilizedPool(sn, counter.excludedDaemons),
+		}
+		wasExhausted := sn.Exhausted
+		sn.Exhausted = su.IsExhausted()
 		err = sn.UpdateStatistics(
 			statsPuller.DB,
 			su,
@@ -133,12 +719,59 @@ func (statsPuller *StatsPuller) pullStats() error {
 			lastErr = err
 			log.Errorf("Cannot update utilization (%.3f, %.3f) in subnet %d: %s",
 				su.GetAddressUtilization(), su.GetDelegatedPrefixUtilization(), sn.ID, err)
+			if state, ok := statsPuller.utilizationAlerts[sn.ID]; ok {
+				utilizationAlerts[sn.ID] = state
+			}
 			continue
 		}
+
+		// Report the exhaustion only once, when the subnet becomes full.
+		if sn.Exhausted && !wasExhausted && statsPuller.EventCenter != nil {
+			statsPuller.EventCenter.AddErrorEvent("{subnet} is exhausted; new clients cannot be served", sn)
+		}
+
+		if watermarks.high > 0 {
+			utilizationAlerts[sn.ID] = statsPuller.checkUtilizationWatermarks(sn, su, watermarks, statsPuller.utilizationAlerts[sn.ID])
+		}
+
+		if historyRetention > 0 {
+			utilizationSamples = append(utilizationSamples, dbmodel.SubnetUtilizationSample{
+				SubnetID:        sn.ID,
+				SampledAt:       sn.StatsCollectedAt,
+				AddrUtilization: sn.AddrUtilization,
+				PdUtilization:   sn.PdUtilization,
+			})
+		}
+	}
+	statsPuller.utilizationAlerts = utilizationAlerts
+
+	// Record the samples in a single query and prune the samples older
+	// than the retention time.
+	if historyRetention > 0 {
+		pruneBefore := statsPuller.clock.Now().Add(-time.Duration(historyRetention) * time.Hour)
+		err = dbmodel.AddSubnetUtilizationSamples(statsPuller.DB, utilizationSamples, pruneBefore)
+		if err != nil {
+			lastErr = err
+			log.WithError(err).Error("Cannot record the subnet utilization samples")
+		}
+	}
+
+	// Use the shared network statistics returned by Kea where available.
+	// Otherwise, use the statistics summed from the subnets.
+	var directStats map[int64]*sharedNetworkStats
+	if len(statsPuller.sharedNetworkStats) > 0 {
+		networks, nerr := dbmodel.GetAllSharedNetworks(statsPuller.DB, 0)
+		if nerr != nil {
+			return nerr
+		}
+		directStats = statsPuller.getDirectSharedNetworkStats(networks, excludedDaemons)
 	}
 
 	// shared network utilization
 	for sharedNetworkID, u := range counter.sharedNetworks {
+		if s, ok := directStats[sharedNetworkID]; ok {
+			u = s
+		}
 		err = dbmodel.UpdateStatisticsInSharedNetwork(
 			statsPuller.DB, sharedNetworkID, u,
 		)
@@ -151,6 +784,10 @@ func (statsPuller *StatsPuller) pullStats() error {
 		}
 	}
 
+	if !refreshGlobalStats {
+		return lastErr
+	}
+
 	// global stats to collect
 	statsMap := map[string]*big.Int{
 		"total-addresses":    counter.global.totalIPv4Addresses.ToBigInt(),
@@ -161,12 +798,19 @@ func (statsPuller *StatsPuller) pullStats() error {
 		"declined-nas":       counter.global.totalDeclinedIPv6Addresses.ToBigInt(),
 		"assigned-pds":       counter.global.totalAssignedDelegatedPrefixes.ToBigInt(),
 		"total-pds":          counter.global.totalDelegatedPrefixes.ToBigInt(),
+
+		"reclaimed-leases":             counter.global.totalReclaimedLeases.ToBigInt(),
+		"reclaimed-declined-addresses": counter.global.totalReclaimedDeclinedAddresses.ToBigInt(),
 	}
 
 	// update global statistics in db
 	err = dbmodel.SetStats(statsPuller.DB, statsMap)
 	if err != nil {
 		lastErr = err
+	} else {
+		statsPuller.stateMutex.Lock()
+		statsPuller.globalStatsRefreshedAt = statsPuller.clock.Now()
+		statsPuller.stateMutex.Unlock()
 	}
 
 	return lastErr
@@ -190,6 +834,191 @@ type StatLeaseGetResponse struct {
 	Arguments *StatLeaseGetArgs `json:"arguments,omitempty"`
 }
 
+// Returns the timestamp of the lease statistics from the stat-lease4-get or
+// stat-lease6-get response. It returns an empty string if the response
+// doesn't contain the timestamp.
+func getStatLeaseGetTimestamp(response interface{}) string {
+	statsResp, ok := response.(*[]StatLeaseGetResponse)
+	if !ok || len(*statsResp) == 0 || (*statsResp)[0].Arguments == nil {
+		return ""
+	}
+	return (*statsResp)[0].Arguments.Timestamp
+}
+
+// Records the timestamp of the lease statistics returned by the daemon and
+// raises a warning when the timestamp hasn't advanced for the
+// staleStatsPollsThreshold consecutive polls. It indicates that the
+// statistics subsystem in Kea is stuck and the statistics are frozen even
+// though the commands succeed. The warning is raised once until the
+// timestamp advances again. It returns true if the statistics are stale.
+func (statsPuller *StatsPuller) checkStaleStats(daemon *dbmodel.Daemon, timestamp string) bool {
+	if timestamp == "" {
+		return false
+	}
+	statsPuller.stateMutex.Lock()
+	defer statsPuller.stateMutex.Unlock()
+	if statsPuller.statsTimestamps == nil {
+		statsPuller.statsTimestamps = make(map[int64]*statsTimestampState)
+	}
+	state, ok := statsPuller.statsTimestamps[daemon.ID]
+	if !ok || state.timestamp != timestamp {
+		statsPuller.statsTimestamps[daemon.ID] = &statsTimestampState{
+			timestamp: timestamp,
+		}
+		return false
+	}
+	state.unchangedPolls++
+	if state.unchangedPolls < staleStatsPollsThreshold {
+		return false
+	}
+	if !state.reported {
+		log.Warnf("Lease statistics returned by daemon %d have not been updated since %s", daemon.ID, timestamp)
+		if statsPuller.EventCenter != nil {
+			statsPuller.EventCenter.AddWarningEvent(
+				fmt.Sprintf("lease statistics returned by {daemon} have not been updated since %s; the data may be stale", timestamp),
+				daemon,
+			)
+		}
+		state.reported = true
+	}
+	return true
+}
+
+// Returns the format of the lease statistics in the response to the
+// stat-lease4-get or stat-lease6-get command. The format is detected from
+// the names of the address columns misspelled by Kea 1.6. It returns an
+// empty string if the response lacks these columns, e.g., the DHCPv6
+// statistics are returned in the same format by all Kea versions.
+func getStatLeaseGetFormat(response interface{}) string {
+	statsResp, ok := response.(*[]StatLeaseGetResponse)
+	if !ok || len(*statsResp) == 0 || (*statsResp)[0].Arguments == nil {
+		return ""
+	}
+	for _, column := range (*statsResp)[0].Arguments.ResultSet.Columns {
+		switch {
+		case strings.HasSuffix(column, "-addreses"):
+			return dbmodel.KeaStatsFormat16
+		case strings.HasSuffix(column, "-addresses") && !strings.HasPrefix(column, "pool"):
+			return dbmodel.KeaStatsFormat18
+		}
+	}
+	return ""
+}
+
+// Records the format of the lease statistics returned by the daemon and
+// raises a warning when the daemon returns them in the legacy Kea 1.6
+// format. It indicates that the daemon runs an outdated Kea version that
+// should be upgraded. The warning is raised when the detected format
+// changes, so it isn't repeated on every pull.
+func (statsPuller *StatsPuller) checkStatsFormat(daemon *dbmodel.Daemon, format string) {
+	if format == "" || daemon.KeaDaemon == nil || daemon.KeaDaemon.KeaDHCPDaemon == nil {
+		return
+	}
+	keaDHCPDaemon := daemon.KeaDaemon.KeaDHCPDaemon
+	if keaDHCPDaemon.StatsFormat == format {
+		return
+	}
+	keaDHCPDaemon.StatsFormat = format
+	if keaDHCPDaemon.ID != 0 {
+		if err := dbmodel.UpdateKeaDHCPDaemonStatsFormat(statsPuller.DB, keaDHCPDaemon); err != nil {
+			log.WithError(err).Errorf("Cannot store the lease stats format of daemon %d", daemon.ID)
+		}
+	}
+	if format != dbmodel.KeaStatsFormat16 {
+		return
+	}
+	log.Warnf("Daemon %d returns the lease stats in the legacy Kea %s format", daemon.ID, format)
+	if statsPuller.EventCenter != nil {
+		statsPuller.EventCenter.AddWarningEvent(
+			fmt.Sprintf("{daemon} returns the lease statistics in the legacy Kea %s format; consider upgrading Kea", format),
+			daemon,
+		)
+	}
+}
+
+// The lease statistics command sent to a daemon in a separate call when
+// the statistics are fetched in batches.
+type statLeaseBatch struct {
+	daemon  *dbmodel.Daemon
+	command *keactrl.Command
+}
+
+// Returns the stat-lease4-get or stat-lease6-get commands fetching the lease
+// statistics of the daemon's subnets. If the daemon has more subnets than
+// the MaxSubnetsPerCall, the command is split into multiple commands, each
+// requesting the statistics for a range of the subnet IDs including at most
+// MaxSubnetsPerCall subnets. Otherwise, a single command fetching the
+// statistics of all subnets is returned. It is also the case when the
+// daemon's configuration is unknown or some subnets lack the IDs.
+func (statsPuller *StatsPuller) getStatLeaseCommands(daemon *dbmodel.Daemon, command string) []*keactrl.Command {
+	daemons := []string{daemon.Name}
+	allSubnetsCommand := []*keactrl.Command{
+		{
+			Command: command,
+			Daemons: daemons,
+		},
+	}
+	if statsPuller.MaxSubnetsPerCall <= 0 || daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil {
+		return allSubnetsCommand
+	}
+
+	// The top-level subnets are returned as members of the shared network
+	// with no name.
+	var subnetIDs []int64
+	for _, sharedNetwork := range daemon.KeaDaemon.Config.GetSharedNetworks(true) {
+		for _, subnet := range sharedNetwork.GetSubnets() {
+			if subnet.GetID() == 0 {
+				return allSubnetsCommand
+			}
+			subnetIDs = append(subnetIDs, subnet.GetID())
+		}
+	}
+	if int64(len(subnetIDs)) <= statsPuller.MaxSubnetsPerCall {
+		return allSubnetsCommand
+	}
+	sort.Slice(subnetIDs, func(i, j int) bool {
+		return subnetIDs[i] < subnetIDs[j]
+	})
+
+	var commands []*keactrl.Command
+	for first := int64(0); first < int64(len(subnetIDs)); first += statsPuller.MaxSubnetsPerCall {
+		last := first + statsPuller.MaxSubnetsPerCall - 1
+		if last >= int64(len(subnetIDs)) {
+			last = int64(len(subnetIDs)) - 1
+		}
+		commands = append(commands, &keactrl.Command{
+			Command: command,
+			Daemons: daemons,
+			Arguments: map[string]interface{}{
+				"subnet-range": map[string]interface{}{
+					"first-subnet-id": subnetIDs[first],
+					"last-subnet-id":  subnetIDs[last],
+				},
+			},
+		})
+	}
+	return commands
+}
+
+// Sends the lease statistics command for a batch of the subnets and stores
+// the returned statistics.
+func (statsPuller *StatsPuller) pullStatLeaseBatch(dbApp *dbmodel.App, batch statLeaseBatch, subnetsMap map[localSubnetKey]*dbmodel.LocalSubnet, poolStats map[int64]dbmodel.SubnetStats) error {
+	response := &[]StatLeaseGetResponse{}
+	cmdsResult, err := statsPuller.Agents.ForwardToKeaOverHTTP(context.Background(), dbApp,
+		[]keactrl.SerializableCommand{batch.command}, response)
+	if err != nil {
+		return err
+	}
+	if cmdsResult.Error != nil {
+		return cmdsResult.Error
+	}
+	family := 4
+	if batch.daemon.Name == dhcp6 {
+		family = 6
+	}
+	return statsPuller.storeDaemonStats(response, subnetsMap, dbApp, family, poolStats)
+}
+
 // A key that is used in map that is mapping from (local subnet id, inet family) to LocalSubnet struct.
 type localSubnetKey struct {
 	LocalSubnetID int64
@@ -197,7 +1026,11 @@ type localSubnetKey struct {
 }
 
 // Process lease stats results from the given command response for given daemon.
-func (statsPuller *StatsPuller) storeDaemonStats(response interface{}, subnetsMap map[localSubnetKey]*dbmodel.LocalSubnet, dbApp *dbmodel.App, family int) error {
+// The pool-indexed columns returned by Kea 2.x, e.g., pool0-total-addresses,
+// are stored as the pool-level statistics, e.g., pool[0].total-addresses.
+// The pool-level statistics by local subnet ID are merged into the stored
+// local subnet statistics.
+func (statsPuller *StatsPuller) storeDaemonStats(response interface{}, subnetsMap map[localSubnetKey]*dbmodel.LocalSubnet, dbApp *dbmodel.App, family int, poolStats map[int64]dbmodel.SubnetStats) error {
 	var lastErr error
 	var sr []StatLeaseGetResponse
 
@@ -225,30 +1058,53 @@ func (statsPuller *StatsPuller) storeDaemonStats(response interface{}, subnetsMa
 		var sn *dbmodel.LocalSubnet
 		var lsnID int64
 		for colIdx, val := range row {
+			// The values without the corresponding columns are skipped.
+			if colIdx >= len(resultSet.Columns) {
+				break
+			}
 			name := resultSet.Columns[colIdx]
 			if name == "subnet-id" {
 				lsnID = val
 				sn = subnetsMap[localSubnetKey{lsnID, family}]
+			} else if poolStatName, ok := getPoolStatNameFromColumn(name); ok {
+				// The result set may include the pool columns for the
+				// subnets lacking the pools. Their values are negative.
+				if val >= 0 {
+					stats[poolStatName] = uint64(val)
+				}
 			} else {
 				// handle inconsistency in stats naming in different kea versions
 				name = strings.Replace(name, "addreses", "addresses", 1)
 
-				// Cast the value to a proper type
+				// Cast the value to a proper type. The columns are
+				// matched by name, so the counters returned only by some
+				// Kea versions, e.g., reclaimed-leases, may be present
+				// at any position or missing.
 				switch name {
 				case "total-addresses", "assigned-addresses", "declined-addresses",
 					"total-nas", "assigned-nas", "declined-nas",
-					"total-pds", "assigned-pds", "cumulative-assigned-addresses":
+					"total-pds", "assigned-pds", "cumulative-assigned-addresses",
+					"reclaimed-leases", "reclaimed-declined-addresses":
 					stats[name] = uint64(val)
 				default:
 					stats[name] = val
 				}
 			}
 		}
+		for name, value := range poolStats[lsnID] {
+			stats[name] = value
+		}
 		if sn == nil {
 			lastErr = errors.Errorf("cannot find LocalSubnet for app: %d, local subnet ID: %d, family: %d", dbApp.ID, lsnID, family)
 			log.Error(lastErr.Error())
 			continue
 		}
+		// The utilization is computed before the statistics are filtered
+		// because it may use the statistics that are not stored.
+		if family == 6 {
+			sn.PdUtilizationByDelegatedLen = getPdUtilizationByDelegatedLen(sn.PrefixPools, stats)
+		}
+		statsPuller.filterStats(stats)
 		err := sn.UpdateStats(statsPuller.DB, stats)
 		if err != nil {
 			log.Errorf("Problem updating Kea stats for local subnet ID %d, app ID %d: %s", sn.LocalSubnetID, dbApp.ID, err.Error())
@@ -258,6 +1114,22 @@ func (statsPuller *StatsPuller) storeDaemonStats(response interface{}, subnetsMa
 	return lastErr
 }
 
+// Checks if the stats can be pulled from the daemon. It must be an active
+// Kea DHCP daemon. The daemons without the statistic hook are ignored to
+// avoid confusing error messages. The daemons with unknown configuration
+// are not ignored.
+func isStatsDaemon(daemon *dbmodel.Daemon) bool {
+	if daemon.KeaDaemon == nil || !daemon.Active || (daemon.Name != dhcp4 && daemon.Name != dhcp6) {
+		return false
+	}
+	if daemon.KeaDaemon.Config != nil {
+		if _, _, present := daemon.KeaDaemon.Config.GetHookLibrary("libdhcp_stat_cmds"); !present {
+			return false
+		}
+	}
+	return true
+}
+
 func (statsPuller *StatsPuller) getStatsFromApp(dbApp *dbmodel.App) error {
 	// If no dhcp daemons found then exit.
 	if len(dbApp.GetActiveDHCPDaemonNames()) == 0 {
@@ -273,30 +1145,43 @@ func (statsPuller *StatsPuller) getStatsFromApp(dbApp *dbmodel.App) error {
 	cmds := []*keactrl.Command{}
 	cmdDaemons := []*dbmodel.Daemon{}
 	responses := []interface{}{}
+	// The lease statistics commands for the remaining subnets sent in
+	// separate calls when the statistics are fetched in batches.
+	batches := []statLeaseBatch{}
 
 	// Iterate over active daemons, adding commands and response containers
 	// for dhcp4 and dhcp6 daemons.
 	for _, d := range dbApp.Daemons {
-		if d.KeaDaemon != nil && d.Active {
-			if d.KeaDaemon.Config != nil {
-				// Ignore the daemons without the statistic hook to avoid
-				// confusing error messages.
-				if _, _, present := d.KeaDaemon.Config.GetHookLibrary("libdhcp_stat_cmds"); !present {
-					continue
-				}
-			}
+		if isStatsDaemon(d) {
 			switch d.Name {
 			case dhcp4:
 				// Add daemon, cmd, and response for DHCP4 lease stats
 				cmdDaemons = append(cmdDaemons, d)
 				dhcp4Daemons := []string{dhcp4}
-				cmds = append(cmds, &keactrl.Command{
-					Command: "stat-lease4-get",
-					Daemons: dhcp4Daemons,
-				})
+				statLeaseCmds := statsPuller.getStatLeaseCommands(d, "stat-lease4-get")
+				cmds = append(cmds, statLeaseCmds[0])
+				for _, cmd := range statLeaseCmds[1:] {
+					batches = append(batches, statLeaseBatch{daemon: d, command: cmd})
+				}
 
 				responses = append(responses, &[]StatLeaseGetResponse{})
 
+				// Add daemon, cmd and response for DHCP4 shared network lease stats
+				if statsPuller.SharedNetworkStats {
+					cmdDaemons = append(cmdDaemons, d)
+					cmds = append(cmds, &keactrl.Command{
+						Command: statLease4SharedNetworkGet,
+						Daemons: dhcp4Daemons,
+					})
+					responses = append(responses, &[]StatLeaseSharedNetworkGetResponse{})
+				}
+
+				// Add daemon, cmd and response for DHCP4 pool lease stats
+				if statsPuller.PoolStats {
+					cmdDaemons = append(cmdDaemons, d)
+					responses = append(responses, poolStatsAddCmd(&cmds, dhcp4Daemons))
+				}
+
 				// Add daemon, cmd and response for DHCP4 RPS stats if we have an RpsWorker
 				if statsPuller.RpsWorker != nil {
 					cmdDaemons = append(cmdDaemons, d)
@@ -307,13 +1192,30 @@ func (statsPuller *StatsPuller) getStatsFromApp(dbApp *dbmodel.App) error {
 				// Add daemon, cmd and response for DHCP6 lease stats
 				cmdDaemons = append(cmdDaemons, d)
 				dhcp6Daemons := []string{dhcp6}
-				cmds = append(cmds, &keactrl.Command{
-					Command: "stat-lease6-get",
-					Daemons: dhcp6Daemons,
-				})
+				statLeaseCmds := statsPuller.getStatLeaseCommands(d, "stat-lease6-get")
+				cmds = append(cmds, statLeaseCmds[0])
+				for _, cmd := range statLeaseCmds[1:] {
+					batches = append(batches, statLeaseBatch{daemon: d, command: cmd})
+				}
 
 				responses = append(responses, &[]StatLeaseGetResponse{})
 
+				// Add daemon, cmd and response for DHCP6 shared network lease stats
+				if statsPuller.SharedNetworkStats {
+					cmdDaemons = append(cmdDaemons, d)
+					cmds = append(cmds, &keactrl.Command{
+						Command: statLease6SharedNetworkGet,
+						Daemons: dhcp6Daemons,
+					})
+					responses = append(responses, &[]StatLeaseSharedNetworkGetResponse{})
+				}
+
+				// Add daemon, cmd and response for DHCP6 pool lease stats
+				if statsPuller.PoolStats {
+					cmdDaemons = append(cmdDaemons, d)
+					responses = append(responses, poolStatsAddCmd(&cmds, dhcp6Daemons))
+				}
+
 				// Add daemon, cmd and response for DHCP6 RPS stats if we have an RpsWorker
 				if statsPuller.RpsWorker != nil {
 					cmdDaemons = append(cmdDaemons, d)
@@ -323,6 +1225,16 @@ func (statsPuller *StatsPuller) getStatsFromApp(dbApp *dbmodel.App) error {
 		}
 	}
 
+	// Send the batches in the same call as the other commands if requested.
+	if statsPuller.CombineCommands {
+		for _, batch := range batches {
+			cmdDaemons = append(cmdDaemons, batch.daemon)
+			cmds = append(cmds, batch.command)
+			responses = append(responses, &[]StatLeaseGetResponse{})
+		}
+		batches = nil
+	}
+
 	// If there are no commands, nothing to do
 	if len(cmds) == 0 {
 		return nil
@@ -345,12 +1257,15 @@ func (statsPuller *StatsPuller) getStatsFromApp(dbApp *dbmodel.App) error {
 	}
 
 	// Process the response for each command for each daemon.
-	return statsPuller.processAppResponses(dbApp, cmds, cmdDaemons, responses)
+	return statsPuller.processAppResponses(dbApp, cmds, cmdDaemons, responses, cmdsResult.CmdsErrors, batches)
 }
 
 // Iterates through the commands for each daemon and processes the command responses
 // Was part of getStatsFromApp() until lint:backend complained about cognitive complexity.
-func (statsPuller *StatsPuller) processAppResponses(dbApp *dbmodel.App, cmds []*keactrl.Command, cmdDaemons []*dbmodel.Daemon, responses []interface{}) error {
+// The remaining batches of the lease statistics are fetched and stored after
+// processing the responses, one batch at a time. The responses to the
+// commands that failed according to the cmdsErrors are skipped.
+func (statsPuller *StatsPuller) processAppResponses(dbApp *dbmodel.App, cmds []*keactrl.Command, cmdDaemons []*dbmodel.Daemon, responses []interface{}, cmdsErrors []error, batches []statLeaseBatch) error {
 	// Lease statistic processing needs app's local subnets
 	subnets, err := dbmodel.GetAppLocalSubnets(statsPuller.DB, dbApp.ID)
 	if err != nil {
@@ -366,16 +1281,66 @@ func (statsPuller *StatsPuller) processAppResponses(dbApp *dbmodel.App, cmds []*
 	}
 
 	var lastErr error
+
+	// Skip the commands that failed.
+	failed := make([]bool, len(cmds))
+	for idx := 0; idx < len(cmds) && idx < len(cmdsErrors); idx++ {
+		if cmdsErrors[idx] != nil {
+			log.Errorf("Error sending %s command to %s: %+v", cmds[idx].Command, cmdDaemons[idx].Name, cmdsErrors[idx])
+			lastErr = cmdsErrors[idx]
+			failed[idx] = true
+		}
+	}
+
+	// The pool-level statistics are merged into the subnet statistics, so
+	// they must be processed first.
+	poolStats := make(map[int64]map[int64]dbmodel.SubnetStats)
 	for idx := 0; idx < len(cmds); idx++ {
+		if failed[idx] || cmds[idx].Command != "statistic-get-all" {
+			continue
+		}
+		stats, err := parsePoolStats(responses[idx])
+		if err != nil {
+			log.Errorf("Error handling statistic-get-all response: %+v", err)
+			lastErr = err
+			continue
+		}
+		poolStats[cmdDaemons[idx].ID] = stats
+	}
+
+	// The combined commands may include several lease statistics responses
+	// for a daemon. Only the first one is checked for the stale statistics
+	// and format to not count the same pull multiple times.
+	checkedDaemons := make(map[int64]bool)
+	checkStatLeaseResponse := func(daemon *dbmodel.Daemon, response interface{}) {
+		if checkedDaemons[daemon.ID] {
+			return
+		}
+		checkedDaemons[daemon.ID] = true
+		statsPuller.checkStaleStats(daemon, getStatLeaseGetTimestamp(response))
+		statsPuller.checkStatsFormat(daemon, getStatLeaseGetFormat(response))
+	}
+
+	for idx := 0; idx < len(cmds); idx++ {
+		if failed[idx] {
+			continue
+		}
 		switch cmdDaemons[idx].Name {
 		case dhcp4:
 			switch cmds[idx].Command {
 			case "stat-lease4-get":
-				err = statsPuller.storeDaemonStats(responses[idx], subnetsMap, dbApp, 4)
+				checkStatLeaseResponse(cmdDaemons[idx], responses[idx])
+				err = statsPuller.storeDaemonStats(responses[idx], subnetsMap, dbApp, 4, poolStats[cmdDaemons[idx].ID])
 				if err != nil {
 					log.Errorf("Error handling stat-lease4-get response: %+v", err)
 					lastErr = err
 				}
+			case statLease4SharedNetworkGet:
+				err = statsPuller.storeSharedNetworkStats(responses[idx], cmdDaemons[idx], 4)
+				if err != nil {
+					log.Errorf("Error handling %s response: %+v", statLease4SharedNetworkGet, err)
+					lastErr = err
+				}
 			case "statistic-get":
 				err = statsPuller.RpsWorker.Response4Handler(cmdDaemons[idx], responses[idx])
 				if err != nil {
@@ -387,11 +1352,18 @@ func (statsPuller *StatsPuller) processAppResponses(dbApp *dbmodel.App, cmds []*
 		case dhcp6:
 			switch cmds[idx].Command {
 			case "stat-lease6-get":
-				err = statsPuller.storeDaemonStats(responses[idx], subnetsMap, dbApp, 6)
+				checkStatLeaseResponse(cmdDaemons[idx], responses[idx])
+				err = statsPuller.storeDaemonStats(responses[idx], subnetsMap, dbApp, 6, poolStats[cmdDaemons[idx].ID])
 				if err != nil {
 					log.Errorf("Error handling stat-lease6-get response: %+v", err)
 					lastErr = err
 				}
+			case statLease6SharedNetworkGet:
+				err = statsPuller.storeSharedNetworkStats(responses[idx], cmdDaemons[idx], 6)
+				if err != nil {
+					log.Errorf("Error handling %s response: %+v", statLease6SharedNetworkGet, err)
+					lastErr = err
+				}
 			case "statistic-get":
 				err = statsPuller.RpsWorker.Response6Handler(cmdDaemons[idx], responses[idx])
 				if err != nil {
@@ -402,5 +1374,13 @@ func (statsPuller *StatsPuller) processAppResponses(dbApp *dbmodel.App, cmds []*
 		}
 	}
 
+	for _, batch := range batches {
+		err = statsPuller.pullStatLeaseBatch(dbApp, batch, subnetsMap, poolStats[batch.daemon.ID])
+		if err != nil {
+			log.Errorf("Error handling %s response for a batch of subnets: %+v", batch.command.Command, err)
+			lastErr = err
+		}
+	}
+
 	return lastErr
 }
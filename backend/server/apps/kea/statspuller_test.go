@@ -2,16 +2,22 @@ package kea
 
 import (
 	"encoding/json"
+	"fmt"
 	"math"
 	"math/big"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-pg/pg/v10"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 	keactrl "isc.org/stork/appctrl/kea"
 	agentcommtest "isc.org/stork/server/agentcomm/test"
 	dbmodel "isc.org/stork/server/database/model"
 	dbtest "isc.org/stork/server/database/test"
+	storktest "isc.org/stork/server/test/dbmodel"
+	"isc.org/stork/testutil"
 )
 
 // Prepares the Kea mock. It accepts list of serialized JSON responses in order:
@@ -354,7 +360,7 @@ func TestStatsPullerBasic(t *testing.T) {
 	fa := agentcommtest.NewFakeAgents(nil, nil)
 
 	// Act
-	sp, err := NewStatsPuller(db, fa)
+	sp, err := NewStatsPuller(db, fa, &storktest.FakeEventCenter{}, nil)
 	defer sp.Shutdown()
 
 	// Assert
@@ -404,7 +410,7 @@ func TestStatsPullerEmptyResponse(t *testing.T) {
 	fa := agentcommtest.NewFakeAgents(keaMock, nil)
 
 	// prepare stats puller
-	sp, _ := NewStatsPuller(db, fa)
+	sp, _ := NewStatsPuller(db, fa, &storktest.FakeEventCenter{}, nil)
 	defer sp.Shutdown()
 
 	// Act
@@ -447,7 +453,8 @@ func checkStatsPullerPullStats(t *testing.T, statsFormat string) {
 	}
 
 	// prepare stats puller
-	sp, _ := NewStatsPuller(db, fa)
+	eventCenter := &storktest.FakeEventCenter{}
+	sp, _ := NewStatsPuller(db, fa, eventCenter, nil)
 	defer sp.Shutdown()
 
 	// Act
@@ -460,6 +467,25 @@ func checkStatsPullerPullStats(t *testing.T, statsFormat string) {
 	// check collected stats
 	verifyStandardLocalSubnetsStatistics(t, db)
 
+	// The stats format is detected for the DHCPv4 daemon. A warning is
+	// raised for the legacy format.
+	dbApp, err := dbmodel.GetAppByID(db, app.ID)
+	require.NoError(t, err)
+	dhcp4Daemon := dbApp.GetDaemonByName(dhcp4)
+	require.NotNil(t, dhcp4Daemon)
+	require.Equal(t, statsFormat, dhcp4Daemon.KeaDaemon.KeaDHCPDaemon.StatsFormat)
+	legacyFormatWarnings := 0
+	for _, event := range eventCenter.Events {
+		if strings.Contains(event.Text, "legacy Kea 1.6 format") {
+			legacyFormatWarnings++
+		}
+	}
+	if statsFormat == dbmodel.KeaStatsFormat16 {
+		require.Equal(t, 1, legacyFormatWarnings)
+	} else {
+		require.Zero(t, legacyFormatWarnings)
+	}
+
 	// We should have two rows in RpsWorker.PreviousRps map one for each daemon
 	require.Equal(t, 2, len(sp.RpsWorker.PreviousRps))
 
@@ -560,7 +586,7 @@ func TestGetStatsFromAppWithoutStatCmd(t *testing.T) {
 		},
 	}
 
-	sp, _ := NewStatsPuller(db, fa)
+	sp, _ := NewStatsPuller(db, fa, &storktest.FakeEventCenter{}, nil)
 
 	// Act
 	err := sp.getStatsFromApp(app)
@@ -570,6 +596,316 @@ func TestGetStatsFromAppWithoutStatCmd(t *testing.T) {
 	require.Zero(t, fa.CallNo)
 }
 
+// Returns the copy of the stat-lease-get response including only the rows
+// for the subnets within the subnet range specified in the command.
+func filterStatLeaseResponse(response []StatLeaseGetResponse, command *keactrl.Command) []StatLeaseGetResponse {
+	arguments, ok := command.Arguments.(map[string]interface{})
+	if !ok {
+		return response
+	}
+	subnetRange := arguments["subnet-range"].(map[string]interface{})
+	first := subnetRange["first-subnet-id"].(int64)
+	last := subnetRange["last-subnet-id"].(int64)
+
+	args := *response[0].Arguments
+	args.ResultSet.Rows = [][]int64{}
+	for _, row := range response[0].Arguments.ResultSet.Rows {
+		if row[0] >= first && row[0] <= last {
+			args.ResultSet.Rows = append(args.ResultSet.Rows, row)
+		}
+	}
+	filtered := response[0]
+	filtered.Arguments = &args
+	return []StatLeaseGetResponse{filtered}
+}
+
+// Test that the lease statistics commands are split into the ranges of
+// the subnet IDs when the daemon has more subnets than allowed in a call.
+func TestStatsPullerGetStatLeaseCommands(t *testing.T) {
+	// Arrange
+	_, v6Config := createDhcpConfigs()
+	config, err := dbmodel.NewKeaConfigFromJSON(v6Config)
+	require.NoError(t, err)
+	daemon := &dbmodel.Daemon{
+		Name: "dhcp6",
+		KeaDaemon: &dbmodel.KeaDaemon{
+			Config: config,
+		},
+	}
+	sp := &StatsPuller{MaxSubnetsPerCall: 2}
+
+	// Act
+	commands := sp.getStatLeaseCommands(daemon, "stat-lease6-get")
+
+	// Assert
+	require.Len(t, commands, 3)
+	for i, expected := range [][]int64{{30, 40}, {50, 60}, {70, 70}} {
+		require.Equal(t, "stat-lease6-get", commands[i].Command)
+		require.Equal(t, []string{"dhcp6"}, commands[i].Daemons)
+		require.Equal(t, map[string]interface{}{
+			"subnet-range": map[string]interface{}{
+				"first-subnet-id": expected[0],
+				"last-subnet-id":  expected[1],
+			},
+		}, commands[i].Arguments)
+	}
+}
+
+// Test that a single lease statistics command is returned when the number
+// of the subnets per call is not limited, the daemon has fewer subnets than
+// the limit or its configuration is unknown.
+func TestStatsPullerGetStatLeaseCommandsSingle(t *testing.T) {
+	// Arrange
+	_, v6Config := createDhcpConfigs()
+	config, err := dbmodel.NewKeaConfigFromJSON(v6Config)
+	require.NoError(t, err)
+	daemon := &dbmodel.Daemon{
+		Name: "dhcp6",
+		KeaDaemon: &dbmodel.KeaDaemon{
+			Config: config,
+		},
+	}
+
+	for _, limit := range []int64{0, 5, 10} {
+		t.Run(fmt.Sprint(limit), func(t *testing.T) {
+			sp := &StatsPuller{MaxSubnetsPerCall: limit}
+
+			// Act
+			commands := sp.getStatLeaseCommands(daemon, "stat-lease6-get")
+
+			// Assert
+			require.Len(t, commands, 1)
+			require.Equal(t, "stat-lease6-get", commands[0].Command)
+			require.Nil(t, commands[0].Arguments)
+		})
+	}
+
+	t.Run("no config", func(t *testing.T) {
+		sp := &StatsPuller{MaxSubnetsPerCall: 1}
+
+		// Act
+		commands := sp.getStatLeaseCommands(&dbmodel.Daemon{Name: "dhcp6"}, "stat-lease6-get")
+
+		// Assert
+		require.Len(t, commands, 1)
+		require.Nil(t, commands[0].Arguments)
+	})
+}
+
+// Test that the lease statistics fetched in batches of the subnets are
+// stored and the global statistics are accumulated correctly.
+func TestStatsPullerPullStatsInBatches(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+	_ = dbmodel.InitializeSettings(db, 0)
+	_ = dbmodel.InitializeStats(db)
+
+	v4Config, v6Config := createDhcpConfigs()
+	app := createAppWithSubnets(t, db, 0, v4Config, v6Config)
+
+	// The standard mock returns the statistics of all subnets. They are
+	// filtered to the subnet range requested in the command.
+	var fa *agentcommtest.FakeAgents
+	standardMock := createStandardKeaMock(false)
+	keaMock := func(callNo int, cmdResponses []interface{}) {
+		if len(cmdResponses) == 4 {
+			standardMock(0, cmdResponses)
+			response := cmdResponses[2].(*[]StatLeaseGetResponse)
+			command := fa.RecordedCommands[len(fa.RecordedCommands)-4+2].(*keactrl.Command)
+			*response = filterStatLeaseResponse(*response, command)
+			return
+		}
+		allResponses := []interface{}{
+			&[]StatLeaseGetResponse{}, &[]StatGetResponse4{},
+			&[]StatLeaseGetResponse{}, &[]StatGetResponse6{},
+		}
+		standardMock(0, allResponses)
+		response := cmdResponses[0].(*[]StatLeaseGetResponse)
+		*response = filterStatLeaseResponse(*allResponses[2].(*[]StatLeaseGetResponse), fa.GetLastCommand())
+	}
+	fa = agentcommtest.NewFakeAgents(keaMock, nil)
+
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	for i := range app.Daemons {
+		sharedNetworks, subnets, err := detectDaemonNetworks(db, app.Daemons[i], lookup)
+		require.NoError(t, err)
+		_, err = dbmodel.CommitNetworksIntoDB(db, sharedNetworks, subnets, app.Daemons[i])
+		require.NoError(t, err)
+	}
+
+	sp, _ := NewStatsPuller(db, fa, &storktest.FakeEventCenter{}, nil)
+	defer sp.Shutdown()
+	sp.MaxSubnetsPerCall = 2
+
+	// Act
+	err := sp.pullStats()
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, 3, fa.CallNo)
+
+	var statLease6Commands []*keactrl.Command
+	for _, command := range fa.RecordedCommands {
+		if command.(*keactrl.Command).Command == "stat-lease6-get" {
+			statLease6Commands = append(statLease6Commands, command.(*keactrl.Command))
+		}
+	}
+	require.Len(t, statLease6Commands, 3)
+
+	verifyStandardLocalSubnetsStatistics(t, db)
+
+	globals, err := dbmodel.GetAllStats(db)
+	require.NoError(t, err)
+	require.EqualValues(t, big.NewInt(4358), globals["total-addresses"])
+	require.EqualValues(t, big.NewInt(2145), globals["assigned-addresses"])
+	require.EqualValues(t, big.NewInt(0).Add(
+		big.NewInt(4355), big.NewInt(0).SetUint64(math.MaxUint64),
+	), globals["total-nas"])
+	require.EqualValues(t, big.NewInt(0).Add(
+		big.NewInt(2460), big.NewInt(math.MaxInt64),
+	), globals["assigned-nas"])
+	require.EqualValues(t, big.NewInt(0).Add(
+		big.NewInt(246), big.NewInt(0).SetUint64(math.MaxUint64),
+	), globals["assigned-pds"])
+}
+
+// Test that the lease statistics batches are sent in a single call with
+// the other commands when the commands are combined.
+func TestStatsPullerPullStatsCombinedCommands(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+	_ = dbmodel.InitializeSettings(db, 0)
+	_ = dbmodel.InitializeStats(db)
+
+	v4Config, v6Config := createDhcpConfigs()
+	app := createAppWithSubnets(t, db, 0, v4Config, v6Config)
+
+	// The first four responses are filled by the standard mock. The
+	// remaining responses are the batches of the DHCPv6 lease statistics
+	// filtered to the subnet range requested in the respective command.
+	var fa *agentcommtest.FakeAgents
+	standardMock := createStandardKeaMock(false)
+	keaMock := func(callNo int, cmdResponses []interface{}) {
+		standardMock(0, cmdResponses[:4])
+		allSubnets := *cmdResponses[2].(*[]StatLeaseGetResponse)
+		for i := 2; i < len(cmdResponses); i++ {
+			if i == 3 {
+				continue
+			}
+			response := cmdResponses[i].(*[]StatLeaseGetResponse)
+			*response = filterStatLeaseResponse(allSubnets, fa.RecordedCommands[i].(*keactrl.Command))
+		}
+	}
+	fa = agentcommtest.NewFakeAgents(keaMock, nil)
+
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	for i := range app.Daemons {
+		sharedNetworks, subnets, err := detectDaemonNetworks(db, app.Daemons[i], lookup)
+		require.NoError(t, err)
+		_, err = dbmodel.CommitNetworksIntoDB(db, sharedNetworks, subnets, app.Daemons[i])
+		require.NoError(t, err)
+	}
+
+	sp, _ := NewStatsPuller(db, fa, &storktest.FakeEventCenter{}, nil)
+	defer sp.Shutdown()
+	sp.MaxSubnetsPerCall = 2
+	sp.CombineCommands = true
+
+	// Act
+	err := sp.pullStats()
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, 1, fa.CallNo)
+	require.Len(t, fa.RecordedCommands, 6)
+
+	var statLease6Commands []*keactrl.Command
+	for _, command := range fa.RecordedCommands {
+		if command.(*keactrl.Command).Command == "stat-lease6-get" {
+			statLease6Commands = append(statLease6Commands, command.(*keactrl.Command))
+		}
+	}
+	require.Len(t, statLease6Commands, 3)
+
+	verifyStandardLocalSubnetsStatistics(t, db)
+
+	globals, err := dbmodel.GetAllStats(db)
+	require.NoError(t, err)
+	require.EqualValues(t, big.NewInt(4358), globals["total-addresses"])
+	require.EqualValues(t, big.NewInt(0).Add(
+		big.NewInt(4355), big.NewInt(0).SetUint64(math.MaxUint64),
+	), globals["total-nas"])
+}
+
+// Test that the response to a command that failed is skipped while the
+// responses to the other commands sent in the same call are processed.
+func TestStatsPullerProcessAppResponsesCommandError(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+	_ = dbmodel.InitializeSettings(db, 0)
+	_ = dbmodel.InitializeStats(db)
+
+	v4Config, v6Config := createDhcpConfigs()
+	app := createAppWithSubnets(t, db, 0, v4Config, v6Config)
+
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	var cmds []*keactrl.Command
+	var cmdDaemons []*dbmodel.Daemon
+	for i := range app.Daemons {
+		sharedNetworks, subnets, err := detectDaemonNetworks(db, app.Daemons[i], lookup)
+		require.NoError(t, err)
+		_, err = dbmodel.CommitNetworksIntoDB(db, sharedNetworks, subnets, app.Daemons[i])
+		require.NoError(t, err)
+		cmds = append(cmds, &keactrl.Command{
+			Command: fmt.Sprintf("stat-lease%s-get", strings.TrimPrefix(app.Daemons[i].Name, "dhcp")),
+			Daemons: []string{app.Daemons[i].Name},
+		})
+		cmdDaemons = append(cmdDaemons, app.Daemons[i])
+	}
+	require.Len(t, cmds, 2)
+
+	allResponses := []interface{}{
+		&[]StatLeaseGetResponse{}, &[]StatGetResponse4{},
+		&[]StatLeaseGetResponse{}, &[]StatGetResponse6{},
+	}
+	createStandardKeaMock(false)(0, allResponses)
+	responses := []interface{}{}
+	cmdsErrors := []error{}
+	for _, daemon := range cmdDaemons {
+		if daemon.Name == dhcp4 {
+			responses = append(responses, allResponses[0])
+			cmdsErrors = append(cmdsErrors, nil)
+		} else {
+			responses = append(responses, allResponses[2])
+			cmdsErrors = append(cmdsErrors, errors.New("stat-lease6-get failed"))
+		}
+	}
+
+	sp, _ := NewStatsPuller(db, agentcommtest.NewFakeAgents(nil, nil), &storktest.FakeEventCenter{}, nil)
+	defer sp.Shutdown()
+
+	// Act
+	err := sp.processAppResponses(app, cmds, cmdDaemons, responses, cmdsErrors, nil)
+
+	// Assert
+	require.ErrorContains(t, err, "stat-lease6-get failed")
+
+	localSubnets := []*dbmodel.LocalSubnet{}
+	err = db.Model(&localSubnets).Relation("Subnet").Select()
+	require.NoError(t, err)
+	require.NotEmpty(t, localSubnets)
+	for _, sn := range localSubnets {
+		if sn.Subnet.GetFamily() == 4 {
+			require.NotEmpty(t, sn.Stats)
+		} else {
+			require.Empty(t, sn.Stats)
+		}
+	}
+}
+
 // Prepares the Kea configuration file with HA hook and some subnets.
 func getHATestConfigWithSubnets(rootName, thisServerName, mode string, peerNames ...string) *dbmodel.KeaConfig {
 	// Creates standard HA config.
@@ -920,7 +1256,7 @@ func TestPrepareHAEnvironment(t *testing.T) {
 	keaMock := createKeaMock(func(callNo int) (jsons []string) { return []string{} })
 
 	fa := agentcommtest.NewFakeAgents(keaMock, nil)
-	sp, err := NewStatsPuller(db, fa)
+	sp, err := NewStatsPuller(db, fa, &storktest.FakeEventCenter{}, nil)
 
 	// Assert
 	require.NoError(t, err)
@@ -943,7 +1279,7 @@ func TestStatsPullerPullStatsHAPairNotInitializedYet(t *testing.T) {
 	fa := agentcommtest.NewFakeAgents(keaMock, nil)
 
 	// prepare stats puller
-	sp, err := NewStatsPuller(db, fa)
+	sp, err := NewStatsPuller(db, fa, &storktest.FakeEventCenter{}, nil)
 	require.NoError(t, err)
 	defer sp.Shutdown()
 
@@ -986,7 +1322,7 @@ func TestStatsPullerPullStatsHAPairHealthy(t *testing.T) {
 	fa := agentcommtest.NewFakeAgents(keaMock, nil)
 
 	// prepare stats puller
-	sp, err := NewStatsPuller(db, fa)
+	sp, err := NewStatsPuller(db, fa, &storktest.FakeEventCenter{}, nil)
 	require.NoError(t, err)
 	defer sp.Shutdown()
 
@@ -1025,7 +1361,7 @@ func TestStatsPullerPullStatsHAPairPrimaryIsDownSecondaryIsReady(t *testing.T) {
 	fa := agentcommtest.NewFakeAgents(keaMock, nil)
 
 	// prepare stats puller
-	sp, err := NewStatsPuller(db, fa)
+	sp, err := NewStatsPuller(db, fa, &storktest.FakeEventCenter{}, nil)
 	require.NoError(t, err)
 	defer sp.Shutdown()
 
@@ -1064,7 +1400,7 @@ func TestStatsPullerPullStatsHAPairPrimaryIsDownSecondaryIsDown(t *testing.T) {
 	fa := agentcommtest.NewFakeAgents(keaMock, nil)
 
 	// prepare stats puller
-	sp, err := NewStatsPuller(db, fa)
+	sp, err := NewStatsPuller(db, fa, &storktest.FakeEventCenter{}, nil)
 	require.NoError(t, err)
 	defer sp.Shutdown()
 
@@ -1078,3 +1414,1098 @@ func TestStatsPullerPullStatsHAPairPrimaryIsDownSecondaryIsDown(t *testing.T) {
 
 	verifyCountingStatisticsFromPrimary(t, db)
 }
+
+// Test that the puller marks the subnets with all addresses assigned as
+// exhausted and raises an error event only when the subnet becomes full.
+func TestStatsPullerPullStatsExhaustedSubnet(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+	_ = dbmodel.InitializeSettings(db, 0)
+	_ = dbmodel.InitializeStats(db)
+	// The utilization watermark events are tested separately.
+	_ = dbmodel.SetSettingInt(db, "subnet_utilization_high_watermark", 0)
+
+	v4Config, _ := createDhcpConfigs()
+	app := createAppWithSubnets(t, db, 0, v4Config, "")
+
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	sharedNetworks, subnets, err := detectDaemonNetworks(db, app.Daemons[0], lookup)
+	require.NoError(t, err)
+	_, err = dbmodel.CommitNetworksIntoDB(db, sharedNetworks, subnets, app.Daemons[0])
+	require.NoError(t, err)
+
+	keaMock := createKeaMock(func(callNo int) []string {
+		data := []interface{}{
+			[]StatLeaseGetResponse{
+				{
+					ResponseHeader: keactrl.ResponseHeader{
+						Result: 0,
+					},
+					Arguments: &StatLeaseGetArgs{
+						ResultSet: ResultSetInStatLeaseGet{
+							Columns: []string{"subnet-id", "total-addresses", "assigned-addresses", "declined-addresses"},
+							Rows: [][]int64{
+								// All addresses assigned.
+								{10, 256, 256, 0},
+								// All addresses assigned but there are two
+								// available out-of-pool reservations.
+								{20, 4098, 4098, 0},
+							},
+						},
+						Timestamp: "2018-05-04 15:03:37.000000",
+					},
+				},
+			},
+			[]StatGetResponse4{
+				{
+					ResponseHeader: keactrl.ResponseHeader{
+						Result: 0,
+					},
+					Arguments: &ResponseArguments4{
+						Samples: []interface{}{
+							[]interface{}{44, "2019-07-30 10:13:00.000000"},
+						},
+					},
+				},
+			},
+		}
+		var jsons []string
+		for _, item := range data {
+			j, _ := json.Marshal(item)
+			jsons = append(jsons, string(j))
+		}
+		return jsons
+	})
+	fa := agentcommtest.NewFakeAgents(keaMock, nil)
+
+	eventCenter := &storktest.FakeEventCenter{}
+	sp, err := NewStatsPuller(db, fa, eventCenter, nil)
+	require.NoError(t, err)
+	defer sp.Shutdown()
+
+	// Act
+	err = sp.pullStats()
+
+	// Assert
+	require.NoError(t, err)
+
+	dbSubnets, err := dbmodel.GetAllSubnets(db, 0)
+	require.NoError(t, err)
+	require.Len(t, dbSubnets, 2)
+
+	var exhaustedSubnetID int64
+	for _, sn := range dbSubnets {
+		switch sn.LocalSubnets[0].LocalSubnetID {
+		case 10:
+			require.True(t, sn.Exhausted)
+			exhaustedSubnetID = sn.ID
+		case 20:
+			require.False(t, sn.Exhausted)
+		}
+	}
+	require.NotZero(t, exhaustedSubnetID)
+
+	require.Len(t, eventCenter.Events, 1)
+	require.EqualValues(t, dbmodel.EvError, eventCenter.Events[0].Level)
+	require.Contains(t, eventCenter.Events[0].Text, "is exhausted")
+	require.NotNil(t, eventCenter.Events[0].Relations)
+	require.EqualValues(t, exhaustedSubnetID, eventCenter.Events[0].Relations.SubnetID)
+
+	// Act
+	// The subnet is still exhausted. The event should not be repeated.
+	err = sp.pullStats()
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, eventCenter.Events, 1)
+}
+
+// Test that the timestamp is extracted from the lease statistics response.
+func TestGetStatLeaseGetTimestamp(t *testing.T) {
+	response := &[]StatLeaseGetResponse{
+		{
+			Arguments: &StatLeaseGetArgs{
+				Timestamp: "2018-05-04 15:03:37.000000",
+			},
+		},
+	}
+	require.Equal(t, "2018-05-04 15:03:37.000000", getStatLeaseGetTimestamp(response))
+
+	require.Empty(t, getStatLeaseGetTimestamp(&[]StatLeaseGetResponse{{}}))
+	require.Empty(t, getStatLeaseGetTimestamp(&[]StatLeaseGetResponse{}))
+	require.Empty(t, getStatLeaseGetTimestamp(nil))
+}
+
+// Test that the warning is raised when the lease statistics timestamp
+// doesn't advance across several polls.
+func TestCheckStaleStats(t *testing.T) {
+	// Arrange
+	eventCenter := &storktest.FakeEventCenter{}
+	sp := &StatsPuller{EventCenter: eventCenter}
+	daemon := dbmodel.NewKeaDaemon(dhcp4, true)
+	daemon.ID = 1
+	timestamp := "2018-05-04 15:03:37.000000"
+
+	// Act & Assert
+	// The first poll records the timestamp.
+	require.False(t, sp.checkStaleStats(daemon, timestamp))
+	// The timestamp doesn't advance but the threshold is not reached yet.
+	for i := 1; i < staleStatsPollsThreshold; i++ {
+		require.False(t, sp.checkStaleStats(daemon, timestamp))
+	}
+	require.Empty(t, eventCenter.Events)
+
+	// The threshold is reached.
+	require.True(t, sp.checkStaleStats(daemon, timestamp))
+	require.Len(t, eventCenter.Events, 1)
+	require.EqualValues(t, dbmodel.EvWarning, eventCenter.Events[0].Level)
+	require.Contains(t, eventCenter.Events[0].Text, "have not been updated since 2018-05-04 15:03:37.000000")
+	require.EqualValues(t, 1, eventCenter.Events[0].Relations.DaemonID)
+
+	// The statistics are still stale but the warning is not repeated.
+	require.True(t, sp.checkStaleStats(daemon, timestamp))
+	require.Len(t, eventCenter.Events, 1)
+
+	// The timestamp advances.
+	timestamp = "2018-05-04 15:04:37.000000"
+	require.False(t, sp.checkStaleStats(daemon, timestamp))
+
+	// The statistics get stuck again and the warning is raised again.
+	for i := 1; i < staleStatsPollsThreshold; i++ {
+		require.False(t, sp.checkStaleStats(daemon, timestamp))
+	}
+	require.True(t, sp.checkStaleStats(daemon, timestamp))
+	require.Len(t, eventCenter.Events, 2)
+}
+
+// Test that the lease statistics timestamps are tracked per daemon.
+func TestCheckStaleStatsPerDaemon(t *testing.T) {
+	// Arrange
+	eventCenter := &storktest.FakeEventCenter{}
+	sp := &StatsPuller{EventCenter: eventCenter}
+	daemon4 := dbmodel.NewKeaDaemon(dhcp4, true)
+	daemon4.ID = 1
+	daemon6 := dbmodel.NewKeaDaemon(dhcp6, true)
+	daemon6.ID = 2
+
+	// Act
+	// Only the DHCPv4 daemon returns the identical timestamps.
+	for i := 0; i <= staleStatsPollsThreshold; i++ {
+		sp.checkStaleStats(daemon4, "2018-05-04 15:03:37.000000")
+		sp.checkStaleStats(daemon6, fmt.Sprintf("2018-05-04 15:03:%02d.000000", i))
+	}
+
+	// Assert
+	require.Len(t, eventCenter.Events, 1)
+	require.EqualValues(t, 1, eventCenter.Events[0].Relations.DaemonID)
+}
+
+// Test that the missing timestamp is ignored.
+func TestCheckStaleStatsNoTimestamp(t *testing.T) {
+	eventCenter := &storktest.FakeEventCenter{}
+	sp := &StatsPuller{EventCenter: eventCenter}
+	daemon := dbmodel.NewKeaDaemon(dhcp4, true)
+
+	for i := 0; i <= staleStatsPollsThreshold; i++ {
+		require.False(t, sp.checkStaleStats(daemon, ""))
+	}
+	require.Empty(t, eventCenter.Events)
+}
+
+// Test that the diagnostic state of the stats puller includes the RPS
+// values, the app error counts and the lease statistics timestamps.
+func TestStatsPullerGetDiagnosticState(t *testing.T) {
+	// Arrange
+	rpsWorker, err := NewRpsWorker(nil, nil)
+	require.NoError(t, err)
+	rpsWorker.recordPreviousRps(3, StatSample{Value: 42})
+	rpsWorker.EndCycle([]int64{3})
+
+	statsPuller := &StatsPuller{RpsWorker: rpsWorker}
+	statsPuller.appErrors = map[int64]int{1: 2}
+	statsPuller.statsTimestamps = map[int64]*statsTimestampState{
+		3: {timestamp: "2024-01-01 10:00:00.000000", unchangedPolls: 4, reported: true},
+	}
+
+	// Act
+	state := statsPuller.GetDiagnosticState()
+	serialized, err := json.Marshal(state)
+
+	// Assert
+	require.NoError(t, err)
+	require.EqualValues(t, 42, state.PreviousRps[3].Value)
+	require.EqualValues(t, 1, state.RpsCycle)
+	require.Equal(t, 2, state.AppErrors[1])
+	require.Equal(t, StatsTimestampState{
+		Timestamp:      "2024-01-01 10:00:00.000000",
+		UnchangedPolls: 4,
+		Reported:       true,
+	}, state.StatsTimestamps[3])
+
+	var parsed map[string]interface{}
+	err = json.Unmarshal(serialized, &parsed)
+	require.NoError(t, err)
+	for _, key := range []string{"LastInvokedAt", "LastFinishedAt", "PreviousRps", "RpsCycle", "AppErrors", "StatsTimestamps"} {
+		require.Contains(t, parsed, key)
+	}
+
+	// The snapshot is independent of the puller state.
+	state.AppErrors[1] = 5
+	require.Equal(t, 2, statsPuller.appErrors[1])
+}
+
+// Test that the diagnostic state is returned without waiting for the
+// ongoing stats pull to complete.
+func TestStatsPullerGetDiagnosticStateDuringPull(t *testing.T) {
+	// Arrange
+	rpsWorker, err := NewRpsWorker(nil, nil)
+	require.NoError(t, err)
+	rpsWorker.recordPreviousRps(3, StatSample{Value: 42})
+
+	statsPuller := &StatsPuller{RpsWorker: rpsWorker}
+	// Simulate the ongoing pull.
+	statsPuller.pullMutex.Lock()
+	defer statsPuller.pullMutex.Unlock()
+
+	// Act
+	stateCh := make(chan *StatsPullerState, 1)
+	go func() {
+		stateCh <- statsPuller.GetDiagnosticState()
+	}()
+
+	// Assert
+	select {
+	case state := <-stateCh:
+		require.EqualValues(t, 42, state.PreviousRps[3].Value)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "getting the diagnostic state waits for the ongoing pull")
+	}
+}
+
+// Test that only the configured statistics are kept, including the
+// pool-level statistics matched by their names without the pool prefix.
+func TestStatsPullerFilterStats(t *testing.T) {
+	// Arrange
+	statsPuller := &StatsPuller{}
+	statsPuller.SetStoredStats([]string{"total-addresses", " assigned-addresses", ""})
+	stats := dbmodel.SubnetStats{
+		"total-addresses":            uint64(256),
+		"assigned-addresses":         uint64(111),
+		"declined-addresses":         uint64(2),
+		"pool[0].total-addresses":    uint64(100),
+		"pool[0].declined-addresses": uint64(1),
+		"pd-pool[0].assigned-pds":    uint64(3),
+	}
+
+	// Act
+	statsPuller.filterStats(stats)
+
+	// Assert
+	require.Len(t, stats, 3)
+	require.Contains(t, stats, "total-addresses")
+	require.Contains(t, stats, "assigned-addresses")
+	require.Contains(t, stats, "pool[0].total-addresses")
+}
+
+// Test that all statistics are kept by default and when the list of the
+// stored statistics is empty.
+func TestStatsPullerFilterStatsDefault(t *testing.T) {
+	// Arrange
+	statsPuller := &StatsPuller{}
+	stats := dbmodel.SubnetStats{
+		"total-addresses":    uint64(256),
+		"declined-addresses": uint64(2),
+	}
+
+	// Act
+	statsPuller.filterStats(stats)
+	statsPuller.SetStoredStats([]string{"total-addresses"})
+	statsPuller.SetStoredStats([]string{""})
+	statsPuller.filterStats(stats)
+
+	// Assert
+	require.Len(t, stats, 2)
+}
+
+// Test that only the configured statistics are stored in the database.
+func TestStatsPullerPullStatsStoredStats(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+	_ = dbmodel.InitializeSettings(db, 0)
+	_ = dbmodel.InitializeStats(db)
+
+	v4Config, v6Config := createDhcpConfigs()
+	app := createAppWithSubnets(t, db, 0, v4Config, v6Config)
+
+	keaMock := createStandardKeaMock(false)
+
+	fa := agentcommtest.NewFakeAgents(keaMock, nil)
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	for i := range app.Daemons {
+		sharedNetworks, subnets, err := detectDaemonNetworks(db, app.Daemons[i], lookup)
+		require.NoError(t, err)
+		_, err = dbmodel.CommitNetworksIntoDB(db, sharedNetworks, subnets, app.Daemons[i])
+		require.NoError(t, err)
+	}
+
+	sp, _ := NewStatsPuller(db, fa, &storktest.FakeEventCenter{}, nil)
+	defer sp.Shutdown()
+	sp.SetStoredStats([]string{"total-addresses", "assigned-addresses"})
+
+	// Act
+	err := sp.pullStats()
+
+	// Assert
+	require.NoError(t, err)
+
+	localSubnets := []*dbmodel.LocalSubnet{}
+	err = db.Model(&localSubnets).Select()
+	require.NoError(t, err)
+	for _, localSubnet := range localSubnets {
+		if localSubnet.LocalSubnetID != 10 {
+			continue
+		}
+		require.Len(t, localSubnet.Stats, 2)
+		require.EqualValues(t, 111, localSubnet.Stats["assigned-addresses"])
+		require.EqualValues(t, 256, localSubnet.Stats["total-addresses"])
+	}
+}
+
+// Test that the delegated prefix utilization by the delegated prefix
+// length is stored in the local subnets.
+func TestStatsPullerPullStatsPdUtilizationByDelegatedLen(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+	_ = dbmodel.InitializeSettings(db, 0)
+	_ = dbmodel.InitializeStats(db)
+
+	v4Config, v6Config := createDhcpConfigs()
+	app := createAppWithSubnets(t, db, 0, v4Config, v6Config)
+
+	keaMock := createStandardKeaMock(false)
+
+	fa := agentcommtest.NewFakeAgents(keaMock, nil)
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	for i := range app.Daemons {
+		sharedNetworks, subnets, err := detectDaemonNetworks(db, app.Daemons[i], lookup)
+		require.NoError(t, err)
+		_, err = dbmodel.CommitNetworksIntoDB(db, sharedNetworks, subnets, app.Daemons[i])
+		require.NoError(t, err)
+	}
+
+	sp, _ := NewStatsPuller(db, fa, &storktest.FakeEventCenter{}, nil)
+	defer sp.Shutdown()
+
+	// Act
+	err := sp.pullStats()
+
+	// Assert
+	require.NoError(t, err)
+
+	localSubnets := []*dbmodel.LocalSubnet{}
+	err = db.Model(&localSubnets).Relation("Daemon").Select()
+	require.NoError(t, err)
+	checked := false
+	for _, localSubnet := range localSubnets {
+		switch {
+		case localSubnet.Daemon.Name == dhcp4:
+			require.Empty(t, localSubnet.PdUtilizationByDelegatedLen)
+		case localSubnet.LocalSubnetID == 50:
+			// The subnet includes a /48 pool delegating the /64 prefixes.
+			require.Len(t, localSubnet.PdUtilizationByDelegatedLen, 1)
+			require.InDelta(t, 15.0/65536.0, localSubnet.PdUtilizationByDelegatedLen[64], 1e-9)
+			checked = true
+		}
+	}
+	require.True(t, checked)
+}
+
+// Test that the subnet utilization samples are recorded on each pull when
+// the history retention is enabled.
+func TestStatsPullerPullStatsUtilizationSamples(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+	_ = dbmodel.InitializeSettings(db, 0)
+	_ = dbmodel.InitializeStats(db)
+
+	v4Config, v6Config := createDhcpConfigs()
+	app := createAppWithSubnets(t, db, 0, v4Config, v6Config)
+
+	keaMock := createStandardKeaMock(false)
+
+	fa := agentcommtest.NewFakeAgents(keaMock, nil)
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	for i := range app.Daemons {
+		sharedNetworks, subnets, err := detectDaemonNetworks(db, app.Daemons[i], lookup)
+		require.NoError(t, err)
+		_, err = dbmodel.CommitNetworksIntoDB(db, sharedNetworks, subnets, app.Daemons[i])
+		require.NoError(t, err)
+	}
+
+	sp, _ := NewStatsPuller(db, fa, &storktest.FakeEventCenter{}, nil)
+	defer sp.Shutdown()
+
+	// The samples are not recorded by default.
+	err := sp.pullStats()
+	require.NoError(t, err)
+	count, err := db.Model((*dbmodel.SubnetUtilizationSample)(nil)).Count()
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	_ = dbmodel.SetSettingInt(db, "subnet_utilization_history_retention", 24)
+
+	// Act
+	err = sp.pullStats()
+	require.NoError(t, err)
+	err = sp.pullStats()
+	require.NoError(t, err)
+
+	// Assert
+	subnets, err := dbmodel.GetAllSubnets(db, 0)
+	require.NoError(t, err)
+	require.NotEmpty(t, subnets)
+	for _, subnet := range subnets {
+		samples, err := dbmodel.GetSubnetUtilizationSamples(db, subnet.ID, time.Time{})
+		require.NoError(t, err)
+		require.Len(t, samples, 2)
+		require.Equal(t, subnet.StatsCollectedAt, samples[1].SampledAt)
+		require.Equal(t, subnet.AddrUtilization, samples[1].AddrUtilization)
+		require.Equal(t, subnet.PdUtilization, samples[1].PdUtilization)
+	}
+}
+
+// Test that the subnet utilization samples older than the retention time
+// are pruned according to the puller's clock.
+func TestStatsPullerPullStatsUtilizationSamplesPruned(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+	_ = dbmodel.InitializeSettings(db, 0)
+	_ = dbmodel.InitializeStats(db)
+	_ = dbmodel.SetSettingInt(db, "subnet_utilization_history_retention", 24)
+
+	v4Config, v6Config := createDhcpConfigs()
+	app := createAppWithSubnets(t, db, 0, v4Config, v6Config)
+
+	fa := agentcommtest.NewFakeAgents(createStandardKeaMock(false), nil)
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	for i := range app.Daemons {
+		sharedNetworks, subnets, err := detectDaemonNetworks(db, app.Daemons[i], lookup)
+		require.NoError(t, err)
+		_, err = dbmodel.CommitNetworksIntoDB(db, sharedNetworks, subnets, app.Daemons[i])
+		require.NoError(t, err)
+	}
+
+	clock := testutil.NewFakeClock(time.Now().UTC())
+	sp, _ := NewStatsPuller(db, fa, &storktest.FakeEventCenter{}, clock)
+	defer sp.Shutdown()
+
+	// The samples are recorded within the retention time.
+	err := sp.pullStats()
+	require.NoError(t, err)
+	count, err := db.Model((*dbmodel.SubnetUtilizationSample)(nil)).Count()
+	require.NoError(t, err)
+	require.NotZero(t, count)
+
+	// Act
+	// All samples, including the new ones, are sampled before the
+	// retention time.
+	clock.Advance(48 * time.Hour)
+	err = sp.pullStats()
+
+	// Assert
+	require.NoError(t, err)
+	count, err = db.Model((*dbmodel.SubnetUtilizationSample)(nil)).Count()
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+// Test that the pool-indexed columns of the stat-lease4-get result set are
+// stored as the pool-level statistics, and the subnets lacking the pool
+// columns values are tolerated.
+func TestStatsPullerPullStatsPoolColumns(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+	_ = dbmodel.InitializeSettings(db, 0)
+	_ = dbmodel.InitializeStats(db)
+
+	v4Config, v6Config := createDhcpConfigs()
+	app := createAppWithSubnets(t, db, 0, v4Config, v6Config)
+
+	standardMock := createStandardKeaMock(false)
+	keaMock := func(callNo int, cmdResponses []interface{}) {
+		standardMock(callNo, cmdResponses)
+		response := cmdResponses[0].(*[]StatLeaseGetResponse)
+		(*response)[0].Arguments.ResultSet = ResultSetInStatLeaseGet{
+			Columns: []string{
+				"subnet-id", "total-addresses", "assigned-addresses", "declined-addresses",
+				"pool0-total-addresses", "pool0-assigned-addresses",
+				"pool1-total-addresses", "pool1-assigned-addresses",
+			},
+			Rows: [][]int64{
+				{10, 256, 111, 0, 100, 100, 156, 11},
+				// The subnet lacks the pools.
+				{20, 4098, 2034, 4, -1, -1, -1, -1},
+			},
+		}
+	}
+	fa := agentcommtest.NewFakeAgents(keaMock, nil)
+
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	for i := range app.Daemons {
+		sharedNetworks, subnets, err := detectDaemonNetworks(db, app.Daemons[i], lookup)
+		require.NoError(t, err)
+		_, err = dbmodel.CommitNetworksIntoDB(db, sharedNetworks, subnets, app.Daemons[i])
+		require.NoError(t, err)
+	}
+
+	sp, _ := NewStatsPuller(db, fa, &storktest.FakeEventCenter{}, nil)
+	defer sp.Shutdown()
+
+	// Act
+	err := sp.pullStats()
+
+	// Assert
+	require.NoError(t, err)
+
+	subnets, err := dbmodel.GetAllSubnets(db, 4)
+	require.NoError(t, err)
+	require.Len(t, subnets, 2)
+	for _, subnet := range subnets {
+		localSubnet := subnet.LocalSubnets[0]
+		poolStats := localSubnet.GetPoolStats()
+		switch localSubnet.LocalSubnetID {
+		case 10:
+			require.EqualValues(t, 256, localSubnet.Stats["total-addresses"])
+			require.Len(t, poolStats, 2)
+			require.EqualValues(t, 100, poolStats[0]["total-addresses"])
+			require.EqualValues(t, 11, poolStats[1]["assigned-addresses"])
+			require.EqualValues(t, 1.0, poolStats.GetUtilization(0))
+			// The exhausted pool is found in the subnet that isn't exhausted.
+			require.EqualValues(t, 0, subnet.Stats["most-utilized-pool-id"])
+			require.EqualValues(t, 1.0, subnet.Stats["most-utilized-pool-utilization"])
+			require.False(t, subnet.Exhausted)
+		case 20:
+			require.EqualValues(t, 2034, localSubnet.Stats["assigned-addresses"])
+			require.Empty(t, poolStats)
+			require.NotContains(t, subnet.Stats, "most-utilized-pool-id")
+		default:
+			require.Fail(t, "unexpected subnet", localSubnet.LocalSubnetID)
+		}
+	}
+}
+
+// Test that the lease reclamation counters are stored in the local subnet
+// statistics and totaled in the global statistics. The columns are
+// matched by name regardless of their order.
+func TestStatsPullerPullStatsReclaimedLeases(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+	_ = dbmodel.InitializeSettings(db, 0)
+	_ = dbmodel.InitializeStats(db)
+
+	v4Config, v6Config := createDhcpConfigs()
+	app := createAppWithSubnets(t, db, 0, v4Config, v6Config)
+
+	standardMock := createStandardKeaMock(false)
+	keaMock := func(callNo int, cmdResponses []interface{}) {
+		standardMock(callNo, cmdResponses)
+		response := cmdResponses[0].(*[]StatLeaseGetResponse)
+		(*response)[0].Arguments.ResultSet = ResultSetInStatLeaseGet{
+			Columns: []string{
+				"reclaimed-leases", "subnet-id", "assigned-addresses", "total-addresses",
+				"reclaimed-declined-addresses", "declined-addresses",
+			},
+			Rows: [][]int64{
+				{30, 10, 111, 256, 2, 0},
+				{5, 20, 2034, 4098, 1, 4},
+			},
+		}
+	}
+	fa := agentcommtest.NewFakeAgents(keaMock, nil)
+
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	for i := range app.Daemons {
+		sharedNetworks, subnets, err := detectDaemonNetworks(db, app.Daemons[i], lookup)
+		require.NoError(t, err)
+		_, err = dbmodel.CommitNetworksIntoDB(db, sharedNetworks, subnets, app.Daemons[i])
+		require.NoError(t, err)
+	}
+
+	sp, _ := NewStatsPuller(db, fa, &storktest.FakeEventCenter{}, nil)
+	defer sp.Shutdown()
+
+	// Act
+	err := sp.pullStats()
+
+	// Assert
+	require.NoError(t, err)
+
+	subnets, err := dbmodel.GetAllSubnets(db, 4)
+	require.NoError(t, err)
+	require.Len(t, subnets, 2)
+	for _, subnet := range subnets {
+		localSubnet := subnet.LocalSubnets[0]
+		switch localSubnet.LocalSubnetID {
+		case 10:
+			require.EqualValues(t, 256, localSubnet.Stats["total-addresses"])
+			require.EqualValues(t, 111, localSubnet.Stats["assigned-addresses"])
+			require.EqualValues(t, 30, localSubnet.Stats["reclaimed-leases"])
+			require.EqualValues(t, 2, localSubnet.Stats["reclaimed-declined-addresses"])
+		case 20:
+			require.EqualValues(t, 4098, localSubnet.Stats["total-addresses"])
+			require.EqualValues(t, 5, localSubnet.Stats["reclaimed-leases"])
+			require.EqualValues(t, 1, localSubnet.Stats["reclaimed-declined-addresses"])
+		default:
+			require.Fail(t, "unexpected subnet", localSubnet.LocalSubnetID)
+		}
+	}
+
+	globals, err := dbmodel.GetAllStats(db)
+	require.NoError(t, err)
+	require.EqualValues(t, big.NewInt(35), globals["reclaimed-leases"])
+	require.EqualValues(t, big.NewInt(3), globals["reclaimed-declined-addresses"])
+}
+
+// Test that the global statistics refresh is due during the first pull,
+// when the interval is zero and when the interval elapsed.
+func TestStatsPullerIsGlobalStatsRefreshDue(t *testing.T) {
+	// Arrange
+	clock := testutil.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	statsPuller := &StatsPuller{clock: clock}
+
+	// Act & Assert
+	require.True(t, statsPuller.isGlobalStatsRefreshDue(5*time.Minute))
+
+	statsPuller.globalStatsRefreshedAt = clock.Now()
+	require.False(t, statsPuller.isGlobalStatsRefreshDue(5*time.Minute))
+	require.True(t, statsPuller.isGlobalStatsRefreshDue(0))
+
+	clock.Advance(4 * time.Minute)
+	require.False(t, statsPuller.isGlobalStatsRefreshDue(5*time.Minute))
+
+	clock.Advance(time.Minute)
+	require.True(t, statsPuller.isGlobalStatsRefreshDue(5*time.Minute))
+}
+
+// Returns the assigned-addresses statistic of the local subnet with ID 10.
+func getStatsPullerTestAssignedAddresses(t *testing.T, db *pg.DB) uint64 {
+	localSubnets := []*dbmodel.LocalSubnet{}
+	err := db.Model(&localSubnets).Where("local_subnet_id = ?", 10).Select()
+	require.NoError(t, err)
+	require.Len(t, localSubnets, 1)
+	return localSubnets[0].Stats["assigned-addresses"].(uint64)
+}
+
+// Test that the global statistics are refreshed at their own interval while
+// the subnet statistics are refreshed during each pull.
+func TestStatsPullerPullStatsGlobalStatsInterval(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+	_ = dbmodel.InitializeSettings(db, 0)
+	_ = dbmodel.InitializeStats(db)
+	err := dbmodel.SetSettingInt(db, "kea_global_stats_interval", 300)
+	require.NoError(t, err)
+
+	v4Config, v6Config := createDhcpConfigs()
+	app := createAppWithSubnets(t, db, 0, v4Config, v6Config)
+
+	// The mock returns the statistics increased by 100 with each call.
+	keaMock := createStandardKeaMock(false)
+
+	fa := agentcommtest.NewFakeAgents(keaMock, nil)
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	for i := range app.Daemons {
+		sharedNetworks, subnets, err := detectDaemonNetworks(db, app.Daemons[i], lookup)
+		require.NoError(t, err)
+		_, err = dbmodel.CommitNetworksIntoDB(db, sharedNetworks, subnets, app.Daemons[i])
+		require.NoError(t, err)
+	}
+
+	clock := testutil.NewFakeClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	sp, _ := NewStatsPuller(db, fa, &storktest.FakeEventCenter{}, clock)
+	defer sp.Shutdown()
+
+	// Act
+	// The global statistics are always refreshed during the first pull.
+	err = sp.pullStats()
+	require.NoError(t, err)
+	subnetStats1 := getStatsPullerTestAssignedAddresses(t, db)
+	globalStats1, _ := dbmodel.GetAllStats(db)
+
+	// The interval hasn't elapsed yet.
+	clock.Advance(time.Minute)
+	err = sp.pullStats()
+	require.NoError(t, err)
+	subnetStats2 := getStatsPullerTestAssignedAddresses(t, db)
+	globalStats2, _ := dbmodel.GetAllStats(db)
+
+	// The interval elapsed.
+	clock.Advance(4 * time.Minute)
+	err = sp.pullStats()
+	require.NoError(t, err)
+	subnetStats3 := getStatsPullerTestAssignedAddresses(t, db)
+	globalStats3, _ := dbmodel.GetAllStats(db)
+
+	// Assert
+	require.Greater(t, subnetStats2, subnetStats1)
+	require.Greater(t, subnetStats3, subnetStats2)
+
+	require.NotZero(t, globalStats1["assigned-addresses"].Int64())
+	require.Equal(t, globalStats1["assigned-addresses"], globalStats2["assigned-addresses"])
+	require.Greater(t, globalStats3["assigned-addresses"].Int64(), globalStats2["assigned-addresses"].Int64())
+	require.Equal(t, clock.Now(), sp.GetDiagnosticState().GlobalStatsRefreshedAt)
+}
+
+// Test parsing the stats pull intervals overriding the global interval
+// for the selected apps.
+func TestParseAppPullIntervals(t *testing.T) {
+	intervals, err := parseAppPullIntervals(" 3:300, 5 : 60,7:0,")
+	require.NoError(t, err)
+	require.Equal(t, map[int64]int64{3: 300, 5: 60, 7: 0}, intervals)
+
+	intervals, err = parseAppPullIntervals("")
+	require.NoError(t, err)
+	require.Empty(t, intervals)
+
+	for _, spec := range []string{"3", "foo:300", "0:300", "3:foo", "3:-1", "3:300,5"} {
+		_, err = parseAppPullIntervals(spec)
+		require.Error(t, err, spec)
+	}
+}
+
+// Test that the stats are pulled from the app with the overridden interval
+// by its own puller instead of the main puller, and that the app puller
+// is stopped when the override is removed.
+func TestStatsPullerPullStatsAppInterval(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+	_ = dbmodel.InitializeSettings(db, 0)
+	_ = dbmodel.InitializeStats(db)
+
+	v4Config, v6Config := createDhcpConfigs()
+	app := createAppWithSubnets(t, db, 0, v4Config, v6Config)
+
+	// The zero interval keeps the app puller inactive, so the stats are
+	// pulled only explicitly by the test.
+	err := dbmodel.SetSettingStr(db, "kea_stats_puller_app_intervals", fmt.Sprintf("%d:0", app.ID))
+	require.NoError(t, err)
+
+	fa := agentcommtest.NewFakeAgents(createStandardKeaMock(false), nil)
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	for i := range app.Daemons {
+		sharedNetworks, subnets, err := detectDaemonNetworks(db, app.Daemons[i], lookup)
+		require.NoError(t, err)
+		_, err = dbmodel.CommitNetworksIntoDB(db, sharedNetworks, subnets, app.Daemons[i])
+		require.NoError(t, err)
+	}
+
+	sp, _ := NewStatsPuller(db, fa, &storktest.FakeEventCenter{}, nil)
+	defer sp.Shutdown()
+
+	// Act & Assert
+	// The app puller is started with the stats puller.
+	require.Contains(t, sp.appPullers, app.ID)
+
+	// The main puller skips the app.
+	err = sp.pullStats()
+	require.NoError(t, err)
+	require.Zero(t, fa.CallNo)
+	require.Contains(t, sp.appPullers, app.ID)
+
+	// The app puller pulls the stats from the app and updates the
+	// utilization of its subnets.
+	err = sp.pullAppStats(app.ID)
+	require.NoError(t, err)
+	require.NotZero(t, fa.CallNo)
+	require.NotZero(t, getStatsPullerTestAssignedAddresses(t, db))
+	subnets, err := dbmodel.GetAllSubnets(db, 0)
+	require.NoError(t, err)
+	utilized := false
+	for _, sn := range subnets {
+		if sn.AddrUtilization > 0 || sn.PdUtilization > 0 {
+			utilized = true
+			break
+		}
+	}
+	require.True(t, utilized)
+
+	// The app is pulled by the main puller when the override is removed.
+	err = dbmodel.SetSettingStr(db, "kea_stats_puller_app_intervals", "")
+	require.NoError(t, err)
+	callNo := fa.CallNo
+	err = sp.pullStats()
+	require.NoError(t, err)
+	require.Greater(t, fa.CallNo, callNo)
+	require.Empty(t, sp.appPullers)
+}
+
+// Test that the app pullers are started even if the main puller is
+// disabled.
+func TestStatsPullerAppIntervalMainPullerDisabled(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+	_ = dbmodel.InitializeSettings(db, 0)
+
+	app := createAppWithSubnets(t, db, 0, `{"Dhcp4": {}}`, `{"Dhcp6": {}}`)
+	err := dbmodel.SetSettingInt(db, "kea_stats_puller_interval", 0)
+	require.NoError(t, err)
+	err = dbmodel.SetSettingStr(db, "kea_stats_puller_app_intervals", fmt.Sprintf("%d:0", app.ID))
+	require.NoError(t, err)
+
+	fa := agentcommtest.NewFakeAgents(createStandardKeaMock(false), nil)
+
+	// Act
+	sp, err := NewStatsPuller(db, fa, &storktest.FakeEventCenter{}, nil)
+	require.NoError(t, err)
+	defer sp.Shutdown()
+
+	// Assert
+	require.Contains(t, sp.appPullers, app.ID)
+	require.NotNil(t, sp.appPullersSyncer)
+}
+
+// Test that the stats can be pulled only from the active Kea DHCP daemons
+// having the statistic hook or an unknown configuration.
+func TestIsStatsDaemon(t *testing.T) {
+	// Arrange
+	withHook, err := dbmodel.NewKeaConfigFromJSON(`{
+		"Dhcp4": {
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/libdhcp_stat_cmds.so"
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+	withoutHook, err := dbmodel.NewKeaConfigFromJSON(`{"Dhcp4": {}}`)
+	require.NoError(t, err)
+
+	// Act & Assert
+	require.True(t, isStatsDaemon(&dbmodel.Daemon{
+		Name: dhcp4, Active: true, KeaDaemon: &dbmodel.KeaDaemon{Config: withHook},
+	}))
+	require.True(t, isStatsDaemon(&dbmodel.Daemon{
+		Name: dhcp6, Active: true, KeaDaemon: &dbmodel.KeaDaemon{},
+	}))
+	require.False(t, isStatsDaemon(&dbmodel.Daemon{
+		Name: dhcp4, Active: true, KeaDaemon: &dbmodel.KeaDaemon{Config: withoutHook},
+	}))
+	require.False(t, isStatsDaemon(&dbmodel.Daemon{
+		Name: dhcp4, Active: false, KeaDaemon: &dbmodel.KeaDaemon{Config: withHook},
+	}))
+	require.False(t, isStatsDaemon(&dbmodel.Daemon{
+		Name: "d2", Active: true, KeaDaemon: &dbmodel.KeaDaemon{},
+	}))
+	require.False(t, isStatsDaemon(&dbmodel.Daemon{
+		Name: dhcp4, Active: true,
+	}))
+}
+
+// Test that the stats are pulled on demand from a single app.
+func TestStatsPullerPullStatsForApp(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+	_ = dbmodel.InitializeSettings(db, 0)
+	_ = dbmodel.InitializeStats(db)
+
+	v4Config, v6Config := createDhcpConfigs()
+	app := createAppWithSubnets(t, db, 0, v4Config, v6Config)
+	// The other app is not pulled.
+	_ = createAppWithSubnets(t, db, 1, v4Config, v6Config)
+
+	fa := agentcommtest.NewFakeAgents(createStandardKeaMock(false), nil)
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	for i := range app.Daemons {
+		sharedNetworks, subnets, err := detectDaemonNetworks(db, app.Daemons[i], lookup)
+		require.NoError(t, err)
+		_, err = dbmodel.CommitNetworksIntoDB(db, sharedNetworks, subnets, app.Daemons[i])
+		require.NoError(t, err)
+	}
+
+	sp, _ := NewStatsPuller(db, fa, &storktest.FakeEventCenter{}, nil)
+	defer sp.Shutdown()
+
+	// Act
+	err := sp.PullStatsForApp(app.ID)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, 1, fa.CallNo)
+	require.NotZero(t, getStatsPullerTestAssignedAddresses(t, db))
+	require.Empty(t, sp.appErrors)
+
+	// The utilization of the app's subnets is updated without waiting
+	// for the main puller.
+	subnets, err := dbmodel.GetAllSubnets(db, 0)
+	require.NoError(t, err)
+	utilized := false
+	for _, sn := range subnets {
+		if sn.AddrUtilization > 0 || sn.PdUtilization > 0 {
+			utilized = true
+			break
+		}
+	}
+	require.True(t, utilized)
+
+	// The global statistics are not refreshed from a single app.
+	require.Zero(t, sp.GetDiagnosticState().GlobalStatsRefreshedAt)
+}
+
+// Test that the subnets of the app and the other subnets sharing the
+// shared networks with them are selected.
+func TestSelectAppSubnets(t *testing.T) {
+	// Arrange
+	dbApp := &dbmodel.App{
+		Daemons: []*dbmodel.Daemon{{ID: 1}, {ID: 2}},
+	}
+	subnets := []*dbmodel.Subnet{
+		// The app's subnet outside of the shared network.
+		{ID: 1, LocalSubnets: []*dbmodel.LocalSubnet{{DaemonID: 1}}},
+		// The app's subnet in the shared network.
+		{ID: 2, SharedNetworkID: 10, LocalSubnets: []*dbmodel.LocalSubnet{{DaemonID: 2}, {DaemonID: 3}}},
+		// The other app's subnet in the same shared network.
+		{ID: 3, SharedNetworkID: 10, LocalSubnets: []*dbmodel.LocalSubnet{{DaemonID: 3}}},
+		// The other app's subnet in another shared network.
+		{ID: 4, SharedNetworkID: 20, LocalSubnets: []*dbmodel.LocalSubnet{{DaemonID: 3}}},
+		// The other app's subnet outside of the shared network.
+		{ID: 5, LocalSubnets: []*dbmodel.LocalSubnet{{DaemonID: 4}}},
+	}
+
+	// Act
+	selected := selectAppSubnets(subnets, dbApp)
+
+	// Assert
+	require.Len(t, selected, 3)
+	require.EqualValues(t, 1, selected[0].ID)
+	require.EqualValues(t, 2, selected[1].ID)
+	require.EqualValues(t, 3, selected[2].ID)
+}
+
+// Test that pulling the stats on demand fails for a non-existing app and
+// for an app without the statistic hook.
+func TestStatsPullerPullStatsForAppError(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+	_ = dbmodel.InitializeSettings(db, 0)
+
+	app := createAppWithSubnets(t, db, 0, `{"Dhcp4": {}}`, `{"Dhcp6": {}}`)
+
+	fa := agentcommtest.NewFakeAgents(createStandardKeaMock(false), nil)
+	sp, _ := NewStatsPuller(db, fa, &storktest.FakeEventCenter{}, nil)
+	defer sp.Shutdown()
+
+	t.Run("app not found", func(t *testing.T) {
+		// Act
+		err := sp.PullStatsForApp(app.ID + 1)
+
+		// Assert
+		require.ErrorContains(t, err, "not found")
+	})
+
+	t.Run("no statistic hook", func(t *testing.T) {
+		// Act
+		err := sp.PullStatsForApp(app.ID)
+
+		// Assert
+		require.ErrorContains(t, err, "libdhcp_stat_cmds")
+	})
+
+	require.Zero(t, fa.CallNo)
+}
+
+// Test that shutting down the stats puller stops the app pullers.
+func TestStatsPullerShutdownAppPullers(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+	_ = dbmodel.InitializeSettings(db, 0)
+	err := dbmodel.SetSettingStr(db, "kea_stats_puller_app_intervals", "1:0,2:0")
+	require.NoError(t, err)
+
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	sp, err := NewStatsPuller(db, fa, &storktest.FakeEventCenter{}, nil)
+	require.NoError(t, err)
+	sp.syncAppPullers(sp.getAppPullIntervals())
+	require.Len(t, sp.appPullers, 2)
+
+	// Act
+	sp.Shutdown()
+
+	// Assert
+	require.Empty(t, sp.appPullers)
+}
+
+// Test that the lease stats format is detected from the column names.
+func TestGetStatLeaseGetFormat(t *testing.T) {
+	for _, testCase := range []struct {
+		columns  []string
+		expected string
+	}{
+		{[]string{"subnet-id", "total-addreses", "assigned-addreses", "declined-addreses"}, dbmodel.KeaStatsFormat16},
+		{[]string{"subnet-id", "total-addresses", "assigned-addresses", "declined-addresses"}, dbmodel.KeaStatsFormat18},
+		{[]string{"subnet-id", "pool0-total-addresses", "total-addreses"}, dbmodel.KeaStatsFormat16},
+		{[]string{"subnet-id", "total-nas", "assigned-nas", "declined-nas"}, ""},
+	} {
+		response := &[]StatLeaseGetResponse{
+			{
+				Arguments: &StatLeaseGetArgs{
+					ResultSet: ResultSetInStatLeaseGet{
+						Columns: testCase.columns,
+					},
+				},
+			},
+		}
+		require.Equal(t, testCase.expected, getStatLeaseGetFormat(response), testCase.columns)
+	}
+
+	require.Empty(t, getStatLeaseGetFormat(&[]StatLeaseGetResponse{}))
+	require.Empty(t, getStatLeaseGetFormat(&[]StatLeaseGetResponse{{}}))
+}
+
+// Test that the warning is raised once when the daemon returns the lease
+// stats in the legacy format.
+func TestStatsPullerCheckStatsFormat(t *testing.T) {
+	// Arrange
+	eventCenter := &storktest.FakeEventCenter{}
+	sp := &StatsPuller{EventCenter: eventCenter}
+	daemon := &dbmodel.Daemon{
+		ID:   1,
+		Name: dhcp4,
+		KeaDaemon: &dbmodel.KeaDaemon{
+			KeaDHCPDaemon: &dbmodel.KeaDHCPDaemon{},
+		},
+	}
+
+	// Act & Assert
+	sp.checkStatsFormat(daemon, "")
+	require.Empty(t, daemon.KeaDaemon.KeaDHCPDaemon.StatsFormat)
+
+	sp.checkStatsFormat(daemon, dbmodel.KeaStatsFormat16)
+	require.Equal(t, dbmodel.KeaStatsFormat16, daemon.KeaDaemon.KeaDHCPDaemon.StatsFormat)
+	require.Len(t, eventCenter.Events, 1)
+	require.EqualValues(t, dbmodel.EvWarning, eventCenter.Events[0].Level)
+	require.Contains(t, eventCenter.Events[0].Text, "consider upgrading Kea")
+	require.EqualValues(t, 1, eventCenter.Events[0].Relations.DaemonID)
+
+	// The warning is not repeated.
+	sp.checkStatsFormat(daemon, dbmodel.KeaStatsFormat16)
+	require.Len(t, eventCenter.Events, 1)
+
+	// No warning is raised after the upgrade.
+	sp.checkStatsFormat(daemon, dbmodel.KeaStatsFormat18)
+	require.Equal(t, dbmodel.KeaStatsFormat18, daemon.KeaDaemon.KeaDHCPDaemon.StatsFormat)
+	require.Len(t, eventCenter.Events, 1)
+}
@@ -3,8 +3,10 @@ package kea
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
+	errors "github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	keactrl "isc.org/stork/appctrl/kea"
 	"isc.org/stork/server/agentcomm"
@@ -67,6 +69,87 @@ type StatusGetRespArgs struct {
 	HA []HARelationshipStatus `json:"high-availability"`
 }
 
+// Maximum number of seconds by which the uptime of a daemon may exceed
+// the uptime of the Control Agent. It covers the delay between starting
+// the daemons and the Control Agent, e.g., by keactrl.
+const maxUptimeSkew int64 = 60
+
+// Checks if the uptime and the time elapsed since the last reload returned
+// in the status-get response are plausible. The daemon can't be reloaded
+// before it has been started, and neither of the values can be negative.
+// The uptime is also compared with the uptime of the Control Agent if its
+// status is specified. The daemons run on the same host as the Control
+// Agent, so they are expected to be started together with it or later,
+// e.g., after a restart. The daemon's uptime significantly exceeding the
+// Control Agent's uptime indicates that they are not time-synced unless
+// the Control Agent has been restarted alone. The implausible values
+// typically indicate clock issues on the host, e.g., the clock having been
+// set back while the daemon was running. A negative reload time results in
+// the reload timestamp in the future.
+func (args *StatusGetRespArgs) validateUptime(caArgs *StatusGetRespArgs) error {
+	switch {
+	case args.Uptime < 0:
+		return errors.Errorf("negative uptime %d s", args.Uptime)
+	case args.Reload < 0:
+		return errors.Errorf("negative time since the last reload %d s", args.Reload)
+	case args.Reload > args.Uptime:
+		return errors.Errorf("time since the last reload %d s exceeds the uptime %d s",
+			args.Reload, args.Uptime)
+	case caArgs == nil:
+		return nil
+	case caArgs.Uptime < 0:
+		return errors.Errorf("negative Control Agent uptime %d s", caArgs.Uptime)
+	case args.Uptime > caArgs.Uptime+maxUptimeSkew:
+		return errors.Errorf("uptime %d s exceeds the Control Agent uptime %d s by more than %d s",
+			args.Uptime, caArgs.Uptime, maxUptimeSkew)
+	}
+	return nil
+}
+
+// Tracks the daemons reporting implausible uptimes. It is used to log the
+// warning about the implausible uptime once, when the daemon transitions
+// from the plausible to the implausible state, rather than on every state
+// pull. It is owned by the state pull settings.
+type ImplausibleUptimeTracker struct {
+	mutex   sync.Mutex
+	daemons map[string]bool
+}
+
+// Creates the tracker of the daemons reporting implausible uptimes.
+func NewImplausibleUptimeTracker() *ImplausibleUptimeTracker {
+	return &ImplausibleUptimeTracker{
+		daemons: make(map[string]bool),
+	}
+}
+
+// Records the current state of the daemon identified by the key. It
+// returns true if the state has changed since the previous call.
+func (t *ImplausibleUptimeTracker) update(key string, implausible bool) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.daemons[key] == implausible {
+		return false
+	}
+	if implausible {
+		t.daemons[key] = true
+	} else {
+		delete(t.daemons, key)
+	}
+	return true
+}
+
+// Removes the daemons not included in the specified keys, e.g., the
+// daemons deleted from the apps or belonging to the deleted apps.
+func (t *ImplausibleUptimeTracker) retain(keys map[string]bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for key := range t.daemons {
+		if !keys[key] {
+			delete(t.daemons, key)
+		}
+	}
+}
+
 // Represents a response from the single Kea server to the status-get
 // command.
 type StatusGetResponse struct {
@@ -726,3 +726,110 @@ func TestPullHAStatus(t *testing.T) {
 func TestPullHAStatus178(t *testing.T) {
 	testPullHAStatus(t, true)
 }
+
+// Test that the plausible uptime and reload time pass the validation.
+func TestValidateUptime(t *testing.T) {
+	// Arrange
+	args := []StatusGetRespArgs{
+		{Uptime: 0, Reload: 0},
+		{Uptime: 100, Reload: 50},
+		{Uptime: 100, Reload: 100},
+	}
+
+	for _, a := range args {
+		// Act
+		err := a.validateUptime(nil)
+
+		// Assert
+		require.NoError(t, err)
+	}
+}
+
+// Test that the inconsistent uptime and reload time are detected.
+func TestValidateUptimeImplausible(t *testing.T) {
+	testCases := []struct {
+		name   string
+		args   StatusGetRespArgs
+		errMsg string
+	}{
+		{"negative uptime", StatusGetRespArgs{Uptime: -10, Reload: 0}, "negative uptime -10 s"},
+		{"negative reload", StatusGetRespArgs{Uptime: 100, Reload: -5}, "negative time since the last reload -5 s"},
+		{"reload exceeding uptime", StatusGetRespArgs{Uptime: 100, Reload: 200}, "time since the last reload 200 s exceeds the uptime 100 s"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			// Act
+			err := testCase.args.validateUptime(nil)
+
+			// Assert
+			require.EqualError(t, err, testCase.errMsg)
+		})
+	}
+}
+
+// Test that the daemon's uptime is compared with the Control Agent's uptime.
+func TestValidateUptimeAgainstCA(t *testing.T) {
+	testCases := []struct {
+		name   string
+		args   StatusGetRespArgs
+		caArgs StatusGetRespArgs
+		errMsg string
+	}{
+		{"started together", StatusGetRespArgs{Uptime: 1000, Reload: 10}, StatusGetRespArgs{Uptime: 990}, ""},
+		{"daemon restarted", StatusGetRespArgs{Uptime: 10, Reload: 10}, StatusGetRespArgs{Uptime: 100000}, ""},
+		{"within skew", StatusGetRespArgs{Uptime: 160}, StatusGetRespArgs{Uptime: 100}, ""},
+		{"negative CA uptime", StatusGetRespArgs{Uptime: 100}, StatusGetRespArgs{Uptime: -1}, "negative Control Agent uptime -1 s"},
+		{"exceeding CA uptime", StatusGetRespArgs{Uptime: 161}, StatusGetRespArgs{Uptime: 100}, "uptime 161 s exceeds the Control Agent uptime 100 s by more than 60 s"},
+		{"own values checked first", StatusGetRespArgs{Uptime: -10}, StatusGetRespArgs{Uptime: 100}, "negative uptime -10 s"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			// Act
+			err := testCase.args.validateUptime(&testCase.caArgs)
+
+			// Assert
+			if testCase.errMsg == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, testCase.errMsg)
+			}
+		})
+	}
+}
+
+// Test that the tracker reports the transitions between the plausible and
+// implausible uptime states only.
+func TestImplausibleUptimeTrackerUpdate(t *testing.T) {
+	// Arrange
+	tracker := NewImplausibleUptimeTracker()
+
+	// Act & Assert
+	require.False(t, tracker.update("foo", false))
+	require.True(t, tracker.update("foo", true))
+	require.False(t, tracker.update("foo", true))
+	require.False(t, tracker.update("bar", false))
+	require.True(t, tracker.update("bar", true))
+	require.True(t, tracker.update("foo", false))
+	require.False(t, tracker.update("foo", false))
+	require.Len(t, tracker.daemons, 1)
+	require.True(t, tracker.daemons["bar"])
+}
+
+// Test that the tracker retains only the specified daemons.
+func TestImplausibleUptimeTrackerRetain(t *testing.T) {
+	// Arrange
+	tracker := NewImplausibleUptimeTracker()
+	tracker.update("foo", true)
+	tracker.update("bar", true)
+
+	// Act
+	tracker.retain(map[string]bool{"bar": true, "baz": true})
+
+	// Assert
+	require.Len(t, tracker.daemons, 1)
+	require.True(t, tracker.daemons["bar"])
+}
@@ -0,0 +1,89 @@
+package kea
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	keaconfig "isc.org/stork/appcfg/kea"
+	dbops "isc.org/stork/server/database"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Converts the subnets and host reservations detected for the daemon to the
+// Kea format. The hosts are indexed by the Stork subnet ID. The convert
+// function creates a subnet in the Kea format (e.g., keaconfig.CreateSubnet4)
+// and the reservations are appended to it by the caller-provided function.
+func exportSubnets[T any](daemonID int64, lookup keaconfig.DHCPOptionDefinitionLookup, subnets []dbmodel.Subnet, hosts map[int64][]dbmodel.Host, convert func(int64, keaconfig.DHCPOptionDefinitionLookup, keaconfig.SubnetAccessor) (*T, error), reservations func(*T) *[]keaconfig.Reservation) ([]T, error) {
+	exported := []T{}
+	for i := range subnets {
+		keaSubnet, err := convert(daemonID, lookup, &subnets[i])
+		if err != nil {
+			return nil, errors.WithMessagef(err, "problem exporting subnet %s", subnets[i].Prefix)
+		}
+		for j := range hosts[subnets[i].ID] {
+			reservation, err := keaconfig.CreateReservation(daemonID, lookup, &hosts[subnets[i].ID][j])
+			if err != nil {
+				return nil, errors.WithMessagef(err, "problem exporting host reservation in subnet %s", subnets[i].Prefix)
+			}
+			*reservations(keaSubnet) = append(*reservations(keaSubnet), *reservation)
+		}
+		exported = append(exported, *keaSubnet)
+	}
+	return exported, nil
+}
+
+// Exports the subnets detected for a DHCP daemon as a Kea configuration
+// fragment. The fragment is a map with a single subnet4 or subnet6 entry,
+// depending on the daemon type, which can be marshalled to JSON and pasted
+// into the Dhcp4 or Dhcp6 configuration of another server. The subnets
+// include the pools, DHCP options, relay settings and other Kea-specific
+// parameters stored in the database. They also include the host
+// reservations specified in the daemon's configuration file. The host
+// reservations stored in the host database are not exported because the
+// migrated server is expected to use the same host database. The subnets
+// belonging to the shared networks are exported as top-level subnets.
+func ExportDaemonSubnets(dbi dbops.DBI, daemon *dbmodel.Daemon, lookup keaconfig.DHCPOptionDefinitionLookup) (map[string]any, error) {
+	if daemon.Name != dhcp4 && daemon.Name != dhcp6 {
+		return nil, errors.Errorf("unable to export subnets of the non-DHCP daemon %s", daemon.Name)
+	}
+
+	subnets, err := dbmodel.GetSubnetsByDaemonID(dbi, daemon.ID)
+	if err != nil {
+		return nil, err
+	}
+	// Order the subnets by their IDs in the daemon's configuration.
+	sort.Slice(subnets, func(i, j int) bool {
+		return subnets[i].GetID(daemon.ID) < subnets[j].GetID(daemon.ID)
+	})
+
+	hosts, _, err := dbmodel.GetHostsByDaemonID(dbi, daemon.ID, dbmodel.HostDataSourceConfig)
+	if err != nil {
+		return nil, err
+	}
+	hostsBySubnet := make(map[int64][]dbmodel.Host)
+	for _, host := range hosts {
+		if host.SubnetID != 0 {
+			hostsBySubnet[host.SubnetID] = append(hostsBySubnet[host.SubnetID], host)
+		}
+	}
+
+	if daemon.Name == dhcp4 {
+		subnet4, err := exportSubnets(daemon.ID, lookup, subnets, hostsBySubnet, keaconfig.CreateSubnet4,
+			func(subnet *keaconfig.Subnet4) *[]keaconfig.Reservation {
+				return &subnet.Reservations
+			})
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"subnet4": subnet4}, nil
+	}
+
+	subnet6, err := exportSubnets(daemon.ID, lookup, subnets, hostsBySubnet, keaconfig.CreateSubnet6,
+		func(subnet *keaconfig.Subnet6) *[]keaconfig.Reservation {
+			return &subnet.Reservations
+		})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"subnet6": subnet6}, nil
+}
@@ -0,0 +1,196 @@
+package kea
+
+import (
+	"encoding/json"
+	"testing"
+
+	require "github.com/stretchr/testify/require"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+	storktest "isc.org/stork/server/test/dbmodel"
+)
+
+// Test that the IPv4 subnets detected for a daemon are exported in the
+// Kea configuration format.
+func TestExportDaemonSubnets4(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	v4Config := `
+        {
+            "Dhcp4": {
+                "shared-networks": [
+                    {
+                        "name": "foo",
+                        "subnet4": [
+                            {
+                                "id": 2,
+                                "subnet": "192.0.3.0/24"
+                            }
+                        ]
+                    }
+                ],
+                "subnet4": [
+                    {
+                        "id": 1,
+                        "subnet": "192.0.2.0/24",
+                        "pools": [
+                            {
+                                "pool": "192.0.2.10 - 192.0.2.20"
+                            }
+                        ],
+                        "relay": {
+                            "ip-addresses": [ "192.0.2.1" ]
+                        },
+                        "option-data": [
+                            {
+                                "code": 3,
+                                "name": "routers",
+                                "space": "dhcp4",
+                                "data": "192.0.2.1"
+                            }
+                        ],
+                        "reservations": [
+                            {
+                                "hw-address": "01:02:03:04:05:06",
+                                "ip-address": "192.0.2.100",
+                                "hostname": "foo.example.org"
+                            }
+                        ]
+                    }
+                ]
+            }
+        }`
+	app := createAppWithSubnets(t, db, 0, v4Config, "")
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	err := CommitAppIntoDB(db, app, &storktest.FakeEventCenter{}, nil, lookup)
+	require.NoError(t, err)
+
+	fragment, err := ExportDaemonSubnets(db, app.Daemons[0], lookup)
+	require.NoError(t, err)
+
+	exported, err := json.Marshal(fragment)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{
+        "subnet4": [
+            {
+                "id": 1,
+                "subnet": "192.0.2.0/24",
+                "pools": [
+                    {
+                        "pool": "192.0.2.10-192.0.2.20"
+                    }
+                ],
+                "relay": {
+                    "ip-addresses": [ "192.0.2.1" ]
+                },
+                "option-data": [
+                    {
+                        "code": 3,
+                        "csv-format": true,
+                        "name": "routers",
+                        "space": "dhcp4",
+                        "data": "192.0.2.1"
+                    }
+                ],
+                "reservations": [
+                    {
+                        "hw-address": "010203040506",
+                        "ip-address": "192.0.2.100",
+                        "hostname": "foo.example.org"
+                    }
+                ]
+            },
+            {
+                "id": 2,
+                "subnet": "192.0.3.0/24"
+            }
+        ]
+    }`, string(exported))
+}
+
+// Test that the IPv6 subnets detected for a daemon are exported in the
+// Kea configuration format.
+func TestExportDaemonSubnets6(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	v6Config := `
+        {
+            "Dhcp6": {
+                "subnet6": [
+                    {
+                        "id": 1,
+                        "subnet": "2001:db8:1::/64",
+                        "pools": [
+                            {
+                                "pool": "2001:db8:1::10 - 2001:db8:1::20"
+                            }
+                        ],
+                        "pd-pools": [
+                            {
+                                "prefix": "3000::",
+                                "prefix-len": 64,
+                                "delegated-len": 96
+                            }
+                        ],
+                        "reservations": [
+                            {
+                                "duid": "01:02:03:04",
+                                "ip-addresses": [ "2001:db8:1::100" ],
+                                "prefixes": [ "3000:1::/96" ]
+                            }
+                        ]
+                    }
+                ]
+            }
+        }`
+	app := createAppWithSubnets(t, db, 0, "", v6Config)
+	lookup := dbmodel.NewDHCPOptionDefinitionLookup()
+	err := CommitAppIntoDB(db, app, &storktest.FakeEventCenter{}, nil, lookup)
+	require.NoError(t, err)
+
+	fragment, err := ExportDaemonSubnets(db, app.Daemons[1], lookup)
+	require.NoError(t, err)
+
+	exported, err := json.Marshal(fragment)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{
+        "subnet6": [
+            {
+                "id": 1,
+                "subnet": "2001:db8:1::/64",
+                "pools": [
+                    {
+                        "pool": "2001:db8:1::10-2001:db8:1::20"
+                    }
+                ],
+                "pd-pools": [
+                    {
+                        "prefix": "3000::",
+                        "prefix-len": 64,
+                        "delegated-len": 96
+                    }
+                ],
+                "reservations": [
+                    {
+                        "duid": "01020304",
+                        "ip-addresses": [ "2001:db8:1::100" ],
+                        "prefixes": [ "3000:1::/96" ]
+                    }
+                ]
+            }
+        ]
+    }`, string(exported))
+}
+
+// Test that exporting the subnets of a non-DHCP daemon fails.
+func TestExportDaemonSubnetsNonDHCPDaemon(t *testing.T) {
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameCA, true)
+
+	fragment, err := ExportDaemonSubnets(nil, daemon, dbmodel.NewDHCPOptionDefinitionLookup())
+	require.Error(t, err)
+	require.Nil(t, fragment)
+}
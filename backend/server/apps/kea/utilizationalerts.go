@@ -0,0 +1,97 @@
+package kea
+
+import (
+	"fmt"
+
+	"github.com/go-pg/pg/v10"
+	log "github.com/sirupsen/logrus"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// The subnet utilization thresholds in percent. Crossing the high watermark
+// upward and then dropping below the low watermark is reported. The gap
+// between them prevents reporting the utilization oscillating around a
+// single threshold. The zero high watermark disables the reports.
+type utilizationWatermarks struct {
+	high int64
+	low  int64
+}
+
+// Indicates which kinds of the subnet utilization crossed the high
+// watermark and haven't dropped below the low watermark yet. It is
+// remembered between the pulls to report only the state transitions.
+type utilizationAlertState struct {
+	addressHigh         bool
+	delegatedPrefixHigh bool
+}
+
+// Reads the utilization watermarks from the settings. The low watermark
+// greater than the high one is lowered to the high watermark.
+func getUtilizationWatermarks(db *pg.DB) (watermarks utilizationWatermarks, err error) {
+	if watermarks.high, err = dbmodel.GetSettingInt(db, "subnet_utilization_high_watermark"); err != nil {
+		return
+	}
+	if watermarks.low, err = dbmodel.GetSettingInt(db, "subnet_utilization_low_watermark"); err != nil {
+		return
+	}
+	if watermarks.low > watermarks.high {
+		log.Warnf("Subnet utilization low watermark %d%% is greater than the high watermark %d%%; using the high watermark",
+			watermarks.low, watermarks.high)
+		watermarks.low = watermarks.high
+	}
+	return
+}
+
+// Returns the new utilization state given the previous state and the
+// current utilization as a fraction. The state changes when the
+// utilization reaches the high watermark or drops below the low watermark.
+func (watermarks utilizationWatermarks) apply(high bool, utilization float64) bool {
+	percent := utilization * 100
+	switch {
+	case !high && percent >= float64(watermarks.high):
+		return true
+	case high && percent < float64(watermarks.low):
+		return false
+	default:
+		return high
+	}
+}
+
+// Compares the subnet utilization with the watermarks and raises the events
+// when the address or delegated prefix utilization crosses the high
+// watermark or recovers below the low watermark. Returns the new state of
+// the subnet.
+func (statsPuller *StatsPuller) checkUtilizationWatermarks(subnet *dbmodel.Subnet, stats subnetStats, watermarks utilizationWatermarks, previous utilizationAlertState) utilizationAlertState {
+	current := utilizationAlertState{
+		addressHigh:         watermarks.apply(previous.addressHigh, stats.GetAddressUtilization()),
+		delegatedPrefixHigh: watermarks.apply(previous.delegatedPrefixHigh, stats.GetDelegatedPrefixUtilization()),
+	}
+	if statsPuller.EventCenter == nil {
+		return current
+	}
+	for _, kind := range []struct {
+		name        string
+		previous    bool
+		current     bool
+		utilization float64
+	}{
+		{"address", previous.addressHigh, current.addressHigh, stats.GetAddressUtilization()},
+		{"delegated prefix", previous.delegatedPrefixHigh, current.delegatedPrefixHigh, stats.GetDelegatedPrefixUtilization()},
+	} {
+		switch {
+		case kind.current && !kind.previous:
+			statsPuller.EventCenter.AddWarningEvent(
+				fmt.Sprintf("{subnet} %s utilization %.1f%% crossed the high watermark of %d%%",
+					kind.name, kind.utilization*100, watermarks.high),
+				subnet,
+			)
+		case !kind.current && kind.previous:
+			statsPuller.EventCenter.AddInfoEvent(
+				fmt.Sprintf("{subnet} %s utilization %.1f%% dropped below the low watermark of %d%%",
+					kind.name, kind.utilization*100, watermarks.low),
+				subnet,
+			)
+		}
+	}
+	return current
+}
@@ -0,0 +1,134 @@
+package kea
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+	storktest "isc.org/stork/server/test/dbmodel"
+)
+
+// Subnet statistics with the fixed utilization used in the tests.
+type testUtilizationStats struct {
+	addressUtilization         float64
+	delegatedPrefixUtilization float64
+}
+
+func (s *testUtilizationStats) GetAddressUtilization() float64 {
+	return s.addressUtilization
+}
+
+func (s *testUtilizationStats) GetDelegatedPrefixUtilization() float64 {
+	return s.delegatedPrefixUtilization
+}
+
+func (s *testUtilizationStats) GetStatistics() dbmodel.SubnetStats {
+	return dbmodel.SubnetStats{}
+}
+
+func (s *testUtilizationStats) IsExhausted() bool {
+	return false
+}
+
+// Test that the utilization state changes only when the utilization
+// reaches the high watermark or drops below the low watermark.
+func TestUtilizationWatermarksApply(t *testing.T) {
+	watermarks := utilizationWatermarks{high: 90, low: 80}
+
+	require.False(t, watermarks.apply(false, 0.5))
+	require.False(t, watermarks.apply(false, 0.899))
+	require.True(t, watermarks.apply(false, 0.9))
+	require.True(t, watermarks.apply(false, 1.0))
+	require.True(t, watermarks.apply(true, 0.85))
+	require.True(t, watermarks.apply(true, 0.8))
+	require.False(t, watermarks.apply(true, 0.799))
+}
+
+// Test that the events are raised only when the subnet utilization
+// crosses the watermarks.
+func TestStatsPullerCheckUtilizationWatermarks(t *testing.T) {
+	// Arrange
+	eventCenter := &storktest.FakeEventCenter{}
+	sp := &StatsPuller{EventCenter: eventCenter}
+	subnet := &dbmodel.Subnet{ID: 7, Prefix: "192.0.2.0/24"}
+	watermarks := utilizationWatermarks{high: 90, low: 80}
+	stats := &testUtilizationStats{}
+	state := utilizationAlertState{}
+
+	// Act & Assert
+	// The utilization is low.
+	stats.addressUtilization = 0.5
+	state = sp.checkUtilizationWatermarks(subnet, stats, watermarks, state)
+	require.False(t, state.addressHigh)
+	require.Empty(t, eventCenter.Events)
+
+	// The address utilization crosses the high watermark.
+	stats.addressUtilization = 0.92
+	state = sp.checkUtilizationWatermarks(subnet, stats, watermarks, state)
+	require.True(t, state.addressHigh)
+	require.Len(t, eventCenter.Events, 1)
+	require.EqualValues(t, dbmodel.EvWarning, eventCenter.Events[0].Level)
+	require.Contains(t, eventCenter.Events[0].Text, "address utilization 92.0% crossed the high watermark of 90%")
+	require.EqualValues(t, 7, eventCenter.Events[0].Relations.SubnetID)
+
+	// The utilization is still high. No event is raised.
+	stats.addressUtilization = 0.95
+	state = sp.checkUtilizationWatermarks(subnet, stats, watermarks, state)
+	require.True(t, state.addressHigh)
+	require.Len(t, eventCenter.Events, 1)
+
+	// The utilization decreases below the high watermark but not below
+	// the low watermark.
+	stats.addressUtilization = 0.85
+	state = sp.checkUtilizationWatermarks(subnet, stats, watermarks, state)
+	require.True(t, state.addressHigh)
+	require.Len(t, eventCenter.Events, 1)
+
+	// The delegated prefix utilization crosses the high watermark.
+	stats.delegatedPrefixUtilization = 0.9
+	state = sp.checkUtilizationWatermarks(subnet, stats, watermarks, state)
+	require.True(t, state.delegatedPrefixHigh)
+	require.Len(t, eventCenter.Events, 2)
+	require.EqualValues(t, dbmodel.EvWarning, eventCenter.Events[1].Level)
+	require.Contains(t, eventCenter.Events[1].Text, "delegated prefix utilization 90.0% crossed the high watermark of 90%")
+
+	// The address utilization recovers.
+	stats.addressUtilization = 0.7
+	state = sp.checkUtilizationWatermarks(subnet, stats, watermarks, state)
+	require.False(t, state.addressHigh)
+	require.True(t, state.delegatedPrefixHigh)
+	require.Len(t, eventCenter.Events, 3)
+	require.EqualValues(t, dbmodel.EvInfo, eventCenter.Events[2].Level)
+	require.Contains(t, eventCenter.Events[2].Text, "address utilization 70.0% dropped below the low watermark of 80%")
+}
+
+// Test that the watermarks are read from the settings and the low watermark
+// doesn't exceed the high watermark.
+func TestGetUtilizationWatermarks(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	t.Run("no settings", func(t *testing.T) {
+		_, err := getUtilizationWatermarks(db)
+		require.Error(t, err)
+	})
+
+	_ = dbmodel.InitializeSettings(db, 0)
+
+	t.Run("default", func(t *testing.T) {
+		watermarks, err := getUtilizationWatermarks(db)
+		require.NoError(t, err)
+		require.EqualValues(t, 90, watermarks.high)
+		require.EqualValues(t, 80, watermarks.low)
+	})
+
+	t.Run("low greater than high", func(t *testing.T) {
+		_ = dbmodel.SetSettingInt(db, "subnet_utilization_low_watermark", 95)
+		watermarks, err := getUtilizationWatermarks(db)
+		require.NoError(t, err)
+		require.EqualValues(t, 90, watermarks.high)
+		require.EqualValues(t, 90, watermarks.low)
+	})
+}
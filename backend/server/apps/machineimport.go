@@ -0,0 +1,219 @@
+package apps
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/go-pg/pg/v10"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Format of the file with the machines to import.
+type MachineImportFormat string
+
+const (
+	// A JSON list of objects with the address and agentPort keys, e.g.:
+	//
+	//	[ { "address": "192.0.2.1", "agentPort": 8080 } ]
+	MachineImportFormatJSON MachineImportFormat = "json"
+	// The CSV records with the address and the agent port, e.g.:
+	//
+	//	192.0.2.1,8080
+	//
+	// The optional header starting with the address column is skipped.
+	// The lines starting with the # character are comments.
+	MachineImportFormatCSV MachineImportFormat = "csv"
+)
+
+// Status of importing a single machine.
+type MachineImportStatus string
+
+const (
+	// The machine has been added to the database.
+	MachineImportStatusAdded MachineImportStatus = "added"
+	// The machine with the same address and agent port already exists.
+	MachineImportStatusSkipped MachineImportStatus = "skipped"
+	// The entry is malformed or the machine couldn't be added.
+	MachineImportStatusError MachineImportStatus = "error"
+)
+
+// The result of importing a single entry from the file. The Entry is the
+// 1-based position of the entry in the file (the record number in the
+// CSV file). The MachineID is set for the added and skipped machines.
+type MachineImportResult struct {
+	Entry     int
+	Address   string
+	AgentPort int64
+	Status    MachineImportStatus
+	MachineID int64
+	Error     string
+}
+
+// A single machine entry parsed from the file. The error is set when the
+// entry is malformed.
+type machineImportEntry struct {
+	Address   string `json:"address"`
+	AgentPort int64  `json:"agentPort"`
+	err       error
+}
+
+// Parses the JSON list of the machines. Each entry is parsed separately,
+// so a malformed entry doesn't prevent parsing the other ones.
+func parseMachineImportJSON(reader io.Reader) ([]machineImportEntry, error) {
+	var rawEntries []json.RawMessage
+	if err := json.NewDecoder(reader).Decode(&rawEntries); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the list of machines")
+	}
+	entries := make([]machineImportEntry, len(rawEntries))
+	for i, rawEntry := range rawEntries {
+		if err := json.Unmarshal(rawEntry, &entries[i]); err != nil {
+			entries[i].err = errors.Wrap(err, "malformed entry")
+		}
+	}
+	return entries, nil
+}
+
+// Parses the CSV records with the machines. A record with an unexpected
+// number of fields or an invalid port is returned with an error.
+func parseMachineImportCSV(reader io.Reader) ([]machineImportEntry, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.Comment = '#'
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the list of machines")
+	}
+	// Skip the header.
+	if len(records) > 0 && strings.EqualFold(strings.TrimSpace(records[0][0]), "address") {
+		records = records[1:]
+	}
+	var entries []machineImportEntry
+	for _, record := range records {
+		entry := machineImportEntry{
+			Address: strings.TrimSpace(record[0]),
+		}
+		if len(record) != 2 {
+			entry.err = errors.Errorf("malformed entry: expected 2 fields but got %d", len(record))
+		} else if entry.AgentPort, err = strconv.ParseInt(strings.TrimSpace(record[1]), 10, 64); err != nil {
+			entry.err = errors.Errorf("malformed entry: invalid agent port %s", record[1])
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Validates the machine entry in the same way as the machine data sent
+// over the REST API.
+func (entry *machineImportEntry) validate() error {
+	if entry.err != nil {
+		return entry.err
+	}
+	if !govalidator.IsHost(entry.Address) {
+		return errors.Errorf("invalid address %s", entry.Address)
+	}
+	if entry.AgentPort <= 0 || entry.AgentPort > 65535 {
+		return errors.Errorf("invalid agent port %d", entry.AgentPort)
+	}
+	return nil
+}
+
+// Imports the machines from the file in the specified format. The machines
+// are added to the database unless the machines with the same address and
+// agent port already exist. The duplicated entries in the file are added
+// only once. The added machines are not authorized. It returns the result
+// for each entry in the file. The malformed entries are reported in the
+// results and don't interrupt the import. An error is returned if the file
+// cannot be parsed at all.
+func ImportMachines(db *pg.DB, reader io.Reader, format MachineImportFormat) ([]MachineImportResult, error) {
+	var (
+		entries []machineImportEntry
+		err     error
+	)
+	switch format {
+	case MachineImportFormatJSON:
+		entries, err = parseMachineImportJSON(reader)
+	case MachineImportFormatCSV:
+		entries, err = parseMachineImportCSV(reader)
+	default:
+		return nil, errors.Errorf("unsupported machines file format %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	results := []MachineImportResult{}
+	for i := range entries {
+		entry := &entries[i]
+		result := MachineImportResult{
+			Entry:     i + 1,
+			Address:   entry.Address,
+			AgentPort: entry.AgentPort,
+		}
+		results = append(results, result)
+		current := &results[len(results)-1]
+
+		if err := entry.validate(); err != nil {
+			current.Status = MachineImportStatusError
+			current.Error = err.Error()
+			continue
+		}
+
+		existing, err := dbmodel.GetMachineByAddressAndAgentPort(db, entry.Address, entry.AgentPort)
+		if err != nil {
+			current.Status = MachineImportStatusError
+			current.Error = err.Error()
+			continue
+		}
+		if existing != nil {
+			current.Status = MachineImportStatusSkipped
+			current.MachineID = existing.ID
+			continue
+		}
+
+		machine := &dbmodel.Machine{
+			Address:   entry.Address,
+			AgentPort: entry.AgentPort,
+		}
+		if err := dbmodel.AddMachine(db, machine); err != nil {
+			current.Status = MachineImportStatusError
+			current.Error = err.Error()
+			continue
+		}
+		current.Status = MachineImportStatusAdded
+		current.MachineID = machine.ID
+		log.WithFields(log.Fields{
+			"address": machine.Address,
+			"port":    machine.AgentPort,
+		}).Info("Imported machine")
+	}
+	return results, nil
+}
+
+// Imports the machines from the specified file. The file format is
+// determined from the file extension (.json or .csv).
+func ImportMachinesFromFile(db *pg.DB, path string) ([]MachineImportResult, error) {
+	var format MachineImportFormat
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		format = MachineImportFormatJSON
+	case ".csv":
+		format = MachineImportFormatCSV
+	default:
+		return nil, errors.Errorf("unable to determine the format of the machines file %s", path)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open the machines file %s", path)
+	}
+	defer file.Close()
+	return ImportMachines(db, file, format)
+}
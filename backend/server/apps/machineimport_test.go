@@ -0,0 +1,193 @@
+package apps
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+)
+
+// Test that the machines are parsed from the JSON file and the malformed
+// entries are returned with errors.
+func TestParseMachineImportJSON(t *testing.T) {
+	// Arrange
+	reader := strings.NewReader(`[
+        { "address": "192.0.2.1", "agentPort": 8080 },
+        { "address": "agent.example.org", "agentPort": "8080" },
+        { "address": "192.0.2.2", "agentPort": 8081 }
+    ]`)
+
+	// Act
+	entries, err := parseMachineImportJSON(reader)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, "192.0.2.1", entries[0].Address)
+	require.EqualValues(t, 8080, entries[0].AgentPort)
+	require.NoError(t, entries[0].err)
+	require.Error(t, entries[1].err)
+	require.Equal(t, "192.0.2.2", entries[2].Address)
+	require.EqualValues(t, 8081, entries[2].AgentPort)
+	require.NoError(t, entries[2].err)
+}
+
+// Test that an error is returned when the JSON file is not a list.
+func TestParseMachineImportJSONInvalid(t *testing.T) {
+	// Arrange
+	reader := strings.NewReader(`{ "address": "192.0.2.1", "agentPort": 8080 }`)
+
+	// Act
+	entries, err := parseMachineImportJSON(reader)
+
+	// Assert
+	require.Error(t, err)
+	require.Nil(t, entries)
+}
+
+// Test that the machines are parsed from the CSV file with the header and
+// comments, and the malformed records are returned with errors.
+func TestParseMachineImportCSV(t *testing.T) {
+	// Arrange
+	reader := strings.NewReader("address,agent_port\n" +
+		"# The DHCP servers.\n" +
+		"192.0.2.1, 8080\n" +
+		"192.0.2.2\n" +
+		"192.0.2.3,port\n" +
+		"agent.example.org,8081\n")
+
+	// Act
+	entries, err := parseMachineImportCSV(reader)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	require.Equal(t, "192.0.2.1", entries[0].Address)
+	require.EqualValues(t, 8080, entries[0].AgentPort)
+	require.NoError(t, entries[0].err)
+	require.Equal(t, "192.0.2.2", entries[1].Address)
+	require.ErrorContains(t, entries[1].err, "expected 2 fields but got 1")
+	require.Equal(t, "192.0.2.3", entries[2].Address)
+	require.ErrorContains(t, entries[2].err, "invalid agent port")
+	require.Equal(t, "agent.example.org", entries[3].Address)
+	require.EqualValues(t, 8081, entries[3].AgentPort)
+	require.NoError(t, entries[3].err)
+}
+
+// Test that the machine entries are validated.
+func TestMachineImportEntryValidate(t *testing.T) {
+	require.NoError(t, (&machineImportEntry{Address: "192.0.2.1", AgentPort: 8080}).validate())
+	require.NoError(t, (&machineImportEntry{Address: "agent.example.org", AgentPort: 65535}).validate())
+	require.Error(t, (&machineImportEntry{Address: "", AgentPort: 8080}).validate())
+	require.Error(t, (&machineImportEntry{Address: "in valid", AgentPort: 8080}).validate())
+	require.Error(t, (&machineImportEntry{Address: "192.0.2.1", AgentPort: 0}).validate())
+	require.Error(t, (&machineImportEntry{Address: "192.0.2.1", AgentPort: 65536}).validate())
+}
+
+// Test that an error is returned for an unsupported file format.
+func TestImportMachinesUnsupportedFormat(t *testing.T) {
+	// Act
+	results, err := ImportMachines(nil, strings.NewReader(""), "xml")
+
+	// Assert
+	require.Error(t, err)
+	require.Nil(t, results)
+}
+
+// Test that the new machines are added, the duplicates are skipped and the
+// malformed entries are reported.
+func TestImportMachines(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	existing := &dbmodel.Machine{
+		Address:   "192.0.2.2",
+		AgentPort: 8080,
+	}
+	err := dbmodel.AddMachine(db, existing)
+	require.NoError(t, err)
+
+	reader := strings.NewReader(`[
+        { "address": "192.0.2.1", "agentPort": 8080 },
+        { "address": "192.0.2.2", "agentPort": 8080 },
+        { "address": "192.0.2.1", "agentPort": 8080 },
+        { "address": "192.0.2.1", "agentPort": 8081 },
+        { "address": "192.0.2.3", "agentPort": 0 },
+        { "address": "192.0.2.4", "agentPort": "8080" }
+    ]`)
+
+	// Act
+	results, err := ImportMachines(db, reader, MachineImportFormatJSON)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, results, 6)
+
+	require.Equal(t, MachineImportStatusAdded, results[0].Status)
+	require.NotZero(t, results[0].MachineID)
+	require.Equal(t, MachineImportStatusSkipped, results[1].Status)
+	require.Equal(t, existing.ID, results[1].MachineID)
+	// The duplicated entry in the file.
+	require.Equal(t, MachineImportStatusSkipped, results[2].Status)
+	require.Equal(t, results[0].MachineID, results[2].MachineID)
+	require.Equal(t, MachineImportStatusAdded, results[3].Status)
+	require.NotEqual(t, results[0].MachineID, results[3].MachineID)
+	require.Equal(t, MachineImportStatusError, results[4].Status)
+	require.Contains(t, results[4].Error, "invalid agent port")
+	require.Zero(t, results[4].MachineID)
+	require.Equal(t, MachineImportStatusError, results[5].Status)
+	require.Contains(t, results[5].Error, "malformed entry")
+
+	for i, result := range results {
+		require.Equal(t, i+1, result.Entry)
+	}
+
+	machines, err := dbmodel.GetAllMachines(db, nil)
+	require.NoError(t, err)
+	require.Len(t, machines, 3)
+}
+
+// Test that the machines are imported from the CSV file.
+func TestImportMachinesFromFile(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	path := filepath.Join(t.TempDir(), "machines.csv")
+	err := os.WriteFile(path, []byte("address,port\n192.0.2.1,8080\n192.0.2.1,8080\nfoo\n"), 0o600)
+	require.NoError(t, err)
+
+	// Act
+	results, err := ImportMachinesFromFile(db, path)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	require.Equal(t, MachineImportStatusAdded, results[0].Status)
+	require.Equal(t, MachineImportStatusSkipped, results[1].Status)
+	require.Equal(t, MachineImportStatusError, results[2].Status)
+
+	machine, err := dbmodel.GetMachineByAddressAndAgentPort(db, "192.0.2.1", 8080)
+	require.NoError(t, err)
+	require.NotNil(t, machine)
+	require.False(t, machine.Authorized)
+}
+
+// Test that an error is returned when the file format cannot be determined
+// or the file doesn't exist.
+func TestImportMachinesFromFileInvalid(t *testing.T) {
+	// Act
+	results1, err1 := ImportMachinesFromFile(nil, "machines.txt")
+	results2, err2 := ImportMachinesFromFile(nil, filepath.Join(t.TempDir(), "machines.json"))
+
+	// Assert
+	require.Error(t, err1)
+	require.Nil(t, results1)
+	require.Error(t, err2)
+	require.Nil(t, results2)
+}
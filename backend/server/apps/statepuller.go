@@ -23,6 +23,15 @@ type StatePuller struct {
 	EventCenter                eventcenter.EventCenter
 	ReviewDispatcher           configreview.Dispatcher
 	DHCPOptionDefinitionLookup keaconfig.DHCPOptionDefinitionLookup
+	// Optional prober checking if the Kea lease databases are reachable.
+	// The lease databases are not probed when it is nil.
+	LeaseDatabaseProber *kea.LeaseDatabaseProber
+	// Severities of the events raised for the Kea daemon state changes.
+	// The default severities are used when it is nil.
+	DaemonEventSeverities kea.DaemonEventSeverities
+	// The timeouts and retries of the communication with the Kea apps.
+	// The defaults are used when it is nil.
+	AppStatePullSettings *kea.AppStatePullSettings
 }
 
 // Create an instance of the puller which periodically checks the status of
@@ -59,21 +68,42 @@ func (puller *StatePuller) pullData() error {
 	// get state from machines and their apps
 	var lastErr error
 	okCnt := 0
+	var apps []*dbmodel.App
 	for _, dbM := range dbMachines {
 		dbM2 := dbM
 		ctx := context.Background()
-		errStr := GetMachineAndAppsState(ctx, puller.DB, &dbM2, puller.Agents, puller.EventCenter, puller.ReviewDispatcher, puller.DHCPOptionDefinitionLookup)
+		errStr := GetMachineAndAppsState(ctx, puller.DB, &dbM2, puller.Agents, puller.EventCenter, puller.ReviewDispatcher, puller.DHCPOptionDefinitionLookup, puller.DaemonEventSeverities, puller.AppStatePullSettings)
 		if errStr != "" {
 			lastErr = errors.New(errStr)
 			log.Errorf("Error occurred while getting info from machine %d: %s", dbM2.ID, errStr)
 		} else {
 			okCnt++
 		}
+		apps = append(apps, dbM2.Apps...)
 	}
 	log.Printf("Completed pulling information from machines: %d/%d succeeded", okCnt, len(dbMachines))
+
+	// Forget the implausible uptimes of the daemons that no longer exist.
+	puller.AppStatePullSettings.PruneImplausibleUptimes(apps)
+
+	// Probe the lease databases once for all machines rather than
+	// in the loop above, so the probes don't delay the pulls.
+	puller.probeLeaseDatabases(apps)
 	return lastErr
 }
 
+// Checks if the lease databases of the Kea apps are reachable and passes
+// the resulting events to the event center. It does nothing if the lease
+// database probe is disabled.
+func (puller *StatePuller) probeLeaseDatabases(apps []*dbmodel.App) {
+	if puller.LeaseDatabaseProber == nil {
+		return
+	}
+	for _, event := range puller.LeaseDatabaseProber.Probe(apps) {
+		puller.EventCenter.AddEvent(event)
+	}
+}
+
 // Store updated machine fields in to database.
 func updateMachineFields(db *dbops.PgDB, dbMachine *dbmodel.Machine, m *agentcomm.State) error {
 	// update state fields in machine
@@ -204,15 +234,21 @@ func mergeNewAndOldApps(db *dbops.PgDB, dbMachine *dbmodel.Machine, discoveredAp
 		}
 		allApps = append(allApps, dbApp)
 
-		// add or update access points
+		// add or update access points; the reachability of the existing
+		// access points is preserved until the app state is fetched
 		var accessPoints []*dbmodel.AccessPoint
 		for _, point := range app.AccessPoints {
+			reachable := true
+			if oldPoint, err := dbApp.GetAccessPoint(point.Type); err == nil {
+				reachable = oldPoint.Reachable
+			}
 			accessPoints = append(accessPoints, &dbmodel.AccessPoint{
 				Type:              point.Type,
 				Address:           point.Address,
 				Port:              point.Port,
 				Key:               point.Key,
 				UseSecureProtocol: point.UseSecureProtocol,
+				Reachable:         reachable,
 			})
 		}
 		dbApp.AccessPoints = accessPoints
@@ -237,7 +273,12 @@ func mergeNewAndOldApps(db *dbops.PgDB, dbMachine *dbmodel.Machine, discoveredAp
 }
 
 // Retrieve remotely machine and its apps state, and store it in the database.
-func GetMachineAndAppsState(ctx context.Context, db *dbops.PgDB, dbMachine *dbmodel.Machine, agents agentcomm.ConnectedAgents, eventCenter eventcenter.EventCenter, reviewDispatcher configreview.Dispatcher, lookup keaconfig.DHCPOptionDefinitionLookup) string {
+// The severities specify the levels of the events raised for the Kea daemon
+// state changes. If they are nil, the default levels are used. The pull
+// settings specify the timeouts and retries of the communication with the
+// Kea apps. If they are nil, the defaults are used. The timeout configured
+// for the machine takes precedence over the timeout in the pull settings.
+func GetMachineAndAppsState(ctx context.Context, db *dbops.PgDB, dbMachine *dbmodel.Machine, agents agentcomm.ConnectedAgents, eventCenter eventcenter.EventCenter, reviewDispatcher configreview.Dispatcher, lookup keaconfig.DHCPOptionDefinitionLookup, severities kea.DaemonEventSeverities, pullSettings *kea.AppStatePullSettings) string {
 	ctx2, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
@@ -273,17 +314,26 @@ func GetMachineAndAppsState(ctx context.Context, db *dbops.PgDB, dbMachine *dbmo
 		return errStr
 	}
 
+	if dbMachine.AppStateTimeout > 0 {
+		pullSettings = pullSettings.WithTimeout(time.Duration(dbMachine.AppStateTimeout) * time.Second)
+	}
+
 	// go through all apps and store their changes in database
 	for _, dbApp := range allApps {
 		// get app state from the machine
 		switch dbApp.Type {
 		case dbmodel.AppTypeKea:
-			state := kea.GetAppState(ctx2, agents, dbApp, eventCenter)
+			// The Kea app state pull has its own timeouts accommodating the
+			// retries, so it is not limited by the machine state timeout.
+			state := kea.GetAppState(ctx, agents, dbApp, eventCenter, severities, pullSettings)
 			err = kea.CommitAppIntoDB(db, dbApp, eventCenter, state, lookup)
 			if err == nil {
 				// Let's now identify new daemons or the daemons with updated
 				// configurations and schedule configuration reviews for them
 				conditionallyBeginKeaConfigReviews(dbApp, state, reviewDispatcher, isStorkAgentChanged)
+				// A missing hook library causes Kea startup failure, so check
+				// the hook libraries referenced in the modified configurations.
+				kea.ReportMissingHookLibraries(agents, dbApp, state, eventCenter)
 			}
 		case dbmodel.AppTypeBind9:
 			bind9.GetAppState(ctx2, agents, dbApp, eventCenter)
@@ -893,6 +893,29 @@ func (d *dispatcherImpl) BeginReview(daemon *dbmodel.Daemon, triggers Triggers,
 	return d.beginReview(daemon, triggers, callback)
 }
 
+// Re-runs the config review for all Kea daemons having their configurations
+// stored in the database. It uses the stored configurations rather than
+// fetching them from the monitored servers. The reviews are performed by
+// the checkers registered in the dispatcher and the new reports replace
+// the existing ones. The callback function, if not nil, is invoked for each
+// completed review. It returns the number of scheduled reviews. A review is
+// not scheduled for a daemon when another review for this daemon is already
+// in progress or when all checkers are disabled for it.
+func ReviewAllDaemons(db *dbops.PgDB, dispatcher Dispatcher, callback CallbackFunc) (int, error) {
+	daemons, err := dbmodel.GetKeaDaemonsWithConfigs(db)
+	if err != nil {
+		return 0, err
+	}
+	scheduled := 0
+	for _, daemon := range daemons {
+		if dispatcher.BeginReview(daemon, Triggers{ManualRun}, callback) {
+			scheduled++
+		}
+	}
+	log.WithField("count", scheduled).Info("Scheduled the configuration reviews for all Kea daemons")
+	return scheduled, nil
+}
+
 // Checks if the review for the specified daemon is in progress.
 func (d *dispatcherImpl) ReviewInProgress(daemonID int64) bool {
 	d.mutex.RLock()
@@ -913,10 +936,28 @@ func RegisterDefaultCheckers(dispatcher Dispatcher) {
 	dispatcher.RegisterChecker(KeaDHCPDaemon, "canonical_prefix", GetDefaultTriggers(), canonicalPrefixes)
 	dispatcher.RegisterChecker(KeaDHCPDaemon, "ha_mt_presence", GetDefaultTriggers(), highAvailabilityMultiThreadingMode)
 	dispatcher.RegisterChecker(KeaDHCPDaemon, "ha_dedicated_ports", GetDefaultTriggers(), highAvailabilityDedicatedPorts)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "ha_insecure_access_point", GetDefaultTriggers(), highAvailabilityInsecureAccessPoint)
 	dispatcher.RegisterChecker(KeaDHCPDaemon, "address_pools_exhausted_by_reservations", ExtendDefaultTriggers(DBHostsModified), addressPoolsExhaustedByReservations)
 	dispatcher.RegisterChecker(KeaDHCPDaemon, "pd_pools_exhausted_by_reservations", ExtendDefaultTriggers(DBHostsModified), delegatedPrefixPoolsExhaustedByReservations)
 	dispatcher.RegisterChecker(KeaDHCPDaemon, "subnet_cmds_and_cb_mutual_exclusion", GetDefaultTriggers(), subnetCmdsAndConfigBackendMutualExclusion)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "lease_timers_conflict", GetDefaultTriggers(), leaseTimersConflict)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "unused_client_class", GetDefaultTriggers(), clientClassesUnused)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "reservation_out_of_subnet", GetDefaultTriggers(), reservationsOutOfSubnet)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "subnet_without_id", GetDefaultTriggers(), subnetsWithoutID)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "ha_unknown_peers", GetDefaultTriggers(), highAvailabilityUnknownPeers)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "deprecated_parameter", GetDefaultTriggers(), deprecatedParameters)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "in_pool_reservation_ignored", ExtendDefaultTriggers(DBHostsModified), reservationsInPoolIgnored)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "global_reservation_ignored", ExtendDefaultTriggers(DBHostsModified), reservationsGlobalIgnored)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "fragmented_pools", GetDefaultTriggers(), fragmentedPools)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "ha_client_id_mismatch", GetDefaultTriggers(), highAvailabilityClientIdentificationMismatch)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "ha_rapid_commit_mismatch", GetDefaultTriggers(), highAvailabilityRapidCommitMismatch)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "pool_family_mismatch", GetDefaultTriggers(), poolsFamilyMismatch)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "overlapping_pool", GetDefaultTriggers(), overlappingPools)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "valid_lifetime_out_of_range", GetDefaultTriggers(), validLifetimeOutOfRange)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "valid_lifetime_unset", GetDefaultTriggers(), validLifetimeUnset)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "duplicate_option", GetDefaultTriggers(), duplicateOptions)
 	dispatcher.RegisterChecker(KeaCADaemon, "agent_credentials_over_https", ExtendDefaultTriggers(StorkAgentConfigModified), credentialsOverHTTPS)
+	dispatcher.RegisterChecker(KeaCADaemon, "duplicate_access_point", ExtendDefaultTriggers(StorkAgentConfigModified), duplicateAccessPoints)
 }
 
 // Fetches all checker preferences from the database and loads them into
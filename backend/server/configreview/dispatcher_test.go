@@ -2,6 +2,7 @@ package configreview
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -680,11 +681,29 @@ func TestRegisterDefaultCheckers(t *testing.T) {
 	require.Contains(t, checkerNames, "out_of_pool_reservation")
 	require.Contains(t, checkerNames, "ha_mt_presence")
 	require.Contains(t, checkerNames, "ha_dedicated_ports")
+	require.Contains(t, checkerNames, "ha_insecure_access_point")
 	require.Contains(t, checkerNames, "address_pools_exhausted_by_reservations")
 	require.Contains(t, checkerNames, "pd_pools_exhausted_by_reservations")
 	require.Contains(t, checkerNames, "overlapping_subnet")
 	require.Contains(t, checkerNames, "canonical_prefix")
 	require.Contains(t, checkerNames, "subnet_cmds_and_cb_mutual_exclusion")
+	require.Contains(t, checkerNames, "lease_timers_conflict")
+	require.Contains(t, checkerNames, "unused_client_class")
+	require.Contains(t, checkerNames, "reservation_out_of_subnet")
+	require.Contains(t, checkerNames, "subnet_without_id")
+	require.Contains(t, checkerNames, "ha_unknown_peers")
+	require.Contains(t, checkerNames, "deprecated_parameter")
+	require.Contains(t, checkerNames, "duplicate_access_point")
+	require.Contains(t, checkerNames, "in_pool_reservation_ignored")
+	require.Contains(t, checkerNames, "global_reservation_ignored")
+	require.Contains(t, checkerNames, "fragmented_pools")
+	require.Contains(t, checkerNames, "ha_client_id_mismatch")
+	require.Contains(t, checkerNames, "ha_rapid_commit_mismatch")
+	require.Contains(t, checkerNames, "pool_family_mismatch")
+	require.Contains(t, checkerNames, "overlapping_pool")
+	require.Contains(t, checkerNames, "valid_lifetime_out_of_range")
+	require.Contains(t, checkerNames, "valid_lifetime_unset")
+	require.Contains(t, checkerNames, "duplicate_option")
 
 	// Ensure that the appropriate triggers were registered for the
 	// default checkers.
@@ -692,14 +711,14 @@ func TestRegisterDefaultCheckers(t *testing.T) {
 	require.Contains(t, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts, ConfigModified)
 	require.Contains(t, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts, DBHostsModified)
 
-	require.EqualValues(t, 12, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts[ManualRun])
-	require.EqualValues(t, 12, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts[ConfigModified])
-	require.EqualValues(t, 4, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts[DBHostsModified])
+	require.EqualValues(t, 29, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts[ManualRun])
+	require.EqualValues(t, 29, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts[ConfigModified])
+	require.EqualValues(t, 6, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts[DBHostsModified])
 	require.EqualValues(t, 0, dispatcher.groups[KeaDHCPDaemon].triggerRefCounts[StorkAgentConfigModified])
-	require.EqualValues(t, 1, dispatcher.groups[KeaCADaemon].triggerRefCounts[ManualRun])
-	require.EqualValues(t, 1, dispatcher.groups[KeaCADaemon].triggerRefCounts[ConfigModified])
+	require.EqualValues(t, 2, dispatcher.groups[KeaCADaemon].triggerRefCounts[ManualRun])
+	require.EqualValues(t, 2, dispatcher.groups[KeaCADaemon].triggerRefCounts[ConfigModified])
 	require.EqualValues(t, 0, dispatcher.groups[KeaCADaemon].triggerRefCounts[DBHostsModified])
-	require.EqualValues(t, 1, dispatcher.groups[KeaCADaemon].triggerRefCounts[StorkAgentConfigModified])
+	require.EqualValues(t, 2, dispatcher.groups[KeaCADaemon].triggerRefCounts[StorkAgentConfigModified])
 }
 
 // Verifies that registering new checkers and bumping up the
@@ -1077,3 +1096,157 @@ func TestTriggersIsInternalRun(t *testing.T) {
 		require.False(t, Triggers{}.isInternalRun())
 	})
 }
+
+// Test that the config reviews are re-run for all Kea daemons having the
+// configurations stored in the database and that the reports are
+// regenerated from the stored configurations.
+func TestReviewAllDaemons(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	machine := &dbmodel.Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	err := dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+
+	config, err := dbmodel.NewKeaConfigFromJSON(`{
+		"Dhcp4": {
+			"subnet4": [
+				{
+					"id": 1,
+					"subnet": "192.0.2.0/24"
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+
+	// The DHCPv6 daemon has no configuration so it should not be reviewed.
+	app := &dbmodel.App{
+		Type:      dbmodel.AppTypeKea,
+		MachineID: machine.ID,
+		Daemons: []*dbmodel.Daemon{
+			{
+				Name:   dbmodel.DaemonNameDHCPv4,
+				Active: true,
+				KeaDaemon: &dbmodel.KeaDaemon{
+					Config: config,
+				},
+			},
+			{
+				Name:      dbmodel.DaemonNameDHCPv6,
+				Active:    true,
+				KeaDaemon: &dbmodel.KeaDaemon{},
+			},
+		},
+	}
+	daemons, err := dbmodel.AddApp(db, app)
+	require.NoError(t, err)
+	require.Len(t, daemons, 2)
+
+	dispatcher := NewDispatcher(db)
+	dispatcher.RegisterChecker(KeaDHCPDaemon, "subnets_count", GetDefaultTriggers(), func(ctx *ReviewContext) (*Report, error) {
+		subnets := ctx.subjectDaemon.KeaDaemon.Config.GetSubnets()
+		return NewReport(ctx, fmt.Sprintf("configuration includes %d subnet(s)", len(subnets))).create()
+	})
+	dispatcher.Start()
+	defer dispatcher.Shutdown()
+
+	reviewAll := func() (int, []error) {
+		var (
+			mutex  sync.Mutex
+			errs   []error
+			wg     sync.WaitGroup
+			nCalls int
+		)
+		wg.Add(1)
+		scheduled, err := ReviewAllDaemons(db, dispatcher, func(daemonID int64, err error) {
+			defer wg.Done()
+			mutex.Lock()
+			defer mutex.Unlock()
+			nCalls++
+			errs = append(errs, err)
+		})
+		require.NoError(t, err)
+		wg.Wait()
+		require.Equal(t, 1, nCalls)
+		return scheduled, errs
+	}
+
+	// Act
+	scheduled, errs := reviewAll()
+
+	// Assert
+	require.Equal(t, 1, scheduled)
+	require.Len(t, errs, 1)
+	require.NoError(t, errs[0])
+
+	reports, total, err := dbmodel.GetConfigReportsByDaemonID(db, 0, 0, daemons[0].ID, false)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, total)
+	require.Equal(t, "configuration includes 1 subnet(s)", *reports[0].Content)
+
+	reports, total, err = dbmodel.GetConfigReportsByDaemonID(db, 0, 0, daemons[1].ID, false)
+	require.NoError(t, err)
+	require.Zero(t, total)
+	require.Empty(t, reports)
+
+	// Arrange
+	// Modify the stored configuration.
+	daemon, err := dbmodel.GetDaemonByID(db, daemons[0].ID)
+	require.NoError(t, err)
+	err = daemon.SetConfigFromJSON(`{
+		"Dhcp4": {
+			"subnet4": [
+				{
+					"id": 1,
+					"subnet": "192.0.2.0/24"
+				},
+				{
+					"id": 2,
+					"subnet": "192.0.3.0/24"
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+	err = dbmodel.UpdateDaemon(db, daemon)
+	require.NoError(t, err)
+
+	// Act
+	scheduled, errs = reviewAll()
+
+	// Assert
+	require.Equal(t, 1, scheduled)
+	require.NoError(t, errs[0])
+
+	// The report should be replaced with the one reflecting the new
+	// configuration.
+	reports, total, err = dbmodel.GetConfigReportsByDaemonID(db, 0, 0, daemons[0].ID, false)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, total)
+	require.Equal(t, "configuration includes 2 subnet(s)", *reports[0].Content)
+}
+
+// Test that no reviews are scheduled when there are no Kea daemons with
+// configurations.
+func TestReviewAllDaemonsNoDaemons(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	dispatcher := NewDispatcher(db)
+	RegisterDefaultCheckers(dispatcher)
+	dispatcher.Start()
+	defer dispatcher.Shutdown()
+
+	// Act
+	scheduled, err := ReviewAllDaemons(db, dispatcher, nil)
+
+	// Assert
+	require.NoError(t, err)
+	require.Zero(t, scheduled)
+}
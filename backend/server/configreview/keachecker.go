@@ -1,7 +1,9 @@
 package configreview
 
 import (
+	"bytes"
 	"fmt"
+	"net"
 	"net/url"
 	"sort"
 	"strconv"
@@ -9,6 +11,7 @@ import (
 
 	"github.com/pkg/errors"
 	keaconfig "isc.org/stork/appcfg/kea"
+	dbops "isc.org/stork/server/database"
 	dbmodel "isc.org/stork/server/database/model"
 	storkutil "isc.org/stork/util"
 )
@@ -63,14 +66,14 @@ func sharedNetworkDispensable(ctx *ReviewContext) (*Report, error) {
 
 	// Iterate over the shared-networks and check if any of them is
 	// empty or contains only one subnet.
-	emptyCount := int64(0)
+	var emptyNames []string
 	singleCount := int64(0)
 	for _, net := range sharedNetworks {
 		// Depending on whether there are no subnets or there is a single
 		// subnet let's update the respective counters.
 		switch len(net.GetSubnets()) {
 		case 0:
-			emptyCount++
+			emptyNames = append(emptyNames, fmt.Sprintf("'%s'", net.GetName()))
 		case 1:
 			singleCount++
 		}
@@ -78,6 +81,7 @@ func sharedNetworkDispensable(ctx *ReviewContext) (*Report, error) {
 
 	// Create a report only if there is at least one empty shared network
 	// or a shared network with only one subnet.
+	emptyCount := int64(len(emptyNames))
 	if emptyCount > 0 || singleCount > 0 {
 		details := ""
 		if emptyCount > 0 {
@@ -90,12 +94,21 @@ func sharedNetworkDispensable(ctx *ReviewContext) (*Report, error) {
 			details += storkutil.FormatNoun(singleCount, "shared network", "s")
 			details += " with only a single subnet"
 		}
-		r, err := NewReport(ctx, fmt.Sprintf("Kea {daemon} configuration "+
+		text := fmt.Sprintf("Kea {daemon} configuration "+
 			"includes %s. Shared networks create overhead for a Kea server "+
 			"configuration and DHCP message processing, affecting their "+
 			"performance. It is recommended to remove any shared networks "+
 			"having none or a single subnet and specify these subnets at the "+
-			"global configuration level.", details)).
+			"global configuration level.", details)
+		if emptyCount > 0 {
+			// Name the empty shared networks because they may indicate
+			// that the configuration was only partially edited, e.g., the
+			// subnets were moved out of them or deleted.
+			text += fmt.Sprintf(" The shared networks without member subnets: %s. "+
+				"They may indicate that the configuration was only partially edited.",
+				strings.Join(emptyNames, ", "))
+		}
+		r, err := NewReport(ctx, text).
 			referencingDaemon(ctx.subjectDaemon).
 			create()
 		return r, err
@@ -1174,36 +1187,1496 @@ func subnetCmdsAndConfigBackendMutualExclusion(ctx *ReviewContext) (*Report, err
 	).referencingDaemon(ctx.subjectDaemon).create()
 }
 
-// The checker validates that the Stork agent communicates with the Kea Control
-// Agent using the HTTPS protocol when the HTTP authentication credentials
-// (i.e., Basic Auth) are configured.
-func credentialsOverHTTPS(ctx *ReviewContext) (*Report, error) {
-	if ctx.subjectDaemon.Name != dbmodel.DaemonNameCA {
+// Returns the description of the conflict between the effective renew timer,
+// rebind timer and valid lifetime of a subnet. It returns an empty string if
+// the timers are configured correctly or are unspecified.
+func getLeaseTimersConflict(timers keaconfig.TimerParameters, lifetimes keaconfig.ValidLifetimeParameters) string {
+	var conflicts []string
+	if timers.RenewTimer != nil && timers.RebindTimer != nil && *timers.RenewTimer >= *timers.RebindTimer {
+		conflicts = append(conflicts, fmt.Sprintf("renew-timer (%d) is not lower than rebind-timer (%d)",
+			*timers.RenewTimer, *timers.RebindTimer))
+	}
+	if lifetimes.ValidLifetime != nil {
+		if timers.RenewTimer != nil && *timers.RenewTimer > *lifetimes.ValidLifetime {
+			conflicts = append(conflicts, fmt.Sprintf("renew-timer (%d) exceeds valid-lifetime (%d)",
+				*timers.RenewTimer, *lifetimes.ValidLifetime))
+		}
+		if timers.RebindTimer != nil && *timers.RebindTimer > *lifetimes.ValidLifetime {
+			conflicts = append(conflicts, fmt.Sprintf("rebind-timer (%d) exceeds valid-lifetime (%d)",
+				*timers.RebindTimer, *lifetimes.ValidLifetime))
+		}
+	}
+	return strings.Join(conflicts, ", ")
+}
+
+// The checker verifying that the renew timer is lower than the rebind timer
+// and that none of them exceeds the valid lifetime in any subnet. The timers
+// and the valid lifetime are resolved according to the Kea configuration
+// inheritance scheme, i.e., they can be specified at the subnet, shared
+// network or global level.
+func leaseTimersConflict(ctx *ReviewContext) (*Report, error) {
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv4 &&
+		ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv6 {
 		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
 	}
 
-	machine := ctx.subjectDaemon.App.Machine
+	config := ctx.subjectDaemon.KeaDaemon.Config
+	globalTimers := config.GetTimerParameters()
+	globalLifetimes := config.GetValidLifetimeParameters()
 
-	if !machine.State.AgentUsesHTTPCredentials {
-		// The HTTP credentials are not configured. Nothing to do.
+	maxIssues := 10
+	var issues []string
+
+	// The top-level subnets are returned as members of the shared network
+	// with no name and no parameters.
+	for _, sharedNetwork := range config.GetSharedNetworks(true) {
+		networkParams := sharedNetwork.GetSharedNetworkParameters()
+		for _, subnet := range sharedNetwork.GetSubnets() {
+			subnetParams := subnet.GetSubnetParameters()
+			timers := keaconfig.ResolveTimerParameters(
+				subnetParams.TimerParameters,
+				networkParams.TimerParameters,
+				globalTimers,
+			)
+			lifetimes := keaconfig.ResolveValidLifetimeParameters(
+				subnetParams.ValidLifetimeParameters,
+				networkParams.ValidLifetimeParameters,
+				globalLifetimes,
+			)
+			conflict := getLeaseTimersConflict(timers, lifetimes)
+			if conflict == "" {
+				continue
+			}
+
+			subnetID := ""
+			if subnet.GetID() != 0 {
+				subnetID = fmt.Sprintf("[%d] ", subnet.GetID())
+			}
+			issues = append(issues, fmt.Sprintf("%d. %s%s: %s",
+				len(issues)+1, subnetID, subnet.GetPrefix(), conflict))
+
+			if len(issues) == maxIssues {
+				break
+			}
+		}
+		if len(issues) == maxIssues {
+			break
+		}
+	}
+
+	if len(issues) == 0 {
+		return nil, nil
+	}
+
+	maxExceedMessage := ""
+	if len(issues) == maxIssues {
+		maxExceedMessage = " at least"
+	}
+
+	return NewReport(ctx, fmt.Sprintf("Kea {daemon} configuration "+
+		"includes%s %s with conflicting lease timers. The renew-timer "+
+		"should be lower than the rebind-timer, and both should not "+
+		"exceed the valid-lifetime. Otherwise, the DHCP clients may "+
+		"renew or rebind their leases at unexpected times.\n%s",
+		maxExceedMessage,
+		storkutil.FormatNoun(int64(len(issues)), "subnet", "s"),
+		strings.Join(issues, "; "))).referencingDaemon(ctx.subjectDaemon).create()
+}
+
+// The checker reporting the client classes defined in the configuration
+// but never used, i.e., not referenced by any subnet, shared network, pool,
+// host reservation or other class, and not defining any DHCP options. Such
+// classes are often leftovers from the previous configurations.
+func clientClassesUnused(ctx *ReviewContext) (*Report, error) {
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv4 &&
+		ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv6 {
+		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
+	}
+
+	unused := ctx.subjectDaemon.KeaDaemon.Config.GetUnusedClientClasses()
+	if len(unused) == 0 {
 		return nil, nil
 	}
 
+	names := make([]string, len(unused))
+	for i, clientClass := range unused {
+		names[i] = fmt.Sprintf("'%s'", clientClass.Name)
+	}
+
+	return NewReport(ctx, fmt.Sprintf("Kea {daemon} configuration "+
+		"includes %s not referenced by any subnet, shared network, pool, "+
+		"host reservation or other class, and not defining any DHCP "+
+		"options. Unused classes are often leftovers from previous "+
+		"configurations. Consider removing them unless they are used by "+
+		"the host reservations in the database or by the hook libraries.\n%s",
+		storkutil.FormatNoun(int64(len(unused)), "unused client class", "es"),
+		strings.Join(names, ", "))).referencingDaemon(ctx.subjectDaemon).create()
+}
+
+// The checker verifying that the addresses reserved in the subnets belong
+// to these subnets. Kea doesn't assign the reserved addresses falling
+// outside the subnet prefix, so such reservations are configuration errors.
+// The reserved delegated prefixes are not checked because they don't have
+// to belong to the subnet prefix.
+func reservationsOutOfSubnet(ctx *ReviewContext) (*Report, error) {
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv4 &&
+		ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv6 {
+		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
+	}
+
 	config := ctx.subjectDaemon.KeaDaemon.Config
 
-	if config.UseSecureProtocol() {
-		// The TLS is configured. All is OK.
+	// Global subnets and shared networks.
+	subnets := config.GetSubnets()
+	for _, sharedNetwork := range config.GetSharedNetworks(false) {
+		subnets = append(subnets, sharedNetwork.GetSubnets()...)
+	}
+
+	maxIssues := 10
+	var issues []string
+
+	for _, subnet := range subnets {
+		parsedPrefix := storkutil.ParseIP(subnet.GetPrefix())
+		if parsedPrefix == nil || parsedPrefix.IPNet == nil {
+			// Invalid prefixes are reported by the canonical_prefix checker.
+			continue
+		}
+		subnetID := ""
+		if subnet.GetID() != 0 {
+			subnetID = fmt.Sprintf("[%d] ", subnet.GetID())
+		}
+		for _, reservation := range subnet.GetReservations() {
+			addresses := reservation.IPAddresses
+			if reservation.IPAddress != "" {
+				addresses = append([]string{reservation.IPAddress}, addresses...)
+			}
+			for _, address := range addresses {
+				parsedAddress := storkutil.ParseIP(address)
+				if parsedAddress != nil && parsedAddress.IPNet == nil && parsedPrefix.IPNet.Contains(parsedAddress.IP) {
+					continue
+				}
+				issues = append(issues, fmt.Sprintf("%d. %s is reserved in %s%s",
+					len(issues)+1, address, subnetID, subnet.GetPrefix()))
+				if len(issues) == maxIssues {
+					break
+				}
+			}
+			if len(issues) == maxIssues {
+				break
+			}
+		}
+		if len(issues) == maxIssues {
+			break
+		}
+	}
+
+	if len(issues) == 0 {
 		return nil, nil
 	}
 
-	// The Stork agent has HTTP credentials configured but communicates with
-	// Kea over unsecure protocol.
-	return NewReport(ctx, "The Kea Control Agent requires the Basic Auth "+
-		"credentials but it accepts connections over unsecure protocol - TLS "+
-		"is disabled. The communication between the Stork Agent and Kea Control "+
-		"Agent is vulnerable to man-in-the-middle attacks and the credentials "+
-		"may be stolen. "+
-		"Configure the 'trust-anchor', 'cert-file', and 'key-file' "+
-		"properties in the Kea Control Agent {daemon} configuration to use "+
-		"the secure protocol.").referencingDaemon(ctx.subjectDaemon).create()
+	maxExceedMessage := ""
+	if len(issues) == maxIssues {
+		maxExceedMessage = " at least"
+	}
+
+	return NewReport(ctx, fmt.Sprintf("Kea {daemon} configuration "+
+		"includes%s %s outside the prefixes of the subnets they are "+
+		"specified in. Kea does not assign such addresses to the DHCP "+
+		"clients. Move the reservations to the appropriate subnets or "+
+		"correct the reserved addresses.\n%s", maxExceedMessage,
+		storkutil.FormatNoun(int64(len(issues)), "reserved address", "es"),
+		strings.Join(issues, "; "))).referencingDaemon(ctx.subjectDaemon).create()
+}
+
+// Checks if any of the reservations specified in the subnet configuration
+// or in the host database for this subnet is within the subnet pools.
+func hasInPoolReservations(subnet keaconfig.Subnet, dbHosts []dbmodel.Host) bool {
+	for _, reservation := range subnet.GetReservations() {
+		addresses := reservation.IPAddresses
+		if reservation.IPAddress != "" {
+			addresses = append([]string{reservation.IPAddress}, addresses...)
+		}
+		if isAnyAddressInPools(addresses, subnet.GetPools()) ||
+			isAnyPrefixInPools(reservation.Prefixes, subnet.GetPDPools()) {
+			return true
+		}
+	}
+	for _, dbHost := range dbHosts {
+		if isAnyIPReservationInPools(dbHost.IPReservations, subnet.GetPools()) ||
+			isAnyIPReservationInPDPools(dbHost.IPReservations, subnet.GetPDPools()) {
+			return true
+		}
+	}
+	return false
+}
+
+// The checker verifying that the subnets with the in-pool reservations
+// have the in-subnet reservation mode enabled. Kea ignores the subnet-level
+// reservations when the reservations-in-subnet is disabled, so the reserved
+// addresses and prefixes may be assigned to other clients.
+func reservationsInPoolIgnored(ctx *ReviewContext) (*Report, error) {
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv4 &&
+		ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv6 {
+		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
+	}
+
+	config := ctx.subjectDaemon.KeaDaemon.Config
+	globalParameters := config.GetGlobalReservationParameters()
+
+	// Get hosts from the database when libdhcp_host_cmds hooks library is used.
+	_, dbHosts, err := getDaemonHostsAndIndexBySubnet(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	maxIssues := 10
+	var issues []string
+
+	for _, sharedNetwork := range config.GetSharedNetworks(true) {
+		for _, subnet := range sharedNetwork.GetSubnets() {
+			// Check if the in-subnet reservation mode effective for the
+			// subnet is enabled. The mode is enabled by default.
+			if keaconfig.GetEffectiveReservationMode(func(modes keaconfig.ReservationParameters) (bool, bool) {
+				return modes.IsInSubnet()
+			}, subnet.GetSubnetParameters().ReservationParameters, sharedNetwork.GetSharedNetworkParameters().ReservationParameters, globalParameters) {
+				continue
+			}
+			if !hasInPoolReservations(subnet, dbHosts[subnet.GetID()]) {
+				continue
+			}
+			subnetID := ""
+			if subnet.GetID() != 0 {
+				subnetID = fmt.Sprintf("[%d] ", subnet.GetID())
+			}
+			issues = append(issues, fmt.Sprintf("%d. %s%s", len(issues)+1, subnetID, subnet.GetPrefix()))
+			if len(issues) == maxIssues {
+				break
+			}
+		}
+		if len(issues) == maxIssues {
+			break
+		}
+	}
+
+	if len(issues) == 0 {
+		return nil, nil
+	}
+
+	maxExceedMessage := ""
+	if len(issues) == maxIssues {
+		maxExceedMessage = " at least"
+	}
+
+	return NewReport(ctx, fmt.Sprintf("Kea {daemon} configuration "+
+		"includes%s %s with the in-pool host reservations but the "+
+		"reservations-in-subnet is disabled for them. Kea does not honor "+
+		"such reservations and may assign the reserved addresses or "+
+		"delegated prefixes to other clients. Enable the "+
+		"reservations-in-subnet or remove the reservations.\n%s",
+		maxExceedMessage, storkutil.FormatNoun(int64(len(issues)), "subnet", "s"),
+		strings.Join(issues, "; "))).referencingDaemon(ctx.subjectDaemon).create()
+}
+
+// Returns the number of the global host reservations of the daemon. They
+// include the reservations specified in the configuration and the global
+// reservations in the host database when the libdhcp_host_cmds hooks
+// library is used.
+func countGlobalReservations(ctx *ReviewContext) (int64, error) {
+	config := ctx.subjectDaemon.KeaDaemon.Config
+	count := int64(len(config.GetReservations()))
+	if ctx.db == nil {
+		return count, nil
+	}
+	if _, _, present := config.GetHookLibrary("libdhcp_host_cmds"); !present {
+		return count, nil
+	}
+	hosts, _, err := dbmodel.GetHostsByDaemonID(ctx.db, ctx.subjectDaemon.ID, dbmodel.HostDataSourceAPI)
+	if err != nil {
+		return count, err
+	}
+	for _, host := range hosts {
+		if host.SubnetID == 0 {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// The checker verifying that the reservations-global is enabled at some
+// configuration level when the global host reservations are defined. Kea
+// ignores the global reservations when the reservations-global is disabled
+// globally and for all subnets, so the reserved resources may be assigned
+// to other clients.
+func reservationsGlobalIgnored(ctx *ReviewContext) (*Report, error) {
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv4 &&
+		ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv6 {
+		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
+	}
+
+	config := ctx.subjectDaemon.KeaDaemon.Config
+	globalParameters := config.GetGlobalReservationParameters()
+
+	// The global reservations are honored for the subnets with the global
+	// reservation mode effectively enabled. The mode is disabled by default.
+	if enabled, _ := globalParameters.IsGlobal(); enabled {
+		return nil, nil
+	}
+	for _, sharedNetwork := range config.GetSharedNetworks(true) {
+		for _, subnet := range sharedNetwork.GetSubnets() {
+			if keaconfig.GetEffectiveReservationMode(func(modes keaconfig.ReservationParameters) (bool, bool) {
+				return modes.IsGlobal()
+			}, subnet.GetSubnetParameters().ReservationParameters, sharedNetwork.GetSharedNetworkParameters().ReservationParameters, globalParameters) {
+				return nil, nil
+			}
+		}
+	}
+
+	count, err := countGlobalReservations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, nil
+	}
+
+	return NewReport(ctx, fmt.Sprintf("Kea {daemon} configuration "+
+		"includes %s but the reservations-global is disabled globally "+
+		"and for all subnets. Kea does not honor such reservations and "+
+		"may assign the reserved resources to other clients. Enable the "+
+		"reservations-global or remove the global reservations.",
+		storkutil.FormatNoun(count, "global host reservation", "s"))).
+		referencingDaemon(ctx.subjectDaemon).
+		create()
+}
+
+// The default number of the pools of one kind in a subnet above which the
+// subnet is reported as fragmented. It is used when the threshold cannot
+// be read from the database.
+const defaultMaxPoolsPerSubnet int64 = 16
+
+// The checker verifying that the subnets don't have an unusually high
+// number of address or delegated prefix pools. Many small pools usually
+// indicate an operational problem (e.g., the pools added over time instead
+// of extending the existing ones) and hurt the allocator performance. The
+// threshold is configurable in the max_pools_per_subnet setting.
+func fragmentedPools(ctx *ReviewContext) (*Report, error) {
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv4 &&
+		ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv6 {
+		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
+	}
+
+	maxPools := defaultMaxPoolsPerSubnet
+	if ctx.db != nil {
+		var err error
+		maxPools, err = dbmodel.GetSettingInt(ctx.db, "max_pools_per_subnet")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	config := ctx.subjectDaemon.KeaDaemon.Config
+
+	// Global subnets and shared networks.
+	subnets := config.GetSubnets()
+	for _, sharedNetwork := range config.GetSharedNetworks(false) {
+		subnets = append(subnets, sharedNetwork.GetSubnets()...)
+	}
+
+	maxIssues := 10
+	var issues []string
+
+	for _, subnet := range subnets {
+		var counts []string
+		if poolCount := int64(len(subnet.GetPools())); poolCount > maxPools {
+			counts = append(counts, storkutil.FormatNoun(poolCount, "address pool", "s"))
+		}
+		if pdPoolCount := int64(len(subnet.GetPDPools())); pdPoolCount > maxPools {
+			counts = append(counts, storkutil.FormatNoun(pdPoolCount, "delegated prefix pool", "s"))
+		}
+		if len(counts) == 0 {
+			continue
+		}
+		subnetID := ""
+		if subnet.GetID() != 0 {
+			subnetID = fmt.Sprintf("[%d] ", subnet.GetID())
+		}
+		issues = append(issues, fmt.Sprintf("%d. %s%s has %s", len(issues)+1,
+			subnetID, subnet.GetPrefix(), strings.Join(counts, " and ")))
+		if len(issues) == maxIssues {
+			break
+		}
+	}
+
+	if len(issues) == 0 {
+		return nil, nil
+	}
+
+	maxExceedMessage := ""
+	if len(issues) == maxIssues {
+		maxExceedMessage = " at least"
+	}
+
+	return NewReport(ctx, fmt.Sprintf("Kea {daemon} configuration "+
+		"includes%s %s with more than %d pools of one kind. The subnets "+
+		"fragmented across many small pools are harder to maintain and "+
+		"may hurt the lease allocation performance. Consider merging the "+
+		"adjacent pools into larger ones.\n%s", maxExceedMessage,
+		storkutil.FormatNoun(int64(len(issues)), "subnet", "s"), maxPools,
+		strings.Join(issues, "; "))).referencingDaemon(ctx.subjectDaemon).create()
+}
+
+// Returns a list of the pools belonging to the subnet whose addresses or
+// prefixes are from the other address family than the subnet prefix. The
+// pools that cannot be parsed are not returned.
+func getPoolsWithMismatchedFamily(subnet keaconfig.Subnet) (pools []string) {
+	subnetPrefix := storkutil.ParseIP(subnet.GetPrefix())
+	if subnetPrefix == nil {
+		return
+	}
+	isIPv4 := subnetPrefix.Protocol == storkutil.IPv4
+	for _, pool := range subnet.GetPools() {
+		lb, _, err := pool.GetBoundaries()
+		if err != nil {
+			continue
+		}
+		if (lb.To4() != nil) != isIPv4 {
+			pools = append(pools, pool.Pool)
+		}
+	}
+	for _, pdPool := range subnet.GetPDPools() {
+		prefix := storkutil.ParseIP(pdPool.Prefix)
+		if prefix == nil {
+			continue
+		}
+		if (prefix.Protocol == storkutil.IPv4) != isIPv4 {
+			pools = append(pools, pdPool.GetCanonicalPrefix())
+		}
+	}
+	return
+}
+
+// The checker verifying that the address and delegated prefix pools belong
+// to the same address family as their subnets, e.g., there are no IPv6
+// pools in the IPv4 subnets. Such pools are the configuration errors.
+func poolsFamilyMismatch(ctx *ReviewContext) (*Report, error) {
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv4 &&
+		ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv6 {
+		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
+	}
+
+	config := ctx.subjectDaemon.KeaDaemon.Config
+
+	// Global subnets and shared networks.
+	subnets := config.GetSubnets()
+	for _, sharedNetwork := range config.GetSharedNetworks(false) {
+		subnets = append(subnets, sharedNetwork.GetSubnets()...)
+	}
+
+	maxIssues := 10
+	var issues []string
+	count := 0
+
+	for _, subnet := range subnets {
+		pools := getPoolsWithMismatchedFamily(subnet)
+		if len(pools) == 0 {
+			continue
+		}
+		count++
+		if len(issues) == maxIssues {
+			continue
+		}
+		subnetID := ""
+		if subnet.GetID() != 0 {
+			subnetID = fmt.Sprintf("[%d] ", subnet.GetID())
+		}
+		issues = append(issues, fmt.Sprintf("%d. %s%s has %s", len(issues)+1,
+			subnetID, subnet.GetPrefix(), strings.Join(pools, ", ")))
+	}
+
+	if count == 0 {
+		return nil, nil
+	}
+
+	return NewReport(ctx, fmt.Sprintf("Kea {daemon} configuration "+
+		"includes %s with the pools from the other address family than "+
+		"the subnet prefix. Such pools can't be used to allocate the "+
+		"leases in these subnets. Move the pools to the subnets of the "+
+		"matching address family or remove them.\n%s",
+		storkutil.FormatNoun(int64(count), "subnet", "s"),
+		strings.Join(issues, "; "))).referencingDaemon(ctx.subjectDaemon).create()
+}
+
+// Returns the pairs of the overlapping address pools in the subnet. The
+// pools may be specified as the address ranges or prefixes. The adjacent
+// pools don't overlap. The pools that cannot be parsed are skipped.
+func findOverlappingPools(subnet keaconfig.Subnet) (overlaps []string) {
+	type poolRange struct {
+		pool   string
+		lb, ub net.IP
+	}
+	var ranges []poolRange
+	for _, pool := range subnet.GetPools() {
+		lb, ub, err := pool.GetBoundaries()
+		if err != nil {
+			continue
+		}
+		ranges = append(ranges, poolRange{pool.Pool, lb.To16(), ub.To16()})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return bytes.Compare(ranges[i].lb, ranges[j].lb) < 0
+	})
+	// The pools are sorted by the lower bounds, so the pool overlaps the
+	// preceding pools whose upper bounds are not lower than its lower bound.
+	for i := range ranges {
+		for j := 0; j < i; j++ {
+			if bytes.Compare(ranges[j].ub, ranges[i].lb) >= 0 {
+				overlaps = append(overlaps, fmt.Sprintf("%s and %s", ranges[j].pool, ranges[i].pool))
+			}
+		}
+	}
+	return
+}
+
+// The checker verifying that the address pools in the subnets don't
+// overlap. Kea may allocate the same address from both overlapping pools,
+// especially when the pools are guarded by different client classes.
+func overlappingPools(ctx *ReviewContext) (*Report, error) {
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv4 &&
+		ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv6 {
+		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
+	}
+
+	config := ctx.subjectDaemon.KeaDaemon.Config
+
+	// Global subnets and shared networks.
+	subnets := config.GetSubnets()
+	for _, sharedNetwork := range config.GetSharedNetworks(false) {
+		subnets = append(subnets, sharedNetwork.GetSubnets()...)
+	}
+
+	maxIssues := 10
+	var issues []string
+	count := 0
+
+	for _, subnet := range subnets {
+		overlaps := findOverlappingPools(subnet)
+		if len(overlaps) == 0 {
+			continue
+		}
+		count++
+		if len(issues) == maxIssues {
+			continue
+		}
+		subnetID := ""
+		if subnet.GetID() != 0 {
+			subnetID = fmt.Sprintf("[%d] ", subnet.GetID())
+		}
+		issues = append(issues, fmt.Sprintf("%d. %s%s has overlapping pools %s", len(issues)+1,
+			subnetID, subnet.GetPrefix(), strings.Join(overlaps, ", ")))
+	}
+
+	if count == 0 {
+		return nil, nil
+	}
+
+	return NewReport(ctx, fmt.Sprintf("Kea {daemon} configuration "+
+		"includes %s with the overlapping address pools. The same "+
+		"address may be allocated from more than one pool. Make the "+
+		"pools disjoint.\n%s",
+		storkutil.FormatNoun(int64(count), "subnet", "s"),
+		strings.Join(issues, "; "))).referencingDaemon(ctx.subjectDaemon).create()
+}
+
+// The default bounds of the sane valid lifetime in seconds. They are used
+// when the bounds cannot be read from the database.
+const (
+	defaultMinValidLifetime int64 = 300
+	defaultMaxValidLifetime int64 = 604800
+)
+
+// The checker verifying that the valid lifetime in the subnets is neither
+// extremely low nor extremely high. A very short lifetime causes heavy
+// renewal traffic and the clients losing their leases when the server is
+// briefly unavailable, while a very long lifetime holds the addresses of
+// the departed clients. The valid lifetime is resolved according to the
+// Kea configuration inheritance scheme. The subnets using the Kea default
+// valid lifetime are not reported. The bounds are configurable in the
+// min_valid_lifetime and max_valid_lifetime settings.
+func validLifetimeOutOfRange(ctx *ReviewContext) (*Report, error) {
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv4 &&
+		ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv6 {
+		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
+	}
+
+	minLifetime := defaultMinValidLifetime
+	maxLifetime := defaultMaxValidLifetime
+	if ctx.db != nil {
+		var err error
+		minLifetime, err = dbmodel.GetSettingInt(ctx.db, "min_valid_lifetime")
+		if err != nil {
+			return nil, err
+		}
+		maxLifetime, err = dbmodel.GetSettingInt(ctx.db, "max_valid_lifetime")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	config := ctx.subjectDaemon.KeaDaemon.Config
+	globalLifetimes := config.GetValidLifetimeParameters()
+
+	maxIssues := 10
+	var issues []string
+	count := 0
+
+	// The top-level subnets are returned as members of the shared network
+	// with no name and no parameters.
+	for _, sharedNetwork := range config.GetSharedNetworks(true) {
+		networkParams := sharedNetwork.GetSharedNetworkParameters()
+		for _, subnet := range sharedNetwork.GetSubnets() {
+			lifetimes := keaconfig.ResolveValidLifetimeParameters(
+				subnet.GetSubnetParameters().ValidLifetimeParameters,
+				networkParams.ValidLifetimeParameters,
+				globalLifetimes,
+			)
+			if lifetimes.ValidLifetime == nil {
+				continue
+			}
+			lifetime := *lifetimes.ValidLifetime
+			var problem string
+			switch {
+			case lifetime < minLifetime:
+				problem = "too low"
+			case lifetime > maxLifetime:
+				problem = "too high"
+			default:
+				continue
+			}
+			count++
+			if len(issues) == maxIssues {
+				continue
+			}
+			subnetID := ""
+			if subnet.GetID() != 0 {
+				subnetID = fmt.Sprintf("[%d] ", subnet.GetID())
+			}
+			issues = append(issues, fmt.Sprintf("%d. %s%s: valid-lifetime %d is %s",
+				len(issues)+1, subnetID, subnet.GetPrefix(), lifetime, problem))
+		}
+	}
+
+	if count == 0 {
+		return nil, nil
+	}
+
+	return NewReport(ctx, fmt.Sprintf("Kea {daemon} configuration "+
+		"includes %s with the valid-lifetime outside of the range from "+
+		"%d to %d seconds. A very short lifetime causes heavy renewal "+
+		"traffic and the clients may lose their leases when the server is "+
+		"briefly unavailable. A very long lifetime keeps the leases of the "+
+		"departed clients and may exhaust the pools.\n%s",
+		storkutil.FormatNoun(int64(count), "subnet", "s"), minLifetime, maxLifetime,
+		strings.Join(issues, "; "))).referencingDaemon(ctx.subjectDaemon).create()
+}
+
+// The checker finding the subnets without the valid-lifetime specified at
+// the subnet, shared network or global level. Such subnets use the Kea
+// default valid lifetime, which operators are often unaware of. The finding
+// is informational because the default lifetime is reasonable.
+func validLifetimeUnset(ctx *ReviewContext) (*Report, error) {
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv4 &&
+		ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv6 {
+		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
+	}
+
+	config := ctx.subjectDaemon.KeaDaemon.Config
+	globalLifetimes := config.GetValidLifetimeParameters()
+
+	maxIssues := 10
+	var issues []string
+	count := 0
+
+	// The top-level subnets are returned as members of the shared network
+	// with no name and no parameters.
+	for _, sharedNetwork := range config.GetSharedNetworks(true) {
+		networkParams := sharedNetwork.GetSharedNetworkParameters()
+		for _, subnet := range sharedNetwork.GetSubnets() {
+			lifetimes := keaconfig.ResolveValidLifetimeParameters(
+				subnet.GetSubnetParameters().ValidLifetimeParameters,
+				networkParams.ValidLifetimeParameters,
+				globalLifetimes,
+			)
+			if lifetimes.ValidLifetime != nil {
+				continue
+			}
+			count++
+			if len(issues) == maxIssues {
+				continue
+			}
+			subnetID := ""
+			if subnet.GetID() != 0 {
+				subnetID = fmt.Sprintf("[%d] ", subnet.GetID())
+			}
+			issues = append(issues, fmt.Sprintf("%d. %s%s", len(issues)+1, subnetID, subnet.GetPrefix()))
+		}
+	}
+
+	if count == 0 {
+		return nil, nil
+	}
+
+	return NewReport(ctx, fmt.Sprintf("Kea {daemon} configuration "+
+		"includes %s without the valid-lifetime specified at the subnet, "+
+		"shared network or global level. This is informational; these "+
+		"subnets use the Kea default valid lifetime. "+
+		"Consider setting the valid-lifetime explicitly to make the lease "+
+		"duration evident in the configuration.\n%s",
+		storkutil.FormatNoun(int64(count), "subnet", "s"),
+		strings.Join(issues, "; "))).referencingDaemon(ctx.subjectDaemon).create()
+}
+
+// Returns the descriptions of the options defined more than once in the
+// option data list, e.g., option 6 or option 1 in space vendor-4491. The
+// options are identified by the code and the option space. The options
+// specified by the name only are identified by the name. The options from
+// the default space are described without the space.
+func findDuplicateOptions(options []keaconfig.SingleOptionData, defaultSpace string) (duplicates []string) {
+	counts := make(map[string]int)
+	for _, option := range options {
+		description := fmt.Sprintf("option %d", option.Code)
+		if option.Code == 0 {
+			description = fmt.Sprintf("option %s", option.Name)
+		}
+		if option.Space != "" && option.Space != defaultSpace {
+			description = fmt.Sprintf("%s in space %s", description, option.Space)
+		}
+		counts[description]++
+		if counts[description] == 2 {
+			duplicates = append(duplicates, description)
+		}
+	}
+	return
+}
+
+// Returns the descriptions of the options defined more than once in the
+// subnet scope or in the scope of one of the subnet pools.
+func findSubnetDuplicateOptions(subnet keaconfig.Subnet, defaultSpace string) (duplicates []string) {
+	duplicates = findDuplicateOptions(subnet.GetDHCPOptions(), defaultSpace)
+	for _, pool := range subnet.GetPools() {
+		for _, duplicate := range findDuplicateOptions(pool.OptionData, defaultSpace) {
+			duplicates = append(duplicates, fmt.Sprintf("%s in pool %s", duplicate, pool.Pool))
+		}
+	}
+	for _, pdPool := range subnet.GetPDPools() {
+		for _, duplicate := range findDuplicateOptions(pdPool.OptionData, defaultSpace) {
+			duplicates = append(duplicates, fmt.Sprintf("%s in pool %s", duplicate, pdPool.GetCanonicalPrefix()))
+		}
+	}
+	return
+}
+
+// The checker verifying that the subnets don't define the same option more
+// than once in the same scope, i.e., in the subnet or in one of its pools.
+// Such a configuration is ambiguous because it is unclear which option
+// instance is sent to the clients. The options specified by the code and
+// by the name are not matched against each other.
+func duplicateOptions(ctx *ReviewContext) (*Report, error) {
+	defaultSpace := "dhcp4"
+	switch ctx.subjectDaemon.Name {
+	case dbmodel.DaemonNameDHCPv4:
+	case dbmodel.DaemonNameDHCPv6:
+		defaultSpace = "dhcp6"
+	default:
+		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
+	}
+
+	config := ctx.subjectDaemon.KeaDaemon.Config
+
+	// Global subnets and shared networks.
+	subnets := config.GetSubnets()
+	for _, sharedNetwork := range config.GetSharedNetworks(false) {
+		subnets = append(subnets, sharedNetwork.GetSubnets()...)
+	}
+
+	maxIssues := 10
+	var issues []string
+	count := 0
+
+	for _, subnet := range subnets {
+		duplicates := findSubnetDuplicateOptions(subnet, defaultSpace)
+		if len(duplicates) == 0 {
+			continue
+		}
+		count++
+		if len(issues) == maxIssues {
+			continue
+		}
+		subnetID := ""
+		if subnet.GetID() != 0 {
+			subnetID = fmt.Sprintf("[%d] ", subnet.GetID())
+		}
+		issues = append(issues, fmt.Sprintf("%d. %s%s defines %s more than once",
+			len(issues)+1, subnetID, subnet.GetPrefix(), strings.Join(duplicates, ", ")))
+	}
+
+	if count == 0 {
+		return nil, nil
+	}
+
+	return NewReport(ctx, fmt.Sprintf("Kea {daemon} configuration "+
+		"includes %s defining the same option more than once in the same "+
+		"scope. It is ambiguous which option instance is sent to the "+
+		"clients. Remove the redundant option definitions.\n%s",
+		storkutil.FormatNoun(int64(count), "subnet", "s"),
+		strings.Join(issues, "; "))).referencingDaemon(ctx.subjectDaemon).create()
+}
+
+// The checker verifying if all subnets have explicit IDs. Kea assigns the
+// IDs to the subnets lacking them automatically, but these IDs may change
+// when the subnets are reordered or removed. Stork identifies the subnet
+// statistics using the subnet IDs, so the statistics may be assigned to
+// wrong subnets after such a change.
+func subnetsWithoutID(ctx *ReviewContext) (*Report, error) {
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv4 &&
+		ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv6 {
+		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
+	}
+
+	config := ctx.subjectDaemon.KeaDaemon.Config
+
+	// Global subnets and shared networks.
+	subnets := config.GetSubnets()
+	for _, sharedNetwork := range config.GetSharedNetworks(false) {
+		subnets = append(subnets, sharedNetwork.GetSubnets()...)
+	}
+
+	maxIssues := 10
+	var issues []string
+	count := 0
+
+	for _, subnet := range subnets {
+		if subnet.GetID() != 0 {
+			continue
+		}
+		count++
+		if len(issues) < maxIssues {
+			issues = append(issues, fmt.Sprintf("%d. %s", len(issues)+1, subnet.GetPrefix()))
+		}
+	}
+
+	if count == 0 {
+		return nil, nil
+	}
+
+	return NewReport(ctx, fmt.Sprintf("Kea {daemon} configuration "+
+		"includes %s without explicit IDs. Kea assigns the IDs to such "+
+		"subnets automatically, but they may change when the subnets are "+
+		"added, removed or reordered. It may cause associating the "+
+		"statistics with wrong subnets. Specify the IDs for all subnets.\n%s",
+		storkutil.FormatNoun(int64(count), "subnet", "s"),
+		strings.Join(issues, "; "))).referencingDaemon(ctx.subjectDaemon).create()
+}
+
+// The checker validates that the Stork agent communicates with the Kea Control
+// Agent using the HTTPS protocol when the HTTP authentication credentials
+// (i.e., Basic Auth) are configured.
+func credentialsOverHTTPS(ctx *ReviewContext) (*Report, error) {
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameCA {
+		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
+	}
+
+	machine := ctx.subjectDaemon.App.Machine
+
+	if !machine.State.AgentUsesHTTPCredentials {
+		// The HTTP credentials are not configured. Nothing to do.
+		return nil, nil
+	}
+
+	config := ctx.subjectDaemon.KeaDaemon.Config
+
+	if config.UseSecureProtocol() {
+		// The TLS is configured. All is OK.
+		return nil, nil
+	}
+
+	// The Stork agent has HTTP credentials configured but communicates with
+	// Kea over unsecure protocol.
+	return NewReport(ctx, "The Kea Control Agent requires the Basic Auth "+
+		"credentials but it accepts connections over unsecure protocol - TLS "+
+		"is disabled. The communication between the Stork Agent and Kea Control "+
+		"Agent is vulnerable to man-in-the-middle attacks and the credentials "+
+		"may be stolen. "+
+		"Configure the 'trust-anchor', 'cert-file', and 'key-file' "+
+		"properties in the Kea Control Agent {daemon} configuration to use "+
+		"the secure protocol.").referencingDaemon(ctx.subjectDaemon).create()
+}
+
+// The checker validates that the DHCP daemon participating in the High
+// Availability setup communicates with its peers over the secure protocol.
+// The HA peers exchange the lease updates and state information, so the
+// plain HTTP exposes this traffic to eavesdropping and tampering. The peers'
+// URLs must use the HTTPS scheme. If the HA hook opens the dedicated HTTP
+// listener (HA+MT), the TLS must be configured in the HA hook parameters.
+// Otherwise, the traffic goes through the Kea Control Agent, which must
+// accept the connections over the secure protocol.
+func highAvailabilityInsecureAccessPoint(ctx *ReviewContext) (*Report, error) {
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv4 &&
+		ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv6 {
+		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
+	}
+
+	config := ctx.subjectDaemon.KeaDaemon.Config
+
+	_, params, ok := config.GetHookLibraries().GetHAHookLibrary()
+	if !ok {
+		// There is no HA configured.
+		return nil, nil
+	}
+
+	for _, relationship := range params.HA {
+		var problems []string
+		var insecureURLs []string
+		for _, peer := range relationship.Peers {
+			if peer.URL == nil {
+				continue
+			}
+			if urlObj, err := url.Parse(*peer.URL); err != nil || urlObj.Scheme != "https" {
+				insecureURLs = append(insecureURLs, *peer.URL)
+			}
+		}
+		if len(insecureURLs) > 0 {
+			problems = append(problems, fmt.Sprintf("the peers' URLs use the "+
+				"insecure protocol: %s", strings.Join(insecureURLs, ", ")))
+		}
+
+		var advice string
+		if relationship.IsDedicatedListenerEnabled() {
+			// The peers communicate directly with the HA hook.
+			if !relationship.IsTLSConfigured() {
+				problems = append(problems, "the TLS is not configured for "+
+					"the dedicated HTTP listener")
+			}
+			advice = "Configure the 'trust-anchor', 'cert-file', and " +
+				"'key-file' parameters in the High Availability hook " +
+				"configuration and use the HTTPS scheme in the peers' URLs."
+		} else {
+			// The peers communicate via the Kea Control Agent.
+			if ctx.subjectDaemon.App != nil {
+				accessPoint, err := ctx.subjectDaemon.App.GetAccessPoint(dbmodel.AccessPointControl)
+				if err == nil && !accessPoint.UseSecureProtocol {
+					problems = append(problems, fmt.Sprintf("the Kea Control "+
+						"Agent forwarding the commands to it accepts connections "+
+						"over the insecure protocol on %s",
+						storkutil.HostWithPortURL(accessPoint.Address, accessPoint.Port, false)))
+				}
+			}
+			advice = "Configure the 'trust-anchor', 'cert-file', and " +
+				"'key-file' properties in the Kea Control Agent configuration " +
+				"and use the HTTPS scheme in the peers' URLs."
+		}
+
+		if len(problems) == 0 {
+			continue
+		}
+
+		return NewReport(ctx, fmt.Sprintf("The {daemon} participates in the "+
+			"High Availability setup but %s. The communication between the "+
+			"HA peers, including the lease updates, is vulnerable to "+
+			"man-in-the-middle attacks. %s",
+			strings.Join(problems, " and "), advice)).
+			referencingDaemon(ctx.subjectDaemon).create()
+	}
+	return nil, nil
+}
+
+// Returns the HA peers configured in the specified HA relationships that
+// are not monitored by Stork. The peer is considered monitored if there
+// is a Kea app running on the machine with the peer's address or having
+// the access point bound to this address. The peer's port is not compared
+// because the peers may communicate over the dedicated HTTP listeners.
+// This server and the peers using the loopback addresses are skipped
+// because they run on the subject daemon's machine.
+func findUnknownHAPeers(db dbops.DBI, relationships []keaconfig.HA) ([]keaconfig.Peer, error) {
+	var unknownPeers []keaconfig.Peer
+	for _, relationship := range relationships {
+		for _, peer := range relationship.Peers {
+			if !peer.IsValid() || (relationship.ThisServerName != nil && *peer.Name == *relationship.ThisServerName) {
+				continue
+			}
+			urlObj, err := url.Parse(*peer.URL)
+			if err != nil || urlObj.Hostname() == "" {
+				// It should never happen. Kea disallows invalid URLs.
+				continue
+			}
+			address := urlObj.Hostname()
+			if ip := net.ParseIP(address); address == "localhost" || (ip != nil && ip.IsLoopback()) {
+				continue
+			}
+			apps, err := dbmodel.GetKeaAppsByAddress(db, address)
+			if err != nil {
+				return nil, err
+			}
+			if len(apps) == 0 {
+				unknownPeers = append(unknownPeers, peer)
+			}
+		}
+	}
+	return unknownPeers, nil
+}
+
+// The checker verifies that all peers of the subject daemon in the High
+// Availability relationships are monitored by Stork. The HA status of the
+// unmonitored peers cannot be verified, so the HA coverage is incomplete.
+func highAvailabilityUnknownPeers(ctx *ReviewContext) (*Report, error) {
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv4 &&
+		ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv6 {
+		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
+	}
+
+	config := ctx.subjectDaemon.KeaDaemon.Config
+
+	_, haConfig, ok := config.GetHookLibraries().GetHAHookLibrary()
+	if !ok {
+		// There is no HA configured.
+		return nil, nil
+	}
+
+	unknownPeers, err := findUnknownHAPeers(ctx.db, haConfig.HA)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(unknownPeers) == 0 {
+		return nil, nil
+	}
+
+	var issues []string
+	for i, peer := range unknownPeers {
+		issues = append(issues, fmt.Sprintf("%d. '%s' (%s)", i+1, *peer.Name, *peer.URL))
+	}
+
+	return NewReport(ctx, fmt.Sprintf("The {daemon} participates in the "+
+		"High Availability setup but Stork does not monitor %s. Stork "+
+		"cannot verify the state of such peers and report their failures. "+
+		"Install the Stork agent on the peers' machines and register them "+
+		"in the Stork server.\n%s",
+		storkutil.FormatNoun(int64(len(unknownPeers)), "of its peer", "s"),
+		strings.Join(issues, "; "))).referencingDaemon(ctx.subjectDaemon).create()
+}
+
+// Returns the High Availability peers of the subject daemon having the
+// same name and a known configuration. The daemon may belong to several HA
+// relationships with the same peer but the peer is returned once.
+func getHAPeers(ctx *ReviewContext) ([]*dbmodel.Daemon, error) {
+	services, err := dbmodel.GetDetailedServicesByAppID(ctx.db, ctx.subjectDaemon.AppID)
+	if err != nil {
+		return nil, err
+	}
+
+	var peers []*dbmodel.Daemon
+	visited := map[int64]bool{ctx.subjectDaemon.ID: true}
+	for _, service := range services {
+		if service.HAService == nil {
+			continue
+		}
+		inService := false
+		for _, daemon := range service.Daemons {
+			if daemon.ID == ctx.subjectDaemon.ID {
+				inService = true
+				break
+			}
+		}
+		if !inService {
+			continue
+		}
+		for _, daemon := range service.Daemons {
+			if visited[daemon.ID] || daemon.Name != ctx.subjectDaemon.Name ||
+				daemon.KeaDaemon == nil || daemon.KeaDaemon.Config == nil {
+				continue
+			}
+			visited[daemon.ID] = true
+			peers = append(peers, daemon)
+		}
+	}
+	return peers, nil
+}
+
+// Returns the effective DHCPv4 client identification parameters of the
+// subnets by subnet prefix. Kea enables the match-client-id and the
+// echo-client-id when they are not specified, so the returned parameters
+// are never nil.
+func getClientIdentificationBySubnet(config *dbmodel.KeaConfig) map[string]keaconfig.ClientIdentificationParameters {
+	defaults := keaconfig.ClientIdentificationParameters{
+		MatchClientID: storkutil.Ptr(true),
+		EchoClientID:  storkutil.Ptr(true),
+	}
+	global := config.GetClientIdentificationParameters()
+	parameters := make(map[string]keaconfig.ClientIdentificationParameters)
+	for _, sharedNetwork := range config.GetSharedNetworks(true) {
+		sharedNetworkParameters := sharedNetwork.GetSharedNetworkParameters().GetClientIdentificationParameters()
+		for _, subnet := range sharedNetwork.GetSubnets() {
+			parameters[subnet.GetPrefix()] = keaconfig.ResolveClientIdentificationParameters(
+				subnet.GetSubnetParameters().GetClientIdentificationParameters(),
+				sharedNetworkParameters,
+				global,
+				defaults,
+			)
+		}
+	}
+	return parameters
+}
+
+// Compares the effective DHCPv4 client identification parameters of the
+// subnets configured in the subject daemon and its HA peer. It returns the
+// descriptions of the differences. The subnets not configured in the peer
+// are skipped. The number of the described subnets is limited to maxIssues.
+func findClientIdentificationMismatches(config, peerConfig *dbmodel.KeaConfig, maxIssues int) (issues []string) {
+	echoClientID := config.GetEchoClientID() == nil || *config.GetEchoClientID()
+	peerEchoClientID := peerConfig.GetEchoClientID() == nil || *peerConfig.GetEchoClientID()
+	if echoClientID != peerEchoClientID {
+		issues = append(issues, fmt.Sprintf("echo-client-id is %t but the peer's is %t",
+			echoClientID, peerEchoClientID))
+	}
+
+	subnetParameters := getClientIdentificationBySubnet(config)
+	peerParameters := getClientIdentificationBySubnet(peerConfig)
+	var mismatchedSubnets []string
+	for _, sharedNetwork := range config.GetSharedNetworks(true) {
+		for _, subnet := range sharedNetwork.GetSubnets() {
+			peerSubnetParameters, ok := peerParameters[subnet.GetPrefix()]
+			if !ok {
+				continue
+			}
+			matchClientID := *subnetParameters[subnet.GetPrefix()].MatchClientID
+			peerMatchClientID := *peerSubnetParameters.MatchClientID
+			if matchClientID == peerMatchClientID {
+				continue
+			}
+			mismatchedSubnets = append(mismatchedSubnets, fmt.Sprintf("[%d] %s (%t vs %t)",
+				subnet.GetID(), subnet.GetPrefix(), matchClientID, peerMatchClientID))
+		}
+	}
+	if len(mismatchedSubnets) > maxIssues {
+		mismatchedSubnets = append(mismatchedSubnets[:maxIssues], "...")
+	}
+	if len(mismatchedSubnets) > 0 {
+		issues = append(issues, fmt.Sprintf("match-client-id differs in %s",
+			strings.Join(mismatchedSubnets, ", ")))
+	}
+	return issues
+}
+
+// The checker verifies that the DHCPv4 daemon and its High Availability
+// peers have the same match-client-id and echo-client-id settings. The
+// peers identifying the clients differently allocate different leases to
+// the same clients after the failover, causing the lease churn.
+func highAvailabilityClientIdentificationMismatch(ctx *ReviewContext) (*Report, error) {
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv4 &&
+		ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv6 {
+		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
+	}
+
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv4 {
+		// The client identification parameters are DHCPv4-specific.
+		return nil, nil
+	}
+
+	config := ctx.subjectDaemon.KeaDaemon.Config
+
+	if _, _, ok := config.GetHookLibraries().GetHAHookLibrary(); !ok {
+		// There is no HA configured.
+		return nil, nil
+	}
+
+	peers, err := getHAPeers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	maxIssues := 10
+	var issues []string
+	var mismatchedPeers []*dbmodel.Daemon
+	for _, peer := range peers {
+		peerIssues := findClientIdentificationMismatches(config, peer.KeaDaemon.Config, maxIssues)
+		if len(peerIssues) == 0 {
+			continue
+		}
+		peerLabel := fmt.Sprintf("daemon with ID %d", peer.ID)
+		if peer.App != nil {
+			peerLabel = fmt.Sprintf("%s of the app %s", peerLabel, peer.App.Name)
+		}
+		issues = append(issues, fmt.Sprintf("%d. %s: %s", len(issues)+1, peerLabel,
+			strings.Join(peerIssues, "; ")))
+		mismatchedPeers = append(mismatchedPeers, peer)
+	}
+
+	if len(issues) == 0 {
+		return nil, nil
+	}
+
+	report := NewReport(ctx, fmt.Sprintf("The {daemon} participates in the "+
+		"High Availability setup but its client identification settings "+
+		"differ from the settings of %s. The servers using different "+
+		"match-client-id or echo-client-id settings identify the same "+
+		"clients differently, which causes the lease churn after the "+
+		"failover. Use the same settings on all HA peers.\n%s",
+		storkutil.FormatNoun(int64(len(issues)), "peer", "s"),
+		strings.Join(issues, "\n"))).referencingDaemon(ctx.subjectDaemon)
+	for _, peer := range mismatchedPeers {
+		report = report.referencingDaemon(peer)
+	}
+	return report.create()
+}
+
+// Returns the effective DHCPv6 rapid commit flags of the subnets by subnet
+// prefix. Kea disables the rapid commit when it is not specified.
+func getRapidCommitBySubnet(config *dbmodel.KeaConfig) map[string]bool {
+	global := config.GetRapidCommit()
+	rapidCommit := make(map[string]bool)
+	for _, sharedNetwork := range config.GetSharedNetworks(true) {
+		sharedNetworkRapidCommit := sharedNetwork.GetSharedNetworkParameters().RapidCommit
+		for _, subnet := range sharedNetwork.GetSubnets() {
+			resolved := keaconfig.ResolveRapidCommit(
+				subnet.GetSubnetParameters().RapidCommit,
+				sharedNetworkRapidCommit,
+				global,
+			)
+			rapidCommit[subnet.GetPrefix()] = resolved != nil && *resolved
+		}
+	}
+	return rapidCommit
+}
+
+// Compares the effective DHCPv6 rapid commit flags of the subnets configured
+// in the subject daemon and its HA peer. It returns the descriptions of the
+// mismatched subnets. The subnets not configured in the peer are skipped.
+// The number of the described subnets is limited to maxIssues.
+func findRapidCommitMismatches(config, peerConfig *dbmodel.KeaConfig, maxIssues int) (mismatchedSubnets []string) {
+	rapidCommit := getRapidCommitBySubnet(config)
+	peerRapidCommit := getRapidCommitBySubnet(peerConfig)
+	for _, sharedNetwork := range config.GetSharedNetworks(true) {
+		for _, subnet := range sharedNetwork.GetSubnets() {
+			peerSubnetRapidCommit, ok := peerRapidCommit[subnet.GetPrefix()]
+			if !ok || rapidCommit[subnet.GetPrefix()] == peerSubnetRapidCommit {
+				continue
+			}
+			mismatchedSubnets = append(mismatchedSubnets, fmt.Sprintf("[%d] %s (%t vs %t)",
+				subnet.GetID(), subnet.GetPrefix(), rapidCommit[subnet.GetPrefix()], peerSubnetRapidCommit))
+		}
+	}
+	if len(mismatchedSubnets) > maxIssues {
+		mismatchedSubnets = append(mismatchedSubnets[:maxIssues], "...")
+	}
+	return mismatchedSubnets
+}
+
+// The checker verifies that the DHCPv6 daemon and its High Availability
+// peers have the same rapid-commit settings for the same subnets. The
+// clients using the rapid commit get the leases in two messages from one
+// peer, while the other peer expects four messages. It confuses the
+// clients after the failover or in the load balancing.
+func highAvailabilityRapidCommitMismatch(ctx *ReviewContext) (*Report, error) {
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv4 &&
+		ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv6 {
+		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
+	}
+
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv6 {
+		// The rapid commit is DHCPv6-specific.
+		return nil, nil
+	}
+
+	config := ctx.subjectDaemon.KeaDaemon.Config
+
+	if _, _, ok := config.GetHookLibraries().GetHAHookLibrary(); !ok {
+		// There is no HA configured.
+		return nil, nil
+	}
+
+	peers, err := getHAPeers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	maxIssues := 10
+	var issues []string
+	var mismatchedPeers []*dbmodel.Daemon
+	for _, peer := range peers {
+		mismatchedSubnets := findRapidCommitMismatches(config, peer.KeaDaemon.Config, maxIssues)
+		if len(mismatchedSubnets) == 0 {
+			continue
+		}
+		peerLabel := fmt.Sprintf("daemon with ID %d", peer.ID)
+		if peer.App != nil {
+			peerLabel = fmt.Sprintf("%s of the app %s", peerLabel, peer.App.Name)
+		}
+		issues = append(issues, fmt.Sprintf("%d. %s: rapid-commit differs in %s", len(issues)+1,
+			peerLabel, strings.Join(mismatchedSubnets, ", ")))
+		mismatchedPeers = append(mismatchedPeers, peer)
+	}
+
+	if len(issues) == 0 {
+		return nil, nil
+	}
+
+	report := NewReport(ctx, fmt.Sprintf("The {daemon} participates in the "+
+		"High Availability setup but its rapid-commit settings differ "+
+		"from the settings of %s. The clients requesting the rapid commit "+
+		"get different responses from the peers, which confuses them "+
+		"after the failover. Use the same settings on all HA peers.\n%s",
+		storkutil.FormatNoun(int64(len(issues)), "peer", "s"),
+		strings.Join(issues, "\n"))).referencingDaemon(ctx.subjectDaemon)
+	for _, peer := range mismatchedPeers {
+		report = report.referencingDaemon(peer)
+	}
+	return report.create()
+}
+
+// The checker verifies that the access points of the app the subject Kea
+// Control Agent belongs to are not shared with other apps. Two apps with
+// the same access point address and port usually indicate a registration
+// mistake, and Stork polls the same Kea server twice.
+func duplicateAccessPoints(ctx *ReviewContext) (*Report, error) {
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameCA {
+		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
+	}
+
+	duplicates, err := dbmodel.GetDuplicateAccessPoints(ctx.db, ctx.subjectDaemon.AppID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(duplicates) == 0 {
+		return nil, nil
+	}
+
+	var issues []string
+	for i, accessPoint := range duplicates {
+		issues = append(issues, fmt.Sprintf("%d. %s access point %s:%d of the app with ID %d",
+			i+1, accessPoint.Type, accessPoint.Address, accessPoint.Port, accessPoint.AppID))
+	}
+
+	return NewReport(ctx, fmt.Sprintf("The {daemon} shares the address and "+
+		"port with %s of other apps. It usually indicates that the same Kea "+
+		"server was registered in Stork more than once, and it is polled "+
+		"multiple times. Remove the duplicated apps or correct their "+
+		"access points.\n%s",
+		storkutil.FormatNoun(int64(len(duplicates)), "access point", "s"),
+		strings.Join(issues, "; "))).referencingDaemon(ctx.subjectDaemon).create()
+}
+
+// Kea configuration parameter deprecated in the newer Kea versions and the
+// guidance on how to replace it.
+type deprecatedKeaParameter struct {
+	name        string
+	replacement string
+}
+
+// Returns the table of the deprecated Kea configuration parameters that
+// can be specified at the global, shared network and subnet levels.
+func getDeprecatedKeaParameters() []deprecatedKeaParameter {
+	return []deprecatedKeaParameter{
+		{
+			name:        "reservation-mode",
+			replacement: "use the 'reservations-global', 'reservations-in-subnet' and 'reservations-out-of-pool' boolean flags instead",
+		},
+		{
+			name:        "ddns-use-conflict-resolution",
+			replacement: "use the 'ddns-conflict-resolution-mode' parameter instead",
+		},
+		{
+			name:        "client-class",
+			replacement: "use the 'client-classes' list instead",
+		},
+		{
+			name:        "require-client-classes",
+			replacement: "use the 'evaluate-additional-classes' list instead",
+		},
+	}
+}
+
+// Returns the list of the items of the specified list parameter in the
+// raw configuration. The items that are not maps are skipped.
+func getRawConfigListItems(raw map[string]any, name string) (items []map[string]any) {
+	list, ok := raw[name].([]any)
+	if !ok {
+		return
+	}
+	for _, item := range list {
+		if itemMap, ok := item.(map[string]any); ok {
+			items = append(items, itemMap)
+		}
+	}
+	return
+}
+
+// The checker verifying if the DHCP daemon configuration uses the
+// parameters deprecated in the newer Kea versions at the global, shared
+// network or subnet levels. Such configurations may be rejected after
+// upgrading Kea, so the report includes the guidance on how to replace
+// these parameters.
+func deprecatedParameters(ctx *ReviewContext) (*Report, error) {
+	if ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv4 &&
+		ctx.subjectDaemon.Name != dbmodel.DaemonNameDHCPv6 {
+		return nil, errors.Errorf("unsupported daemon %s", ctx.subjectDaemon.Name)
+	}
+
+	config := ctx.subjectDaemon.KeaDaemon.Config
+	rootName := "Dhcp4"
+	subnetsName := "subnet4"
+	if ctx.subjectDaemon.Name == dbmodel.DaemonNameDHCPv6 {
+		rootName = "Dhcp6"
+		subnetsName = "subnet6"
+	}
+	root, ok := config.Raw[rootName].(map[string]any)
+	if !ok {
+		return nil, nil
+	}
+
+	// Collect the scopes in which the deprecated parameters are searched.
+	type scope struct {
+		description string
+		params      map[string]any
+	}
+	scopes := []scope{{"global scope", root}}
+	appendSubnets := func(params map[string]any) {
+		for _, subnet := range getRawConfigListItems(params, subnetsName) {
+			prefix, _ := subnet["subnet"].(string)
+			scopes = append(scopes, scope{fmt.Sprintf("subnet %s", prefix), subnet})
+		}
+	}
+	appendSubnets(root)
+	for _, sharedNetwork := range getRawConfigListItems(root, "shared-networks") {
+		name, _ := sharedNetwork["name"].(string)
+		scopes = append(scopes, scope{fmt.Sprintf("shared network '%s'", name), sharedNetwork})
+		appendSubnets(sharedNetwork)
+	}
+
+	maxIssues := 10
+	var issues []string
+	count := 0
+
+	for _, s := range scopes {
+		for _, parameter := range getDeprecatedKeaParameters() {
+			if _, ok := s.params[parameter.name]; !ok {
+				continue
+			}
+			count++
+			if len(issues) < maxIssues {
+				issues = append(issues, fmt.Sprintf("%d. '%s' in the %s: %s",
+					len(issues)+1, parameter.name, s.description, parameter.replacement))
+			}
+		}
+	}
+
+	if count == 0 {
+		return nil, nil
+	}
+
+	return NewReport(ctx, fmt.Sprintf("Kea {daemon} configuration "+
+		"includes %s deprecated in the newer Kea versions. Such "+
+		"configuration may be rejected after upgrading Kea. Replace the "+
+		"deprecated parameters as follows.\n%s",
+		storkutil.FormatNoun(int64(count), "parameter", "s"),
+		strings.Join(issues, "; "))).referencingDaemon(ctx.subjectDaemon).create()
 }
@@ -441,6 +441,69 @@ func TestSharedNetworkDispensableMultipleDHCPv6Subnets(t *testing.T) {
 	require.Nil(t, report)
 }
 
+// Tests that the checker finding dispensable shared networks names the
+// shared networks without member subnets.
+func TestSharedNetworkDispensableEmptyNames(t *testing.T) {
+	configStr := `{
+        "Dhcp4": {
+            "shared-networks": [
+                {
+                    "name": "foo"
+                },
+                {
+                    "name": "bar",
+                    "subnet4": [
+                        {
+                            "subnet": "192.0.2.0/24"
+                        },
+                        {
+                            "subnet": "192.0.3.0/24"
+                        }
+                    ]
+                },
+                {
+                    "name": "baz",
+                    "subnet4": [ ]
+                }
+            ]
+        }
+    }`
+	ctx := createReviewContext(t, nil, configStr)
+	report, err := sharedNetworkDispensable(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Len(t, report.refDaemonIDs, 1)
+	require.Contains(t, report.refDaemonIDs, ctx.subjectDaemon.ID)
+	require.NotNil(t, report.content)
+	require.Contains(t, *report.content, "configuration includes 2 empty shared networks.")
+	require.Contains(t, *report.content, "The shared networks without member subnets: 'foo', 'baz'.")
+	require.NotContains(t, *report.content, "'bar'")
+}
+
+// Tests that the checker finding dispensable shared networks doesn't name
+// any shared networks when none of them is empty.
+func TestSharedNetworkDispensableNoEmptyNames(t *testing.T) {
+	configStr := `{
+        "Dhcp6": {
+            "shared-networks": [
+                {
+                    "name": "foo",
+                    "subnet6": [
+                        {
+                            "subnet": "2001:db8:1::/64"
+                        }
+                    ]
+                }
+            ]
+        }
+    }`
+	report, err := sharedNetworkDispensable(createReviewContext(t, nil, configStr))
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.NotNil(t, report.content)
+	require.NotContains(t, *report.content, "without member subnets")
+}
+
 // Tests that the checker finding dispensable subnets finds the subnets
 // that comprise no pools and no reservations.
 func TestIPv4SubnetDispensableNoPoolsNoReservations(t *testing.T) {
@@ -3751,6 +3814,198 @@ func TestCredentialsOverHTTPSForProvidedCredentialsWithTLS(t *testing.T) {
 	require.Nil(t, report)
 }
 
+// Returns a DHCPv4 server configuration with the High Availability hook.
+// The peers' URLs use the specified scheme. The extra parameters are
+// appended to the HA relationship configuration.
+func getHAConfig(scheme string, extraParams string) string {
+	return fmt.Sprintf(`{ "Dhcp4": {
+        "hooks-libraries": [
+            {
+                "library": "/libdhcp_ha.so",
+                "parameters": {
+                    "high-availability": [{
+                        %s
+                        "this-server-name": "foo",
+                        "peers": [
+                            {
+                                "role": "primary",
+                                "name": "foo",
+                                "url": "%s://10.0.0.1:8000"
+                            },
+                            {
+                                "role": "standby",
+                                "name": "bar",
+                                "url": "%s://10.0.0.2:8000"
+                            }
+                        ]
+                    }]
+                }
+            }
+        ]
+    } }`, extraParams, scheme, scheme)
+}
+
+// Returns the HA relationship parameters enabling the dedicated HTTP
+// listener.
+func getHADedicatedListenerParams() string {
+	return `"multi-threading": {
+        "enable-multi-threading": true,
+        "http-dedicated-listener": true
+    },`
+}
+
+// Returns the HA relationship TLS parameters.
+func getHATLSParams() string {
+	return `"trust-anchor": "/ca.pem",
+    "cert-file": "/cert.pem",
+    "key-file": "/key.pem",`
+}
+
+// Appends the control access point to the app of the subject daemon.
+func appendHAControlAccessPoint(ctx *ReviewContext, secure bool) {
+	ctx.subjectDaemon.App.AccessPoints = append(ctx.subjectDaemon.App.AccessPoints, &dbmodel.AccessPoint{
+		Address:           "10.0.0.1",
+		Port:              8000,
+		Type:              dbmodel.AccessPointControl,
+		UseSecureProtocol: secure,
+	})
+}
+
+// Test that the HA insecure access point checker returns an error for the
+// not-DHCP daemons.
+func TestHighAvailabilityInsecureAccessPointForNonDHCPDaemon(t *testing.T) {
+	// Arrange
+	ctx := createReviewContext(t, nil, `{ "Control-agent": { } }`)
+
+	// Act
+	report, err := highAvailabilityInsecureAccessPoint(ctx)
+
+	// Assert
+	require.Nil(t, report)
+	require.ErrorContains(t, err, "unsupported daemon")
+}
+
+// Test that the HA insecure access point checker returns no report if the
+// HA is not configured.
+func TestHighAvailabilityInsecureAccessPointNoHA(t *testing.T) {
+	// Arrange
+	ctx := createReviewContext(t, nil, `{ "Dhcp4": { } }`)
+	appendHAControlAccessPoint(ctx, false)
+
+	// Act
+	report, err := highAvailabilityInsecureAccessPoint(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the HA insecure access point checker returns no report if the
+// app has no control access point and the peers use HTTPS.
+func TestHighAvailabilityInsecureAccessPointMissingAccessPoint(t *testing.T) {
+	// Arrange
+	ctx := createReviewContext(t, nil, getHAConfig("https", ""))
+
+	// Act
+	report, err := highAvailabilityInsecureAccessPoint(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the HA insecure access point checker reports an issue if the
+// daemon participates in the HA setup and the control access point and
+// the peers use the insecure protocol.
+func TestHighAvailabilityInsecureAccessPointInsecure(t *testing.T) {
+	// Arrange
+	ctx := createReviewContext(t, nil, getHAConfig("http", ""))
+	appendHAControlAccessPoint(ctx, false)
+
+	// Act
+	report, err := highAvailabilityInsecureAccessPoint(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Equal(t, ctx.subjectDaemon.ID, report.daemonID)
+	require.Len(t, report.refDaemonIDs, 1)
+	require.Contains(t, report.refDaemonIDs, ctx.subjectDaemon.ID)
+	require.NotNil(t, report.content)
+	require.Contains(t, *report.content, "http://10.0.0.1:8000, http://10.0.0.2:8000")
+	require.Contains(t, *report.content, "over the insecure protocol on http://10.0.0.1:8000/")
+	require.Contains(t, *report.content, "in the Kea Control Agent configuration")
+}
+
+// Test that the HA insecure access point checker reports an issue if the
+// control access point is secure but the peers' URLs use HTTP.
+func TestHighAvailabilityInsecureAccessPointInsecurePeerURLs(t *testing.T) {
+	// Arrange
+	ctx := createReviewContext(t, nil, getHAConfig("http", ""))
+	appendHAControlAccessPoint(ctx, true)
+
+	// Act
+	report, err := highAvailabilityInsecureAccessPoint(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "the peers' URLs use the insecure protocol")
+	require.NotContains(t, *report.content, "accepts connections over the insecure protocol")
+}
+
+// Test that the HA insecure access point checker reports no issue if the
+// daemon participates in the HA setup and the control access point and
+// the peers use the secure protocol.
+func TestHighAvailabilityInsecureAccessPointSecure(t *testing.T) {
+	// Arrange
+	ctx := createReviewContext(t, nil, getHAConfig("https", ""))
+	appendHAControlAccessPoint(ctx, true)
+
+	// Act
+	report, err := highAvailabilityInsecureAccessPoint(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the HA insecure access point checker doesn't consider the
+// control access point when the peers communicate over the dedicated
+// HTTP listener with the TLS configured.
+func TestHighAvailabilityInsecureAccessPointDedicatedListenerSecure(t *testing.T) {
+	// Arrange
+	ctx := createReviewContext(t, nil, getHAConfig("https",
+		getHADedicatedListenerParams()+getHATLSParams()))
+	appendHAControlAccessPoint(ctx, false)
+
+	// Act
+	report, err := highAvailabilityInsecureAccessPoint(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the HA insecure access point checker reports an issue if the
+// peers communicate over the dedicated HTTP listener without the TLS even
+// though the control access point is secure.
+func TestHighAvailabilityInsecureAccessPointDedicatedListenerNoTLS(t *testing.T) {
+	// Arrange
+	ctx := createReviewContext(t, nil, getHAConfig("https", getHADedicatedListenerParams()))
+	appendHAControlAccessPoint(ctx, true)
+
+	// Act
+	report, err := highAvailabilityInsecureAccessPoint(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "the TLS is not configured for the dedicated HTTP listener")
+	require.Contains(t, *report.content, "in the High Availability hook configuration")
+	require.NotContains(t, *report.content, "Kea Control Agent")
+}
+
 // Benchmark measuring performance of a Kea configuration checker that detects
 // subnets in which the out-of-pool host reservation mode is recommended.
 func BenchmarkReservationsOutOfPoolConfig(b *testing.B) {
@@ -3982,3 +4237,2428 @@ func BenchmarkOverlapsBinaryPrefixesOnly(b *testing.B) {
 		_ = findOverlaps(subnets, maximumOverlaps)
 	}
 }
+
+// Test that the checker reports the subnets with the renew timer not lower
+// than the rebind timer and the timers exceeding the valid lifetime. The
+// timers are inherited from the shared network and global levels.
+func TestLeaseTimersConflict(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp4": {
+            "renew-timer": 1000,
+            "rebind-timer": 2000,
+            "valid-lifetime": 4000,
+            "subnet4": [
+                {
+                    "id": 1,
+                    "subnet": "192.0.2.0/24"
+                },
+                {
+                    "id": 2,
+                    "subnet": "192.0.3.0/24",
+                    "renew-timer": 2000
+                },
+                {
+                    "id": 3,
+                    "subnet": "192.0.4.0/24",
+                    "valid-lifetime": 1500
+                }
+            ],
+            "shared-networks": [
+                {
+                    "name": "foo",
+                    "rebind-timer": 5000,
+                    "subnet4": [
+                        {
+                            "id": 4,
+                            "subnet": "10.0.0.0/8"
+                        },
+                        {
+                            "id": 5,
+                            "subnet": "10.1.0.0/16",
+                            "valid-lifetime": 6000
+                        }
+                    ]
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := leaseTimersConflict(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.EqualValues(t, 42, report.daemonID)
+	require.NotNil(t, report.content)
+	require.Contains(t, *report.content, "Kea {daemon} configuration includes 3 subnets with conflicting lease timers.")
+	require.Contains(t, *report.content, "[2] 192.0.3.0/24: renew-timer (2000) is not lower than rebind-timer (2000)")
+	require.Contains(t, *report.content, "[3] 192.0.4.0/24: rebind-timer (2000) exceeds valid-lifetime (1500)")
+	require.Contains(t, *report.content, "[4] 10.0.0.0/8: rebind-timer (5000) exceeds valid-lifetime (4000)")
+	require.NotContains(t, *report.content, "192.0.2.0/24")
+	require.NotContains(t, *report.content, "10.1.0.0/16")
+}
+
+// Test that the checker reports no issues when the timers are configured
+// correctly or are not specified.
+func TestLeaseTimersConflictValidTimers(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv6, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp6": {
+            "valid-lifetime": 4000,
+            "subnet6": [
+                {
+                    "id": 1,
+                    "subnet": "2001:db8:1::/64"
+                },
+                {
+                    "id": 2,
+                    "subnet": "2001:db8:2::/64",
+                    "renew-timer": 1000,
+                    "rebind-timer": 2000
+                },
+                {
+                    "id": 3,
+                    "subnet": "2001:db8:3::/64",
+                    "renew-timer": 4000
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := leaseTimersConflict(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the checker returns an error for the non-DHCP daemon.
+func TestLeaseTimersConflictUnsupportedDaemon(t *testing.T) {
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameCA, true)
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	report, err := leaseTimersConflict(ctx)
+
+	require.Error(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the conflicts between the timers and the valid lifetime are
+// described.
+func TestGetLeaseTimersConflict(t *testing.T) {
+	require.Empty(t, getLeaseTimersConflict(keaconfig.TimerParameters{}, keaconfig.ValidLifetimeParameters{}))
+
+	require.Equal(t, "renew-timer (3000) is not lower than rebind-timer (2000), "+
+		"renew-timer (3000) exceeds valid-lifetime (2500)",
+		getLeaseTimersConflict(keaconfig.TimerParameters{
+			RenewTimer:  storkutil.Ptr[int64](3000),
+			RebindTimer: storkutil.Ptr[int64](2000),
+		}, keaconfig.ValidLifetimeParameters{
+			ValidLifetime: storkutil.Ptr[int64](2500),
+		}))
+
+	require.Empty(t, getLeaseTimersConflict(keaconfig.TimerParameters{
+		RenewTimer:  storkutil.Ptr[int64](1000),
+		RebindTimer: storkutil.Ptr[int64](2000),
+	}, keaconfig.ValidLifetimeParameters{
+		ValidLifetime: storkutil.Ptr[int64](2000),
+	}))
+}
+
+// Test that the checker reports the client classes that are defined but
+// never used.
+func TestClientClassesUnused(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp4": {
+            "client-classes": [
+                { "name": "referenced" },
+                { "name": "orphan" },
+                { "name": "leftover" }
+            ],
+            "subnet4": [
+                {
+                    "id": 1,
+                    "subnet": "192.0.2.0/24",
+                    "client-class": "referenced"
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := clientClassesUnused(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.EqualValues(t, 42, report.daemonID)
+	require.NotNil(t, report.content)
+	require.Contains(t, *report.content, "Kea {daemon} configuration includes 2 unused client classes")
+	require.Contains(t, *report.content, "'orphan', 'leftover'")
+	require.NotContains(t, *report.content, "'referenced'")
+}
+
+// Test that the checker reports no issues when all client classes are
+// used.
+func TestClientClassesUnusedAllReferenced(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp4": {
+            "client-classes": [
+                { "name": "referenced" },
+                { "name": "member" },
+                { "name": "parent", "test": "member('member')" }
+            ],
+            "subnet4": [
+                {
+                    "id": 1,
+                    "subnet": "192.0.2.0/24",
+                    "pools": [
+                        {
+                            "pool": "192.0.2.10-192.0.2.20",
+                            "client-class": "referenced",
+                            "require-client-classes": [ "parent" ]
+                        }
+                    ]
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := clientClassesUnused(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the unused client classes checker returns an error for the
+// non-DHCP daemon.
+func TestClientClassesUnusedUnsupportedDaemon(t *testing.T) {
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameCA, true)
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	report, err := clientClassesUnused(ctx)
+
+	require.Error(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the checker reports the IPv4 reservations with the addresses
+// outside the subnet prefix.
+func TestReservationsOutOfSubnet4(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp4": {
+            "subnet4": [
+                {
+                    "id": 20,
+                    "subnet": "192.0.3.0/24",
+                    "reservations": [
+                        {
+                            "hw-address": "00:00:00:00:00:21",
+                            "ip-address": "192.0.3.2"
+                        },
+                        {
+                            "hw-address": "00:00:00:00:00:22",
+                            "ip-address": "192.0.2.22"
+                        },
+                        {
+                            "hw-address": "00:00:00:00:00:23",
+                            "hostname": "no-address.example.org"
+                        }
+                    ]
+                }
+            ],
+            "shared-networks": [
+                {
+                    "name": "foo",
+                    "subnet4": [
+                        {
+                            "id": 30,
+                            "subnet": "10.0.0.0/8",
+                            "reservations": [
+                                {
+                                    "hw-address": "00:00:00:00:00:24",
+                                    "ip-address": "11.0.0.1"
+                                }
+                            ]
+                        }
+                    ]
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := reservationsOutOfSubnet(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.EqualValues(t, 42, report.daemonID)
+	require.NotNil(t, report.content)
+	require.Contains(t, *report.content, "Kea {daemon} configuration includes 2 reserved addresses outside the prefixes")
+	require.Contains(t, *report.content, "1. 192.0.2.22 is reserved in [20] 192.0.3.0/24")
+	require.Contains(t, *report.content, "2. 11.0.0.1 is reserved in [30] 10.0.0.0/8")
+	require.NotContains(t, *report.content, "192.0.3.2 ")
+}
+
+// Test that the checker reports the IPv6 reservations with the addresses
+// outside the subnet prefix and ignores the delegated prefixes.
+func TestReservationsOutOfSubnet6(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv6, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp6": {
+            "subnet6": [
+                {
+                    "id": 1,
+                    "subnet": "2001:db8:1::/64",
+                    "reservations": [
+                        {
+                            "duid": "01:02:03:04",
+                            "ip-addresses": [ "2001:db8:1::10", "2001:db8:2::10" ],
+                            "prefixes": [ "3000:1::/96" ]
+                        }
+                    ]
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := reservationsOutOfSubnet(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "includes 1 reserved address outside the prefixes")
+	require.Contains(t, *report.content, "1. 2001:db8:2::10 is reserved in [1] 2001:db8:1::/64")
+	require.NotContains(t, *report.content, "3000:1::")
+}
+
+// Test that the checker reports no issues when all reserved addresses
+// belong to their subnets.
+func TestReservationsOutOfSubnetInRange(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp4": {
+            "subnet4": [
+                {
+                    "id": 1,
+                    "subnet": "192.0.2.0/24",
+                    "reservations": [
+                        {
+                            "hw-address": "00:00:00:00:00:21",
+                            "ip-address": "192.0.2.1"
+                        },
+                        {
+                            "hw-address": "00:00:00:00:00:22",
+                            "ip-address": "192.0.2.255"
+                        }
+                    ]
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := reservationsOutOfSubnet(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the out-of-subnet reservations checker returns an error for
+// the non-DHCP daemon.
+func TestReservationsOutOfSubnetUnsupportedDaemon(t *testing.T) {
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameCA, true)
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	report, err := reservationsOutOfSubnet(ctx)
+
+	require.Error(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the checker reports the subnets lacking explicit IDs.
+func TestSubnetsWithoutID(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp4": {
+            "subnet4": [
+                {
+                    "id": 1,
+                    "subnet": "192.0.2.0/24"
+                },
+                {
+                    "subnet": "192.0.3.0/24"
+                }
+            ],
+            "shared-networks": [
+                {
+                    "name": "foo",
+                    "subnet4": [
+                        {
+                            "subnet": "10.0.0.0/8"
+                        }
+                    ]
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := subnetsWithoutID(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.EqualValues(t, 42, report.daemonID)
+	require.NotNil(t, report.content)
+	require.Contains(t, *report.content, "Kea {daemon} configuration includes 2 subnets without explicit IDs")
+	require.Contains(t, *report.content, "1. 192.0.3.0/24; 2. 10.0.0.0/8")
+	require.NotContains(t, *report.content, "192.0.2.0/24")
+}
+
+// Test that the checker lists at most ten subnets lacking explicit IDs
+// but reports the total number of such subnets.
+func TestSubnetsWithoutIDMaxIssues(t *testing.T) {
+	// Arrange
+	var subnets []string
+	for i := 0; i < 12; i++ {
+		subnets = append(subnets, fmt.Sprintf(`{ "subnet": "2001:db8:%x::/64" }`, i+1))
+	}
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv6, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(fmt.Sprintf(`{
+        "Dhcp6": {
+            "subnet6": [ %s ]
+        }
+    }`, strings.Join(subnets, ",")))
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := subnetsWithoutID(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "includes 12 subnets without explicit IDs")
+	require.Contains(t, *report.content, "10. 2001:db8:a::/64")
+	require.NotContains(t, *report.content, "11.")
+}
+
+// Test that the checker doesn't report the subnets with explicit IDs.
+func TestSubnetsWithoutIDAllSpecified(t *testing.T) {
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv6, true)
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp6": {
+            "subnet6": [
+                {
+                    "id": 1,
+                    "subnet": "2001:db8:1::/64"
+                },
+                {
+                    "id": 2,
+                    "subnet": "2001:db8:2::/64"
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	report, err := subnetsWithoutID(ctx)
+
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the subnet ID checker returns an error for the non-DHCP daemon.
+func TestSubnetsWithoutIDUnsupportedDaemon(t *testing.T) {
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameCA, true)
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	report, err := subnetsWithoutID(ctx)
+
+	require.Error(t, err)
+	require.Nil(t, report)
+}
+
+// Returns the DHCPv4 configuration with the HA hook library including the
+// specified peers of this server.
+func getHAUnknownPeersTestConfig(peers ...string) string {
+	return fmt.Sprintf(`{ "Dhcp4": {
+        "hooks-libraries": [
+            {
+                "library": "/libdhcp_ha.so",
+                "parameters": {
+                    "high-availability": [{
+                        "this-server-name": "server1",
+                        "mode": "hot-standby",
+                        "peers": [
+                            {
+                                "role": "primary",
+                                "name": "server1",
+                                "url": "http://10.0.0.1:8001"
+                            },
+                            %s
+                        ]
+                    }]
+                }
+            }
+        ]
+    } }`, strings.Join(peers, ","))
+}
+
+// Test that the checker returns no report when HA is not configured.
+func TestHighAvailabilityUnknownPeersNoHA(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	ctx := createReviewContext(t, db, `{ "Dhcp4": { } }`)
+
+	// Act
+	report, err := highAvailabilityUnknownPeers(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the checker returns no report when all peers are monitored by
+// Stork, including the peer communicating over the dedicated listener.
+func TestHighAvailabilityUnknownPeersRegistered(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	machine := &dbmodel.Machine{
+		Address:   "10.0.0.2",
+		AgentPort: 8080,
+	}
+	err := dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+
+	app := &dbmodel.App{
+		MachineID: machine.ID,
+		Type:      dbmodel.AppTypeKea,
+		AccessPoints: []*dbmodel.AccessPoint{
+			{
+				Type:    dbmodel.AccessPointControl,
+				Address: "10.0.0.2",
+				Port:    8000,
+			},
+		},
+		Daemons: []*dbmodel.Daemon{{Name: dbmodel.DaemonNameCA}},
+	}
+	_, err = dbmodel.AddApp(db, app)
+	require.NoError(t, err)
+
+	ctx := createReviewContext(t, db, getHAUnknownPeersTestConfig(`{
+        "role": "standby",
+        "name": "server2",
+        "url": "http://10.0.0.2:8001"
+    }`, `{
+        "role": "backup",
+        "name": "server3",
+        "url": "http://127.0.0.1:8002"
+    }`))
+
+	// Act
+	report, err := highAvailabilityUnknownPeers(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the checker reports the peers not monitored by Stork.
+func TestHighAvailabilityUnknownPeersNotRegistered(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	machine := &dbmodel.Machine{
+		Address:   "10.0.0.2",
+		AgentPort: 8080,
+	}
+	err := dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+
+	app := &dbmodel.App{
+		MachineID: machine.ID,
+		Type:      dbmodel.AppTypeKea,
+		AccessPoints: []*dbmodel.AccessPoint{
+			{
+				Type:    dbmodel.AccessPointControl,
+				Address: "10.0.0.2",
+				Port:    8000,
+			},
+		},
+		Daemons: []*dbmodel.Daemon{{Name: dbmodel.DaemonNameCA}},
+	}
+	_, err = dbmodel.AddApp(db, app)
+	require.NoError(t, err)
+
+	ctx := createReviewContext(t, db, getHAUnknownPeersTestConfig(`{
+        "role": "standby",
+        "name": "server2",
+        "url": "http://10.0.0.2:8000"
+    }`, `{
+        "role": "backup",
+        "name": "server3",
+        "url": "http://10.0.0.3:8000"
+    }`))
+
+	// Act
+	report, err := highAvailabilityUnknownPeers(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "Stork does not monitor 1 of its peer.")
+	require.Contains(t, *report.content, "1. 'server3' (http://10.0.0.3:8000)")
+	require.NotContains(t, *report.content, "server2")
+	require.Len(t, report.refDaemonIDs, 1)
+}
+
+// Test that the checker reports the deprecated parameters at the global,
+// shared network and subnet levels with the replacement guidance.
+func TestDeprecatedParameters(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp4": {
+            "reservation-mode": "global",
+            "subnet4": [
+                {
+                    "id": 1,
+                    "subnet": "192.0.2.0/24",
+                    "client-class": "foo"
+                },
+                {
+                    "id": 2,
+                    "subnet": "192.0.3.0/24",
+                    "client-classes": [ "foo" ]
+                }
+            ],
+            "shared-networks": [
+                {
+                    "name": "bar",
+                    "ddns-use-conflict-resolution": false,
+                    "subnet4": [
+                        {
+                            "id": 3,
+                            "subnet": "10.0.0.0/8",
+                            "require-client-classes": [ "baz" ]
+                        }
+                    ]
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := deprecatedParameters(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.EqualValues(t, 42, report.daemonID)
+	require.NotNil(t, report.content)
+	require.Contains(t, *report.content, "Kea {daemon} configuration includes 4 parameters deprecated")
+	require.Contains(t, *report.content, "1. 'reservation-mode' in the global scope: use the 'reservations-global', 'reservations-in-subnet' and 'reservations-out-of-pool' boolean flags instead")
+	require.Contains(t, *report.content, "2. 'client-class' in the subnet 192.0.2.0/24: use the 'client-classes' list instead")
+	require.Contains(t, *report.content, "3. 'ddns-use-conflict-resolution' in the shared network 'bar': use the 'ddns-conflict-resolution-mode' parameter instead")
+	require.Contains(t, *report.content, "4. 'require-client-classes' in the subnet 10.0.0.0/8: use the 'evaluate-additional-classes' list instead")
+	require.NotContains(t, *report.content, "192.0.3.0/24")
+}
+
+// Test that the checker doesn't report the configuration without the
+// deprecated parameters.
+func TestDeprecatedParametersNone(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv6, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp6": {
+            "reservations-global": true,
+            "subnet6": [
+                {
+                    "id": 1,
+                    "subnet": "2001:db8:1::/64",
+                    "client-classes": [ "foo" ]
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := deprecatedParameters(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the checker fails for the daemons other than DHCP.
+func TestDeprecatedParametersUnsupportedDaemon(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameCA, true)
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := deprecatedParameters(ctx)
+
+	// Assert
+	require.Error(t, err)
+	require.Nil(t, report)
+}
+
+// Adds a Kea app with the Control Agent listening on the specified address
+// and port to the database.
+func addAppWithAccessPoint(t *testing.T, db *dbops.PgDB, machineAddress, address string, port int64) *dbmodel.App {
+	machine, err := dbmodel.GetMachineByAddressAndAgentPort(db, machineAddress, 8080)
+	require.NoError(t, err)
+	if machine == nil {
+		machine = &dbmodel.Machine{
+			Address:   machineAddress,
+			AgentPort: 8080,
+		}
+		err = dbmodel.AddMachine(db, machine)
+		require.NoError(t, err)
+	}
+
+	app := &dbmodel.App{
+		MachineID: machine.ID,
+		Type:      dbmodel.AppTypeKea,
+		Name:      fmt.Sprintf("kea@%s:%d", address, port),
+		AccessPoints: []*dbmodel.AccessPoint{
+			{
+				Type:    dbmodel.AccessPointControl,
+				Address: address,
+				Port:    port,
+			},
+		},
+		Daemons: []*dbmodel.Daemon{{Name: dbmodel.DaemonNameCA}},
+	}
+	_, err = dbmodel.AddApp(db, app)
+	require.NoError(t, err)
+	return app
+}
+
+// Test that the checker reports the access points shared with other apps.
+func TestDuplicateAccessPoints(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	app := addAppWithAccessPoint(t, db, "192.0.2.1", "192.0.2.1", 8000)
+	duplicate := addAppWithAccessPoint(t, db, "192.0.2.2", "192.0.2.1", 8000)
+	_ = addAppWithAccessPoint(t, db, "192.0.2.1", "192.0.2.1", 8001)
+
+	ctx := createReviewContext(t, db, `{ "Control-agent": { } }`)
+	ctx.subjectDaemon.AppID = app.ID
+
+	// Act
+	report, err := duplicateAccessPoints(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "shares the address and port with 1 access point of other apps")
+	require.Contains(t, *report.content, fmt.Sprintf("1. control access point 192.0.2.1:8000 of the app with ID %d", duplicate.ID))
+	require.Len(t, report.refDaemonIDs, 1)
+}
+
+// Test that the checker returns no report when the access points are
+// unique, including the same loopback address on different machines.
+func TestDuplicateAccessPointsUnique(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	app := addAppWithAccessPoint(t, db, "192.0.2.1", "127.0.0.1", 8000)
+	_ = addAppWithAccessPoint(t, db, "192.0.2.2", "127.0.0.1", 8000)
+	_ = addAppWithAccessPoint(t, db, "192.0.2.1", "127.0.0.1", 8001)
+
+	ctx := createReviewContext(t, db, `{ "Control-agent": { } }`)
+	ctx.subjectDaemon.AppID = app.ID
+
+	// Act
+	report, err := duplicateAccessPoints(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the same loopback address on the same machine is reported.
+func TestDuplicateAccessPointsLoopbackSameMachine(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	app := addAppWithAccessPoint(t, db, "192.0.2.1", "127.0.0.1", 8000)
+	_ = addAppWithAccessPoint(t, db, "192.0.2.1", "127.0.0.1", 8000)
+
+	ctx := createReviewContext(t, db, `{ "Control-agent": { } }`)
+	ctx.subjectDaemon.AppID = app.ID
+
+	// Act
+	report, err := duplicateAccessPoints(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+}
+
+// Test that the checker returns an error for the daemon other than the
+// Kea Control Agent.
+func TestDuplicateAccessPointsUnsupportedDaemon(t *testing.T) {
+	// Arrange
+	ctx := createReviewContext(t, nil, `{ "Dhcp4": { } }`)
+
+	// Act
+	report, err := duplicateAccessPoints(ctx)
+
+	// Assert
+	require.Error(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the checker reports the subnets with the in-pool reservations
+// when the reservations-in-subnet is disabled at any inheritance level.
+func TestReservationsInPoolIgnored4(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp4": {
+            "reservations-in-subnet": false,
+            "subnet4": [
+                {
+                    "id": 1,
+                    "subnet": "192.0.2.0/24",
+                    "pools": [ { "pool": "192.0.2.10-192.0.2.100" } ],
+                    "reservations": [
+                        {
+                            "hw-address": "00:00:00:00:00:01",
+                            "ip-address": "192.0.2.20"
+                        }
+                    ]
+                },
+                {
+                    "id": 2,
+                    "subnet": "192.0.3.0/24",
+                    "pools": [ { "pool": "192.0.3.10-192.0.3.100" } ],
+                    "reservations": [
+                        {
+                            "hw-address": "00:00:00:00:00:02",
+                            "ip-address": "192.0.3.200"
+                        }
+                    ]
+                },
+                {
+                    "id": 3,
+                    "subnet": "192.0.4.0/24",
+                    "reservations-in-subnet": true,
+                    "pools": [ { "pool": "192.0.4.10-192.0.4.100" } ],
+                    "reservations": [
+                        {
+                            "hw-address": "00:00:00:00:00:03",
+                            "ip-address": "192.0.4.20"
+                        }
+                    ]
+                }
+            ],
+            "shared-networks": [
+                {
+                    "name": "foo",
+                    "subnet4": [
+                        {
+                            "id": 4,
+                            "subnet": "10.0.0.0/8",
+                            "pools": [ { "pool": "10.0.0.10-10.0.0.100" } ],
+                            "reservations": [
+                                {
+                                    "hw-address": "00:00:00:00:00:04",
+                                    "ip-address": "10.0.0.20"
+                                }
+                            ]
+                        }
+                    ]
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := reservationsInPoolIgnored(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.EqualValues(t, 42, report.daemonID)
+	require.NotNil(t, report.content)
+	require.Contains(t, *report.content, "Kea {daemon} configuration includes 2 subnets with the in-pool host reservations")
+	require.Contains(t, *report.content, "[1] 192.0.2.0/24")
+	require.Contains(t, *report.content, "[4] 10.0.0.0/8")
+	require.NotContains(t, *report.content, "192.0.3.0/24")
+	require.NotContains(t, *report.content, "192.0.4.0/24")
+}
+
+// Test that the checker reports the IPv6 subnets with the in-pool prefix
+// reservations when the reservations-in-subnet is disabled using the
+// deprecated reservation-mode.
+func TestReservationsInPoolIgnored6(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv6, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp6": {
+            "subnet6": [
+                {
+                    "id": 1,
+                    "subnet": "2001:db8:1::/64",
+                    "reservation-mode": "global",
+                    "pd-pools": [
+                        {
+                            "prefix": "3000::",
+                            "prefix-len": 64,
+                            "delegated-len": 96
+                        }
+                    ],
+                    "reservations": [
+                        {
+                            "duid": "01:02:03:04",
+                            "prefixes": [ "3000::1:0:0/96" ]
+                        }
+                    ]
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := reservationsInPoolIgnored(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "Kea {daemon} configuration includes 1 subnet with the in-pool host reservations")
+	require.Contains(t, *report.content, "[1] 2001:db8:1::/64")
+}
+
+// Test that the checker returns no report when the reservations-in-subnet
+// is enabled by default.
+func TestReservationsInPoolIgnoredDefault(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp4": {
+            "subnet4": [
+                {
+                    "id": 1,
+                    "subnet": "192.0.2.0/24",
+                    "pools": [ { "pool": "192.0.2.10-192.0.2.100" } ],
+                    "reservations": [
+                        {
+                            "hw-address": "00:00:00:00:00:01",
+                            "ip-address": "192.0.2.20"
+                        }
+                    ]
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := reservationsInPoolIgnored(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the checker reports the global reservations ignored because
+// the reservations-global is disabled globally and for all subnets.
+func TestReservationsGlobalIgnored(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp4": {
+            "reservations-global": false,
+            "reservations": [
+                {
+                    "hw-address": "00:00:00:00:00:01",
+                    "hostname": "foo"
+                },
+                {
+                    "hw-address": "00:00:00:00:00:02",
+                    "hostname": "bar"
+                }
+            ],
+            "subnet4": [
+                {
+                    "id": 1,
+                    "subnet": "192.0.2.0/24"
+                }
+            ],
+            "shared-networks": [
+                {
+                    "name": "foo",
+                    "reservations-global": false,
+                    "subnet4": [
+                        {
+                            "id": 2,
+                            "subnet": "10.0.0.0/8"
+                        }
+                    ]
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := reservationsGlobalIgnored(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "Kea {daemon} configuration includes 2 global host reservations but the reservations-global is disabled")
+	require.Len(t, report.refDaemonIDs, 1)
+	require.Contains(t, report.refDaemonIDs, ctx.subjectDaemon.ID)
+}
+
+// Test that the checker returns no report when the reservations-global is
+// enabled at any configuration level or there are no global reservations.
+func TestReservationsGlobalIgnoredCorrectConfig(t *testing.T) {
+	configs := map[string]string{
+		"enabled globally": `{
+            "Dhcp6": {
+                "reservations-global": true,
+                "reservations": [ { "duid": "01:02:03:04", "hostname": "foo" } ],
+                "subnet6": [ { "id": 1, "subnet": "2001:db8:1::/64" } ]
+            }
+        }`,
+		"enabled by the deprecated mode": `{
+            "Dhcp6": {
+                "reservation-mode": "global",
+                "reservations": [ { "duid": "01:02:03:04", "hostname": "foo" } ]
+            }
+        }`,
+		"enabled for a subnet": `{
+            "Dhcp6": {
+                "reservations": [ { "duid": "01:02:03:04", "hostname": "foo" } ],
+                "subnet6": [
+                    { "id": 1, "subnet": "2001:db8:1::/64" },
+                    { "id": 2, "subnet": "2001:db8:2::/64", "reservations-global": true }
+                ]
+            }
+        }`,
+		"enabled for a shared network": `{
+            "Dhcp6": {
+                "reservations": [ { "duid": "01:02:03:04", "hostname": "foo" } ],
+                "shared-networks": [
+                    {
+                        "name": "foo",
+                        "reservations-global": true,
+                        "subnet6": [ { "id": 1, "subnet": "2001:db8:1::/64" } ]
+                    }
+                ]
+            }
+        }`,
+		"no global reservations": `{
+            "Dhcp6": {
+                "subnet6": [ { "id": 1, "subnet": "2001:db8:1::/64" } ]
+            }
+        }`,
+	}
+
+	for name, config := range configs {
+		config := config
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv6, true)
+			err := daemon.SetConfigFromJSON(config)
+			require.NoError(t, err)
+
+			ctx := newReviewContext(nil, daemon,
+				Triggers{ManualRun}, func(i int64, err error) {})
+
+			// Act
+			report, err := reservationsGlobalIgnored(ctx)
+
+			// Assert
+			require.NoError(t, err)
+			require.Nil(t, report)
+		})
+	}
+}
+
+// Generates the Kea configuration with one subnet including the specified
+// number of the single-address pools.
+func getFragmentedPoolsTestConfig(poolCount int) string {
+	pools := make([]string, poolCount)
+	for i := range pools {
+		pools[i] = fmt.Sprintf(`{ "pool": "192.0.2.%d-192.0.2.%d" }`, i+1, i+1)
+	}
+	return fmt.Sprintf(`{
+        "Dhcp4": {
+            "subnet4": [
+                {
+                    "id": 1,
+                    "subnet": "192.0.2.0/24",
+                    "pools": [ %s ]
+                }
+            ]
+        }
+    }`, strings.Join(pools, ","))
+}
+
+// Test that the checker returns no report for the subnets with few pools.
+func TestFragmentedPoolsFewPools(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(getFragmentedPoolsTestConfig(16))
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := fragmentedPools(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the checker reports the subnets with many pools.
+func TestFragmentedPoolsManyPools(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(getFragmentedPoolsTestConfig(17))
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := fragmentedPools(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "Kea {daemon} configuration includes 1 subnet with more than 16 pools of one kind")
+	require.Contains(t, *report.content, "1. [1] 192.0.2.0/24 has 17 address pools")
+}
+
+// Test that the checker reports the subnets with many delegated prefix pools.
+func TestFragmentedPoolsManyPDPools(t *testing.T) {
+	// Arrange
+	pdPools := make([]string, 20)
+	for i := range pdPools {
+		pdPools[i] = fmt.Sprintf(`{ "prefix": "3000:%x::", "prefix-len": 48, "delegated-len": 64 }`, i+1)
+	}
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv6, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(fmt.Sprintf(`{
+        "Dhcp6": {
+            "shared-networks": [
+                {
+                    "name": "foo",
+                    "subnet6": [
+                        {
+                            "id": 1,
+                            "subnet": "2001:db8:1::/64",
+                            "pools": [ { "pool": "2001:db8:1::10-2001:db8:1::100" } ],
+                            "pd-pools": [ %s ]
+                        }
+                    ]
+                }
+            ]
+        }
+    }`, strings.Join(pdPools, ",")))
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := fragmentedPools(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "1. [1] 2001:db8:1::/64 has 20 delegated prefix pools")
+	require.NotContains(t, *report.content, "address pool")
+}
+
+// Test that the pool count threshold is read from the database.
+func TestFragmentedPoolsThresholdFromDatabase(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	err := dbmodel.InitializeSettings(db, 0)
+	require.NoError(t, err)
+	err = dbmodel.SetSettingInt(db, "max_pools_per_subnet", 4)
+	require.NoError(t, err)
+
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	err = daemon.SetConfigFromJSON(getFragmentedPoolsTestConfig(5))
+	require.NoError(t, err)
+
+	ctx := newReviewContext(db, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := fragmentedPools(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "with more than 4 pools of one kind")
+	require.Contains(t, *report.content, "1. [1] 192.0.2.0/24 has 5 address pools")
+}
+
+// Test that the checker returns no report when the pools match the
+// subnets' address family.
+func TestPoolsFamilyMismatchNoMismatch(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv6, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp6": {
+            "subnet6": [
+                {
+                    "id": 1,
+                    "subnet": "2001:db8:1::/64",
+                    "pools": [ { "pool": "2001:db8:1::10-2001:db8:1::100" } ],
+                    "pd-pools": [ { "prefix": "3000::", "prefix-len": 48, "delegated-len": 64 } ]
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := poolsFamilyMismatch(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the checker reports the IPv6 pools in the IPv4 subnets.
+func TestPoolsFamilyMismatchIPv6PoolInIPv4Subnet(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp4": {
+            "subnet4": [
+                {
+                    "id": 1,
+                    "subnet": "192.0.2.0/24",
+                    "pools": [
+                        { "pool": "192.0.2.10-192.0.2.100" },
+                        { "pool": "2001:db8:1::10-2001:db8:1::100" }
+                    ]
+                },
+                {
+                    "id": 2,
+                    "subnet": "192.0.3.0/24",
+                    "pools": [ { "pool": "192.0.3.10-192.0.3.100" } ]
+                }
+            ],
+            "shared-networks": [
+                {
+                    "name": "foo",
+                    "subnet4": [
+                        {
+                            "subnet": "192.0.4.0/24",
+                            "pools": [ { "pool": "2001:db8:2::/120" } ]
+                        }
+                    ]
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := poolsFamilyMismatch(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "Kea {daemon} configuration includes 2 subnets with the pools from the other address family")
+	require.Contains(t, *report.content, "1. [1] 192.0.2.0/24 has 2001:db8:1::10-2001:db8:1::100")
+	require.Contains(t, *report.content, "2. 192.0.4.0/24 has 2001:db8:2::-2001:db8:2::ff")
+	require.NotContains(t, *report.content, "192.0.3.0/24")
+}
+
+// Test that the checker reports the IPv4 address and delegated prefix
+// pools in the IPv6 subnets.
+func TestPoolsFamilyMismatchIPv4PoolInIPv6Subnet(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv6, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp6": {
+            "subnet6": [
+                {
+                    "id": 1,
+                    "subnet": "2001:db8:1::/64",
+                    "pools": [ { "pool": "192.0.2.10-192.0.2.100" } ],
+                    "pd-pools": [ { "prefix": "10.0.0.0", "prefix-len": 8, "delegated-len": 16 } ]
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := poolsFamilyMismatch(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "includes 1 subnet with the pools")
+	require.Contains(t, *report.content, "1. [1] 2001:db8:1::/64 has 192.0.2.10-192.0.2.100, 10.0.0.0/8")
+}
+
+// Test that the checker doesn't fail on the pools that cannot be parsed.
+func TestPoolsFamilyMismatchInvalidPool(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp4": {
+            "subnet4": [
+                {
+                    "id": 1,
+                    "subnet": "192.0.2.0/24",
+                    "pools": [ { "pool": "192.0.2.10-2001:db8:1::100" } ]
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := poolsFamilyMismatch(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the overlapping address pools specified as the ranges and
+// prefixes are found and the adjacent pools are not reported.
+func TestFindOverlappingPools(t *testing.T) {
+	t.Run("IPv4", func(t *testing.T) {
+		subnet := &keaconfig.Subnet4{
+			MandatorySubnetParameters: keaconfig.MandatorySubnetParameters{
+				Subnet: "192.0.2.0/24",
+			},
+		}
+		subnet.Pools = []keaconfig.Pool{
+			{Pool: "192.0.2.64/26"},
+			{Pool: "192.0.2.10-192.0.2.63"},
+			{Pool: "192.0.2.100-192.0.2.110"},
+			{Pool: "192.0.2.128-192.0.2.200"},
+		}
+		require.Equal(t, []string{"192.0.2.64/26 and 192.0.2.100-192.0.2.110"}, findOverlappingPools(subnet))
+	})
+
+	t.Run("IPv6", func(t *testing.T) {
+		subnet := &keaconfig.Subnet6{
+			MandatorySubnetParameters: keaconfig.MandatorySubnetParameters{
+				Subnet: "2001:db8:1::/64",
+			},
+		}
+		subnet.Pools = []keaconfig.Pool{
+			{Pool: "2001:db8:1::/120"},
+			{Pool: "2001:db8:1::ff-2001:db8:1::1ff"},
+			{Pool: "2001:db8:1::200-2001:db8:1::2ff"},
+		}
+		require.Equal(t, []string{"2001:db8:1::/120 and 2001:db8:1::ff-2001:db8:1::1ff"}, findOverlappingPools(subnet))
+	})
+
+	t.Run("identical", func(t *testing.T) {
+		subnet := &keaconfig.Subnet4{}
+		subnet.Pools = []keaconfig.Pool{
+			{Pool: "192.0.2.0/28"},
+			{Pool: "192.0.2.0-192.0.2.15"},
+		}
+		require.Len(t, findOverlappingPools(subnet), 1)
+	})
+
+	t.Run("invalid pool", func(t *testing.T) {
+		subnet := &keaconfig.Subnet4{}
+		subnet.Pools = []keaconfig.Pool{
+			{Pool: "192.0.2.10-192.0.2.20"},
+			{Pool: "foo"},
+		}
+		require.Empty(t, findOverlappingPools(subnet))
+	})
+}
+
+// Test that the checker returns no report when the pools don't overlap.
+func TestOverlappingPoolsNoOverlaps(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp4": {
+            "subnet4": [
+                {
+                    "id": 1,
+                    "subnet": "192.0.2.0/24",
+                    "pools": [
+                        { "pool": "192.0.2.0/25" },
+                        { "pool": "192.0.2.128 - 192.0.2.255" }
+                    ]
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := overlappingPools(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the checker reports the subnets with the overlapping pools.
+func TestOverlappingPools(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv6, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp6": {
+            "subnet6": [
+                {
+                    "id": 1,
+                    "subnet": "2001:db8:1::/64",
+                    "pools": [
+                        { "pool": "2001:db8:1::/120" },
+                        { "pool": "2001:db8:1::10-2001:db8:1::20" }
+                    ]
+                },
+                {
+                    "id": 2,
+                    "subnet": "2001:db8:2::/64",
+                    "pools": [
+                        { "pool": "2001:db8:2::/120" },
+                        { "pool": "2001:db8:2::100-2001:db8:2::1ff" }
+                    ]
+                }
+            ],
+            "shared-networks": [
+                {
+                    "name": "foo",
+                    "subnet6": [
+                        {
+                            "subnet": "2001:db8:3::/64",
+                            "pools": [
+                                { "pool": "2001:db8:3::1-2001:db8:3::100" },
+                                { "pool": "2001:db8:3::100-2001:db8:3::200" }
+                            ]
+                        }
+                    ]
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := overlappingPools(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "Kea {daemon} configuration includes 2 subnets with the overlapping address pools")
+	require.Contains(t, *report.content, "1. [1] 2001:db8:1::/64 has overlapping pools 2001:db8:1::-2001:db8:1::ff and 2001:db8:1::10-2001:db8:1::20")
+	require.Contains(t, *report.content, "2. 2001:db8:3::/64 has overlapping pools 2001:db8:3::1-2001:db8:3::100 and 2001:db8:3::100-2001:db8:3::200")
+	require.NotContains(t, *report.content, "2001:db8:2::/64")
+}
+
+// Returns the DHCPv4 configuration with the global, shared network and
+// subnet-level valid lifetimes used in the valid lifetime range tests.
+func getValidLifetimeTestConfig() string {
+	return `{
+        "Dhcp4": {
+            "valid-lifetime": 4000,
+            "shared-networks": [
+                {
+                    "name": "foo",
+                    "valid-lifetime": 2592000,
+                    "subnet4": [
+                        {
+                            "id": 1,
+                            "subnet": "192.0.2.0/24"
+                        },
+                        {
+                            "id": 2,
+                            "subnet": "192.0.3.0/24",
+                            "valid-lifetime": 7200
+                        }
+                    ]
+                }
+            ],
+            "subnet4": [
+                {
+                    "id": 3,
+                    "subnet": "192.0.4.0/24",
+                    "valid-lifetime": 60
+                },
+                {
+                    "id": 4,
+                    "subnet": "192.0.5.0/24"
+                }
+            ]
+        }
+    }`
+}
+
+// Test that the checker returns no report when the valid lifetimes are
+// within the range.
+func TestValidLifetimeOutOfRangeNormal(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv6, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp6": {
+            "valid-lifetime": 4000,
+            "subnet6": [
+                {
+                    "id": 1,
+                    "subnet": "2001:db8:1::/64"
+                },
+                {
+                    "id": 2,
+                    "subnet": "2001:db8:2::/64",
+                    "valid-lifetime": 604800
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := validLifetimeOutOfRange(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the checker returns no report when the valid lifetime is not
+// specified and the Kea default applies.
+func TestValidLifetimeOutOfRangeDefault(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp4": {
+            "subnet4": [
+                {
+                    "id": 1,
+                    "subnet": "192.0.2.0/24"
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := validLifetimeOutOfRange(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the checker reports the subnets with too low and too high
+// valid lifetimes, including the inherited ones.
+func TestValidLifetimeOutOfRange(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(getValidLifetimeTestConfig())
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := validLifetimeOutOfRange(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "Kea {daemon} configuration includes 2 subnets with the valid-lifetime outside of the range from 300 to 604800 seconds")
+	require.Contains(t, *report.content, "[3] 192.0.4.0/24: valid-lifetime 60 is too low")
+	require.Contains(t, *report.content, "[1] 192.0.2.0/24: valid-lifetime 2592000 is too high")
+	require.NotContains(t, *report.content, "192.0.3.0/24")
+	require.NotContains(t, *report.content, "192.0.5.0/24")
+}
+
+// Test that the valid lifetime bounds are read from the database.
+func TestValidLifetimeOutOfRangeBoundsFromDatabase(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	err := dbmodel.InitializeSettings(db, 0)
+	require.NoError(t, err)
+	err = dbmodel.SetSettingInt(db, "min_valid_lifetime", 5000)
+	require.NoError(t, err)
+	err = dbmodel.SetSettingInt(db, "max_valid_lifetime", 3000000)
+	require.NoError(t, err)
+
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	err = daemon.SetConfigFromJSON(getValidLifetimeTestConfig())
+	require.NoError(t, err)
+
+	ctx := newReviewContext(db, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := validLifetimeOutOfRange(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "includes 2 subnets with the valid-lifetime outside of the range from 5000 to 3000000 seconds")
+	require.Contains(t, *report.content, "[3] 192.0.4.0/24: valid-lifetime 60 is too low")
+	require.Contains(t, *report.content, "[4] 192.0.5.0/24: valid-lifetime 4000 is too low")
+	require.NotContains(t, *report.content, "192.0.2.0/24")
+}
+
+// Test that the checker returns no report when the valid lifetime is
+// specified explicitly or inherited by all subnets.
+func TestValidLifetimeUnsetAllSpecified(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	err := daemon.SetConfigFromJSON(getValidLifetimeTestConfig())
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := validLifetimeUnset(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the checker reports the subnets without the valid lifetime
+// specified at any level.
+func TestValidLifetimeUnset(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv6, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp6": {
+            "shared-networks": [
+                {
+                    "name": "foo",
+                    "valid-lifetime": 7200,
+                    "subnet6": [
+                        {
+                            "id": 1,
+                            "subnet": "2001:db8:1::/64"
+                        }
+                    ]
+                },
+                {
+                    "name": "bar",
+                    "subnet6": [
+                        {
+                            "id": 2,
+                            "subnet": "2001:db8:2::/64"
+                        }
+                    ]
+                }
+            ],
+            "subnet6": [
+                {
+                    "id": 3,
+                    "subnet": "2001:db8:3::/64",
+                    "valid-lifetime": 3600
+                },
+                {
+                    "id": 4,
+                    "subnet": "2001:db8:4::/64"
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := validLifetimeUnset(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "includes 2 subnets without the valid-lifetime specified")
+	require.Contains(t, *report.content, "[2] 2001:db8:2::/64")
+	require.Contains(t, *report.content, "[4] 2001:db8:4::/64")
+	require.NotContains(t, *report.content, "2001:db8:1::/64")
+	require.NotContains(t, *report.content, "2001:db8:3::/64")
+	require.Len(t, report.refDaemonIDs, 1)
+	require.Contains(t, report.refDaemonIDs, daemon.ID)
+}
+
+// Returns the DHCPv4 configuration with the HA hook library and the
+// specified global and subnet-level client identification settings.
+func getHAClientIdentificationTestConfig(globalParams, subnetParams string) string {
+	return fmt.Sprintf(`{ "Dhcp4": {
+        %s
+        "subnet4": [
+            {
+                "id": 1,
+                "subnet": "192.0.2.0/24"
+                %s
+            },
+            {
+                "id": 2,
+                "subnet": "192.0.3.0/24"
+            }
+        ],
+        "hooks-libraries": [
+            {
+                "library": "/libdhcp_ha.so",
+                "parameters": {
+                    "high-availability": [{
+                        "this-server-name": "server1",
+                        "mode": "hot-standby",
+                        "peers": [
+                            {
+                                "role": "primary",
+                                "name": "server1",
+                                "url": "http://10.0.0.1:8001"
+                            },
+                            {
+                                "role": "standby",
+                                "name": "server2",
+                                "url": "http://10.0.0.2:8001"
+                            }
+                        ]
+                    }]
+                }
+            }
+        ]
+    } }`, globalParams, subnetParams)
+}
+
+// Test that no mismatches are found when the peers have the same effective
+// client identification settings, including the defaults.
+func TestFindClientIdentificationMismatchesNone(t *testing.T) {
+	// Arrange
+	config, err := dbmodel.NewKeaConfigFromJSON(getHAClientIdentificationTestConfig(`"match-client-id": true,`, ""))
+	require.NoError(t, err)
+	peerConfig, err := dbmodel.NewKeaConfigFromJSON(getHAClientIdentificationTestConfig(`"echo-client-id": true,`, ""))
+	require.NoError(t, err)
+
+	// Act
+	issues := findClientIdentificationMismatches(config, peerConfig, 10)
+
+	// Assert
+	require.Empty(t, issues)
+}
+
+// Test that the mismatched global and subnet-level client identification
+// settings are found.
+func TestFindClientIdentificationMismatches(t *testing.T) {
+	// Arrange
+	config, err := dbmodel.NewKeaConfigFromJSON(getHAClientIdentificationTestConfig(`"echo-client-id": false,`, `, "match-client-id": false`))
+	require.NoError(t, err)
+	peerConfig, err := dbmodel.NewKeaConfigFromJSON(getHAClientIdentificationTestConfig("", ""))
+	require.NoError(t, err)
+
+	// Act
+	issues := findClientIdentificationMismatches(config, peerConfig, 10)
+
+	// Assert
+	require.Len(t, issues, 2)
+	require.Equal(t, "echo-client-id is false but the peer's is true", issues[0])
+	require.Equal(t, "match-client-id differs in [1] 192.0.2.0/24 (false vs true)", issues[1])
+}
+
+// Adds the Kea app with the DHCPv4 daemon having the specified configuration
+// to the database. It returns the added daemon.
+func addHAClientIdentificationTestDaemon(t *testing.T, db *dbops.PgDB, address, configStr string) *dbmodel.Daemon {
+	machine := &dbmodel.Machine{
+		Address:   address,
+		AgentPort: 8080,
+	}
+	err := dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+
+	config, err := dbmodel.NewKeaConfigFromJSON(configStr)
+	require.NoError(t, err)
+
+	app := &dbmodel.App{
+		MachineID: machine.ID,
+		Type:      dbmodel.AppTypeKea,
+		Name:      "kea@" + address,
+		Daemons: []*dbmodel.Daemon{
+			{
+				Name:   dbmodel.DaemonNameDHCPv4,
+				Active: true,
+				KeaDaemon: &dbmodel.KeaDaemon{
+					Config: config,
+				},
+			},
+		},
+	}
+	daemons, err := dbmodel.AddApp(db, app)
+	require.NoError(t, err)
+	require.Len(t, daemons, 1)
+	daemons[0].App = app
+	return daemons[0]
+}
+
+// Test that the checker reports the HA peer with different client
+// identification settings.
+func TestHighAvailabilityClientIdentificationMismatch(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addHAClientIdentificationTestDaemon(t, db, "10.0.0.1",
+		getHAClientIdentificationTestConfig("", `, "match-client-id": false`))
+	peer := addHAClientIdentificationTestDaemon(t, db, "10.0.0.2",
+		getHAClientIdentificationTestConfig("", ""))
+
+	service := &dbmodel.Service{
+		BaseService: dbmodel.BaseService{
+			Name:    "ha",
+			Daemons: []*dbmodel.Daemon{daemon, peer},
+		},
+		HAService: &dbmodel.BaseHAService{
+			HAType:      "dhcp4",
+			PrimaryID:   daemon.ID,
+			SecondaryID: peer.ID,
+		},
+	}
+	err := dbmodel.AddService(db, service)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(db, daemon, Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := highAvailabilityClientIdentificationMismatch(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "differ from the settings of 1 peer")
+	require.Contains(t, *report.content, "kea@10.0.0.2: match-client-id differs in [1] 192.0.2.0/24 (false vs true)")
+	require.ElementsMatch(t, []int64{daemon.ID, peer.ID}, report.refDaemonIDs)
+}
+
+// Test that the checker returns no report when the peers have the same
+// client identification settings.
+func TestHighAvailabilityClientIdentificationMatch(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addHAClientIdentificationTestDaemon(t, db, "10.0.0.1",
+		getHAClientIdentificationTestConfig(`"match-client-id": false,`, ""))
+	peer := addHAClientIdentificationTestDaemon(t, db, "10.0.0.2",
+		getHAClientIdentificationTestConfig(`"match-client-id": false,`, `, "match-client-id": false`))
+
+	service := &dbmodel.Service{
+		BaseService: dbmodel.BaseService{
+			Name:    "ha",
+			Daemons: []*dbmodel.Daemon{daemon, peer},
+		},
+		HAService: &dbmodel.BaseHAService{
+			HAType:      "dhcp4",
+			PrimaryID:   daemon.ID,
+			SecondaryID: peer.ID,
+		},
+	}
+	err := dbmodel.AddService(db, service)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(db, daemon, Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := highAvailabilityClientIdentificationMismatch(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Returns the DHCPv6 configuration with the HA hook library and the
+// specified global and subnet-level rapid commit settings.
+func getHARapidCommitTestConfig(globalParams, subnetParams string) string {
+	return fmt.Sprintf(`{ "Dhcp6": {
+        %s
+        "subnet6": [
+            {
+                "id": 1,
+                "subnet": "2001:db8:1::/64"
+                %s
+            }
+        ],
+        "shared-networks": [
+            {
+                "name": "foo",
+                "rapid-commit": true,
+                "subnet6": [
+                    {
+                        "id": 2,
+                        "subnet": "2001:db8:2::/64"
+                    }
+                ]
+            }
+        ],
+        "hooks-libraries": [
+            {
+                "library": "/libdhcp_ha.so",
+                "parameters": {
+                    "high-availability": [{
+                        "this-server-name": "server1",
+                        "mode": "hot-standby",
+                        "peers": [
+                            {
+                                "role": "primary",
+                                "name": "server1",
+                                "url": "http://10.0.0.1:8001"
+                            },
+                            {
+                                "role": "standby",
+                                "name": "server2",
+                                "url": "http://10.0.0.2:8001"
+                            }
+                        ]
+                    }]
+                }
+            }
+        ]
+    } }`, globalParams, subnetParams)
+}
+
+// Test that the effective rapid commit flags are resolved from the subnet,
+// shared network and global levels.
+func TestGetRapidCommitBySubnet(t *testing.T) {
+	// Arrange
+	config, err := dbmodel.NewKeaConfigFromJSON(getHARapidCommitTestConfig(`"rapid-commit": false,`, ""))
+	require.NoError(t, err)
+
+	// Act
+	rapidCommit := getRapidCommitBySubnet(config)
+
+	// Assert
+	require.Len(t, rapidCommit, 2)
+	require.False(t, rapidCommit["2001:db8:1::/64"])
+	require.True(t, rapidCommit["2001:db8:2::/64"])
+}
+
+// Test that no mismatches are found when the peers have the same effective
+// rapid commit settings specified at different levels.
+func TestFindRapidCommitMismatchesNone(t *testing.T) {
+	// Arrange
+	config, err := dbmodel.NewKeaConfigFromJSON(getHARapidCommitTestConfig(`"rapid-commit": true,`, ""))
+	require.NoError(t, err)
+	peerConfig, err := dbmodel.NewKeaConfigFromJSON(getHARapidCommitTestConfig("", `, "rapid-commit": true`))
+	require.NoError(t, err)
+
+	// Act
+	mismatchedSubnets := findRapidCommitMismatches(config, peerConfig, 10)
+
+	// Assert
+	require.Empty(t, mismatchedSubnets)
+}
+
+// Test that the subnets with different effective rapid commit settings are
+// found.
+func TestFindRapidCommitMismatches(t *testing.T) {
+	// Arrange
+	config, err := dbmodel.NewKeaConfigFromJSON(getHARapidCommitTestConfig("", `, "rapid-commit": true`))
+	require.NoError(t, err)
+	peerConfig, err := dbmodel.NewKeaConfigFromJSON(getHARapidCommitTestConfig("", ""))
+	require.NoError(t, err)
+
+	// Act
+	mismatchedSubnets := findRapidCommitMismatches(config, peerConfig, 10)
+
+	// Assert
+	require.Equal(t, []string{"[1] 2001:db8:1::/64 (true vs false)"}, mismatchedSubnets)
+}
+
+// Test that the checker returns no report for the DHCPv4 daemon.
+func TestHighAvailabilityRapidCommitMismatchDHCPv4(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	err := daemon.SetConfigFromJSON(getHAClientIdentificationTestConfig("", ""))
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon, Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := highAvailabilityRapidCommitMismatch(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Adds the Kea app with the DHCPv6 daemon having the specified configuration
+// and returns the added daemon.
+func addHARapidCommitTestDaemon(t *testing.T, db *dbops.PgDB, address, configStr string) *dbmodel.Daemon {
+	machine := &dbmodel.Machine{
+		Address:   address,
+		AgentPort: 8080,
+	}
+	err := dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+
+	config, err := dbmodel.NewKeaConfigFromJSON(configStr)
+	require.NoError(t, err)
+
+	app := &dbmodel.App{
+		MachineID: machine.ID,
+		Type:      dbmodel.AppTypeKea,
+		Name:      "kea@" + address,
+		Daemons: []*dbmodel.Daemon{
+			{
+				Name:   dbmodel.DaemonNameDHCPv6,
+				Active: true,
+				KeaDaemon: &dbmodel.KeaDaemon{
+					Config: config,
+				},
+			},
+		},
+	}
+	daemons, err := dbmodel.AddApp(db, app)
+	require.NoError(t, err)
+	require.Len(t, daemons, 1)
+	daemons[0].App = app
+	return daemons[0]
+}
+
+// Adds the HA service comprising the specified DHCPv6 daemons.
+func addHARapidCommitTestService(t *testing.T, db *dbops.PgDB, primary, secondary *dbmodel.Daemon) {
+	service := &dbmodel.Service{
+		BaseService: dbmodel.BaseService{
+			Name:    "ha",
+			Daemons: []*dbmodel.Daemon{primary, secondary},
+		},
+		HAService: &dbmodel.BaseHAService{
+			HAType:      "dhcp6",
+			PrimaryID:   primary.ID,
+			SecondaryID: secondary.ID,
+		},
+	}
+	err := dbmodel.AddService(db, service)
+	require.NoError(t, err)
+}
+
+// Test that the checker reports the HA peer with different rapid commit
+// settings.
+func TestHighAvailabilityRapidCommitMismatch(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addHARapidCommitTestDaemon(t, db, "10.0.0.1",
+		getHARapidCommitTestConfig("", `, "rapid-commit": true`))
+	peer := addHARapidCommitTestDaemon(t, db, "10.0.0.2",
+		getHARapidCommitTestConfig("", ""))
+	addHARapidCommitTestService(t, db, daemon, peer)
+
+	ctx := newReviewContext(db, daemon, Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := highAvailabilityRapidCommitMismatch(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "differ from the settings of 1 peer")
+	require.Contains(t, *report.content, "kea@10.0.0.2: rapid-commit differs in [1] 2001:db8:1::/64 (true vs false)")
+	require.NotContains(t, *report.content, "2001:db8:2::/64")
+	require.ElementsMatch(t, []int64{daemon.ID, peer.ID}, report.refDaemonIDs)
+}
+
+// Test that the checker returns no report when the peers agree on the
+// rapid commit settings.
+func TestHighAvailabilityRapidCommitMatch(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addHARapidCommitTestDaemon(t, db, "10.0.0.1",
+		getHARapidCommitTestConfig(`"rapid-commit": true,`, ""))
+	peer := addHARapidCommitTestDaemon(t, db, "10.0.0.2",
+		getHARapidCommitTestConfig("", `, "rapid-commit": true`))
+	addHARapidCommitTestService(t, db, daemon, peer)
+
+	ctx := newReviewContext(db, daemon, Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := highAvailabilityRapidCommitMismatch(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the duplicated options are found in the option data list.
+func TestFindDuplicateOptions(t *testing.T) {
+	// Arrange
+	options := []keaconfig.SingleOptionData{
+		{Code: 6, Data: "192.0.2.1"},
+		{Code: 3, Data: "192.0.2.2"},
+		{Code: 6, Data: "192.0.2.3", Space: "dhcp4"},
+		{Code: 6, Data: "192.0.2.4"},
+		{Code: 1, Space: "vendor-4491"},
+		{Code: 1, Space: "vendor-4491"},
+		{Code: 1, Space: "vendor-3561"},
+		{Name: "domain-name", Data: "example.org"},
+		{Name: "domain-name", Data: "example.com"},
+	}
+
+	// Act
+	duplicates := findDuplicateOptions(options, "dhcp4")
+
+	// Assert
+	require.Equal(t, []string{
+		"option 6",
+		"option 1 in space vendor-4491",
+		"option domain-name",
+	}, duplicates)
+}
+
+// Test that no duplicates are found when all options are unique.
+func TestFindDuplicateOptionsUnique(t *testing.T) {
+	// Arrange
+	options := []keaconfig.SingleOptionData{
+		{Code: 6, Data: "192.0.2.1"},
+		{Code: 3, Data: "192.0.2.2"},
+		{Code: 6, Space: "custom"},
+		{Name: "domain-name", Data: "example.org"},
+	}
+
+	// Act
+	duplicates := findDuplicateOptions(options, "dhcp4")
+
+	// Assert
+	require.Empty(t, duplicates)
+}
+
+// Test that the checker reports the subnets defining the same option more
+// than once in the subnet and pool scopes.
+func TestDuplicateOptions(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp4": {
+            "option-data": [
+                { "code": 6, "data": "192.0.2.1" },
+                { "code": 6, "data": "192.0.2.2" }
+            ],
+            "subnet4": [
+                {
+                    "id": 1,
+                    "subnet": "192.0.2.0/24",
+                    "option-data": [
+                        { "code": 3, "data": "192.0.2.1" },
+                        { "code": 3, "data": "192.0.2.2" }
+                    ]
+                },
+                {
+                    "id": 2,
+                    "subnet": "192.0.3.0/24",
+                    "option-data": [
+                        { "code": 3, "data": "192.0.3.1" }
+                    ],
+                    "pools": [
+                        {
+                            "pool": "192.0.3.10-192.0.3.100",
+                            "option-data": [
+                                { "name": "domain-name", "data": "example.org" },
+                                { "name": "domain-name", "data": "example.com" }
+                            ]
+                        }
+                    ]
+                }
+            ],
+            "shared-networks": [
+                {
+                    "name": "foo",
+                    "subnet4": [
+                        {
+                            "subnet": "192.0.4.0/24",
+                            "option-data": [
+                                { "code": 6, "data": "192.0.4.1" },
+                                { "code": 3, "data": "192.0.4.1" }
+                            ]
+                        }
+                    ]
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := duplicateOptions(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "Kea {daemon} configuration includes 2 subnets defining the same option more than once")
+	require.Contains(t, *report.content, "1. [1] 192.0.2.0/24 defines option 3 more than once")
+	require.Contains(t, *report.content, "2. [2] 192.0.3.0/24 defines option domain-name in pool 192.0.3.10-192.0.3.100 more than once")
+	require.NotContains(t, *report.content, "192.0.4.0/24")
+	require.Len(t, report.refDaemonIDs, 1)
+	require.Contains(t, report.refDaemonIDs, daemon.ID)
+}
+
+// Test that the checker reports the duplicated options in the DHCPv6
+// delegated prefix pools.
+func TestDuplicateOptionsDHCPv6(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv6, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp6": {
+            "subnet6": [
+                {
+                    "id": 1,
+                    "subnet": "2001:db8:1::/64",
+                    "option-data": [
+                        { "code": 23, "data": "2001:db8::1" },
+                        { "code": 23, "data": "2001:db8::1", "space": "dhcp6" }
+                    ],
+                    "pd-pools": [
+                        {
+                            "prefix": "3000::",
+                            "prefix-len": 48,
+                            "delegated-len": 64,
+                            "option-data": [
+                                { "code": 24, "data": "example.org" },
+                                { "code": 24, "data": "example.com" }
+                            ]
+                        }
+                    ]
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := duplicateOptions(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	require.Contains(t, *report.content, "includes 1 subnet defining the same option more than once")
+	require.Contains(t, *report.content, "1. [1] 2001:db8:1::/64 defines option 23, option 24 in pool 3000::/48 more than once")
+}
+
+// Test that the checker doesn't report the subnets with the unique options.
+func TestDuplicateOptionsUnique(t *testing.T) {
+	// Arrange
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	err := daemon.SetConfigFromJSON(`{
+        "Dhcp4": {
+            "subnet4": [
+                {
+                    "id": 1,
+                    "subnet": "192.0.2.0/24",
+                    "option-data": [
+                        { "code": 3, "data": "192.0.2.1" },
+                        { "code": 6, "data": "192.0.2.2" }
+                    ],
+                    "pools": [
+                        {
+                            "pool": "192.0.2.10-192.0.2.100",
+                            "option-data": [
+                                { "code": 3, "data": "192.0.2.3" }
+                            ]
+                        }
+                    ]
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	// Act
+	report, err := duplicateOptions(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, report)
+}
+
+// Test that the checker returns an error for an unsupported daemon.
+func TestDuplicateOptionsUnsupportedDaemon(t *testing.T) {
+	daemon := dbmodel.NewKeaDaemon(dbmodel.DaemonNameCA, true)
+	ctx := newReviewContext(nil, daemon,
+		Triggers{ManualRun}, func(i int64, err error) {})
+
+	report, err := duplicateOptions(ctx)
+
+	require.Error(t, err)
+	require.Nil(t, report)
+}
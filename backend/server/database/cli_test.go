@@ -244,6 +244,24 @@ func TestConvertDatabaseCLIFlagsToSettings(t *testing.T) {
 	require.EqualValues(t, LoggingQueryPresetRuntime, settings.TraceSQL)
 }
 
+// Test that the comma-separated replica hosts are converted to the list
+// in the database settings.
+func TestConvertDatabaseCLIFlagsWithReplicaHostsToSettings(t *testing.T) {
+	// Arrange
+	cliFlags := &DatabaseCLIFlags{
+		Host:         "host",
+		Port:         42,
+		ReplicaHosts: "replica1, replica2:5433,,",
+	}
+
+	// Act
+	settings, err := cliFlags.ConvertToDatabaseSettings()
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, []string{"replica1", "replica2:5433"}, settings.ReplicaHosts)
+}
+
 // Test that the database CLI flags with URL are converted to the database
 // settings properly.
 func TestConvertDatabaseCLIFlagsWithURLToSettings(t *testing.T) {
@@ -650,7 +668,7 @@ func TestConvertDatabaseCLIFlagsToDefinitions(t *testing.T) {
 	definitions := pointer.ConvertToCLIFlagDefinitions()
 
 	// Assert
-	require.Len(t, definitions, 11)
+	require.Len(t, definitions, 12)
 
 	definitionMap := make(map[string]*CLIFlagDefinition, len(definitions))
 	for _, definition := range definitions {
@@ -672,7 +690,7 @@ func TestConvertMaintenanceDatabaseCLIFlagsToDefinitions(t *testing.T) {
 	definitions := pointer.ConvertToCLIFlagDefinitions()
 
 	// Assert
-	require.Len(t, definitions, 11+3)
+	require.Len(t, definitions, 12+3)
 
 	definitionMap := make(map[string]*CLIFlagDefinition, len(definitions))
 	for _, definition := range definitions {
@@ -0,0 +1,19 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Add a flag indicating that all addresses or delegated prefixes
+			-- in the subnet are assigned.
+			ALTER TABLE subnet ADD COLUMN exhausted BOOLEAN NOT NULL DEFAULT FALSE;
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			ALTER TABLE subnet DROP COLUMN exhausted;
+		`)
+		return err
+	})
+}
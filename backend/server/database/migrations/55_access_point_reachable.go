@@ -0,0 +1,20 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Add a flag indicating whether the access point responded
+			-- during the last state pull. The existing access points are
+			-- assumed reachable until the next pull.
+			ALTER TABLE access_point ADD COLUMN reachable BOOLEAN NOT NULL DEFAULT TRUE;
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			ALTER TABLE access_point DROP COLUMN reachable;
+		`)
+		return err
+	})
+}
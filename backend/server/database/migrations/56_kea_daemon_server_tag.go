@@ -0,0 +1,21 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Add the server tag selecting the daemon's configuration in
+			-- the configuration backend. The config hash is reset to
+			-- populate the server tag during the next state pull.
+			ALTER TABLE kea_daemon ADD COLUMN server_tag TEXT;
+			UPDATE kea_daemon SET config_hash = NULL;
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			ALTER TABLE kea_daemon DROP COLUMN server_tag;
+		`)
+		return err
+	})
+}
@@ -0,0 +1,21 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Add the variant of the configuration structure used by
+			-- the daemon. The config hash is reset to populate the
+			-- structure variant during the next state pull.
+			ALTER TABLE kea_daemon ADD COLUMN config_structure TEXT;
+			UPDATE kea_daemon SET config_hash = NULL;
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			ALTER TABLE kea_daemon DROP COLUMN config_structure;
+		`)
+		return err
+	})
+}
@@ -0,0 +1,25 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Add the interfaces-config settings of the DHCP daemon. The
+			-- config hash is reset to populate the settings during the
+			-- next state pull.
+			ALTER TABLE kea_daemon ADD COLUMN interfaces_re_detect BOOLEAN;
+			ALTER TABLE kea_daemon ADD COLUMN dhcp_socket_type TEXT;
+			ALTER TABLE kea_daemon ADD COLUMN outbound_interface TEXT;
+			UPDATE kea_daemon SET config_hash = NULL;
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			ALTER TABLE kea_daemon DROP COLUMN outbound_interface;
+			ALTER TABLE kea_daemon DROP COLUMN dhcp_socket_type;
+			ALTER TABLE kea_daemon DROP COLUMN interfaces_re_detect;
+		`)
+		return err
+	})
+}
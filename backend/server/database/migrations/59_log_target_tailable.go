@@ -0,0 +1,23 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Indicates whether the log output is a file that can be
+			-- tailed. The logs written to stdout, stderr and syslog
+			-- can't be viewed in Stork.
+			ALTER TABLE log_target ADD COLUMN tailable BOOLEAN NOT NULL DEFAULT TRUE;
+			UPDATE log_target SET tailable = FALSE
+				WHERE output IN ('', 'stdout', 'stderr', 'syslog')
+				OR output LIKE 'syslog:%';
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			ALTER TABLE log_target DROP COLUMN tailable;
+		`)
+		return err
+	})
+}
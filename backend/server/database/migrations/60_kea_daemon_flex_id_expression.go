@@ -0,0 +1,21 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Add the identifier expression of the flex_id hook library.
+			-- The config hash is reset to populate the expression during
+			-- the next state pull.
+			ALTER TABLE kea_daemon ADD COLUMN flex_id_expression TEXT;
+			UPDATE kea_daemon SET config_hash = NULL;
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			ALTER TABLE kea_daemon DROP COLUMN flex_id_expression;
+		`)
+		return err
+	})
+}
@@ -0,0 +1,23 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Add the configuration fetched when the daemon was added to
+			-- Stork. The current configuration becomes the baseline for
+			-- the existing daemons.
+			ALTER TABLE kea_daemon ADD COLUMN baseline_config JSONB;
+			ALTER TABLE kea_daemon ADD COLUMN baseline_config_hash TEXT;
+			UPDATE kea_daemon SET baseline_config = config, baseline_config_hash = config_hash;
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			ALTER TABLE kea_daemon DROP COLUMN baseline_config_hash;
+			ALTER TABLE kea_daemon DROP COLUMN baseline_config;
+		`)
+		return err
+	})
+}
@@ -0,0 +1,46 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Add the time when the app became active or inactive. The
+			-- actual change time is unknown for the existing apps.
+			ALTER TABLE app ADD COLUMN active_changed_at TIMESTAMP WITHOUT TIME ZONE;
+			UPDATE app SET active_changed_at = timezone('utc'::text, now());
+			ALTER TABLE app ALTER COLUMN active_changed_at SET NOT NULL;
+
+			-- Trigger function setting the time of the active state change.
+			-- The time is preserved when the state doesn't change and the
+			-- new time is not specified.
+			CREATE OR REPLACE FUNCTION app_active_changed_at()
+				RETURNS trigger
+				LANGUAGE 'plpgsql'
+				AS $function$
+			BEGIN
+				IF TG_OP = 'INSERT' THEN
+					NEW.active_changed_at = COALESCE(NEW.active_changed_at, timezone('utc'::text, now()));
+				ELSIF COALESCE(NEW.active, false) IS DISTINCT FROM COALESCE(OLD.active, false) THEN
+					NEW.active_changed_at = timezone('utc'::text, now());
+				ELSIF NEW.active_changed_at IS NULL THEN
+					NEW.active_changed_at = OLD.active_changed_at;
+				END IF;
+				RETURN NEW;
+			END;
+			$function$;
+
+			CREATE TRIGGER app_before_insert_update_active
+				BEFORE INSERT OR UPDATE ON app
+					FOR EACH ROW EXECUTE PROCEDURE app_active_changed_at();
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			DROP TRIGGER IF EXISTS app_before_insert_update_active ON app;
+			DROP FUNCTION IF EXISTS app_active_changed_at;
+			ALTER TABLE app DROP COLUMN active_changed_at;
+		`)
+		return err
+	})
+}
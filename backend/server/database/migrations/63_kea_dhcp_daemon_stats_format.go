@@ -0,0 +1,19 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Add the format of the lease statistics returned by the
+			-- stat_cmds hook library, i.e., 1.6 or 1.8.
+			ALTER TABLE kea_dhcp_daemon ADD COLUMN stats_format TEXT;
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			ALTER TABLE kea_dhcp_daemon DROP COLUMN stats_format;
+		`)
+		return err
+	})
+}
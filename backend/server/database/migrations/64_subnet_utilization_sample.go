@@ -0,0 +1,40 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- This creates a table of the subnet utilization samples recorded
+			-- by the statistics puller. They are used to render the utilization
+			-- trends. The utilizations are stored in permilles like in the
+			-- subnet table.
+			CREATE TABLE IF NOT EXISTS subnet_utilization_sample (
+				id BIGSERIAL NOT NULL,
+				subnet_id BIGINT NOT NULL,
+				sampled_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+				addr_utilization SMALLINT NOT NULL DEFAULT 0,
+				pd_utilization SMALLINT NOT NULL DEFAULT 0,
+				CONSTRAINT subnet_utilization_sample_pkey PRIMARY KEY (id),
+				CONSTRAINT subnet_utilization_sample_subnet_id_fkey FOREIGN KEY (subnet_id)
+					REFERENCES subnet (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+			);
+
+			-- The samples are selected by subnet and time.
+			CREATE INDEX subnet_utilization_sample_subnet_id_sampled_at_idx
+				ON subnet_utilization_sample(subnet_id, sampled_at);
+
+			-- The old samples are pruned by time.
+			CREATE INDEX subnet_utilization_sample_sampled_at_idx
+				ON subnet_utilization_sample(sampled_at);
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			DROP TABLE IF EXISTS subnet_utilization_sample;
+		`)
+		return err
+	})
+}
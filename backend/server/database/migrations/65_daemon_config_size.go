@@ -0,0 +1,35 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- This creates a table of the daemon configuration sizes recorded
+			-- when the daemon configurations are committed. They are used to
+			-- show the configuration growth trends.
+			CREATE TABLE IF NOT EXISTS daemon_config_size (
+				id BIGSERIAL NOT NULL,
+				daemon_id BIGINT NOT NULL,
+				recorded_at TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+				subnet_count BIGINT NOT NULL DEFAULT 0,
+				reservation_count BIGINT NOT NULL DEFAULT 0,
+				CONSTRAINT daemon_config_size_pkey PRIMARY KEY (id),
+				CONSTRAINT daemon_config_size_daemon_id_fkey FOREIGN KEY (daemon_id)
+					REFERENCES daemon (id) MATCH SIMPLE
+					ON UPDATE CASCADE
+					ON DELETE CASCADE
+			);
+
+			-- The sizes are selected by daemon and time.
+			CREATE INDEX daemon_config_size_daemon_id_recorded_at_idx
+				ON daemon_config_size(daemon_id, recorded_at);
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			DROP TABLE IF EXISTS daemon_config_size;
+		`)
+		return err
+	})
+}
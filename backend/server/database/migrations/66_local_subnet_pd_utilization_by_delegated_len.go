@@ -0,0 +1,22 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- The delegated prefix utilization of the local subnet by the
+			-- delegated prefix length. The pools delegating the prefixes of
+			-- different lengths have different capacities.
+			ALTER TABLE local_subnet
+				ADD COLUMN IF NOT EXISTS pd_utilization_by_delegated_len JSONB;
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			ALTER TABLE local_subnet
+				DROP COLUMN IF EXISTS pd_utilization_by_delegated_len;
+		`)
+		return err
+	})
+}
@@ -0,0 +1,19 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Add the configuration hash reported by the Kea daemon
+			-- in response to the config-hash-get command.
+			ALTER TABLE kea_daemon ADD COLUMN kea_config_hash TEXT;
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			ALTER TABLE kea_daemon DROP COLUMN kea_config_hash;
+		`)
+		return err
+	})
+}
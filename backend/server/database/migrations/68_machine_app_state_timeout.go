@@ -0,0 +1,19 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Add the timeout in seconds of getting the state of the apps
+			-- running on the machine. Zero means the global timeout.
+			ALTER TABLE machine ADD COLUMN app_state_timeout INTEGER NOT NULL DEFAULT 0;
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			ALTER TABLE machine DROP COLUMN app_state_timeout;
+		`)
+		return err
+	})
+}
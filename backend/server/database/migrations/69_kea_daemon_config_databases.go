@@ -0,0 +1,22 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Add the configuration backend database connections used
+			-- by the Kea daemons. The credentials are not stored. The
+			-- config hash is reset to populate the connections during
+			-- the next state pull.
+			ALTER TABLE kea_daemon ADD COLUMN config_databases JSONB;
+			UPDATE kea_daemon SET config_hash = NULL;
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			ALTER TABLE kea_daemon DROP COLUMN config_databases;
+		`)
+		return err
+	})
+}
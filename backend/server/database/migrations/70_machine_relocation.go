@@ -0,0 +1,24 @@
+package dbmigs
+
+import "github.com/go-pg/migrations/v8"
+
+func init() {
+	migrations.MustRegisterTx(func(db migrations.DB) error {
+		_, err := db.Exec(`
+			-- Add the pending relocation of the machine. It is set when
+			-- the agent registers from a new address without the server
+			-- token and remains pending until confirmed by an administrator.
+			ALTER TABLE machine ADD COLUMN relocation_address TEXT;
+			ALTER TABLE machine ADD COLUMN relocation_agent_port INTEGER;
+			ALTER TABLE machine ADD COLUMN relocation_cert_fingerprint BYTEA;
+		`)
+		return err
+	}, func(db migrations.DB) error {
+		_, err := db.Exec(`
+			ALTER TABLE machine DROP COLUMN relocation_address;
+			ALTER TABLE machine DROP COLUMN relocation_agent_port;
+			ALTER TABLE machine DROP COLUMN relocation_cert_fingerprint;
+		`)
+		return err
+	})
+}
@@ -2,6 +2,7 @@ package dbmodel
 
 import (
 	"errors"
+	"net"
 
 	"github.com/go-pg/pg/v10"
 	pkgerrors "github.com/pkg/errors"
@@ -17,6 +18,11 @@ type AccessPoint struct {
 	Port              int64
 	Key               string
 	UseSecureProtocol bool `pg:",use_zero"`
+	// Indicates whether the access point responded during the last state
+	// pull. A machine may run several apps (e.g., Kea Control Agents
+	// listening on different ports), and each of them is tracked
+	// independently.
+	Reachable bool `pg:",use_zero"`
 }
 
 // Valid kinds of the access points.
@@ -26,7 +32,7 @@ const (
 )
 
 // AppendAccessPoint is an utility function that appends an access point to a
-// list.
+// list. The access point is initially marked reachable.
 func AppendAccessPoint(list []*AccessPoint, tp, address, key string, port int64, useSecureProtocol bool) []*AccessPoint {
 	list = append(list, &AccessPoint{
 		Type:              tp,
@@ -34,6 +40,7 @@ func AppendAccessPoint(list []*AccessPoint, tp, address, key string, port int64,
 		Port:              port,
 		Key:               key,
 		UseSecureProtocol: useSecureProtocol,
+		Reachable:         true,
 	})
 	return list
 }
@@ -55,3 +62,56 @@ func GetAccessPointByID(db dbops.DBI, appID int64, accessPointType string) (*Acc
 	}
 	return accessPoint, nil
 }
+
+// Checks if the access point address is local to the machine, i.e., it is
+// a loopback or unspecified address. Such addresses designate different
+// endpoints on different machines.
+func (ap *AccessPoint) isMachineLocal() bool {
+	if ap.Address == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(ap.Address)
+	return ip != nil && (ip.IsLoopback() || ip.IsUnspecified())
+}
+
+// Returns the access points of other apps sharing the address and port with
+// any access point of the specified app. Such duplicates usually indicate a
+// registration mistake causing Stork to poll the same server twice. The
+// access points having the loopback or unspecified addresses are only
+// considered duplicates when they belong to the same machine.
+func GetDuplicateAccessPoints(dbi dbops.DBI, appID int64) ([]AccessPoint, error) {
+	var ownAccessPoints []AccessPoint
+	err := dbi.Model(&ownAccessPoints).
+		Where("app_id = ?", appID).
+		OrderExpr("type ASC").
+		Select()
+	if err != nil && !errors.Is(err, pg.ErrNoRows) {
+		return nil, pkgerrors.Wrapf(err, "problem getting access points of app: %d", appID)
+	}
+
+	duplicates := []AccessPoint{}
+	found := make(map[AccessPoint]bool)
+	for _, own := range ownAccessPoints {
+		var accessPoints []AccessPoint
+		q := dbi.Model(&accessPoints).
+			Where("address = ?", own.Address).
+			Where("port = ?", own.Port).
+			Where("app_id != ?", appID)
+		if own.isMachineLocal() {
+			q = q.Where("machine_id = ?", own.MachineID)
+		}
+		err = q.OrderExpr("app_id ASC, type ASC").Select()
+		if err != nil && !errors.Is(err, pg.ErrNoRows) {
+			return nil, pkgerrors.Wrapf(err, "problem getting duplicates of the access point %s:%d", own.Address, own.Port)
+		}
+		// The app may have several access points with the same address
+		// and port, e.g., control and statistics.
+		for _, accessPoint := range accessPoints {
+			if !found[accessPoint] {
+				found[accessPoint] = true
+				duplicates = append(duplicates, accessPoint)
+			}
+		}
+	}
+	return duplicates, nil
+}
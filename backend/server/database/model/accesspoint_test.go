@@ -95,3 +95,62 @@ func TestGetAccessPointByID(t *testing.T) {
 	require.EqualValues(t, AccessPointControl, accessPoint.Type)
 	require.True(t, accessPoint.UseSecureProtocol)
 }
+
+// Test that the access points of other apps sharing the address and port
+// with the access points of the given app are returned.
+func TestGetDuplicateAccessPoints(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	var machines []*Machine
+	for _, address := range []string{"192.0.2.1", "192.0.2.2"} {
+		machine := &Machine{Address: address, AgentPort: 8080}
+		_ = AddMachine(db, machine)
+		machines = append(machines, machine)
+	}
+
+	var apps []*App
+	for _, ap := range []struct {
+		machine *Machine
+		address string
+		port    int64
+	}{
+		{machines[0], "192.0.2.1", 8000},
+		{machines[0], "127.0.0.1", 8001},
+		{machines[1], "192.0.2.1", 8000},
+		{machines[1], "127.0.0.1", 8001},
+		{machines[0], "127.0.0.1", 8001},
+		{machines[0], "192.0.2.1", 8002},
+	} {
+		app := &App{
+			MachineID: ap.machine.ID,
+			Type:      AppTypeKea,
+			AccessPoints: []*AccessPoint{{
+				Type:    AccessPointControl,
+				Address: ap.address,
+				Port:    ap.port,
+			}},
+		}
+		_, _ = AddApp(db, app)
+		apps = append(apps, app)
+	}
+
+	// Act
+	duplicates0, err0 := GetDuplicateAccessPoints(db, apps[0].ID)
+	duplicates1, err1 := GetDuplicateAccessPoints(db, apps[1].ID)
+	duplicates5, err5 := GetDuplicateAccessPoints(db, apps[5].ID)
+
+	// Assert
+	require.NoError(t, err0)
+	require.Len(t, duplicates0, 1)
+	require.EqualValues(t, apps[2].ID, duplicates0[0].AppID)
+
+	// The loopback address is only duplicated on the same machine.
+	require.NoError(t, err1)
+	require.Len(t, duplicates1, 1)
+	require.EqualValues(t, apps[4].ID, duplicates1[0].AppID)
+
+	require.NoError(t, err5)
+	require.Empty(t, duplicates5)
+}
@@ -10,6 +10,7 @@ import (
 	pkgerrors "github.com/pkg/errors"
 	"isc.org/stork/datamodel"
 	dbops "isc.org/stork/server/database"
+	storkutil "isc.org/stork/util"
 )
 
 // A short for datamodel.AppType.
@@ -25,6 +26,10 @@ const (
 type AppMeta struct {
 	Version         string
 	ExtendedVersion string
+	// Indicates that the Kea Control Agent has no control sockets
+	// configured, i.e., the app has no DHCP or D2 daemons and monitors
+	// nothing.
+	NoDaemons bool
 }
 
 // Represents an app held in app table in the database.
@@ -37,6 +42,9 @@ type App struct {
 	Active    bool
 	Meta      AppMeta
 	Name      string
+	// Time when the app became active or inactive. It is maintained by
+	// the database when the active state changes.
+	ActiveChangedAt time.Time
 
 	AccessPoints []*AccessPoint `pg:"rel:has-many"`
 
@@ -340,6 +348,42 @@ func GetAppByID(dbi dbops.DBI, id int64) (*App, error) {
 	return &app, nil
 }
 
+// Represents an app continuously unreachable for a long time.
+type UnreachableApp struct {
+	*App
+	// Time elapsed since the app became unreachable.
+	Downtime time.Duration
+}
+
+// Returns the apps that have been continuously unreachable (inactive) for
+// longer than the threshold, e.g., to escalate the problem. The apps are
+// returned with their downtime, sorted from the longest unreachable one.
+func GetLongUnreachableApps(dbi dbops.DBI, threshold time.Duration) ([]UnreachableApp, error) {
+	now := storkutil.UTCNow()
+
+	var apps []*App
+	q := dbi.Model(&apps)
+	q = q.Relation("Machine")
+	q = q.Relation("AccessPoints")
+	q = q.Relation("Daemons")
+	q = q.Where("app.active IS NOT TRUE")
+	q = q.Where("app.active_changed_at <= ?", now.Add(-threshold))
+	q = q.OrderExpr("app.active_changed_at ASC, app.id ASC")
+	err := q.Select()
+	if err != nil {
+		return nil, pkgerrors.Wrapf(err, "problem getting apps unreachable for longer than %s", threshold)
+	}
+
+	unreachableApps := make([]UnreachableApp, 0, len(apps))
+	for _, app := range apps {
+		unreachableApps = append(unreachableApps, UnreachableApp{
+			App:      app,
+			Downtime: now.Sub(app.ActiveChangedAt),
+		})
+	}
+	return unreachableApps, nil
+}
+
 // Returns applications belonging to a machine with a given ID.
 func GetAppsByMachine(dbi dbops.DBI, machineID int64) ([]*App, error) {
 	var apps []*App
@@ -385,6 +429,31 @@ func GetAppsByType(dbi dbops.DBI, appType AppType) ([]App, error) {
 	return apps, nil
 }
 
+// Fetches the Kea apps running on the machines with the specified address
+// or having the access points bound to this address.
+func GetKeaAppsByAddress(dbi dbops.DBI, address string) ([]App, error) {
+	var apps []App
+
+	q := dbi.Model(&apps)
+	q = q.Relation("Machine")
+	q = q.Relation("AccessPoints")
+	q = q.Where("app.type = ?", AppTypeKea)
+	q = q.WhereGroup(func(qq *orm.Query) (*orm.Query, error) {
+		qq = qq.WhereOr("machine.address = ?", address)
+		qq = qq.WhereOr("EXISTS (SELECT 1 FROM access_point AS ap WHERE ap.app_id = app.id AND ap.address = ?)", address)
+		return qq, nil
+	})
+	q = q.OrderExpr("app.id ASC")
+	err := q.Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return []App{}, nil
+		}
+		return nil, pkgerrors.Wrapf(err, "problem getting Kea apps with the address %s", address)
+	}
+	return apps, nil
+}
+
 // Fetches a collection of apps from the database. The offset and
 // limit specify the beginning of the page and the maximum size of the
 // page. Limit has to be greater then 0, otherwise error is
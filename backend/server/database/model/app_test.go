@@ -966,6 +966,59 @@ func TestGetAppsByType(t *testing.T) {
 	require.NotNil(t, apps[0].Daemons[0].Bind9Daemon)
 }
 
+// Test that the Kea apps are found by the machine address or the access
+// point address.
+func TestGetKeaAppsByAddress(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	m := &Machine{
+		Address:   "192.0.2.1",
+		AgentPort: 8080,
+	}
+	err := AddMachine(db, m)
+	require.NoError(t, err)
+
+	var keaPoints []*AccessPoint
+	keaPoints = AppendAccessPoint(keaPoints, AccessPointControl, "192.0.2.2", "", 8000, false)
+	aKea := &App{
+		MachineID:    m.ID,
+		Type:         AppTypeKea,
+		AccessPoints: keaPoints,
+	}
+	_, err = AddApp(db, aKea)
+	require.NoError(t, err)
+
+	var bind9Points []*AccessPoint
+	bind9Points = AppendAccessPoint(bind9Points, AccessPointControl, "192.0.2.3", "", 953, false)
+	aBind9 := &App{
+		MachineID:    m.ID,
+		Type:         AppTypeBind9,
+		AccessPoints: bind9Points,
+	}
+	_, err = AddApp(db, aBind9)
+	require.NoError(t, err)
+
+	// Act
+	byMachine, errMachine := GetKeaAppsByAddress(db, "192.0.2.1")
+	byAccessPoint, errAccessPoint := GetKeaAppsByAddress(db, "192.0.2.2")
+	byBind9, errBind9 := GetKeaAppsByAddress(db, "192.0.2.3")
+
+	// Assert
+	require.NoError(t, errMachine)
+	require.Len(t, byMachine, 1)
+	require.Equal(t, aKea.ID, byMachine[0].ID)
+	require.NotNil(t, byMachine[0].Machine)
+
+	require.NoError(t, errAccessPoint)
+	require.Len(t, byAccessPoint, 1)
+	require.Equal(t, aKea.ID, byAccessPoint[0].ID)
+
+	require.NoError(t, errBind9)
+	require.Empty(t, byBind9)
+}
+
 // Check getting app by its ID.
 func TestGetAppByID(t *testing.T) {
 	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
@@ -1477,3 +1530,116 @@ func TestGetDaemonTagsKea(t *testing.T) {
 	require.EqualValues(t, 11, daemons[1].GetID())
 	require.Equal(t, AppTypeBind9, daemons[1].GetAppType())
 }
+
+// Test that the apps continuously unreachable for longer than the threshold
+// are returned with their downtime.
+func TestGetLongUnreachableApps(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	m := &Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	err := AddMachine(db, m)
+	require.NoError(t, err)
+
+	// The apps are inactive except the last one. The downtime is seeded
+	// by moving the time of the active state change back.
+	downtimes := []time.Duration{2 * time.Hour, 30 * time.Minute, 5 * time.Hour, 10 * time.Hour}
+	var apps []*App
+	for i, downtime := range downtimes {
+		var accessPoints []*AccessPoint
+		accessPoints = AppendAccessPoint(accessPoints, AccessPointControl, "", "", int64(1234+i), false)
+		app := &App{
+			MachineID:    m.ID,
+			Type:         AppTypeKea,
+			Name:         fmt.Sprintf("kea%d", i),
+			Active:       i == len(downtimes)-1,
+			AccessPoints: accessPoints,
+			Daemons: []*Daemon{
+				{
+					Name:      "dhcp4",
+					KeaDaemon: &KeaDaemon{},
+				},
+			},
+		}
+		_, err = AddApp(db, app)
+		require.NoError(t, err)
+		_, err = db.Exec("UPDATE app SET active_changed_at = ? WHERE id = ?",
+			time.Now().UTC().Add(-downtime), app.ID)
+		require.NoError(t, err)
+		apps = append(apps, app)
+	}
+
+	// Act
+	unreachableApps, err := GetLongUnreachableApps(db, time.Hour)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, unreachableApps, 2)
+
+	require.Equal(t, apps[2].ID, unreachableApps[0].ID)
+	require.NotNil(t, unreachableApps[0].Machine)
+	require.Len(t, unreachableApps[0].Daemons, 1)
+	require.InDelta(t, (5 * time.Hour).Seconds(), unreachableApps[0].Downtime.Seconds(), 60)
+
+	require.Equal(t, apps[0].ID, unreachableApps[1].ID)
+	require.InDelta(t, (2 * time.Hour).Seconds(), unreachableApps[1].Downtime.Seconds(), 60)
+
+	// The lower threshold includes the app unreachable for 30 minutes.
+	unreachableApps, err = GetLongUnreachableApps(db, 10*time.Minute)
+	require.NoError(t, err)
+	require.Len(t, unreachableApps, 3)
+	require.Equal(t, apps[1].ID, unreachableApps[2].ID)
+}
+
+// Test that the time of the active state change is updated only when the
+// app becomes active or inactive.
+func TestAppActiveChangedAt(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	m := &Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	err := AddMachine(db, m)
+	require.NoError(t, err)
+
+	var accessPoints []*AccessPoint
+	accessPoints = AppendAccessPoint(accessPoints, AccessPointControl, "", "", 1234, false)
+	app := &App{
+		MachineID:    m.ID,
+		Type:         AppTypeKea,
+		Active:       true,
+		AccessPoints: accessPoints,
+	}
+	_, err = AddApp(db, app)
+	require.NoError(t, err)
+
+	changedAt := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	_, err = db.Exec("UPDATE app SET active_changed_at = ? WHERE id = ?", changedAt, app.ID)
+	require.NoError(t, err)
+
+	// Act & Assert
+	// The state doesn't change.
+	app, err = GetAppByID(db, app.ID)
+	require.NoError(t, err)
+	app.Name = "kea-server"
+	_, _, err = UpdateApp(db, app)
+	require.NoError(t, err)
+	app, err = GetAppByID(db, app.ID)
+	require.NoError(t, err)
+	require.True(t, changedAt.Equal(app.ActiveChangedAt))
+
+	// The app becomes inactive.
+	app.Active = false
+	_, _, err = UpdateApp(db, app)
+	require.NoError(t, err)
+	app, err = GetAppByID(db, app.ID)
+	require.NoError(t, err)
+	require.True(t, app.ActiveChangedAt.After(changedAt))
+}
@@ -32,6 +32,15 @@ type KeaDHCPDaemonStats struct {
 	RPS2 int `pg:"rps2"`
 }
 
+// Formats of the lease statistics returned by the Kea stat_cmds hook
+// library. Kea 1.6 returns the misspelled total-addreses,
+// assigned-addreses and declined-addreses columns. They are spelled
+// correctly since Kea 1.8.
+const (
+	KeaStatsFormat16 = "1.6"
+	KeaStatsFormat18 = "1.8"
+)
+
 // A structure holding Kea DHCP specific information about a daemon. It
 // reflects the kea_dhcp_daemon table which extends the daemon and
 // kea_daemon tables with the Kea DHCPv4 or DHCPv6 specific information.
@@ -40,6 +49,18 @@ type KeaDHCPDaemon struct {
 	ID          int64
 	KeaDaemonID int64
 	Stats       KeaDHCPDaemonStats
+	// Format of the lease statistics returned by the daemon. It is empty
+	// until it is detected.
+	StatsFormat string
+}
+
+// Connection parameters of a configuration backend database used by
+// the Kea daemon. The credentials are deliberately not included.
+type KeaConfigDatabase struct {
+	Type string `json:"type"`
+	Host string `json:"host,omitempty"`
+	Port int64  `json:"port,omitempty"`
+	Name string `json:"name"`
 }
 
 // A structure holding common information for all Kea daemons. It
@@ -48,7 +69,30 @@ type KeaDaemon struct {
 	ID         int64
 	Config     *KeaConfig `pg:",use_zero"`
 	ConfigHash string
-	DaemonID   int64
+	// The configuration hash returned by the daemon in response to the
+	// config-hash-get command. It is empty if the daemon doesn't support
+	// this command.
+	KeaConfigHash   string
+	ServerTag       string
+	ConfigStructure string
+	// The interfaces-config settings used to troubleshoot the issues
+	// with receiving the packets by the DHCP servers. The re-detect
+	// is nil if it is not specified in the configuration.
+	InterfacesReDetect *bool
+	DHCPSocketType     string
+	OutboundInterface  string
+	// The identifier expression of the flex_id hook library used to
+	// match the flex-id host reservations.
+	FlexIDExpression string
+	// The configuration backend databases specified in the config-control
+	// of the DHCP daemon configuration. It allows for checking whether all
+	// daemons use the same configuration backend.
+	ConfigDatabases []KeaConfigDatabase
+	// The configuration fetched when the daemon was added to Stork. It is
+	// the baseline for detecting the configuration changes since then.
+	BaselineConfig     *KeaConfig
+	BaselineConfigHash string
+	DaemonID           int64
 
 	KeaDHCPDaemon *KeaDHCPDaemon `pg:"rel:belongs-to"`
 }
@@ -284,6 +328,24 @@ func GetKeaDHCPDaemons(dbi pg.DBI) (daemons []Daemon, err error) {
 	return
 }
 
+// Get all Kea daemons having the configurations stored in the database.
+// The returned daemons include the relations required by the config review.
+func GetKeaDaemonsWithConfigs(dbi pg.DBI) (daemons []*Daemon, err error) {
+	err = dbi.Model(&daemons).
+		Relation("App.AccessPoints").
+		Relation("App.Machine").
+		Relation("KeaDaemon").
+		Where("kea_daemon.config IS NOT NULL").
+		OrderExpr("daemon.id ASC").
+		Select()
+	if errors.Is(err, pg.ErrNoRows) {
+		err = nil
+	} else {
+		err = pkgerrors.Wrapf(err, "problem with getting Kea daemons with configurations")
+	}
+	return
+}
+
 // Select one or more daemons for update. The main use case for this function is
 // to prevent modifications and deletions of the daemons while the server inserts
 // config reports for them. It must be called within a transaction and the selected
@@ -457,6 +519,18 @@ func UpdateDaemon(dbi dbops.DBI, daemon *Daemon) error {
 	return updateDaemon(dbi.(*pg.Tx), daemon)
 }
 
+// Updates the format of the lease statistics returned by the Kea DHCP
+// daemon. Other daemon's data are not modified.
+func UpdateKeaDHCPDaemonStatsFormat(dbi dbops.DBI, keaDHCPDaemon *KeaDHCPDaemon) error {
+	result, err := dbi.Model(keaDHCPDaemon).Column("stats_format").WherePK().Update()
+	if err != nil {
+		return pkgerrors.Wrapf(err, "problem updating the stats format of the Kea DHCP daemon %d", keaDHCPDaemon.ID)
+	} else if result.RowsAffected() <= 0 {
+		return pkgerrors.Wrapf(ErrNotExists, "Kea DHCP daemon with ID %d does not exist", keaDHCPDaemon.ID)
+	}
+	return nil
+}
+
 // This is a hook to go-pg that is called just after reading rows from database.
 // It reconverts KeaDaemon's configuration from json string maps to the
 // expected structure in GO.
@@ -522,6 +596,37 @@ func (d *Daemon) SetConfigWithHash(config *KeaConfig, configHash string) error {
 		}
 		d.KeaDaemon.Config = config
 		d.KeaDaemon.ConfigHash = configHash
+		// The first configuration set for the daemon becomes its baseline.
+		if d.KeaDaemon.BaselineConfig == nil {
+			d.KeaDaemon.BaselineConfig = config
+			d.KeaDaemon.BaselineConfigHash = configHash
+		}
+		d.KeaDaemon.ServerTag = config.GetServerTag()
+		d.KeaDaemon.ConfigStructure = string(config.GetConfigStructure())
+		d.KeaDaemon.FlexIDExpression, _ = config.GetFlexIDIdentifierExpression()
+		d.KeaDaemon.ConfigDatabases = nil
+		if configControl := config.GetConfigControl(); configControl != nil {
+			for _, database := range configControl.ConfigDatabases {
+				d.KeaDaemon.ConfigDatabases = append(d.KeaDaemon.ConfigDatabases, KeaConfigDatabase{
+					Type: database.Type,
+					Host: database.Host,
+					Port: database.Port,
+					Name: database.Name,
+				})
+			}
+		}
+		d.KeaDaemon.InterfacesReDetect = nil
+		d.KeaDaemon.DHCPSocketType = ""
+		d.KeaDaemon.OutboundInterface = ""
+		if interfacesConfig := config.GetInterfacesConfig(); interfacesConfig != nil {
+			d.KeaDaemon.InterfacesReDetect = interfacesConfig.ReDetect
+			if interfacesConfig.DHCPSocketType != nil {
+				d.KeaDaemon.DHCPSocketType = *interfacesConfig.DHCPSocketType
+			}
+			if interfacesConfig.OutboundInterface != nil {
+				d.KeaDaemon.OutboundInterface = *interfacesConfig.OutboundInterface
+			}
+		}
 	}
 	return nil
 }
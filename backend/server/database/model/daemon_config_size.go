@@ -0,0 +1,58 @@
+package dbmodel
+
+import (
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	dbops "isc.org/stork/server/database"
+)
+
+// The maximum number of the configuration sizes kept for a daemon. The
+// oldest sizes are deleted when a new size is recorded.
+const MaxDaemonConfigSizeHistory = 30
+
+// A size of the daemon configuration recorded when the configuration was
+// committed. The sizes are used to show the configuration growth trends.
+type DaemonConfigSize struct {
+	ID               int64
+	DaemonID         int64
+	RecordedAt       time.Time
+	SubnetCount      int64 `pg:",use_zero"`
+	ReservationCount int64 `pg:",use_zero"`
+}
+
+// Adds the daemon configuration size and deletes the oldest sizes of the
+// daemon exceeding the MaxDaemonConfigSizeHistory limit.
+func AddDaemonConfigSize(dbi dbops.DBI, size *DaemonConfigSize) error {
+	_, err := dbi.Model(size).Insert()
+	if err != nil {
+		return pkgerrors.Wrapf(err, "problem adding configuration size of daemon %d", size.DaemonID)
+	}
+	_, err = dbi.Exec(`
+		DELETE FROM daemon_config_size
+		WHERE daemon_id = ? AND id NOT IN (
+			SELECT id FROM daemon_config_size
+			WHERE daemon_id = ?
+			ORDER BY recorded_at DESC, id DESC
+			LIMIT ?
+		)`, size.DaemonID, size.DaemonID, MaxDaemonConfigSizeHistory)
+	if err != nil {
+		return pkgerrors.Wrapf(err, "problem deleting old configuration sizes of daemon %d", size.DaemonID)
+	}
+	return nil
+}
+
+// Returns the configuration sizes of the daemon ordered by the recording
+// time.
+func GetDaemonConfigSizes(dbi dbops.DBI, daemonID int64) ([]DaemonConfigSize, error) {
+	sizes := []DaemonConfigSize{}
+	err := dbi.Model(&sizes).
+		Where("daemon_id = ?", daemonID).
+		OrderExpr("recorded_at ASC").
+		OrderExpr("id ASC").
+		Select()
+	if err != nil {
+		return nil, pkgerrors.Wrapf(err, "problem getting configuration sizes of daemon %d", daemonID)
+	}
+	return sizes, nil
+}
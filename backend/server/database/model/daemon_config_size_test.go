@@ -0,0 +1,100 @@
+package dbmodel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	dbops "isc.org/stork/server/database"
+	dbtest "isc.org/stork/server/database/test"
+)
+
+// Adds a machine and a Kea app with the DHCPv4 daemon for the daemon
+// configuration size tests.
+func addDaemonConfigSizeTestDaemon(t *testing.T, db *dbops.PgDB) *Daemon {
+	machine := &Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	err := AddMachine(db, machine)
+	require.NoError(t, err)
+
+	app := &App{
+		MachineID: machine.ID,
+		Type:      AppTypeKea,
+		Daemons: []*Daemon{
+			NewKeaDaemon(DaemonNameDHCPv4, true),
+		},
+	}
+	_, err = AddApp(db, app)
+	require.NoError(t, err)
+	return app.Daemons[0]
+}
+
+// Test that the daemon configuration sizes are added and returned in
+// the order of the recording time.
+func TestAddDaemonConfigSize(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addDaemonConfigSizeTestDaemon(t, db)
+	now := time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)
+
+	// Act
+	err1 := AddDaemonConfigSize(db, &DaemonConfigSize{
+		DaemonID:         daemon.ID,
+		RecordedAt:       now,
+		SubnetCount:      20,
+		ReservationCount: 200,
+	})
+	err2 := AddDaemonConfigSize(db, &DaemonConfigSize{
+		DaemonID:   daemon.ID,
+		RecordedAt: now.Add(-time.Hour),
+	})
+
+	// Assert
+	require.NoError(t, err1)
+	require.NoError(t, err2)
+
+	sizes, err := GetDaemonConfigSizes(db, daemon.ID)
+	require.NoError(t, err)
+	require.Len(t, sizes, 2)
+	require.Equal(t, now.Add(-time.Hour), sizes[0].RecordedAt)
+	require.Zero(t, sizes[0].SubnetCount)
+	require.Zero(t, sizes[0].ReservationCount)
+	require.Equal(t, now, sizes[1].RecordedAt)
+	require.EqualValues(t, 20, sizes[1].SubnetCount)
+	require.EqualValues(t, 200, sizes[1].ReservationCount)
+
+	sizes, err = GetDaemonConfigSizes(db, daemon.ID+1)
+	require.NoError(t, err)
+	require.Empty(t, sizes)
+}
+
+// Test that only the most recent daemon configuration sizes are kept.
+func TestAddDaemonConfigSizeHistoryLimit(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	daemon := addDaemonConfigSizeTestDaemon(t, db)
+	now := time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)
+
+	// Act
+	for i := 0; i < MaxDaemonConfigSizeHistory+5; i++ {
+		err := AddDaemonConfigSize(db, &DaemonConfigSize{
+			DaemonID:    daemon.ID,
+			RecordedAt:  now.Add(time.Duration(i) * time.Minute),
+			SubnetCount: int64(i),
+		})
+		require.NoError(t, err)
+	}
+
+	// Assert
+	sizes, err := GetDaemonConfigSizes(db, daemon.ID)
+	require.NoError(t, err)
+	require.Len(t, sizes, MaxDaemonConfigSizeHistory)
+	require.EqualValues(t, 5, sizes[0].SubnetCount)
+	require.EqualValues(t, MaxDaemonConfigSizeHistory+4, sizes[len(sizes)-1].SubnetCount)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/go-pg/pg/v10"
 	require "github.com/stretchr/testify/require"
 	dbtest "isc.org/stork/server/database/test"
+	storkutil "isc.org/stork/util"
 )
 
 // Test that new instance of the generic Kea daemon can be created.
@@ -353,6 +354,60 @@ func TestGetKeaDHCPDaemons(t *testing.T) {
 	require.Contains(t, names, DaemonNameDHCPv6)
 }
 
+// Test getting all Kea daemons having the configurations.
+func TestGetKeaDaemonsWithConfigs(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	// No daemons in the database.
+	daemons, err := GetKeaDaemonsWithConfigs(db)
+	require.NoError(t, err)
+	require.Empty(t, daemons)
+
+	m := &Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	err = AddMachine(db, m)
+	require.NoError(t, err)
+
+	config, err := NewKeaConfigFromJSON(`{"Dhcp4": { }}`)
+	require.NoError(t, err)
+
+	// Add Kea app with one daemon having a configuration and
+	// another one lacking it.
+	app := &App{
+		MachineID: m.ID,
+		Type:      AppTypeKea,
+		Daemons: []*Daemon{
+			{
+				Name:   DaemonNameDHCPv4,
+				Active: true,
+				KeaDaemon: &KeaDaemon{
+					Config: config,
+				},
+			},
+			{
+				Name:      DaemonNameDHCPv6,
+				Active:    true,
+				KeaDaemon: &KeaDaemon{},
+			},
+		},
+	}
+	_, err = AddApp(db, app)
+	require.NoError(t, err)
+
+	// Only the daemon with the configuration should be returned.
+	daemons, err = GetKeaDaemonsWithConfigs(db)
+	require.NoError(t, err)
+	require.Len(t, daemons, 1)
+	require.Equal(t, DaemonNameDHCPv4, daemons[0].Name)
+	require.NotNil(t, daemons[0].KeaDaemon)
+	require.NotNil(t, daemons[0].KeaDaemon.Config)
+	require.NotNil(t, daemons[0].App)
+	require.NotNil(t, daemons[0].App.Machine)
+}
+
 // Test selecting BIND9 daemon by ID for update which should result in locking
 // the daemon information until the transaction is committed or rolled back.
 func TestGetBind9DaemonsForUpdate(t *testing.T) {
@@ -782,6 +837,408 @@ func TestSetConfigFromJSONWithHash(t *testing.T) {
 	require.Equal(t, "f1c994d55b6f4edba9568d89ce2a804a", daemon.KeaDaemon.ConfigHash)
 }
 
+// Test that the server tag is extracted from the daemon configuration and
+// updated when the configuration changes.
+func TestSetConfigServerTag(t *testing.T) {
+	daemon1 := NewKeaDaemon("kea-dhcp4", true)
+	daemon2 := NewKeaDaemon("kea-dhcp4", true)
+
+	err := daemon1.SetConfigFromJSON(`{ "Dhcp4": { "server-tag": "server1" } }`)
+	require.NoError(t, err)
+	err = daemon2.SetConfigFromJSON(`{ "Dhcp4": { "server-tag": "server2" } }`)
+	require.NoError(t, err)
+
+	require.Equal(t, "server1", daemon1.KeaDaemon.ServerTag)
+	require.Equal(t, "server2", daemon2.KeaDaemon.ServerTag)
+
+	// The server tag is removed from the configuration.
+	err = daemon1.SetConfigFromJSON(`{ "Dhcp4": { } }`)
+	require.NoError(t, err)
+	require.Empty(t, daemon1.KeaDaemon.ServerTag)
+}
+
+// Test that the configuration structure variant is detected for the
+// old-style and new-style configurations.
+func TestSetConfigStructure(t *testing.T) {
+	daemon := NewKeaDaemon("kea-dhcp4", true)
+
+	err := daemon.SetConfigFromJSON(`{
+		"Dhcp4": { },
+		"Logging": {
+			"loggers": [
+				{
+					"name": "kea-dhcp4",
+					"output_options": [ { "output": "stdout" } ],
+					"severity": "INFO"
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+	require.Equal(t, "legacy", daemon.KeaDaemon.ConfigStructure)
+	require.Len(t, daemon.LogTargets, 1)
+	require.Equal(t, "stdout", daemon.LogTargets[0].Output)
+
+	err = daemon.SetConfigFromJSON(`{
+		"Dhcp4": {
+			"loggers": [
+				{
+					"name": "kea-dhcp4",
+					"output_options": [ { "output": "stdout" } ],
+					"severity": "INFO"
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+	require.Equal(t, "current", daemon.KeaDaemon.ConfigStructure)
+	require.Len(t, daemon.LogTargets, 1)
+}
+
+// Test that the interfaces-config socket settings are extracted from the
+// daemon configuration for different configurations.
+func TestSetConfigInterfacesConfig(t *testing.T) {
+	testCases := []struct {
+		name              string
+		config            string
+		reDetect          *bool
+		dhcpSocketType    string
+		outboundInterface string
+	}{
+		{
+			name: "all set",
+			config: `{ "Dhcp4": { "interfaces-config": {
+				"interfaces": [ "eth0" ],
+				"re-detect": false,
+				"dhcp-socket-type": "udp",
+				"outbound-interface": "use-routing"
+			} } }`,
+			reDetect:          storkutil.Ptr(false),
+			dhcpSocketType:    "udp",
+			outboundInterface: "use-routing",
+		},
+		{
+			name: "raw socket",
+			config: `{ "Dhcp4": { "interfaces-config": {
+				"re-detect": true,
+				"dhcp-socket-type": "raw"
+			} } }`,
+			reDetect:       storkutil.Ptr(true),
+			dhcpSocketType: "raw",
+		},
+		{
+			name:     "DHCPv6",
+			config:   `{ "Dhcp6": { "interfaces-config": { "re-detect": true } } }`,
+			reDetect: storkutil.Ptr(true),
+		},
+		{
+			name:   "no interfaces config",
+			config: `{ "Dhcp4": { } }`,
+		},
+		{
+			name:   "no socket settings",
+			config: `{ "Dhcp4": { "interfaces-config": { "interfaces": [ "*" ] } } }`,
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			// Arrange
+			daemon := NewKeaDaemon("kea-dhcp4", true)
+			// Ensure the settings of the previous configuration are cleared.
+			err := daemon.SetConfigFromJSON(`{ "Dhcp4": { "interfaces-config": {
+				"re-detect": true,
+				"dhcp-socket-type": "raw",
+				"outbound-interface": "same-as-inbound"
+			} } }`)
+			require.NoError(t, err)
+
+			// Act
+			err = daemon.SetConfigFromJSON(testCase.config)
+
+			// Assert
+			require.NoError(t, err)
+			require.Equal(t, testCase.reDetect, daemon.KeaDaemon.InterfacesReDetect)
+			require.Equal(t, testCase.dhcpSocketType, daemon.KeaDaemon.DHCPSocketType)
+			require.Equal(t, testCase.outboundInterface, daemon.KeaDaemon.OutboundInterface)
+		})
+	}
+}
+
+// Test that the flex_id hook library's identifier expression is extracted
+// from the daemon configuration and the flex-id reservations are detected.
+func TestSetConfigFlexIDExpression(t *testing.T) {
+	daemon := NewKeaDaemon("kea-dhcp4", true)
+
+	err := daemon.SetConfigFromJSON(`{
+		"Dhcp4": {
+			"hooks-libraries": [
+				{
+					"library": "/usr/lib/kea/hooks/libdhcp_flex_id.so",
+					"parameters": {
+						"identifier-expression": "substring(relay4[0].option[18].hex,0,8)"
+					}
+				}
+			],
+			"subnet4": [
+				{
+					"id": 1,
+					"subnet": "192.0.2.0/24",
+					"reservations": [
+						{
+							"flex-id": "'port1234'",
+							"ip-address": "192.0.2.10"
+						}
+					]
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+	require.Equal(t, "substring(relay4[0].option[18].hex,0,8)", daemon.KeaDaemon.FlexIDExpression)
+
+	subnets := daemon.KeaDaemon.Config.GetSubnets()
+	require.Len(t, subnets, 1)
+	subnet, err := NewSubnetFromKea(subnets[0], daemon, HostDataSourceConfig, NewDHCPOptionDefinitionLookup())
+	require.NoError(t, err)
+	require.Len(t, subnet.Hosts, 1)
+	require.Len(t, subnet.Hosts[0].HostIdentifiers, 1)
+	require.Equal(t, "flex-id", subnet.Hosts[0].HostIdentifiers[0].Type)
+	require.Equal(t, []byte("port1234"), subnet.Hosts[0].HostIdentifiers[0].Value)
+
+	// The expression is removed with the hook library.
+	err = daemon.SetConfigFromJSON(`{ "Dhcp4": { } }`)
+	require.NoError(t, err)
+	require.Empty(t, daemon.KeaDaemon.FlexIDExpression)
+}
+
+// Test that the interfaces-config socket settings are stored in the database.
+func TestInterfacesConfigStored(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	m := &Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	err := AddMachine(db, m)
+	require.NoError(t, err)
+
+	daemon4 := NewKeaDaemon("kea-dhcp4", true)
+	err = daemon4.SetConfigFromJSON(`{ "Dhcp4": { "interfaces-config": {
+		"re-detect": false,
+		"dhcp-socket-type": "udp",
+		"outbound-interface": "use-routing"
+	} } }`)
+	require.NoError(t, err)
+	daemon6 := NewKeaDaemon("kea-dhcp6", true)
+	err = daemon6.SetConfigFromJSON(`{ "Dhcp6": { } }`)
+	require.NoError(t, err)
+
+	accessPoints := []*AccessPoint{}
+	accessPoints = AppendAccessPoint(accessPoints, AccessPointControl, "", "", 1234, false)
+	app := &App{
+		MachineID:    m.ID,
+		Type:         AppTypeKea,
+		Daemons:      []*Daemon{daemon4, daemon6},
+		AccessPoints: accessPoints,
+	}
+
+	// Act
+	_, err = AddApp(db, app)
+	require.NoError(t, err)
+	returned4, err4 := GetDaemonByID(db, app.Daemons[0].ID)
+	returned6, err6 := GetDaemonByID(db, app.Daemons[1].ID)
+
+	// Assert
+	require.NoError(t, err4)
+	require.NotNil(t, returned4.KeaDaemon.InterfacesReDetect)
+	require.False(t, *returned4.KeaDaemon.InterfacesReDetect)
+	require.Equal(t, "udp", returned4.KeaDaemon.DHCPSocketType)
+	require.Equal(t, "use-routing", returned4.KeaDaemon.OutboundInterface)
+
+	require.NoError(t, err6)
+	require.Nil(t, returned6.KeaDaemon.InterfacesReDetect)
+	require.Empty(t, returned6.KeaDaemon.DHCPSocketType)
+	require.Empty(t, returned6.KeaDaemon.OutboundInterface)
+}
+
+// Test that the configuration backend databases are extracted from the
+// daemon configuration without the credentials.
+func TestSetConfigConfigDatabases(t *testing.T) {
+	daemon := NewKeaDaemon("kea-dhcp4", true)
+
+	err := daemon.SetConfigFromJSON(`{
+		"Dhcp4": {
+			"config-control": {
+				"config-databases": [
+					{
+						"type": "mysql",
+						"name": "kea-cb",
+						"host": "cb.example.org",
+						"port": 3306,
+						"user": "kea",
+						"password": "secret"
+					}
+				]
+			}
+		}
+	}`)
+	require.NoError(t, err)
+	require.Len(t, daemon.KeaDaemon.ConfigDatabases, 1)
+	require.Equal(t, KeaConfigDatabase{
+		Type: "mysql",
+		Host: "cb.example.org",
+		Port: 3306,
+		Name: "kea-cb",
+	}, daemon.KeaDaemon.ConfigDatabases[0])
+
+	// The databases are removed with the config-control.
+	err = daemon.SetConfigFromJSON(`{ "Dhcp4": { } }`)
+	require.NoError(t, err)
+	require.Empty(t, daemon.KeaDaemon.ConfigDatabases)
+}
+
+// Test that the configuration backend databases are stored in the database
+// without the credentials.
+func TestConfigDatabasesStored(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	m := &Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	err := AddMachine(db, m)
+	require.NoError(t, err)
+
+	daemon4 := NewKeaDaemon("kea-dhcp4", true)
+	err = daemon4.SetConfigFromJSON(`{ "Dhcp4": { "config-control": {
+		"config-databases": [
+			{
+				"type": "postgresql",
+				"name": "kea-cb",
+				"host": "cb.example.org",
+				"port": 5432,
+				"user": "kea",
+				"password": "secret"
+			}
+		]
+	} } }`)
+	require.NoError(t, err)
+	daemon6 := NewKeaDaemon("kea-dhcp6", true)
+	err = daemon6.SetConfigFromJSON(`{ "Dhcp6": { } }`)
+	require.NoError(t, err)
+
+	accessPoints := []*AccessPoint{}
+	accessPoints = AppendAccessPoint(accessPoints, AccessPointControl, "", "", 1234, false)
+	app := &App{
+		MachineID:    m.ID,
+		Type:         AppTypeKea,
+		Daemons:      []*Daemon{daemon4, daemon6},
+		AccessPoints: accessPoints,
+	}
+
+	// Act
+	_, err = AddApp(db, app)
+	require.NoError(t, err)
+	returned4, err4 := GetDaemonByID(db, app.Daemons[0].ID)
+	returned6, err6 := GetDaemonByID(db, app.Daemons[1].ID)
+
+	var rawDatabases string
+	_, errRaw := db.QueryOne(pg.Scan(&rawDatabases),
+		"SELECT config_databases::text FROM kea_daemon WHERE id = ?", returned4.KeaDaemon.ID)
+
+	// Assert
+	require.NoError(t, err4)
+	require.Equal(t, []KeaConfigDatabase{
+		{
+			Type: "postgresql",
+			Host: "cb.example.org",
+			Port: 5432,
+			Name: "kea-cb",
+		},
+	}, returned4.KeaDaemon.ConfigDatabases)
+
+	require.NoError(t, err6)
+	require.Empty(t, returned6.KeaDaemon.ConfigDatabases)
+
+	require.NoError(t, errRaw)
+	require.NotContains(t, rawDatabases, "secret")
+	require.NotContains(t, rawDatabases, "password")
+	require.NotContains(t, rawDatabases, "user")
+}
+
+// Test that the first configuration set for the daemon becomes its
+// baseline and is not replaced by the subsequent configurations.
+func TestSetConfigBaseline(t *testing.T) {
+	daemon := NewKeaDaemon("kea-dhcp4", true)
+	require.Nil(t, daemon.KeaDaemon.BaselineConfig)
+
+	err := daemon.SetConfigFromJSON(`{ "Dhcp4": { "valid-lifetime": 1000 } }`)
+	require.NoError(t, err)
+	require.NotNil(t, daemon.KeaDaemon.BaselineConfig)
+	baselineHash := daemon.KeaDaemon.ConfigHash
+	require.NotEmpty(t, baselineHash)
+	require.Equal(t, baselineHash, daemon.KeaDaemon.BaselineConfigHash)
+
+	err = daemon.SetConfigFromJSON(`{ "Dhcp4": { "valid-lifetime": 2000 } }`)
+	require.NoError(t, err)
+	require.NotEqual(t, baselineHash, daemon.KeaDaemon.ConfigHash)
+	require.Equal(t, baselineHash, daemon.KeaDaemon.BaselineConfigHash)
+	require.EqualValues(t, 1000, *daemon.KeaDaemon.BaselineConfig.GetValidLifetimeParameters().ValidLifetime)
+	require.EqualValues(t, 2000, *daemon.KeaDaemon.Config.GetValidLifetimeParameters().ValidLifetime)
+}
+
+// Test that the baseline configuration is stored in the database and
+// preserved when the daemon configuration is updated.
+func TestBaselineConfigStored(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	m := &Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	err := AddMachine(db, m)
+	require.NoError(t, err)
+
+	daemon := NewKeaDaemon("kea-dhcp4", true)
+	err = daemon.SetConfigFromJSON(`{ "Dhcp4": { "valid-lifetime": 1000 } }`)
+	require.NoError(t, err)
+
+	accessPoints := []*AccessPoint{}
+	accessPoints = AppendAccessPoint(accessPoints, AccessPointControl, "", "", 1234, false)
+	app := &App{
+		MachineID:    m.ID,
+		Type:         AppTypeKea,
+		Daemons:      []*Daemon{daemon},
+		AccessPoints: accessPoints,
+	}
+	_, err = AddApp(db, app)
+	require.NoError(t, err)
+
+	returned, err := GetDaemonByID(db, app.Daemons[0].ID)
+	require.NoError(t, err)
+	err = returned.SetConfigFromJSON(`{ "Dhcp4": { "valid-lifetime": 2000 } }`)
+	require.NoError(t, err)
+
+	// Act
+	err = UpdateDaemon(db, returned)
+	require.NoError(t, err)
+	returned, err = GetDaemonByID(db, app.Daemons[0].ID)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, returned.KeaDaemon.BaselineConfig)
+	require.EqualValues(t, 1000, *returned.KeaDaemon.BaselineConfig.GetValidLifetimeParameters().ValidLifetime)
+	require.Equal(t, daemon.KeaDaemon.ConfigHash, returned.KeaDaemon.BaselineConfigHash)
+	require.EqualValues(t, 2000, *returned.KeaDaemon.Config.GetValidLifetimeParameters().ValidLifetime)
+}
+
 // Test that SetConfig does not set hash for the config.
 func TestSetConfig(t *testing.T) {
 	daemon := NewKeaDaemon("kea-dhcp4", true)
@@ -32,6 +32,21 @@ func (t EventLevel) String() string {
 	}
 }
 
+// Parses the event level from its human-readable representation, i.e.,
+// info, warning or error.
+func ParseEventLevel(level string) (EventLevel, error) {
+	switch level {
+	case EvInfo.String():
+		return EvInfo, nil
+	case EvWarning.String():
+		return EvWarning, nil
+	case EvError.String():
+		return EvError, nil
+	default:
+		return EvInfo, pkgerrors.Errorf("unknown event severity '%s'", level)
+	}
+}
+
 // Relations between the event and other entities.
 type Relations struct {
 	MachineID int64 `json:",omitempty"`
@@ -113,3 +128,35 @@ func GetEventsByPage(db *pg.DB, offset int64, limit int64, level EventLevel, dae
 	}
 	return events, int64(total), nil
 }
+
+// Criteria selecting the events. The nil time boundaries are ignored. The
+// level indicates the lowest level of the selected events.
+type EventFilter struct {
+	From  *time.Time
+	To    *time.Time
+	Level EventLevel
+}
+
+// Fetches up to limit events with the IDs greater than afterID matching
+// the filter. The events are ordered by ID, so the function can be called
+// repeatedly with the ID of the last returned event to iterate over all
+// events without loading them into memory at once.
+func GetEventsAfterID(db *pg.DB, afterID int64, limit int64, filter EventFilter) ([]Event, error) {
+	events := []Event{}
+	q := db.Model(&events).Where("id > ?", afterID)
+	if filter.From != nil {
+		q = q.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		q = q.Where("created_at <= ?", *filter.To)
+	}
+	if filter.Level > 0 {
+		q = q.Where("level >= ?", filter.Level)
+	}
+	q = q.OrderExpr("id ASC").Limit(int(limit))
+	err := q.Select()
+	if err != nil && !errors.Is(err, pg.ErrNoRows) {
+		return nil, pkgerrors.Wrapf(err, "problem getting events after ID %d", afterID)
+	}
+	return events, nil
+}
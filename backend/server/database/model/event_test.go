@@ -175,3 +175,14 @@ func TestConvertLevelToString(t *testing.T) {
 	require.EqualValues(t, "error", EvError.String())
 	require.EqualValues(t, "unknown", EventLevel(42).String())
 }
+
+// Test that the event level is parsed from the human-readable form.
+func TestParseEventLevel(t *testing.T) {
+	for _, level := range []EventLevel{EvInfo, EvWarning, EvError} {
+		parsed, err := ParseEventLevel(level.String())
+		require.NoError(t, err)
+		require.Equal(t, level, parsed)
+	}
+	_, err := ParseEventLevel("unknown")
+	require.Error(t, err)
+}
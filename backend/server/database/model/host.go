@@ -618,6 +618,8 @@ func DeleteOrphanedHosts(dbi dbops.DBI) (int64, error) {
 // must already include associations with the daemons and other information
 // specific to daemons, e.g., DHCP options.
 func commitHostsIntoDB(dbi dbops.DBI, hosts []Host, subnetID int64, daemon *Daemon) (err error) {
+	var existingHosts []Host
+	existingHostsFetched := false
 	for i := range hosts {
 		hosts[i].SubnetID = subnetID
 		for j := range hosts[i].LocalHosts {
@@ -625,6 +627,20 @@ func commitHostsIntoDB(dbi dbops.DBI, hosts []Host, subnetID int64, daemon *Daem
 				hosts[i].LocalHosts[j].DaemonID = daemon.ID
 			}
 		}
+		if hosts[i].ID == 0 {
+			// The host may already exist in the database but be associated
+			// with the daemon using a different data source. It happens when
+			// the reservation is moved between the configuration file and the
+			// host database. Reconcile it to avoid duplicates.
+			if !existingHostsFetched {
+				existingHosts, err = GetHostsBySubnetID(dbi, subnetID)
+				if err != nil {
+					return err
+				}
+				existingHostsFetched = true
+			}
+			existingHosts = reconcileHostDataSource(&hosts[i], existingHosts, daemon.ID)
+		}
 		if hosts[i].ID == 0 {
 			err = AddHost(dbi, &hosts[i])
 			if err != nil {
@@ -645,6 +661,40 @@ func commitHostsIntoDB(dbi dbops.DBI, hosts []Host, subnetID int64, daemon *Daem
 	return nil
 }
 
+// Searches the existing hosts for a host having a common identifier with
+// the new host and associated with the daemon using a different data source
+// than the new host. If such a host is found, the data source of the
+// reservation has changed (e.g., it has been moved from the configuration
+// file to the host database). The new host takes over the ID of the found
+// host, so the host is updated rather than duplicated. If the found host is
+// shared with other daemons, their associations with the host are merged
+// into the new host. The association of this daemon with the host is
+// replaced with the new data source when the host is committed. It returns
+// the existing hosts without the found host.
+func reconcileHostDataSource(host *Host, existingHosts []Host, daemonID int64) []Host {
+	localHost := host.GetLocalHost(daemonID)
+	if localHost == nil {
+		return existingHosts
+	}
+	for i := range existingHosts {
+		existingLocalHost := existingHosts[i].GetLocalHost(daemonID)
+		if existingLocalHost == nil || existingLocalHost.DataSource == localHost.DataSource {
+			continue
+		}
+		if !existingHosts[i].HasCommonIdentifier(host) {
+			continue
+		}
+		host.ID = existingHosts[i].ID
+		for _, lh := range existingHosts[i].LocalHosts {
+			if lh.DaemonID != daemonID {
+				host.LocalHosts = append(host.LocalHosts, lh)
+			}
+		}
+		return append(existingHosts[:i], existingHosts[i+1:]...)
+	}
+	return existingHosts
+}
+
 // Iterates over the list of hosts and commits them as global hosts.
 func CommitGlobalHostsIntoDB(dbi dbops.DBI, hosts []Host, daemon *Daemon) (err error) {
 	if db, ok := dbi.(*pg.DB); ok {
@@ -696,6 +746,17 @@ func (host Host) HasIdentifier(idType string, identifier []byte) (bool, bool) {
 	return false, false
 }
 
+// Checks if the host has at least one identifier of the same type and value
+// as the other host.
+func (host Host) HasCommonIdentifier(other *Host) bool {
+	for _, id := range other.HostIdentifiers {
+		if _, ok := host.HasIdentifier(id.Type, id.Value); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // This function checks if the given host has an identifier of a given type.
 func (host Host) HasIdentifierType(idType string) bool {
 	for _, i := range host.HostIdentifiers {
@@ -1279,6 +1279,161 @@ func TestCommitGlobalHostsIntoDBLateSetDaemonID(t *testing.T) {
 	require.Zero(t, returned[0].SubnetID)
 }
 
+// Creates a global host with the hw-address identifier associated with the
+// specified daemons using the given data source.
+func createSourceTestHost(address string, source HostDataSource, daemonIDs ...int64) Host {
+	host := Host{
+		HostIdentifiers: []HostIdentifier{
+			{
+				Type:  "hw-address",
+				Value: []byte{1, 2, 3, 4, 5, 6},
+			},
+		},
+		IPReservations: []IPReservation{
+			{
+				Address: address,
+			},
+		},
+	}
+	for _, daemonID := range daemonIDs {
+		host.LocalHosts = append(host.LocalHosts, LocalHost{
+			DaemonID:   daemonID,
+			DataSource: source,
+		})
+	}
+	return host
+}
+
+// Test that the host reservation moved between the configuration file and
+// the host database is updated rather than duplicated.
+func TestCommitGlobalHostsIntoDBDataSourceTransition(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	apps := addTestSubnetApps(t, db)
+	daemon := apps[0].Daemons[0]
+
+	// The reservation is initially specified in the configuration file.
+	hosts := []Host{createSourceTestHost("192.0.2.56", HostDataSourceConfig, daemon.ID)}
+	err := CommitGlobalHostsIntoDB(db, hosts, daemon)
+	require.NoError(t, err)
+
+	returned, err := GetHostsBySubnetID(db, 0)
+	require.NoError(t, err)
+	require.Len(t, returned, 1)
+	hostID := returned[0].ID
+
+	// The reservation is moved to the host database and modified.
+	hosts = []Host{createSourceTestHost("192.0.2.57", HostDataSourceAPI, daemon.ID)}
+	err = CommitGlobalHostsIntoDB(db, hosts, daemon)
+	require.NoError(t, err)
+
+	returned, err = GetHostsBySubnetID(db, 0)
+	require.NoError(t, err)
+	require.Len(t, returned, 1)
+	require.Equal(t, hostID, returned[0].ID)
+	require.Len(t, returned[0].IPReservations, 1)
+	require.Equal(t, "192.0.2.57/32", returned[0].IPReservations[0].Address)
+	require.Len(t, returned[0].LocalHosts, 1)
+	require.Equal(t, HostDataSourceAPI, returned[0].LocalHosts[0].DataSource)
+
+	// The reservation is moved back to the configuration file.
+	hosts = []Host{createSourceTestHost("192.0.2.58", HostDataSourceConfig, daemon.ID)}
+	err = CommitGlobalHostsIntoDB(db, hosts, daemon)
+	require.NoError(t, err)
+
+	returned, err = GetHostsBySubnetID(db, 0)
+	require.NoError(t, err)
+	require.Len(t, returned, 1)
+	require.Equal(t, hostID, returned[0].ID)
+	require.Len(t, returned[0].IPReservations, 1)
+	require.Equal(t, "192.0.2.58/32", returned[0].IPReservations[0].Address)
+	require.Len(t, returned[0].LocalHosts, 1)
+	require.Equal(t, HostDataSourceConfig, returned[0].LocalHosts[0].DataSource)
+}
+
+// Test that the host shared by multiple daemons is not duplicated when the
+// reservation is moved to the host database for one of the daemons. The
+// daemon is attached to the existing host with the new data source and the
+// other daemon remains associated with it.
+func TestCommitGlobalHostsIntoDBDataSourceTransitionSharedHost(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	apps := addTestSubnetApps(t, db)
+	daemon0 := apps[0].Daemons[0]
+	daemon1 := apps[1].Daemons[0]
+
+	hosts := []Host{createSourceTestHost("192.0.2.56", HostDataSourceConfig, daemon0.ID, daemon1.ID)}
+	err := CommitGlobalHostsIntoDB(db, hosts, daemon0)
+	require.NoError(t, err)
+
+	returned, err := GetHostsBySubnetID(db, 0)
+	require.NoError(t, err)
+	require.Len(t, returned, 1)
+	require.Len(t, returned[0].LocalHosts, 2)
+	hostID := returned[0].ID
+
+	// Act
+	hosts = []Host{createSourceTestHost("192.0.2.56", HostDataSourceAPI, daemon0.ID)}
+	err = CommitGlobalHostsIntoDB(db, hosts, daemon0)
+	require.NoError(t, err)
+
+	// Assert
+	returned, err = GetHostsBySubnetID(db, 0)
+	require.NoError(t, err)
+	require.Len(t, returned, 1)
+	require.Equal(t, hostID, returned[0].ID)
+	require.Len(t, returned[0].IPReservations, 1)
+	require.Equal(t, "192.0.2.56/32", returned[0].IPReservations[0].Address)
+	require.Len(t, returned[0].LocalHosts, 2)
+
+	localHost := returned[0].GetLocalHost(daemon0.ID)
+	require.NotNil(t, localHost)
+	require.Equal(t, HostDataSourceAPI, localHost.DataSource)
+	localHost = returned[0].GetLocalHost(daemon1.ID)
+	require.NotNil(t, localHost)
+	require.Equal(t, HostDataSourceConfig, localHost.DataSource)
+}
+
+// Test checking if two hosts have a common identifier.
+func TestHasCommonIdentifier(t *testing.T) {
+	host := Host{
+		HostIdentifiers: []HostIdentifier{
+			{
+				Type:  "hw-address",
+				Value: []byte{1, 2, 3, 4, 5, 6},
+			},
+			{
+				Type:  "client-id",
+				Value: []byte{1, 2, 3, 4},
+			},
+		},
+	}
+	require.True(t, host.HasCommonIdentifier(&Host{
+		HostIdentifiers: []HostIdentifier{
+			{
+				Type:  "client-id",
+				Value: []byte{1, 2, 3, 4},
+			},
+		},
+	}))
+	require.False(t, host.HasCommonIdentifier(&Host{
+		HostIdentifiers: []HostIdentifier{
+			{
+				Type:  "client-id",
+				Value: []byte{1, 2, 3, 5},
+			},
+			{
+				Type:  "duid",
+				Value: []byte{1, 2, 3, 4, 5, 6},
+			},
+		},
+	}))
+	require.False(t, host.HasCommonIdentifier(&Host{}))
+}
+
 // Test that the prefix reservations are properly recognized.
 func TestIsPrefixForPrefix(t *testing.T) {
 	// Arrange
@@ -1550,6 +1705,85 @@ func TestCountOutOfPoolCounters(t *testing.T) {
 	require.EqualValues(t, 4, globalPDs)
 }
 
+// Test that a host reservation including both an address and a prefix
+// is included in the out-of-pool address and prefix counters.
+func TestCountOutOfPoolCountersMixedReservation(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	apps := addTestSubnetApps(t, db)
+
+	subnet := &Subnet{
+		Prefix: "2001:db8:1::/64",
+		LocalSubnets: []*LocalSubnet{
+			{
+				DaemonID: apps[0].Daemons[1].ID,
+				AddressPools: []AddressPool{
+					{
+						LowerBound: "2001:db8:1::1",
+						UpperBound: "2001:db8:1::10",
+					},
+				},
+				PrefixPools: []PrefixPool{
+					{
+						Prefix:       "3001:1::/48",
+						DelegatedLen: 64,
+					},
+				},
+			},
+		},
+	}
+	err := AddSubnet(db, subnet)
+	require.NoError(t, err)
+	err = AddLocalSubnets(db, subnet)
+	require.NoError(t, err)
+
+	// Both the address and the prefix are out of pool.
+	host := &Host{
+		CreatedAt: time.Now(),
+		SubnetID:  subnet.ID,
+		Hostname:  "foo",
+		IPReservations: []IPReservation{
+			{
+				Address: "2001:db8:1::100",
+			},
+			{
+				Address: "3001:2::/64",
+			},
+		},
+	}
+	err = AddHost(db, host)
+	require.NoError(t, err)
+
+	// The address is out of pool but the prefix is in pool.
+	host = &Host{
+		CreatedAt: time.Now(),
+		SubnetID:  subnet.ID,
+		Hostname:  "bar",
+		IPReservations: []IPReservation{
+			{
+				Address: "2001:db8:1::101",
+			},
+			{
+				Address: "3001:1:0:1::/64",
+			},
+		},
+	}
+	err = AddHost(db, host)
+	require.NoError(t, err)
+
+	// Act
+	addressCounters, errAddresses := CountOutOfPoolAddressReservations(db)
+	prefixCounters, errPrefixes := CountOutOfPoolPrefixReservations(db)
+
+	// Assert
+	require.NoError(t, errAddresses)
+	require.NoError(t, errPrefixes)
+	require.EqualValues(t, 2, addressCounters[subnet.ID])
+	require.EqualValues(t, 1, prefixCounters[subnet.ID])
+}
+
 // Test that Host properly implements keaconfig.Host interface.
 func TestKeaConfigHostInterface(t *testing.T) {
 	host := &Host{
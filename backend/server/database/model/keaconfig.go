@@ -2,7 +2,6 @@ package dbmodel
 
 import (
 	"bytes"
-	"encoding/hex"
 	"encoding/json"
 	"reflect"
 	"strings"
@@ -259,10 +258,9 @@ func NewHostFromKeaConfigReservation(reservation keaconfig.Reservation, daemon *
 				continue
 			}
 
-			// Remove colons from the string of hexadecimal values.
-			hexv := strings.ReplaceAll(fieldValue, ":", "")
-			// Convert the identifier to binary.
-			bytev, err := hex.DecodeString(hexv)
+			// Convert the identifier to binary. The flex-id identifiers
+			// are often specified as a quoted text.
+			bytev, err := keaconfig.ParseHostIdentifier(fieldValue)
 			if err != nil {
 				return nil, err
 			}
@@ -275,7 +273,10 @@ func NewHostFromKeaConfigReservation(reservation keaconfig.Reservation, daemon *
 		}
 	}
 	// Iterate over the IPv6 addresses and prefixes and create IP reservations
-	// from them
+	// from them. A reservation may contain both addresses and prefixes. In
+	// this case, the host gets separate IP reservations for the addresses
+	// and prefixes, so they are included in the address (NA) and the
+	// delegated prefix (PD) out-of-pool counters respectively.
 	for _, addrs := range [][]string{reservation.IPAddresses, reservation.Prefixes} {
 		for _, addr := range addrs {
 			host.IPReservations = append(host.IPReservations, IPReservation{
@@ -323,6 +324,7 @@ func NewLogTargetsFromKea(logger keaconfig.Logger) (targets []*LogTarget) {
 			Name:     logger.Name,
 			Severity: strings.ToLower(logger.Severity),
 			Output:   opt.Output,
+			Tailable: IsLogOutputTailable(opt.Output),
 		}
 		targets = append(targets, target)
 	}
@@ -177,6 +177,85 @@ func TestNewSubnetFromKea(t *testing.T) {
 	require.Equal(t, HostDataSourceConfig, parsedSubnet.Hosts[0].LocalHosts[0].DataSource)
 }
 
+// Test that the DHCPv4-over-DHCPv6 parameters are extracted from the IPv4
+// subnet and stored in the local subnet.
+func TestNewSubnetFromKeaWith4o6Parameters(t *testing.T) {
+	config, err := NewKeaConfigFromJSON(`{
+		"Dhcp4": {
+			"subnet4": [
+				{
+					"id": 1,
+					"subnet": "192.0.2.0/24",
+					"4o6-interface": "eth1",
+					"4o6-interface-id": "ethx",
+					"4o6-subnet": "2001:db8:1:1::/64"
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+	subnets := config.GetSubnets()
+	require.Len(t, subnets, 1)
+
+	daemon := NewKeaDaemon(DaemonNameDHCPv4, true)
+	daemon.ID = 42
+	lookup := NewDHCPOptionDefinitionLookup()
+	parsedSubnet, err := NewSubnetFromKea(subnets[0], daemon, HostDataSourceConfig, lookup)
+	require.NoError(t, err)
+	require.NotNil(t, parsedSubnet)
+	require.Len(t, parsedSubnet.LocalSubnets, 1)
+
+	params := parsedSubnet.GetKeaParameters(42)
+	require.NotNil(t, params)
+	require.NotNil(t, params.FourOverSixInterface)
+	require.Equal(t, "eth1", *params.FourOverSixInterface)
+	require.NotNil(t, params.FourOverSixInterfaceID)
+	require.Equal(t, "ethx", *params.FourOverSixInterfaceID)
+	require.NotNil(t, params.FourOverSixSubnet)
+	require.Equal(t, "2001:db8:1:1::/64", *params.FourOverSixSubnet)
+}
+
+// Test that the DHCPv6 preferred lifetime parameters and the rapid commit
+// flag are extracted from the IPv6 subnet and stored in the local subnet.
+func TestNewSubnetFromKeaWithDHCPv6TimingParameters(t *testing.T) {
+	config, err := NewKeaConfigFromJSON(`{
+		"Dhcp6": {
+			"subnet6": [
+				{
+					"id": 1,
+					"subnet": "2001:db8:1::/64",
+					"preferred-lifetime": 3000,
+					"min-preferred-lifetime": 2000,
+					"max-preferred-lifetime": 4000,
+					"rapid-commit": true
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+	subnets := config.GetSubnets()
+	require.Len(t, subnets, 1)
+
+	daemon := NewKeaDaemon(DaemonNameDHCPv6, true)
+	daemon.ID = 42
+	lookup := NewDHCPOptionDefinitionLookup()
+	parsedSubnet, err := NewSubnetFromKea(subnets[0], daemon, HostDataSourceConfig, lookup)
+	require.NoError(t, err)
+	require.NotNil(t, parsedSubnet)
+	require.Len(t, parsedSubnet.LocalSubnets, 1)
+
+	params := parsedSubnet.GetKeaParameters(42)
+	require.NotNil(t, params)
+	require.NotNil(t, params.PreferredLifetime)
+	require.EqualValues(t, 3000, *params.PreferredLifetime)
+	require.NotNil(t, params.MinPreferredLifetime)
+	require.EqualValues(t, 2000, *params.MinPreferredLifetime)
+	require.NotNil(t, params.MaxPreferredLifetime)
+	require.EqualValues(t, 4000, *params.MaxPreferredLifetime)
+	require.NotNil(t, params.RapidCommit)
+	require.True(t, *params.RapidCommit)
+}
+
 // Test that the error is returned when the subnet prefix is invalid.
 func TestNewSubnetFromKeaWithInvalidPrefix(t *testing.T) {
 	// Arrange
@@ -197,6 +276,72 @@ func TestNewSubnetFromKeaWithInvalidPrefix(t *testing.T) {
 	require.Nil(t, parsedSubnet)
 }
 
+// Test that the subnet with the pools from the other address family than
+// the subnet prefix is converted without a panic. Such pools are reported
+// by the configuration review.
+func TestNewSubnetFromKeaWithMismatchedPoolFamily(t *testing.T) {
+	// Arrange
+	keaSubnet := keaconfig.Subnet4{
+		MandatorySubnetParameters: keaconfig.MandatorySubnetParameters{
+			Subnet: "192.0.2.0/24",
+		},
+		CommonSubnetParameters: keaconfig.CommonSubnetParameters{
+			Pools: []keaconfig.Pool{
+				{Pool: "192.0.2.10-192.0.2.100"},
+				{Pool: "2001:db8:1::10-2001:db8:1::100"},
+			},
+		},
+	}
+	daemon := NewKeaDaemon(DaemonNameDHCPv4, true)
+	daemon.ID = 42
+
+	// Act
+	lookup := NewDHCPOptionDefinitionLookup()
+	var parsedSubnet *Subnet
+	var err error
+	require.NotPanics(t, func() {
+		parsedSubnet, err = NewSubnetFromKea(&keaSubnet, daemon, HostDataSourceConfig, lookup)
+	})
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, parsedSubnet)
+	require.Len(t, parsedSubnet.LocalSubnets, 1)
+	require.Len(t, parsedSubnet.LocalSubnets[0].AddressPools, 2)
+	require.Equal(t, "2001:db8:1::10", parsedSubnet.LocalSubnets[0].AddressPools[1].LowerBound)
+	require.Equal(t, "2001:db8:1::100", parsedSubnet.LocalSubnets[0].AddressPools[1].UpperBound)
+}
+
+// Test that the error is returned when the pool boundaries belong to
+// different address families.
+func TestNewSubnetFromKeaWithMixedFamilyPool(t *testing.T) {
+	// Arrange
+	keaSubnet := keaconfig.Subnet4{
+		MandatorySubnetParameters: keaconfig.MandatorySubnetParameters{
+			Subnet: "192.0.2.0/24",
+		},
+		CommonSubnetParameters: keaconfig.CommonSubnetParameters{
+			Pools: []keaconfig.Pool{
+				{Pool: "192.0.2.10-2001:db8:1::100"},
+			},
+		},
+	}
+	daemon := NewKeaDaemon(DaemonNameDHCPv4, true)
+	daemon.ID = 42
+
+	// Act
+	lookup := NewDHCPOptionDefinitionLookup()
+	var parsedSubnet *Subnet
+	var err error
+	require.NotPanics(t, func() {
+		parsedSubnet, err = NewSubnetFromKea(&keaSubnet, daemon, HostDataSourceConfig, lookup)
+	})
+
+	// Assert
+	require.Error(t, err)
+	require.Nil(t, parsedSubnet)
+}
+
 // Test that the default mask is added to IPv4 subnet prefix if missing.
 func TestNewSubnetFromKeaWithDefaultIPv4PrefixMask(t *testing.T) {
 	// Arrange
@@ -288,18 +433,25 @@ func TestNewLogTargetsFromKea(t *testing.T) {
 			{
 				Output: "/tmp/log",
 			},
+			{
+				Output: "syslog",
+			},
 		},
 		Severity: "DEBUG",
 	}
 
 	targets := NewLogTargetsFromKea(logger)
-	require.Len(t, targets, 2)
+	require.Len(t, targets, 3)
 	require.Equal(t, "logger-name", targets[0].Name)
 	require.Equal(t, "stdout", targets[0].Output)
 	require.Equal(t, "debug", targets[0].Severity)
+	require.False(t, targets[0].Tailable)
 	require.Equal(t, "logger-name", targets[1].Name)
 	require.Equal(t, "/tmp/log", targets[1].Output)
 	require.Equal(t, "debug", targets[1].Severity)
+	require.True(t, targets[1].Tailable)
+	require.Equal(t, "syslog", targets[2].Output)
+	require.False(t, targets[2].Tailable)
 }
 
 // Test that appended value is properly scanned.
@@ -581,6 +733,50 @@ func TestNewHostFromKeaDHCPv4Reservation(t *testing.T) {
 	require.Equal(t, "host.example.org", host.LocalHosts[0].ServerHostname)
 }
 
+// Test creating a host from the flex-id reservations specified as a quoted
+// text and as a string of hexadecimal digits.
+func TestNewHostFromKeaFlexIDReservation(t *testing.T) {
+	daemon := &Daemon{
+		ID: 1,
+	}
+	lookup := NewDHCPOptionDefinitionLookup()
+
+	host, err := NewHostFromKeaConfigReservation(keaconfig.Reservation{
+		FlexID:    "'s0mEVaLue'",
+		IPAddress: "192.0.2.1",
+	}, daemon, HostDataSourceConfig, lookup)
+	require.NoError(t, err)
+	require.NotNil(t, host)
+	require.Len(t, host.HostIdentifiers, 1)
+	require.Equal(t, "flex-id", host.HostIdentifiers[0].Type)
+	require.Equal(t, []byte("s0mEVaLue"), host.HostIdentifiers[0].Value)
+	require.True(t, host.HasIdentifierType("flex-id"))
+
+	host, err = NewHostFromKeaConfigReservation(keaconfig.Reservation{
+		FlexID:    "01:02:03",
+		IPAddress: "192.0.2.2",
+	}, daemon, HostDataSourceConfig, lookup)
+	require.NoError(t, err)
+	require.NotNil(t, host)
+	require.Len(t, host.HostIdentifiers, 1)
+	require.Equal(t, "flex-id", host.HostIdentifiers[0].Type)
+	require.Equal(t, []byte{1, 2, 3}, host.HostIdentifiers[0].Value)
+}
+
+// Test that an error is returned for a malformed flex-id identifier.
+func TestNewHostFromKeaInvalidFlexIDReservation(t *testing.T) {
+	daemon := &Daemon{
+		ID: 1,
+	}
+	lookup := NewDHCPOptionDefinitionLookup()
+
+	host, err := NewHostFromKeaConfigReservation(keaconfig.Reservation{
+		FlexID: "'unterminated",
+	}, daemon, HostDataSourceConfig, lookup)
+	require.Error(t, err)
+	require.Nil(t, host)
+}
+
 // Test creating a host from a DHCPv6 reservation.
 func TestNewHostFromKeaDHCPv6Reservation(t *testing.T) {
 	reservation := keaconfig.Reservation{
@@ -607,3 +803,28 @@ func TestNewHostFromKeaDHCPv6Reservation(t *testing.T) {
 	require.EqualValues(t, 1, host.LocalHosts[0].DaemonID)
 	require.Equal(t, HostDataSourceAPI, host.LocalHosts[0].DataSource)
 }
+
+// Test creating a host from a DHCPv6 reservation with an address and a
+// prefix. Both should be converted to the IP reservations and recognized
+// as an address and a prefix respectively.
+func TestNewHostFromKeaDHCPv6MixedReservation(t *testing.T) {
+	reservation := keaconfig.Reservation{
+		DUID:        "01:02:03:04:05:06",
+		IPAddresses: []string{"2001:db8:1::1"},
+		Prefixes:    []string{"3000::/64"},
+	}
+	daemon := &Daemon{
+		ID:   1,
+		Name: DaemonNameDHCPv6,
+	}
+	lookup := NewDHCPOptionDefinitionLookup()
+	host, err := NewHostFromKeaConfigReservation(reservation, daemon, HostDataSourceConfig, lookup)
+	require.NoError(t, err)
+	require.NotNil(t, host)
+
+	require.Len(t, host.IPReservations, 2)
+	require.Equal(t, "2001:db8:1::1", host.IPReservations[0].Address)
+	require.False(t, host.IPReservations[0].IsPrefix())
+	require.Equal(t, "3000::/64", host.IPReservations[1].Address)
+	require.True(t, host.IPReservations[1].IsPrefix())
+}
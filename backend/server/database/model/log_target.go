@@ -2,6 +2,7 @@ package dbmodel
 
 import (
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/go-pg/pg/v10"
@@ -10,16 +11,33 @@ import (
 
 // A structure reflecting information about a logger used by a daemon.
 type LogTarget struct {
-	ID        int64 // Logger ID
-	Name      string
-	Severity  string
-	Output    string
+	ID       int64 // Logger ID
+	Name     string
+	Severity string
+	Output   string
+	// Indicates whether the output is a file that can be tailed. The
+	// logs written to stdout, stderr or syslog can't be viewed in Stork.
+	Tailable  bool `pg:",use_zero"`
 	CreatedAt time.Time
 
 	DaemonID int64
 	Daemon   *Daemon `pg:"rel:has-one"`
 }
 
+// Checks if the log output is a file that can be tailed by the Stork agent.
+// The special outputs, i.e., stdout, stderr and syslog (optionally followed
+// by the syslog facility name, e.g., syslog:local0) are not tailable.
+func IsLogOutputTailable(output string) bool {
+	switch {
+	case output == "", output == "stdout", output == "stderr":
+		return false
+	case output == "syslog", strings.HasPrefix(output, "syslog:"):
+		return false
+	default:
+		return true
+	}
+}
+
 // Retrieves log target from the database by id.
 func GetLogTargetByID(db *pg.DB, id int64) (*LogTarget, error) {
 	logTarget := LogTarget{}
@@ -76,3 +76,78 @@ func TestGetLogTargetByID(t *testing.T) {
 	require.NoError(t, err)
 	require.Nil(t, logTarget)
 }
+
+// Test that the log outputs are classified as tailable or not.
+func TestIsLogOutputTailable(t *testing.T) {
+	testCases := []struct {
+		output   string
+		tailable bool
+	}{
+		{"/var/log/kea/kea-dhcp4.log", true},
+		{"kea-dhcp4.log", true},
+		{"syslog-file.log", true},
+		{"stdout", false},
+		{"stderr", false},
+		{"syslog", false},
+		{"syslog:local0", false},
+		{"", false},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.output, func(t *testing.T) {
+			require.Equal(t, testCase.tailable, IsLogOutputTailable(testCase.output))
+		})
+	}
+}
+
+// Test that the log target tailability is stored in the database.
+func TestLogTargetTailableStored(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	m := &Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	err := AddMachine(db, m)
+	require.NoError(t, err)
+
+	daemon := NewKeaDaemon(DaemonNameDHCPv4, true)
+	err = daemon.SetConfigFromJSON(`{
+        "Dhcp4": {
+            "loggers": [
+                {
+                    "name": "kea-dhcp4",
+                    "severity": "INFO",
+                    "output_options": [
+                        { "output": "/var/log/kea-dhcp4.log" },
+                        { "output": "syslog:local0" },
+                        { "output": "stdout" }
+                    ]
+                }
+            ]
+        }
+    }`)
+	require.NoError(t, err)
+
+	a := &App{
+		MachineID: m.ID,
+		Type:      AppTypeKea,
+		Daemons:   []*Daemon{daemon},
+	}
+
+	// Act
+	_, err = AddApp(db, a)
+	require.NoError(t, err)
+
+	// Assert
+	require.Len(t, a.Daemons[0].LogTargets, 3)
+	expected := []bool{true, false, false}
+	for i, target := range a.Daemons[0].LogTargets {
+		logTarget, err := GetLogTargetByID(db, target.ID)
+		require.NoError(t, err)
+		require.NotNil(t, logTarget)
+		require.Equal(t, expected[i], logTarget.Tailable, logTarget.Output)
+	}
+}
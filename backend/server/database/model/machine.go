@@ -43,6 +43,16 @@ type Machine struct {
 	AgentToken      string
 	CertFingerprint [32]byte
 	Authorized      bool `pg:",use_zero"`
+	// The timeout in seconds of getting the state of the apps running on
+	// the machine, e.g., raised for the machines at the remote sites. The
+	// global timeout is used when it is zero.
+	AppStateTimeout int64 `pg:",use_zero"`
+	// The address, port and certificate fingerprint the agent registered
+	// with from a new location. The relocation is pending until confirmed
+	// by an administrator.
+	RelocationAddress         string
+	RelocationAgentPort       int64
+	RelocationCertFingerprint [32]byte
 }
 
 // Identifier of the relations between the machine and other tables.
@@ -105,6 +115,30 @@ func GetMachineByAddressAndAgentPort(db *pg.DB, address string, agentPort int64)
 	return &machine, nil
 }
 
+// Get a machine by the token of the agent running on it. The agent token is
+// generated by the agent during the first registration and it doesn't change
+// when the agent's address changes. Therefore, it can be used to recognize
+// the agent that has been moved to a new address. It returns nil if the
+// token is empty or no machine uses it.
+func GetMachineByAgentToken(db *pg.DB, agentToken string) (*Machine, error) {
+	if agentToken == "" {
+		return nil, nil
+	}
+	machine := Machine{}
+	err := db.Model(&machine).
+		Where("agent_token = ?", agentToken).
+		Relation("Apps.AccessPoints").
+		Order("id ASC").
+		Limit(1).
+		Select()
+	if errors.Is(err, pg.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, pkgerrors.Wrapf(err, "problem getting machine by agent token")
+	}
+	return &machine, nil
+}
+
 // Get a machine by the machine address and the access point port.
 // Optionally, it filters access points by type.
 func GetMachineByAddressAndAccessPointPort(db *pg.DB, machineAddress string, accessPointPort int64, accessPointType *string) (*Machine, error) {
@@ -282,6 +316,57 @@ func DeleteMachine(db *pg.DB, machine *Machine) error {
 	return nil
 }
 
+// Checks if the agent with the specified token registers from an address
+// or port different than the one recorded for the machine. It indicates
+// that the agent has been moved (e.g., re-addressed or migrated) and its
+// apps belong to this machine.
+func (machine *Machine) IsAgentRelocated(agentToken, address string, agentPort int64) bool {
+	return agentToken != "" && machine.AgentToken == agentToken &&
+		(machine.Address != address || machine.AgentPort != agentPort)
+}
+
+// Records the pending relocation of the machine to the specified address
+// and port. The machine remains at the current address until the relocation
+// is confirmed.
+func (machine *Machine) SetPendingRelocation(address string, agentPort int64, certFingerprint [32]byte) {
+	machine.RelocationAddress = address
+	machine.RelocationAgentPort = agentPort
+	machine.RelocationCertFingerprint = certFingerprint
+}
+
+// Checks if the machine has a pending relocation.
+func (machine *Machine) HasPendingRelocation() bool {
+	return machine.RelocationAddress != "" && machine.RelocationAgentPort != 0
+}
+
+// Checks if the specified address and port are the ones the machine has
+// a pending relocation to.
+func (machine *Machine) IsPendingRelocationTo(address string, agentPort int64) bool {
+	return machine.HasPendingRelocation() &&
+		machine.RelocationAddress == address && machine.RelocationAgentPort == agentPort
+}
+
+// Moves the machine to the address and port of the pending relocation and
+// takes over the certificate fingerprint of the relocated agent. It does
+// nothing if there is no pending relocation. The caller is responsible for
+// updating the machine in the database.
+func (machine *Machine) ConfirmRelocation() {
+	if !machine.HasPendingRelocation() {
+		return
+	}
+	machine.Address = machine.RelocationAddress
+	machine.AgentPort = machine.RelocationAgentPort
+	machine.CertFingerprint = machine.RelocationCertFingerprint
+	machine.ClearPendingRelocation()
+}
+
+// Discards the pending relocation of the machine.
+func (machine *Machine) ClearPendingRelocation() {
+	machine.RelocationAddress = ""
+	machine.RelocationAgentPort = 0
+	machine.RelocationCertFingerprint = [32]byte{}
+}
+
 // MachineTag interface implementation.
 
 // Returns machine ID.
@@ -143,6 +143,95 @@ func TestGetMachineByAddress(t *testing.T) {
 	require.Nil(t, m)
 }
 
+// Test that the machine can be found by the agent token.
+func TestGetMachineByAgentToken(t *testing.T) {
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	// Empty token.
+	m, err := GetMachineByAgentToken(db, "")
+	require.NoError(t, err)
+	require.Nil(t, m)
+
+	// Non-existing machine.
+	m, err = GetMachineByAgentToken(db, "token1")
+	require.NoError(t, err)
+	require.Nil(t, m)
+
+	m1 := &Machine{
+		Address:    "192.0.2.1",
+		AgentPort:  8080,
+		AgentToken: "token1",
+	}
+	err = AddMachine(db, m1)
+	require.NoError(t, err)
+	m2 := &Machine{
+		Address:    "192.0.2.2",
+		AgentPort:  8080,
+		AgentToken: "token2",
+	}
+	err = AddMachine(db, m2)
+	require.NoError(t, err)
+
+	m, err = GetMachineByAgentToken(db, "token2")
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	require.Equal(t, m2.ID, m.ID)
+	require.Equal(t, "192.0.2.2", m.Address)
+}
+
+// Test that the agent registering from a new address with the same agent
+// token is recognized as relocated.
+func TestMachineIsAgentRelocated(t *testing.T) {
+	m := &Machine{
+		Address:    "192.0.2.1",
+		AgentPort:  8080,
+		AgentToken: "token",
+	}
+
+	// The same agent registers from a new address or port.
+	require.True(t, m.IsAgentRelocated("token", "192.0.2.2", 8080))
+	require.True(t, m.IsAgentRelocated("token", "192.0.2.1", 8081))
+	// The same agent registers from the same address.
+	require.False(t, m.IsAgentRelocated("token", "192.0.2.1", 8080))
+	// A different agent registers from a new address.
+	require.False(t, m.IsAgentRelocated("other", "192.0.2.2", 8080))
+	require.False(t, m.IsAgentRelocated("", "192.0.2.2", 8080))
+}
+
+// Test that the pending relocation of the machine is applied only upon
+// the confirmation.
+func TestMachineConfirmRelocation(t *testing.T) {
+	// Arrange
+	m := &Machine{
+		Address:         "192.0.2.1",
+		AgentPort:       8080,
+		CertFingerprint: [32]byte{1},
+	}
+	m.SetPendingRelocation("192.0.2.2", 8081, [32]byte{2})
+
+	// Act & Assert
+	require.True(t, m.HasPendingRelocation())
+	require.True(t, m.IsPendingRelocationTo("192.0.2.2", 8081))
+	require.False(t, m.IsPendingRelocationTo("192.0.2.2", 8080))
+	require.EqualValues(t, "192.0.2.1", m.Address)
+	require.EqualValues(t, 8080, m.AgentPort)
+	require.Equal(t, [32]byte{1}, m.CertFingerprint)
+
+	m.ConfirmRelocation()
+
+	require.False(t, m.HasPendingRelocation())
+	require.EqualValues(t, "192.0.2.2", m.Address)
+	require.EqualValues(t, 8081, m.AgentPort)
+	require.Equal(t, [32]byte{2}, m.CertFingerprint)
+	require.Empty(t, m.RelocationAddress)
+	require.Zero(t, m.RelocationAgentPort)
+
+	// Confirming again does nothing.
+	m.ConfirmRelocation()
+	require.EqualValues(t, "192.0.2.2", m.Address)
+}
+
 // Check if getting machine by its ID.
 func TestGetMachineByID(t *testing.T) {
 	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
@@ -475,37 +475,19 @@ func isOperationalHAState(state HAState) bool {
 	}
 }
 
-// Returns the HA daemons that don't allocate leases independently (depend on
-// another server or don't allocate at all).
-func GetPassiveHADaemonIDs(db dbops.DBI) ([]int64, error) {
-	services, err := GetDetailedAllServices(db)
-	if err != nil {
-		return nil, err
-	}
-
-	passiveHADaemons := make([]int64, 0)
-
-	for _, service := range services {
-		if service.HAService == nil {
-			continue
-		}
+// Returns the ID of the primary or secondary server that depends on its
+// peer according to the last known HA states. It is the secondary server
+// unless the primary server is not operational while the secondary one is.
+func (s *BaseHAService) GetPassivePeerID() int64 {
+	// The server is operational if it is reachable and has an operational state.
+	isPrimaryOperational := isOperationalHAState(s.PrimaryLastState)
+	isPrimaryOperational = isPrimaryOperational && s.PrimaryReachable
 
-		// Backups never actively allocate leases.
-		passiveHADaemons = append(passiveHADaemons, service.HAService.BackupID...)
+	isSecondaryOperational := isOperationalHAState(s.SecondaryLastState)
+	isSecondaryOperational = isSecondaryOperational && s.SecondaryReachable
 
-		// The server is operational if it is reachable and has an operational state.
-		isPrimaryOperational := isOperationalHAState(service.HAService.PrimaryLastState)
-		isPrimaryOperational = isPrimaryOperational && service.HAService.PrimaryReachable
-
-		isSecondaryOperational := isOperationalHAState(service.HAService.SecondaryLastState)
-		isSecondaryOperational = isSecondaryOperational && service.HAService.SecondaryReachable
-
-		if isPrimaryOperational || !isSecondaryOperational {
-			passiveHADaemons = append(passiveHADaemons, service.HAService.SecondaryID)
-		} else {
-			passiveHADaemons = append(passiveHADaemons, service.HAService.PrimaryID)
-		}
+	if isPrimaryOperational || !isSecondaryOperational {
+		return s.SecondaryID
 	}
-
-	return passiveHADaemons, nil
+	return s.PrimaryID
 }
@@ -734,77 +734,34 @@ func TestGetPartnerHAFailureTime(t *testing.T) {
 	require.Equal(t, primaryFailoverAt, failureTime)
 }
 
-// Tests that passive HA daemons are selected properly when HA works correctly.
-func TestGetPassiveHADaemonIDs(t *testing.T) {
+// Tests that the passive peer is selected according to the HA states.
+func TestGetPassivePeerID(t *testing.T) {
 	// Arrange
-	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
-	defer teardown()
-
-	services := addTestServices(t, db)
-	haService := services[1]
-	haService.HAService.PrimaryLastState = HAStateReady
-	haService.HAService.SecondaryLastState = HAStateReady
-	_ = UpdateService(db, haService)
-
-	// Act
-	daemons, err := GetPassiveHADaemonIDs(db)
-
-	// Assert
-	require.NoError(t, err)
-	require.NotNil(t, haService.HAService)
-	// 1 secondary, 2 backups
-	require.Len(t, daemons, 3)
-	require.Contains(t, daemons, haService.HAService.SecondaryID)
-	require.Contains(t, daemons, haService.HAService.BackupID[0])
-	require.Contains(t, daemons, haService.HAService.BackupID[1])
-}
-
-// Tests that passive HA daemons are selected properly when HA daemons are
-// unreachable.
-func TestGetPassiveHAUnreachableDaemonIDs(t *testing.T) {
-	// Arrange
-	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
-	defer teardown()
-
-	services := addTestServices(t, db)
-	haService := services[1]
-	haService.HAService.PrimaryReachable = false
-	haService.HAService.SecondaryReachable = false
-	_ = UpdateService(db, haService)
-
-	// Act
-	daemons, err := GetPassiveHADaemonIDs(db)
-
-	// Assert
-	require.NoError(t, err)
-	require.NotNil(t, haService.HAService)
-	// Both servers are non-operational. Fallback to primary.
-	// 1 secondary, 2 backups
-	require.Len(t, daemons, 3)
-}
-
-// Tests that passive HA daemons are selected properly when a primary
-// daemon isn't operational.
-func TestGetPassiveHADaemonIDsPrimaryIsNotOperational(t *testing.T) {
-	// Arrange
-	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
-	defer teardown()
-
-	services := addTestServices(t, db)
-	haService := services[1]
-	haService.HAService.PrimaryLastState = HAStateSyncing
-	haService.HAService.SecondaryLastState = HAStateReady
-	_ = UpdateService(db, haService)
-
-	// Act
-	daemons, err := GetPassiveHADaemonIDs(db)
-
-	// Assert
-	require.NoError(t, err)
-	require.NotNil(t, haService.HAService)
-	// 1 primary, 2 backups
-	require.Len(t, daemons, 3)
-	require.Contains(t, daemons, haService.HAService.PrimaryID)
-	require.Contains(t, daemons, haService.HAService.BackupID[0])
-	require.Contains(t, daemons, haService.HAService.BackupID[1])
+	service := &BaseHAService{
+		PrimaryID:          1,
+		SecondaryID:        2,
+		PrimaryLastState:   HAStateLoadBalancing,
+		PrimaryReachable:   true,
+		SecondaryLastState: HAStateLoadBalancing,
+		SecondaryReachable: true,
+	}
+
+	// Act & Assert
+	// Both servers are operational.
+	require.EqualValues(t, 2, service.GetPassivePeerID())
+
+	// The primary server is unreachable.
+	service.PrimaryReachable = false
+	require.EqualValues(t, 1, service.GetPassivePeerID())
+
+	// Both servers are unreachable. Fallback to primary.
+	service.SecondaryReachable = false
+	require.EqualValues(t, 2, service.GetPassivePeerID())
+
+	// The secondary server is operational while the primary is syncing.
+	service.PrimaryReachable = true
+	service.PrimaryLastState = HAStateSyncing
+	service.SecondaryReachable = true
+	service.SecondaryLastState = HAStatePartnerDown
+	require.EqualValues(t, 1, service.GetPassivePeerID())
 }
@@ -63,6 +63,16 @@ func InitializeSettings(db *pg.DB, initialPullerInterval int64) error {
 			ValType: SettingValTypeInt,
 			Value:   longInterval,
 		},
+		{
+			Name:    "kea_global_stats_interval", // in seconds, zero refreshes them with each stats pull
+			ValType: SettingValTypeInt,
+			Value:   "0",
+		},
+		{
+			Name:    "kea_stats_puller_app_intervals", // comma-separated app-id:seconds pairs overriding kea_stats_puller_interval
+			ValType: SettingValTypeStr,
+			Value:   "",
+		},
 		{
 			Name:    "kea_hosts_puller_interval", // in seconds
 			ValType: SettingValTypeInt,
@@ -93,6 +103,41 @@ func InitializeSettings(db *pg.DB, initialPullerInterval int64) error {
 			ValType: SettingValTypeInt,
 			Value:   shortInterval, // in seconds
 		},
+		{
+			Name:    "bulk_events_limit", // max events per bulk operation
+			ValType: SettingValTypeInt,
+			Value:   "10",
+		},
+		{
+			Name:    "max_pools_per_subnet", // pool count above which a subnet is reported as fragmented
+			ValType: SettingValTypeInt,
+			Value:   "16",
+		},
+		{
+			Name:    "min_valid_lifetime", // in seconds, lower valid lifetime in a subnet is reported
+			ValType: SettingValTypeInt,
+			Value:   "300",
+		},
+		{
+			Name:    "max_valid_lifetime", // in seconds, higher valid lifetime in a subnet is reported
+			ValType: SettingValTypeInt,
+			Value:   "604800",
+		},
+		{
+			Name:    "subnet_utilization_history_retention", // in hours, zero disables recording the utilization samples
+			ValType: SettingValTypeInt,
+			Value:   "0",
+		},
+		{
+			Name:    "subnet_utilization_high_watermark", // in percent, crossing it upward is reported, zero disables the reports
+			ValType: SettingValTypeInt,
+			Value:   "90",
+		},
+		{
+			Name:    "subnet_utilization_low_watermark", // in percent, dropping below it after crossing the high watermark is reported
+			ValType: SettingValTypeInt,
+			Value:   "80",
+		},
 	}
 
 	// Check if there are new settings vs existing ones. Add new ones to DB.
@@ -207,8 +252,17 @@ func GetAllSettings(db *pg.DB) (map[string]interface{}, error) {
 	return settingsMap, nil
 }
 
-// Set int value of given setting by name.
+// Minimal values of the int settings which must not be set below them.
+var minSettingIntValues = map[string]int64{
+	"bulk_events_limit": 0,
+}
+
+// Set int value of given setting by name. It returns an error if the
+// value is lower than the minimal value of the setting.
 func SetSettingInt(db *pg.DB, name string, value int64) error {
+	if minValue, ok := minSettingIntValues[name]; ok && value < minValue {
+		return pkgerrors.Errorf("value %d of setting %s is lower than %d", value, name, minValue)
+	}
 	s, err := getAndCheckSetting(db, name, SettingValTypeInt)
 	if err != nil {
 		return err
@@ -49,6 +49,38 @@ func TestInitializeSettings(t *testing.T) {
 	require.NoError(t, err)
 	require.EqualValues(t, 30, val)
 
+	val, err = GetSettingInt(db, "max_pools_per_subnet")
+	require.NoError(t, err)
+	require.EqualValues(t, 16, val)
+
+	val, err = GetSettingInt(db, "min_valid_lifetime")
+	require.NoError(t, err)
+	require.EqualValues(t, 300, val)
+
+	val, err = GetSettingInt(db, "max_valid_lifetime")
+	require.NoError(t, err)
+	require.EqualValues(t, 604800, val)
+
+	val, err = GetSettingInt(db, "kea_global_stats_interval")
+	require.NoError(t, err)
+	require.Zero(t, val)
+
+	appIntervals, err := GetSettingStr(db, "kea_stats_puller_app_intervals")
+	require.NoError(t, err)
+	require.Empty(t, appIntervals)
+
+	val, err = GetSettingInt(db, "subnet_utilization_history_retention")
+	require.NoError(t, err)
+	require.Zero(t, val)
+
+	val, err = GetSettingInt(db, "subnet_utilization_high_watermark")
+	require.NoError(t, err)
+	require.EqualValues(t, 90, val)
+
+	val, err = GetSettingInt(db, "subnet_utilization_low_watermark")
+	require.NoError(t, err)
+	require.EqualValues(t, 80, val)
+
 	// change the setting
 	err = SetSettingInt(db, "kea_stats_puller_interval", 123)
 	require.NoError(t, err)
@@ -191,3 +223,32 @@ func TestSettingsSetAndGet(t *testing.T) {
 	require.NoError(t, err)
 	require.EqualValues(t, "H@kErZ", pwdVal)
 }
+
+// Test that a negative limit of events per bulk operation is rejected and
+// zero is accepted.
+func TestSetSettingIntBulkEventsLimit(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	err := InitializeSettings(db, 0)
+	require.NoError(t, err)
+
+	// Act
+	err = SetSettingInt(db, "bulk_events_limit", -1)
+
+	// Assert
+	require.ErrorContains(t, err, "lower than 0")
+	val, err := GetSettingInt(db, "bulk_events_limit")
+	require.NoError(t, err)
+	require.EqualValues(t, 10, val)
+
+	// Act
+	err = SetSettingInt(db, "bulk_events_limit", 0)
+
+	// Assert
+	require.NoError(t, err)
+	val, err = GetSettingInt(db, "bulk_events_limit")
+	require.NoError(t, err)
+	require.Zero(t, val)
+}
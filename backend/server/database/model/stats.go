@@ -46,6 +46,8 @@ func InitializeStats(db *pg.DB) error {
 		{Name: "assigned-pds", Value: newIntegerDecimalZero()},
 		{Name: "total-pds", Value: newIntegerDecimalZero()},
 		{Name: "declined-nas", Value: newIntegerDecimalZero()},
+		{Name: "reclaimed-leases", Value: newIntegerDecimalZero()},
+		{Name: "reclaimed-declined-addresses", Value: newIntegerDecimalZero()},
 	}
 
 	// Check if there are new statistics vs existing ones. Add new ones to DB.
@@ -19,9 +19,11 @@ func TestStats(t *testing.T) {
 	// get all stats and check some values
 	stats, err := GetAllStats(db)
 	require.NoError(t, err)
-	require.Len(t, stats, 8)
+	require.Len(t, stats, 10)
 	require.Contains(t, stats, "assigned-addresses")
 	require.EqualValues(t, big.NewInt(0), stats["assigned-addresses"])
+	require.Contains(t, stats, "reclaimed-leases")
+	require.Contains(t, stats, "reclaimed-declined-addresses")
 
 	// modify one stats and store it in db
 	stats["assigned-addresses"] = big.NewInt(10)
@@ -31,7 +33,7 @@ func TestStats(t *testing.T) {
 	// get stats again and check if they have been modified
 	stats, err = GetAllStats(db)
 	require.NoError(t, err)
-	require.Len(t, stats, 8)
+	require.Len(t, stats, 10)
 	require.Contains(t, stats, "assigned-addresses")
 	require.EqualValues(t, big.NewInt(10), stats["assigned-addresses"])
 
@@ -43,7 +45,7 @@ func TestStats(t *testing.T) {
 	require.NoError(t, err)
 	stats, err = GetAllStats(db)
 	require.NoError(t, err)
-	require.Len(t, stats, 8)
+	require.Len(t, stats, 10)
 	require.Contains(t, stats, "assigned-addresses")
 	require.EqualValues(t, largeValue, stats["assigned-addresses"])
 }
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -139,6 +141,10 @@ type LocalSubnet struct {
 	Stats            SubnetStats
 	StatsCollectedAt time.Time
 
+	// The delegated prefix utilization by the delegated prefix length.
+	// The lengths whose utilization cannot be computed are not included.
+	PdUtilizationByDelegatedLen map[int]float64
+
 	AddressPools []AddressPool `pg:"rel:has-many"`
 	PrefixPools  []PrefixPool  `pg:"rel:has-many"`
 
@@ -166,6 +172,10 @@ type Subnet struct {
 	PdUtilization    int16
 	Stats            SubnetStats
 	StatsCollectedAt time.Time
+
+	// Indicates that all addresses or delegated prefixes available in
+	// the subnet are assigned and the new clients cannot be served.
+	Exhausted bool `pg:",use_zero"`
 }
 
 // Returns local subnet id for the specified daemon.
@@ -188,6 +198,254 @@ func (s *Subnet) GetKeaParameters(daemonID int64) *keaconfig.SubnetParameters {
 	return nil
 }
 
+// Returns the Kea parameters of the subnet and its shared network, and the
+// configuration of the specified daemon. They are the configuration levels
+// from which the effective subnet parameters are resolved. The shared
+// network parameters and the daemon's configuration are nil when they have
+// not been fetched together with the subnet.
+func (s *Subnet) getInheritanceLevels(daemonID int64) (subnetParams *keaconfig.SubnetParameters, sharedNetworkParams *keaconfig.SharedNetworkParameters, config *KeaConfig) {
+	for _, ls := range s.LocalSubnets {
+		if ls.DaemonID != daemonID {
+			continue
+		}
+		subnetParams = ls.KeaParameters
+		if s.SharedNetwork != nil {
+			sharedNetworkParams = s.SharedNetwork.GetKeaParameters(daemonID)
+		}
+		if ls.Daemon != nil && ls.Daemon.KeaDaemon != nil && ls.Daemon.KeaDaemon.Config != nil && ls.Daemon.KeaDaemon.Config.Config != nil {
+			config = ls.Daemon.KeaDaemon.Config
+		}
+		return
+	}
+	return
+}
+
+// Returns the valid lifetime parameters effective for the subnet configured
+// in the specified daemon. The parameters are resolved from the subnet-level,
+// shared network-level and global-level configuration. The shared network
+// and the daemon's configuration are only taken into account when they have
+// been fetched together with the subnet.
+func (s *Subnet) GetEffectiveValidLifetimeParameters(daemonID int64) keaconfig.ValidLifetimeParameters {
+	var levels []keaconfig.ValidLifetimeParameters
+	subnetParams, sharedNetworkParams, config := s.getInheritanceLevels(daemonID)
+	if subnetParams != nil {
+		levels = append(levels, subnetParams.ValidLifetimeParameters)
+	}
+	if sharedNetworkParams != nil {
+		levels = append(levels, sharedNetworkParams.ValidLifetimeParameters)
+	}
+	if config != nil {
+		levels = append(levels, config.GetValidLifetimeParameters())
+	}
+	return keaconfig.ResolveValidLifetimeParameters(levels...)
+}
+
+// Returns the DDNS parameters effective for the subnet configured in the
+// specified daemon. The parameters are resolved like in
+// GetEffectiveValidLifetimeParameters.
+func (s *Subnet) GetEffectiveDDNSParameters(daemonID int64) keaconfig.DDNSParameters {
+	var levels []keaconfig.DDNSParameters
+	subnetParams, sharedNetworkParams, config := s.getInheritanceLevels(daemonID)
+	if subnetParams != nil {
+		levels = append(levels, subnetParams.DDNSParameters)
+	}
+	if sharedNetworkParams != nil {
+		levels = append(levels, sharedNetworkParams.DDNSParameters)
+	}
+	if config != nil {
+		levels = append(levels, config.GetDDNSParameters())
+	}
+	return keaconfig.ResolveDDNSParameters(levels...)
+}
+
+// Returns the hostname sanitization parameters effective for the subnet
+// configured in the specified daemon. The parameters are resolved like in
+// GetEffectiveValidLifetimeParameters.
+func (s *Subnet) GetEffectiveHostnameCharParameters(daemonID int64) keaconfig.HostnameCharParameters {
+	var levels []keaconfig.HostnameCharParameters
+	subnetParams, sharedNetworkParams, config := s.getInheritanceLevels(daemonID)
+	if subnetParams != nil {
+		levels = append(levels, subnetParams.HostnameCharParameters)
+	}
+	if sharedNetworkParams != nil {
+		levels = append(levels, sharedNetworkParams.HostnameCharParameters)
+	}
+	if config != nil {
+		levels = append(levels, config.GetHostnameCharParameters())
+	}
+	return keaconfig.ResolveHostnameCharParameters(levels...)
+}
+
+// Returns the DHCPv4 boot parameters and the authoritative flag effective
+// for the subnet configured in the specified daemon. The parameters are
+// resolved like in GetEffectiveValidLifetimeParameters.
+func (s *Subnet) GetEffectiveBootParameters(daemonID int64) keaconfig.BootParameters {
+	var levels []keaconfig.BootParameters
+	subnetParams, sharedNetworkParams, config := s.getInheritanceLevels(daemonID)
+	if subnetParams != nil {
+		levels = append(levels, subnetParams.GetBootParameters())
+	}
+	if sharedNetworkParams != nil {
+		levels = append(levels, sharedNetworkParams.GetBootParameters())
+	}
+	if config != nil {
+		levels = append(levels, config.GetBootParameters())
+	}
+	return keaconfig.ResolveBootParameters(levels...)
+}
+
+// Returns the allocators effective for the subnet configured in the
+// specified daemon. The parameters are resolved like in
+// GetEffectiveValidLifetimeParameters.
+func (s *Subnet) GetEffectiveAllocatorParameters(daemonID int64) keaconfig.AllocatorParameters {
+	var levels []keaconfig.AllocatorParameters
+	subnetParams, sharedNetworkParams, config := s.getInheritanceLevels(daemonID)
+	if subnetParams != nil {
+		levels = append(levels, subnetParams.GetAllocatorParameters())
+	}
+	if sharedNetworkParams != nil {
+		levels = append(levels, sharedNetworkParams.GetAllocatorParameters())
+	}
+	if config != nil {
+		levels = append(levels, config.GetAllocatorParameters())
+	}
+	return keaconfig.ResolveAllocatorParameters(levels...)
+}
+
+// Returns the DHCPv4 client identification parameters effective for the
+// subnet configured in the specified daemon. The parameters are resolved
+// like in GetEffectiveValidLifetimeParameters.
+func (s *Subnet) GetEffectiveClientIdentificationParameters(daemonID int64) keaconfig.ClientIdentificationParameters {
+	var levels []keaconfig.ClientIdentificationParameters
+	subnetParams, sharedNetworkParams, config := s.getInheritanceLevels(daemonID)
+	if subnetParams != nil {
+		levels = append(levels, subnetParams.GetClientIdentificationParameters())
+	}
+	if sharedNetworkParams != nil {
+		levels = append(levels, sharedNetworkParams.GetClientIdentificationParameters())
+	}
+	if config != nil {
+		levels = append(levels, config.GetClientIdentificationParameters())
+	}
+	return keaconfig.ResolveClientIdentificationParameters(levels...)
+}
+
+// Returns the DHCPv6 preferred lifetime parameters effective for the subnet
+// configured in the specified daemon. The parameters are resolved like in
+// GetEffectiveValidLifetimeParameters.
+func (s *Subnet) GetEffectivePreferredLifetimeParameters(daemonID int64) keaconfig.PreferredLifetimeParameters {
+	var levels []keaconfig.PreferredLifetimeParameters
+	subnetParams, sharedNetworkParams, config := s.getInheritanceLevels(daemonID)
+	if subnetParams != nil {
+		levels = append(levels, subnetParams.PreferredLifetimeParameters)
+	}
+	if sharedNetworkParams != nil {
+		levels = append(levels, sharedNetworkParams.PreferredLifetimeParameters)
+	}
+	if config != nil {
+		levels = append(levels, config.GetPreferredLifetimeParameters())
+	}
+	return keaconfig.ResolvePreferredLifetimeParameters(levels...)
+}
+
+// Returns the DHCPv6 rapid commit flag effective for the subnet configured
+// in the specified daemon. The flag is resolved like the other parameters
+// in GetEffectiveValidLifetimeParameters.
+func (s *Subnet) GetEffectiveRapidCommit(daemonID int64) *bool {
+	var levels []*bool
+	subnetParams, sharedNetworkParams, config := s.getInheritanceLevels(daemonID)
+	if subnetParams != nil {
+		levels = append(levels, subnetParams.RapidCommit)
+	}
+	if sharedNetworkParams != nil {
+		levels = append(levels, sharedNetworkParams.RapidCommit)
+	}
+	if config != nil {
+		levels = append(levels, config.GetRapidCommit())
+	}
+	return keaconfig.ResolveRapidCommit(levels...)
+}
+
+// Configuration level from which the effective DHCP option comes.
+type DHCPOptionScope string
+
+const (
+	DHCPOptionScopeSubnet        DHCPOptionScope = "subnet"
+	DHCPOptionScopeSharedNetwork DHCPOptionScope = "shared-network"
+	DHCPOptionScopeGlobal        DHCPOptionScope = "global"
+)
+
+// DHCP option effective for a subnet and the configuration level it
+// comes from.
+type EffectiveDHCPOption struct {
+	Option DHCPOption
+	Scope  DHCPOptionScope
+}
+
+// Returns the top-level DHCP option with the specified code effective for
+// the subnet configured in the specified daemon. The option is resolved
+// from the subnet-level, shared network-level and global-level
+// configuration. The shared network and the daemon's configuration are only
+// taken into account when they have been fetched together with the subnet.
+// The lookup is used to parse the global options. It returns nil if the
+// option is not configured at any level.
+func (s *Subnet) GetEffectiveOption(daemonID int64, code uint16, lookup keaconfig.DHCPOptionDefinitionLookup) (*EffectiveDHCPOption, error) {
+	universe := storkutil.IPv4
+	space := dhcpmodel.DHCPv4OptionSpace
+	if s.GetFamily() == 6 {
+		universe = storkutil.IPv6
+		space = dhcpmodel.DHCPv6OptionSpace
+	}
+	findOption := func(options []DHCPOption) *DHCPOption {
+		for i := range options {
+			if options[i].Code == code && options[i].Space == space {
+				return &options[i]
+			}
+		}
+		return nil
+	}
+
+	for _, ls := range s.LocalSubnets {
+		if ls.DaemonID != daemonID {
+			continue
+		}
+		if option := findOption(ls.DHCPOptionSet); option != nil {
+			return &EffectiveDHCPOption{Option: *option, Scope: DHCPOptionScopeSubnet}, nil
+		}
+		if s.SharedNetwork != nil {
+			for _, lsn := range s.SharedNetwork.LocalSharedNetworks {
+				if lsn.DaemonID != daemonID {
+					continue
+				}
+				if option := findOption(lsn.DHCPOptionSet); option != nil {
+					return &EffectiveDHCPOption{Option: *option, Scope: DHCPOptionScopeSharedNetwork}, nil
+				}
+			}
+		}
+		_, _, config := s.getInheritanceLevels(daemonID)
+		if config == nil {
+			return nil, nil
+		}
+		var globalOptions []DHCPOption
+		for _, optionData := range config.GetDHCPOptions() {
+			// Kea assumes the top-level option space when it is not specified.
+			if optionData.Space == "" {
+				optionData.Space = space
+			}
+			option, err := NewDHCPOptionFromKea(optionData, universe, lookup)
+			if err != nil {
+				return nil, pkgerrors.WithMessagef(err, "problem parsing the global DHCP options of the daemon %d", daemonID)
+			}
+			globalOptions = append(globalOptions, *option)
+		}
+		if option := findOption(globalOptions); option != nil {
+			return &EffectiveDHCPOption{Option: *option, Scope: DHCPOptionScopeGlobal}, nil
+		}
+		return nil, nil
+	}
+	return nil, nil
+}
+
 // Returns subnet prefix.
 func (s *Subnet) GetPrefix() string {
 	return s.Prefix
@@ -551,6 +809,9 @@ type SubnetsByPageFilters struct {
 	LocalSubnetID *int64
 	Family        *int64
 	Text          *string
+	// The zero value selects the subnets not belonging to any shared
+	// network.
+	SharedNetworkID *int64
 }
 
 // Shorthand to set the IPv4 family.
@@ -620,6 +881,15 @@ func GetSubnetsByPage(dbi dbops.DBI, offset, limit int64, filters *SubnetsByPage
 		q = q.Where("ls.local_subnet_id = ?", *filters.LocalSubnetID)
 	}
 
+	// Filter by shared network ID.
+	if filters.SharedNetworkID != nil {
+		if *filters.SharedNetworkID == 0 {
+			q = q.Where("subnet.shared_network_id IS NULL")
+		} else {
+			q = q.Where("subnet.shared_network_id = ?", *filters.SharedNetworkID)
+		}
+	}
+
 	// Quick filtering by subnet prefix, pool ranges or shared network name.
 	if filters.Text != nil {
 		// The combination of the concat and host functions reconstruct the textual
@@ -650,12 +920,43 @@ func GetSubnetsByPage(dbi dbops.DBI, offset, limit int64, filters *SubnetsByPage
 	return subnets, int64(total), err
 }
 
+// Default number of the subnets fetched from the database at once when
+// iterating over the subnets page by page.
+const DefaultSubnetsPageSize int64 = 1000
+
+// Fetches the subnets matching the filters page by page and calls the
+// callback for each page. It allows for processing a large number of
+// subnets without loading all of them into memory at once. The subnets
+// are ordered by ID. The page size defaults to DefaultSubnetsPageSize if
+// it is not positive. The nil filters select all subnets. The iteration
+// stops when the callback returns an error, and this error is returned.
+func ForEachSubnetsPage(dbi dbops.DBI, filters *SubnetsByPageFilters, pageSize int64, callback func(subnets []Subnet) error) error {
+	if pageSize <= 0 {
+		pageSize = DefaultSubnetsPageSize
+	}
+	for offset := int64(0); ; offset += pageSize {
+		subnets, total, err := GetSubnetsByPage(dbi, offset, pageSize, filters, "id", SortDirAsc)
+		if err != nil {
+			return err
+		}
+		if len(subnets) == 0 {
+			return nil
+		}
+		if err = callback(subnets); err != nil {
+			return err
+		}
+		if offset+int64(len(subnets)) >= total {
+			return nil
+		}
+	}
+}
+
 // Get list of Subnets with LocalSubnets ordered by SharedNetworkID.
 func GetSubnetsWithLocalSubnets(dbi dbops.DBI) ([]*Subnet, error) {
 	subnets := []*Subnet{}
 	q := dbi.Model(&subnets)
 	// only selected columns are returned for performance reasons
-	q = q.Column("id", "shared_network_id", "prefix")
+	q = q.Column("id", "shared_network_id", "prefix", "exhausted")
 	q = q.Relation("LocalSubnets")
 	q = q.Order("shared_network_id ASC")
 
@@ -670,6 +971,92 @@ func GetSubnetsWithLocalSubnets(dbi dbops.DBI) ([]*Subnet, error) {
 	return subnets, nil
 }
 
+// The number of the subnets and the totals of the assigned leases of an
+// app summed from the statistics stored in its local subnets.
+type AppSubnetStats struct {
+	AppID       int64
+	SubnetCount int64
+	// Assigned IPv4 addresses.
+	AssignedAddresses *big.Int
+	// Assigned IPv6 addresses.
+	AssignedNAs *big.Int
+	// Assigned delegated prefixes.
+	AssignedPDs *big.Int
+}
+
+// Returns the total number of the leases assigned by the app.
+func (s *AppSubnetStats) GetAssignedLeases() *big.Int {
+	total := big.NewInt(0)
+	for _, value := range []*big.Int{s.AssignedAddresses, s.AssignedNAs, s.AssignedPDs} {
+		if value != nil {
+			total.Add(total, value)
+		}
+	}
+	return total
+}
+
+// Returns the SQL expression summing the values of the specified statistic
+// stored in the local subnets. Kea returns -1 when the statistic is not
+// available, which is stored as the maximum uint64 value. Such values and
+// the values that are not non-negative integers are skipped.
+func getLocalSubnetStatSumExpr(name string) string {
+	value := fmt.Sprintf("(local_subnet.stats->>'%s')", name)
+	return fmt.Sprintf("COALESCE(SUM(%[1]s::numeric) FILTER (WHERE %[1]s ~ '^[0-9]+$' AND %[1]s <> '%[2]d'), 0)::text",
+		value, uint64(math.MaxUint64))
+}
+
+// Returns the number of the subnets and the totals of the assigned leases
+// of each app by app ID. They are aggregated by the database from the
+// statistics stored in the local subnets, so the subnets are not loaded.
+// The apps without subnets are not included.
+func GetAppSubnetStats(dbi dbops.DBI) (map[int64]*AppSubnetStats, error) {
+	var rows []struct {
+		AppID             int64
+		SubnetCount       int64
+		AssignedAddresses string
+		AssignedNAs       string `pg:"assigned_nas"`
+		AssignedPDs       string `pg:"assigned_pds"`
+	}
+	err := dbi.Model((*LocalSubnet)(nil)).
+		ColumnExpr("daemon.app_id AS app_id").
+		ColumnExpr("COUNT(DISTINCT local_subnet.subnet_id) AS subnet_count").
+		ColumnExpr("? AS assigned_addresses", pg.Safe(getLocalSubnetStatSumExpr("assigned-addresses"))).
+		ColumnExpr("? AS assigned_nas", pg.Safe(getLocalSubnetStatSumExpr("assigned-nas"))).
+		ColumnExpr("? AS assigned_pds", pg.Safe(getLocalSubnetStatSumExpr("assigned-pds"))).
+		Join("JOIN daemon").JoinOn("daemon.id = local_subnet.daemon_id").
+		Group("daemon.app_id").
+		Select(&rows)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "problem getting the subnet statistics of the apps")
+	}
+
+	// The totals may exceed the uint64 range, so they are returned as text.
+	var parseErr error
+	parse := func(appID int64, text string) *big.Int {
+		value, ok := big.NewInt(0).SetString(text, 10)
+		if !ok {
+			parseErr = pkgerrors.Errorf("invalid assigned leases total %s of app %d", text, appID)
+			return nil
+		}
+		return value
+	}
+
+	stats := make(map[int64]*AppSubnetStats, len(rows))
+	for _, row := range rows {
+		stats[row.AppID] = &AppSubnetStats{
+			AppID:             row.AppID,
+			SubnetCount:       row.SubnetCount,
+			AssignedAddresses: parse(row.AppID, row.AssignedAddresses),
+			AssignedNAs:       parse(row.AppID, row.AssignedNAs),
+			AssignedPDs:       parse(row.AppID, row.AssignedPDs),
+		}
+	}
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	return stats, nil
+}
+
 // Associates a daemon with the subnet having a specified ID and prefix
 // in a transaction. Internally, the association is made via the local_subnet
 // table which holds the information about the subnet from the given daemon
@@ -877,6 +1264,9 @@ func GetAppLocalSubnets(dbi dbops.DBI, appID int64) ([]*LocalSubnet, error) {
 	q = q.Column("local_subnet.id", "local_subnet.daemon_id", "local_subnet.subnet_id", "local_subnet.local_subnet_id")
 	q = q.Relation("Subnet")
 	q = q.Relation("Daemon.App")
+	q = q.Relation("PrefixPools", func(q *orm.Query) (*orm.Query, error) {
+		return q.Order("prefix_pool.id ASC"), nil
+	})
 	q = q.Where("d.app_id = ?", appID)
 
 	err := q.Select()
@@ -890,12 +1280,13 @@ func GetAppLocalSubnets(dbi dbops.DBI, appID int64) ([]*LocalSubnet, error) {
 	return subnets, nil
 }
 
-// Update stats pulled for given local subnet.
+// Update stats pulled for given local subnet. The delegated prefix
+// utilization by the delegated prefix length must be set by the caller.
 func (lsn *LocalSubnet) UpdateStats(dbi dbops.DBI, stats SubnetStats) error {
 	lsn.Stats = stats
 	lsn.StatsCollectedAt = storkutil.UTCNow()
 	q := dbi.Model(lsn)
-	q = q.Column("stats", "stats_collected_at")
+	q = q.Column("stats", "stats_collected_at", "pd_utilization_by_delegated_len")
 	q = q.WherePK()
 	result, err := q.Update()
 	if err != nil {
@@ -908,7 +1299,70 @@ func (lsn *LocalSubnet) UpdateStats(dbi dbops.DBI, stats SubnetStats) error {
 	return err
 }
 
-// Update statistics in Subnet.
+// Lease statistics of the address pools of a local subnet by pool index.
+// The statistic names are stripped from the pool prefix, e.g.,
+// assigned-addresses.
+type PoolStats map[int64]SubnetStats
+
+// Matches the address pool statistics, e.g., pool[0].assigned-addresses.
+var poolStatPattern = regexp.MustCompile(`^pool\[(\d+)\]\.(.+)$`)
+
+// Returns the lease statistics of the address pools of the local subnet by
+// pool index. The pool-level statistics are stored in the local subnet
+// statistics with the pool prefix, e.g., pool[0].assigned-addresses. The
+// delegated prefix pool statistics are not included. It returns an empty
+// map if the pool-level statistics are not available.
+func (lsn *LocalSubnet) GetPoolStats() PoolStats {
+	poolStats := PoolStats{}
+	for name, value := range lsn.Stats {
+		match := poolStatPattern.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+		poolIndex, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		if poolStats[poolIndex] == nil {
+			poolStats[poolIndex] = SubnetStats{}
+		}
+		poolStats[poolIndex][match[2]] = value
+	}
+	return poolStats
+}
+
+// Adds the value of the statistic to the counter. It returns false if the
+// statistic is missing or has an unexpected type. The counter is not
+// modified in this case.
+func (s SubnetStats) AddToCounter(counter *storkutil.BigCounter, name string) bool {
+	switch value := s[name].(type) {
+	case uint64:
+		counter.AddUint64(value)
+		return true
+	case *big.Int:
+		counter.AddBigInt(value)
+		return true
+	default:
+		return false
+	}
+}
+
+// Returns the address utilization of the pool with the specified index as
+// a fraction of the assigned addresses (or NAs) in the total addresses (or
+// NAs). It returns zero if the pool statistics are not available.
+func (s PoolStats) GetUtilization(poolIndex int64) float64 {
+	stats := s[poolIndex]
+	total := storkutil.NewBigCounter(0)
+	assigned := storkutil.NewBigCounter(0)
+	for _, suffix := range []string{"addresses", "nas"} {
+		stats.AddToCounter(total, "total-"+suffix)
+		stats.AddToCounter(assigned, "assigned-"+suffix)
+	}
+	return assigned.DivideSafeBy(total)
+}
+
+// Update statistics in Subnet. It also updates the exhausted flag which
+// must be set by the caller.
 func (s *Subnet) UpdateStatistics(dbi dbops.DBI, statistics utilizationStats) error {
 	addrUtilization := statistics.GetAddressUtilization()
 	pdUtilization := statistics.GetDelegatedPrefixUtilization()
@@ -917,7 +1371,7 @@ func (s *Subnet) UpdateStatistics(dbi dbops.DBI, statistics utilizationStats) er
 	s.Stats = statistics.GetStatistics()
 	s.StatsCollectedAt = time.Now().UTC()
 	q := dbi.Model(s)
-	q = q.Column("addr_utilization", "pd_utilization", "stats", "stats_collected_at")
+	q = q.Column("addr_utilization", "pd_utilization", "stats", "stats_collected_at", "exhausted")
 	q = q.WherePK()
 	result, err := q.Update()
 	if err != nil {
@@ -932,16 +1386,25 @@ func (s *Subnet) UpdateStatistics(dbi dbops.DBI, statistics utilizationStats) er
 // Deletes subnets which are not associated with any apps. Returns deleted subnet
 // count and an error.
 func DeleteOrphanedSubnets(dbi dbops.DBI) (int64, error) {
+	subnets, err := DeleteAndGetOrphanedSubnets(dbi)
+	return int64(len(subnets)), err
+}
+
+// Deletes subnets having no association with any daemons and returns
+// the deleted subnets.
+func DeleteAndGetOrphanedSubnets(dbi dbops.DBI) ([]Subnet, error) {
 	subquery := dbi.Model(&[]LocalSubnet{}).
 		Column("id").
 		Limit(1).
 		Where("subnet.id = local_subnet.subnet_id")
-	result, err := dbi.Model(&[]Subnet{}).
+	subnets := []Subnet{}
+	_, err := dbi.Model(&subnets).
 		Where("(?) IS NULL", subquery).
+		Returning("*").
 		Delete()
 	if err != nil {
 		err = pkgerrors.Wrapf(err, "problem deleting orphaned subnets")
-		return 0, err
+		return nil, err
 	}
-	return int64(result.RowsAffected()), nil
+	return subnets, nil
 }
@@ -3,6 +3,7 @@ package dbmodel
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"math/big"
@@ -656,6 +657,183 @@ func TestGetSubnetsByPage(t *testing.T) {
 	require.Empty(t, returned)
 }
 
+// Adds the subnets used in the tests iterating over the subnets page by
+// page. There are five IPv4 and two IPv6 subnets. Two of the IPv4 subnets
+// belong to the shared network. All subnets are served by the first app.
+func addPaginationTestSubnets(t *testing.T, db *pg.DB) ([]*App, *SharedNetwork) {
+	apps := addTestApps(t, db)
+
+	sharedNetwork := &SharedNetwork{
+		Name:   "foo",
+		Family: 4,
+	}
+	err := AddSharedNetwork(db, sharedNetwork)
+	require.NoError(t, err)
+
+	prefixes := []string{
+		"192.0.2.0/24", "192.0.3.0/24", "192.0.4.0/24", "192.0.5.0/24",
+		"192.0.6.0/24", "2001:db8:1::/64", "2001:db8:2::/64",
+	}
+	for i, prefix := range prefixes {
+		subnet := &Subnet{
+			Prefix: prefix,
+			LocalSubnets: []*LocalSubnet{
+				{
+					DaemonID:      apps[0].Daemons[0].ID,
+					LocalSubnetID: int64(i + 1),
+				},
+			},
+		}
+		if i == 1 || i == 3 {
+			subnet.SharedNetworkID = sharedNetwork.ID
+		}
+		err = AddSubnet(db, subnet)
+		require.NoError(t, err)
+		err = AddLocalSubnets(db, subnet)
+		require.NoError(t, err)
+	}
+	return apps, sharedNetwork
+}
+
+// Test that the subnets are filtered by the shared network.
+func TestGetSubnetsByPageSharedNetworkFilter(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	_, sharedNetwork := addPaginationTestSubnets(t, db)
+
+	// Act
+	inNetwork, inNetworkCount, errInNetwork := GetSubnetsByPage(db, 0, 10, &SubnetsByPageFilters{
+		SharedNetworkID: newPtr(sharedNetwork.ID),
+	}, "id", SortDirAsc)
+	global, globalCount, errGlobal := GetSubnetsByPage(db, 0, 10, &SubnetsByPageFilters{
+		SharedNetworkID: newPtr(int64(0)),
+		Family:          newPtr(int64(4)),
+	}, "id", SortDirAsc)
+
+	// Assert
+	require.NoError(t, errInNetwork)
+	require.EqualValues(t, 2, inNetworkCount)
+	require.Len(t, inNetwork, 2)
+	require.Equal(t, "192.0.3.0/24", inNetwork[0].Prefix)
+	require.Equal(t, "192.0.5.0/24", inNetwork[1].Prefix)
+
+	require.NoError(t, errGlobal)
+	require.EqualValues(t, 3, globalCount)
+	require.Len(t, global, 3)
+	require.Equal(t, "192.0.2.0/24", global[0].Prefix)
+	require.Equal(t, "192.0.4.0/24", global[1].Prefix)
+	require.Equal(t, "192.0.6.0/24", global[2].Prefix)
+}
+
+// Test that all subnets are visited when iterating page by page, including
+// the page sizes not dividing the number of subnets and the page sizes
+// exceeding it.
+func TestForEachSubnetsPage(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	addPaginationTestSubnets(t, db)
+
+	for _, testCase := range []struct {
+		pageSize      int64
+		expectedPages []int
+	}{
+		{1, []int{1, 1, 1, 1, 1, 1, 1}},
+		{3, []int{3, 3, 1}},
+		{7, []int{7}},
+		{10, []int{7}},
+		{0, []int{7}},
+	} {
+		t.Run(fmt.Sprint(testCase.pageSize), func(t *testing.T) {
+			var pages []int
+			var prefixes []string
+
+			// Act
+			err := ForEachSubnetsPage(db, nil, testCase.pageSize, func(subnets []Subnet) error {
+				pages = append(pages, len(subnets))
+				for _, subnet := range subnets {
+					require.Len(t, subnet.LocalSubnets, 1)
+					prefixes = append(prefixes, subnet.Prefix)
+				}
+				return nil
+			})
+
+			// Assert
+			require.NoError(t, err)
+			require.Equal(t, testCase.expectedPages, pages)
+			require.Equal(t, []string{
+				"192.0.2.0/24", "192.0.3.0/24", "192.0.4.0/24", "192.0.5.0/24",
+				"192.0.6.0/24", "2001:db8:1::/64", "2001:db8:2::/64",
+			}, prefixes)
+		})
+	}
+}
+
+// Test that only the subnets matching the filters are visited when
+// iterating page by page.
+func TestForEachSubnetsPageFilters(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	apps, sharedNetwork := addPaginationTestSubnets(t, db)
+
+	collect := func(filters *SubnetsByPageFilters) []string {
+		var prefixes []string
+		err := ForEachSubnetsPage(db, filters, 2, func(subnets []Subnet) error {
+			for _, subnet := range subnets {
+				prefixes = append(prefixes, subnet.Prefix)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		return prefixes
+	}
+
+	// Act
+	ipv6 := collect(&SubnetsByPageFilters{Family: newPtr(int64(6))})
+	inNetwork := collect(&SubnetsByPageFilters{SharedNetworkID: newPtr(sharedNetwork.ID)})
+	firstApp := collect(&SubnetsByPageFilters{AppID: newPtr(apps[0].ID)})
+	secondApp := collect(&SubnetsByPageFilters{AppID: newPtr(apps[1].ID)})
+
+	// Assert
+	require.Equal(t, []string{"2001:db8:1::/64", "2001:db8:2::/64"}, ipv6)
+	require.Equal(t, []string{"192.0.3.0/24", "192.0.5.0/24"}, inNetwork)
+	require.Len(t, firstApp, 7)
+	require.Empty(t, secondApp)
+}
+
+// Test that the iteration stops when the callback returns an error and
+// that the callback isn't called when there are no subnets.
+func TestForEachSubnetsPageStop(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	calls := 0
+	err := ForEachSubnetsPage(db, nil, 2, func(subnets []Subnet) error {
+		calls++
+		return nil
+	})
+	require.NoError(t, err)
+	require.Zero(t, calls)
+
+	addPaginationTestSubnets(t, db)
+
+	// Act
+	err = ForEachSubnetsPage(db, nil, 2, func(subnets []Subnet) error {
+		calls++
+		return errors.New("stop")
+	})
+
+	// Assert
+	require.EqualError(t, err, "stop")
+	require.Equal(t, 1, calls)
+}
+
 // Test that the subnet can be fetched by local ID and app ID.
 func TestGetAppLocalSubnets(t *testing.T) {
 	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
@@ -735,6 +913,94 @@ func TestUpdateStats(t *testing.T) {
 	require.EqualValues(t, 123, lsn.Stats["hakuna-matata"])
 }
 
+// Test that the subnet counts and the assigned leases totals are aggregated
+// per app from the stored statistics.
+func TestGetAppSubnetStats(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	apps := addTestSubnetApps(t, db)
+	require.Len(t, apps, 2)
+
+	addSubnet := func(prefix string, daemons []*Daemon, stats []SubnetStats) {
+		subnet := &Subnet{Prefix: prefix}
+		err := AddSubnet(db, subnet)
+		require.NoError(t, err)
+		for i, daemon := range daemons {
+			err = AddDaemonToSubnet(db, subnet, daemon)
+			require.NoError(t, err)
+			lsn := &LocalSubnet{}
+			err = db.Model(lsn).
+				Where("daemon_id = ?", daemon.ID).
+				Where("subnet_id = ?", subnet.ID).
+				Select()
+			require.NoError(t, err)
+			err = lsn.UpdateStats(db, stats[i])
+			require.NoError(t, err)
+		}
+	}
+
+	hugeValue, _ := big.NewInt(0).SetString("36893488147419103232", 10)
+	addSubnet("192.0.2.0/24",
+		[]*Daemon{apps[0].Daemons[0], apps[1].Daemons[0]},
+		[]SubnetStats{
+			{"assigned-addresses": uint64(10), "total-addresses": uint64(256)},
+			{"assigned-addresses": uint64(7)},
+		})
+	// The unavailable statistic returned as -1 by Kea is skipped.
+	addSubnet("192.0.3.0/24",
+		[]*Daemon{apps[0].Daemons[0]},
+		[]SubnetStats{
+			{"assigned-addresses": uint64(math.MaxUint64)},
+		})
+	addSubnet("2001:db8:1::/64",
+		[]*Daemon{apps[0].Daemons[1]},
+		[]SubnetStats{
+			{"assigned-nas": hugeValue, "assigned-pds": uint64(5)},
+		})
+
+	// Act
+	stats, err := GetAppSubnetStats(db)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+
+	require.Contains(t, stats, apps[0].ID)
+	appStats := stats[apps[0].ID]
+	require.EqualValues(t, apps[0].ID, appStats.AppID)
+	require.EqualValues(t, 3, appStats.SubnetCount)
+	require.EqualValues(t, big.NewInt(10), appStats.AssignedAddresses)
+	require.EqualValues(t, hugeValue, appStats.AssignedNAs)
+	require.EqualValues(t, big.NewInt(5), appStats.AssignedPDs)
+	require.EqualValues(t, big.NewInt(0).Add(hugeValue, big.NewInt(15)), appStats.GetAssignedLeases())
+
+	require.Contains(t, stats, apps[1].ID)
+	appStats = stats[apps[1].ID]
+	require.EqualValues(t, 1, appStats.SubnetCount)
+	require.EqualValues(t, big.NewInt(7), appStats.AssignedAddresses)
+	require.Zero(t, appStats.AssignedNAs.Sign())
+	require.Zero(t, appStats.AssignedPDs.Sign())
+	require.EqualValues(t, big.NewInt(7), appStats.GetAssignedLeases())
+}
+
+// Test that the total assigned leases include all kinds of the leases
+// and tolerate the missing values.
+func TestAppSubnetStatsGetAssignedLeases(t *testing.T) {
+	// Arrange
+	stats := &AppSubnetStats{
+		AssignedAddresses: big.NewInt(3),
+		AssignedPDs:       big.NewInt(4),
+	}
+
+	// Act
+	total := stats.GetAssignedLeases()
+
+	// Assert
+	require.EqualValues(t, big.NewInt(7), total)
+}
+
 // Test that global shared networks and subnet instances are committed
 // to the database and associated with the given app. This test is very
 // simple. More exhaustive tests are implemented in backend/apps.
@@ -1166,6 +1432,86 @@ func TestSerializeLocalSubnetWithNoneStatsToJSON(t *testing.T) {
 	require.Nil(t, deserialized.Stats)
 }
 
+// Test that the pool-level statistics are extracted from the local subnet
+// statistics by pool index.
+func TestLocalSubnetGetPoolStats(t *testing.T) {
+	// Arrange
+	localSubnet := &LocalSubnet{
+		Stats: SubnetStats{
+			"total-addresses":            uint64(356),
+			"pool[0].total-addresses":    uint64(100),
+			"pool[0].assigned-addresses": uint64(50),
+			"pool[1].total-addresses":    uint64(256),
+			"pd-pool[0].total-pds":       uint64(10),
+		},
+	}
+
+	// Act
+	poolStats := localSubnet.GetPoolStats()
+
+	// Assert
+	require.Len(t, poolStats, 2)
+	require.Equal(t, SubnetStats{
+		"total-addresses":    uint64(100),
+		"assigned-addresses": uint64(50),
+	}, poolStats[0])
+	require.Equal(t, SubnetStats{
+		"total-addresses": uint64(256),
+	}, poolStats[1])
+}
+
+// Test that the empty pool statistics are returned when the local subnet
+// has no pool-level statistics.
+func TestLocalSubnetGetPoolStatsNoStats(t *testing.T) {
+	require.Empty(t, (&LocalSubnet{}).GetPoolStats())
+	require.Empty(t, (&LocalSubnet{Stats: SubnetStats{"total-addresses": uint64(1)}}).GetPoolStats())
+}
+
+// Test that the pool utilization is computed from the pool statistics.
+func TestPoolStatsGetUtilization(t *testing.T) {
+	// Arrange
+	hugeCount, ok := new(big.Int).SetString("36893488147419103232", 10)
+	require.True(t, ok)
+	poolStats := PoolStats{
+		0: SubnetStats{
+			"total-addresses":    uint64(100),
+			"assigned-addresses": uint64(100),
+		},
+		1: SubnetStats{
+			"total-nas":    hugeCount,
+			"assigned-nas": new(big.Int).Div(hugeCount, big.NewInt(4)),
+		},
+		2: SubnetStats{
+			"assigned-addresses": uint64(10),
+		},
+	}
+
+	// Act & Assert
+	require.EqualValues(t, 1.0, poolStats.GetUtilization(0))
+	require.InDelta(t, 0.25, poolStats.GetUtilization(1), 0.001)
+	require.Zero(t, poolStats.GetUtilization(2))
+	require.Zero(t, poolStats.GetUtilization(3))
+}
+
+// Test that the statistic values of the supported types are added to the
+// counter and the other values are skipped.
+func TestSubnetStatsAddToCounter(t *testing.T) {
+	// Arrange
+	stats := SubnetStats{
+		"total-addresses":    uint64(100),
+		"total-nas":          big.NewInt(20),
+		"assigned-addresses": "foo",
+	}
+	counter := storkutil.NewBigCounter(0)
+
+	// Act & Assert
+	require.True(t, stats.AddToCounter(counter, "total-addresses"))
+	require.True(t, stats.AddToCounter(counter, "total-nas"))
+	require.False(t, stats.AddToCounter(counter, "assigned-addresses"))
+	require.False(t, stats.AddToCounter(counter, "assigned-nas"))
+	require.EqualValues(t, 120, counter.ToUint64())
+}
+
 // Test that the subnet and its pools are updated properly.
 func TestUpdateSubnet(t *testing.T) {
 	// Arrange
@@ -1490,6 +1836,499 @@ func TestSubnetGetKeaParameters(t *testing.T) {
 	require.Nil(t, subnet.GetKeaParameters(1000))
 }
 
+// Test that the effective valid lifetime parameters are resolved from the
+// subnet, shared network and global configuration levels.
+func TestSubnetGetEffectiveValidLifetimeParameters(t *testing.T) {
+	config, err := NewKeaConfigFromJSON(`{
+		"Dhcp4": {
+			"valid-lifetime": 4000,
+			"min-valid-lifetime": 3000,
+			"max-valid-lifetime": 5000
+		}
+	}`)
+	require.NoError(t, err)
+
+	subnet := Subnet{
+		SharedNetwork: &SharedNetwork{
+			LocalSharedNetworks: []*LocalSharedNetwork{
+				{
+					DaemonID: 110,
+					KeaParameters: &keaconfig.SharedNetworkParameters{
+						ValidLifetimeParameters: keaconfig.ValidLifetimeParameters{
+							MinValidLifetime: storkutil.Ptr[int64](2000),
+						},
+					},
+				},
+			},
+		},
+		LocalSubnets: []*LocalSubnet{
+			{
+				DaemonID: 110,
+				Daemon: &Daemon{
+					KeaDaemon: &KeaDaemon{
+						Config: config,
+					},
+				},
+				KeaParameters: &keaconfig.SubnetParameters{
+					ValidLifetimeParameters: keaconfig.ValidLifetimeParameters{
+						MaxValidLifetime: storkutil.Ptr[int64](6000),
+					},
+				},
+			},
+			{
+				DaemonID: 111,
+				KeaParameters: &keaconfig.SubnetParameters{
+					ValidLifetimeParameters: keaconfig.ValidLifetimeParameters{
+						ValidLifetime: storkutil.Ptr[int64](1000),
+					},
+				},
+			},
+		},
+	}
+	params := subnet.GetEffectiveValidLifetimeParameters(110)
+	require.NotNil(t, params.ValidLifetime)
+	require.EqualValues(t, 4000, *params.ValidLifetime)
+	require.NotNil(t, params.MinValidLifetime)
+	require.EqualValues(t, 2000, *params.MinValidLifetime)
+	require.NotNil(t, params.MaxValidLifetime)
+	require.EqualValues(t, 6000, *params.MaxValidLifetime)
+
+	// The daemon has not been fetched for the second local subnet so
+	// only the subnet-level parameters are available.
+	params = subnet.GetEffectiveValidLifetimeParameters(111)
+	require.NotNil(t, params.ValidLifetime)
+	require.EqualValues(t, 1000, *params.ValidLifetime)
+	require.Nil(t, params.MinValidLifetime)
+	require.Nil(t, params.MaxValidLifetime)
+
+	params = subnet.GetEffectiveValidLifetimeParameters(1000)
+	require.Nil(t, params.ValidLifetime)
+	require.Nil(t, params.MinValidLifetime)
+	require.Nil(t, params.MaxValidLifetime)
+}
+
+// Test that the effective DDNS parameters are resolved from the subnet,
+// shared network and global configuration levels.
+func TestSubnetGetEffectiveDDNSParameters(t *testing.T) {
+	config, err := NewKeaConfigFromJSON(`{
+		"Dhcp6": {
+			"ddns-update-on-renew": false,
+			"ddns-use-conflict-resolution": true,
+			"ddns-conflict-resolution-mode": "check-with-dhcid"
+		}
+	}`)
+	require.NoError(t, err)
+
+	subnet := Subnet{
+		SharedNetwork: &SharedNetwork{
+			LocalSharedNetworks: []*LocalSharedNetwork{
+				{
+					DaemonID: 110,
+					KeaParameters: &keaconfig.SharedNetworkParameters{
+						DDNSParameters: keaconfig.DDNSParameters{
+							DDNSUpdateOnRenew: storkutil.Ptr(true),
+						},
+					},
+				},
+			},
+		},
+		LocalSubnets: []*LocalSubnet{
+			{
+				DaemonID: 110,
+				Daemon: &Daemon{
+					KeaDaemon: &KeaDaemon{
+						Config: config,
+					},
+				},
+				KeaParameters: &keaconfig.SubnetParameters{
+					DDNSParameters: keaconfig.DDNSParameters{
+						DDNSConflictResolutionMode: storkutil.Ptr("check-exists-with-dhcid"),
+					},
+				},
+			},
+			{
+				DaemonID: 111,
+				KeaParameters: &keaconfig.SubnetParameters{
+					DDNSParameters: keaconfig.DDNSParameters{
+						DDNSUseConflictResolution: storkutil.Ptr(false),
+					},
+				},
+			},
+		},
+	}
+	params := subnet.GetEffectiveDDNSParameters(110)
+	require.NotNil(t, params.DDNSUpdateOnRenew)
+	require.True(t, *params.DDNSUpdateOnRenew)
+	require.NotNil(t, params.DDNSUseConflictResolution)
+	require.True(t, *params.DDNSUseConflictResolution)
+	require.NotNil(t, params.DDNSConflictResolutionMode)
+	require.Equal(t, "check-exists-with-dhcid", *params.DDNSConflictResolutionMode)
+
+	// The daemon has not been fetched for the second local subnet so
+	// only the subnet-level parameters are available.
+	params = subnet.GetEffectiveDDNSParameters(111)
+	require.Nil(t, params.DDNSUpdateOnRenew)
+	require.NotNil(t, params.DDNSUseConflictResolution)
+	require.False(t, *params.DDNSUseConflictResolution)
+	require.Nil(t, params.DDNSConflictResolutionMode)
+
+	params = subnet.GetEffectiveDDNSParameters(1000)
+	require.Nil(t, params.DDNSUpdateOnRenew)
+	require.Nil(t, params.DDNSUseConflictResolution)
+	require.Nil(t, params.DDNSConflictResolutionMode)
+}
+
+// Test that the effective prefix of the generated client names is resolved
+// from the subnet and global configuration levels.
+func TestSubnetGetEffectiveDDNSGeneratedPrefix(t *testing.T) {
+	config, err := NewKeaConfigFromJSON(`{
+		"Dhcp4": {
+			"ddns-generated-prefix": "myhost"
+		}
+	}`)
+	require.NoError(t, err)
+
+	daemon := &Daemon{
+		KeaDaemon: &KeaDaemon{
+			Config: config,
+		},
+	}
+	subnet := Subnet{
+		LocalSubnets: []*LocalSubnet{
+			{
+				DaemonID:      110,
+				Daemon:        daemon,
+				KeaParameters: &keaconfig.SubnetParameters{},
+			},
+			{
+				DaemonID: 111,
+				Daemon:   daemon,
+				KeaParameters: &keaconfig.SubnetParameters{
+					DDNSParameters: keaconfig.DDNSParameters{
+						DDNSGeneratedPrefix: storkutil.Ptr("guest"),
+					},
+				},
+			},
+		},
+	}
+
+	params := subnet.GetEffectiveDDNSParameters(110)
+	require.NotNil(t, params.DDNSGeneratedPrefix)
+	require.Equal(t, "myhost", *params.DDNSGeneratedPrefix)
+
+	params = subnet.GetEffectiveDDNSParameters(111)
+	require.NotNil(t, params.DDNSGeneratedPrefix)
+	require.Equal(t, "guest", *params.DDNSGeneratedPrefix)
+}
+
+// Test that the effective hostname sanitization parameters are resolved
+// from the subnet, shared network and global configuration levels.
+func TestSubnetGetEffectiveHostnameCharParameters(t *testing.T) {
+	config, err := NewKeaConfigFromJSON(`{
+		"Dhcp4": {
+			"hostname-char-set": "[^A-Za-z0-9.-]",
+			"hostname-char-replacement": "x"
+		}
+	}`)
+	require.NoError(t, err)
+
+	subnet := Subnet{
+		SharedNetwork: &SharedNetwork{
+			LocalSharedNetworks: []*LocalSharedNetwork{
+				{
+					DaemonID: 110,
+					KeaParameters: &keaconfig.SharedNetworkParameters{
+						HostnameCharParameters: keaconfig.HostnameCharParameters{
+							HostnameCharReplacement: storkutil.Ptr("-"),
+						},
+					},
+				},
+			},
+		},
+		LocalSubnets: []*LocalSubnet{
+			{
+				DaemonID: 110,
+				Daemon: &Daemon{
+					KeaDaemon: &KeaDaemon{
+						Config: config,
+					},
+				},
+				KeaParameters: &keaconfig.SubnetParameters{},
+			},
+			{
+				DaemonID: 111,
+				KeaParameters: &keaconfig.SubnetParameters{
+					HostnameCharParameters: keaconfig.HostnameCharParameters{
+						HostnameCharSet: storkutil.Ptr("[^A-Za-z0-9-]"),
+					},
+				},
+			},
+		},
+	}
+	params := subnet.GetEffectiveHostnameCharParameters(110)
+	require.NotNil(t, params.HostnameCharReplacement)
+	require.Equal(t, "-", *params.HostnameCharReplacement)
+	require.NotNil(t, params.HostnameCharSet)
+	require.Equal(t, "[^A-Za-z0-9.-]", *params.HostnameCharSet)
+
+	// The daemon has not been fetched for the second local subnet so
+	// only the subnet-level parameters are available.
+	params = subnet.GetEffectiveHostnameCharParameters(111)
+	require.Nil(t, params.HostnameCharReplacement)
+	require.NotNil(t, params.HostnameCharSet)
+	require.Equal(t, "[^A-Za-z0-9-]", *params.HostnameCharSet)
+
+	params = subnet.GetEffectiveHostnameCharParameters(1000)
+	require.Nil(t, params.HostnameCharReplacement)
+	require.Nil(t, params.HostnameCharSet)
+}
+
+// Test that the effective boot parameters are resolved from the subnet,
+// shared network and global configuration levels.
+func TestSubnetGetEffectiveBootParameters(t *testing.T) {
+	config, err := NewKeaConfigFromJSON(`{
+		"Dhcp4": {
+			"authoritative": true,
+			"boot-file-name": "/tmp/global-boot",
+			"next-server": "192.0.2.1"
+		}
+	}`)
+	require.NoError(t, err)
+
+	subnet := Subnet{
+		SharedNetwork: &SharedNetwork{
+			LocalSharedNetworks: []*LocalSharedNetwork{
+				{
+					DaemonID: 110,
+					KeaParameters: &keaconfig.SharedNetworkParameters{
+						NextServer: storkutil.Ptr("192.0.2.2"),
+					},
+				},
+			},
+		},
+		LocalSubnets: []*LocalSubnet{
+			{
+				DaemonID: 110,
+				Daemon: &Daemon{
+					KeaDaemon: &KeaDaemon{
+						Config: config,
+					},
+				},
+				KeaParameters: &keaconfig.SubnetParameters{
+					ServerHostname: storkutil.Ptr("pxe.example.org"),
+				},
+			},
+		},
+	}
+	params := subnet.GetEffectiveBootParameters(110)
+	require.NotNil(t, params.Authoritative)
+	require.True(t, *params.Authoritative)
+	require.NotNil(t, params.BootFileName)
+	require.Equal(t, "/tmp/global-boot", *params.BootFileName)
+	require.NotNil(t, params.NextServer)
+	require.Equal(t, "192.0.2.2", *params.NextServer)
+	require.NotNil(t, params.ServerHostname)
+	require.Equal(t, "pxe.example.org", *params.ServerHostname)
+
+	params = subnet.GetEffectiveBootParameters(1000)
+	require.Nil(t, params.Authoritative)
+	require.Nil(t, params.BootFileName)
+	require.Nil(t, params.NextServer)
+	require.Nil(t, params.ServerHostname)
+}
+
+// Test that the effective allocators are resolved from the subnet, shared
+// network and global configuration levels.
+func TestSubnetGetEffectiveAllocatorParameters(t *testing.T) {
+	config, err := NewKeaConfigFromJSON(`{
+		"Dhcp6": {
+			"allocator": "flq",
+			"pd-allocator": "flq"
+		}
+	}`)
+	require.NoError(t, err)
+
+	subnet := Subnet{
+		SharedNetwork: &SharedNetwork{
+			LocalSharedNetworks: []*LocalSharedNetwork{
+				{
+					DaemonID: 110,
+					KeaParameters: &keaconfig.SharedNetworkParameters{
+						PDAllocator: storkutil.Ptr("random"),
+					},
+				},
+			},
+		},
+		LocalSubnets: []*LocalSubnet{
+			{
+				DaemonID: 110,
+				Daemon: &Daemon{
+					KeaDaemon: &KeaDaemon{
+						Config: config,
+					},
+				},
+				KeaParameters: &keaconfig.SubnetParameters{},
+			},
+			{
+				DaemonID: 111,
+				Daemon: &Daemon{
+					KeaDaemon: &KeaDaemon{
+						Config: config,
+					},
+				},
+				KeaParameters: &keaconfig.SubnetParameters{
+					Allocator: storkutil.Ptr("iterative"),
+				},
+			},
+		},
+	}
+	params := subnet.GetEffectiveAllocatorParameters(110)
+	require.NotNil(t, params.Allocator)
+	require.Equal(t, "flq", *params.Allocator)
+	require.NotNil(t, params.PDAllocator)
+	require.Equal(t, "random", *params.PDAllocator)
+
+	params = subnet.GetEffectiveAllocatorParameters(111)
+	require.NotNil(t, params.Allocator)
+	require.Equal(t, "iterative", *params.Allocator)
+	require.NotNil(t, params.PDAllocator)
+	require.Equal(t, "flq", *params.PDAllocator)
+
+	params = subnet.GetEffectiveAllocatorParameters(1000)
+	require.Nil(t, params.Allocator)
+	require.Nil(t, params.PDAllocator)
+}
+
+// Test that the effective client identification parameters are resolved
+// from the subnet, shared network and global configuration levels.
+func TestSubnetGetEffectiveClientIdentificationParameters(t *testing.T) {
+	config, err := NewKeaConfigFromJSON(`{
+		"Dhcp4": {
+			"echo-client-id": false
+		}
+	}`)
+	require.NoError(t, err)
+
+	subnet := Subnet{
+		SharedNetwork: &SharedNetwork{
+			LocalSharedNetworks: []*LocalSharedNetwork{
+				{
+					DaemonID: 110,
+					KeaParameters: &keaconfig.SharedNetworkParameters{
+						MatchClientID: storkutil.Ptr(false),
+					},
+				},
+			},
+		},
+		LocalSubnets: []*LocalSubnet{
+			{
+				DaemonID: 110,
+				Daemon: &Daemon{
+					KeaDaemon: &KeaDaemon{
+						Config: config,
+					},
+				},
+				KeaParameters: &keaconfig.SubnetParameters{},
+			},
+			{
+				DaemonID: 111,
+				Daemon: &Daemon{
+					KeaDaemon: &KeaDaemon{
+						Config: config,
+					},
+				},
+				KeaParameters: &keaconfig.SubnetParameters{
+					MatchClientID: storkutil.Ptr(true),
+				},
+			},
+		},
+	}
+	params := subnet.GetEffectiveClientIdentificationParameters(110)
+	require.NotNil(t, params.MatchClientID)
+	require.False(t, *params.MatchClientID)
+	require.NotNil(t, params.EchoClientID)
+	require.False(t, *params.EchoClientID)
+
+	params = subnet.GetEffectiveClientIdentificationParameters(111)
+	require.NotNil(t, params.MatchClientID)
+	require.True(t, *params.MatchClientID)
+	require.NotNil(t, params.EchoClientID)
+	require.False(t, *params.EchoClientID)
+
+	params = subnet.GetEffectiveClientIdentificationParameters(1000)
+	require.Nil(t, params.MatchClientID)
+	require.Nil(t, params.EchoClientID)
+}
+
+// Test that the effective DHCPv6 preferred lifetime parameters and the
+// rapid commit flag are resolved from the subnet, shared network and
+// global configuration levels.
+func TestSubnetGetEffectivePreferredLifetimeParametersAndRapidCommit(t *testing.T) {
+	config, err := NewKeaConfigFromJSON(`{
+		"Dhcp6": {
+			"preferred-lifetime": 3000,
+			"min-preferred-lifetime": 2000,
+			"max-preferred-lifetime": 4000,
+			"rapid-commit": false
+		}
+	}`)
+	require.NoError(t, err)
+
+	subnet := Subnet{
+		SharedNetwork: &SharedNetwork{
+			LocalSharedNetworks: []*LocalSharedNetwork{
+				{
+					DaemonID: 110,
+					KeaParameters: &keaconfig.SharedNetworkParameters{
+						PreferredLifetimeParameters: keaconfig.PreferredLifetimeParameters{
+							MinPreferredLifetime: storkutil.Ptr[int64](1000),
+						},
+						RapidCommit: storkutil.Ptr(true),
+					},
+				},
+			},
+		},
+		LocalSubnets: []*LocalSubnet{
+			{
+				DaemonID: 110,
+				Daemon: &Daemon{
+					KeaDaemon: &KeaDaemon{
+						Config: config,
+					},
+				},
+				KeaParameters: &keaconfig.SubnetParameters{
+					PreferredLifetimeParameters: keaconfig.PreferredLifetimeParameters{
+						MaxPreferredLifetime: storkutil.Ptr[int64](5000),
+					},
+				},
+			},
+		},
+	}
+	params := subnet.GetEffectivePreferredLifetimeParameters(110)
+	require.NotNil(t, params.PreferredLifetime)
+	require.EqualValues(t, 3000, *params.PreferredLifetime)
+	require.NotNil(t, params.MinPreferredLifetime)
+	require.EqualValues(t, 1000, *params.MinPreferredLifetime)
+	require.NotNil(t, params.MaxPreferredLifetime)
+	require.EqualValues(t, 5000, *params.MaxPreferredLifetime)
+
+	rapidCommit := subnet.GetEffectiveRapidCommit(110)
+	require.NotNil(t, rapidCommit)
+	require.True(t, *rapidCommit)
+
+	// The subnet-level rapid commit flag takes precedence.
+	subnet.LocalSubnets[0].KeaParameters.RapidCommit = storkutil.Ptr(false)
+	rapidCommit = subnet.GetEffectiveRapidCommit(110)
+	require.NotNil(t, rapidCommit)
+	require.False(t, *rapidCommit)
+
+	params = subnet.GetEffectivePreferredLifetimeParameters(1000)
+	require.Nil(t, params.PreferredLifetime)
+	require.Nil(t, params.MinPreferredLifetime)
+	require.Nil(t, params.MaxPreferredLifetime)
+	require.Nil(t, subnet.GetEffectiveRapidCommit(1000))
+}
+
 // Test implementation of the dhcpmodel.SubnetAccessor interface (GetPrefix() function).
 func TestSubnetGetPrefix(t *testing.T) {
 	subnet := Subnet{
@@ -1662,3 +2501,104 @@ func TestJoinSubnets(t *testing.T) {
 	require.EqualValues(t, 2, subnet0.LocalSubnets[1].DaemonID)
 	require.EqualValues(t, 3, subnet0.LocalSubnets[2].DaemonID)
 }
+
+// Test that the effective DHCP option is resolved from the subnet, shared
+// network and global configuration levels.
+func TestSubnetGetEffectiveOption(t *testing.T) {
+	// Arrange
+	config, err := NewKeaConfigFromJSON(`{
+		"Dhcp4": {
+			"option-data": [
+				{
+					"code": 6,
+					"data": "192.0.2.1"
+				},
+				{
+					"code": 15,
+					"data": "global.example.org"
+				},
+				{
+					"code": 42,
+					"data": "192.0.2.42"
+				}
+			]
+		}
+	}`)
+	require.NoError(t, err)
+
+	subnet := Subnet{
+		Prefix: "192.0.2.0/24",
+		SharedNetwork: &SharedNetwork{
+			LocalSharedNetworks: []*LocalSharedNetwork{
+				{
+					DaemonID: 110,
+					DHCPOptionSet: []DHCPOption{
+						{
+							Code:     15,
+							Space:    dhcpmodel.DHCPv4OptionSpace,
+							Universe: storkutil.IPv4,
+							Fields: []DHCPOptionField{
+								{FieldType: dhcpmodel.FqdnField, Values: []any{"network.example.org"}},
+							},
+						},
+					},
+				},
+			},
+		},
+		LocalSubnets: []*LocalSubnet{
+			{
+				DaemonID: 110,
+				Daemon: &Daemon{
+					KeaDaemon: &KeaDaemon{
+						Config: config,
+					},
+				},
+				DHCPOptionSet: []DHCPOption{
+					{
+						Code:     6,
+						Space:    dhcpmodel.DHCPv4OptionSpace,
+						Universe: storkutil.IPv4,
+						Fields: []DHCPOptionField{
+							{FieldType: dhcpmodel.IPv4AddressField, Values: []any{"192.0.2.2"}},
+						},
+					},
+				},
+			},
+		},
+	}
+	lookup := NewDHCPOptionDefinitionLookup()
+
+	// Act & Assert
+	// Overridden at the subnet level.
+	option, err := subnet.GetEffectiveOption(110, 6, lookup)
+	require.NoError(t, err)
+	require.NotNil(t, option)
+	require.Equal(t, DHCPOptionScopeSubnet, option.Scope)
+	require.Equal(t, []any{"192.0.2.2"}, option.Option.Fields[0].Values)
+
+	// Overridden at the shared network level.
+	option, err = subnet.GetEffectiveOption(110, 15, lookup)
+	require.NoError(t, err)
+	require.NotNil(t, option)
+	require.Equal(t, DHCPOptionScopeSharedNetwork, option.Scope)
+	require.Equal(t, []any{"network.example.org"}, option.Option.Fields[0].Values)
+
+	// Inherited from the global level.
+	option, err = subnet.GetEffectiveOption(110, 42, lookup)
+	require.NoError(t, err)
+	require.NotNil(t, option)
+	require.Equal(t, DHCPOptionScopeGlobal, option.Scope)
+	require.EqualValues(t, 42, option.Option.Code)
+	require.Equal(t, dhcpmodel.DHCPv4OptionSpace, option.Option.Space)
+	require.Len(t, option.Option.Fields, 1)
+
+	// Not configured at any level.
+	option, err = subnet.GetEffectiveOption(110, 3, lookup)
+	require.NoError(t, err)
+	require.Nil(t, option)
+
+	// The subnet is not configured in the daemon.
+	option, err = subnet.GetEffectiveOption(111, 6, lookup)
+	require.NoError(t, err)
+	require.Nil(t, option)
+}
@@ -0,0 +1,79 @@
+package dbmodel
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	pkgerrors "github.com/pkg/errors"
+	dbops "isc.org/stork/server/database"
+)
+
+// A subnet utilization recorded at a given time. The samples are appended
+// by the statistics puller and used to render the utilization trends. The
+// utilizations are stored in permilles like in the Subnet.
+type SubnetUtilizationSample struct {
+	ID              int64
+	SubnetID        int64
+	SampledAt       time.Time
+	AddrUtilization int16 `pg:",use_zero"`
+	PdUtilization   int16 `pg:",use_zero"`
+}
+
+// Adds the subnet utilization samples in a single query.
+func addSubnetUtilizationSamples(dbi dbops.DBI, samples []SubnetUtilizationSample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	_, err := dbi.Model(&samples).Insert()
+	if err != nil {
+		return pkgerrors.Wrapf(err, "problem adding %d subnet utilization samples", len(samples))
+	}
+	return nil
+}
+
+// Deletes the subnet utilization samples recorded before the specified
+// time. It returns the number of the deleted samples.
+func DeleteSubnetUtilizationSamplesBefore(dbi dbops.DBI, before time.Time) (int64, error) {
+	result, err := dbi.Model((*SubnetUtilizationSample)(nil)).
+		Where("sampled_at < ?", before).
+		Delete()
+	if err != nil {
+		return 0, pkgerrors.Wrapf(err, "problem deleting subnet utilization samples recorded before %s", before)
+	}
+	return int64(result.RowsAffected()), nil
+}
+
+// Adds the subnet utilization samples and deletes the samples recorded
+// before the specified time. The samples are added in a single query. It
+// begins a new transaction when dbi has a *pg.DB type or uses an existing
+// transaction when dbi has a *pg.Tx type.
+func AddSubnetUtilizationSamples(dbi dbops.DBI, samples []SubnetUtilizationSample, pruneBefore time.Time) error {
+	add := func(tx *pg.Tx) error {
+		if err := addSubnetUtilizationSamples(tx, samples); err != nil {
+			return err
+		}
+		_, err := DeleteSubnetUtilizationSamplesBefore(tx, pruneBefore)
+		return err
+	}
+	if db, ok := dbi.(*pg.DB); ok {
+		return db.RunInTransaction(context.Background(), add)
+	}
+	return add(dbi.(*pg.Tx))
+}
+
+// Returns the utilization samples of the subnet recorded since the
+// specified time ordered by the sampling time.
+func GetSubnetUtilizationSamples(dbi dbops.DBI, subnetID int64, since time.Time) ([]SubnetUtilizationSample, error) {
+	samples := []SubnetUtilizationSample{}
+	err := dbi.Model(&samples).
+		Where("subnet_id = ?", subnetID).
+		Where("sampled_at >= ?", since).
+		OrderExpr("sampled_at ASC").
+		OrderExpr("id ASC").
+		Select()
+	if err != nil {
+		return nil, pkgerrors.Wrapf(err, "problem getting utilization samples of subnet %d", subnetID)
+	}
+	return samples, nil
+}
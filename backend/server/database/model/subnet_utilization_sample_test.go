@@ -0,0 +1,114 @@
+package dbmodel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	dbtest "isc.org/stork/server/database/test"
+)
+
+// Test that the subnet utilization samples are added, selected by subnet
+// and time, and pruned.
+func TestSubnetUtilizationSamples(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	subnets := []*Subnet{{Prefix: "192.0.2.0/24"}, {Prefix: "2001:db8:1::/64"}}
+	for _, subnet := range subnets {
+		err := AddSubnet(db, subnet)
+		require.NoError(t, err)
+	}
+
+	now := time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)
+	samples := []SubnetUtilizationSample{
+		{SubnetID: subnets[0].ID, SampledAt: now.Add(-48 * time.Hour), AddrUtilization: 100},
+		{SubnetID: subnets[0].ID, SampledAt: now.Add(-time.Hour), AddrUtilization: 200},
+		{SubnetID: subnets[0].ID, SampledAt: now, AddrUtilization: 0},
+		{SubnetID: subnets[1].ID, SampledAt: now, AddrUtilization: 300, PdUtilization: 400},
+	}
+
+	// Act
+	err := AddSubnetUtilizationSamples(db, samples, now.Add(-72*time.Hour))
+
+	// Assert
+	require.NoError(t, err)
+
+	returned, err := GetSubnetUtilizationSamples(db, subnets[0].ID, now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, returned, 2)
+	require.EqualValues(t, 200, returned[0].AddrUtilization)
+	require.Equal(t, now.Add(-time.Hour), returned[0].SampledAt)
+	require.Zero(t, returned[1].AddrUtilization)
+	require.Equal(t, now, returned[1].SampledAt)
+
+	returned, err = GetSubnetUtilizationSamples(db, subnets[1].ID, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, returned, 1)
+	require.EqualValues(t, 300, returned[0].AddrUtilization)
+	require.EqualValues(t, 400, returned[0].PdUtilization)
+
+	// Act
+	// Adding the next samples prunes the old ones.
+	err = AddSubnetUtilizationSamples(db, []SubnetUtilizationSample{
+		{SubnetID: subnets[0].ID, SampledAt: now.Add(time.Hour), AddrUtilization: 500},
+	}, now.Add(-24*time.Hour))
+
+	// Assert
+	require.NoError(t, err)
+	returned, err = GetSubnetUtilizationSamples(db, subnets[0].ID, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, returned, 3)
+	require.EqualValues(t, 200, returned[0].AddrUtilization)
+	require.EqualValues(t, 500, returned[2].AddrUtilization)
+}
+
+// Test that the samples recorded before the specified time are deleted.
+func TestDeleteSubnetUtilizationSamplesBefore(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	subnet := &Subnet{Prefix: "192.0.2.0/24"}
+	err := AddSubnet(db, subnet)
+	require.NoError(t, err)
+
+	now := time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)
+	err = addSubnetUtilizationSamples(db, []SubnetUtilizationSample{
+		{SubnetID: subnet.ID, SampledAt: now.Add(-2 * time.Hour)},
+		{SubnetID: subnet.ID, SampledAt: now.Add(-time.Hour)},
+		{SubnetID: subnet.ID, SampledAt: now},
+	})
+	require.NoError(t, err)
+
+	// Act
+	count, err := DeleteSubnetUtilizationSamplesBefore(db, now.Add(-time.Hour))
+
+	// Assert
+	require.NoError(t, err)
+	require.EqualValues(t, 1, count)
+	returned, err := GetSubnetUtilizationSamples(db, subnet.ID, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, returned, 2)
+
+	// The samples are deleted together with the subnet.
+	_, err = DeleteOrphanedSubnets(db)
+	require.NoError(t, err)
+	returned, err = GetSubnetUtilizationSamples(db, subnet.ID, time.Time{})
+	require.NoError(t, err)
+	require.Empty(t, returned)
+}
+
+// Test that adding no samples only prunes the old ones.
+func TestAddSubnetUtilizationSamplesEmpty(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	// Act
+	err := AddSubnetUtilizationSamples(db, nil, time.Now().UTC())
+
+	// Assert
+	require.NoError(t, err)
+}
@@ -0,0 +1,83 @@
+package dbops
+
+import (
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// Routes the database queries between the primary database and its read
+// replicas. The writes and the queries that must see the latest data go
+// to the primary database. The heavy read and reporting queries (e.g.,
+// exports and aggregates) go to the replicas selected in a round-robin
+// fashion. If there are no replicas, all queries go to the primary
+// database.
+type ReplicaRouter struct {
+	primary  *PgDB
+	replicas []*PgDB
+	next     atomic.Uint64
+}
+
+// Creates a router for the primary database and the read replicas.
+func NewReplicaRouter(primary *PgDB, replicas ...*PgDB) *ReplicaRouter {
+	return &ReplicaRouter{
+		primary:  primary,
+		replicas: replicas,
+	}
+}
+
+// Returns the connection to the primary database. It should be used for
+// the writes.
+func (r *ReplicaRouter) Writer() *PgDB {
+	return r.primary
+}
+
+// Returns the connection to the next read replica or to the primary
+// database if there are no replicas. It should be used for the heavy
+// read and reporting queries that tolerate the replication lag.
+func (r *ReplicaRouter) Reader() *PgDB {
+	if len(r.replicas) == 0 {
+		return r.primary
+	}
+	index := (r.next.Add(1) - 1) % uint64(len(r.replicas))
+	return r.replicas[index]
+}
+
+// Closes the connections to the read replicas. The connection to the
+// primary database is not closed because it is owned by the caller. It is
+// safe to call it for a nil router.
+func (r *ReplicaRouter) CloseReplicas() {
+	if r == nil {
+		return
+	}
+	for _, replica := range r.replicas {
+		replica.Close()
+	}
+}
+
+// Returns the number of the read replicas.
+func (r *ReplicaRouter) GetReplicaCount() int {
+	return len(r.replicas)
+}
+
+// Connects to the read replicas specified in the database settings. It
+// closes the already established connections if connecting to any replica
+// fails.
+func NewReplicaConns(settings *DatabaseSettings) ([]*PgDB, error) {
+	replicaSettings, err := settings.GetReplicaSettings()
+	if err != nil {
+		return nil, err
+	}
+	var replicas []*PgDB
+	for _, replicaSetting := range replicaSettings {
+		replica, err := NewPgDBConn(replicaSetting)
+		if err != nil {
+			for _, replica := range replicas {
+				replica.Close()
+			}
+			return nil, errors.WithMessagef(err, "problem connecting to the database replica %s", replicaSetting.Host)
+		}
+		replicas = append(replicas, replica)
+	}
+	return replicas, nil
+}
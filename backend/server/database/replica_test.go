@@ -0,0 +1,115 @@
+package dbops
+
+import (
+	"testing"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the reads go to the replicas in the round-robin fashion and the
+// writes go to the primary database.
+func TestReplicaRouter(t *testing.T) {
+	// Arrange
+	primary := pg.Connect(&pg.Options{Addr: "primary:5432"})
+	defer primary.Close()
+	replica1 := pg.Connect(&pg.Options{Addr: "replica1:5432"})
+	defer replica1.Close()
+	replica2 := pg.Connect(&pg.Options{Addr: "replica2:5432"})
+	defer replica2.Close()
+
+	router := NewReplicaRouter(primary, replica1, replica2)
+
+	// Act & Assert
+	require.Equal(t, 2, router.GetReplicaCount())
+	require.Same(t, primary, router.Writer())
+	require.Same(t, replica1, router.Reader())
+	require.Same(t, replica2, router.Reader())
+	require.Same(t, replica1, router.Reader())
+	require.Same(t, primary, router.Writer())
+}
+
+// Test that the reads go to the primary database when there are no replicas.
+func TestReplicaRouterNoReplicas(t *testing.T) {
+	primary := pg.Connect(&pg.Options{Addr: "primary:5432"})
+	defer primary.Close()
+
+	router := NewReplicaRouter(primary)
+
+	require.Zero(t, router.GetReplicaCount())
+	require.Same(t, primary, router.Reader())
+	require.Same(t, primary, router.Writer())
+}
+
+// Test that the replica settings are derived from the primary database
+// settings.
+func TestGetReplicaSettings(t *testing.T) {
+	// Arrange
+	settings := &DatabaseSettings{
+		DBName:       "stork",
+		User:         "stork",
+		Password:     "secret",
+		Host:         "primary",
+		Port:         5432,
+		SSLMode:      "require",
+		ReplicaHosts: []string{"replica1", "replica2:5433", "[2001:db8::1]:5434", "2001:db8::2"},
+	}
+
+	// Act
+	replicas, err := settings.GetReplicaSettings()
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, replicas, 4)
+
+	require.Equal(t, "replica1", replicas[0].Host)
+	require.Equal(t, 5432, replicas[0].Port)
+	require.Equal(t, "replica2", replicas[1].Host)
+	require.Equal(t, 5433, replicas[1].Port)
+	require.Equal(t, "2001:db8::1", replicas[2].Host)
+	require.Equal(t, 5434, replicas[2].Port)
+	require.Equal(t, "2001:db8::2", replicas[3].Host)
+	require.Equal(t, 5432, replicas[3].Port)
+
+	for _, replica := range replicas {
+		require.Equal(t, "stork", replica.DBName)
+		require.Equal(t, "stork", replica.User)
+		require.Equal(t, "secret", replica.Password)
+		require.Equal(t, "require", replica.SSLMode)
+		require.Empty(t, replica.ReplicaHosts)
+	}
+
+	// The primary settings are not modified.
+	require.Equal(t, "primary", settings.Host)
+	require.Len(t, settings.ReplicaHosts, 4)
+}
+
+// Test that an error is returned for a replica host with an invalid port.
+func TestGetReplicaSettingsInvalidPort(t *testing.T) {
+	settings := &DatabaseSettings{
+		Host:         "primary",
+		Port:         5432,
+		ReplicaHosts: []string{"replica1:port"},
+	}
+
+	replicas, err := settings.GetReplicaSettings()
+
+	require.ErrorContains(t, err, "invalid port of the database replica host: 'replica1:port'")
+	require.Nil(t, replicas)
+}
+
+// Test that no replica settings are returned when no replicas are specified.
+func TestGetReplicaSettingsNoReplicas(t *testing.T) {
+	settings := &DatabaseSettings{Host: "primary"}
+
+	replicas, err := settings.GetReplicaSettings()
+
+	require.NoError(t, err)
+	require.Empty(t, replicas)
+}
+
+// Test that closing the replicas of a nil router doesn't panic.
+func TestReplicaRouterCloseReplicasNil(t *testing.T) {
+	var router *ReplicaRouter
+	require.NotPanics(t, router.CloseReplicas)
+}
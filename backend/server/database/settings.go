@@ -2,11 +2,14 @@ package dbops
 
 import (
 	"fmt"
+	"net"
 	"path"
+	"strconv"
 	"strings"
 
 	"github.com/go-pg/pg/v10"
 	"github.com/go-pg/pg/v10/orm"
+	pkgerrors "github.com/pkg/errors"
 
 	storkutil "isc.org/stork/util"
 )
@@ -61,6 +64,11 @@ type DatabaseSettings struct {
 	SSLKey      string
 	SSLRootCert string
 	TraceSQL    LoggingQueryPreset
+	// Read replica hosts specified as host names, IP addresses or host:port
+	// pairs. The replicas use the primary database port when it is not
+	// specified. The remaining connection parameters are shared with the
+	// primary database.
+	ReplicaHosts []string
 }
 
 // Returns generic connection parameters as a list of space separated name/value pairs.
@@ -150,6 +158,32 @@ func (s *DatabaseSettings) ConvertToConnectionString() string {
 	return strings.Join(paramsStr, " ")
 }
 
+// Returns the settings to connect to the read replicas. The returned settings
+// are the copies of the primary database settings with the replica host and
+// port. It returns an error if any replica host has an invalid port.
+func (s *DatabaseSettings) GetReplicaSettings() ([]*DatabaseSettings, error) {
+	var replicas []*DatabaseSettings
+	for _, replicaHost := range s.ReplicaHosts {
+		replica := *s
+		replica.ReplicaHosts = nil
+
+		host, portRaw, err := net.SplitHostPort(replicaHost)
+		if err != nil {
+			// No port specified.
+			replica.Host = replicaHost
+		} else {
+			port, err := strconv.ParseInt(portRaw, 10, 0)
+			if err != nil {
+				return nil, pkgerrors.Wrapf(err, "invalid port of the database replica host: '%s'", replicaHost)
+			}
+			replica.Host = host
+			replica.Port = int(port)
+		}
+		replicas = append(replicas, &replica)
+	}
+	return replicas, nil
+}
+
 // Converts generic connection parameters to go-pg specific parameters.
 func (s *DatabaseSettings) convertToPgOptions() (*PgOptions, error) {
 	pgopts := &PgOptions{Database: s.DBName, User: s.User, Password: s.Password}
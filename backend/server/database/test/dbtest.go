@@ -6,6 +6,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-pg/pg/v10"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 	dbops "isc.org/stork/server/database"
@@ -25,6 +27,42 @@ func failOnError(testArg interface{}, err error) {
 	}
 }
 
+// Checks if the template database exists. The tests can't clone a missing
+// template and the database server returns a cryptic error in this case.
+func checkTemplateExists(db *dbops.PgDB, templateDBName string) error {
+	var count int
+	_, err := db.QueryOne(pg.Scan(&count), "SELECT count(*) FROM pg_database WHERE datname = ?", templateDBName)
+	if err != nil {
+		return errors.Wrapf(err, "problem checking if the template database %s exists", templateDBName)
+	}
+	if count == 0 {
+		return errors.Errorf("template database %s does not exist; create it and migrate it to the latest version before running the tests", templateDBName)
+	}
+	return nil
+}
+
+// Checks if the template database schema version matches the version
+// expected by the tests, i.e., the highest available migration version.
+func checkTemplateVersion(templateDBName string, version, expectedVersion int64) error {
+	if version != expectedVersion {
+		return errors.Errorf("template database %s is at the migration version %d but the tests expect the version %d; migrate the template database before running the tests",
+			templateDBName, version, expectedVersion)
+	}
+	return nil
+}
+
+// Returns an error explaining why the template database couldn't be cloned.
+// The most common reason is that the template is accessed by other sessions.
+func explainCloneError(db *dbops.PgDB, templateDBName string, cloneErr error) error {
+	var count int
+	_, err := db.QueryOne(pg.Scan(&count), "SELECT count(*) FROM pg_stat_activity WHERE datname = ?", templateDBName)
+	if err == nil && count > 0 {
+		return errors.Wrapf(cloneErr, "problem cloning the template database %s; it is accessed by %d other session(s), close them before running the tests",
+			templateDBName, count)
+	}
+	return errors.Wrapf(cloneErr, "problem cloning the template database %s", templateDBName)
+}
+
 // Creates unit test setup by re-creating the database schema and returns the
 // settings to connect to the created database as standard and maintenance user.
 func createDatabaseTestCase() (settings *dbops.DatabaseSettings, maintenanceSettings *dbops.DatabaseSettings, err error) {
@@ -70,6 +108,10 @@ func createDatabaseTestCase() (settings *dbops.DatabaseSettings, maintenanceSett
 		log.Warn("The maintenance database should not be the same as the template database; otherwise, the source database may report that other users are accessing it.")
 	}
 
+	if err = checkTemplateExists(db, templateDBName); err != nil {
+		return
+	}
+
 	rand.Seed(time.Now().UnixNano())
 	dbName := fmt.Sprintf("%s%d", templateDBName, rand.Int63()) //nolint:gosec
 
@@ -82,6 +124,7 @@ func createDatabaseTestCase() (settings *dbops.DatabaseSettings, maintenanceSett
 	cmd = fmt.Sprintf(`CREATE DATABASE %s TEMPLATE %s;`, dbName, templateDBName)
 	_, err = db.Exec(cmd)
 	if err != nil {
+		err = explainCloneError(db, templateDBName, err)
 		return
 	}
 
@@ -94,9 +137,33 @@ func createDatabaseTestCase() (settings *dbops.DatabaseSettings, maintenanceSett
 	settings.DBName = dbName
 	maintenanceSettings.DBName = dbName
 
+	// Verify the schema version using the clone rather than the template.
+	// Connecting to the template would prevent the tests running in parallel
+	// from cloning it.
+	if err = checkCloneVersion(maintenanceSettings, templateDBName); err != nil {
+		_, _ = db.Exec(fmt.Sprintf(`DROP DATABASE IF EXISTS %s;`, dbName))
+		return
+	}
+
 	return settings, maintenanceSettings, nil
 }
 
+// Checks if the database cloned from the template is at the expected
+// migration version.
+func checkCloneVersion(settings *dbops.DatabaseSettings, templateDBName string) error {
+	db, err := dbops.NewPgDBConn(settings)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	version, err := dbops.CurrentVersion(db)
+	if err != nil {
+		return errors.WithMessagef(err, "problem checking the migration version of the template database %s", templateDBName)
+	}
+	return checkTemplateVersion(templateDBName, version, dbops.AvailableVersion())
+}
+
 // Returns a database connection object and teardown function.
 func prepareDBInstance(settings *dbops.DatabaseSettings) (*dbops.PgDB, func(), error) {
 	db, err := dbops.NewPgDBConn(settings)
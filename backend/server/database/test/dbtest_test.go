@@ -0,0 +1,29 @@
+package dbtest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the template database at the expected migration version
+// is accepted.
+func TestCheckTemplateVersionMatch(t *testing.T) {
+	require.NoError(t, checkTemplateVersion("storktest", 42, 42))
+}
+
+// Test that the template database at an unexpected migration version is
+// rejected with a meaningful error.
+func TestCheckTemplateVersionMismatch(t *testing.T) {
+	// Outdated template.
+	err := checkTemplateVersion("storktest", 41, 42)
+	require.ErrorContains(t, err, "template database storktest is at the migration version 41 but the tests expect the version 42")
+
+	// Template migrated by a newer code version.
+	err = checkTemplateVersion("storktest", 43, 42)
+	require.ErrorContains(t, err, "template database storktest is at the migration version 43 but the tests expect the version 42")
+
+	// Not migrated template.
+	err = checkTemplateVersion("storktest", 0, 42)
+	require.ErrorContains(t, err, "migration version 0")
+}
@@ -0,0 +1,26 @@
+package dump
+
+// The dump of the Kea statistics puller internal state. It helps to
+// diagnose why the statistics are not updated.
+type StatsPullerDump struct {
+	BasicDump
+	getState func() interface{}
+}
+
+// Construct the statistics puller state dump. The function returns the
+// serializable snapshot of the puller state. It is called when the dump
+// is executed.
+func NewStatsPullerDump(getState func() interface{}) *StatsPullerDump {
+	return &StatsPullerDump{
+		*NewBasicDump("stats-puller"),
+		getState,
+	}
+}
+
+// It dumps the snapshot of the puller internal state.
+func (d *StatsPullerDump) Execute() error {
+	d.AppendArtifact(NewBasicStructArtifact(
+		"state", d.getState(),
+	))
+	return nil
+}
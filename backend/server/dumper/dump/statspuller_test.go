@@ -0,0 +1,27 @@
+package dump_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dumppkg "isc.org/stork/server/dumper/dump"
+)
+
+// Test that the dump includes the state returned by the puller.
+func TestStatsPullerDumpExecute(t *testing.T) {
+	// Arrange
+	state := map[string]interface{}{"RpsCycle": 3}
+	dump := dumppkg.NewStatsPullerDump(func() interface{} {
+		return state
+	})
+
+	// Act
+	err := dump.Execute()
+
+	// Assert
+	require.NoError(t, err)
+	require.EqualValues(t, 1, dump.GetArtifactsNumber())
+	artifact := dump.GetArtifact(0).(dumppkg.StructArtifact)
+	require.Equal(t, "state", artifact.GetName())
+	require.Equal(t, state, artifact.GetStruct())
+}
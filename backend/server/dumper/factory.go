@@ -3,6 +3,7 @@ package dumper
 import (
 	"github.com/go-pg/pg/v10"
 	"isc.org/stork/server/agentcomm"
+	"isc.org/stork/server/apps/kea"
 	dbmodel "isc.org/stork/server/database/model"
 	"isc.org/stork/server/dumper/dump"
 )
@@ -12,6 +13,8 @@ type factory struct {
 	db              *pg.DB
 	m               *dbmodel.Machine
 	connectedAgents agentcomm.ConnectedAgents
+	// Optional Kea statistics puller. Its state is dumped if it is set.
+	statsPuller *kea.StatsPuller
 }
 
 func newFactory(db *pg.DB, m *dbmodel.Machine, agents agentcomm.ConnectedAgents) factory {
@@ -24,10 +27,16 @@ func newFactory(db *pg.DB, m *dbmodel.Machine, agents agentcomm.ConnectedAgents)
 
 // Construct createAll supported dumps.
 func (f *factory) createAll() []dump.Dump {
-	return []dump.Dump{
+	dumps := []dump.Dump{
 		dump.NewMachineDump(f.m),
 		dump.NewEventsDump(f.db, f.m),
 		dump.NewLogsDump(f.m, f.connectedAgents),
 		dump.NewSettingsDump(f.db),
 	}
+	if f.statsPuller != nil {
+		dumps = append(dumps, dump.NewStatsPullerDump(func() interface{} {
+			return f.statsPuller.GetDiagnosticState()
+		}))
+	}
+	return dumps
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"isc.org/stork/server/agentcomm"
+	"isc.org/stork/server/apps/kea"
 	dbmodel "isc.org/stork/server/database/model"
 	dbtest "isc.org/stork/server/database/test"
 	storktest "isc.org/stork/server/test/dbmodel"
@@ -48,6 +49,22 @@ func TestFactoryProducesTheUniqueDumps(t *testing.T) {
 	}
 }
 
+// Test that the stats puller state dump is created when the puller is
+// specified.
+func TestFactoryProducesStatsPullerDump(t *testing.T) {
+	// Arrange
+	m := &dbmodel.Machine{ID: 1}
+	factory := newFactory(nil, m, nil)
+	factory.statsPuller = &kea.StatsPuller{}
+
+	// Act
+	dumps := factory.createAll()
+
+	// Assert
+	require.Len(t, dumps, 5)
+	require.Equal(t, "stats-puller", dumps[4].GetName())
+}
+
 // Test that all created dumps are executed properly.
 func TestAllProducedDumpsAreExecutedWithNoErrorForValidData(t *testing.T) {
 	// Arrange
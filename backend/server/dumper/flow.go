@@ -10,14 +10,17 @@ import (
 
 	"github.com/go-pg/pg/v10"
 	"isc.org/stork/server/agentcomm"
+	"isc.org/stork/server/apps/kea"
 	dbmodel "isc.org/stork/server/database/model"
 	"isc.org/stork/server/dumper/dump"
 )
 
 // The main function of this module. It dumps the specific machine (and related data) to the tarball archive.
 // Returns closeable stream with the dump binary and error. If the machine doesn't exist it returns
-// nil and no error.
-func DumpMachine(db *pg.DB, connectedAgents agentcomm.ConnectedAgents, machineID int64) (io.ReadCloser, error) {
+// nil and no error. The naming convention specifies the paths of the artifact files in the
+// tarball. If it is nil, the flat structure with timestamps is used. The internal state of the
+// stats puller is included in the dump if the puller is not nil.
+func DumpMachine(db *pg.DB, connectedAgents agentcomm.ConnectedAgents, statsPuller *kea.StatsPuller, machineID int64, namingConvention NamingConvention) (io.ReadCloser, error) {
 	m, err := dbmodel.GetMachineByIDWithRelations(db, machineID,
 		dbmodel.MachineRelationApps,
 		dbmodel.MachineRelationDaemons,
@@ -37,9 +40,14 @@ func DumpMachine(db *pg.DB, connectedAgents agentcomm.ConnectedAgents, machineID
 
 	// Factory will create the dump instances
 	factory := newFactory(db, m, connectedAgents)
+	factory.statsPuller = statsPuller
 	// Saver will save the dumps to the tarball as JSON and raw binary files
-	// It uses a flat structure - it means the output doesn't contain subfolders.
-	saver := newTarballSaver(indentJSONSerializer, flatStructureWithTimestampNamingConvention)
+	// By default, it uses a flat structure - it means the output doesn't
+	// contain subfolders.
+	if namingConvention == nil {
+		namingConvention = flatStructureWithTimestampNamingConvention
+	}
+	saver := newTarballSaver(indentJSONSerializer, namingConvention)
 
 	// Init dump objects
 	dumps := factory.createAll()
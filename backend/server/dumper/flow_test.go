@@ -108,7 +108,7 @@ func TestDumpMachineReturnsNoErrorWhenMachineExists(t *testing.T) {
 	defer agents.Shutdown()
 
 	// Act
-	result, err := DumpMachine(db, agents, m.ID)
+	result, err := DumpMachine(db, agents, nil, m.ID, nil)
 
 	// Assert
 	require.NoError(t, err)
@@ -136,7 +136,7 @@ func TestDumpMachineReturnsProperContent(t *testing.T) {
 	fec := &storktest.FakeEventCenter{}
 	agents := agentcomm.NewConnectedAgents(&settings, fec, []byte{}, []byte{}, []byte{})
 	defer agents.Shutdown()
-	result, _ := DumpMachine(db, agents, m.ID)
+	result, _ := DumpMachine(db, agents, nil, m.ID, nil)
 	defer result.Close()
 
 	// Act
@@ -147,6 +147,53 @@ func TestDumpMachineReturnsProperContent(t *testing.T) {
 	require.Len(t, filenames, 4)
 }
 
+// Test that the machine dump uses the custom naming convention.
+func TestDumpMachineWithCustomNamingConvention(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	m := &dbmodel.Machine{
+		ID:         0,
+		Address:    "localhost",
+		AgentPort:  8080,
+		Authorized: true,
+	}
+	_ = dbmodel.AddMachine(db, m)
+	_ = dbmodel.InitializeSettings(db, 0)
+
+	settings := agentcomm.AgentsSettings{}
+	fec := &storktest.FakeEventCenter{}
+	agents := agentcomm.NewConnectedAgents(&settings, fec, []byte{}, []byte{}, []byte{})
+	defer agents.Shutdown()
+
+	// The convention places the artifacts in the subfolders named after
+	// the machine address and the dumps.
+	convention := func(dumpObj dump.Dump, artifact dump.Artifact) string {
+		return fmt.Sprintf("%s/%s/%s%s", m.Address, dumpObj.GetName(),
+			artifact.GetName(), artifact.GetExtension())
+	}
+
+	result, err := DumpMachine(db, agents, nil, m.ID, convention)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	defer result.Close()
+
+	// Act
+	filenames, err := storkutil.ListFilesInTarball(result)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, filenames, 4)
+	for _, filename := range filenames {
+		parts := strings.Split(filename, "/")
+		require.Len(t, parts, 3, filename)
+		require.Equal(t, "localhost", parts[0])
+		require.NotEmpty(t, parts[1])
+		require.NotEmpty(t, parts[2])
+	}
+}
+
 // Test that the JSON serializer does not escape characters problematic for HTML.
 func TestIndentJSONSerializerNoEscape(t *testing.T) {
 	jsonInput := `{
@@ -12,7 +12,8 @@ import (
 // Function that produces the names for the artifacts.
 // It is expected to return unique name for each dump-artifact combination.
 // The result haven't must be deterministic (e.g. may contain a timestamp).
-type namingConvention func(dump dump.Dump, artifact dump.Artifact) string
+// The name may contain slashes to place the artifact in a subfolder.
+type NamingConvention func(dump dump.Dump, artifact dump.Artifact) string
 
 // Serialize the Go object to the binary content. It is expected to
 // return human-readable output (e.g. JSON or YAML).
@@ -29,12 +30,12 @@ type saver interface {
 // Each dump artifact is located in a separate file.
 type tarballSaver struct {
 	serializer       structSerializer
-	namingConvention namingConvention
+	namingConvention NamingConvention
 }
 
 // To create the tarball saver you need to provide a serializer that specify the output format
 // for the struct artifacts and a naming convention used to name the artifact files.
-func newTarballSaver(serializer structSerializer, namingConvention namingConvention) *tarballSaver {
+func newTarballSaver(serializer structSerializer, namingConvention NamingConvention) *tarballSaver {
 	return &tarballSaver{
 		serializer:       serializer,
 		namingConvention: namingConvention,
@@ -22,8 +22,9 @@ type EventCenter interface {
 	ServeHTTP(w http.ResponseWriter, req *http.Request)
 }
 
-// EventCenter. It has channel for receiving events
-// and a SSE broker for dispatching events to subscribers.
+// EventCenter. It has channel for receiving events, a SSE broker
+// for dispatching events to subscribers and the webhooks notifying
+// the external systems.
 type eventCenter struct {
 	db     *dbops.PgDB
 	done   chan bool
@@ -31,16 +32,19 @@ type eventCenter struct {
 	events chan *dbmodel.Event
 
 	sseBroker *SSEBroker
+	webhooks  []*WebhookSubscriber
 }
 
-// Create new EventCenter object.
-func NewEventCenter(db *pg.DB) EventCenter {
+// Create new EventCenter object. The optional webhooks receive the
+// events they accept.
+func NewEventCenter(db *pg.DB, webhooks ...*WebhookSubscriber) EventCenter {
 	ec := &eventCenter{
 		db:        db,
 		done:      make(chan bool),
 		wg:        &sync.WaitGroup{},
 		events:    make(chan *dbmodel.Event),
 		sseBroker: NewSSEBroker(db),
+		webhooks:  webhooks,
 	}
 	ec.wg.Add(1)
 	go ec.mainLoop()
@@ -110,6 +114,31 @@ func CreateEvent(level dbmodel.EventLevel, text string, objects ...interface{})
 	return e
 }
 
+// Default maximum number of the individual events added to the event
+// center for a bulk operation (see AddBulkEvents).
+const DefaultBulkEventsLimit = 10
+
+// Adds the events generated by a bulk operation on multiple objects, e.g.,
+// subnets added to a daemon, to the event center. The individual events are
+// only added when their number does not exceed the limit. The summary event
+// is added after them, or alone if the limit is exceeded, so the operation is
+// always reported without flooding the event log. The summary is omitted
+// when there are no individual events or it is nil. A zero limit means that
+// only the summary is added.
+func AddBulkEvents(ec EventCenter, events []*dbmodel.Event, limit int64, summary *dbmodel.Event) {
+	if len(events) == 0 {
+		return
+	}
+	if int64(len(events)) <= limit {
+		for _, event := range events {
+			ec.AddEvent(event)
+		}
+	}
+	if summary != nil {
+		ec.AddEvent(summary)
+	}
+}
+
 func (ec *eventCenter) addEvent(level dbmodel.EventLevel, text string, objects ...interface{}) {
 	e := CreateEvent(level, text, objects...)
 	ec.AddEvent(e)
@@ -128,11 +157,15 @@ func (ec *eventCenter) Shutdown() {
 	log.Printf("Stopping EventCenter")
 	ec.done <- true
 	ec.wg.Wait()
+	for _, webhook := range ec.webhooks {
+		webhook.shutdown()
+	}
 	log.Printf("Stopped EventCenter")
 }
 
 // A main loop of EventCenter. It receives events via channel, stores
-// them into database and dispatches them to subscribers using SSE broker.
+// them into database and dispatches them to subscribers using SSE broker
+// and to the webhooks.
 func (ec *eventCenter) mainLoop() {
 	defer ec.wg.Done()
 	for {
@@ -148,6 +181,9 @@ func (ec *eventCenter) mainLoop() {
 				continue
 			}
 			ec.sseBroker.dispatchEvent(event)
+			for _, webhook := range ec.webhooks {
+				webhook.dispatchEvent(event)
+			}
 		}
 	}
 }
@@ -1,6 +1,8 @@
 package eventcenter
 
 import (
+	"fmt"
+	"net/http"
 	"testing"
 	"time"
 
@@ -420,3 +422,82 @@ func TestAddEvent(t *testing.T) {
 	require.Len(t, events, 3)
 	require.EqualValues(t, "some text", events[0].Text)
 }
+
+// Event center recording the added events.
+type recordingEventCenter struct {
+	events []*dbmodel.Event
+}
+
+func (ec *recordingEventCenter) AddInfoEvent(text string, objects ...interface{}) {
+	ec.AddEvent(CreateEvent(dbmodel.EvInfo, text, objects...))
+}
+
+func (ec *recordingEventCenter) AddWarningEvent(text string, objects ...interface{}) {
+	ec.AddEvent(CreateEvent(dbmodel.EvWarning, text, objects...))
+}
+
+func (ec *recordingEventCenter) AddErrorEvent(text string, objects ...interface{}) {
+	ec.AddEvent(CreateEvent(dbmodel.EvError, text, objects...))
+}
+
+func (ec *recordingEventCenter) AddEvent(event *dbmodel.Event) {
+	ec.events = append(ec.events, event)
+}
+
+func (ec *recordingEventCenter) Shutdown() {}
+
+func (ec *recordingEventCenter) ServeHTTP(w http.ResponseWriter, req *http.Request) {}
+
+// Creates the specified number of events for the bulk operation.
+func createBulkEvents(count int) (events []*dbmodel.Event) {
+	for i := 0; i < count; i++ {
+		events = append(events, CreateEvent(dbmodel.EvInfo, fmt.Sprintf("event %d", i)))
+	}
+	return
+}
+
+// Test that all bulk events and the summary are added when the number
+// of events is equal to the limit.
+func TestAddBulkEventsAtLimit(t *testing.T) {
+	ec := &recordingEventCenter{}
+	AddBulkEvents(ec, createBulkEvents(5), 5, CreateEvent(dbmodel.EvInfo, "summary"))
+
+	require.Len(t, ec.events, 6)
+	for i := 0; i < 5; i++ {
+		require.Equal(t, fmt.Sprintf("event %d", i), ec.events[i].Text)
+	}
+	require.Equal(t, "summary", ec.events[5].Text)
+}
+
+// Test that only the summary is added when the number of events exceeds
+// the limit.
+func TestAddBulkEventsAboveLimit(t *testing.T) {
+	ec := &recordingEventCenter{}
+	AddBulkEvents(ec, createBulkEvents(6), 5, CreateEvent(dbmodel.EvInfo, "summary"))
+
+	require.Len(t, ec.events, 1)
+	require.Equal(t, "summary", ec.events[0].Text)
+}
+
+// Test that only the summary is added when the limit is zero.
+func TestAddBulkEventsZeroLimit(t *testing.T) {
+	ec := &recordingEventCenter{}
+	AddBulkEvents(ec, createBulkEvents(1), 0, CreateEvent(dbmodel.EvInfo, "summary"))
+
+	require.Len(t, ec.events, 1)
+	require.Equal(t, "summary", ec.events[0].Text)
+}
+
+// Test that no events are added when there are no bulk events and that
+// the summary is optional.
+func TestAddBulkEventsNoEventsNoSummary(t *testing.T) {
+	ec := &recordingEventCenter{}
+	AddBulkEvents(ec, nil, 5, CreateEvent(dbmodel.EvInfo, "summary"))
+	require.Empty(t, ec.events)
+
+	AddBulkEvents(ec, createBulkEvents(2), 5, nil)
+	require.Len(t, ec.events, 2)
+
+	AddBulkEvents(ec, createBulkEvents(6), 5, nil)
+	require.Len(t, ec.events, 2)
+}
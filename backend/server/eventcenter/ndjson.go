@@ -0,0 +1,75 @@
+package eventcenter
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/pkg/errors"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Number of events fetched from the database at once while exporting.
+const ndjsonExportBatchSize = 1000
+
+// Event representation in the exported event log. The level is a string
+// so the log is readable by the ingestion pipelines without knowing the
+// level values.
+type ndjsonEvent struct {
+	ID        int64              `json:"id"`
+	CreatedAt time.Time          `json:"createdAt"`
+	Level     string             `json:"level"`
+	Text      string             `json:"text"`
+	Details   string             `json:"details,omitempty"`
+	Relations *dbmodel.Relations `json:"relations,omitempty"`
+}
+
+// Converts the event to its representation in the exported event log.
+func newNDJSONEvent(event *dbmodel.Event) ndjsonEvent {
+	return ndjsonEvent{
+		ID:        event.ID,
+		CreatedAt: event.CreatedAt,
+		Level:     event.Level.String(),
+		Text:      event.Text,
+		Details:   event.Details,
+		Relations: event.Relations,
+	}
+}
+
+// Writes the events to the encoder, one JSON object per line.
+func writeEventsNDJSON(encoder *json.Encoder, events []dbmodel.Event) error {
+	for i := range events {
+		event := &events[i]
+		err := encoder.Encode(newNDJSONEvent(event))
+		if err != nil {
+			return errors.Wrapf(err, "problem writing event %d", event.ID)
+		}
+	}
+	return nil
+}
+
+// Streams the event log matching the filter to the writer as
+// newline-delimited JSON (NDJSON), i.e., one event per line ordered from
+// the oldest. The events are fetched from the database in batches, so the
+// log of any size can be exported.
+func ExportEventsNDJSON(db *pg.DB, writer io.Writer, filter dbmodel.EventFilter) error {
+	encoder := json.NewEncoder(writer)
+	// The event texts contain the tags of the related entities.
+	encoder.SetEscapeHTML(false)
+
+	lastID := int64(0)
+	for {
+		events, err := dbmodel.GetEventsAfterID(db, lastID, ndjsonExportBatchSize, filter)
+		if err != nil {
+			return err
+		}
+		if err = writeEventsNDJSON(encoder, events); err != nil {
+			return err
+		}
+		if len(events) < ndjsonExportBatchSize {
+			return nil
+		}
+		lastID = events[len(events)-1].ID
+	}
+}
@@ -0,0 +1,122 @@
+package eventcenter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+)
+
+// Parses the NDJSON output into the list of objects. It verifies that each
+// line holds exactly one JSON object.
+func parseNDJSON(t *testing.T, output string) []map[string]interface{} {
+	var objects []map[string]interface{}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		var object map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &object), scanner.Text())
+		objects = append(objects, object)
+	}
+	require.NoError(t, scanner.Err())
+	return objects
+}
+
+// Test that the events are written one JSON object per line.
+func TestWriteEventsNDJSON(t *testing.T) {
+	// Arrange
+	createdAt := time.Date(2023, time.March, 15, 10, 0, 0, 0, time.UTC)
+	events := []dbmodel.Event{
+		{
+			ID:        1,
+			CreatedAt: createdAt,
+			Text:      "foo <daemon id=\"1\" name=\"dhcp4\">",
+			Level:     dbmodel.EvWarning,
+			Relations: &dbmodel.Relations{DaemonID: 1},
+			Details:   "multi\nline",
+		},
+		{
+			ID:        2,
+			CreatedAt: createdAt.Add(time.Minute),
+			Text:      "bar",
+			Level:     dbmodel.EvError,
+		},
+	}
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+
+	// Act
+	err := writeEventsNDJSON(encoder, events)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, 2, strings.Count(buffer.String(), "\n"))
+	objects := parseNDJSON(t, buffer.String())
+	require.Len(t, objects, 2)
+
+	require.EqualValues(t, 1, objects[0]["id"])
+	require.Equal(t, "2023-03-15T10:00:00Z", objects[0]["createdAt"])
+	require.Equal(t, "warning", objects[0]["level"])
+	require.Equal(t, "foo <daemon id=\"1\" name=\"dhcp4\">", objects[0]["text"])
+	require.Equal(t, "multi\nline", objects[0]["details"])
+	require.EqualValues(t, 1, objects[0]["relations"].(map[string]interface{})["DaemonID"])
+
+	require.EqualValues(t, 2, objects[1]["id"])
+	require.Equal(t, "error", objects[1]["level"])
+	require.NotContains(t, objects[1], "details")
+	require.NotContains(t, objects[1], "relations")
+}
+
+// Test that the exported event log is filtered by time range and level.
+func TestExportEventsNDJSON(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	start := time.Date(2023, time.March, 15, 10, 0, 0, 0, time.UTC)
+	for i, level := range []dbmodel.EventLevel{dbmodel.EvInfo, dbmodel.EvWarning, dbmodel.EvError, dbmodel.EvWarning, dbmodel.EvInfo} {
+		err := dbmodel.AddEvent(db, &dbmodel.Event{
+			CreatedAt: start.Add(time.Duration(i) * time.Hour),
+			Text:      "event",
+			Level:     level,
+		})
+		require.NoError(t, err)
+	}
+
+	from := start.Add(time.Hour)
+	to := start.Add(3 * time.Hour)
+
+	testCases := []struct {
+		name     string
+		filter   dbmodel.EventFilter
+		expected []string
+	}{
+		{"no filter", dbmodel.EventFilter{}, []string{"info", "warning", "error", "warning", "info"}},
+		{"time range", dbmodel.EventFilter{From: &from, To: &to}, []string{"warning", "error", "warning"}},
+		{"level", dbmodel.EventFilter{Level: dbmodel.EvError}, []string{"error"}},
+		{"time range and level", dbmodel.EventFilter{From: &from, Level: dbmodel.EvWarning}, []string{"warning", "error", "warning"}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+
+			// Act
+			err := ExportEventsNDJSON(db, &buffer, testCase.filter)
+
+			// Assert
+			require.NoError(t, err)
+			objects := parseNDJSON(t, buffer.String())
+			require.Len(t, objects, len(testCase.expected))
+			for i, object := range objects {
+				require.Equal(t, testCase.expected[i], object["level"])
+			}
+		})
+	}
+}
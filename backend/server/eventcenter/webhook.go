@@ -0,0 +1,191 @@
+package eventcenter
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	dbmodel "isc.org/stork/server/database/model"
+)
+
+// Default interval between the retries of a failed webhook delivery.
+const DefaultWebhookRetryInterval = 5 * time.Second
+
+// Maximum number of the events waiting for the delivery to the webhook.
+// The events are dropped when the queue is full, so a slow or unreachable
+// webhook doesn't block the event center.
+const webhookQueueSize = 100
+
+// Timeout of a single webhook request.
+const webhookRequestTimeout = 10 * time.Second
+
+// Settings of the webhook subscriber.
+type WebhookSettings struct {
+	// URL the events are posted to.
+	URL string
+	// The lowest level of the delivered events.
+	Level dbmodel.EventLevel
+	// Types of the delivered events, e.g., unreachable or exhausted. The
+	// event type matches if the event text contains it (case-insensitive).
+	// All events are delivered if no types are specified.
+	EventTypes []string
+	// Number of the retries of a failed delivery.
+	Retries int
+	// Interval between the retries of a failed delivery.
+	RetryInterval time.Duration
+	// Period after the subscriber start during which the events are not
+	// delivered. The first pulls after the server start detect many state
+	// changes, so this avoids flooding the webhook. The events are still
+	// stored by the event center.
+	QuietPeriod time.Duration
+}
+
+// Webhook subscriber. It posts the accepted events to the configured URL
+// as JSON objects having the same format as the exported event log. The
+// events are queued and delivered in the background. The delivery is
+// retried when the request fails or the webhook returns a non-2xx status.
+type WebhookSubscriber struct {
+	settings   WebhookSettings
+	client     *http.Client
+	quietUntil time.Time
+	events     chan *dbmodel.Event
+	done       chan bool
+	wg         *sync.WaitGroup
+}
+
+// Parses the comma-separated list of the event types delivered to the
+// webhook. The empty entries are skipped.
+func ParseWebhookEventTypes(spec string) []string {
+	var eventTypes []string
+	for _, eventType := range strings.Split(spec, ",") {
+		eventType = strings.TrimSpace(eventType)
+		if eventType != "" {
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	return eventTypes
+}
+
+// Creates a new webhook subscriber and starts the goroutine delivering
+// the events. It returns an error if the URL is invalid.
+func NewWebhookSubscriber(settings WebhookSettings) (*WebhookSubscriber, error) {
+	request, err := http.NewRequest(http.MethodPost, settings.URL, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid webhook URL %s", settings.URL)
+	}
+	if request.URL.Scheme != "http" && request.URL.Scheme != "https" {
+		return nil, errors.Errorf("invalid webhook URL %s; expected http or https scheme", settings.URL)
+	}
+	if settings.Retries < 0 {
+		settings.Retries = 0
+	}
+	ws := &WebhookSubscriber{
+		settings: settings,
+		client: &http.Client{
+			Timeout: webhookRequestTimeout,
+		},
+		quietUntil: time.Now().Add(settings.QuietPeriod),
+		events:     make(chan *dbmodel.Event, webhookQueueSize),
+		done:       make(chan bool),
+		wg:         &sync.WaitGroup{},
+	}
+	ws.wg.Add(1)
+	go ws.mainLoop()
+	return ws, nil
+}
+
+// Returns a boolean value indicating if the webhook should receive the
+// specified event.
+func (ws *WebhookSubscriber) AcceptsEvent(event *dbmodel.Event) bool {
+	if event.Level < ws.settings.Level {
+		return false
+	}
+	if len(ws.settings.EventTypes) == 0 {
+		return true
+	}
+	text := strings.ToLower(event.Text)
+	for _, eventType := range ws.settings.EventTypes {
+		if strings.Contains(text, strings.ToLower(eventType)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Queues the event for the delivery if the webhook accepts it. The events
+// are not delivered during the quiet period.
+func (ws *WebhookSubscriber) dispatchEvent(event *dbmodel.Event) {
+	if !ws.AcceptsEvent(event) {
+		return
+	}
+	if time.Now().Before(ws.quietUntil) {
+		log.WithField("url", ws.settings.URL).Debugf("Suppressed event '%s' during the webhook quiet period", event.Text)
+		return
+	}
+	select {
+	case ws.events <- event:
+	default:
+		log.WithField("url", ws.settings.URL).Warnf("Dropped event '%s' because the webhook queue is full", event.Text)
+	}
+}
+
+// Stops delivering the events. The queued events are discarded.
+func (ws *WebhookSubscriber) shutdown() {
+	close(ws.done)
+	ws.wg.Wait()
+}
+
+// A main loop of the webhook subscriber. It delivers the queued events
+// one by one.
+func (ws *WebhookSubscriber) mainLoop() {
+	defer ws.wg.Done()
+	for {
+		select {
+		case <-ws.done:
+			return
+		case event := <-ws.events:
+			if err := ws.deliver(event); err != nil {
+				log.WithField("url", ws.settings.URL).Errorf("Failed to deliver event '%s' to the webhook: %+v", event.Text, err)
+			}
+		}
+	}
+}
+
+// Posts the event to the webhook. The delivery is retried the configured
+// number of times. It returns the error of the last attempt when all
+// attempts fail or the subscriber has been shut down.
+func (ws *WebhookSubscriber) deliver(event *dbmodel.Event) error {
+	payload, err := json.Marshal(newNDJSONEvent(event))
+	if err != nil {
+		return errors.Wrapf(err, "problem serializing event %d to json", event.ID)
+	}
+	for attempt := 0; ; attempt++ {
+		err = ws.post(payload)
+		if err == nil || attempt >= ws.settings.Retries {
+			return err
+		}
+		select {
+		case <-ws.done:
+			return err
+		case <-time.After(ws.settings.RetryInterval):
+		}
+	}
+}
+
+// Sends a single request with the payload to the webhook.
+func (ws *WebhookSubscriber) post(payload []byte) error {
+	response, err := ws.client.Post(ws.settings.URL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return errors.Wrapf(err, "problem posting event to %s", ws.settings.URL)
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return errors.Errorf("webhook %s returned status %s", ws.settings.URL, response.Status)
+	}
+	return nil
+}
@@ -0,0 +1,285 @@
+package eventcenter
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	dbmodel "isc.org/stork/server/database/model"
+	dbtest "isc.org/stork/server/database/test"
+)
+
+// Test HTTP server recording the events posted to the webhook. The
+// specified number of the first requests is rejected with the internal
+// server error.
+type webhookTestServer struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	failures int
+	requests int
+	events   []map[string]interface{}
+}
+
+// Creates the test HTTP server failing the specified number of the first
+// requests.
+func newWebhookTestServer(t *testing.T, failures int) *webhookTestServer {
+	server := &webhookTestServer{
+		failures: failures,
+	}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+		server.requests++
+		if server.requests <= server.failures {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var event map[string]interface{}
+		require.NoError(t, json.Unmarshal(body, &event))
+		server.events = append(server.events, event)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// Returns the number of the received requests and the copy of the
+// recorded events.
+func (server *webhookTestServer) getRecorded() (int, []map[string]interface{}) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	return server.requests, append([]map[string]interface{}{}, server.events...)
+}
+
+// Test that the event types are parsed from the comma-separated list.
+func TestParseWebhookEventTypes(t *testing.T) {
+	require.Equal(t, []string{"unreachable", "exhausted"}, ParseWebhookEventTypes(" unreachable,,exhausted "))
+	require.Empty(t, ParseWebhookEventTypes(""))
+}
+
+// Test that the webhook subscriber is not created for an invalid URL.
+func TestNewWebhookSubscriberInvalidURL(t *testing.T) {
+	for _, url := range []string{"", "ftp://example.org", "http://[::1"} {
+		t.Run(url, func(t *testing.T) {
+			webhook, err := NewWebhookSubscriber(WebhookSettings{URL: url})
+			require.Error(t, err)
+			require.Nil(t, webhook)
+		})
+	}
+}
+
+// Test that the webhook accepts the events matching the severity and the
+// event types.
+func TestWebhookSubscriberAcceptsEvent(t *testing.T) {
+	// Arrange
+	webhook := &WebhookSubscriber{
+		settings: WebhookSettings{
+			Level:      dbmodel.EvWarning,
+			EventTypes: []string{"unreachable", "exhausted"},
+		},
+	}
+
+	// Act & Assert
+	require.True(t, webhook.AcceptsEvent(&dbmodel.Event{Level: dbmodel.EvWarning, Text: "daemon is Unreachable"}))
+	require.True(t, webhook.AcceptsEvent(&dbmodel.Event{Level: dbmodel.EvError, Text: "subnet pool exhausted"}))
+	require.False(t, webhook.AcceptsEvent(&dbmodel.Event{Level: dbmodel.EvInfo, Text: "daemon is unreachable"}))
+	require.False(t, webhook.AcceptsEvent(&dbmodel.Event{Level: dbmodel.EvError, Text: "daemon restarted"}))
+
+	webhook.settings.EventTypes = nil
+	require.True(t, webhook.AcceptsEvent(&dbmodel.Event{Level: dbmodel.EvError, Text: "daemon restarted"}))
+}
+
+// Test that the event is posted to the webhook as JSON.
+func TestWebhookSubscriberDeliver(t *testing.T) {
+	// Arrange
+	server := newWebhookTestServer(t, 0)
+	webhook, err := NewWebhookSubscriber(WebhookSettings{URL: server.URL})
+	require.NoError(t, err)
+	defer webhook.shutdown()
+
+	event := CreateEvent(dbmodel.EvError, "{subnet} is exhausted", &dbmodel.Subnet{ID: 5, Prefix: "192.0.2.0/24"}, "details")
+	event.ID = 42
+
+	// Act
+	err = webhook.deliver(event)
+
+	// Assert
+	require.NoError(t, err)
+	requests, events := server.getRecorded()
+	require.Equal(t, 1, requests)
+	require.Len(t, events, 1)
+	require.EqualValues(t, 42, events[0]["id"])
+	require.Equal(t, "error", events[0]["level"])
+	require.Equal(t, `<subnet id="5" prefix="192.0.2.0/24"> is exhausted`, events[0]["text"])
+	require.Equal(t, "details", events[0]["details"])
+	require.EqualValues(t, 5, events[0]["relations"].(map[string]interface{})["SubnetID"])
+}
+
+// Test that the failed delivery is retried.
+func TestWebhookSubscriberDeliverRetry(t *testing.T) {
+	// Arrange
+	server := newWebhookTestServer(t, 2)
+	webhook, err := NewWebhookSubscriber(WebhookSettings{
+		URL:           server.URL,
+		Retries:       2,
+		RetryInterval: time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer webhook.shutdown()
+
+	// Act
+	err = webhook.deliver(&dbmodel.Event{ID: 1, Text: "daemon is unreachable"})
+
+	// Assert
+	require.NoError(t, err)
+	requests, events := server.getRecorded()
+	require.Equal(t, 3, requests)
+	require.Len(t, events, 1)
+}
+
+// Test that an error is returned when all delivery attempts fail.
+func TestWebhookSubscriberDeliverRetriesExceeded(t *testing.T) {
+	// Arrange
+	server := newWebhookTestServer(t, 3)
+	webhook, err := NewWebhookSubscriber(WebhookSettings{
+		URL:           server.URL,
+		Retries:       2,
+		RetryInterval: time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer webhook.shutdown()
+
+	// Act
+	err = webhook.deliver(&dbmodel.Event{ID: 1, Text: "daemon is unreachable"})
+
+	// Assert
+	require.ErrorContains(t, err, "500")
+	requests, events := server.getRecorded()
+	require.Equal(t, 3, requests)
+	require.Empty(t, events)
+}
+
+// Test that only the events matching the severity are delivered to the
+// webhook.
+func TestWebhookSubscriberDispatchEvent(t *testing.T) {
+	// Arrange
+	server := newWebhookTestServer(t, 0)
+	webhook, err := NewWebhookSubscriber(WebhookSettings{
+		URL:   server.URL,
+		Level: dbmodel.EvWarning,
+	})
+	require.NoError(t, err)
+	defer webhook.shutdown()
+
+	// Act
+	webhook.dispatchEvent(&dbmodel.Event{ID: 1, Level: dbmodel.EvInfo, Text: "info"})
+	webhook.dispatchEvent(&dbmodel.Event{ID: 2, Level: dbmodel.EvWarning, Text: "warning"})
+	webhook.dispatchEvent(&dbmodel.Event{ID: 3, Level: dbmodel.EvError, Text: "error"})
+
+	// Assert
+	require.Eventually(t, func() bool {
+		_, events := server.getRecorded()
+		return len(events) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	requests, events := server.getRecorded()
+	require.Equal(t, 2, requests)
+	require.Equal(t, "warning", events[0]["text"])
+	require.Equal(t, "error", events[1]["text"])
+}
+
+// Test that the events are not delivered during the quiet period and
+// they are delivered after it.
+func TestWebhookSubscriberQuietPeriod(t *testing.T) {
+	// Arrange
+	server := newWebhookTestServer(t, 0)
+	webhook, err := NewWebhookSubscriber(WebhookSettings{
+		URL:         server.URL,
+		Level:       dbmodel.EvWarning,
+		QuietPeriod: time.Hour,
+	})
+	require.NoError(t, err)
+	defer webhook.shutdown()
+
+	// Act
+	webhook.dispatchEvent(&dbmodel.Event{ID: 1, Level: dbmodel.EvError, Text: "during quiet period"})
+	// End the quiet period.
+	webhook.quietUntil = time.Now()
+	webhook.dispatchEvent(&dbmodel.Event{ID: 2, Level: dbmodel.EvError, Text: "after quiet period"})
+
+	// Assert
+	require.Eventually(t, func() bool {
+		_, events := server.getRecorded()
+		return len(events) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	requests, events := server.getRecorded()
+	require.Equal(t, 1, requests)
+	require.Equal(t, "after quiet period", events[0]["text"])
+}
+
+// Test that the event center dispatches the events to the webhooks.
+func TestEventCenterWebhook(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	server := newWebhookTestServer(t, 0)
+	webhook, err := NewWebhookSubscriber(WebhookSettings{
+		URL:   server.URL,
+		Level: dbmodel.EvError,
+	})
+	require.NoError(t, err)
+
+	ec := NewEventCenter(db, webhook)
+	defer ec.Shutdown()
+
+	// Act
+	ec.AddInfoEvent("daemon is reachable")
+	ec.AddErrorEvent("daemon is unreachable")
+
+	// Assert
+	require.Eventually(t, func() bool {
+		_, events := server.getRecorded()
+		return len(events) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	_, events := server.getRecorded()
+	require.Equal(t, "daemon is unreachable", events[0]["text"])
+	require.NotZero(t, events[0]["id"])
+}
+
+// Test that the events raised during the quiet period are stored but not
+// delivered to the webhook.
+func TestEventCenterWebhookQuietPeriod(t *testing.T) {
+	// Arrange
+	db, _, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	server := newWebhookTestServer(t, 0)
+	webhook, err := NewWebhookSubscriber(WebhookSettings{
+		URL:         server.URL,
+		Level:       dbmodel.EvInfo,
+		QuietPeriod: time.Hour,
+	})
+	require.NoError(t, err)
+
+	ec := NewEventCenter(db, webhook)
+	defer ec.Shutdown()
+
+	// Act
+	ec.AddErrorEvent("daemon is unreachable")
+
+	// Assert
+	require.Eventually(t, func() bool {
+		events, _, err := dbmodel.GetEventsByPage(db, 0, 10, dbmodel.EvInfo, nil, nil, nil, nil, "", dbmodel.SortDirAny)
+		return err == nil && len(events) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	requests, _ := server.getRecorded()
+	require.Zero(t, requests)
+}
@@ -18,7 +18,7 @@ import (
 
 // Get daemon config. Only Kea daemon supported.
 func (r *RestAPI) GetDaemonConfig(ctx context.Context, params services.GetDaemonConfigParams) middleware.Responder {
-	dbDaemon, err := dbmodel.GetDaemonByID(r.DB, params.ID)
+	dbDaemon, err := dbmodel.GetDaemonByID(r.getReportingDB(), params.ID)
 	if err != nil {
 		log.Error(err)
 		msg := fmt.Sprintf("Cannot get daemon with ID %d from db", params.ID)
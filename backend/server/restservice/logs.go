@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"strings"
 
 	"github.com/go-openapi/runtime/middleware"
 	log "github.com/sirupsen/logrus"
@@ -40,8 +39,7 @@ func (r *RestAPI) GetLogTail(ctx context.Context, params services.GetLogTailPara
 	}
 
 	// Currently we only support viewing log files.
-	if dbLogTarget.Output == "stdout" || dbLogTarget.Output == "stderr" ||
-		strings.HasPrefix(dbLogTarget.Output, "syslog") {
+	if !dbmodel.IsLogOutputTailable(dbLogTarget.Output) {
 		msg := fmt.Sprintf("Viewing log from %s is not supported", dbLogTarget.Output)
 		log.Warn(msg)
 		rsp := services.NewGetLogTailDefault(http.StatusBadRequest).WithPayload(&models.APIError{
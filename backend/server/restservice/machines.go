@@ -3,6 +3,7 @@ package restservice
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"strings"
 	"time"
@@ -54,6 +55,7 @@ func (r *RestAPI) machineToRestAPI(dbMachine dbmodel.Machine) *models.Machine {
 		Address:                  &dbMachine.Address,
 		AgentPort:                dbMachine.AgentPort,
 		Authorized:               dbMachine.Authorized,
+		AppStateTimeout:          &dbMachine.AppStateTimeout,
 		AgentToken:               dbMachine.AgentToken,
 		AgentVersion:             dbMachine.State.AgentVersion,
 		Cpus:                     dbMachine.State.Cpus,
@@ -76,6 +78,10 @@ func (r *RestAPI) machineToRestAPI(dbMachine dbmodel.Machine) *models.Machine {
 		Error:                    dbMachine.Error,
 		Apps:                     apps,
 	}
+	if dbMachine.HasPendingRelocation() {
+		m.RelocationAddress = dbMachine.RelocationAddress
+		m.RelocationAgentPort = dbMachine.RelocationAgentPort
+	}
 	return &m
 }
 
@@ -98,7 +104,7 @@ func (r *RestAPI) GetMachineState(ctx context.Context, params services.GetMachin
 		return rsp
 	}
 
-	errStr := apps.GetMachineAndAppsState(ctx, r.DB, dbMachine, r.Agents, r.EventCenter, r.ReviewDispatcher, r.DHCPOptionDefinitionLookup)
+	errStr := apps.GetMachineAndAppsState(ctx, r.DB, dbMachine, r.Agents, r.EventCenter, r.ReviewDispatcher, r.DHCPOptionDefinitionLookup, r.getDaemonEventSeverities(), r.getAppStatePullSettings())
 	if errStr != "" {
 		rsp := services.NewGetMachineStateDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
 			Message: &errStr,
@@ -323,6 +329,25 @@ func (r *RestAPI) CreateMachine(ctx context.Context, params services.CreateMachi
 		return rsp
 	}
 
+	// Check if the same agent has been registered from a different address.
+	// In this case, the existing machine is moved to the new address instead
+	// of creating a duplicate machine with the same apps.
+	var relocatedMachine *dbmodel.Machine
+	if dbMachine == nil {
+		relocatedMachine, err = dbmodel.GetMachineByAgentToken(r.DB, *params.Machine.AgentToken)
+		if err != nil {
+			log.Error(err)
+			msg := "Problem finding machine by agent token in database"
+			rsp := services.NewCreateMachineDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		}
+		if relocatedMachine != nil && !relocatedMachine.IsAgentRelocated(*params.Machine.AgentToken, addr, params.Machine.AgentPort) {
+			relocatedMachine = nil
+		}
+	}
+
 	// check server token
 	machineAuthorized, httpRspCode, rspMsg := r.checkServerToken(params.Machine.ServerToken, true)
 	if httpRspCode != 0 {
@@ -389,7 +414,46 @@ func (r *RestAPI) CreateMachine(ctx context.Context, params services.CreateMachi
 		defer r.Pullers.AppsStatePuller.Unpause()
 	}
 
-	if dbMachine == nil {
+	switch {
+	case relocatedMachine != nil && params.Machine.ServerToken != "" && machineAuthorized:
+		// The agent proved it is trusted by providing the server token.
+		// Move the existing machine to the new address.
+		oldAddress := net.JoinHostPort(relocatedMachine.Address, fmt.Sprint(relocatedMachine.AgentPort))
+		dbMachine = relocatedMachine
+		dbMachine.Address = addr
+		dbMachine.AgentPort = params.Machine.AgentPort
+		dbMachine.CertFingerprint = agentCertFingerprint
+		dbMachine.Authorized = machineAuthorized
+		dbMachine.ClearPendingRelocation()
+		err = dbmodel.UpdateMachine(r.DB, dbMachine)
+		if err != nil {
+			log.Error(err)
+			msg := fmt.Sprintf("Cannot update machine %s in database", addr)
+			rsp := services.NewCreateMachineDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		}
+		r.EventCenter.AddWarningEvent(fmt.Sprintf("agent of {machine} moved from %s", oldAddress), dbMachine)
+	case relocatedMachine != nil:
+		// The agent token is not a secret, so the agent registering only
+		// with it cannot take over the existing machine. Record the pending
+		// relocation and leave the machine untouched until an administrator
+		// confirms it by changing the machine address.
+		newAddress := net.JoinHostPort(addr, fmt.Sprint(params.Machine.AgentPort))
+		dbMachine = relocatedMachine
+		dbMachine.SetPendingRelocation(addr, params.Machine.AgentPort, agentCertFingerprint)
+		err = dbmodel.UpdateMachine(r.DB, dbMachine)
+		if err != nil {
+			log.Error(err)
+			msg := fmt.Sprintf("Cannot update machine %s in database", addr)
+			rsp := services.NewCreateMachineDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		}
+		r.EventCenter.AddWarningEvent(fmt.Sprintf("agent of {machine} registered from %s; change the machine address to confirm the relocation", newAddress), dbMachine)
+	case dbMachine == nil:
 		dbMachine = &dbmodel.Machine{
 			Address:         addr,
 			AgentPort:       params.Machine.AgentPort,
@@ -407,7 +471,7 @@ func (r *RestAPI) CreateMachine(ctx context.Context, params services.CreateMachi
 			return rsp
 		}
 		r.EventCenter.AddInfoEvent("added {machine}", dbMachine)
-	} else {
+	default:
 		dbMachine.AgentToken = *params.Machine.AgentToken
 		dbMachine.CertFingerprint = agentCertFingerprint
 		dbMachine.Authorized = machineAuthorized
@@ -486,7 +550,7 @@ func (r *RestAPI) PingMachine(ctx context.Context, params services.PingMachinePa
 	}
 
 	// Communication with an agent established, so get machine's state.
-	errStr := apps.GetMachineAndAppsState(ctx2, r.DB, dbMachine, r.Agents, r.EventCenter, r.ReviewDispatcher, r.DHCPOptionDefinitionLookup)
+	errStr := apps.GetMachineAndAppsState(ctx2, r.DB, dbMachine, r.Agents, r.EventCenter, r.ReviewDispatcher, r.DHCPOptionDefinitionLookup, r.getDaemonEventSeverities(), r.getAppStatePullSettings())
 	if errStr != "" {
 		rsp := services.NewPingMachineDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
 			Message: &errStr,
@@ -527,6 +591,15 @@ func (r *RestAPI) UpdateMachine(ctx context.Context, params services.UpdateMachi
 		return rsp
 	}
 
+	if params.Machine.AppStateTimeout != nil && *params.Machine.AppStateTimeout < 0 {
+		log.Warnf("Bad app state timeout %d", *params.Machine.AppStateTimeout)
+		msg := "Bad app state timeout"
+		rsp := services.NewUpdateMachineDefault(http.StatusBadRequest).WithPayload(&models.APIError{
+			Message: &msg,
+		})
+		return rsp
+	}
+
 	dbMachine, err := dbmodel.GetMachineByID(r.DB, params.ID)
 	if err != nil {
 		log.Error(err)
@@ -569,11 +642,30 @@ func (r *RestAPI) UpdateMachine(ctx context.Context, params services.UpdateMachi
 		}
 	}
 
+	// Changing the address to the one the agent registered from confirms
+	// the pending relocation. It makes the server trust the certificate
+	// issued to the relocated agent, so it requires super-admin group.
+	confirmRelocation := dbMachine.IsPendingRelocationTo(addr, params.Machine.AgentPort)
+	if confirmRelocation {
+		_, dbUser := r.SessionManager.Logged(ctx)
+		if !dbUser.InGroup(&dbmodel.SystemGroup{ID: dbmodel.SuperAdminGroupID}) {
+			msg := "User is forbidden to confirm machine relocation"
+			rsp := services.NewUpdateMachineDefault(http.StatusForbidden).WithPayload(&models.APIError{
+				Message: &msg,
+			})
+			return rsp
+		}
+		dbMachine.ConfirmRelocation()
+	}
+
 	// copy fields
 	dbMachine.Address = addr
 	dbMachine.AgentPort = params.Machine.AgentPort
 	prevAuthorized := dbMachine.Authorized
 	dbMachine.Authorized = params.Machine.Authorized
+	if params.Machine.AppStateTimeout != nil {
+		dbMachine.AppStateTimeout = *params.Machine.AppStateTimeout
+	}
 	_, err = r.DB.Model(dbMachine).WherePK().Update()
 	if err != nil {
 		log.Errorf("Cannot update machine: %s", err)
@@ -588,7 +680,7 @@ func (r *RestAPI) UpdateMachine(ctx context.Context, params services.UpdateMachi
 	if !prevAuthorized && dbMachine.Authorized {
 		ctx2, cancel := context.WithTimeout(ctx, 10*time.Second)
 		defer cancel()
-		errStr := apps.GetMachineAndAppsState(ctx2, r.DB, dbMachine, r.Agents, r.EventCenter, r.ReviewDispatcher, r.DHCPOptionDefinitionLookup)
+		errStr := apps.GetMachineAndAppsState(ctx2, r.DB, dbMachine, r.Agents, r.EventCenter, r.ReviewDispatcher, r.DHCPOptionDefinitionLookup, r.getDaemonEventSeverities(), r.getAppStatePullSettings())
 		if errStr != "" {
 			rsp := services.NewUpdateMachineDefault(http.StatusInternalServerError).WithPayload(&models.APIError{
 				Message: &errStr,
@@ -703,7 +795,11 @@ func (r *RestAPI) DeleteMachine(ctx context.Context, params services.DeleteMachi
 // Return a single machine dump archive. It is intended for easily sharing the configuration
 // for diagnostic purposes. The archive contains the database dumps and some log files.
 func (r *RestAPI) GetMachineDump(ctx context.Context, params services.GetMachineDumpParams) middleware.Responder {
-	dump, err := dumper.DumpMachine(r.DB, r.Agents, params.ID)
+	var statsPuller *kea.StatsPuller
+	if r.Pullers != nil {
+		statsPuller = r.Pullers.KeaStatsPuller
+	}
+	dump, err := dumper.DumpMachine(r.getReportingDB(), r.Agents, statsPuller, params.ID, nil)
 	if err != nil {
 		status := http.StatusInternalServerError
 		statusMessage := fmt.Sprintf("Cannot dump machine %d", params.ID)
@@ -831,6 +927,7 @@ func baseAppToRestAPI(dbApp *dbmodel.App) *models.AppBase {
 			Address:           point.Address,
 			Port:              point.Port,
 			UseSecureProtocol: point.UseSecureProtocol,
+			Reachable:         point.Reachable,
 		})
 	}
 	app.AccessPoints = accessPoints
@@ -890,6 +987,7 @@ func (r *RestAPI) appToRestAPI(dbApp *dbmodel.App) *models.App {
 			models.AppKea{
 				ExtendedVersion: dbApp.Meta.ExtendedVersion,
 				Daemons:         keaDaemons,
+				NoDaemons:       dbApp.Meta.NoDaemons,
 			},
 			models.AppBind9{},
 		}
@@ -957,19 +1055,25 @@ func (r *RestAPI) appToRestAPI(dbApp *dbmodel.App) *models.App {
 // Converts KeaDaemon structure to REST API format.
 func keaDaemonToRestAPI(dbDaemon *dbmodel.Daemon) *models.KeaDaemon {
 	daemon := &models.KeaDaemon{
-		ID:              dbDaemon.ID,
-		Pid:             int64(dbDaemon.Pid),
-		Name:            dbDaemon.Name,
-		Active:          dbDaemon.Active,
-		Monitored:       dbDaemon.Monitored,
-		Version:         dbDaemon.Version,
-		ExtendedVersion: dbDaemon.ExtendedVersion,
-		Uptime:          dbDaemon.Uptime,
-		ReloadedAt:      strfmt.DateTime(dbDaemon.ReloadedAt),
-		Hooks:           []string{},
-		Backends:        []*models.KeaDaemonDatabase{},
-		Files:           []*models.File{},
-		LogTargets:      []*models.LogTarget{},
+		ID:                 dbDaemon.ID,
+		Pid:                int64(dbDaemon.Pid),
+		Name:               dbDaemon.Name,
+		Active:             dbDaemon.Active,
+		Monitored:          dbDaemon.Monitored,
+		Version:            dbDaemon.Version,
+		ExtendedVersion:    dbDaemon.ExtendedVersion,
+		Uptime:             dbDaemon.Uptime,
+		ReloadedAt:         strfmt.DateTime(dbDaemon.ReloadedAt),
+		Hooks:              []string{},
+		Backends:           []*models.KeaDaemonDatabase{},
+		Files:              []*models.File{},
+		LogTargets:         []*models.LogTarget{},
+		ServerTag:          dbDaemon.KeaDaemon.ServerTag,
+		ConfigStructure:    dbDaemon.KeaDaemon.ConfigStructure,
+		InterfacesReDetect: dbDaemon.KeaDaemon.InterfacesReDetect,
+		DhcpSocketType:     dbDaemon.KeaDaemon.DHCPSocketType,
+		OutboundInterface:  dbDaemon.KeaDaemon.OutboundInterface,
+		FlexIDExpression:   dbDaemon.KeaDaemon.FlexIDExpression,
 	}
 
 	// Daemon can include App information (depending on the database query).
@@ -990,6 +1094,7 @@ func keaDaemonToRestAPI(dbDaemon *dbmodel.Daemon) *models.KeaDaemon {
 			Name:     logTarget.Name,
 			Severity: logTarget.Severity,
 			Output:   logTarget.Output,
+			Tailable: logTarget.Tailable,
 		})
 	}
 
@@ -1288,7 +1393,7 @@ func (r *RestAPI) GetAppServicesStatus(ctx context.Context, params services.GetA
 func (r *RestAPI) GetAppsStats(ctx context.Context, params services.GetAppsStatsParams) middleware.Responder {
 	// The second argument indicates that only basic information about the apps
 	// should be returned, i.e. the information stored in the app table.
-	dbApps, err := dbmodel.GetAllApps(r.DB, false)
+	dbApps, err := dbmodel.GetAllApps(r.getReportingDB(), false)
 	if err != nil {
 		log.Error(err)
 		msg := "Cannot get all apps from db"
@@ -1325,11 +1430,13 @@ func (r *RestAPI) GetAppsStats(ctx context.Context, params services.GetAppsStats
 
 // Get DHCP overview.
 func (r *RestAPI) GetDhcpOverview(ctx context.Context, params dhcp.GetDhcpOverviewParams) middleware.Responder {
+	db := r.getReportingDB()
+
 	// get list of mostly utilized subnets
 	filters := &dbmodel.SubnetsByPageFilters{}
 	filters.SetIPv4Family()
 
-	subnets4, err := r.getSubnets(0, 5, filters, "addr_utilization", dbmodel.SortDirDesc)
+	subnets4, err := r.getSubnets(db, 0, 5, filters, "addr_utilization", dbmodel.SortDirDesc)
 	if err != nil {
 		log.Error(err)
 		msg := "Cannot get IPv4 subnets from db"
@@ -1340,7 +1447,7 @@ func (r *RestAPI) GetDhcpOverview(ctx context.Context, params dhcp.GetDhcpOvervi
 	}
 
 	filters.SetIPv6Family()
-	subnets6, err := r.getSubnets(0, 5, filters, "addr_utilization", dbmodel.SortDirDesc)
+	subnets6, err := r.getSubnets(db, 0, 5, filters, "addr_utilization", dbmodel.SortDirDesc)
 	if err != nil {
 		log.Error(err)
 		msg := "Cannot get IPv6 subnets from db"
@@ -1351,7 +1458,7 @@ func (r *RestAPI) GetDhcpOverview(ctx context.Context, params dhcp.GetDhcpOvervi
 	}
 
 	// get list of mostly utilized shared networks
-	sharedNetworks4, err := r.getSharedNetworks(0, 5, 0, 4, nil, "addr_utilization", dbmodel.SortDirDesc)
+	sharedNetworks4, err := r.getSharedNetworks(db, 0, 5, 0, 4, nil, "addr_utilization", dbmodel.SortDirDesc)
 	if err != nil {
 		log.Error(err)
 		msg := "Cannot get IPv4 shared networks from db"
@@ -1361,7 +1468,7 @@ func (r *RestAPI) GetDhcpOverview(ctx context.Context, params dhcp.GetDhcpOvervi
 		return rsp
 	}
 
-	sharedNetworks6, err := r.getSharedNetworks(0, 5, 0, 6, nil, "addr_utilization", dbmodel.SortDirDesc)
+	sharedNetworks6, err := r.getSharedNetworks(db, 0, 5, 0, 6, nil, "addr_utilization", dbmodel.SortDirDesc)
 	if err != nil {
 		log.Error(err)
 		msg := "Cannot get IPv6 shared networks from db"
@@ -1372,7 +1479,7 @@ func (r *RestAPI) GetDhcpOverview(ctx context.Context, params dhcp.GetDhcpOvervi
 	}
 
 	// get dhcp statistics
-	stats, err := dbmodel.GetAllStats(r.DB)
+	stats, err := dbmodel.GetAllStats(db)
 	if err != nil {
 		log.Error(err)
 		msg := "Cannot get statistics from db"
@@ -1396,7 +1503,7 @@ func (r *RestAPI) GetDhcpOverview(ctx context.Context, params dhcp.GetDhcpOvervi
 	}
 
 	// get kea apps and daemons statuses
-	dbApps, err := dbmodel.GetAppsByType(r.DB, dbmodel.AppTypeKea)
+	dbApps, err := dbmodel.GetAppsByType(db, dbmodel.AppTypeKea)
 	if err != nil {
 		log.Error(err)
 		msg := "Cannot get statistics from db"
@@ -158,9 +158,23 @@ func mockGetAppsState(callNo int, cmdResponses []interface{}) {
 				},
 			},
 		}
+		// config-hash-get response
+		list3 := cmdResponses[2].(*[]kea.ConfigHashGetResponse)
+		*list3 = []kea.ConfigHashGetResponse{
+			{
+				ResponseHeader: keactrl.ResponseHeader{
+					Result: 0,
+					Daemon: "dhcp4",
+				},
+				Arguments: &kea.ConfigHashGetRespArgs{
+					Hash: "hash",
+				},
+			},
+		}
+	case 2:
 		// config-get response
-		list3 := cmdResponses[2].(*[]keactrl.HashedResponse)
-		*list3 = []keactrl.HashedResponse{
+		list1 := cmdResponses[0].(*[]keactrl.HashedResponse)
+		*list1 = []keactrl.HashedResponse{
 			{
 				ResponseHeader: keactrl.ResponseHeader{
 					Result: 0,
@@ -476,13 +490,14 @@ func TestCreateMachine(t *testing.T) {
 	// add another machine but with no server token (agent token is used for authorization)
 	addr = "5.6.7.8"
 	serverToken = ""
+	agentToken2 := "agentToken2"
 	params = services.CreateMachineParams{
 		Machine: &models.NewMachineReq{
 			Address:     &addr,
 			AgentPort:   8080,
 			AgentCSR:    &agentCSR,
 			ServerToken: serverToken,
-			AgentToken:  &agentToken,
+			AgentToken:  &agentToken2,
 		},
 	}
 	rsp = rapi.CreateMachine(ctx, params)
@@ -501,6 +516,174 @@ func TestCreateMachine(t *testing.T) {
 	require.False(t, m2.Authorized)
 }
 
+// Test that the agent registering with the server token from a new address
+// is moved to this address instead of creating a duplicate machine.
+func TestCreateMachineAgentRelocated(t *testing.T) {
+	// Arrange
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	settings := RestAPISettings{}
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	fec := &storktest.FakeEventCenter{}
+	fd := &storktest.FakeDispatcher{}
+	rapi, err := NewRestAPI(&settings, dbSettings, db, fa, fec, fd)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, _, _, err = certs.SetupServerCerts(db)
+	require.NoError(t, err)
+	dbServerToken, err := dbmodel.GetSecret(db, dbmodel.SecretServerToken)
+	require.NoError(t, err)
+	serverToken := string(dbServerToken)
+
+	machine := &dbmodel.Machine{
+		Address:    "192.0.2.1",
+		AgentPort:  8080,
+		AgentToken: "agentToken",
+		Authorized: true,
+	}
+	err = dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+	app := &dbmodel.App{
+		MachineID: machine.ID,
+		Type:      dbmodel.AppTypeKea,
+	}
+	_, err = dbmodel.AddApp(db, app)
+	require.NoError(t, err)
+
+	addr := "192.0.2.2"
+	agentToken := "agentToken"
+	_, csrPEM, _, err := pki.GenKeyAndCSR("agent", []string{"name"}, []net.IP{net.ParseIP(addr)})
+	require.NoError(t, err)
+	agentCSR := string(csrPEM)
+	params := services.CreateMachineParams{
+		Machine: &models.NewMachineReq{
+			Address:     &addr,
+			AgentPort:   8080,
+			AgentCSR:    &agentCSR,
+			AgentToken:  &agentToken,
+			ServerToken: serverToken,
+		},
+	}
+
+	// Act
+	rsp := rapi.CreateMachine(ctx, params)
+
+	// Assert
+	require.IsType(t, &services.CreateMachineOK{}, rsp)
+	okRsp := rsp.(*services.CreateMachineOK)
+	require.Equal(t, machine.ID, okRsp.Payload.ID)
+
+	machines, err := dbmodel.GetAllMachines(db, nil)
+	require.NoError(t, err)
+	require.Len(t, machines, 1)
+	require.Equal(t, addr, machines[0].Address)
+	require.True(t, machines[0].Authorized)
+	require.NotEqual(t, [32]byte{}, machines[0].CertFingerprint)
+	require.False(t, machines[0].HasPendingRelocation())
+
+	apps, err := dbmodel.GetAppsByMachine(db, machine.ID)
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "moved from 192.0.2.1:8080")
+}
+
+// Test that the agent registering only with the agent token from a new
+// address doesn't change the existing machine. The relocation remains
+// pending until confirmed by the super admin changing the machine address.
+func TestCreateMachineAgentRelocatedTokenOnly(t *testing.T) {
+	// Arrange
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	settings := RestAPISettings{}
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	fec := &storktest.FakeEventCenter{}
+	fd := &storktest.FakeDispatcher{}
+	rapi, err := NewRestAPI(&settings, dbSettings, db, fa, fec, fd)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, _, _, err = certs.SetupServerCerts(db)
+	require.NoError(t, err)
+
+	fingerprint := [32]byte{1, 2, 3}
+	machine := &dbmodel.Machine{
+		Address:         "192.0.2.1",
+		AgentPort:       8080,
+		AgentToken:      "agentToken",
+		CertFingerprint: fingerprint,
+		Authorized:      true,
+	}
+	err = dbmodel.AddMachine(db, machine)
+	require.NoError(t, err)
+
+	addr := "192.0.2.2"
+	agentToken := "agentToken"
+	_, csrPEM, _, err := pki.GenKeyAndCSR("agent", []string{"name"}, []net.IP{net.ParseIP(addr)})
+	require.NoError(t, err)
+	agentCSR := string(csrPEM)
+	params := services.CreateMachineParams{
+		Machine: &models.NewMachineReq{
+			Address:    &addr,
+			AgentPort:  8081,
+			AgentCSR:   &agentCSR,
+			AgentToken: &agentToken,
+		},
+	}
+
+	// Act
+	rsp := rapi.CreateMachine(ctx, params)
+
+	// Assert
+	require.IsType(t, &services.CreateMachineOK{}, rsp)
+
+	machines, err := dbmodel.GetAllMachines(db, nil)
+	require.NoError(t, err)
+	require.Len(t, machines, 1)
+	require.Equal(t, "192.0.2.1", machines[0].Address)
+	require.EqualValues(t, 8080, machines[0].AgentPort)
+	require.True(t, machines[0].Authorized)
+	require.Equal(t, fingerprint, machines[0].CertFingerprint)
+	require.True(t, machines[0].IsPendingRelocationTo(addr, 8081))
+
+	require.Len(t, fec.Events, 1)
+	require.Contains(t, fec.Events[0].Text, "registered from 192.0.2.2:8081")
+
+	restMachine := rapi.machineToRestAPI(*machines[0])
+	require.Equal(t, addr, restMachine.RelocationAddress)
+	require.EqualValues(t, 8081, restMachine.RelocationAgentPort)
+
+	// Act
+	// The super admin confirms the relocation by changing the address.
+	user, err := dbmodel.GetUserByID(rapi.DB, 1)
+	require.NoError(t, err)
+	ctx, err = rapi.SessionManager.Load(ctx, "")
+	require.NoError(t, err)
+	err = rapi.SessionManager.LoginHandler(ctx, user)
+	require.NoError(t, err)
+	rsp = rapi.UpdateMachine(ctx, services.UpdateMachineParams{
+		ID: machine.ID,
+		Machine: &models.Machine{
+			Address:    &addr,
+			AgentPort:  8081,
+			Authorized: true,
+		},
+	})
+
+	// Assert
+	require.IsType(t, &services.UpdateMachineOK{}, rsp)
+	relocated, err := dbmodel.GetMachineByID(db, machine.ID)
+	require.NoError(t, err)
+	require.Equal(t, addr, relocated.Address)
+	require.EqualValues(t, 8081, relocated.AgentPort)
+	require.NotEqual(t, fingerprint, relocated.CertFingerprint)
+	require.False(t, relocated.HasPendingRelocation())
+}
+
 func TestGetMachines(t *testing.T) {
 	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
 	defer teardown()
@@ -822,6 +1005,82 @@ func TestUpdateMachine(t *testing.T) {
 	require.Equal(t, "Cannot parse address", *defaultRsp.Payload.Message)
 }
 
+// Test that the app state timeout of the machine is updated only when it
+// is specified and it is validated.
+func TestUpdateMachineAppStateTimeout(t *testing.T) {
+	// Arrange
+	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
+	defer teardown()
+
+	settings := RestAPISettings{}
+	fa := agentcommtest.NewFakeAgents(nil, nil)
+	fec := &storktest.FakeEventCenter{}
+	fd := &storktest.FakeDispatcher{}
+	rapi, err := NewRestAPI(&settings, dbSettings, db, fa, fec, fd)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	m := &dbmodel.Machine{
+		Address:   "localhost",
+		AgentPort: 8080,
+	}
+	err = dbmodel.AddMachine(db, m)
+	require.NoError(t, err)
+
+	addr := "localhost"
+	timeout := int64(30)
+	negativeTimeout := int64(-1)
+
+	t.Run("set timeout", func(t *testing.T) {
+		params := services.UpdateMachineParams{
+			ID: m.ID,
+			Machine: &models.Machine{
+				Address:         &addr,
+				AgentPort:       8080,
+				AppStateTimeout: &timeout,
+			},
+		}
+		rsp := rapi.UpdateMachine(ctx, params)
+		require.IsType(t, &services.UpdateMachineOK{}, rsp)
+		okRsp := rsp.(*services.UpdateMachineOK)
+		require.EqualValues(t, 30, *okRsp.Payload.AppStateTimeout)
+	})
+
+	t.Run("timeout not specified", func(t *testing.T) {
+		params := services.UpdateMachineParams{
+			ID: m.ID,
+			Machine: &models.Machine{
+				Address:   &addr,
+				AgentPort: 8080,
+			},
+		}
+		rsp := rapi.UpdateMachine(ctx, params)
+		require.IsType(t, &services.UpdateMachineOK{}, rsp)
+		okRsp := rsp.(*services.UpdateMachineOK)
+		require.EqualValues(t, 30, *okRsp.Payload.AppStateTimeout)
+	})
+
+	t.Run("negative timeout", func(t *testing.T) {
+		params := services.UpdateMachineParams{
+			ID: m.ID,
+			Machine: &models.Machine{
+				Address:         &addr,
+				AgentPort:       8080,
+				AppStateTimeout: &negativeTimeout,
+			},
+		}
+		rsp := rapi.UpdateMachine(ctx, params)
+		require.IsType(t, &services.UpdateMachineDefault{}, rsp)
+		defaultRsp := rsp.(*services.UpdateMachineDefault)
+		require.Equal(t, http.StatusBadRequest, getStatusCode(*defaultRsp))
+		require.Equal(t, "Bad app state timeout", *defaultRsp.Payload.Message)
+	})
+
+	machine, err := dbmodel.GetMachineByID(db, m.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 30, machine.AppStateTimeout)
+}
+
 func TestDeleteMachine(t *testing.T) {
 	db, dbSettings, teardown := dbtest.SetupDatabaseTestCase(t)
 	defer teardown()
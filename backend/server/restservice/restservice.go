@@ -25,6 +25,7 @@ import (
 	keaconfig "isc.org/stork/appcfg/kea"
 	"isc.org/stork/server/agentcomm"
 	"isc.org/stork/server/apps"
+	"isc.org/stork/server/apps/kea"
 	"isc.org/stork/server/config"
 	"isc.org/stork/server/configreview"
 	dbops "isc.org/stork/server/database"
@@ -63,6 +64,7 @@ type RestAPI struct {
 	Settings                   *RestAPISettings
 	DBSettings                 *dbops.DatabaseSettings
 	DB                         *dbops.PgDB
+	DBRouter                   *dbops.ReplicaRouter
 	SessionManager             *dbsession.SessionMgr
 	EventCenter                eventcenter.EventCenter
 	Pullers                    *apps.Pullers
@@ -177,6 +179,10 @@ func NewRestAPI(args ...interface{}) (*RestAPI, error) {
 			api.DB = arg.(*pg.DB)
 			continue
 		}
+		if argType.AssignableTo(reflect.TypeOf((*dbops.ReplicaRouter)(nil))) {
+			api.DBRouter = arg.(*dbops.ReplicaRouter)
+			continue
+		}
 		if argType.AssignableTo(reflect.TypeOf((*apps.Pullers)(nil))) {
 			api.Pullers = arg.(*apps.Pullers)
 			continue
@@ -209,6 +215,39 @@ func NewRestAPI(args ...interface{}) (*RestAPI, error) {
 	return api, nil
 }
 
+// Returns the database connection for the heavy read and reporting queries,
+// i.e., the machine dump, the daemon configuration export, and the aggregates
+// presented in the dashboard. It is one of the read replicas if they are
+// configured or the primary database otherwise. The queries returning the
+// data that the user may have just modified use the primary database to
+// avoid reading stale data due to the replication lag.
+func (r *RestAPI) getReportingDB() *dbops.PgDB {
+	if r.DBRouter != nil {
+		return r.DBRouter.Reader()
+	}
+	return r.DB
+}
+
+// Returns the severities of the events raised for the Kea daemon state
+// changes configured in the state puller. It returns nil if the state
+// puller is not available so the default severities are used.
+func (r *RestAPI) getDaemonEventSeverities() kea.DaemonEventSeverities {
+	if r.Pullers != nil && r.Pullers.AppsStatePuller != nil {
+		return r.Pullers.AppsStatePuller.DaemonEventSeverities
+	}
+	return nil
+}
+
+// Returns the timeouts and retries of the communication with the Kea apps
+// configured in the state puller. It returns nil if the state puller is not
+// available so the defaults are used.
+func (r *RestAPI) getAppStatePullSettings() *kea.AppStatePullSettings {
+	if r.Pullers != nil && r.Pullers.AppsStatePuller != nil {
+		return r.Pullers.AppsStatePuller.AppStatePullSettings
+	}
+	return nil
+}
+
 func prepareTLS(httpServer *http.Server, s *RestAPISettings) error {
 	var err error
 
@@ -8,12 +8,14 @@ import (
 	"path"
 	"testing"
 
+	"github.com/go-pg/pg/v10"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 	"isc.org/stork/hooks"
 	agentcommtest "isc.org/stork/server/agentcomm/test"
 	apps "isc.org/stork/server/apps"
 	appstest "isc.org/stork/server/apps/test"
+	dbops "isc.org/stork/server/database"
 	dbtest "isc.org/stork/server/database/test"
 	"isc.org/stork/server/hookmanager"
 	storktest "isc.org/stork/server/test"
@@ -198,3 +200,25 @@ func TestPrepareAuthenticationIconsNonWritableDirectory(t *testing.T) {
 	// Assert
 	require.ErrorContains(t, err, "cannot open the icon file to write")
 }
+
+// Test that the reporting queries go to the read replica when the replica
+// router is configured and to the primary database otherwise.
+func TestGetReportingDB(t *testing.T) {
+	// Arrange
+	primary := pg.Connect(&pg.Options{Addr: "primary:5432"})
+	defer primary.Close()
+	replica := pg.Connect(&pg.Options{Addr: "replica:5432"})
+	defer replica.Close()
+
+	rapi := &RestAPI{DB: primary}
+
+	// Act & Assert
+	require.Same(t, primary, rapi.getReportingDB())
+
+	// Arrange
+	rapi.DBRouter = dbops.NewReplicaRouter(primary, replica)
+
+	// Act & Assert
+	require.Same(t, replica, rapi.getReportingDB())
+	require.Same(t, primary, rapi.DB)
+}
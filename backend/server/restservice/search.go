@@ -43,13 +43,13 @@ func (r *RestAPI) SearchRecords(ctx context.Context, params search.SearchRecords
 	filters := &dbmodel.SubnetsByPageFilters{Text: &text}
 
 	// get list of subnets
-	subnets, err := r.getSubnets(0, 5, filters, "", dbmodel.SortDirAny)
+	subnets, err := r.getSubnets(r.DB, 0, 5, filters, "", dbmodel.SortDirAny)
 	if err != nil {
 		return handleSearchError(err, "Cannot get subnets from the db")
 	}
 
 	// get list of shared networks
-	sharedNetworks, err := r.getSharedNetworks(0, 5, 0, 0, &text, "", dbmodel.SortDirAny)
+	sharedNetworks, err := r.getSharedNetworks(r.DB, 0, 5, 0, 0, &text, "", dbmodel.SortDirAny)
 	if err != nil {
 		return handleSearchError(err, "Cannot get shared networks from the db")
 	}
@@ -8,6 +8,7 @@ import (
 	"github.com/go-openapi/runtime/middleware"
 	"github.com/go-openapi/strfmt"
 	log "github.com/sirupsen/logrus"
+	dbops "isc.org/stork/server/database"
 	dbmodel "isc.org/stork/server/database/model"
 	storkutil "isc.org/stork/util"
 
@@ -74,14 +75,15 @@ func (r *RestAPI) subnetToRestAPI(sn *dbmodel.Subnet) *models.Subnet {
 					RequireClientClasses: keaParameters.RequireClientClasses,
 				},
 				KeaConfigDdnsParameters: models.KeaConfigDdnsParameters{
-					DdnsGeneratedPrefix:       keaParameters.DDNSGeneratedPrefix,
-					DdnsOverrideClientUpdate:  keaParameters.DDNSOverrideClientUpdate,
-					DdnsOverrideNoUpdate:      keaParameters.DDNSOverrideNoUpdate,
-					DdnsQualifyingSuffix:      keaParameters.DDNSQualifyingSuffix,
-					DdnsReplaceClientName:     keaParameters.DDNSReplaceClientName,
-					DdnsSendUpdates:           keaParameters.DDNSSendUpdates,
-					DdnsUpdateOnRenew:         keaParameters.DDNSUpdateOnRenew,
-					DdnsUseConflictResolution: keaParameters.DDNSUseConflictResolution,
+					DdnsGeneratedPrefix:        keaParameters.DDNSGeneratedPrefix,
+					DdnsOverrideClientUpdate:   keaParameters.DDNSOverrideClientUpdate,
+					DdnsOverrideNoUpdate:       keaParameters.DDNSOverrideNoUpdate,
+					DdnsQualifyingSuffix:       keaParameters.DDNSQualifyingSuffix,
+					DdnsReplaceClientName:      keaParameters.DDNSReplaceClientName,
+					DdnsSendUpdates:            keaParameters.DDNSSendUpdates,
+					DdnsUpdateOnRenew:          keaParameters.DDNSUpdateOnRenew,
+					DdnsUseConflictResolution:  keaParameters.DDNSUseConflictResolution,
+					DdnsConflictResolutionMode: keaParameters.DDNSConflictResolutionMode,
 				},
 				KeaConfigFourOverSixParameters: models.KeaConfigFourOverSixParameters{
 					FourOverSixInterface:   keaParameters.FourOverSixInterface,
@@ -151,14 +153,15 @@ func (r *RestAPI) subnetToRestAPI(sn *dbmodel.Subnet) *models.Subnet {
 						RequireClientClasses: keaParameters.RequireClientClasses,
 					},
 					KeaConfigDdnsParameters: models.KeaConfigDdnsParameters{
-						DdnsGeneratedPrefix:       keaParameters.DDNSGeneratedPrefix,
-						DdnsOverrideClientUpdate:  keaParameters.DDNSOverrideClientUpdate,
-						DdnsOverrideNoUpdate:      keaParameters.DDNSOverrideNoUpdate,
-						DdnsQualifyingSuffix:      keaParameters.DDNSQualifyingSuffix,
-						DdnsReplaceClientName:     keaParameters.DDNSReplaceClientName,
-						DdnsSendUpdates:           keaParameters.DDNSSendUpdates,
-						DdnsUpdateOnRenew:         keaParameters.DDNSUpdateOnRenew,
-						DdnsUseConflictResolution: keaParameters.DDNSUseConflictResolution,
+						DdnsGeneratedPrefix:        keaParameters.DDNSGeneratedPrefix,
+						DdnsOverrideClientUpdate:   keaParameters.DDNSOverrideClientUpdate,
+						DdnsOverrideNoUpdate:       keaParameters.DDNSOverrideNoUpdate,
+						DdnsQualifyingSuffix:       keaParameters.DDNSQualifyingSuffix,
+						DdnsReplaceClientName:      keaParameters.DDNSReplaceClientName,
+						DdnsSendUpdates:            keaParameters.DDNSSendUpdates,
+						DdnsUpdateOnRenew:          keaParameters.DDNSUpdateOnRenew,
+						DdnsUseConflictResolution:  keaParameters.DDNSUseConflictResolution,
+						DdnsConflictResolutionMode: keaParameters.DDNSConflictResolutionMode,
 					},
 					KeaConfigHostnameCharParameters: models.KeaConfigHostnameCharParameters{
 						HostnameCharReplacement: keaParameters.HostnameCharReplacement,
@@ -223,14 +226,15 @@ func (r *RestAPI) subnetToRestAPI(sn *dbmodel.Subnet) *models.Subnet {
 					CacheMaxAge:    cfg.GetCacheParameters().CacheMaxAge,
 				},
 				KeaConfigDdnsParameters: models.KeaConfigDdnsParameters{
-					DdnsGeneratedPrefix:       cfg.GetDDNSParameters().DDNSGeneratedPrefix,
-					DdnsOverrideClientUpdate:  cfg.GetDDNSParameters().DDNSOverrideClientUpdate,
-					DdnsOverrideNoUpdate:      cfg.GetDDNSParameters().DDNSOverrideNoUpdate,
-					DdnsQualifyingSuffix:      cfg.GetDDNSParameters().DDNSQualifyingSuffix,
-					DdnsReplaceClientName:     cfg.GetDDNSParameters().DDNSReplaceClientName,
-					DdnsSendUpdates:           cfg.GetDDNSParameters().DDNSSendUpdates,
-					DdnsUpdateOnRenew:         cfg.GetDDNSParameters().DDNSUpdateOnRenew,
-					DdnsUseConflictResolution: cfg.GetDDNSParameters().DDNSUseConflictResolution,
+					DdnsGeneratedPrefix:        cfg.GetDDNSParameters().DDNSGeneratedPrefix,
+					DdnsOverrideClientUpdate:   cfg.GetDDNSParameters().DDNSOverrideClientUpdate,
+					DdnsOverrideNoUpdate:       cfg.GetDDNSParameters().DDNSOverrideNoUpdate,
+					DdnsQualifyingSuffix:       cfg.GetDDNSParameters().DDNSQualifyingSuffix,
+					DdnsReplaceClientName:      cfg.GetDDNSParameters().DDNSReplaceClientName,
+					DdnsSendUpdates:            cfg.GetDDNSParameters().DDNSSendUpdates,
+					DdnsUpdateOnRenew:          cfg.GetDDNSParameters().DDNSUpdateOnRenew,
+					DdnsUseConflictResolution:  cfg.GetDDNSParameters().DDNSUseConflictResolution,
+					DdnsConflictResolutionMode: cfg.GetDDNSParameters().DDNSConflictResolutionMode,
 				},
 				KeaConfigHostnameCharParameters: models.KeaConfigHostnameCharParameters{
 					HostnameCharReplacement: cfg.GetHostnameCharParameters().HostnameCharReplacement,
@@ -287,9 +291,9 @@ func (r *RestAPI) subnetToRestAPI(sn *dbmodel.Subnet) *models.Subnet {
 	return subnet
 }
 
-func (r *RestAPI) getSubnets(offset, limit int64, filters *dbmodel.SubnetsByPageFilters, sortField string, sortDir dbmodel.SortDirEnum) (*models.Subnets, error) {
+func (r *RestAPI) getSubnets(db *dbops.PgDB, offset, limit int64, filters *dbmodel.SubnetsByPageFilters, sortField string, sortDir dbmodel.SortDirEnum) (*models.Subnets, error) {
 	// get subnets from db
-	dbSubnets, total, err := dbmodel.GetSubnetsByPage(r.DB, offset, limit, filters, sortField, sortDir)
+	dbSubnets, total, err := dbmodel.GetSubnetsByPage(db, offset, limit, filters, sortField, sortDir)
 	if err != nil {
 		return nil, err
 	}
@@ -329,7 +333,7 @@ func (r *RestAPI) GetSubnets(ctx context.Context, params dhcp.GetSubnetsParams)
 		LocalSubnetID: params.LocalSubnetID,
 	}
 
-	subnets, err := r.getSubnets(start, limit, filters, "", dbmodel.SortDirAsc)
+	subnets, err := r.getSubnets(r.DB, start, limit, filters, "", dbmodel.SortDirAsc)
 	if err != nil {
 		msg := "Cannot get subnets from db"
 		log.Error(err)
@@ -372,9 +376,9 @@ func (r *RestAPI) GetSubnet(ctx context.Context, params dhcp.GetSubnetParams) mi
 	return rsp
 }
 
-func (r *RestAPI) getSharedNetworks(offset, limit, appID, family int64, filterText *string, sortField string, sortDir dbmodel.SortDirEnum) (*models.SharedNetworks, error) {
+func (r *RestAPI) getSharedNetworks(db *dbops.PgDB, offset, limit, appID, family int64, filterText *string, sortField string, sortDir dbmodel.SortDirEnum) (*models.SharedNetworks, error) {
 	// get shared networks from db
-	dbSharedNetworks, total, err := dbmodel.GetSharedNetworksByPage(r.DB, offset, limit, appID, family, filterText, sortField, sortDir)
+	dbSharedNetworks, total, err := dbmodel.GetSharedNetworksByPage(db, offset, limit, appID, family, filterText, sortField, sortDir)
 	if err != nil {
 		return nil, err
 	}
@@ -436,7 +440,7 @@ func (r *RestAPI) GetSharedNetworks(ctx context.Context, params dhcp.GetSharedNe
 	}
 
 	// get shared networks from db
-	sharedNetworks, err := r.getSharedNetworks(start, limit, appID, dhcpVer, params.Text, "", dbmodel.SortDirAsc)
+	sharedNetworks, err := r.getSharedNetworks(r.DB, start, limit, appID, dhcpVer, params.Text, "", dbmodel.SortDirAsc)
 	if err != nil {
 		msg := "Cannot get shared network from db"
 		log.Error(err)
@@ -2,7 +2,9 @@ package server
 
 import (
 	"os"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-pg/pg/v10"
 	flags "github.com/jessevdk/go-flags"
@@ -47,6 +49,8 @@ const (
 type StorkServer struct {
 	DBSettings dbops.DatabaseSettings
 	DB         *dbops.PgDB
+	// Routes the reporting queries to the read replicas, if configured.
+	DBRouter *dbops.ReplicaRouter
 
 	AgentsSettings agentcomm.AgentsSettings
 	Agents         agentcomm.ConnectedAgents
@@ -81,10 +85,26 @@ type EnvironmentFileSettings struct {
 // Global server settings (called application settings in go-flags nomenclature).
 type Settings struct {
 	EnvironmentFileSettings
-	Version               bool   `short:"v" long:"version" description:"Show software version"`
-	EnableMetricsEndpoint bool   `short:"m" long:"metrics" description:"Enable Prometheus /metrics endpoint (no auth)" env:"STORK_SERVER_ENABLE_METRICS"`
-	InitialPullerInterval int64  `long:"initial-puller-interval" description:"Initial interval used by pullers fetching data from Kea; if not provided the recommended values for each puller are used" env:"STORK_SERVER_INITIAL_PULLER_INTERVAL"`
-	HookDirectory         string `long:"hook-directory" description:"The path to the hook directory" env:"STORK_SERVER_HOOK_DIRECTORY" default:"/var/lib/stork-server/hooks"`
+	Version                 bool   `short:"v" long:"version" description:"Show software version"`
+	EnableMetricsEndpoint   bool   `short:"m" long:"metrics" description:"Enable Prometheus /metrics endpoint (no auth)" env:"STORK_SERVER_ENABLE_METRICS"`
+	InitialPullerInterval   int64  `long:"initial-puller-interval" description:"Initial interval used by pullers fetching data from Kea; if not provided the recommended values for each puller are used" env:"STORK_SERVER_INITIAL_PULLER_INTERVAL"`
+	HookDirectory           string `long:"hook-directory" description:"The path to the hook directory" env:"STORK_SERVER_HOOK_DIRECTORY" default:"/var/lib/stork-server/hooks"`
+	LeaseDatabaseProbe      bool   `long:"kea-lease-database-probe" description:"Periodically check if the Kea lease databases are reachable from the Stork server" env:"STORK_SERVER_KEA_LEASE_DATABASE_PROBE"`
+	SharedNetworkStats      bool   `long:"kea-shared-network-stats" description:"Request the lease statistics grouped by shared network from Kea instead of summing the subnet statistics; the summation is used if Kea doesn't return them" env:"STORK_SERVER_KEA_SHARED_NETWORK_STATS"`
+	PoolStats               bool   `long:"kea-pool-stats" description:"Request the pool-level lease statistics from Kea to find the most utilized pool in each subnet; the subnet utilization is used if Kea doesn't return them" env:"STORK_SERVER_KEA_POOL_STATS"`
+	KeaStoredStats          string `long:"kea-stored-stats" description:"Comma-separated list of the names of the Kea subnet statistics stored in the database, e.g., total-addresses,assigned-addresses; all statistics are stored if not provided" env:"STORK_SERVER_KEA_STORED_STATS"`
+	KeaStatsMaxSubnets      int64  `long:"kea-stats-max-subnets-per-call" description:"Maximum number of the subnets whose lease statistics are fetched from a Kea daemon in a single call; the statistics are fetched in multiple calls for the ranges of the subnet IDs if the daemon has more subnets; not limited if not provided" env:"STORK_SERVER_KEA_STATS_MAX_SUBNETS_PER_CALL"`
+	KeaStatsCombineCommands bool   `long:"kea-stats-combine-commands" description:"Send the lease statistics commands for all ranges of the subnet IDs in a single call together with the other statistics commands; it has effect only when --kea-stats-max-subnets-per-call is specified" env:"STORK_SERVER_KEA_STATS_COMBINE_COMMANDS"`
+	KeaStatsPullerSchedule  string `long:"kea-stats-puller-schedule" description:"Cron expression specifying when the Kea lease statistics are pulled, e.g., */5 * * * *; if not provided the stats are pulled at the interval configured in the settings" env:"STORK_SERVER_KEA_STATS_PULLER_SCHEDULE"`
+	KeaStateTimeout         int64  `long:"kea-state-timeout" description:"Number of milliseconds after which a single attempt to get the state from the Kea Control Agent or the Kea daemons times out" env:"STORK_SERVER_KEA_STATE_TIMEOUT" default:"2000"`
+	KeaStateRetries         int    `long:"kea-state-retries" description:"Number of the retries of the failed commands sent to the Kea Control Agent to get its state before the Control Agent is declared unreachable" env:"STORK_SERVER_KEA_STATE_RETRIES" default:"2"`
+	KeaStateRetryBackoff    int64  `long:"kea-state-retry-backoff" description:"Number of milliseconds before the first retry of the failed commands sent to the Kea Control Agent; the delay is doubled for each subsequent retry" env:"STORK_SERVER_KEA_STATE_RETRY_BACKOFF" default:"500"`
+	DaemonEventSeverity     string `long:"kea-daemon-event-severity" description:"Comma-separated list of the severities of the events raised for the Kea daemon state changes in the [daemon:]category=severity format, e.g., d2:unreachable=info; the daemons are dhcp4, dhcp6, d2 and ca; the categories are unreachable, reachable, restarted and version-changed" env:"STORK_SERVER_KEA_DAEMON_EVENT_SEVERITY"`
+	WebhookURL              string `long:"webhook-url" description:"URL of the webhook notified about the events, e.g., https://hooks.example.org/stork; the events are posted as JSON objects" env:"STORK_SERVER_WEBHOOK_URL"`
+	WebhookSeverity         string `long:"webhook-severity" description:"The lowest severity of the events posted to the webhook: info, warning or error" env:"STORK_SERVER_WEBHOOK_SEVERITY" default:"warning"`
+	WebhookEventTypes       string `long:"webhook-event-types" description:"Comma-separated list of the event types posted to the webhook, e.g., unreachable,exhausted; an event matches the type when its text contains it; all events are posted if not provided" env:"STORK_SERVER_WEBHOOK_EVENT_TYPES"`
+	WebhookRetries          int    `long:"webhook-retries" description:"Number of the retries of a failed event delivery to the webhook" env:"STORK_SERVER_WEBHOOK_RETRIES" default:"3"`
+	WebhookQuietPeriod      int64  `long:"webhook-quiet-period" description:"Number of seconds after the server start during which the events are not posted to the webhook to avoid the notifications about the state changes detected by the first pulls; the events are still stored" env:"STORK_SERVER_WEBHOOK_QUIET_PERIOD" default:"0"`
 }
 
 // Parse the command line arguments into GO structures.
@@ -203,6 +223,12 @@ func (ss *StorkServer) Bootstrap(reload bool) (err error) {
 	if err != nil {
 		return err
 	}
+	replicas, err := dbops.NewReplicaConns(&ss.DBSettings)
+	if err != nil {
+		ss.DB.Close()
+		return err
+	}
+	ss.DBRouter = dbops.NewReplicaRouter(ss.DB, replicas...)
 
 	// initialize stork settings
 	err = dbmodel.InitializeSettings(ss.DB, ss.GeneralSettings.InitialPullerInterval)
@@ -218,9 +244,32 @@ func (ss *StorkServer) Bootstrap(reload bool) (err error) {
 	}
 
 	// setup event center
-	ss.EventCenter = eventcenter.NewEventCenter(ss.DB)
+	var webhooks []*eventcenter.WebhookSubscriber
+	if ss.GeneralSettings.WebhookURL != "" {
+		webhookLevel, err := dbmodel.ParseEventLevel(ss.GeneralSettings.WebhookSeverity)
+		if err != nil {
+			return err
+		}
+		webhook, err := eventcenter.NewWebhookSubscriber(eventcenter.WebhookSettings{
+			URL:           ss.GeneralSettings.WebhookURL,
+			Level:         webhookLevel,
+			EventTypes:    eventcenter.ParseWebhookEventTypes(ss.GeneralSettings.WebhookEventTypes),
+			Retries:       ss.GeneralSettings.WebhookRetries,
+			RetryInterval: eventcenter.DefaultWebhookRetryInterval,
+			QuietPeriod:   time.Duration(ss.GeneralSettings.WebhookQuietPeriod) * time.Second,
+		})
+		if err != nil {
+			return err
+		}
+		webhooks = append(webhooks, webhook)
+		log.WithField("url", ss.GeneralSettings.WebhookURL).Info("Events will be posted to the webhook")
+	}
+	ss.EventCenter = eventcenter.NewEventCenter(ss.DB, webhooks...)
 
 	// setup connected agents
+	if _, err = agentcomm.ParseTLSServerNames(ss.AgentsSettings.TLSServerNames); err != nil {
+		return err
+	}
 	ss.Agents = agentcomm.NewConnectedAgents(&ss.AgentsSettings, ss.EventCenter, caCertPEM, serverCertPEM, serverKeyPEM)
 	// TODO: if any operation below fails then this Shutdown here causes segfault.
 	// I do not know why and do not know how to fix this. Commenting out for now.
@@ -256,6 +305,19 @@ func (ss *StorkServer) Bootstrap(reload bool) (err error) {
 	if err != nil {
 		return err
 	}
+	if ss.GeneralSettings.LeaseDatabaseProbe {
+		ss.Pullers.AppsStatePuller.LeaseDatabaseProber = kea.NewLeaseDatabaseProber(nil)
+	}
+	ss.Pullers.AppsStatePuller.DaemonEventSeverities, err = kea.ParseDaemonEventSeverities(ss.GeneralSettings.DaemonEventSeverity)
+	if err != nil {
+		return err
+	}
+	ss.Pullers.AppsStatePuller.AppStatePullSettings = &kea.AppStatePullSettings{
+		Timeout:            time.Duration(ss.GeneralSettings.KeaStateTimeout) * time.Millisecond,
+		Retries:            ss.GeneralSettings.KeaStateRetries,
+		Backoff:            time.Duration(ss.GeneralSettings.KeaStateRetryBackoff) * time.Millisecond,
+		ImplausibleUptimes: kea.NewImplausibleUptimeTracker(),
+	}
 
 	// setup bind9 stats puller
 	ss.Pullers.Bind9StatsPuller, err = bind9.NewStatsPuller(ss.DB, ss.Agents, ss.EventCenter)
@@ -264,10 +326,18 @@ func (ss *StorkServer) Bootstrap(reload bool) (err error) {
 	}
 
 	// setup kea stats puller
-	ss.Pullers.KeaStatsPuller, err = kea.NewStatsPuller(ss.DB, ss.Agents)
+	ss.Pullers.KeaStatsPuller, err = kea.NewStatsPuller(ss.DB, ss.Agents, ss.EventCenter, storkutil.NewRealClock())
 	if err != nil {
 		return err
 	}
+	ss.Pullers.KeaStatsPuller.SharedNetworkStats = ss.GeneralSettings.SharedNetworkStats
+	ss.Pullers.KeaStatsPuller.PoolStats = ss.GeneralSettings.PoolStats
+	ss.Pullers.KeaStatsPuller.SetStoredStats(strings.Split(ss.GeneralSettings.KeaStoredStats, ","))
+	ss.Pullers.KeaStatsPuller.MaxSubnetsPerCall = ss.GeneralSettings.KeaStatsMaxSubnets
+	ss.Pullers.KeaStatsPuller.CombineCommands = ss.GeneralSettings.KeaStatsCombineCommands
+	if err = ss.Pullers.KeaStatsPuller.SetCronSchedule(ss.GeneralSettings.KeaStatsPullerSchedule); err != nil {
+		return err
+	}
 
 	// Setup Kea hosts puller.
 	ss.Pullers.KeaHostsPuller, err = kea.NewHostsPuller(ss.DB, ss.Agents, ss.ReviewDispatcher, ss.DHCPOptionDefinitionLookup)
@@ -301,7 +371,7 @@ func (ss *StorkServer) Bootstrap(reload bool) (err error) {
 
 	// setup ReST API service
 	r, err := restservice.NewRestAPI(&ss.RestAPISettings, &ss.DBSettings,
-		ss.DB, ss.Agents, ss.EventCenter,
+		ss.DB, ss.DBRouter, ss.Agents, ss.EventCenter,
 		ss.Pullers, ss.ReviewDispatcher, ss.MetricsCollector, ss.ConfigManager,
 		ss.DHCPOptionDefinitionLookup, ss.HookManager)
 	if err != nil {
@@ -315,6 +385,7 @@ func (ss *StorkServer) Bootstrap(reload bool) (err error) {
 		}
 
 		ss.HookManager.Close()
+		ss.DBRouter.CloseReplicas()
 		ss.DB.Close()
 
 		return err
@@ -365,6 +436,7 @@ func (ss *StorkServer) Shutdown(reload bool) {
 			ss.MetricsCollector.Shutdown()
 		}
 		ss.HookManager.Close()
+		ss.DBRouter.CloseReplicas()
 		ss.DB.Close()
 
 		if !reload {
@@ -0,0 +1,33 @@
+package testutil
+
+import (
+	"sync"
+	"time"
+)
+
+// The clock returning a fixed time which can be moved forward on demand.
+// It implements the storkutil.Clock interface and is useful in the tests
+// of the time-dependent logic.
+type FakeClock struct {
+	now   time.Time
+	mutex sync.Mutex
+}
+
+// Creates a fake clock set to the specified time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Returns the current time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+// Moves the clock forward by the specified duration.
+func (c *FakeClock) Advance(duration time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(duration)
+}
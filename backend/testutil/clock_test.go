@@ -0,0 +1,23 @@
+package testutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the fake clock returns the configured time until it is
+// advanced.
+func TestFakeClock(t *testing.T) {
+	// Arrange
+	start := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	// Act & Assert
+	require.Equal(t, start, clock.Now())
+	require.Equal(t, start, clock.Now())
+
+	clock.Advance(90 * time.Second)
+	require.Equal(t, start.Add(90*time.Second), clock.Now())
+}
@@ -0,0 +1,25 @@
+package storkutil
+
+import "time"
+
+// Interface to a source of the current time. The components performing
+// time-dependent computations should use it instead of calling time.Now()
+// directly, so the unit tests can control the time flow.
+type Clock interface {
+	// Returns the current time.
+	Now() time.Time
+}
+
+// Clock implementation returning the current system time in the UTC
+// time zone.
+type realClock struct{}
+
+// Returns the current system time in the UTC time zone.
+func (realClock) Now() time.Time {
+	return UTCNow()
+}
+
+// Creates a clock returning the current system time in the UTC time zone.
+func NewRealClock() Clock {
+	return realClock{}
+}
@@ -0,0 +1,23 @@
+package storkutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the real clock returns the current time in the UTC time zone.
+func TestRealClockNow(t *testing.T) {
+	// Arrange
+	clock := NewRealClock()
+	before := time.Now()
+
+	// Act
+	now := clock.Now()
+
+	// Assert
+	require.Equal(t, time.UTC, now.Location())
+	require.False(t, now.Before(before.Truncate(time.Second)))
+	require.False(t, now.After(time.Now()))
+}
@@ -0,0 +1,147 @@
+package storkutil
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Represents a schedule specified with a standard cron expression. The
+// expression consists of five space-separated fields: minute (0-59), hour
+// (0-23), day of month (1-31), month (1-12) and day of week (0-6, where 0
+// is Sunday). Each field is an asterisk, a single value, a range (e.g.,
+// 9-17) or a comma-separated list of them. The asterisks and the ranges
+// may be followed by a step (e.g., */5 or 9-17/2).
+type CronSchedule struct {
+	expression string
+	minutes    []bool
+	hours      []bool
+	daysOfMon  []bool
+	months     []bool
+	daysOfWeek []bool
+	// Indicate whether the day fields were restricted. As in the standard
+	// cron, if both are restricted, the day matches if any of them matches.
+	daysOfMonRestricted  bool
+	daysOfWeekRestricted bool
+}
+
+// Parses a single field of the cron expression. It returns the allowed
+// values as a slice indexed by the value and the flag indicating whether
+// the field is restricted (i.e., it is not an asterisk).
+func parseCronField(field string, minValue, maxValue int) ([]bool, bool, error) {
+	allowed := make([]bool, maxValue+1)
+	restricted := true
+	for _, item := range strings.Split(field, ",") {
+		valueRange := item
+		step := 1
+		if idx := strings.Index(item, "/"); idx >= 0 {
+			var err error
+			step, err = strconv.Atoi(item[idx+1:])
+			if err != nil || step <= 0 {
+				return nil, false, errors.Errorf("invalid step in the cron field %s", field)
+			}
+			valueRange = item[:idx]
+		}
+		var lower, upper int
+		switch {
+		case valueRange == "*":
+			lower, upper = minValue, maxValue
+			if field == "*" {
+				restricted = false
+			}
+		case strings.Contains(valueRange, "-"):
+			bounds := strings.SplitN(valueRange, "-", 2)
+			var err error
+			if lower, err = strconv.Atoi(bounds[0]); err != nil {
+				return nil, false, errors.Errorf("invalid range in the cron field %s", field)
+			}
+			if upper, err = strconv.Atoi(bounds[1]); err != nil {
+				return nil, false, errors.Errorf("invalid range in the cron field %s", field)
+			}
+		default:
+			value, err := strconv.Atoi(valueRange)
+			if err != nil {
+				return nil, false, errors.Errorf("invalid value in the cron field %s", field)
+			}
+			lower, upper = value, value
+			if strings.Contains(item, "/") {
+				upper = maxValue
+			}
+		}
+		if lower < minValue || upper > maxValue || lower > upper {
+			return nil, false, errors.Errorf("cron field %s is out of the range %d-%d", field, minValue, maxValue)
+		}
+		for value := lower; value <= upper; value += step {
+			allowed[value] = true
+		}
+	}
+	return allowed, restricted, nil
+}
+
+// Parses the cron expression and returns the schedule.
+func ParseCronSchedule(expression string) (*CronSchedule, error) {
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("cron expression %s must consist of 5 fields: minute, hour, day of month, month and day of week", expression)
+	}
+	schedule := &CronSchedule{expression: expression}
+	var err error
+	if schedule.minutes, _, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, err
+	}
+	if schedule.hours, _, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, err
+	}
+	if schedule.daysOfMon, schedule.daysOfMonRestricted, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, err
+	}
+	if schedule.months, _, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, err
+	}
+	if schedule.daysOfWeek, schedule.daysOfWeekRestricted, err = parseCronField(fields[4], 0, 6); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// Returns the cron expression the schedule was parsed from.
+func (schedule *CronSchedule) String() string {
+	return schedule.expression
+}
+
+// Checks if the schedule allows the day of the given time.
+func (schedule *CronSchedule) matchesDay(t time.Time) bool {
+	dayOfMon := schedule.daysOfMon[t.Day()]
+	dayOfWeek := schedule.daysOfWeek[int(t.Weekday())]
+	if schedule.daysOfMonRestricted && schedule.daysOfWeekRestricted {
+		return dayOfMon || dayOfWeek
+	}
+	return dayOfMon && dayOfWeek
+}
+
+// Returns the first scheduled time after the given time. The schedule is
+// evaluated in the location of the given time. It returns zero time if
+// the schedule never matches (e.g., February 30).
+func (schedule *CronSchedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	// The schedule repeats at least every four years, including the leap
+	// years, so there is no point in looking further.
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case !schedule.months[int(t.Month())]:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !schedule.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !schedule.hours[t.Hour()]:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case !schedule.minutes[t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
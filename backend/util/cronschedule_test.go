@@ -0,0 +1,117 @@
+package storkutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the valid cron expressions are parsed.
+func TestParseCronSchedule(t *testing.T) {
+	expressions := []string{
+		"* * * * *",
+		"*/5 * * * *",
+		"0,30 8-17 * * 1-5",
+		"5/15 0 1 1,7 0",
+		"  0   12 *  * * ",
+		"0 9-17/2 * * *",
+	}
+	for _, expression := range expressions {
+		t.Run(expression, func(t *testing.T) {
+			schedule, err := ParseCronSchedule(expression)
+			require.NoError(t, err)
+			require.NotNil(t, schedule)
+			require.Equal(t, expression, schedule.String())
+		})
+	}
+}
+
+// Test that parsing the invalid cron expressions fails.
+func TestParseInvalidCronSchedule(t *testing.T) {
+	expressions := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 7",
+		"*/0 * * * *",
+		"*/foo * * * *",
+		"10-5 * * * *",
+		"a-5 * * * *",
+		"foo * * * *",
+		"1,,2 * * * *",
+	}
+	for _, expression := range expressions {
+		t.Run(expression, func(t *testing.T) {
+			schedule, err := ParseCronSchedule(expression)
+			require.Error(t, err)
+			require.Nil(t, schedule)
+		})
+	}
+}
+
+// Test that the next scheduled time is aligned to the schedule.
+func TestCronScheduleNext(t *testing.T) {
+	// Wednesday.
+	now := time.Date(2023, time.March, 15, 10, 7, 30, 0, time.UTC)
+
+	testCases := []struct {
+		expression string
+		expected   time.Time
+	}{
+		{"* * * * *", time.Date(2023, time.March, 15, 10, 8, 0, 0, time.UTC)},
+		{"*/5 * * * *", time.Date(2023, time.March, 15, 10, 10, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2023, time.March, 15, 11, 0, 0, 0, time.UTC)},
+		{"30 9 * * *", time.Date(2023, time.March, 16, 9, 30, 0, 0, time.UTC)},
+		{"0 8-17 * * 1-5", time.Date(2023, time.March, 15, 11, 0, 0, 0, time.UTC)},
+		{"0 8 * * 6,0", time.Date(2023, time.March, 18, 8, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 1 *", time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		// The day matches if any of the restricted day fields matches.
+		{"0 0 20 * 5", time.Date(2023, time.March, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.expression, func(t *testing.T) {
+			// Arrange
+			schedule, err := ParseCronSchedule(testCase.expression)
+			require.NoError(t, err)
+
+			// Act
+			next := schedule.Next(now)
+
+			// Assert
+			require.Equal(t, testCase.expected, next)
+		})
+	}
+}
+
+// Test that the next scheduled time is after the given time even if the
+// given time matches the schedule.
+func TestCronScheduleNextStrictlyAfter(t *testing.T) {
+	// Arrange
+	schedule, _ := ParseCronSchedule("*/5 * * * *")
+	now := time.Date(2023, time.March, 15, 10, 5, 0, 0, time.UTC)
+
+	// Act
+	next := schedule.Next(now)
+
+	// Assert
+	require.Equal(t, time.Date(2023, time.March, 15, 10, 10, 0, 0, time.UTC), next)
+}
+
+// Test that the zero time is returned when the schedule never matches.
+func TestCronScheduleNextNever(t *testing.T) {
+	// Arrange
+	schedule, _ := ParseCronSchedule("0 0 30 2 *")
+
+	// Act
+	next := schedule.Next(time.Date(2023, time.March, 15, 10, 5, 0, 0, time.UTC))
+
+	// Assert
+	require.True(t, next.IsZero())
+}
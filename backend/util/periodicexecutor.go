@@ -21,6 +21,11 @@ type PeriodicExecutor struct {
 	wg              *sync.WaitGroup
 	mutex           *sync.Mutex
 	getIntervalFunc func() (int64, error)
+	// Optional schedule overriding the interval. If it is set, the
+	// function is executed at the scheduled times instead.
+	schedule        *CronSchedule
+	scheduleTimer   *time.Timer
+	scheduleUpdated chan bool
 }
 
 // Interval is used while the puller is inactive to check if it was re-enabled.
@@ -59,6 +64,7 @@ func NewPeriodicExecutor(name string, executorFunc func() error, getIntervalFunc
 		mutex:           &sync.Mutex{},
 		interval:        interval,
 		getIntervalFunc: getIntervalFunc,
+		scheduleUpdated: make(chan bool, 1),
 	}
 
 	periodicExecutor.wg.Add(1)
@@ -123,8 +129,11 @@ func (executor *PeriodicExecutor) unpause(lock bool, intervals ...int64) {
 			// Override the interval.
 			executor.interval = intervals[0]
 		}
-		// Reschedule the timer.
-		executor.ticker.Reset(time.Duration(executor.interval) * time.Second)
+		// Reschedule the timer. The ticker is not used when the executor
+		// runs according to the schedule.
+		if executor.schedule == nil {
+			executor.ticker.Reset(time.Duration(executor.interval) * time.Second)
+		}
 	}
 }
 
@@ -152,12 +161,87 @@ func (executor *PeriodicExecutor) Reset(interval int64) {
 	executor.unpause(false, interval)
 }
 
+// Sets the schedule of the executor. If the schedule is set, the function
+// is executed at the scheduled times instead of the interval. The interval
+// is still used to disable the executor when it is zero. The nil schedule
+// restores the interval-based execution.
+func (executor *PeriodicExecutor) SetSchedule(schedule *CronSchedule) {
+	executor.mutex.Lock()
+	defer executor.mutex.Unlock()
+	executor.schedule = schedule
+	executor.rescheduleTimer(time.Now())
+	if schedule == nil {
+		if executor.pauseCount == 0 {
+			executor.ticker.Reset(time.Duration(executor.interval) * time.Second)
+		}
+	} else {
+		executor.ticker.Stop()
+	}
+	// Wake up the executor loop to wait for the new timer.
+	select {
+	case executor.scheduleUpdated <- true:
+	default:
+	}
+}
+
+// Returns the schedule of the executor or nil if the executor uses the
+// interval.
+func (executor *PeriodicExecutor) GetSchedule() *CronSchedule {
+	executor.mutex.Lock()
+	defer executor.mutex.Unlock()
+	return executor.schedule
+}
+
+// Sets the schedule timer to the first scheduled time after the given
+// time. It must be called with the mutex locked.
+func (executor *PeriodicExecutor) rescheduleTimer(now time.Time) {
+	if executor.scheduleTimer != nil {
+		executor.scheduleTimer.Stop()
+		executor.scheduleTimer = nil
+	}
+	if executor.schedule == nil {
+		return
+	}
+	next := executor.schedule.Next(now)
+	if next.IsZero() {
+		log.Warnf("Schedule %s of %s never matches", executor.schedule, executor.name)
+		return
+	}
+	executor.scheduleTimer = time.NewTimer(next.Sub(now))
+}
+
+// Returns the channel of the schedule timer or nil if the schedule is not
+// set. Receiving from the nil channel blocks forever.
+func (executor *PeriodicExecutor) getScheduleTimerChan() <-chan time.Time {
+	executor.mutex.Lock()
+	defer executor.mutex.Unlock()
+	if executor.scheduleTimer == nil {
+		return nil
+	}
+	return executor.scheduleTimer.C
+}
+
 // This function controls the timing of the function execution and captures the
 // termination signal.
 func (executor *PeriodicExecutor) executorLoop() {
 	defer executor.wg.Done()
 	for {
 		select {
+		// execute user defined function at the scheduled time
+		case <-executor.getScheduleTimerChan():
+			if executor.active && !executor.Paused() {
+				executor.Pause()
+				err := executor.executorFunc()
+				executor.Unpause()
+				if err != nil {
+					log.Errorf("Errors were encountered while pulling data from apps: %+v", err)
+				}
+			}
+			executor.mutex.Lock()
+			executor.rescheduleTimer(time.Now())
+			executor.mutex.Unlock()
+		// the schedule was changed, so wait for the new timer
+		case <-executor.scheduleUpdated:
 		// every N seconds execute user defined function
 		case <-executor.ticker.C:
 			if executor.active {
@@ -171,3 +171,41 @@ func TestGetName(t *testing.T) {
 	// Assert
 	require.EqualValues(t, "foobar", name)
 }
+
+// Test that the executor doesn't run at the interval when the schedule
+// is set and that it runs at the interval again when the schedule is
+// removed.
+func TestSetSchedule(t *testing.T) {
+	// Arrange
+	var calls int64
+	executor, _ := NewPeriodicExecutor("", func() error {
+		atomic.AddInt64(&calls, 1)
+		return nil
+	}, func() (int64, error) { return 1, nil })
+	defer executor.Shutdown()
+
+	// The schedule matches once a year.
+	schedule, err := ParseCronSchedule("0 0 1 1 *")
+	require.NoError(t, err)
+
+	// Act
+	executor.SetSchedule(schedule)
+	atomic.StoreInt64(&calls, 0)
+
+	// Assert
+	require.Equal(t, schedule, executor.GetSchedule())
+	require.Never(t, func() bool {
+		return atomic.LoadInt64(&calls) > 0
+	}, 3*time.Second, 500*time.Millisecond,
+		"executor function was invoked at the interval but it shouldn't when the schedule is set")
+
+	// Act
+	executor.SetSchedule(nil)
+
+	// Assert
+	require.Nil(t, executor.GetSchedule())
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt64(&calls) > 0
+	}, 5*time.Second, 500*time.Millisecond,
+		"executor function was not invoked at the interval after removing the schedule")
+}
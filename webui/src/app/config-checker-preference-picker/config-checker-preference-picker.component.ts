@@ -202,6 +202,12 @@ export class ConfigCheckerPreferencePickerComponent {
                     'via the HTTP ports exposed by the dedicated listeners ' +
                     'rather than Kea Control Agent.'
                 )
+            case 'ha_insecure_access_point':
+                return (
+                    'The checker verifies if the Kea Control Agent forwarding ' +
+                    'the commands to the DHCP daemon participating in the ' +
+                    'High Availability setup accepts connections over TLS.'
+                )
             case 'address_pools_exhausted_by_reservations':
                 return 'The checker verifying if all available addresses in IP pools are not reserved for hosts.'
             case 'pd_pools_exhausted_by_reservations':
@@ -213,12 +219,93 @@ export class ConfigCheckerPreferencePickerComponent {
                     'database and suggesting replacing it with the ' +
                     'configuration backend command hook.'
                 )
+            case 'lease_timers_conflict':
+                return (
+                    'The checker verifying if the renew timer is lower than ' +
+                    'the rebind timer and none of them exceeds the valid ' +
+                    'lifetime in any subnet.'
+                )
+            case 'unused_client_class':
+                return (
+                    'The checker verifying if all client classes are ' +
+                    'referenced by subnets, shared networks, pools, host ' +
+                    'reservations or other classes, or define DHCP options.'
+                )
+            case 'reservation_out_of_subnet':
+                return 'The checker verifying if the reserved addresses belong to the subnets they are specified in.'
+            case 'subnet_without_id':
+                return (
+                    'The checker verifying if all subnets have explicit IDs. The IDs assigned by Kea ' +
+                    'automatically may change when the subnets are reordered.'
+                )
+            case 'ha_unknown_peers':
+                return (
+                    'The checker verifying if all High Availability peers of the DHCP daemon are monitored ' +
+                    'by Stork.'
+                )
+            case 'deprecated_parameter':
+                return (
+                    'The checker verifying if the DHCP daemon configuration uses the parameters deprecated ' +
+                    'in the newer Kea versions.'
+                )
+            case 'in_pool_reservation_ignored':
+                return (
+                    'The checker verifying if the subnets with the in-pool host reservations have the ' +
+                    'reservations-in-subnet enabled.'
+                )
+            case 'global_reservation_ignored':
+                return (
+                    'The checker verifying if the reservations-global is enabled when the global host ' +
+                    'reservations are defined.'
+                )
+            case 'fragmented_pools':
+                return (
+                    'The checker verifying if the subnets are not fragmented across an unusually high ' +
+                    'number of address or delegated prefix pools.'
+                )
+            case 'ha_client_id_mismatch':
+                return (
+                    'The checker verifying if the DHCPv4 High Availability peers use the same ' +
+                    'match-client-id and echo-client-id settings.'
+                )
+            case 'ha_rapid_commit_mismatch':
+                return (
+                    'The checker verifying if the DHCPv6 High Availability peers use the same ' +
+                    'rapid-commit settings for the same subnets.'
+                )
+            case 'pool_family_mismatch':
+                return (
+                    'The checker verifying if the address and delegated prefix pools belong to ' +
+                    'the same address family as their subnets.'
+                )
+            case 'overlapping_pool':
+                return 'The checker verifying if the address pools in the subnets do not overlap.'
+            case 'valid_lifetime_out_of_range':
+                return (
+                    'The checker verifying if the valid lifetime in the subnets is within the ' +
+                    'sane range configured in the settings.'
+                )
+            case 'valid_lifetime_unset':
+                return (
+                    'The checker finding the subnets without the valid lifetime specified at any ' +
+                    'configuration level, i.e., relying on the Kea default.'
+                )
+            case 'duplicate_option':
+                return (
+                    'The checker verifying if the subnets define the same option more than ' +
+                    'once in the same scope.'
+                )
             case 'agent_credentials_over_https':
                 return (
                     'The checker verifying if the Stork agent communicates ' +
                     'with the Kea Control Agent using the TLS when the ' +
                     'HTTP authentication credentials (i.e., Basic Auth) are configured.'
                 )
+            case 'duplicate_access_point':
+                return (
+                    'The checker verifying if the Kea Control Agent access point address and port are not ' +
+                    'shared with other apps registered in Stork.'
+                )
             default:
                 return ''
         }
@@ -294,12 +294,18 @@ export class KeaAppTabComponent implements OnInit, OnDestroy {
      * Checks if the specified log target can be viewed
      *
      * Only the logs that are stored in the file can be viewed in Stork. The
-     * logs output to stdout, stderr or syslog can't be viewed in Stork.
+     * logs output to stdout, stderr or syslog can't be viewed in Stork. The
+     * server classifies the log target outputs. The output location is
+     * checked if the classification is not available.
      *
-     * @param target log target output location
+     * @param logTarget log target
      * @returns true if the log target can be viewed, false otherwise.
      */
-    logTargetViewable(target): boolean {
+    logTargetViewable(logTarget): boolean {
+        if (logTarget.tailable !== undefined && logTarget.tailable !== null) {
+            return logTarget.tailable
+        }
+        const target = logTarget.output
         return target !== 'stdout' && target !== 'stderr' && !target.startsWith('syslog')
     }
 